@@ -0,0 +1,71 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/marcus/sidecar/internal/agentstatus"
+)
+
+// maxHookInput bounds how much hook input is read from stdin.
+const maxHookInput = 1 << 20
+
+// runAgentHook implements `sidecar agent-hook`, the command sidecar registers
+// as a Claude Code hook and Codex notify program. It records the agent's
+// status in the worktree's status file. Hooks must never disrupt the agent,
+// so failures are reported on stderr but always exit 0.
+func runAgentHook(args []string) int {
+	fs := flag.NewFlagSet("agent-hook", flag.ContinueOnError)
+	agent := fs.String("agent", "claude", "agent invoking the hook: claude or codex")
+	file := fs.String("file", "", "status file to write")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: sidecar agent-hook -agent claude|codex -file PATH [payload]\n\n")
+		fmt.Fprintf(fs.Output(), "Record agent status from a Claude Code hook (payload on stdin)\n")
+		fmt.Fprintf(fs.Output(), "or Codex notify (payload as the last argument). Registered by the\n")
+		fmt.Fprintf(fs.Output(), "workspace plugin when it starts an agent.\n\n")
+		fmt.Fprintf(fs.Output(), "Options:\n")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		// A non-zero exit from a Claude hook blocks the tool call; the
+		// flag package has already written the error to stderr.
+		return 0
+	}
+	if *file == "" {
+		fmt.Fprintln(os.Stderr, "sidecar agent-hook: -file is required")
+		return 0
+	}
+
+	var (
+		ev agentstatus.Event
+		ok bool
+	)
+	switch *agent {
+	case "claude":
+		input, err := io.ReadAll(io.LimitReader(os.Stdin, maxHookInput))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "sidecar agent-hook: read stdin: %v\n", err)
+			return 0
+		}
+		ev, ok = agentstatus.FromClaudeHook(input)
+	case "codex":
+		if fs.NArg() > 0 {
+			ev, ok = agentstatus.FromCodexNotify(fs.Arg(fs.NArg() - 1))
+		}
+	default:
+		fmt.Fprintf(os.Stderr, "sidecar agent-hook: unknown agent %q\n", *agent)
+		return 0
+	}
+	if !ok {
+		return 0
+	}
+	if prev, err := agentstatus.Read(*file); err == nil {
+		ev = agentstatus.InheritTool(ev, prev)
+	}
+	if err := agentstatus.Write(*file, ev); err != nil {
+		fmt.Fprintf(os.Stderr, "sidecar agent-hook: %v\n", err)
+	}
+	return 0
+}
@@ -0,0 +1,14 @@
+package main
+
+import "testing"
+
+func TestPipedCommandsExitZeroOnBadFlags(t *testing.T) {
+	// A non-zero exit from a Claude hook blocks the tool call, and tmux
+	// pipe-pane commands must never disturb the pane.
+	if code := runAgentHook([]string{"-bogus"}); code != 0 {
+		t.Errorf("agent-hook exit code = %d, want 0", code)
+	}
+	if code := runTranscript([]string{"-bogus"}); code != 0 {
+		t.Errorf("transcript exit code = %d, want 0", code)
+	}
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/marcus/sidecar/internal/dataset"
+	"github.com/marcus/sidecar/internal/sessionmeta"
+	"github.com/marcus/sidecar/internal/timebound"
+)
+
+// runExport implements `sidecar export`, which writes sessions as
+// training-data JSONL (OpenAI chat or ShareGPT).
+func runExport(args []string) int {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	var (
+		adapters, models, sessionIDs, tags, redactPatterns stringList
+	)
+	format := fs.String("format", "openai", "output format: openai or sharegpt")
+	project := fs.String("project", *projectRoot, "project root directory")
+	output := fs.String("o", "", "output file (default stdout)")
+	since := fs.String("since", "", "only sessions updated since (YYYY-MM-DD, RFC3339, or age like 7d)")
+	until := fs.String("until", "", "only sessions updated until (YYYY-MM-DD, inclusive; RFC3339; or age like 7d)")
+	redact := fs.Bool("redact", false, "redact secrets, emails, and home directory paths")
+	thinking := fs.Bool("thinking", false, "include thinking blocks in assistant turns")
+	metadata := fs.Bool("metadata", false, "add a metadata object (session, model, tags, notes, annotations) to each record")
+	system := fs.String("system", "", "system prompt to prepend to every conversation")
+	fs.Var(&adapters, "adapter", "adapter ID to include (repeatable or comma-separated)")
+	fs.Var(&models, "model", "model substring to include, e.g. opus (repeatable)")
+	fs.Var(&sessionIDs, "session", "session ID to include (repeatable)")
+	fs.Var(&tags, "tag", "only sessions carrying this tag (repeatable; all must match)")
+	fs.Var(&redactPatterns, "redact-pattern", "extra regular expression to redact (repeatable, implies -redact)")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: sidecar export [options]\n\n")
+		fmt.Fprintf(fs.Output(), "Export agent sessions as training-data JSONL.\n\n")
+		fmt.Fprintf(fs.Output(), "Options:\n")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+		return 2
+	}
+
+	fmtName, err := dataset.ParseFormat(*format)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "sidecar export: %v\n", err)
+		return 2
+	}
+
+	meta, err := sessionmeta.Open(sessionmeta.DefaultPath())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "sidecar export: session metadata: %v\n", err)
+		return 1
+	}
+
+	now := time.Now()
+	filter := dataset.Filter{
+		SessionIDs: sessionIDs,
+		Adapters:   adapters,
+		Models:     models,
+		Tags:       sessionmeta.ParseTags(strings.Join(tags, ",")),
+		TagSource:  meta,
+	}
+	if filter.Since, err = timebound.Parse(*since, now); err != nil {
+		fmt.Fprintf(os.Stderr, "sidecar export: -since: %v\n", err)
+		return 2
+	}
+	if filter.Until, err = timebound.ParseUntil(*until, now); err != nil {
+		fmt.Fprintf(os.Stderr, "sidecar export: -until: %v\n", err)
+		return 2
+	}
+
+	opts := dataset.Options{
+		Format:          fmtName,
+		Filter:          filter,
+		IncludeMetadata: *metadata,
+		Annotations:     meta,
+		Convert: dataset.ConvertOptions{
+			SystemPrompt:    *system,
+			IncludeThinking: *thinking,
+		},
+	}
+	if *redact || len(redactPatterns) > 0 {
+		home, _ := os.UserHomeDir()
+		r, err := dataset.NewRedactor(dataset.RedactOptions{HomeDir: home, Patterns: redactPatterns})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "sidecar export: %v\n", err)
+			return 2
+		}
+		opts.Convert.Redactor = r
+	}
+
+	workDir, err := filepath.Abs(*project)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "sidecar export: resolve project: %v\n", err)
+		return 1
+	}
+	adapterMap, sessions := loadProjectSessions(workDir)
+
+	var w io.Writer = os.Stdout
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "sidecar export: %v\n", err)
+			return 1
+		}
+		defer func() { _ = f.Close() }()
+		w = f
+	}
+	bw := bufio.NewWriter(w)
+	stats, err := dataset.Export(bw, adapterMap, sessions, opts)
+	if flushErr := bw.Flush(); err == nil {
+		err = flushErr
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "sidecar export: %v\n", err)
+		return 1
+	}
+
+	fmt.Fprintf(os.Stderr, "exported %d session(s)", stats.Written)
+	if stats.Skipped > 0 {
+		fmt.Fprintf(os.Stderr, ", skipped %d", stats.Skipped)
+	}
+	if stats.Failed > 0 {
+		fmt.Fprintf(os.Stderr, ", %d failed to load", stats.Failed)
+	}
+	fmt.Fprintln(os.Stderr)
+	return 0
+}
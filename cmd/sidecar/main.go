@@ -84,6 +84,11 @@ func main() {
 		os.Exit(0)
 	}
 
+	// Handle non-interactive subcommands
+	if flag.NArg() > 0 {
+		os.Exit(runSubcommand(flag.Arg(0), flag.Args()[1:]))
+	}
+
 	// Setup logging to file (never to stderr - it leaks through TUI)
 	logLevel := slog.LevelInfo
 	if *debugFlag {
@@ -277,13 +282,35 @@ func effectiveVersion(v string) string {
 func init() {
 	// Customize usage output
 	flag.Usage = func() {
-		fmt.Fprintf(os.Stderr, "Usage: sidecar [options]\n\n")
+		fmt.Fprintf(os.Stderr, "Usage: sidecar [options]\n")
+		fmt.Fprintf(os.Stderr, "       sidecar <command> [options]\n\n")
 		fmt.Fprintf(os.Stderr, "A TUI dashboard for AI coding agents.\n\n")
+		fmt.Fprintf(os.Stderr, "Commands:\n")
+		fmt.Fprintf(os.Stderr, "  export    Export sessions as training-data JSONL\n")
+		fmt.Fprintf(os.Stderr, "  sessions  List sessions with a filter query; manage saved filters\n\n")
 		fmt.Fprintf(os.Stderr, "Options:\n")
 		flag.PrintDefaults()
 	}
 }
 
+// runSubcommand dispatches a CLI subcommand and returns the exit code.
+func runSubcommand(name string, args []string) int {
+	switch name {
+	case "export":
+		return runExport(args)
+	case "sessions":
+		return runSessions(args)
+	case "agent-hook":
+		return runAgentHook(args)
+	case "transcript":
+		return runTranscript(args)
+	default:
+		fmt.Fprintf(os.Stderr, "sidecar: unknown command %q\n\n", name)
+		flag.Usage()
+		return 2
+	}
+}
+
 // openLogFile creates/opens the debug log file in config directory.
 func openLogFile() (*os.File, error) {
 	logPath := filepath.Join(filepath.Dir(config.ConfigPath()), "debug.log")
@@ -0,0 +1,326 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/marcus/sidecar/internal/adapter"
+	"github.com/marcus/sidecar/internal/app"
+	"github.com/marcus/sidecar/internal/config"
+	"github.com/marcus/sidecar/internal/sessionmeta"
+	"github.com/marcus/sidecar/internal/sessionquery"
+)
+
+// stringList is a repeatable flag that also accepts comma-separated values.
+type stringList []string
+
+func (s *stringList) String() string { return strings.Join(*s, ",") }
+
+func (s *stringList) Set(v string) error {
+	for _, part := range strings.Split(v, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			*s = append(*s, part)
+		}
+	}
+	return nil
+}
+
+// loadProjectSessions detects adapters for the project and loads sessions
+// from the main worktree and every linked worktree, mirroring what the
+// conversations plugin shows. Sessions are sorted newest first.
+func loadProjectSessions(workDir string) (map[string]adapter.Adapter, []adapter.Session) {
+	adapters := make(map[string]adapter.Adapter)
+	for id, a := range adapter.AllAdapters() {
+		if found, err := a.Detect(workDir); err == nil && found {
+			adapters[id] = a
+		}
+	}
+
+	paths := app.GetAllRelatedPaths(workDir)
+	if len(paths) == 0 {
+		paths = []string{workDir}
+	}
+	currentPath := workDir
+	if abs, err := filepath.Abs(workDir); err == nil {
+		currentPath = abs
+	}
+
+	seen := make(map[string]bool)
+	var sessions []adapter.Session
+	for id, a := range adapters {
+		for _, wtPath := range paths {
+			wtSessions, err := a.Sessions(wtPath)
+			if err != nil {
+				continue
+			}
+			for _, s := range wtSessions {
+				if s.AdapterID == "" {
+					s.AdapterID = id
+				}
+				if s.AdapterName == "" {
+					s.AdapterName = a.Name()
+				}
+				key := s.AdapterID + "/" + s.ID
+				if seen[key] {
+					continue
+				}
+				seen[key] = true
+				if wtPath != currentPath {
+					s.WorktreePath = wtPath
+					s.WorktreeName = app.WorktreeNameForPath(workDir, wtPath)
+					if s.WorktreeName == "" {
+						s.WorktreeName = filepath.Base(wtPath)
+					}
+				}
+				sessions = append(sessions, s)
+			}
+		}
+	}
+
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].UpdatedAt.After(sessions[j].UpdatedAt)
+	})
+	return adapters, sessions
+}
+
+// runSessions implements `sidecar sessions`, which lists sessions matching a
+// filter query and manages saved filters.
+func runSessions(args []string) int {
+	if len(args) == 0 {
+		sessionsUsage(os.Stderr)
+		return 2
+	}
+	switch args[0] {
+	case "list", "ls":
+		return runSessionsList(args[1:])
+	case "filters":
+		return runSessionsFilters()
+	case "save-filter":
+		return runSessionsSaveFilter(args[1:])
+	case "delete-filter":
+		return runSessionsDeleteFilter(args[1:])
+	case "-h", "-help", "--help", "help":
+		sessionsUsage(os.Stdout)
+		return 0
+	default:
+		fmt.Fprintf(os.Stderr, "sidecar sessions: unknown command %q\n\n", args[0])
+		sessionsUsage(os.Stderr)
+		return 2
+	}
+}
+
+func sessionsUsage(w io.Writer) {
+	fmt.Fprintf(w, "Usage: sidecar sessions <command> [options]\n\n")
+	fmt.Fprintf(w, "Commands:\n")
+	fmt.Fprintf(w, "  list [-json] [-limit N] [QUERY...]  List sessions matching a filter query\n")
+	fmt.Fprintf(w, "  filters                            List saved filters\n")
+	fmt.Fprintf(w, "  save-filter NAME QUERY...          Save a query for reuse as @NAME\n")
+	fmt.Fprintf(w, "  delete-filter NAME                 Delete a saved filter\n\n")
+	fmt.Fprintf(w, "Query terms (all must match; prefix with - to negate):\n")
+	fmt.Fprintf(w, "  adapter:codex model:opus worktree:feature-x name:text category:cron\n")
+	fmt.Fprintf(w, "  file:internal/app/*.go tool:Bash tag:bug has:error|tools|files|tags|note\n")
+	fmt.Fprintf(w, "  is:active|starred|subagent cost>2 tokens>100k messages>=10 duration>30m\n")
+	fmt.Fprintf(w, "  since:7d until:2026-01-31 @saved-filter \"bare words\"\n")
+}
+
+// sessionListEntry is the JSON shape of one `sessions list -json` row.
+type sessionListEntry struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name,omitempty"`
+	Adapter   string    `json:"adapter"`
+	Worktree  string    `json:"worktree,omitempty"`
+	UpdatedAt time.Time `json:"updatedAt"`
+	Cost      float64   `json:"cost,omitempty"`
+	Tokens    int       `json:"tokens,omitempty"`
+	Messages  int       `json:"messages,omitempty"`
+	Active    bool      `json:"active,omitempty"`
+	Starred   bool      `json:"starred,omitempty"`
+	Tags      []string  `json:"tags,omitempty"`
+}
+
+func runSessionsList(args []string) int {
+	fs := flag.NewFlagSet("sessions list", flag.ContinueOnError)
+	project := fs.String("project", *projectRoot, "project root directory")
+	asJSON := fs.Bool("json", false, "print sessions as a JSON array")
+	limit := fs.Int("limit", 0, "print at most N sessions (0 = all)")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: sidecar sessions list [options] [QUERY...]\n\n")
+		fmt.Fprintf(fs.Output(), "List sessions matching a filter query, newest first.\n\n")
+		fmt.Fprintf(fs.Output(), "Options:\n")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+		return 2
+	}
+
+	cfg, err := loadConfig(*configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "sidecar sessions: config: %v\n", err)
+		return 1
+	}
+	q, err := sessionquery.Parse(strings.Join(fs.Args(), " "), cfg.Plugins.Conversations.SavedFilters)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "sidecar sessions: query: %v\n", err)
+		return 2
+	}
+	meta, err := sessionmeta.Open(sessionmeta.DefaultPath())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "sidecar sessions: session metadata: %v\n", err)
+		return 1
+	}
+
+	workDir, err := filepath.Abs(*project)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "sidecar sessions: resolve project: %v\n", err)
+		return 1
+	}
+	adapterMap, sessions := loadProjectSessions(workDir)
+
+	var matched []sessionListEntry
+	for _, s := range sessions {
+		sub := sessionquery.Subject{Session: s, Meta: meta.Get(s.AdapterID, s.ID)}
+		// Messages are only loaded when a term needs them.
+		if q.NeedsDetails() {
+			if a := adapterMap[s.AdapterID]; a != nil {
+				if msgs, err := a.Messages(s.ID); err == nil {
+					sub.Details = sessionquery.DetailsFromMessages(msgs)
+				}
+			}
+		}
+		if !q.Match(sub) {
+			continue
+		}
+		matched = append(matched, sessionListEntry{
+			ID:        s.ID,
+			Name:      s.Name,
+			Adapter:   s.AdapterID,
+			Worktree:  s.WorktreeName,
+			UpdatedAt: s.UpdatedAt,
+			Cost:      s.EstCost,
+			Tokens:    s.TotalTokens,
+			Messages:  s.MessageCount,
+			Active:    s.IsActive,
+			Starred:   sub.Meta.Starred,
+			Tags:      sub.Meta.Tags,
+		})
+		if *limit > 0 && len(matched) >= *limit {
+			break
+		}
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if matched == nil {
+			matched = []sessionListEntry{}
+		}
+		if err := enc.Encode(matched); err != nil {
+			fmt.Fprintf(os.Stderr, "sidecar sessions: %v\n", err)
+			return 1
+		}
+		return 0
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "UPDATED\tADAPTER\tWORKTREE\tCOST\tNAME\tID")
+	for _, e := range matched {
+		cost := "-"
+		if e.Cost > 0 {
+			cost = fmt.Sprintf("$%.2f", e.Cost)
+		}
+		name := e.Name
+		if r := []rune(name); len(r) > 60 {
+			name = string(r[:59]) + "…"
+		}
+		if e.Starred {
+			name = "★ " + name
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			e.UpdatedAt.Local().Format("2006-01-02 15:04"), e.Adapter, e.Worktree, cost, name, e.ID)
+	}
+	if err := tw.Flush(); err != nil {
+		return 1
+	}
+	fmt.Fprintf(os.Stderr, "%d of %d session(s)\n", len(matched), len(sessions))
+	return 0
+}
+
+func runSessionsFilters() int {
+	cfg, err := loadConfig(*configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "sidecar sessions: config: %v\n", err)
+		return 1
+	}
+	saved := cfg.Plugins.Conversations.SavedFilters
+	names := make([]string, 0, len(saved))
+	for name := range saved {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Printf("@%s\t%s\n", name, saved[name])
+	}
+	return 0
+}
+
+func runSessionsSaveFilter(args []string) int {
+	if len(args) < 2 {
+		fmt.Fprintf(os.Stderr, "Usage: sidecar sessions save-filter NAME QUERY...\n")
+		return 2
+	}
+	name := strings.TrimPrefix(args[0], "@")
+	query := strings.Join(args[1:], " ")
+	cfg, err := loadConfig(*configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "sidecar sessions: config: %v\n", err)
+		return 1
+	}
+	if _, err := sessionquery.Parse(query, cfg.Plugins.Conversations.SavedFilters); err != nil {
+		fmt.Fprintf(os.Stderr, "sidecar sessions: query: %v\n", err)
+		return 2
+	}
+	if cfg.Plugins.Conversations.SavedFilters == nil {
+		cfg.Plugins.Conversations.SavedFilters = make(map[string]string)
+	}
+	cfg.Plugins.Conversations.SavedFilters[name] = query
+	if err := config.Save(cfg); err != nil {
+		fmt.Fprintf(os.Stderr, "sidecar sessions: save config: %v\n", err)
+		return 1
+	}
+	fmt.Fprintf(os.Stderr, "saved @%s\n", name)
+	return 0
+}
+
+func runSessionsDeleteFilter(args []string) int {
+	if len(args) != 1 {
+		fmt.Fprintf(os.Stderr, "Usage: sidecar sessions delete-filter NAME\n")
+		return 2
+	}
+	name := strings.TrimPrefix(args[0], "@")
+	cfg, err := loadConfig(*configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "sidecar sessions: config: %v\n", err)
+		return 1
+	}
+	if _, ok := cfg.Plugins.Conversations.SavedFilters[name]; !ok {
+		fmt.Fprintf(os.Stderr, "sidecar sessions: no saved filter @%s\n", name)
+		return 1
+	}
+	delete(cfg.Plugins.Conversations.SavedFilters, name)
+	if err := config.Save(cfg); err != nil {
+		fmt.Fprintf(os.Stderr, "sidecar sessions: save config: %v\n", err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/marcus/sidecar/internal/config"
+)
+
+func TestSessionsSaveFilterUsesConfigFlag(t *testing.T) {
+	dir := t.TempDir()
+	defaultPath := filepath.Join(dir, "default.json")
+	customPath := filepath.Join(dir, "custom.json")
+	config.SetTestConfigPath(defaultPath)
+	defer config.ResetTestConfigPath()
+	if err := os.WriteFile(customPath, []byte(`{"prompts":[]}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	old := *configPath
+	*configPath = customPath
+	defer func() { *configPath = old }()
+
+	if code := runSessionsSaveFilter([]string{"pricey", "cost>5"}); code != 0 {
+		t.Fatalf("save-filter exit code %d", code)
+	}
+	cfg, err := config.LoadFrom(customPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Plugins.Conversations.SavedFilters["pricey"] != "cost>5" {
+		t.Errorf("saved filters in -config file = %v", cfg.Plugins.Conversations.SavedFilters)
+	}
+	if _, err := os.Stat(defaultPath); !os.IsNotExist(err) {
+		t.Errorf("default config was written (stat err %v)", err)
+	}
+
+	if code := runSessionsDeleteFilter([]string{"@pricey"}); code != 0 {
+		t.Fatalf("delete-filter exit code %d", code)
+	}
+	if cfg, err = config.LoadFrom(customPath); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := cfg.Plugins.Conversations.SavedFilters["pricey"]; ok {
+		t.Error("delete-filter left the filter in the -config file")
+	}
+	if _, err := os.Stat(defaultPath); !os.IsNotExist(err) {
+		t.Errorf("default config was written (stat err %v)", err)
+	}
+}
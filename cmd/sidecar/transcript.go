@@ -0,0 +1,54 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/marcus/sidecar/internal/transcript"
+)
+
+// runTranscript implements `sidecar transcript`, the command tmux pipes a
+// pane's output to when transcript logging is on. It copies stdin into the
+// session's rotating logs until the pane closes. Like agent-hook it must never
+// disturb the session, so failures are reported on stderr but always exit 0.
+func runTranscript(args []string) int {
+	fs := flag.NewFlagSet("transcript", flag.ContinueOnError)
+	dir := fs.String("dir", "", "directory to write the logs to")
+	maxBytes := fs.Int64("max-bytes", transcript.DefaultMaxBytes, "size at which a log is rotated")
+	keep := fs.Int("keep", transcript.DefaultKeep, "rotated files to keep per log")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: sidecar transcript -dir DIR [-max-bytes N] [-keep N]\n\n")
+		fmt.Fprintf(fs.Output(), "Record terminal output from stdin to raw and plain-text logs.\n")
+		fmt.Fprintf(fs.Output(), "Started by the workspace plugin through tmux pipe-pane.\n\n")
+		fmt.Fprintf(fs.Output(), "Options:\n")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		// The flag package has already written the error to stderr. Drain
+		// the pipe so tmux doesn't block on a full buffer.
+		_, _ = io.Copy(io.Discard, os.Stdin)
+		return 0
+	}
+	if *dir == "" {
+		fmt.Fprintln(os.Stderr, "sidecar transcript: -dir is required")
+		_, _ = io.Copy(io.Discard, os.Stdin)
+		return 0
+	}
+
+	w, err := transcript.NewWriter(*dir, *maxBytes, *keep)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "sidecar transcript: %v\n", err)
+		// Drain the pipe so tmux doesn't block on a full buffer
+		_, _ = io.Copy(io.Discard, os.Stdin)
+		return 0
+	}
+	if _, err := io.Copy(w, os.Stdin); err != nil {
+		fmt.Fprintf(os.Stderr, "sidecar transcript: %v\n", err)
+	}
+	if err := w.Close(); err != nil {
+		fmt.Fprintf(os.Stderr, "sidecar transcript: %v\n", err)
+	}
+	return 0
+}
@@ -166,8 +166,20 @@ type Message struct {
 	ThinkingBlocks []ThinkingBlock
 	ContentBlocks  []ContentBlock // Structured content for rich display
 	SourceLabel    string         // Channel badge, e.g. "[TG] Marcus Vorwaller", "[WA]", "[cron] job-name"
+	Event          string         // Session event this message marks (EventCompaction, EventSummary), "" if none
 }
 
+// Session events adapters can attach to a message via Message.Event.
+const (
+	// EventCompaction marks the first message after the agent compacted its
+	// context window, automatically or on request (e.g. /compact).
+	EventCompaction = "compaction"
+	// EventSummary marks a message carrying a summary that replaced earlier
+	// context; like a compaction it starts a fresh context window. Claude
+	// Code sets it on the isCompactSummary message written by a compaction.
+	EventSummary = "summary"
+)
+
 // TokenUsage tracks token counts for a message or session.
 type TokenUsage struct {
 	InputTokens  int
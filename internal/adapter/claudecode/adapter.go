@@ -455,6 +455,9 @@ func (a *Adapter) parseMessageLine(line []byte) (adapter.Message, string, bool)
 		Timestamp: raw.Timestamp,
 		Model:     raw.Message.Model,
 	}
+	if raw.IsCompactSummary {
+		msg.Event = adapter.EventSummary
+	}
 
 	content, toolUses, thinkingBlocks, contentBlocks := a.parseContentWithResults(raw.Message.Content, nil)
 	msg.Content = content
@@ -118,6 +118,30 @@ func TestParseContent_ThinkingBlock(t *testing.T) {
 	}
 }
 
+func TestParseMessageLine_CompactSummary(t *testing.T) {
+	a := &Adapter{}
+
+	// The compact_boundary system line carries no message and is skipped
+	boundary := []byte(`{"type":"system","subtype":"compact_boundary","uuid":"b1","content":"Conversation compacted","timestamp":"2025-01-01T00:00:00Z"}`)
+	if _, _, ok := a.parseMessageLine(boundary); ok {
+		t.Error("compact_boundary line should not produce a message")
+	}
+
+	summary := []byte(`{"type":"user","uuid":"s1","isCompactSummary":true,"timestamp":"2025-01-01T00:00:01Z","message":{"role":"user","content":"This session is being continued from a previous conversation."}}`)
+	msg, msgType, ok := a.parseMessageLine(summary)
+	if !ok || msgType != "user" {
+		t.Fatalf("parseMessageLine(summary) = %q, %v", msgType, ok)
+	}
+	if msg.Event != adapter.EventSummary {
+		t.Errorf("summary Event = %q, want %q", msg.Event, adapter.EventSummary)
+	}
+
+	plain := []byte(`{"type":"user","uuid":"u1","timestamp":"2025-01-01T00:00:02Z","message":{"role":"user","content":"next"}}`)
+	if msg, _, _ := a.parseMessageLine(plain); msg.Event != "" {
+		t.Errorf("plain message Event = %q, want none", msg.Event)
+	}
+}
+
 func TestParseSessionMetadata_ValidFile(t *testing.T) {
 	a := &Adapter{}
 	testFile := filepath.Join("testdata", "valid_session.jsonl")
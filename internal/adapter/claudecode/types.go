@@ -17,6 +17,10 @@ type RawMessage struct {
 	Version    string          `json:"version,omitempty"`
 	GitBranch  string          `json:"gitBranch,omitempty"`
 	Slug       string          `json:"slug,omitempty"`
+
+	// IsCompactSummary flags the user message carrying the summary that
+	// replaced the context when it was compacted.
+	IsCompactSummary bool `json:"isCompactSummary,omitempty"`
 }
 
 // MessageContent holds the actual message data.
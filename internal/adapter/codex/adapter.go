@@ -352,6 +352,7 @@ type parseState struct {
 	totalUsage      *TokenUsage
 	currentModel    string
 	lastTimestamp   time.Time
+	pendingEvent    string // event to attach to the next message (e.g. compaction)
 }
 
 func newParseState(sessionID string) *parseState {
@@ -396,6 +397,11 @@ func (a *Adapter) processMessageRecord(line []byte, state *parseState) {
 	}
 
 	switch record.Type {
+	case "compacted":
+		// Context was compacted; the summary replaces earlier history.
+		state.flushPending()
+		state.pendingEvent = adapter.EventCompaction
+
 	case "turn_context":
 		var payload TurnContextPayload
 		if err := json.Unmarshal(record.Payload, &payload); err == nil && payload.Model != "" {
@@ -427,7 +433,9 @@ func (a *Adapter) processMessageRecord(line []byte, state *parseState) {
 				Content:   content,
 				Timestamp: record.Timestamp,
 				Model:     state.currentModel,
+				Event:     state.pendingEvent,
 			}
+			state.pendingEvent = ""
 			if msg.Role == "assistant" {
 				message.ToolUses = append(message.ToolUses, state.pendingTools...)
 				message.ThinkingBlocks = append(message.ThinkingBlocks, state.pendingThinking...)
@@ -4,6 +4,8 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+
+	"github.com/marcus/sidecar/internal/adapter"
 )
 
 func TestMessagesAndUsage(t *testing.T) {
@@ -86,3 +88,39 @@ func TestMessagesAndUsage(t *testing.T) {
 		t.Fatalf("usage MessageCount = %d, want 4", usage.MessageCount)
 	}
 }
+
+func TestMessagesCompaction(t *testing.T) {
+	root := t.TempDir()
+	sessionsDir := filepath.Join(root, "sessions")
+	path := filepath.Join(sessionsDir, "2025", "11", "20")
+	if err := os.MkdirAll(path, 0o755); err != nil {
+		t.Fatalf("mkdir sessions: %v", err)
+	}
+
+	lines := []string{
+		`{"timestamp":"2025-11-21T04:13:55.791Z","type":"session_meta","payload":{"id":"id-c","timestamp":"2025-11-21T04:13:55.777Z","cwd":"` + root + `"}}`,
+		`{"timestamp":"2025-11-21T04:14:00.000Z","type":"response_item","payload":{"type":"message","role":"user","content":[{"type":"input_text","text":"hello"}]}}`,
+		`{"timestamp":"2025-11-21T04:14:01.000Z","type":"compacted","payload":{"message":"summary of earlier work"}}`,
+		`{"timestamp":"2025-11-21T04:14:02.000Z","type":"response_item","payload":{"type":"message","role":"user","content":[{"type":"input_text","text":"continue"}]}}`,
+	}
+	if err := writeSessionFile(filepath.Join(path, "rollout-c.jsonl"), lines); err != nil {
+		t.Fatalf("write session file: %v", err)
+	}
+
+	a := New()
+	a.sessionsDir = sessionsDir
+
+	messages, err := a.Messages("id-c")
+	if err != nil {
+		t.Fatalf("Messages error: %v", err)
+	}
+	if len(messages) != 2 {
+		t.Fatalf("Messages() = %d, want 2", len(messages))
+	}
+	if messages[0].Event != "" {
+		t.Errorf("first message Event = %q, want none", messages[0].Event)
+	}
+	if messages[1].Event != adapter.EventCompaction {
+		t.Errorf("message after compaction Event = %q, want %q", messages[1].Event, adapter.EventCompaction)
+	}
+}
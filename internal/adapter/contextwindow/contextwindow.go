@@ -0,0 +1,170 @@
+// Package contextwindow estimates how full a model's context window is from
+// the per-message token usage reported by adapters.
+package contextwindow
+
+import (
+	"strings"
+	"time"
+
+	"github.com/marcus/sidecar/internal/adapter"
+)
+
+// DefaultSize is assumed for models missing from the size table.
+const DefaultSize = 200_000
+
+// sizeEntry maps a model name fragment to its context window in tokens.
+type sizeEntry struct {
+	match string
+	size  int
+}
+
+// sizes is checked in order; more specific fragments come first.
+var sizes = []sizeEntry{
+	{"gpt-4.1", 1_047_576},
+	{"gpt-5", 272_000},
+	{"codex", 272_000},
+	{"gpt-4o", 128_000},
+	{"gpt-4", 128_000},
+	{"gemini", 1_048_576},
+	{"opus", 200_000},
+	{"sonnet", 200_000},
+	{"haiku", 200_000},
+	{"claude", 200_000},
+}
+
+// Size returns the context window in tokens for model.
+func Size(model string) int {
+	lower := strings.ToLower(model)
+	for _, e := range sizes {
+		if strings.Contains(lower, e.match) {
+			return e.size
+		}
+	}
+	return DefaultSize
+}
+
+// openAIStyle reports whether model reports cached tokens as part of
+// InputTokens (OpenAI/Codex) rather than separately (Anthropic).
+func openAIStyle(model string) bool {
+	lower := strings.ToLower(model)
+	if strings.HasPrefix(lower, "gpt-") || strings.Contains(lower, "codex") {
+		return true
+	}
+	return len(lower) > 1 && lower[0] == 'o' && lower[1] >= '1' && lower[1] <= '9'
+}
+
+// Used returns how many tokens of the context window a request with usage u
+// occupied, including the tokens it generated.
+func Used(model string, u adapter.TokenUsage) int {
+	if openAIStyle(model) {
+		return u.InputTokens + u.OutputTokens
+	}
+	return u.InputTokens + u.CacheRead + u.CacheWrite + u.OutputTokens
+}
+
+// Point is the context fill after one message.
+type Point struct {
+	MessageID string
+	Time      time.Time
+	Used      int
+	Size      int
+	Event     string // adapter.EventCompaction or adapter.EventSummary, "" otherwise
+}
+
+// Percent returns the fill of the context window, 0-100.
+func (p Point) Percent() float64 {
+	if p.Size <= 0 {
+		return 0
+	}
+	pct := float64(p.Used) * 100 / float64(p.Size)
+	if pct > 100 {
+		return 100
+	}
+	return pct
+}
+
+// Gauge summarizes context usage over a session.
+type Gauge struct {
+	Current     Point   // last measured point
+	Peak        Point   // highest fill seen
+	Compactions int     // compaction and summary events
+	Points      []Point // fill over time, oldest first
+
+	model string // last model seen, carried across Add calls
+}
+
+// IsEmpty reports whether no message carried usage or events.
+func (g Gauge) IsEmpty() bool {
+	return len(g.Points) == 0
+}
+
+// IsCompaction reports whether event starts a fresh context window.
+func IsCompaction(event string) bool {
+	return event == adapter.EventCompaction || event == adapter.EventSummary
+}
+
+// Measure builds a gauge from a session's messages. Messages without usage
+// are skipped unless they mark a compaction, which is recorded as a point
+// with no usage so the timeline shows the drop.
+func Measure(msgs []adapter.Message) Gauge {
+	var g Gauge
+	g.Add(msgs)
+	return g
+}
+
+// Add extends the gauge with messages that follow those already measured.
+func (g *Gauge) Add(msgs []adapter.Message) {
+	for _, m := range msgs {
+		if m.Model != "" {
+			g.model = m.Model
+		}
+		used := Used(g.model, m.TokenUsage)
+		compaction := IsCompaction(m.Event)
+		if used == 0 && !compaction {
+			continue
+		}
+		pt := Point{MessageID: m.ID, Time: m.Timestamp, Used: used, Size: Size(g.model), Event: m.Event}
+		if compaction {
+			g.Compactions++
+		}
+		g.Points = append(g.Points, pt)
+		g.Current = pt
+		if pt.Percent() > g.Peak.Percent() {
+			g.Peak = pt
+		}
+	}
+}
+
+// sparkBlocks are the sparkline levels, lowest first.
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// Sparkline renders fill over time in at most width cells, bucketing points
+// when there are more points than cells.
+func (g Gauge) Sparkline(width int) string {
+	if width <= 0 || len(g.Points) == 0 {
+		return ""
+	}
+	pts := g.Points
+	if len(pts) > width {
+		step := float64(len(pts)) / float64(width)
+		sampled := make([]Point, width)
+		for i := range sampled {
+			// Take the highest fill in each bucket so spikes stay visible.
+			lo, hi := int(float64(i)*step), int(float64(i+1)*step)
+			best := pts[lo]
+			for _, p := range pts[lo:hi] {
+				if p.Used > best.Used {
+					best = p
+				}
+			}
+			sampled[i] = best
+		}
+		pts = sampled
+	}
+	var sb strings.Builder
+	for _, p := range pts {
+		idx := int(p.Percent() / 100 * float64(len(sparkBlocks)-1))
+		sb.WriteRune(sparkBlocks[idx])
+	}
+	return sb.String()
+}
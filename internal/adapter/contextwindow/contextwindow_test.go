@@ -0,0 +1,86 @@
+package contextwindow
+
+import (
+	"testing"
+
+	"github.com/marcus/sidecar/internal/adapter"
+)
+
+func TestSize(t *testing.T) {
+	tests := []struct {
+		model string
+		want  int
+	}{
+		{"claude-opus-4-5-20251101", 200_000},
+		{"gpt-5-codex", 272_000},
+		{"gpt-4.1-mini", 1_047_576},
+		{"gpt-4o", 128_000},
+		{"gemini-2.5-pro", 1_048_576},
+		{"something-new", DefaultSize},
+	}
+	for _, tt := range tests {
+		if got := Size(tt.model); got != tt.want {
+			t.Errorf("Size(%q) = %d, want %d", tt.model, got, tt.want)
+		}
+	}
+}
+
+func TestUsed(t *testing.T) {
+	u := adapter.TokenUsage{InputTokens: 100, OutputTokens: 50, CacheRead: 1000, CacheWrite: 200}
+	if got := Used("claude-sonnet-4-5", u); got != 1350 {
+		t.Errorf("anthropic Used = %d, want 1350", got)
+	}
+	// OpenAI-style input already includes cached tokens.
+	if got := Used("gpt-5-codex", u); got != 150 {
+		t.Errorf("openai Used = %d, want 150", got)
+	}
+	if got := Used("o3", u); got != 150 {
+		t.Errorf("o-series Used = %d, want 150", got)
+	}
+}
+
+func TestMeasure(t *testing.T) {
+	model := "claude-sonnet-4-5"
+	msgs := []adapter.Message{
+		{ID: "1", Role: "user"},
+		{ID: "2", Role: "assistant", Model: model, TokenUsage: adapter.TokenUsage{CacheRead: 100_000}},
+		{ID: "3", Role: "assistant", Model: model, TokenUsage: adapter.TokenUsage{CacheRead: 180_000}},
+		{ID: "4", Role: "user", Event: adapter.EventSummary},
+		{ID: "5", Role: "assistant", Model: model, TokenUsage: adapter.TokenUsage{InputTokens: 20_000}},
+	}
+	g := Measure(msgs)
+	if len(g.Points) != 4 {
+		t.Fatalf("expected 4 points, got %d", len(g.Points))
+	}
+	if g.Compactions != 1 {
+		t.Errorf("Compactions = %d, want 1", g.Compactions)
+	}
+	if g.Peak.MessageID != "3" || g.Peak.Percent() != 90 {
+		t.Errorf("Peak = %+v (%.0f%%)", g.Peak, g.Peak.Percent())
+	}
+	if g.Current.MessageID != "5" || g.Current.Percent() != 10 {
+		t.Errorf("Current = %+v (%.0f%%)", g.Current, g.Current.Percent())
+	}
+	if got := g.Sparkline(10); got != "▄▇▁▁" {
+		t.Errorf("Sparkline(10) = %q", got)
+	}
+	if got := []rune(g.Sparkline(2)); len(got) != 2 || got[0] != '▇' {
+		t.Errorf("Sparkline(2) = %q", string(got))
+	}
+	if !Measure(nil).IsEmpty() {
+		t.Error("Measure(nil) should be empty")
+	}
+}
+
+func TestGaugeAdd(t *testing.T) {
+	msgs := []adapter.Message{
+		{ID: "1", Model: "gpt-5-codex", TokenUsage: adapter.TokenUsage{InputTokens: 27_200}},
+		{ID: "2", TokenUsage: adapter.TokenUsage{InputTokens: 54_400, CacheRead: 50_000}},
+	}
+	g := Measure(msgs[:1])
+	g.Add(msgs[1:])
+	// The model carries over, so cached tokens are not double counted.
+	if g.Current.Percent() != 20 {
+		t.Errorf("Current = %.1f%%, want 20%%", g.Current.Percent())
+	}
+}
@@ -404,6 +404,9 @@ func (a *Adapter) messagesJSON(sessionID string) ([]adapter.Message, error) {
 			ToolUses:       parts.toolUses,
 			ThinkingBlocks: parts.thinkingBlocks,
 		}
+		if parts.compaction {
+			adapterMsg.Event = adapter.EventCompaction
+		}
 
 		// Add token usage
 		if msg.Tokens != nil {
@@ -674,6 +677,8 @@ func appendParsedPart(parts parsedParts, part Part) parsedParts {
 		}
 	case "patch":
 		parts.patchFiles = append(parts.patchFiles, part.Files...)
+	case "compaction":
+		parts.compaction = true
 	}
 	return parts
 }
@@ -806,6 +811,9 @@ func (a *Adapter) messagesSQLite(sessionID string) ([]adapter.Message, error) {
 			ToolUses:       parts.toolUses,
 			ThinkingBlocks: parts.thinkingBlocks,
 		}
+		if parts.compaction {
+			adapterMsg.Event = adapter.EventCompaction
+		}
 		if adapterMsg.Timestamp.IsZero() && row.CreatedMS > 0 {
 			adapterMsg.Timestamp = time.UnixMilli(row.CreatedMS).Local()
 		}
@@ -1144,6 +1152,7 @@ type parsedParts struct {
 	thinkingBlocks []adapter.ThinkingBlock
 	fileRefs       []string
 	patchFiles     []string
+	compaction     bool // message carries a compaction part
 }
 
 // batchReadMessages reads all message files from a directory and parses them.
@@ -1203,6 +1212,7 @@ func (a *Adapter) batchLoadAllParts(messageIDs []string) map[string]parsedParts
 		var thinkingBlocks []adapter.ThinkingBlock
 		var fileRefs []string
 		var patchFiles []string
+		var compaction bool
 
 		for _, e := range entries {
 			if !strings.HasSuffix(e.Name(), ".json") {
@@ -1241,6 +1251,8 @@ func (a *Adapter) batchLoadAllParts(messageIDs []string) map[string]parsedParts
 				}
 			case "patch":
 				patchFiles = append(patchFiles, part.Files...)
+			case "compaction":
+				compaction = true
 			}
 		}
 
@@ -1250,6 +1262,7 @@ func (a *Adapter) batchLoadAllParts(messageIDs []string) map[string]parsedParts
 			thinkingBlocks: thinkingBlocks,
 			fileRefs:       fileRefs,
 			patchFiles:     patchFiles,
+			compaction:     compaction,
 		}
 	}
 
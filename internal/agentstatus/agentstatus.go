@@ -0,0 +1,200 @@
+// Package agentstatus is the structured status channel between coding agents
+// and sidecar. Sidecar registers agent hooks (Claude Code hooks, Codex notify)
+// that run `sidecar agent-hook`, which translates the agent's hook payload
+// into an Event and writes it to a per-worktree status file. The workspace
+// plugin reads that file before falling back to session files and terminal
+// output heuristics.
+package agentstatus
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// FileName is the status file written in each worktree's state directory.
+const FileName = "agent-status.json"
+
+// Status values reported by hooks.
+const (
+	StatusActive  = "active"  // agent is working on a prompt
+	StatusWaiting = "waiting" // agent needs the user (e.g. permission prompt)
+	StatusDone    = "done"    // agent finished its turn and is idle
+)
+
+// Event is one status update written by an agent hook.
+type Event struct {
+	Status  string    `json:"status"`
+	Agent   string    `json:"agent"`             // "claude" or "codex"
+	Hook    string    `json:"hook"`              // hook or notification type that fired
+	Message string    `json:"message,omitempty"` // human-readable detail, e.g. the permission request
+	Tool    string    `json:"tool,omitempty"`    // tool about to run (PreToolUse) or awaiting permission
+	Target  string    `json:"target,omitempty"`  // the tool's command or file path
+	Time    time.Time `json:"time"`
+}
+
+// Write atomically replaces the status file at path with e.
+func Write(path string, e Event) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// Read returns the event stored at path.
+func Read(path string) (Event, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Event{}, err
+	}
+	var e Event
+	if err := json.Unmarshal(data, &e); err != nil {
+		return Event{}, fmt.Errorf("parse %s: %w", path, err)
+	}
+	if e.Status == "" {
+		return Event{}, fmt.Errorf("parse %s: missing status", path)
+	}
+	return e, nil
+}
+
+// claudeHookInput is the subset of the JSON Claude Code passes to hooks on stdin.
+type claudeHookInput struct {
+	HookEventName    string          `json:"hook_event_name"`
+	Message          string          `json:"message"`
+	NotificationType string          `json:"notification_type"`
+	ToolName         string          `json:"tool_name"`
+	ToolInput        json.RawMessage `json:"tool_input"`
+}
+
+// toolTarget extracts the command or file path from a tool_input object.
+func toolTarget(input json.RawMessage) string {
+	var fields map[string]any
+	if len(input) == 0 || json.Unmarshal(input, &fields) != nil {
+		return ""
+	}
+	for _, key := range []string{"command", "file_path", "notebook_path", "path"} {
+		if v, ok := fields[key].(string); ok && v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// FromClaudeHook converts a Claude Code hook payload into an event.
+// ok is false for hooks that carry no status change.
+func FromClaudeHook(input []byte) (Event, bool) {
+	var in claudeHookInput
+	if err := json.Unmarshal(input, &in); err != nil {
+		return Event{}, false
+	}
+	e := Event{Agent: "claude", Hook: in.HookEventName, Time: time.Now()}
+	switch in.HookEventName {
+	case "UserPromptSubmit", "PostToolUse":
+		e.Status = StatusActive
+	case "PreToolUse":
+		// Recorded so a following permission Notification, which only
+		// names the tool, can say what it is asking about.
+		e.Status = StatusActive
+		e.Tool = in.ToolName
+		e.Target = toolTarget(in.ToolInput)
+	case "Stop", "SessionEnd":
+		e.Status = StatusDone
+	case "Notification":
+		// Idle notifications fire after Stop; the agent is at its prompt.
+		// Older releases omit notification_type, so match the message too.
+		if in.NotificationType == "idle_prompt" ||
+			(in.NotificationType == "" && strings.Contains(in.Message, "waiting for your input")) {
+			e.Status = StatusDone
+			break
+		}
+		e.Status = StatusWaiting
+		e.Message = in.Message
+		if in.NotificationType != "" {
+			e.Hook += ":" + in.NotificationType
+		}
+	default:
+		return Event{}, false
+	}
+	return e, true
+}
+
+// InheritTool copies the tool call from prev, the event the status file
+// held before e, onto a permission prompt. Claude's permission Notification
+// only names the tool ("Claude needs your permission to use Bash"); the
+// command comes from the PreToolUse hook that fired just before it.
+func InheritTool(e, prev Event) Event {
+	if e.Status != StatusWaiting || e.Tool != "" || prev.Hook != "PreToolUse" || prev.Tool == "" {
+		return e
+	}
+	if !strings.Contains(strings.ToLower(e.Message), strings.ToLower(prev.Tool)) {
+		return e
+	}
+	e.Tool, e.Target = prev.Tool, prev.Target
+	return e
+}
+
+// codexNotifyInput is the JSON Codex passes as the last argument to its
+// notify program.
+type codexNotifyInput struct {
+	Type string `json:"type"`
+}
+
+// FromCodexNotify converts a Codex notify payload into an event. Codex only
+// notifies on turn completion; the active state comes from session files.
+func FromCodexNotify(arg string) (Event, bool) {
+	var in codexNotifyInput
+	if err := json.Unmarshal([]byte(arg), &in); err != nil {
+		return Event{}, false
+	}
+	e := Event{Agent: "codex", Hook: in.Type, Time: time.Now()}
+	switch in.Type {
+	case "agent-turn-complete":
+		e.Status = StatusDone
+	default:
+		return Event{}, false
+	}
+	return e, true
+}
+
+// claudeHookEvents are the Claude Code hook events sidecar registers.
+var claudeHookEvents = []string{"UserPromptSubmit", "PreToolUse", "PostToolUse", "Notification", "Stop", "SessionEnd"}
+
+// ClaudeSettings returns a Claude Code settings document, for use with
+// `claude --settings`, that runs hookCmd for every status-bearing hook.
+func ClaudeSettings(hookCmd string) ([]byte, error) {
+	type command struct {
+		Type    string `json:"type"`
+		Command string `json:"command"`
+	}
+	type matcher struct {
+		Matcher string    `json:"matcher,omitempty"`
+		Hooks   []command `json:"hooks"`
+	}
+	hooks := make(map[string][]matcher, len(claudeHookEvents))
+	for _, ev := range claudeHookEvents {
+		hooks[ev] = []matcher{{Hooks: []command{{Type: "command", Command: hookCmd}}}}
+	}
+	return json.MarshalIndent(map[string]any{"hooks": hooks}, "", "  ")
+}
+
+// CodexNotifyOverride returns a `codex -c` value setting notify to argv.
+func CodexNotifyOverride(argv []string) string {
+	quoted := make([]string, len(argv))
+	for i, a := range argv {
+		// TOML basic strings share JSON's escaping for the characters we emit.
+		b, _ := json.Marshal(a)
+		quoted[i] = string(b)
+	}
+	return "notify=[" + strings.Join(quoted, ",") + "]"
+}
@@ -0,0 +1,115 @@
+package agentstatus
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWriteRead(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", FileName)
+	want := Event{Status: StatusWaiting, Agent: "claude", Hook: "Notification", Message: "needs permission"}
+	if err := Write(path, want); err != nil {
+		t.Fatal(err)
+	}
+	got, err := Read(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Status != want.Status || got.Message != want.Message || got.Hook != want.Hook {
+		t.Errorf("Read() = %+v, want %+v", got, want)
+	}
+	if _, err := Read(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("expected error for missing file")
+	}
+}
+
+func TestFromClaudeHook(t *testing.T) {
+	tests := []struct {
+		input  string
+		status string
+		ok     bool
+	}{
+		{`{"hook_event_name":"UserPromptSubmit","prompt":"hi"}`, StatusActive, true},
+		{`{"hook_event_name":"PreToolUse","tool_name":"Bash"}`, StatusActive, true},
+		{`{"hook_event_name":"Stop"}`, StatusDone, true},
+		{`{"hook_event_name":"SessionEnd","reason":"prompt_input_exit"}`, StatusDone, true},
+		{`{"hook_event_name":"Notification","notification_type":"permission_prompt","message":"Claude needs your permission to use Bash"}`, StatusWaiting, true},
+		{`{"hook_event_name":"Notification","notification_type":"idle_prompt","message":"Claude is waiting for your input"}`, StatusDone, true},
+		{`{"hook_event_name":"Notification","message":"Claude is waiting for your input"}`, StatusDone, true},
+		{`{"hook_event_name":"SessionStart"}`, "", false},
+		{`not json`, "", false},
+	}
+	for _, tt := range tests {
+		e, ok := FromClaudeHook([]byte(tt.input))
+		if ok != tt.ok || e.Status != tt.status {
+			t.Errorf("FromClaudeHook(%s) = %q, %v; want %q, %v", tt.input, e.Status, ok, tt.status, tt.ok)
+		}
+	}
+	e, _ := FromClaudeHook([]byte(`{"hook_event_name":"Notification","notification_type":"permission_prompt","message":"Allow Bash?"}`))
+	if e.Message != "Allow Bash?" || e.Hook != "Notification:permission_prompt" {
+		t.Errorf("unexpected waiting event %+v", e)
+	}
+}
+
+func TestInheritTool(t *testing.T) {
+	pre, _ := FromClaudeHook([]byte(`{"hook_event_name":"PreToolUse","tool_name":"Bash","tool_input":{"command":"rm -rf /","description":"clean"}}`))
+	if pre.Tool != "Bash" || pre.Target != "rm -rf /" {
+		t.Fatalf("PreToolUse event = %+v", pre)
+	}
+	edit, _ := FromClaudeHook([]byte(`{"hook_event_name":"PreToolUse","tool_name":"Edit","tool_input":{"file_path":"/repo/a.go","old_string":"x"}}`))
+	if edit.Target != "/repo/a.go" {
+		t.Errorf("Edit target = %q", edit.Target)
+	}
+
+	ask, _ := FromClaudeHook([]byte(`{"hook_event_name":"Notification","notification_type":"permission_prompt","message":"Claude needs your permission to use Bash"}`))
+	if got := InheritTool(ask, pre); got.Tool != "Bash" || got.Target != "rm -rf /" {
+		t.Errorf("permission prompt should carry the pending command, got %+v", got)
+	}
+	if got := InheritTool(ask, edit); got.Tool != "" || got.Target != "" {
+		t.Errorf("prompt for another tool must not inherit, got %+v", got)
+	}
+	post, _ := FromClaudeHook([]byte(`{"hook_event_name":"PostToolUse","tool_name":"Bash"}`))
+	if got := InheritTool(ask, post); got.Target != "" {
+		t.Errorf("only PreToolUse carries a pending call, got %+v", got)
+	}
+	if got := InheritTool(pre, pre); got.Status != StatusActive {
+		t.Errorf("non-waiting events are unchanged, got %+v", got)
+	}
+}
+
+func TestFromCodexNotify(t *testing.T) {
+	if e, ok := FromCodexNotify(`{"type":"agent-turn-complete","turn-id":"1"}`); !ok || e.Status != StatusDone {
+		t.Errorf("turn complete = %+v, %v", e, ok)
+	}
+	if _, ok := FromCodexNotify(`{"type":"something-else"}`); ok {
+		t.Error("unknown notify type should be ignored")
+	}
+}
+
+func TestClaudeSettings(t *testing.T) {
+	data, err := ClaudeSettings("/usr/bin/sidecar agent-hook")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc struct {
+		Hooks map[string][]struct {
+			Hooks []struct{ Type, Command string }
+		}
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatal(err)
+	}
+	stop := doc.Hooks["Stop"]
+	if len(stop) != 1 || len(stop[0].Hooks) != 1 || stop[0].Hooks[0].Command != "/usr/bin/sidecar agent-hook" {
+		t.Errorf("unexpected Stop hooks: %+v", stop)
+	}
+}
+
+func TestCodexNotifyOverride(t *testing.T) {
+	got := CodexNotifyOverride([]string{"/bin/sidecar", "agent-hook", `a "b"`})
+	if !strings.HasPrefix(got, `notify=["/bin/sidecar","agent-hook",`) || !strings.Contains(got, `\"b\"`) {
+		t.Errorf("CodexNotifyOverride() = %s", got)
+	}
+}
@@ -80,6 +80,12 @@ type FocusPluginByIDMsg struct {
 	PluginID string
 }
 
+// FocusConversationMsg requests the conversations plugin open a session.
+// Sent alongside FocusPlugin("conversations") by plugins that link to sessions.
+type FocusConversationMsg struct {
+	SessionID string
+}
+
 // SwitchWorktreeMsg requests switching to a different worktree.
 // Used by the worktree switcher modal and workspace plugin "Open in Git Tab" command.
 type SwitchWorktreeMsg struct {
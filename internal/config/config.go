@@ -9,6 +9,18 @@ type Config struct {
 	Keymap   KeymapConfig   `json:"keymap"`
 	UI       UIConfig       `json:"ui"`
 	Features FeaturesConfig `json:"features"`
+
+	// path is the file passed to LoadFrom, so Save writes back to it.
+	path string
+}
+
+// Path returns the file this config was loaded from with LoadFrom, or
+// ConfigPath() for the default location.
+func (c *Config) Path() string {
+	if c.path != "" {
+		return c.path
+	}
+	return ConfigPath()
 }
 
 // FeaturesConfig holds feature flag settings.
@@ -29,6 +41,7 @@ type ProjectConfig struct {
 	Path          string       `json:"path"`                    // absolute path to project root (supports ~ expansion)
 	Theme         *ThemeConfig `json:"theme,omitempty"`         // per-project theme (nil = use global)
 	LastOpenInApp string       `json:"lastOpenInApp,omitempty"` // last app used to open this project (e.g. "vscode", "goland")
+	TestCommand   string       `json:"testCommand,omitempty"`   // per-project workspace test command (empty = use plugins.workspace.testCommand)
 }
 
 // PluginsConfig holds per-plugin configuration.
@@ -68,6 +81,9 @@ type ConversationsPluginConfig struct {
 	// Example: ["interactive"] hides cron/system sessions by default.
 	// Empty or omitted means show all sessions (no filter).
 	DefaultCategoryFilter []string `json:"defaultCategoryFilter,omitempty"`
+	// SavedFilters maps names to session filter queries, usable as @name.
+	// Example: {"pricey": "cost>5 since:7d"}
+	SavedFilters map[string]string `json:"savedFilters,omitempty"`
 }
 
 // WorkspacePluginConfig configures the workspace plugin.
@@ -96,6 +112,94 @@ type WorkspacePluginConfig struct {
 	InteractivePasteKey string `json:"interactivePasteKey,omitempty"`
 	// SidebarDisplay controls what information is shown in the workspace sidebar entries.
 	SidebarDisplay SidebarDisplayConfig `json:"sidebarDisplay"`
+	// ContextWarnPercent warns in the sidebar when a running agent's session fills
+	// this percentage of its model's context window. 0 disables. Default: 80.
+	ContextWarnPercent int `json:"contextWarnPercent"`
+	// AgentHooks registers status hooks with Claude Code and Codex agents started
+	// by sidecar, so status comes from the agent instead of terminal output. Off by
+	// default: Codex's notify override replaces the user's own notify program.
+	AgentHooks bool `json:"agentHooks"`
+	// TestCommand verifies a workspace, e.g. "go test ./..." or "make lint". It runs
+	// via sh in the workspace directory before merging and on the fan-out board.
+	// A .sidecar-test file committed on the base branch, then the project's
+	// testCommand, take precedence. Default: "" (no test step).
+	TestCommand string `json:"testCommand,omitempty"`
+	// SessionBackend selects what runs agent and shell sessions: "tmux", "pty"
+	// (in-process terminals that end with sidecar), or "auto" (tmux when
+	// installed, else pty). Default: "auto".
+	SessionBackend string `json:"sessionBackend,omitempty"`
+	// TmuxControlMode streams tmux panes through control-mode clients (tmux -C)
+	// instead of polling capture-pane. Needs tmux 3.2+; older versions fall
+	// back to polling. Default: true.
+	TmuxControlMode bool `json:"tmuxControlMode"`
+	// SyncStrategy is how workspaces catch up with their base branch: "rebase"
+	// or "merge". Default: "rebase".
+	SyncStrategy string `json:"syncStrategy,omitempty"`
+	// AutoSync syncs a workspace when its base branch advances and its agent
+	// is idle. Default: false.
+	AutoSync bool `json:"autoSync"`
+	// TranscriptLogging records every agent and shell session to a transcript
+	// in the project's data directory, readable after the session ends.
+	// Default: false.
+	TranscriptLogging bool `json:"transcriptLogging"`
+	// TranscriptMaxMB is the size in MB at which a transcript log is rotated.
+	// Three rotated files are kept per log. Default: 10.
+	TranscriptMaxMB int `json:"transcriptMaxMB"`
+	// Setup declares provisioning steps run when a workspace is created.
+	Setup WorkspaceSetupConfig `json:"setup"`
+	// Presets are named create-modal defaults, selectable in the modal or
+	// bound to a key that opens it with the preset applied.
+	Presets []WorkspacePreset `json:"presets,omitempty"`
+}
+
+// WorkspacePreset fills in the create modal in one step.
+type WorkspacePreset struct {
+	// Name identifies the preset in the create modal, e.g. "bugfix".
+	Name string `json:"name"`
+	// BaseBranch is the branch the workspace starts from. Default: current branch.
+	BaseBranch string `json:"baseBranch,omitempty"`
+	// Agent is the agent type to start, e.g. "claude" or "none". Default: the default agent.
+	Agent string `json:"agent,omitempty"`
+	// Prompt names a configured prompt to send to the agent.
+	Prompt string `json:"prompt,omitempty"`
+	// SkipPermissions starts the agent with its auto-approve flag.
+	SkipPermissions bool `json:"skipPermissions,omitempty"`
+	// SetupProfile names a setup profile whose steps replace setup.steps.
+	SetupProfile string `json:"setupProfile,omitempty"`
+	// Key opens the create modal with this preset from the workspace list, e.g. "B".
+	Key string `json:"key,omitempty"`
+}
+
+// WorkspaceSetupConfig declares how new workspaces are provisioned.
+type WorkspaceSetupConfig struct {
+	// Steps run after env files are copied and before .worktree-setup.sh.
+	Steps []ProvisionStep `json:"steps,omitempty"`
+	// Profiles are named alternatives to Steps, chosen by a workspace preset.
+	Profiles map[string][]ProvisionStep `json:"profiles,omitempty"`
+}
+
+// ProvisionStep is a workspace provisioning step. It either copies paths from
+// the main worktree or runs a command in the new workspace.
+type ProvisionStep struct {
+	// Name labels the step in the create modal. Default: the copied paths or the command.
+	Name string `json:"name,omitempty"`
+	// Copy lists directories or files to copy from the main worktree, e.g. "node_modules".
+	Copy []string `json:"copy,omitempty"`
+	// CopyMode is how Copy duplicates files: "auto" (reflink where the filesystem
+	// supports it, else a full copy), "reflink", "hardlink" (shares files with the
+	// main worktree) or "copy". Default: "auto".
+	CopyMode string `json:"copyMode,omitempty"`
+	// Run is a shell command run in the new workspace.
+	Run string `json:"run,omitempty"`
+	// Lockfiles key the step on dependency manifests such as package-lock.json.
+	// Copying is skipped when they differ from the main worktree's, as the
+	// copies would be stale. Run is skipped when every path in Creates exists
+	// and the lockfiles match, typically because a copy step provided them.
+	Lockfiles []string `json:"lockfiles,omitempty"`
+	// Creates lists the paths a run step produces.
+	Creates []string `json:"creates,omitempty"`
+	// Parallel runs the step at the same time as adjacent parallel steps.
+	Parallel bool `json:"parallel,omitempty"`
 }
 
 // SidebarDisplayConfig controls visibility of workspace sidebar entry elements.
@@ -109,6 +213,8 @@ type SidebarDisplayConfig struct {
 	HideTask bool `json:"hideTask"`
 	// HideStats hides the +/- line change stats on the second line. Default: false.
 	HideStats bool `json:"hideStats"`
+	// HideCost hides the agent session cost and token totals on the second line. Default: false.
+	HideCost bool `json:"hideCost"`
 }
 
 // NotesPluginConfig configures the notes plugin.
@@ -166,6 +272,10 @@ func Default() *Config {
 			Workspace: WorkspacePluginConfig{
 				DirPrefix:           true,
 				TmuxCaptureMaxBytes: 2 * 1024 * 1024,
+				ContextWarnPercent:  80,
+				TmuxControlMode:     true,
+				SyncStrategy:        "rebase",
+				TranscriptMaxMB:     10,
 			},
 		},
 		Keymap: KeymapConfig{
@@ -195,5 +305,8 @@ func (c *Config) Validate() error {
 	if c.Plugins.Workspace.TmuxCaptureMaxBytes <= 0 {
 		c.Plugins.Workspace.TmuxCaptureMaxBytes = 2 * 1024 * 1024
 	}
+	if c.Plugins.Workspace.ContextWarnPercent < 0 || c.Plugins.Workspace.ContextWarnPercent > 100 {
+		c.Plugins.Workspace.ContextWarnPercent = 80
+	}
 	return nil
 }
@@ -69,6 +69,7 @@ type rawProjectConfig struct {
 	Path          string       `json:"path"`
 	Theme         *ThemeConfig `json:"theme,omitempty"`
 	LastOpenInApp string       `json:"lastOpenInApp,omitempty"`
+	TestCommand   string       `json:"testCommand,omitempty"`
 }
 
 type rawPluginsConfig struct {
@@ -90,6 +91,17 @@ type rawWorkspaceConfig struct {
 	InteractiveCopyKey   string                   `json:"interactiveCopyKey"`
 	InteractivePasteKey  string                   `json:"interactivePasteKey"`
 	SidebarDisplay       *rawSidebarDisplayConfig `json:"sidebarDisplay"`
+	ContextWarnPercent   *int                     `json:"contextWarnPercent"`
+	AgentHooks           *bool                    `json:"agentHooks"`
+	TestCommand          string                   `json:"testCommand"`
+	SessionBackend       string                   `json:"sessionBackend"`
+	TmuxControlMode      *bool                    `json:"tmuxControlMode"`
+	SyncStrategy         string                   `json:"syncStrategy"`
+	AutoSync             *bool                    `json:"autoSync"`
+	TranscriptLogging    *bool                    `json:"transcriptLogging"`
+	TranscriptMaxMB      *int                     `json:"transcriptMaxMB"`
+	Setup                *WorkspaceSetupConfig    `json:"setup"`
+	Presets              []WorkspacePreset        `json:"presets"`
 }
 
 type rawSidebarDisplayConfig struct {
@@ -97,6 +109,7 @@ type rawSidebarDisplayConfig struct {
 	HideAgent      *bool `json:"hideAgent"`
 	HideTask       *bool `json:"hideTask"`
 	HideStats      *bool `json:"hideStats"`
+	HideCost       *bool `json:"hideCost"`
 }
 
 type rawGitStatusConfig struct {
@@ -115,8 +128,9 @@ type rawFileBrowserConfig struct {
 }
 
 type rawConversationsConfig struct {
-	Enabled       *bool  `json:"enabled"`
-	ClaudeDataDir string `json:"claudeDataDir"`
+	Enabled       *bool             `json:"enabled"`
+	ClaudeDataDir string            `json:"claudeDataDir"`
+	SavedFilters  map[string]string `json:"savedFilters"`
 }
 
 const (
@@ -133,6 +147,7 @@ func Load() (*Config, error) {
 // If path is empty, uses ~/.config/sidecar/config.json
 func LoadFrom(path string) (*Config, error) {
 	cfg := Default()
+	cfg.path = path
 
 	if path == "" {
 		home, err := os.UserHomeDir()
@@ -234,6 +249,9 @@ func mergeConfig(cfg *Config, raw *rawConfig) {
 	if raw.Plugins.Conversations.ClaudeDataDir != "" {
 		cfg.Plugins.Conversations.ClaudeDataDir = raw.Plugins.Conversations.ClaudeDataDir
 	}
+	if len(raw.Plugins.Conversations.SavedFilters) > 0 {
+		cfg.Plugins.Conversations.SavedFilters = raw.Plugins.Conversations.SavedFilters
+	}
 
 	// Workspace
 	if raw.Plugins.Workspace.DirPrefix != nil {
@@ -242,6 +260,52 @@ func mergeConfig(cfg *Config, raw *rawConfig) {
 	if raw.Plugins.Workspace.TmuxCaptureMaxBytes != nil {
 		cfg.Plugins.Workspace.TmuxCaptureMaxBytes = *raw.Plugins.Workspace.TmuxCaptureMaxBytes
 	}
+	if raw.Plugins.Workspace.ContextWarnPercent != nil {
+		cfg.Plugins.Workspace.ContextWarnPercent = *raw.Plugins.Workspace.ContextWarnPercent
+	}
+	if raw.Plugins.Workspace.AgentHooks != nil {
+		cfg.Plugins.Workspace.AgentHooks = *raw.Plugins.Workspace.AgentHooks
+	}
+	if raw.Plugins.Workspace.TestCommand != "" {
+		cfg.Plugins.Workspace.TestCommand = strings.TrimSpace(raw.Plugins.Workspace.TestCommand)
+	}
+	if raw.Plugins.Workspace.SessionBackend != "" {
+		cfg.Plugins.Workspace.SessionBackend = strings.ToLower(strings.TrimSpace(raw.Plugins.Workspace.SessionBackend))
+	}
+	if raw.Plugins.Workspace.TmuxControlMode != nil {
+		cfg.Plugins.Workspace.TmuxControlMode = *raw.Plugins.Workspace.TmuxControlMode
+	}
+	if raw.Plugins.Workspace.SyncStrategy != "" {
+		cfg.Plugins.Workspace.SyncStrategy = strings.ToLower(strings.TrimSpace(raw.Plugins.Workspace.SyncStrategy))
+	}
+	if raw.Plugins.Workspace.AutoSync != nil {
+		cfg.Plugins.Workspace.AutoSync = *raw.Plugins.Workspace.AutoSync
+	}
+	if raw.Plugins.Workspace.TranscriptLogging != nil {
+		cfg.Plugins.Workspace.TranscriptLogging = *raw.Plugins.Workspace.TranscriptLogging
+	}
+	if raw.Plugins.Workspace.TranscriptMaxMB != nil && *raw.Plugins.Workspace.TranscriptMaxMB > 0 {
+		cfg.Plugins.Workspace.TranscriptMaxMB = *raw.Plugins.Workspace.TranscriptMaxMB
+	}
+	if setup := raw.Plugins.Workspace.Setup; setup != nil {
+		for i := range setup.Steps {
+			setup.Steps[i].CopyMode = strings.ToLower(strings.TrimSpace(setup.Steps[i].CopyMode))
+		}
+		for _, steps := range setup.Profiles {
+			for i := range steps {
+				steps[i].CopyMode = strings.ToLower(strings.TrimSpace(steps[i].CopyMode))
+			}
+		}
+		cfg.Plugins.Workspace.Setup = *setup
+	}
+	for _, preset := range raw.Plugins.Workspace.Presets {
+		preset.Name = strings.TrimSpace(preset.Name)
+		if preset.Name == "" {
+			continue
+		}
+		preset.Agent = strings.ToLower(strings.TrimSpace(preset.Agent))
+		cfg.Plugins.Workspace.Presets = append(cfg.Plugins.Workspace.Presets, preset)
+	}
 	if raw.Plugins.Workspace.DefaultAgentType != "" {
 		cfg.Plugins.Workspace.DefaultAgentType = raw.Plugins.Workspace.DefaultAgentType
 	}
@@ -276,6 +340,9 @@ func mergeConfig(cfg *Config, raw *rawConfig) {
 		if sd.HideStats != nil {
 			cfg.Plugins.Workspace.SidebarDisplay.HideStats = *sd.HideStats
 		}
+		if sd.HideCost != nil {
+			cfg.Plugins.Workspace.SidebarDisplay.HideCost = *sd.HideCost
+		}
 	}
 
 	// Keymap
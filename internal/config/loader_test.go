@@ -408,3 +408,146 @@ func TestLoadFrom_FileBrowserDisabled(t *testing.T) {
 		t.Error("git-status should still be enabled (default)")
 	}
 }
+
+func TestLoadFrom_ConversationsSavedFilters(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+
+	content := []byte(`{
+		"plugins": {
+			"conversations": {
+				"savedFilters": {"pricey": "cost>5 since:7d"}
+			}
+		}
+	}`)
+
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadFrom(path)
+	if err != nil {
+		t.Fatalf("LoadFrom failed: %v", err)
+	}
+
+	if got := cfg.Plugins.Conversations.SavedFilters["pricey"]; got != "cost>5 since:7d" {
+		t.Errorf("savedFilters[pricey] = %q, want %q", got, "cost>5 since:7d")
+	}
+}
+
+// loadTestConfig writes content to a temp config file and loads it.
+func loadTestConfig(t *testing.T, content string) *Config {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := LoadFrom(path)
+	if err != nil {
+		t.Fatalf("LoadFrom failed: %v", err)
+	}
+	return cfg
+}
+
+func TestLoadFrom_WorkspaceContextWarnPercent(t *testing.T) {
+	if got := loadTestConfig(t, `{}`).Plugins.Workspace.ContextWarnPercent; got != 80 {
+		t.Errorf("default contextWarnPercent = %d, want 80", got)
+	}
+	cfg := loadTestConfig(t, `{"plugins": {"workspace": {"contextWarnPercent": 0}}}`)
+	if got := cfg.Plugins.Workspace.ContextWarnPercent; got != 0 {
+		t.Errorf("contextWarnPercent = %d, want 0 (disabled)", got)
+	}
+}
+
+func TestLoadFrom_WorkspaceAgentHooks(t *testing.T) {
+	if loadTestConfig(t, `{}`).Plugins.Workspace.AgentHooks {
+		t.Error("agentHooks should be off by default")
+	}
+	if !loadTestConfig(t, `{"plugins": {"workspace": {"agentHooks": true}}}`).Plugins.Workspace.AgentHooks {
+		t.Error("agentHooks = false, want true")
+	}
+}
+
+func TestLoadFrom_WorkspaceTestCommand(t *testing.T) {
+	cfg := loadTestConfig(t, `{"plugins": {"workspace": {"testCommand": " go test ./... "}}}`)
+	if got := cfg.Plugins.Workspace.TestCommand; got != "go test ./..." {
+		t.Errorf("testCommand = %q, want %q", got, "go test ./...")
+	}
+}
+
+func TestLoadFrom_ProjectTestCommand(t *testing.T) {
+	cfg := loadTestConfig(t, `{"projects": {"list": [{"name": "api", "path": "/tmp/api", "testCommand": "make check"}]}}`)
+	if len(cfg.Projects.List) != 1 || cfg.Projects.List[0].TestCommand != "make check" {
+		t.Errorf("projects = %+v, want testCommand %q", cfg.Projects.List, "make check")
+	}
+}
+
+func TestLoadFrom_WorkspaceSessionBackend(t *testing.T) {
+	cfg := loadTestConfig(t, `{"plugins": {"workspace": {"sessionBackend": " PTY "}}}`)
+	if got := cfg.Plugins.Workspace.SessionBackend; got != "pty" {
+		t.Errorf("sessionBackend = %q, want %q", got, "pty")
+	}
+}
+
+func TestLoadFrom_WorkspaceTmuxControlMode(t *testing.T) {
+	if !loadTestConfig(t, `{}`).Plugins.Workspace.TmuxControlMode {
+		t.Error("tmuxControlMode should be on by default")
+	}
+	if loadTestConfig(t, `{"plugins": {"workspace": {"tmuxControlMode": false}}}`).Plugins.Workspace.TmuxControlMode {
+		t.Error("tmuxControlMode = true, want false")
+	}
+}
+
+func TestLoadFrom_WorkspaceSync(t *testing.T) {
+	cfg := loadTestConfig(t, `{"plugins": {"workspace": {"syncStrategy": "Merge", "autoSync": true}}}`)
+	if cfg.Plugins.Workspace.SyncStrategy != "merge" || !cfg.Plugins.Workspace.AutoSync {
+		t.Errorf("syncStrategy = %q, autoSync = %v, want merge, true", cfg.Plugins.Workspace.SyncStrategy, cfg.Plugins.Workspace.AutoSync)
+	}
+}
+
+func TestLoadFrom_WorkspaceTranscriptLogging(t *testing.T) {
+	cfg := loadTestConfig(t, `{"plugins": {"workspace": {"transcriptLogging": true, "transcriptMaxMB": 0}}}`)
+	if !cfg.Plugins.Workspace.TranscriptLogging || cfg.Plugins.Workspace.TranscriptMaxMB != 10 {
+		t.Errorf("transcriptLogging = %v, transcriptMaxMB = %d, want true, 10 (0 keeps the default)",
+			cfg.Plugins.Workspace.TranscriptLogging, cfg.Plugins.Workspace.TranscriptMaxMB)
+	}
+}
+
+func TestLoadFrom_WorkspaceSetupSteps(t *testing.T) {
+	cfg := loadTestConfig(t, `{"plugins": {"workspace": {"setup": {"steps": [
+		{"name": "deps", "copy": ["node_modules"], "copyMode": " Reflink ", "lockfiles": ["package-lock.json"], "parallel": true}]}}}}`)
+	if steps := cfg.Plugins.Workspace.Setup.Steps; len(steps) != 1 || steps[0].CopyMode != "reflink" ||
+		!steps[0].Parallel || steps[0].Lockfiles[0] != "package-lock.json" {
+		t.Errorf("setup steps = %+v, want one parallel reflink step keyed on package-lock.json", steps)
+	}
+}
+
+func TestLoadFrom_WorkspacePresets(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+
+	content := []byte(`{"plugins": {"workspace": {
+		"setup": {"profiles": {"full": [{"run": "make deps", "copyMode": " Copy "}]}},
+		"presets": [
+			{"name": " bugfix ", "baseBranch": "main", "agent": " Claude ", "prompt": "Fix ticket", "skipPermissions": true, "setupProfile": "full", "key": "B"},
+			{"name": "", "agent": "codex"}
+		]}}}`)
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := LoadFrom(path)
+	if err != nil {
+		t.Fatalf("LoadFrom failed: %v", err)
+	}
+	presets := cfg.Plugins.Workspace.Presets
+	if len(presets) != 1 {
+		t.Fatalf("presets = %+v, want one (unnamed presets dropped)", presets)
+	}
+	want := WorkspacePreset{Name: "bugfix", BaseBranch: "main", Agent: "claude", Prompt: "Fix ticket", SkipPermissions: true, SetupProfile: "full", Key: "B"}
+	if presets[0] != want {
+		t.Errorf("preset = %+v, want %+v", presets[0], want)
+	}
+	if steps := cfg.Plugins.Workspace.Setup.Profiles["full"]; len(steps) != 1 || steps[0].CopyMode != "copy" {
+		t.Errorf("profile steps = %+v, want one step with copyMode copy", steps)
+	}
+}
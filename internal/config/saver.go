@@ -47,8 +47,9 @@ type saveTDMonitorConfig struct {
 }
 
 type saveConversationsConfig struct {
-	Enabled       *bool  `json:"enabled,omitempty"`
-	ClaudeDataDir string `json:"claudeDataDir,omitempty"`
+	Enabled       *bool             `json:"enabled,omitempty"`
+	ClaudeDataDir string            `json:"claudeDataDir,omitempty"`
+	SavedFilters  map[string]string `json:"savedFilters,omitempty"`
 }
 
 type saveWorkspaceConfig struct {
@@ -61,6 +62,25 @@ type saveWorkspaceConfig struct {
 	InteractiveCopyKey   string               `json:"interactiveCopyKey,omitempty"`
 	InteractivePasteKey  string               `json:"interactivePasteKey,omitempty"`
 	SidebarDisplay       *SidebarDisplayConfig `json:"sidebarDisplay,omitempty"`
+	ContextWarnPercent   *int                  `json:"contextWarnPercent,omitempty"`
+	AgentHooks           *bool                 `json:"agentHooks,omitempty"`
+	TestCommand          string                `json:"testCommand,omitempty"`
+	SessionBackend       string                `json:"sessionBackend,omitempty"`
+	TmuxControlMode      *bool                 `json:"tmuxControlMode,omitempty"`
+	SyncStrategy         string                `json:"syncStrategy,omitempty"`
+	AutoSync             *bool                 `json:"autoSync,omitempty"`
+	TranscriptLogging    *bool                 `json:"transcriptLogging,omitempty"`
+	TranscriptMaxMB      *int                  `json:"transcriptMaxMB,omitempty"`
+	Setup                *WorkspaceSetupConfig `json:"setup,omitempty"`
+	Presets              []WorkspacePreset     `json:"presets,omitempty"`
+}
+
+// saveSetupConfig omits the workspace setup block when it has no steps.
+func saveSetupConfig(setup WorkspaceSetupConfig) *WorkspaceSetupConfig {
+	if len(setup.Steps) == 0 && len(setup.Profiles) == 0 {
+		return nil
+	}
+	return &setup
 }
 
 // toSaveConfig converts Config to the JSON-serializable format.
@@ -87,6 +107,7 @@ func toSaveConfig(cfg *Config) saveConfig {
 			Conversations: saveConversationsConfig{
 				Enabled:       &cfg.Plugins.Conversations.Enabled,
 				ClaudeDataDir: cfg.Plugins.Conversations.ClaudeDataDir,
+				SavedFilters:  cfg.Plugins.Conversations.SavedFilters,
 			},
 			Workspace: saveWorkspaceConfig{
 				DirPrefix:            &cfg.Plugins.Workspace.DirPrefix,
@@ -98,6 +119,17 @@ func toSaveConfig(cfg *Config) saveConfig {
 				InteractiveCopyKey:   cfg.Plugins.Workspace.InteractiveCopyKey,
 				InteractivePasteKey:  cfg.Plugins.Workspace.InteractivePasteKey,
 				SidebarDisplay:       &cfg.Plugins.Workspace.SidebarDisplay,
+				ContextWarnPercent:   &cfg.Plugins.Workspace.ContextWarnPercent,
+				AgentHooks:           &cfg.Plugins.Workspace.AgentHooks,
+				TestCommand:          cfg.Plugins.Workspace.TestCommand,
+				SessionBackend:       cfg.Plugins.Workspace.SessionBackend,
+				TmuxControlMode:      &cfg.Plugins.Workspace.TmuxControlMode,
+				SyncStrategy:         cfg.Plugins.Workspace.SyncStrategy,
+				AutoSync:             &cfg.Plugins.Workspace.AutoSync,
+				TranscriptLogging:    &cfg.Plugins.Workspace.TranscriptLogging,
+				TranscriptMaxMB:      &cfg.Plugins.Workspace.TranscriptMaxMB,
+				Setup:                saveSetupConfig(cfg.Plugins.Workspace.Setup),
+				Presets:              cfg.Plugins.Workspace.Presets,
 			},
 		},
 		Keymap:   cfg.Keymap,
@@ -106,11 +138,14 @@ func toSaveConfig(cfg *Config) saveConfig {
 	}
 }
 
-// Save writes the config to ~/.config/sidecar/config.json, preserving
-// any keys it doesn't manage (e.g. "prompts").
+// Save writes the config back to the file it was loaded from (see
+// Config.Path), preserving any keys it doesn't manage (e.g. "prompts").
 func Save(cfg *Config) error {
-	path := ConfigPath()
+	return SaveTo(cfg.Path(), cfg)
+}
 
+// SaveTo writes the config to path, preserving any keys it doesn't manage.
+func SaveTo(path string, cfg *Config) error {
 	dir := filepath.Dir(path)
 	if err := os.MkdirAll(dir, 0755); err != nil {
 		return err
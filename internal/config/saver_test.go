@@ -238,3 +238,50 @@ func TestSave_WritesWorkspaceAgentSettings(t *testing.T) {
 		t.Errorf("agentStart.codex = %v, want %q", got, "codex --dangerously-bypass-approvals-and-sandbox")
 	}
 }
+
+func TestSave_WritesBackToLoadedPath(t *testing.T) {
+	dir := t.TempDir()
+	defaultPath := filepath.Join(dir, "default.json")
+	customPath := filepath.Join(dir, "custom.json")
+
+	SetTestConfigPath(defaultPath)
+	defer ResetTestConfigPath()
+
+	if err := os.WriteFile(defaultPath, []byte(`{"ui":{"theme":{"name":"default"}}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(customPath, []byte(`{"ui":{"theme":{"name":"dracula"}}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadFrom(customPath)
+	if err != nil {
+		t.Fatalf("LoadFrom failed: %v", err)
+	}
+	if cfg.Path() != customPath {
+		t.Errorf("Path() = %q, want %q", cfg.Path(), customPath)
+	}
+	cfg.Plugins.Conversations.SavedFilters = map[string]string{"pricey": "cost>5"}
+	if err := Save(cfg); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	reloaded, err := LoadFrom(customPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reloaded.Plugins.Conversations.SavedFilters["pricey"] != "cost>5" {
+		t.Errorf("custom config missing saved filter: %v", reloaded.Plugins.Conversations.SavedFilters)
+	}
+	data, err := os.ReadFile(defaultPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"ui":{"theme":{"name":"default"}}}` {
+		t.Errorf("default config was rewritten: %s", data)
+	}
+
+	if got := Default().Path(); got != defaultPath {
+		t.Errorf("Default().Path() = %q, want %q", got, defaultPath)
+	}
+}
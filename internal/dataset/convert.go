@@ -0,0 +1,247 @@
+package dataset
+
+import (
+	"strings"
+
+	"github.com/marcus/sidecar/internal/adapter"
+)
+
+// Chat roles used in exported conversations.
+const (
+	RoleSystem    = "system"
+	RoleUser      = "user"
+	RoleAssistant = "assistant"
+	RoleTool      = "tool"
+)
+
+// ChatMessage is a normalized chat turn shared by all output formats.
+type ChatMessage struct {
+	Role       string     `json:"role"`
+	Content    string     `json:"content"`
+	ToolCalls  []ToolCall `json:"tool_calls,omitempty"`
+	ToolCallID string     `json:"tool_call_id,omitempty"`
+	Name       string     `json:"name,omitempty"` // tool name for role=tool
+}
+
+// ToolCall is an OpenAI-style function call made by the assistant.
+type ToolCall struct {
+	ID       string       `json:"id"`
+	Type     string       `json:"type"` // always "function"
+	Function FunctionCall `json:"function"`
+}
+
+// FunctionCall holds the function name and JSON-encoded arguments.
+type FunctionCall struct {
+	Name      string `json:"name"`
+	Arguments string `json:"arguments"`
+}
+
+// ConvertOptions controls how adapter messages are normalized.
+type ConvertOptions struct {
+	SystemPrompt    string    // Prepended as a system message when non-empty
+	IncludeThinking bool      // Include thinking blocks in assistant content
+	Redactor        *Redactor // Applied to all text, arguments, and tool output (nil = none)
+}
+
+// ConvertMessages normalizes adapter messages into chat turns.
+//
+// Adapters differ in where tool results live: Claude Code puts tool_result
+// blocks in the following user message, Pi attaches them to the assistant
+// message, and others only fill ToolUse.Output. Results are emitted as role=tool
+// messages exactly once, directly after the assistant turn that requested them
+// when they are not carried by a later message. Consecutive text messages from
+// the same role are merged.
+func ConvertMessages(messages []adapter.Message, opts ConvertOptions) []ChatMessage {
+	c := &converter{opts: opts, emitted: make(map[string]bool), names: make(map[string]string)}
+
+	// Tool results that arrive as standalone blocks in user messages
+	carried := make(map[string]bool)
+	for _, msg := range messages {
+		if msg.Role != RoleUser {
+			continue
+		}
+		for _, b := range msg.ContentBlocks {
+			if b.Type == "tool_result" && b.ToolUseID != "" {
+				carried[b.ToolUseID] = true
+			}
+		}
+	}
+	c.carried = carried
+
+	if opts.SystemPrompt != "" {
+		c.out = append(c.out, ChatMessage{Role: RoleSystem, Content: c.redact(opts.SystemPrompt)})
+	}
+
+	for _, msg := range messages {
+		switch msg.Role {
+		case RoleUser:
+			c.addUser(msg)
+		case RoleAssistant:
+			c.addAssistant(msg)
+		case RoleSystem:
+			if text := strings.TrimSpace(msg.Content); text != "" {
+				c.appendText(RoleSystem, text)
+			}
+		}
+	}
+	return c.out
+}
+
+type converter struct {
+	opts    ConvertOptions
+	out     []ChatMessage
+	carried map[string]bool   // tool_use IDs answered by a later tool_result block
+	emitted map[string]bool   // tool_use IDs already emitted as role=tool
+	names   map[string]string // tool_use ID -> tool name
+}
+
+func (c *converter) redact(s string) string {
+	if c.opts.Redactor == nil {
+		return s
+	}
+	return c.opts.Redactor.Redact(s)
+}
+
+// appendText adds text for role, merging into the previous message when it
+// has the same role and carries no tool calls.
+func (c *converter) appendText(role, text string) {
+	text = c.redact(text)
+	if n := len(c.out); n > 0 {
+		last := &c.out[n-1]
+		if last.Role == role && len(last.ToolCalls) == 0 {
+			if last.Content != "" {
+				last.Content += "\n\n"
+			}
+			last.Content += text
+			return
+		}
+	}
+	c.out = append(c.out, ChatMessage{Role: role, Content: text})
+}
+
+func (c *converter) addToolResult(id, output string, isError bool) {
+	if id == "" || c.emitted[id] {
+		return
+	}
+	c.emitted[id] = true
+	if isError && !strings.HasPrefix(output, "Error") {
+		output = "Error: " + output
+	}
+	c.out = append(c.out, ChatMessage{
+		Role:       RoleTool,
+		ToolCallID: id,
+		Name:       c.names[id],
+		Content:    c.redact(output),
+	})
+}
+
+func (c *converter) addUser(msg adapter.Message) {
+	if len(msg.ContentBlocks) == 0 {
+		if text := strings.TrimSpace(msg.Content); text != "" {
+			c.appendText(RoleUser, text)
+		}
+		return
+	}
+	var texts []string
+	for _, b := range msg.ContentBlocks {
+		switch b.Type {
+		case "tool_result":
+			c.addToolResult(b.ToolUseID, b.ToolOutput, b.IsError)
+		case "text":
+			if text := strings.TrimSpace(b.Text); text != "" {
+				texts = append(texts, text)
+			}
+		}
+	}
+	if len(texts) > 0 {
+		c.appendText(RoleUser, strings.Join(texts, "\n\n"))
+	}
+}
+
+// pendingResult is a tool result to emit after the assistant turn.
+type pendingResult struct {
+	id      string
+	output  string
+	isError bool
+}
+
+func (c *converter) addAssistant(msg adapter.Message) {
+	var texts []string
+	var calls []ToolCall
+	var results []pendingResult
+
+	addCall := func(id, name, input, output string, isError bool) {
+		if input == "" {
+			input = "{}"
+		}
+		c.names[id] = name
+		calls = append(calls, ToolCall{
+			ID:       id,
+			Type:     "function",
+			Function: FunctionCall{Name: name, Arguments: c.redact(input)},
+		})
+		if !c.carried[id] && output != "" {
+			results = append(results, pendingResult{id: id, output: output, isError: isError})
+		}
+	}
+
+	if len(msg.ContentBlocks) == 0 {
+		if text := strings.TrimSpace(msg.Content); text != "" {
+			texts = append(texts, text)
+		}
+		for _, tu := range msg.ToolUses {
+			addCall(tu.ID, tu.Name, tu.Input, tu.Output, false)
+		}
+	} else {
+		for _, b := range msg.ContentBlocks {
+			switch b.Type {
+			case "text":
+				if text := strings.TrimSpace(b.Text); text != "" {
+					texts = append(texts, text)
+				}
+			case "thinking":
+				if c.opts.IncludeThinking && strings.TrimSpace(b.Text) != "" {
+					texts = append(texts, "<thinking>\n"+strings.TrimSpace(b.Text)+"\n</thinking>")
+				}
+			case "tool_use":
+				addCall(b.ToolUseID, b.ToolName, b.ToolInput, b.ToolOutput, b.IsError)
+			case "tool_result":
+				results = append(results, pendingResult{id: b.ToolUseID, output: b.ToolOutput, isError: b.IsError})
+			}
+		}
+	}
+
+	if len(texts) == 0 && len(calls) == 0 {
+		return
+	}
+
+	content := c.redact(strings.Join(texts, "\n\n"))
+	if n := len(c.out); n > 0 && c.out[n-1].Role == RoleAssistant {
+		// Claude Code writes one JSONL entry per content block; fold them
+		// back into a single assistant turn.
+		last := &c.out[n-1]
+		if content != "" {
+			if last.Content != "" {
+				last.Content += "\n\n"
+			}
+			last.Content += content
+		}
+		last.ToolCalls = append(last.ToolCalls, calls...)
+	} else {
+		c.out = append(c.out, ChatMessage{Role: RoleAssistant, Content: content, ToolCalls: calls})
+	}
+
+	for _, r := range results {
+		c.addToolResult(r.id, r.output, r.isError)
+	}
+}
+
+// HasAssistantTurn reports whether the conversation contains any assistant output.
+func HasAssistantTurn(msgs []ChatMessage) bool {
+	for _, m := range msgs {
+		if m.Role == RoleAssistant {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,244 @@
+package dataset
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/marcus/sidecar/internal/adapter"
+)
+
+// Format identifies an export format.
+type Format string
+
+const (
+	// FormatOpenAI writes one {"messages": [...]} object per line, with
+	// assistant tool_calls and role=tool results.
+	FormatOpenAI Format = "openai"
+	// FormatShareGPT writes one {"conversations": [...]} object per line
+	// using from/value turns (human, gpt, function_call, observation).
+	FormatShareGPT Format = "sharegpt"
+)
+
+// ParseFormat validates a format name.
+func ParseFormat(s string) (Format, error) {
+	switch Format(strings.ToLower(strings.TrimSpace(s))) {
+	case FormatOpenAI, "":
+		return FormatOpenAI, nil
+	case FormatShareGPT:
+		return FormatShareGPT, nil
+	default:
+		return "", fmt.Errorf("unknown format %q (want openai or sharegpt)", s)
+	}
+}
+
+// TagSource resolves user-assigned tags for a session.
+type TagSource interface {
+	SessionTags(adapterID, sessionID string) []string
+}
+
+// AnnotationSource resolves a session's user note and per-message annotations.
+type AnnotationSource interface {
+	SessionNote(adapterID, sessionID string) string
+	MessageAnnotations(adapterID, sessionID string) map[string]string // message ID -> text
+}
+
+// Filter selects which sessions are exported. Zero values match everything.
+type Filter struct {
+	SessionIDs []string  // Exact session IDs (empty = any)
+	Adapters   []string  // Adapter IDs, e.g. "claude-code", "codex"
+	Models     []string  // Case-insensitive substrings of any message model
+	Since      time.Time // Sessions updated at or after
+	Until      time.Time // Sessions updated at or before
+	Tags       []string  // Sessions carrying all of these tags
+	TagSource  TagSource // Required when Tags is non-empty
+}
+
+// MatchesSession applies the filters that only need session metadata.
+func (f Filter) MatchesSession(s adapter.Session) bool {
+	if len(f.SessionIDs) > 0 && !slices.Contains(f.SessionIDs, s.ID) {
+		return false
+	}
+	if len(f.Adapters) > 0 && !slices.Contains(f.Adapters, s.AdapterID) {
+		return false
+	}
+	if !f.Since.IsZero() && s.UpdatedAt.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && s.UpdatedAt.After(f.Until) {
+		return false
+	}
+	if len(f.Tags) > 0 {
+		if f.TagSource == nil {
+			return false
+		}
+		have := f.TagSource.SessionTags(s.AdapterID, s.ID)
+		for _, want := range f.Tags {
+			if !slices.Contains(have, want) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// MatchesMessages applies the model filter, which needs loaded messages.
+func (f Filter) MatchesMessages(messages []adapter.Message) bool {
+	if len(f.Models) == 0 {
+		return true
+	}
+	for _, msg := range messages {
+		model := strings.ToLower(msg.Model)
+		if model == "" {
+			continue
+		}
+		for _, want := range f.Models {
+			if strings.Contains(model, strings.ToLower(want)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Options configures an export run.
+type Options struct {
+	Format          Format
+	Filter          Filter
+	Convert         ConvertOptions
+	IncludeMetadata bool             // Add a "metadata" object describing the source session
+	Annotations     AnnotationSource // Notes and annotations to add to metadata (optional)
+}
+
+// Metadata describes the session a record came from.
+type Metadata struct {
+	SessionID   string              `json:"session_id"`
+	Adapter     string              `json:"adapter"`
+	Name        string              `json:"name,omitempty"`
+	Model       string              `json:"model,omitempty"`
+	CreatedAt   time.Time           `json:"created_at"`
+	Worktree    string              `json:"worktree,omitempty"`
+	Tags        []string            `json:"tags,omitempty"`
+	Note        string              `json:"note,omitempty"`
+	Annotations []MessageAnnotation `json:"annotations,omitempty"`
+}
+
+// MessageAnnotation is a user annotation on one source message.
+type MessageAnnotation struct {
+	MessageID string `json:"message_id"`
+	Role      string `json:"role"`
+	Text      string `json:"text"`
+}
+
+// openAIRecord is one line of OpenAI chat fine-tuning JSONL.
+type openAIRecord struct {
+	Messages []ChatMessage `json:"messages"`
+	Metadata *Metadata     `json:"metadata,omitempty"`
+}
+
+// Stats summarizes an export run.
+type Stats struct {
+	Written int // Records written
+	Skipped int // Sessions filtered out after loading messages or with no assistant output
+	Failed  int // Sessions whose messages could not be loaded
+}
+
+// Export loads messages for each matching session and writes one JSONL
+// record per session to w.
+func Export(w io.Writer, adapters map[string]adapter.Adapter, sessions []adapter.Session, opts Options) (Stats, error) {
+	var stats Stats
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+
+	for _, s := range sessions {
+		if !opts.Filter.MatchesSession(s) {
+			continue
+		}
+		a, ok := adapters[s.AdapterID]
+		if !ok {
+			stats.Failed++
+			continue
+		}
+		messages, err := a.Messages(s.ID)
+		if err != nil {
+			stats.Failed++
+			continue
+		}
+		if !opts.Filter.MatchesMessages(messages) {
+			stats.Skipped++
+			continue
+		}
+		chat := ConvertMessages(messages, opts.Convert)
+		if !HasAssistantTurn(chat) {
+			stats.Skipped++
+			continue
+		}
+
+		var meta *Metadata
+		if opts.IncludeMetadata {
+			meta = sessionMetadata(s, messages, opts.Filter.TagSource, opts.Annotations, opts.Convert.Redactor)
+		}
+
+		var record any
+		switch opts.Format {
+		case FormatShareGPT:
+			record = toShareGPT(chat, meta)
+		default:
+			record = openAIRecord{Messages: chat, Metadata: meta}
+		}
+		if err := enc.Encode(record); err != nil {
+			return stats, err
+		}
+		stats.Written++
+	}
+	return stats, nil
+}
+
+// sessionMetadata builds the metadata block for a session. The free-text
+// fields (name, note, tags, annotations) go through r like message content.
+func sessionMetadata(s adapter.Session, messages []adapter.Message, tags TagSource, notes AnnotationSource, r *Redactor) *Metadata {
+	meta := &Metadata{
+		SessionID: s.ID,
+		Adapter:   s.AdapterID,
+		Name:      r.Redact(s.Name),
+		Model:     primaryModel(messages),
+		CreatedAt: s.CreatedAt,
+		Worktree:  s.WorktreeName,
+	}
+	if tags != nil {
+		for _, tag := range tags.SessionTags(s.AdapterID, s.ID) {
+			meta.Tags = append(meta.Tags, r.Redact(tag))
+		}
+	}
+	if notes != nil {
+		meta.Note = r.Redact(notes.SessionNote(s.AdapterID, s.ID))
+		if byID := notes.MessageAnnotations(s.AdapterID, s.ID); len(byID) > 0 {
+			// Emit in conversation order
+			for _, m := range messages {
+				if text, ok := byID[m.ID]; ok {
+					meta.Annotations = append(meta.Annotations, MessageAnnotation{MessageID: m.ID, Role: m.Role, Text: r.Redact(text)})
+				}
+			}
+		}
+	}
+	return meta
+}
+
+// primaryModel returns the most frequently used model in messages.
+func primaryModel(messages []adapter.Message) string {
+	counts := make(map[string]int)
+	var best string
+	for _, m := range messages {
+		if m.Model == "" {
+			continue
+		}
+		counts[m.Model]++
+		if counts[m.Model] > counts[best] {
+			best = m.Model
+		}
+	}
+	return best
+}
@@ -0,0 +1,336 @@
+package dataset
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/marcus/sidecar/internal/adapter"
+)
+
+// stubAdapter serves canned messages keyed by session ID.
+type stubAdapter struct {
+	messages map[string][]adapter.Message
+}
+
+func (s *stubAdapter) ID() string                                 { return "stub" }
+func (s *stubAdapter) Name() string                               { return "Stub" }
+func (s *stubAdapter) Icon() string                               { return "S" }
+func (s *stubAdapter) Detect(string) (bool, error)                { return true, nil }
+func (s *stubAdapter) Capabilities() adapter.CapabilitySet        { return nil }
+func (s *stubAdapter) Sessions(string) ([]adapter.Session, error) { return nil, nil }
+func (s *stubAdapter) Messages(id string) ([]adapter.Message, error) {
+	return s.messages[id], nil
+}
+func (s *stubAdapter) Usage(string) (*adapter.UsageStats, error) { return nil, nil }
+func (s *stubAdapter) Watch(string) (<-chan adapter.Event, io.Closer, error) {
+	return nil, nil, nil
+}
+
+// claudeStyleMessages mimics Claude Code: one entry per block, tool results
+// in the following user message.
+func claudeStyleMessages() []adapter.Message {
+	return []adapter.Message{
+		{Role: "user", Content: "fix the bug", ContentBlocks: []adapter.ContentBlock{{Type: "text", Text: "fix the bug"}}},
+		{Role: "assistant", Model: "claude-opus-4-5", ContentBlocks: []adapter.ContentBlock{
+			{Type: "thinking", Text: "look at main.go"},
+			{Type: "text", Text: "Reading the file."},
+		}},
+		{Role: "assistant", Model: "claude-opus-4-5", ContentBlocks: []adapter.ContentBlock{
+			{Type: "tool_use", ToolUseID: "t1", ToolName: "Read", ToolInput: `{"file_path":"main.go"}`, ToolOutput: "package main"},
+		}},
+		{Role: "user", Content: "[1 tool result(s)]", ContentBlocks: []adapter.ContentBlock{
+			{Type: "tool_result", ToolUseID: "t1", ToolOutput: "package main"},
+		}},
+		{Role: "assistant", Model: "claude-opus-4-5", ContentBlocks: []adapter.ContentBlock{{Type: "text", Text: "Done."}}},
+	}
+}
+
+func TestConvertMessages_ClaudeStyle(t *testing.T) {
+	got := ConvertMessages(claudeStyleMessages(), ConvertOptions{})
+
+	wantRoles := []string{"user", "assistant", "tool", "assistant"}
+	if len(got) != len(wantRoles) {
+		t.Fatalf("got %d messages, want %d: %+v", len(got), len(wantRoles), got)
+	}
+	for i, role := range wantRoles {
+		if got[i].Role != role {
+			t.Errorf("msg %d role = %q, want %q", i, got[i].Role, role)
+		}
+	}
+	if got[1].Content != "Reading the file." {
+		t.Errorf("assistant content = %q", got[1].Content)
+	}
+	if len(got[1].ToolCalls) != 1 || got[1].ToolCalls[0].Function.Name != "Read" {
+		t.Fatalf("tool calls = %+v", got[1].ToolCalls)
+	}
+	if got[2].ToolCallID != "t1" || got[2].Content != "package main" || got[2].Name != "Read" {
+		t.Errorf("tool message = %+v", got[2])
+	}
+}
+
+func TestConvertMessages_IncludeThinking(t *testing.T) {
+	got := ConvertMessages(claudeStyleMessages(), ConvertOptions{IncludeThinking: true, SystemPrompt: "be brief"})
+	if got[0].Role != "system" || got[0].Content != "be brief" {
+		t.Errorf("first message = %+v, want system prompt", got[0])
+	}
+	if !strings.HasPrefix(got[2].Content, "<thinking>") {
+		t.Errorf("assistant content should start with thinking, got %q", got[2].Content)
+	}
+}
+
+func TestConvertMessages_InlineResults(t *testing.T) {
+	// Adapters that only populate ToolUses (no ContentBlocks)
+	msgs := []adapter.Message{
+		{Role: "user", Content: "list files"},
+		{Role: "assistant", Content: "Listing.", ToolUses: []adapter.ToolUse{
+			{ID: "a", Name: "Bash", Input: `{"command":"ls"}`, Output: "README.md"},
+			{ID: "b", Name: "Bash", Input: ""},
+		}},
+	}
+	got := ConvertMessages(msgs, ConvertOptions{})
+	if len(got) != 3 {
+		t.Fatalf("got %d messages, want 3: %+v", len(got), got)
+	}
+	if got[1].ToolCalls[1].Function.Arguments != "{}" {
+		t.Errorf("empty input should become {}, got %q", got[1].ToolCalls[1].Function.Arguments)
+	}
+	if got[2].Role != "tool" || got[2].ToolCallID != "a" {
+		t.Errorf("tool message = %+v", got[2])
+	}
+}
+
+func TestConvertMessages_AssistantCarriedResults(t *testing.T) {
+	// Pi attaches tool_result blocks to the assistant message
+	msgs := []adapter.Message{
+		{Role: "user", Content: "go"},
+		{Role: "assistant", ContentBlocks: []adapter.ContentBlock{
+			{Type: "tool_use", ToolUseID: "x", ToolName: "read", ToolInput: `{}`},
+			{Type: "tool_result", ToolUseID: "x", ToolOutput: "boom", IsError: true},
+		}},
+	}
+	got := ConvertMessages(msgs, ConvertOptions{})
+	if len(got) != 3 || got[2].Role != "tool" {
+		t.Fatalf("unexpected messages: %+v", got)
+	}
+	if got[2].Content != "Error: boom" {
+		t.Errorf("error result = %q", got[2].Content)
+	}
+}
+
+func TestRedactor(t *testing.T) {
+	r, err := NewRedactor(RedactOptions{HomeDir: "/home/alice", Patterns: []string{`internal-\d+`}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	in := "key sk-ant-REDACTED mail bob@example.com path /home/alice/src ticket internal-42"
+	got := r.Redact(in)
+	for _, leak := range []string{"sk-ant-", "bob@example.com", "/home/alice", "internal-42"} {
+		if strings.Contains(got, leak) {
+			t.Errorf("redacted output still contains %q: %s", leak, got)
+		}
+	}
+	if !strings.Contains(got, "~/src") {
+		t.Errorf("home dir should become ~, got %s", got)
+	}
+
+	// Tool-call arguments are JSON text; redaction must leave it valid
+	args := `{"command":"export TOKEN=\"abcdef123456\" && make"}`
+	redacted := r.Redact(args)
+	if strings.Contains(redacted, "abcdef123456") || !json.Valid([]byte(redacted)) {
+		t.Errorf("redacted arguments = %s, want valid JSON without the token", redacted)
+	}
+
+	if _, err := NewRedactor(RedactOptions{Patterns: []string{"("}}); err == nil {
+		t.Error("expected error for invalid pattern")
+	}
+}
+
+type mapTags map[string][]string
+
+func (m mapTags) SessionTags(adapterID, sessionID string) []string {
+	return m[adapterID+"/"+sessionID]
+}
+
+func TestFilter(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	s := adapter.Session{ID: "s1", AdapterID: "codex", UpdatedAt: now}
+
+	tests := []struct {
+		name   string
+		filter Filter
+		want   bool
+	}{
+		{"empty", Filter{}, true},
+		{"adapter match", Filter{Adapters: []string{"codex"}}, true},
+		{"adapter miss", Filter{Adapters: []string{"claude-code"}}, false},
+		{"since", Filter{Since: now.Add(-time.Hour)}, true},
+		{"since miss", Filter{Since: now.Add(time.Hour)}, false},
+		{"until miss", Filter{Until: now.Add(-time.Hour)}, false},
+		{"session id", Filter{SessionIDs: []string{"s2"}}, false},
+		{"tag match", Filter{Tags: []string{"good"}, TagSource: mapTags{"codex/s1": {"good", "eval"}}}, true},
+		{"tag miss", Filter{Tags: []string{"bad"}, TagSource: mapTags{"codex/s1": {"good"}}}, false},
+		{"tag without source", Filter{Tags: []string{"good"}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.MatchesSession(s); got != tt.want {
+				t.Errorf("MatchesSession = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	msgs := []adapter.Message{{Role: "assistant", Model: "claude-opus-4-5-20251101"}}
+	if !(Filter{Models: []string{"Opus"}}).MatchesMessages(msgs) {
+		t.Error("model filter should match case-insensitively")
+	}
+	if (Filter{Models: []string{"sonnet"}}).MatchesMessages(msgs) {
+		t.Error("model filter should not match sonnet")
+	}
+}
+
+func TestExport_Formats(t *testing.T) {
+	stub := &stubAdapter{messages: map[string][]adapter.Message{
+		"s1": claudeStyleMessages(),
+		"s2": {{Role: "user", Content: "hello"}}, // no assistant output
+	}}
+	adapters := map[string]adapter.Adapter{"stub": stub}
+	sessions := []adapter.Session{
+		{ID: "s1", AdapterID: "stub", Name: "fix bug"},
+		{ID: "s2", AdapterID: "stub"},
+	}
+
+	var buf bytes.Buffer
+	stats, err := Export(&buf, adapters, sessions, Options{Format: FormatOpenAI, IncludeMetadata: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Written != 1 || stats.Skipped != 1 {
+		t.Errorf("stats = %+v, want 1 written, 1 skipped", stats)
+	}
+	var rec struct {
+		Messages []ChatMessage `json:"messages"`
+		Metadata Metadata      `json:"metadata"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &rec); err != nil {
+		t.Fatalf("invalid JSONL: %v\n%s", err, buf.String())
+	}
+	if len(rec.Messages) != 4 || rec.Metadata.Model != "claude-opus-4-5" {
+		t.Errorf("unexpected record: %+v", rec)
+	}
+
+	buf.Reset()
+	if _, err := Export(&buf, adapters, sessions[:1], Options{Format: FormatShareGPT}); err != nil {
+		t.Fatal(err)
+	}
+	var sg struct {
+		Conversations []ShareGPTTurn `json:"conversations"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &sg); err != nil {
+		t.Fatalf("invalid ShareGPT JSONL: %v", err)
+	}
+	wantFrom := []string{"human", "gpt", "function_call", "observation", "gpt"}
+	if len(sg.Conversations) != len(wantFrom) {
+		t.Fatalf("got %d turns, want %d: %+v", len(sg.Conversations), len(wantFrom), sg.Conversations)
+	}
+	for i, from := range wantFrom {
+		if sg.Conversations[i].From != from {
+			t.Errorf("turn %d from = %q, want %q", i, sg.Conversations[i].From, from)
+		}
+	}
+	if !strings.Contains(sg.Conversations[2].Value, `"file_path":"main.go"`) {
+		t.Errorf("function_call should embed arguments as JSON, got %s", sg.Conversations[2].Value)
+	}
+}
+
+// mapNotes implements AnnotationSource for tests.
+type mapNotes struct {
+	note        string
+	annotations map[string]string
+}
+
+func (m mapNotes) SessionNote(string, string) string                   { return m.note }
+func (m mapNotes) MessageAnnotations(string, string) map[string]string { return m.annotations }
+
+func TestExport_Annotations(t *testing.T) {
+	msgs := claudeStyleMessages()
+	msgs[0].ID = "u1"
+	msgs[4].ID = "a3"
+	stub := &stubAdapter{messages: map[string][]adapter.Message{"s1": msgs}}
+	sessions := []adapter.Session{{ID: "s1", AdapterID: "stub"}}
+
+	var buf bytes.Buffer
+	_, err := Export(&buf, map[string]adapter.Adapter{"stub": stub}, sessions, Options{
+		IncludeMetadata: true,
+		Filter:          Filter{TagSource: mapTags{"stub/s1": {"good"}}},
+		Annotations:     mapNotes{note: "clean fix", annotations: map[string]string{"a3": "nice summary", "u1": "clear prompt"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var rec struct {
+		Metadata Metadata `json:"metadata"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &rec); err != nil {
+		t.Fatal(err)
+	}
+	m := rec.Metadata
+	if m.Note != "clean fix" || len(m.Tags) != 1 {
+		t.Errorf("note/tags missing: %+v", m)
+	}
+	if len(m.Annotations) != 2 || m.Annotations[0].MessageID != "u1" || m.Annotations[1].Role != "assistant" {
+		t.Errorf("annotations should follow message order: %+v", m.Annotations)
+	}
+}
+
+func TestExport_RedactsMetadata(t *testing.T) {
+	msgs := claudeStyleMessages()
+	msgs[0].ID = "u1"
+	stub := &stubAdapter{messages: map[string][]adapter.Message{"s1": msgs}}
+	sessions := []adapter.Session{{ID: "s1", AdapterID: "stub", Name: "rotate internal-42 for alice@example.com"}}
+	r, err := NewRedactor(RedactOptions{Patterns: []string{`internal-\d+`}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	_, err = Export(&buf, map[string]adapter.Adapter{"stub": stub}, sessions, Options{
+		IncludeMetadata: true,
+		Convert:         ConvertOptions{Redactor: r},
+		Filter:          Filter{TagSource: mapTags{"stub/s1": {"internal-7"}}},
+		Annotations:     mapNotes{note: "used token=hunter22secret", annotations: map[string]string{"u1": "key sk-ant-REDACTED"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{"internal-42", "alice@example.com", "internal-7", "hunter22secret", "sk-ant-"} {
+		if strings.Contains(buf.String(), secret) {
+			t.Errorf("export leaked %q: %s", secret, buf.String())
+		}
+	}
+	var rec struct {
+		Metadata Metadata `json:"metadata"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &rec); err != nil {
+		t.Fatal(err)
+	}
+	m := rec.Metadata
+	if m.Name != "rotate [REDACTED] for [REDACTED]" || len(m.Tags) != 1 || m.Tags[0] != "[REDACTED]" || len(m.Annotations) != 1 {
+		t.Errorf("metadata not redacted as expected: %+v", m)
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	if f, err := ParseFormat("ShareGPT"); err != nil || f != FormatShareGPT {
+		t.Errorf("ParseFormat(ShareGPT) = %v, %v", f, err)
+	}
+	if f, err := ParseFormat(""); err != nil || f != FormatOpenAI {
+		t.Errorf("ParseFormat(\"\") = %v, %v", f, err)
+	}
+	if _, err := ParseFormat("alpaca"); err == nil {
+		t.Error("expected error for unknown format")
+	}
+}
@@ -0,0 +1,4 @@
+// Package dataset converts adapter sessions into training-data formats
+// (OpenAI chat JSONL with tool_calls, ShareGPT) for evals and fine-tuning,
+// with session filters and optional secret redaction.
+package dataset
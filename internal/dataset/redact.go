@@ -0,0 +1,68 @@
+package dataset
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// redactedPlaceholder replaces every redacted match.
+const redactedPlaceholder = "[REDACTED]"
+
+// defaultRedactPatterns match common credentials and personal data. The
+// key=value value stops at a backslash so JSON text, such as tool-call
+// arguments, is never cut inside an escape.
+var defaultRedactPatterns = []string{
+	`-----BEGIN [A-Z ]*PRIVATE KEY-----[\s\S]*?-----END [A-Z ]*PRIVATE KEY-----`,
+	`\bsk-(?:ant-|proj-)?[A-Za-z0-9_\-]{20,}`,                                               // Anthropic / OpenAI API keys
+	`\b(?:ghp|gho|ghu|ghs|ghr)_[A-Za-z0-9]{30,}`,                                            // GitHub tokens
+	`\bgithub_pat_[A-Za-z0-9_]{30,}`,                                                        // GitHub fine-grained tokens
+	`\bxox[abposr]-[A-Za-z0-9\-]{10,}`,                                                      // Slack tokens
+	`\bAKIA[0-9A-Z]{16}\b`,                                                                  // AWS access key IDs
+	`\bAIza[0-9A-Za-z_\-]{35}\b`,                                                            // Google API keys
+	`(?i)\bbearer\s+[A-Za-z0-9_\-\.=]{20,}`,                                                 // Authorization headers
+	`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`,                                      // Email addresses
+	`(?i)\b(?:password|passwd|secret|api[_-]?key|token)\s*[=:]\s*(?:\\?["'])?[^\s"'\\]{6,}`, // key=value secrets
+}
+
+// Redactor scrubs secrets and personal paths from exported text.
+type Redactor struct {
+	patterns []*regexp.Regexp
+	homeDir  string
+}
+
+// RedactOptions configures a Redactor.
+type RedactOptions struct {
+	HomeDir  string   // Replaced with "~" when non-empty
+	Patterns []string // Extra regular expressions to redact
+}
+
+// NewRedactor builds a redactor from the default patterns plus any extras.
+func NewRedactor(opts RedactOptions) (*Redactor, error) {
+	r := &Redactor{homeDir: strings.TrimRight(opts.HomeDir, "/")}
+	for _, p := range defaultRedactPatterns {
+		r.patterns = append(r.patterns, regexp.MustCompile(p))
+	}
+	for _, p := range opts.Patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redact pattern %q: %w", p, err)
+		}
+		r.patterns = append(r.patterns, re)
+	}
+	return r, nil
+}
+
+// Redact returns s with all matches replaced by a placeholder.
+func (r *Redactor) Redact(s string) string {
+	if r == nil || s == "" {
+		return s
+	}
+	for _, re := range r.patterns {
+		s = re.ReplaceAllString(s, redactedPlaceholder)
+	}
+	if r.homeDir != "" && r.homeDir != "/" {
+		s = strings.ReplaceAll(s, r.homeDir, "~")
+	}
+	return s
+}
@@ -0,0 +1,62 @@
+package dataset
+
+import (
+	"encoding/json"
+)
+
+// ShareGPT speaker names.
+const (
+	shareGPTSystem       = "system"
+	shareGPTHuman        = "human"
+	shareGPTAssistant    = "gpt"
+	shareGPTFunctionCall = "function_call"
+	shareGPTObservation  = "observation"
+)
+
+// ShareGPTTurn is a single from/value entry.
+type ShareGPTTurn struct {
+	From  string `json:"from"`
+	Value string `json:"value"`
+}
+
+// shareGPTRecord is one line of ShareGPT JSONL.
+type shareGPTRecord struct {
+	Conversations []ShareGPTTurn `json:"conversations"`
+	Metadata      *Metadata      `json:"metadata,omitempty"`
+}
+
+// shareGPTCall is the value of a function_call turn.
+type shareGPTCall struct {
+	Name      string          `json:"name"`
+	Arguments json.RawMessage `json:"arguments"`
+}
+
+// toShareGPT converts normalized chat messages to ShareGPT turns. Each tool
+// call becomes its own function_call turn following the assistant text, and
+// each tool result an observation turn.
+func toShareGPT(msgs []ChatMessage, meta *Metadata) shareGPTRecord {
+	rec := shareGPTRecord{Metadata: meta}
+	for _, m := range msgs {
+		switch m.Role {
+		case RoleSystem:
+			rec.Conversations = append(rec.Conversations, ShareGPTTurn{From: shareGPTSystem, Value: m.Content})
+		case RoleUser:
+			rec.Conversations = append(rec.Conversations, ShareGPTTurn{From: shareGPTHuman, Value: m.Content})
+		case RoleAssistant:
+			if m.Content != "" {
+				rec.Conversations = append(rec.Conversations, ShareGPTTurn{From: shareGPTAssistant, Value: m.Content})
+			}
+			for _, tc := range m.ToolCalls {
+				args := json.RawMessage(tc.Function.Arguments)
+				if !json.Valid(args) {
+					args, _ = json.Marshal(tc.Function.Arguments)
+				}
+				value, _ := json.Marshal(shareGPTCall{Name: tc.Function.Name, Arguments: args})
+				rec.Conversations = append(rec.Conversations, ShareGPTTurn{From: shareGPTFunctionCall, Value: string(value)})
+			}
+		case RoleTool:
+			rec.Conversations = append(rec.Conversations, ShareGPTTurn{From: shareGPTObservation, Value: m.Content})
+		}
+	}
+	return rec
+}
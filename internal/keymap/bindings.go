@@ -232,6 +232,9 @@ func DefaultBindings() []Binding {
 		{Key: "Y", Command: "yank-resume", Context: "conversations-sidebar"},
 		{Key: "C", Command: "toggle-category", Context: "conversations-sidebar"},
 		{Key: "R", Command: "resume-in-workspace", Context: "conversations-sidebar"},
+		{Key: "x", Command: "compare", Context: "conversations-sidebar"},
+		{Key: "t", Command: "edit-tags", Context: "conversations-sidebar"},
+		{Key: "n", Command: "edit-note", Context: "conversations-sidebar"},
 		{Key: "+", Command: "resize-pane-grow", Context: "conversations-sidebar"},
 		{Key: "-", Command: "resize-pane-shrink", Context: "conversations-sidebar"},
 
@@ -252,9 +255,25 @@ func DefaultBindings() []Binding {
 		{Key: "y", Command: "yank-details", Context: "conversations-main"},
 		{Key: "Y", Command: "yank-resume", Context: "conversations-main"},
 		{Key: "R", Command: "resume-in-workspace", Context: "conversations-main"},
+		{Key: "a", Command: "annotate", Context: "conversations-main"},
 		{Key: "+", Command: "resize-pane-grow", Context: "conversations-main"},
 		{Key: "-", Command: "resize-pane-shrink", Context: "conversations-main"},
 
+		// Conversations search (filter query language)
+		{Key: "enter", Command: "select", Context: "conversations-search"},
+		{Key: "esc", Command: "cancel", Context: "conversations-search"},
+		{Key: "ctrl+s", Command: "save-filter", Context: "conversations-search"},
+
+		// Conversations tag/note/annotation edit modal
+		{Key: "enter", Command: "confirm", Context: "conversations-meta-edit"},
+		{Key: "esc", Command: "cancel", Context: "conversations-meta-edit"},
+
+		// Conversations compare context (side-by-side session comparison)
+		{Key: "esc", Command: "back", Context: "conversations-compare"},
+		{Key: "q", Command: "back", Context: "conversations-compare"},
+		{Key: "j", Command: "scroll", Context: "conversations-compare"},
+		{Key: "k", Command: "scroll", Context: "conversations-compare"},
+
 		// File browser tree context
 		{Key: "tab", Command: "switch-pane", Context: "file-browser-tree"},
 		{Key: "shift+tab", Command: "switch-pane", Context: "file-browser-tree"},
@@ -362,6 +381,9 @@ func DefaultBindings() []Binding {
 		// Worktree context
 		{Key: "n", Command: "new-workspace", Context: "workspace-list"},
 		{Key: "v", Command: "toggle-view", Context: "workspace-list"},
+		{Key: "V", Command: "switch-board", Context: "workspace-list"},
+		{Key: "<", Command: "move-card-left", Context: "workspace-list"},
+		{Key: ">", Command: "move-card-right", Context: "workspace-list"},
 		{Key: "r", Command: "refresh", Context: "workspace-list"},
 		{Key: "D", Command: "delete-workspace", Context: "workspace-list"},
 		{Key: "d", Command: "show-diff", Context: "workspace-list"},
@@ -377,6 +399,7 @@ func DefaultBindings() []Binding {
 		{Key: "N", Command: "reject", Context: "workspace-list"},
 		{Key: "K", Command: "kill-shell", Context: "workspace-list"},
 		{Key: "O", Command: "open-in-git", Context: "workspace-list"},
+		{Key: "H", Command: "jump-to-conversation", Context: "workspace-list"},
 		{Key: "l", Command: "focus-right", Context: "workspace-list"},
 		{Key: "right", Command: "focus-right", Context: "workspace-list"},
 		{Key: "tab", Command: "switch-pane", Context: "workspace-list"},
@@ -385,6 +408,15 @@ func DefaultBindings() []Binding {
 		{Key: "[", Command: "prev-tab", Context: "workspace-list"},
 		{Key: "]", Command: "next-tab", Context: "workspace-list"},
 		{Key: "F", Command: "fetch-pr", Context: "workspace-list"},
+		{Key: "B", Command: "fan-out", Context: "workspace-list"},
+		{Key: "b", Command: "fan-out-board", Context: "workspace-list"},
+		{Key: "Q", Command: "prompt-queue", Context: "workspace-list"},
+		{Key: "C", Command: "review", Context: "workspace-list"},
+		{Key: "A", Command: "archive-workspace", Context: "workspace-list"},
+		{Key: "u", Command: "sync-workspace", Context: "workspace-list"},
+		{Key: "U", Command: "sync-all", Context: "workspace-list"},
+		{Key: "P", Command: "stack-prs", Context: "workspace-list"},
+		{Key: "L", Command: "transcripts", Context: "workspace-list"},
 		{Key: "+", Command: "resize-pane-grow", Context: "workspace-list"},
 		{Key: "-", Command: "resize-pane-shrink", Context: "workspace-list"},
 		{Key: "ctrl+t", Command: "toggle-terminal", Context: "workspace-list"},
@@ -394,6 +426,42 @@ func DefaultBindings() []Binding {
 		{Key: "esc", Command: "cancel", Context: "workspace-fetch-pr"},
 		{Key: "enter", Command: "fetch", Context: "workspace-fetch-pr"},
 
+		// Workspace fan-out comparison board context
+		{Key: "esc", Command: "close", Context: "workspace-fan-out-board"},
+		{Key: "t", Command: "run-tests", Context: "workspace-fan-out-board"},
+		{Key: "w", Command: "pick-winner", Context: "workspace-fan-out-board"},
+		{Key: "enter", Command: "go-to", Context: "workspace-fan-out-board"},
+		{Key: "r", Command: "refresh", Context: "workspace-fan-out-board"},
+		{Key: "y", Command: "confirm-winner", Context: "workspace-fan-out-board"},
+		{Key: "n", Command: "cancel-winner", Context: "workspace-fan-out-board"},
+
+		// Workspace prompt queue context
+		{Key: "esc", Command: "close", Context: "workspace-prompt-queue"},
+		{Key: "enter", Command: "add-prompt", Context: "workspace-prompt-queue"},
+		{Key: "tab", Command: "next-field", Context: "workspace-prompt-queue"},
+		{Key: "p", Command: "toggle-pause", Context: "workspace-prompt-queue"},
+		{Key: "x", Command: "skip-prompt", Context: "workspace-prompt-queue"},
+		{Key: "K", Command: "move-up", Context: "workspace-prompt-queue"},
+		{Key: "J", Command: "move-down", Context: "workspace-prompt-queue"},
+
+		// Workspace review comment editor context
+		{Key: "enter", Command: "save-comment", Context: "workspace-review-comment"},
+		{Key: "esc", Command: "cancel", Context: "workspace-review-comment"},
+
+		// Workspace review context
+		{Key: "esc", Command: "close", Context: "workspace-review"},
+		{Key: "s", Command: "send-review", Context: "workspace-review"},
+		{Key: "enter", Command: "go-to-comment", Context: "workspace-review"},
+		{Key: "x", Command: "delete-comment", Context: "workspace-review"},
+		{Key: "X", Command: "clear-resolved", Context: "workspace-review"},
+
+		// Workspace sync conflict context
+		{Key: "esc", Command: "close", Context: "workspace-sync-conflict"},
+		{Key: "r", Command: "rebase", Context: "workspace-sync-conflict"},
+		{Key: "m", Command: "merge", Context: "workspace-sync-conflict"},
+		{Key: "a", Command: "hand-to-agent", Context: "workspace-sync-conflict"},
+		{Key: "x", Command: "abort", Context: "workspace-sync-conflict"},
+
 		// Workspace preview context
 		{Key: "h", Command: "focus-left", Context: "workspace-preview"},
 		{Key: "left", Command: "focus-left", Context: "workspace-preview"},
@@ -418,11 +486,38 @@ func DefaultBindings() []Binding {
 		{Key: "-", Command: "resize-pane-shrink", Context: "workspace-preview"},
 		{Key: "ctrl+t", Command: "toggle-terminal", Context: "workspace-preview"},
 		{Key: "alt+t", Command: "switch-terminal-layout", Context: "workspace-preview"},
+		{Key: "/", Command: "search-output", Context: "workspace-preview"},
+		{Key: "c", Command: "comment-line", Context: "workspace-preview"},
+		{Key: "C", Command: "review", Context: "workspace-preview"},
+		{Key: "H", Command: "jump-to-conversation", Context: "workspace-preview"},
+
+		// Workspace output search bindings
+		{Key: "enter", Command: "done", Context: "workspace-output-search-input"},
+		{Key: "ctrl+r", Command: "toggle-regex", Context: "workspace-output-search-input"},
+		{Key: "esc", Command: "close", Context: "workspace-output-search-input"},
+		{Key: "n", Command: "next-match", Context: "workspace-output-search"},
+		{Key: "N", Command: "prev-match", Context: "workspace-output-search"},
+		{Key: "y", Command: "copy-block", Context: "workspace-output-search"},
+		{Key: "o", Command: "open-file", Context: "workspace-output-search"},
+		{Key: "/", Command: "edit-query", Context: "workspace-output-search"},
+		{Key: "esc", Command: "close", Context: "workspace-output-search"},
+
+		// Workspace transcripts bindings
+		{Key: "esc", Command: "close", Context: "workspace-transcripts"},
+		{Key: "enter", Command: "open-transcript", Context: "workspace-transcripts"},
+		{Key: "d", Command: "delete-transcript", Context: "workspace-transcripts"},
+		{Key: "r", Command: "refresh", Context: "workspace-transcripts"},
+		{Key: "y", Command: "confirm-delete", Context: "workspace-transcripts"},
 
 		// Workspace merge error context
 		{Key: "esc", Command: "dismiss-merge-error", Context: "workspace-merge-error"},
 		{Key: "y", Command: "yank-merge-error", Context: "workspace-merge-error"},
 
+		// Workspace merge test gate failure context
+		{Key: "esc", Command: "cancel", Context: "workspace-merge-tests"},
+		{Key: "r", Command: "retry-merge-tests", Context: "workspace-merge-tests"},
+		{Key: "f", Command: "send-test-failure", Context: "workspace-merge-tests"},
+
 		// Workspace interactive context bindings are registered dynamically
 		// by the workspace plugin Init() to reflect configured keys.
 
@@ -1,4 +1,6 @@
-package notes
+// Package notestore reads and writes notes in the td database, for the
+// notes plugin and for anything else that references a note by ID.
+package notestore
 
 import (
 	"crypto/rand"
@@ -35,9 +37,6 @@ const (
 	ActionCreate ActionType = "create"
 	ActionUpdate ActionType = "update"
 	ActionDelete ActionType = "delete"
-
-	// maxTitleLength is the maximum length for note titles (truncated when displaying)
-	maxTitleLength = 80
 )
 
 // Store handles SQLite operations for notes.
@@ -6,12 +6,14 @@ import (
 	"github.com/marcus/sidecar/internal/adapter"
 	"github.com/marcus/sidecar/internal/config"
 	"github.com/marcus/sidecar/internal/event"
+	"github.com/marcus/sidecar/internal/keymap"
 )
 
 // BindingRegistrar allows plugins to register key bindings dynamically.
 // This is implemented by keymap.Registry.
 type BindingRegistrar interface {
 	RegisterPluginBinding(key, command, context string)
+	BindingsForContext(context string) []keymap.Binding
 }
 
 // Context provides shared resources to plugins during initialization.
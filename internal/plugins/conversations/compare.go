@@ -0,0 +1,362 @@
+package conversations
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/marcus/sidecar/internal/adapter"
+	appmsg "github.com/marcus/sidecar/internal/msg"
+)
+
+// Side-by-side comparison of two sessions, e.g. the same task run through
+// Claude and Codex in separate worktrees.
+
+// CompareSide holds one session's data in compare mode.
+type CompareSide struct {
+	Session   adapter.Session
+	Summary   SessionSummary
+	Exchanges []Exchange
+	Root      string // Worktree root the session ran in
+}
+
+// Cost returns the computed cost, falling back to the adapter estimate.
+func (s CompareSide) Cost() float64 {
+	if s.Summary.TotalCost > 0 {
+		return s.Summary.TotalCost
+	}
+	return s.Session.EstCost
+}
+
+// ToolTotal returns the total number of tool calls.
+func (s CompareSide) ToolTotal() int {
+	total := 0
+	for _, n := range s.Summary.ToolCounts {
+		total += n
+	}
+	return total
+}
+
+// Exchange is a user prompt together with the assistant work it triggered.
+type Exchange struct {
+	Prompt    string
+	ToolCount int
+	TokensOut int
+	Duration  time.Duration
+}
+
+// ExchangePair aligns the i-th exchange of each session. Either side may be
+// nil when one session has more exchanges than the other.
+type ExchangePair struct {
+	Left  *Exchange
+	Right *Exchange
+}
+
+// FileDiff is the difference between the final content of one file in the
+// two sessions' worktrees.
+type FileDiff struct {
+	Path    string   // Path relative to the worktree root
+	Status  string   // "modified", "left-only", "right-only"
+	Lines   []string // Unified diff body (hunks only)
+	Added   int
+	Removed int
+}
+
+// compareState holds the loaded comparison.
+type compareState struct {
+	Left      CompareSide
+	Right     CompareSide
+	Pairs     []ExchangePair
+	FileDiffs []FileDiff
+	SameRoot  bool // Both sessions ran in the same directory; file diff not meaningful
+}
+
+// CompareLoadedMsg carries a completed comparison.
+type CompareLoadedMsg struct {
+	Epoch uint64
+	State *compareState
+	Err   error
+}
+
+// GetEpoch implements plugin.EpochMessage.
+func (m CompareLoadedMsg) GetEpoch() uint64 { return m.Epoch }
+
+// isPromptTurn reports whether a user turn contains text typed by the user,
+// as opposed to only carrying tool results back to the model.
+func isPromptTurn(t Turn) bool {
+	if t.Role != "user" {
+		return false
+	}
+	for _, msg := range t.Messages {
+		if len(msg.ContentBlocks) == 0 {
+			if strings.TrimSpace(msg.Content) != "" {
+				return true
+			}
+			continue
+		}
+		for _, b := range msg.ContentBlocks {
+			if b.Type == "text" && strings.TrimSpace(b.Text) != "" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// BuildExchanges groups turns into prompt/response exchanges. Assistant work
+// before the first prompt is attached to an exchange with an empty prompt.
+func BuildExchanges(turns []Turn) []Exchange {
+	var out []Exchange
+	var cur *Exchange
+	var start, end time.Time
+
+	flush := func() {
+		if cur == nil {
+			return
+		}
+		if !start.IsZero() && end.After(start) {
+			cur.Duration = end.Sub(start)
+		}
+		out = append(out, *cur)
+		cur = nil
+	}
+
+	for _, t := range turns {
+		if isPromptTurn(t) {
+			flush()
+			cur = &Exchange{Prompt: t.Preview(200)}
+			start, end = time.Time{}, time.Time{}
+		} else if cur == nil {
+			cur = &Exchange{}
+		}
+		for _, msg := range t.Messages {
+			if msg.Timestamp.IsZero() {
+				continue
+			}
+			if start.IsZero() {
+				start = msg.Timestamp
+			}
+			end = msg.Timestamp
+		}
+		if t.Role == "assistant" {
+			cur.ToolCount += t.ToolCount
+			cur.TokensOut += t.TotalTokensOut
+		}
+	}
+	flush()
+	return out
+}
+
+// AlignExchanges pairs exchanges by position.
+func AlignExchanges(left, right []Exchange) []ExchangePair {
+	n := max(len(left), len(right))
+	pairs := make([]ExchangePair, n)
+	for i := range n {
+		if i < len(left) {
+			pairs[i].Left = &left[i]
+		}
+		if i < len(right) {
+			pairs[i].Right = &right[i]
+		}
+	}
+	return pairs
+}
+
+// relativeToRoots converts a tool file path into a path relative to whichever
+// worktree root contains it. Relative paths are returned unchanged; absolute
+// paths outside both roots return "".
+func relativeToRoots(path string, roots ...string) string {
+	if !filepath.IsAbs(path) {
+		return filepath.Clean(path)
+	}
+	for _, root := range roots {
+		if root == "" {
+			continue
+		}
+		if rel, err := filepath.Rel(root, path); err == nil && !strings.HasPrefix(rel, "..") {
+			return rel
+		}
+	}
+	return ""
+}
+
+// diffWorktreeFiles compares the final content of each touched file between
+// the two worktree roots. Files identical on both sides are omitted.
+func diffWorktreeFiles(leftRoot, rightRoot string, leftFiles, rightFiles []string) []FileDiff {
+	relSet := make(map[string]bool)
+	for _, f := range leftFiles {
+		if rel := relativeToRoots(f, leftRoot, rightRoot); rel != "" {
+			relSet[rel] = true
+		}
+	}
+	for _, f := range rightFiles {
+		if rel := relativeToRoots(f, rightRoot, leftRoot); rel != "" {
+			relSet[rel] = true
+		}
+	}
+	rels := make([]string, 0, len(relSet))
+	for rel := range relSet {
+		rels = append(rels, rel)
+	}
+	sort.Strings(rels)
+
+	var diffs []FileDiff
+	for _, rel := range rels {
+		leftPath := filepath.Join(leftRoot, rel)
+		rightPath := filepath.Join(rightRoot, rel)
+		leftExists := fileExists(leftPath)
+		rightExists := fileExists(rightPath)
+
+		fd := FileDiff{Path: rel, Status: "modified"}
+		switch {
+		case !leftExists && !rightExists:
+			continue
+		case !leftExists:
+			fd.Status = "right-only"
+			leftPath = os.DevNull
+		case !rightExists:
+			fd.Status = "left-only"
+			rightPath = os.DevNull
+		}
+
+		out, err := exec.Command("git", "diff", "--no-index", "--no-color", "-U3", "--", leftPath, rightPath).Output()
+		var exitErr *exec.ExitError
+		if err != nil && !(errors.As(err, &exitErr) && exitErr.ExitCode() == 1) {
+			continue
+		}
+		if len(bytes.TrimSpace(out)) == 0 {
+			continue // identical
+		}
+		fd.Lines, fd.Added, fd.Removed = diffBody(string(out))
+		diffs = append(diffs, fd)
+	}
+	return diffs
+}
+
+// diffBody strips git's file headers and counts added/removed lines.
+func diffBody(out string) (lines []string, added, removed int) {
+	inHunk := false
+	for _, line := range strings.Split(strings.TrimRight(out, "\n"), "\n") {
+		if strings.HasPrefix(line, "@@") {
+			inHunk = true
+		}
+		if !inHunk {
+			continue
+		}
+		switch {
+		case strings.HasPrefix(line, "+"):
+			added++
+		case strings.HasPrefix(line, "-"):
+			removed++
+		}
+		lines = append(lines, line)
+	}
+	return lines, added, removed
+}
+
+func fileExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
+
+// compareKey identifies a session for compare marking.
+func compareKey(s adapter.Session) string {
+	return s.AdapterID + "/" + s.ID
+}
+
+// sessionRoot returns the directory the session's agent ran in.
+func (p *Plugin) sessionRoot(s adapter.Session) string {
+	if s.WorktreePath != "" {
+		return s.WorktreePath
+	}
+	if p.ctx != nil {
+		return p.ctx.WorkDir
+	}
+	return ""
+}
+
+// toggleCompare marks the session under the cursor for comparison, or opens
+// the compare view when a different session is already marked.
+func (p *Plugin) toggleCompare() tea.Cmd {
+	sessions := p.visibleSessions()
+	if p.cursor >= len(sessions) {
+		return nil
+	}
+	current := sessions[p.cursor]
+
+	if p.compareMark == nil || compareKey(*p.compareMark) == compareKey(current) {
+		if p.compareMark != nil {
+			p.compareMark = nil
+			return appmsg.ShowToast("Compare mark cleared", 2*time.Second)
+		}
+		marked := current
+		p.compareMark = &marked
+		return appmsg.ShowToast("Marked for compare — select another session and press x", 2*time.Second)
+	}
+
+	left := *p.compareMark
+	p.compareMark = nil
+	p.view = ViewCompare
+	p.compare = nil
+	p.compareErr = nil
+	p.compareScrollOff = 0
+	return p.loadCompare(left, current)
+}
+
+// loadCompare loads both sessions and computes the comparison off the UI goroutine.
+func (p *Plugin) loadCompare(left, right adapter.Session) tea.Cmd {
+	var epoch uint64
+	if p.ctx != nil {
+		epoch = p.ctx.Epoch
+	}
+	leftAdapter := p.adapters[left.AdapterID]
+	rightAdapter := p.adapters[right.AdapterID]
+	leftRoot := p.sessionRoot(left)
+	rightRoot := p.sessionRoot(right)
+
+	return func() tea.Msg {
+		if leftAdapter == nil || rightAdapter == nil {
+			return CompareLoadedMsg{Epoch: epoch, Err: errors.New("adapter not available")}
+		}
+		leftMsgs, err := leftAdapter.Messages(left.ID)
+		if err != nil {
+			return CompareLoadedMsg{Epoch: epoch, Err: err}
+		}
+		rightMsgs, err := rightAdapter.Messages(right.ID)
+		if err != nil {
+			return CompareLoadedMsg{Epoch: epoch, Err: err}
+		}
+
+		st := &compareState{
+			Left:     buildCompareSide(left, leftMsgs, leftRoot),
+			Right:    buildCompareSide(right, rightMsgs, rightRoot),
+			SameRoot: filepath.Clean(leftRoot) == filepath.Clean(rightRoot),
+		}
+		st.Pairs = AlignExchanges(st.Left.Exchanges, st.Right.Exchanges)
+		if !st.SameRoot {
+			st.FileDiffs = diffWorktreeFiles(leftRoot, rightRoot,
+				st.Left.Summary.FilesTouched, st.Right.Summary.FilesTouched)
+		}
+		return CompareLoadedMsg{Epoch: epoch, State: st}
+	}
+}
+
+// buildCompareSide computes summary and exchanges for one session.
+func buildCompareSide(s adapter.Session, messages []adapter.Message, root string) CompareSide {
+	duration := s.Duration
+	if duration == 0 && len(messages) > 1 {
+		duration = messages[len(messages)-1].Timestamp.Sub(messages[0].Timestamp)
+	}
+	return CompareSide{
+		Session:   s,
+		Summary:   ComputeSessionSummary(messages, duration),
+		Exchanges: BuildExchanges(GroupMessagesIntoTurns(messages)),
+		Root:      root,
+	}
+}
@@ -0,0 +1,124 @@
+package conversations
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/marcus/sidecar/internal/adapter"
+)
+
+func TestBuildExchanges(t *testing.T) {
+	now := time.Now()
+	messages := []adapter.Message{
+		{Role: "user", Content: "Fix the bug", Timestamp: now},
+		{Role: "assistant", Content: "Looking", Timestamp: now.Add(time.Second),
+			ToolUses: []adapter.ToolUse{{ID: "t1", Name: "Read"}}, TokenUsage: adapter.TokenUsage{OutputTokens: 100}},
+		{Role: "user", Timestamp: now.Add(2 * time.Second),
+			ContentBlocks: []adapter.ContentBlock{{Type: "tool_result", ToolUseID: "t1", ToolOutput: "ok"}}},
+		{Role: "assistant", Content: "Done", Timestamp: now.Add(10 * time.Second),
+			ToolUses: []adapter.ToolUse{{ID: "t2", Name: "Edit"}}, TokenUsage: adapter.TokenUsage{OutputTokens: 50}},
+		{Role: "user", Content: "Now add tests", Timestamp: now.Add(20 * time.Second)},
+		{Role: "assistant", Content: "Added", Timestamp: now.Add(25 * time.Second)},
+	}
+
+	exchanges := BuildExchanges(GroupMessagesIntoTurns(messages))
+	if len(exchanges) != 2 {
+		t.Fatalf("expected 2 exchanges, got %d", len(exchanges))
+	}
+	if exchanges[0].Prompt != "Fix the bug" {
+		t.Errorf("prompt = %q", exchanges[0].Prompt)
+	}
+	if exchanges[0].ToolCount != 2 {
+		t.Errorf("tool count = %d, want 2 (tool-result turn should not start a new exchange)", exchanges[0].ToolCount)
+	}
+	if exchanges[0].TokensOut != 150 {
+		t.Errorf("tokens out = %d, want 150", exchanges[0].TokensOut)
+	}
+	if exchanges[0].Duration != 10*time.Second {
+		t.Errorf("duration = %v, want 10s", exchanges[0].Duration)
+	}
+	if exchanges[1].Prompt != "Now add tests" {
+		t.Errorf("second prompt = %q", exchanges[1].Prompt)
+	}
+}
+
+func TestAlignExchanges(t *testing.T) {
+	left := []Exchange{{Prompt: "a"}, {Prompt: "b"}, {Prompt: "c"}}
+	right := []Exchange{{Prompt: "x"}}
+
+	pairs := AlignExchanges(left, right)
+	if len(pairs) != 3 {
+		t.Fatalf("expected 3 pairs, got %d", len(pairs))
+	}
+	if pairs[0].Left.Prompt != "a" || pairs[0].Right.Prompt != "x" {
+		t.Errorf("pair 0 misaligned: %+v", pairs[0])
+	}
+	if pairs[2].Left.Prompt != "c" || pairs[2].Right != nil {
+		t.Errorf("pair 2 should have only a left side: %+v", pairs[2])
+	}
+}
+
+func TestRelativeToRoots(t *testing.T) {
+	tests := []struct {
+		path  string
+		roots []string
+		want  string
+	}{
+		{"src/main.go", []string{"/repo"}, "src/main.go"},
+		{"/repo/src/main.go", []string{"/repo"}, "src/main.go"},
+		{"/wt/src/main.go", []string{"/repo", "/wt"}, "src/main.go"},
+		{"/elsewhere/main.go", []string{"/repo", "/wt"}, ""},
+	}
+	for _, tt := range tests {
+		if got := relativeToRoots(tt.path, tt.roots...); got != tt.want {
+			t.Errorf("relativeToRoots(%q, %v) = %q, want %q", tt.path, tt.roots, got, tt.want)
+		}
+	}
+}
+
+func TestDiffBody(t *testing.T) {
+	out := "diff --git a/x b/x\nindex 1..2 100644\n--- a/x\n+++ b/x\n@@ -1,2 +1,2 @@\n same\n-old\n+new\n+extra\n"
+	lines, added, removed := diffBody(out)
+	if added != 2 || removed != 1 {
+		t.Errorf("added=%d removed=%d, want 2/1", added, removed)
+	}
+	if len(lines) != 5 || lines[0] != "@@ -1,2 +1,2 @@" {
+		t.Errorf("unexpected lines: %q", lines)
+	}
+}
+
+func TestDiffWorktreeFiles(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	left := t.TempDir()
+	right := t.TempDir()
+	write := func(root, name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(root, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(left, "same.txt", "hello\n")
+	write(right, "same.txt", "hello\n")
+	write(left, "changed.txt", "one\n")
+	write(right, "changed.txt", "two\n")
+	write(right, "new.txt", "fresh\n")
+
+	diffs := diffWorktreeFiles(left, right,
+		[]string{filepath.Join(left, "same.txt"), filepath.Join(left, "changed.txt")},
+		[]string{filepath.Join(right, "changed.txt"), filepath.Join(right, "new.txt")})
+
+	if len(diffs) != 2 {
+		t.Fatalf("expected 2 diffs, got %d: %+v", len(diffs), diffs)
+	}
+	if diffs[0].Path != "changed.txt" || diffs[0].Status != "modified" || diffs[0].Added != 1 || diffs[0].Removed != 1 {
+		t.Errorf("unexpected changed.txt diff: %+v", diffs[0])
+	}
+	if diffs[1].Path != "new.txt" || diffs[1].Status != "right-only" || diffs[1].Added != 1 {
+		t.Errorf("unexpected new.txt diff: %+v", diffs[1])
+	}
+}
@@ -9,10 +9,12 @@ import (
 
 	"github.com/atotto/clipboard"
 	"github.com/marcus/sidecar/internal/adapter"
+	"github.com/marcus/sidecar/internal/sessionmeta"
 )
 
 // ExportSessionAsMarkdown converts a session and its messages to markdown format.
-func ExportSessionAsMarkdown(session *adapter.Session, messages []adapter.Message) string {
+// Tags, the session note, and message annotations from meta are included.
+func ExportSessionAsMarkdown(session *adapter.Session, messages []adapter.Message, meta sessionmeta.Meta) string {
 	var sb strings.Builder
 
 	// Header
@@ -36,6 +38,15 @@ func ExportSessionAsMarkdown(session *adapter.Session, messages []adapter.Messag
 		if session.EstCost > 0 {
 			sb.WriteString(fmt.Sprintf("**Estimated Cost**: $%.2f\n", session.EstCost))
 		}
+		if meta.Starred {
+			sb.WriteString("**Starred**: yes\n")
+		}
+		if len(meta.Tags) > 0 {
+			sb.WriteString(fmt.Sprintf("**Tags**: %s\n", strings.Join(meta.Tags, ", ")))
+		}
+		if meta.Note != "" {
+			sb.WriteString(fmt.Sprintf("**Note**: %s\n", meta.Note))
+		}
 		sb.WriteString("\n---\n\n")
 	}
 
@@ -73,6 +84,11 @@ func ExportSessionAsMarkdown(session *adapter.Session, messages []adapter.Messag
 		sb.WriteString(msg.Content)
 		sb.WriteString("\n\n")
 
+		// User annotation
+		if note, ok := meta.Annotation(msg.ID); ok {
+			sb.WriteString(fmt.Sprintf("> **Annotation:** %s\n\n", note))
+		}
+
 		// Tool uses
 		if len(msg.ToolUses) > 0 {
 			sb.WriteString("**Tools used:**\n")
@@ -99,8 +115,8 @@ func CopyToClipboard(content string) error {
 }
 
 // ExportSessionToFile writes a session to a markdown file.
-func ExportSessionToFile(session *adapter.Session, messages []adapter.Message, workDir string) (string, error) {
-	md := ExportSessionAsMarkdown(session, messages)
+func ExportSessionToFile(session *adapter.Session, messages []adapter.Message, meta sessionmeta.Meta, workDir string) (string, error) {
+	md := ExportSessionAsMarkdown(session, messages, meta)
 
 	// Generate filename from session name or ID
 	name := "session"
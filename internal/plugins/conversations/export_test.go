@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/marcus/sidecar/internal/adapter"
+	"github.com/marcus/sidecar/internal/sessionmeta"
 )
 
 func TestSanitizeFilename(t *testing.T) {
@@ -66,7 +67,7 @@ func TestExportSessionAsMarkdown_NilSession(t *testing.T) {
 	messages := []adapter.Message{
 		{Role: "user", Content: "hello", Timestamp: time.Now()},
 	}
-	result := ExportSessionAsMarkdown(nil, messages)
+	result := ExportSessionAsMarkdown(nil, messages, sessionmeta.Meta{})
 	if !strings.Contains(result, "Unknown Session") {
 		t.Error("nil session should use 'Unknown Session'")
 	}
@@ -106,7 +107,7 @@ func TestExportSessionAsMarkdown_BasicSession(t *testing.T) {
 		},
 	}
 
-	result := ExportSessionAsMarkdown(session, messages)
+	result := ExportSessionAsMarkdown(session, messages, sessionmeta.Meta{})
 
 	checks := []string{
 		"Test Session",
@@ -132,7 +133,7 @@ func TestExportSessionAsMarkdown_EmptyMessages(t *testing.T) {
 		Name:      "Empty",
 		CreatedAt: time.Now(),
 	}
-	result := ExportSessionAsMarkdown(session, nil)
+	result := ExportSessionAsMarkdown(session, nil, sessionmeta.Meta{})
 	if !strings.Contains(result, "Empty") {
 		t.Error("session name should be in header")
 	}
@@ -157,7 +158,7 @@ func TestExportSessionAsMarkdown_WithThinkingBlocks(t *testing.T) {
 		},
 	}
 
-	result := ExportSessionAsMarkdown(session, messages)
+	result := ExportSessionAsMarkdown(session, messages, sessionmeta.Meta{})
 	if !strings.Contains(result, "<details>") {
 		t.Error("thinking blocks should use <details> tag")
 	}
@@ -168,3 +169,28 @@ func TestExportSessionAsMarkdown_WithThinkingBlocks(t *testing.T) {
 		t.Error("should show token count in thinking summary")
 	}
 }
+
+func TestExportSessionAsMarkdown_WithMeta(t *testing.T) {
+	session := &adapter.Session{ID: "s1", Name: "Tagged"}
+	messages := []adapter.Message{
+		{ID: "m1", Role: "user", Content: "Do it", Timestamp: time.Now()},
+		{ID: "m2", Role: "assistant", Content: "Done", Timestamp: time.Now()},
+	}
+	meta := sessionmeta.Meta{
+		Starred:     true,
+		Tags:        []string{"bug", "good"},
+		Note:        "Reference fix",
+		Annotations: []sessionmeta.Annotation{{MessageID: "m2", Text: "clean answer"}},
+	}
+
+	result := ExportSessionAsMarkdown(session, messages, meta)
+
+	for _, want := range []string{"**Tags**: bug, good", "**Note**: Reference fix", "**Starred**: yes", "> **Annotation:** clean answer"} {
+		if !strings.Contains(result, want) {
+			t.Errorf("expected %q in export:\n%s", want, result)
+		}
+	}
+	if strings.Index(result, "clean answer") < strings.Index(result, "Done") {
+		t.Error("annotation should follow the annotated message")
+	}
+}
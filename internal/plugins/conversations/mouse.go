@@ -14,6 +14,10 @@ func (p *Plugin) handleMouse(msg tea.MouseMsg) (*Plugin, tea.Cmd) {
 		return p, cmd
 	}
 
+	if p.showMetaEditModal {
+		return p, p.handleMetaEditMouse(msg)
+	}
+
 	action := p.mouseHandler.HandleMouse(msg)
 
 	switch action.Type {
@@ -18,6 +18,8 @@ import (
 	"github.com/marcus/sidecar/internal/modal"
 	"github.com/marcus/sidecar/internal/mouse"
 	"github.com/marcus/sidecar/internal/plugin"
+	"github.com/marcus/sidecar/internal/sessionmeta"
+	"github.com/marcus/sidecar/internal/sessionquery"
 	"github.com/marcus/sidecar/internal/state"
 	"github.com/marcus/sidecar/internal/ui"
 )
@@ -70,6 +72,7 @@ const (
 	ViewMessages
 	ViewAnalytics
 	ViewMessageDetail
+	ViewCompare
 )
 
 // FocusPane represents which pane is active in two-pane mode.
@@ -108,6 +111,7 @@ type Plugin struct {
 	// Message view state
 	selectedSession string
 	loadedSession   string // sessionID that p.messages currently represent
+	pendingFocus    string // session another plugin asked to open, until it loads
 	messages        []adapter.Message
 	turns           []Turn // messages grouped into turns
 	turnCursor      int    // cursor for turn selection in list view
@@ -137,6 +141,13 @@ type Plugin struct {
 	analyticsScrollOff int
 	analyticsLines     []string // pre-rendered lines for scrolling
 
+	// Compare view state
+	compareMark      *adapter.Session // session marked as the left side
+	compare          *compareState
+	compareErr       error
+	compareScrollOff int
+	compareLines     []string // pre-rendered lines for scrolling
+
 	// Layout state
 	activePane         FocusPane // Which pane is focused
 	sidebarRestore     FocusPane // Tracks pane focused before collapse; restored on expand via toggleSidebar()
@@ -168,9 +179,15 @@ type Plugin struct {
 	adapterSpinner   ui.BrailleSpinner // animated loading indicator while adapters load
 
 	// Search state
-	searchMode    bool
-	searchQuery   string
-	searchResults []adapter.Session
+	searchMode     bool
+	searchQuery    string
+	searchResults  []adapter.Session
+	searchParsed   *sessionquery.Query // searchQuery parsed as a filter query
+	searchQueryErr error               // parse error; search falls back to plain text
+
+	// Message-derived details for query terms like model:, file:, tool:
+	queryDetails        map[string]queryDetailsEntry // adapter/session key -> details
+	queryDetailsLoading bool
 
 	// Filter state
 	filterMode            bool
@@ -244,6 +261,17 @@ type Plugin struct {
 	resumeFocus           int
 	resumeSession         *adapter.Session
 
+	// Session metadata (stars, tags, notes, annotations) and its edit modal
+	meta               *sessionmeta.Store
+	showMetaEditModal  bool
+	metaEditModal      *modal.Modal
+	metaEditModalWidth int
+	metaEditKind       metaEditKind
+	metaEditSession    adapter.Session
+	metaEditMessageID  string
+	metaEditQuery      string // query being saved (metaEditSavedFilter)
+	metaEditInput      textinput.Model
+
 	// Content search state (td-6ac70a: cross-conversation search)
 	contentSearchMode  bool                // True when content search modal is open
 	contentSearchState *ContentSearchState // Content search state
@@ -339,6 +367,7 @@ func (p *Plugin) resetState() {
 	// Message view state
 	p.selectedSession = ""
 	p.loadedSession = ""
+	p.pendingFocus = ""
 	p.messages = nil
 	p.turns = nil
 	p.turnCursor = 0
@@ -367,6 +396,13 @@ func (p *Plugin) resetState() {
 	p.analyticsScrollOff = 0
 	p.analyticsLines = nil
 
+	// Compare view state
+	p.compareMark = nil
+	p.compare = nil
+	p.compareErr = nil
+	p.compareScrollOff = 0
+	p.compareLines = nil
+
 	// Layout state - reset to defaults but preserve sidebarWidth (persisted)
 	p.activePane = PaneSidebar
 	p.sidebarRestore = PaneSidebar
@@ -378,6 +414,10 @@ func (p *Plugin) resetState() {
 	p.searchMode = false
 	p.searchQuery = ""
 	p.searchResults = nil
+	p.searchParsed = nil
+	p.searchQueryErr = nil
+	p.queryDetails = nil
+	p.queryDetailsLoading = false
 
 	// Filter state
 	p.filterMode = false
@@ -449,6 +489,15 @@ func (p *Plugin) Init(ctx *plugin.Context) error {
 		p.sidebarWidth = savedWidth
 	}
 
+	// Open sidecar-owned session metadata store
+	if store, err := sessionmeta.Open(sessionmeta.DefaultPath()); err != nil {
+		if ctx.Logger != nil {
+			ctx.Logger.Warn("failed to load session metadata", "error", err)
+		}
+	} else {
+		p.meta = store
+	}
+
 	// Store default category filter from config for C toggle (td-91bbc4)
 	// Don't apply on startup — non-Pi adapters leave SessionCategory empty,
 	// so filtering by "interactive" would hide all their sessions (td-d3b1f6)
@@ -532,6 +581,9 @@ func (p *Plugin) Update(msg tea.Msg) (plugin.Plugin, tea.Cmd) {
 		}
 		return p, nil
 
+	case app.FocusConversationMsg:
+		return p, p.focusSession(msg.SessionID)
+
 	case ui.SkeletonTickMsg:
 		// Forward tick to skeleton for animation (td-6cc19f)
 		var cmds []tea.Cmd
@@ -566,9 +618,16 @@ func (p *Plugin) Update(msg tea.Msg) (plugin.Plugin, tea.Cmd) {
 			return p, cmd
 		}
 
+		// Handle tag/note/annotation edit modal if open
+		if p.showMetaEditModal {
+			return p, p.handleMetaEditKeys(msg)
+		}
+
 		switch p.view {
 		case ViewAnalytics:
 			return p.updateAnalytics(msg)
+		case ViewCompare:
+			return p.updateCompare(msg)
 		default:
 			// Route based on active pane
 			if p.activePane == PaneMessages {
@@ -577,6 +636,20 @@ func (p *Plugin) Update(msg tea.Msg) (plugin.Plugin, tea.Cmd) {
 			return p.updateSessions(msg)
 		}
 
+	case QueryDetailsLoadedMsg:
+		if plugin.IsStale(p.ctx, msg) {
+			return p, nil
+		}
+		return p, p.handleQueryDetailsLoaded(msg)
+
+	case CompareLoadedMsg:
+		if plugin.IsStale(p.ctx, msg) {
+			return p, nil
+		}
+		p.compare = msg.State
+		p.compareErr = msg.Err
+		return p, nil
+
 	case LoadingStartedMsg:
 		if plugin.IsStale(p.ctx, msg) {
 			return p, nil
@@ -635,6 +708,10 @@ func (p *Plugin) Update(msg tea.Msg) (plugin.Plugin, tea.Cmd) {
 				p.cachedWorktreeNames = msg.WorktreeNames
 				p.worktreeCacheTime = time.Now()
 			}
+			// Load details for any new sessions the active query needs
+			if cmd := p.queryDetailsCmd(); cmd != nil {
+				cmds = append(cmds, cmd)
+			}
 			// Check for large session warnings
 			if cmd := p.checkLargeSessionWarnings(); cmd != nil {
 				cmds = append(cmds, cmd)
@@ -664,6 +741,9 @@ func (p *Plugin) Update(msg tea.Msg) (plugin.Plugin, tea.Cmd) {
 				cmds = append(cmds, p.schedulePreviewLoad(p.selectedSession))
 			}
 		}
+		if cmd := p.applyPendingFocus(msg.Final); cmd != nil {
+			cmds = append(cmds, cmd)
+		}
 
 		p.updateTieredHotTargets()
 		return p, tea.Batch(cmds...)
@@ -735,9 +815,15 @@ func (p *Plugin) Update(msg tea.Msg) (plugin.Plugin, tea.Cmd) {
 		if warningCmd != nil {
 			cmds = append(cmds, warningCmd)
 		}
+		if detailsCmd := p.queryDetailsCmd(); detailsCmd != nil {
+			cmds = append(cmds, detailsCmd)
+		}
 		if settleCmd != nil {
 			cmds = append(cmds, settleCmd)
 		}
+		if cmd := p.applyPendingFocus(true); cmd != nil {
+			cmds = append(cmds, cmd)
+		}
 		p.updateTieredHotTargets()
 		if len(cmds) > 0 {
 			return p, tea.Batch(cmds...)
@@ -1091,6 +1177,12 @@ func (p *Plugin) View(width, height int) string {
 		return lipgloss.NewStyle().Width(width).Height(height).MaxHeight(height).Render(content)
 	}
 
+	// Handle tag/note/annotation edit modal overlay
+	if p.showMetaEditModal {
+		content := p.renderMetaEditModal(width, height)
+		return lipgloss.NewStyle().Width(width).Height(height).MaxHeight(height).Render(content)
+	}
+
 	var content string
 	if len(p.adapters) == 0 {
 		content = renderNoAdapter()
@@ -1098,6 +1190,8 @@ func (p *Plugin) View(width, height int) string {
 		switch p.view {
 		case ViewAnalytics:
 			content = p.renderAnalytics()
+		case ViewCompare:
+			content = p.renderCompare()
 		default:
 			content = p.renderTwoPane()
 		}
@@ -1127,10 +1221,17 @@ func (p *Plugin) Commands() []plugin.Command {
 			{ID: "case", Name: "Case", Description: "Toggle alt+c", Category: plugin.CategoryView, Context: "conversations-content-search", Priority: 6},
 		}
 	}
+	if p.showMetaEditModal {
+		return []plugin.Command{
+			{ID: "confirm", Name: "Save", Description: "Save changes", Category: plugin.CategoryActions, Context: "conversations-meta-edit", Priority: 1},
+			{ID: "cancel", Name: "Cancel", Description: "Discard changes", Category: plugin.CategoryActions, Context: "conversations-meta-edit", Priority: 1},
+		}
+	}
 	if p.searchMode {
 		return []plugin.Command{
 			{ID: "select", Name: "Select", Description: "Select search result", Category: plugin.CategoryActions, Context: "conversations-search", Priority: 1},
 			{ID: "cancel", Name: "Cancel", Description: "Cancel search", Category: plugin.CategoryActions, Context: "conversations-search", Priority: 1},
+			{ID: "save-filter", Name: "Save", Description: "Save query as a named filter", Category: plugin.CategoryActions, Context: "conversations-search", Priority: 2},
 		}
 	}
 	if p.filterMode {
@@ -1156,6 +1257,7 @@ func (p *Plugin) Commands() []plugin.Command {
 			{ID: "back", Name: "Back", Description: "Return to sidebar", Category: plugin.CategoryNavigation, Context: "conversations-main", Priority: 4},
 			{ID: "open", Name: "Open", Description: "Open in CLI", Category: plugin.CategoryActions, Context: "conversations-main", Priority: 5},
 			{ID: "yank", Name: "Yank", Description: "Yank turn content", Category: plugin.CategoryActions, Context: "conversations-main", Priority: 6},
+			{ID: "annotate", Name: "Annotate", Description: "Annotate selected message", Category: plugin.CategoryActions, Context: "conversations-main", Priority: 6},
 			{ID: "toggle-sidebar", Name: "Sidebar", Description: "Toggle sidebar visibility", Category: plugin.CategoryView, Context: "conversations-main", Priority: 7},
 		}
 	}
@@ -1164,6 +1266,12 @@ func (p *Plugin) Commands() []plugin.Command {
 			{ID: "back", Name: "Back", Description: "Return to conversations", Category: plugin.CategoryNavigation, Context: "analytics", Priority: 1},
 		}
 	}
+	if p.view == ViewCompare {
+		return []plugin.Command{
+			{ID: "back", Name: "Back", Description: "Return to conversations", Category: plugin.CategoryNavigation, Context: "conversations-compare", Priority: 1},
+			{ID: "scroll", Name: "Scroll", Description: "Scroll comparison", Category: plugin.CategoryNavigation, Context: "conversations-compare", Priority: 2},
+		}
+	}
 	return []plugin.Command{
 		{ID: "view-session", Name: "View", Description: "View session messages", Category: plugin.CategoryView, Context: "conversations-sidebar", Priority: 1},
 		{ID: "search", Name: "Search", Description: "Search conversations", Category: plugin.CategorySearch, Context: "conversations-sidebar", Priority: 2},
@@ -1173,6 +1281,10 @@ func (p *Plugin) Commands() []plugin.Command {
 		{ID: "resume-in-workspace", Name: "Resume", Description: "Resume in workspace", Category: plugin.CategoryActions, Context: "conversations-sidebar", Priority: 3},
 		{ID: "yank-details", Name: "Copy Details", Description: "Copy session details", Category: plugin.CategoryActions, Context: "conversations-sidebar", Priority: 3},
 		{ID: "yank-resume", Name: "Copy Resume", Description: "Copy resume command", Category: plugin.CategoryActions, Context: "conversations-sidebar", Priority: 4},
+		{ID: "toggle-star", Name: "Star", Description: "Star/unstar session", Category: plugin.CategoryActions, Context: "conversations-sidebar", Priority: 3},
+		{ID: "edit-tags", Name: "Tags", Description: "Edit session tags", Category: plugin.CategoryActions, Context: "conversations-sidebar", Priority: 4},
+		{ID: "edit-note", Name: "Note", Description: "Edit session note", Category: plugin.CategoryActions, Context: "conversations-sidebar", Priority: 4},
+		{ID: "compare", Name: "Compare", Description: "Mark/compare two sessions", Category: plugin.CategoryView, Context: "conversations-sidebar", Priority: 4},
 		{ID: "toggle-sidebar", Name: "Sidebar", Description: "Toggle sidebar visibility", Category: plugin.CategoryView, Context: "conversations-sidebar", Priority: 5},
 	}
 }
@@ -1187,6 +1299,9 @@ func (p *Plugin) FocusContext() string {
 	if p.showResumeModal {
 		return "conversations-resume-modal"
 	}
+	if p.showMetaEditModal {
+		return "conversations-meta-edit"
+	}
 	if p.searchMode {
 		return "conversations-search"
 	}
@@ -1200,6 +1315,8 @@ func (p *Plugin) FocusContext() string {
 	switch p.view {
 	case ViewAnalytics:
 		return "analytics"
+	case ViewCompare:
+		return "conversations-compare"
 	default:
 		// Return context based on active pane
 		if p.activePane == PaneSidebar {
@@ -1212,7 +1329,7 @@ func (p *Plugin) FocusContext() string {
 // ConsumesTextInput reports whether conversation UI currently has a focused
 // text-entry flow where app shortcuts should not intercept characters.
 func (p *Plugin) ConsumesTextInput() bool {
-	return p.searchMode || p.filterMode || p.contentSearchMode
+	return p.searchMode || p.filterMode || p.contentSearchMode || p.showMetaEditModal
 }
 
 // Diagnostics returns plugin health info.
@@ -1255,9 +1372,13 @@ func (p *Plugin) Diagnostics() []plugin.Diagnostic {
 func (p *Plugin) copySessionToClipboard() tea.Cmd {
 	session := p.findSelectedSession()
 	messages := p.messages
+	var meta sessionmeta.Meta
+	if session != nil {
+		meta = p.sessionMeta(*session)
+	}
 
 	return func() tea.Msg {
-		md := ExportSessionAsMarkdown(session, messages)
+		md := ExportSessionAsMarkdown(session, messages, meta)
 		if err := CopyToClipboard(md); err != nil {
 			return app.ToastMsg{Message: "Copy failed: " + err.Error(), Duration: 2 * time.Second, IsError: true}
 		}
@@ -1270,9 +1391,13 @@ func (p *Plugin) exportSessionToFile() tea.Cmd {
 	session := p.findSelectedSession()
 	messages := p.messages
 	workDir := p.ctx.WorkDir
+	var meta sessionmeta.Meta
+	if session != nil {
+		meta = p.sessionMeta(*session)
+	}
 
 	return func() tea.Msg {
-		filename, err := ExportSessionToFile(session, messages, workDir)
+		filename, err := ExportSessionToFile(session, messages, meta, workDir)
 		if err != nil {
 			return app.ToastMsg{Message: "Export failed: " + err.Error(), Duration: 2 * time.Second, IsError: true}
 		}
@@ -168,6 +168,8 @@ func (p *Plugin) updateSessions(msg tea.KeyMsg) (plugin.Plugin, tea.Cmd) {
 	case "/":
 		p.searchMode = true
 		p.searchQuery = ""
+		p.searchParsed = nil
+		p.searchQueryErr = nil
 		p.cursor = 0
 		p.scrollOff = 0
 
@@ -181,6 +183,8 @@ func (p *Plugin) updateSessions(msg tea.KeyMsg) (plugin.Plugin, tea.Cmd) {
 		return p.openContentSearch()
 
 	case "r":
+		// Pick up metadata edits from other sidecar instances too
+		_ = p.meta.Reload()
 		return p, p.loadSessions()
 
 	case "U":
@@ -188,6 +192,22 @@ func (p *Plugin) updateSessions(msg tea.KeyMsg) (plugin.Plugin, tea.Cmd) {
 		p.view = ViewAnalytics
 		return p, nil
 
+	case "x":
+		// Mark session for comparison, or compare with the marked one
+		return p, p.toggleCompare()
+
+	case "s":
+		// Star/unstar session
+		return p, p.toggleStar()
+
+	case "t":
+		// Edit session tags
+		return p, p.openMetaEdit(metaEditTags)
+
+	case "n":
+		// Edit session note
+		return p, p.openMetaEdit(metaEditNote)
+
 	case "y":
 		// Yank session details to clipboard
 		return p, p.yankSessionDetails()
@@ -255,6 +275,8 @@ func (p *Plugin) updateSearch(msg tea.KeyMsg) (plugin.Plugin, tea.Cmd) {
 		p.searchMode = false
 		p.searchQuery = ""
 		p.searchResults = nil
+		p.searchParsed = nil
+		p.searchQueryErr = nil
 		p.cursor = 0
 		p.scrollOff = 0
 		if len(p.sessions) > 0 {
@@ -265,6 +287,7 @@ func (p *Plugin) updateSearch(msg tea.KeyMsg) (plugin.Plugin, tea.Cmd) {
 	case "enter":
 		sessions := p.visibleSessions()
 		if len(sessions) > 0 && p.cursor < len(sessions) {
+			queryCmd := p.applySearchQuery()
 			p.setSelectedSession(sessions[p.cursor].ID)
 			p.activePane = PaneMessages
 			p.msgCursor = 0
@@ -273,15 +296,21 @@ func (p *Plugin) updateSearch(msg tea.KeyMsg) (plugin.Plugin, tea.Cmd) {
 			return p, tea.Batch(
 				p.loadMessages(p.selectedSession),
 				p.loadUsage(p.selectedSession),
+				queryCmd,
 			)
 		}
 
+	case "ctrl+s":
+		// Save the current query as a named filter
+		return p, p.openSaveFilter()
+
 	case "backspace":
 		if len(p.searchQuery) > 0 {
 			p.searchQuery = p.searchQuery[:len(p.searchQuery)-1]
 			p.filterSessions()
 			p.cursor = 0
 			p.scrollOff = 0
+			return p, p.queryDetailsCmd()
 		}
 
 	case "up", "ctrl+p":
@@ -376,11 +405,13 @@ func (p *Plugin) updateSearch(msg tea.KeyMsg) (plugin.Plugin, tea.Cmd) {
 			p.filterSessions()
 			p.cursor = 0
 			p.scrollOff = 0
+			detailsCmd := p.queryDetailsCmd()
 			sessions := p.visibleSessions()
 			if len(sessions) > 0 {
 				p.setSelectedSession(sessions[0].ID)
-				return p, p.schedulePreviewLoad(p.selectedSession)
+				return p, tea.Batch(p.schedulePreviewLoad(p.selectedSession), detailsCmd)
 			}
+			return p, detailsCmd
 		}
 	}
 
@@ -431,6 +462,46 @@ func (p *Plugin) updateAnalytics(msg tea.KeyMsg) (plugin.Plugin, tea.Cmd) {
 	return p, nil
 }
 
+// updateCompare handles key events in compare view.
+func (p *Plugin) updateCompare(msg tea.KeyMsg) (plugin.Plugin, tea.Cmd) {
+	maxScroll := len(p.compareLines) - (p.height - 2)
+	if maxScroll < 0 {
+		maxScroll = 0
+	}
+
+	switch msg.String() {
+	case "esc", "q", "x":
+		p.view = ViewSessions
+		p.compare = nil
+		p.compareErr = nil
+		p.compareScrollOff = 0
+		p.compareLines = nil
+
+	case "j", "down":
+		if p.compareScrollOff < maxScroll {
+			p.compareScrollOff++
+		}
+
+	case "k", "up":
+		if p.compareScrollOff > 0 {
+			p.compareScrollOff--
+		}
+
+	case "g":
+		p.compareScrollOff = 0
+
+	case "G":
+		p.compareScrollOff = maxScroll
+
+	case "ctrl+d":
+		p.compareScrollOff = min(p.compareScrollOff+10, maxScroll)
+
+	case "ctrl+u":
+		p.compareScrollOff = max(p.compareScrollOff-10, 0)
+	}
+	return p, nil
+}
+
 // updateMessages handles key events in message view (now uses turns).
 func (p *Plugin) updateMessages(msg tea.KeyMsg) (plugin.Plugin, tea.Cmd) {
 	// In detail mode, handle detail-specific navigation
@@ -772,6 +843,10 @@ func (p *Plugin) updateMessages(msg tea.KeyMsg) (plugin.Plugin, tea.Cmd) {
 	case "F":
 		// Open content search modal (td-6ac70a)
 		return p.openContentSearch()
+
+	case "a":
+		// Annotate selected message
+		return p, p.openAnnotationEdit()
 	}
 
 	return p, nil
@@ -837,6 +912,12 @@ func (p *Plugin) updateFilter(msg tea.KeyMsg) (plugin.Plugin, tea.Cmd) {
 			return p, nil
 		}
 	}
+	for _, opt := range tagFilterOptions(p.meta.AllTags()) {
+		if key == opt.key {
+			p.filters.ToggleTag(opt.tag)
+			return p, nil
+		}
+	}
 
 	switch key {
 	case "esc":
@@ -890,8 +971,12 @@ func (p *Plugin) updateFilter(msg tea.KeyMsg) (plugin.Plugin, tea.Cmd) {
 		// Toggle active only
 		p.filters.ActiveOnly = !p.filters.ActiveOnly
 
+	case "*":
+		// Toggle starred only
+		p.filters.Starred = !p.filters.Starred
+
 	case "x":
-		// Clear all filters
+		// Clear all filters, including a query kept from search
 		p.filters = SearchFilters{}
 	}
 	return p, nil
@@ -6,6 +6,8 @@ import (
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/marcus/sidecar/internal/adapter"
+	appmsg "github.com/marcus/sidecar/internal/msg"
+	"github.com/marcus/sidecar/internal/sessionquery"
 )
 
 // Session selection and state management methods
@@ -63,6 +65,79 @@ func (p *Plugin) findSelectedSession() *adapter.Session {
 	return nil
 }
 
+// focusSession selects a session and opens its messages, for other plugins
+// linking to a conversation. Search and filters are cleared if they hide it.
+// A session that hasn't loaded yet is opened once it arrives.
+func (p *Plugin) focusSession(sessionID string) tea.Cmd {
+	if sessionID == "" {
+		return nil
+	}
+	idx := -1
+	for i := range p.sessions {
+		if p.sessions[i].ID == sessionID {
+			idx = i
+			break
+		}
+	}
+	if idx < 0 {
+		p.pendingFocus = sessionID
+		return p.loadSessions()
+	}
+	p.pendingFocus = ""
+
+	cursor := visibleSessionIndex(p.visibleSessions(), sessionID)
+	if cursor < 0 {
+		p.searchMode = false
+		p.searchQuery = ""
+		p.searchResults = nil
+		p.searchParsed = nil
+		p.searchQueryErr = nil
+		p.filterActive = false
+		if idx >= p.displayedCount {
+			p.displayedCount = idx + 1
+			p.hasMoreSessions = p.displayedCount < len(p.sessions)
+		}
+		cursor = visibleSessionIndex(p.visibleSessions(), sessionID)
+	}
+	p.cursor = cursor
+	p.ensureCursorVisible()
+	p.hitRegionsDirty = true
+	p.setSelectedSession(sessionID)
+	p.activePane = PaneMessages
+	return tea.Batch(
+		p.loadMessages(sessionID),
+		p.loadUsage(sessionID),
+	)
+}
+
+// applyPendingFocus opens a session requested before it had loaded. Once
+// loading is final and the session still isn't there, the request is dropped.
+func (p *Plugin) applyPendingFocus(final bool) tea.Cmd {
+	if p.pendingFocus == "" {
+		return nil
+	}
+	for i := range p.sessions {
+		if p.sessions[i].ID == p.pendingFocus {
+			return p.focusSession(p.pendingFocus)
+		}
+	}
+	if !final {
+		return nil
+	}
+	p.pendingFocus = ""
+	return appmsg.ShowToast("Conversation not found", 2*time.Second)
+}
+
+// visibleSessionIndex returns the index of a session in sessions, or -1.
+func visibleSessionIndex(sessions []adapter.Session, sessionID string) int {
+	for i := range sessions {
+		if sessions[i].ID == sessionID {
+			return i
+		}
+	}
+	return -1
+}
+
 // adapterForSession returns the adapter for a given session ID.
 func (p *Plugin) adapterForSession(sessionID string) adapter.Adapter {
 	for i := range p.sessions {
@@ -93,15 +168,30 @@ func (p *Plugin) getSelectedMessage() *adapter.Message {
 
 // Session filtering methods
 
-// filterSessions filters sessions based on search query.
+// filterSessions filters sessions based on the search query. The query is
+// parsed with the filter query language; if it does not parse, it falls
+// back to a plain substring match.
 func (p *Plugin) filterSessions() {
 	if p.searchQuery == "" {
 		p.searchResults = nil
+		p.searchParsed = nil
+		p.searchQueryErr = nil
 		return
 	}
 
-	query := strings.ToLower(p.searchQuery)
+	p.searchParsed, p.searchQueryErr = sessionquery.Parse(p.searchQuery, p.savedFilters())
 	var results []adapter.Session
+	if p.searchQueryErr == nil {
+		for _, s := range p.sessions {
+			if p.searchParsed.Match(p.querySubject(s)) {
+				results = append(results, s)
+			}
+		}
+		p.searchResults = results
+		return
+	}
+
+	query := strings.ToLower(p.searchQuery)
 	for _, s := range p.sessions {
 		if strings.Contains(strings.ToLower(s.Name), query) ||
 			strings.Contains(strings.ToLower(s.Slug), query) ||
@@ -123,7 +213,8 @@ func (p *Plugin) visibleSessions() []adapter.Session {
 	if p.filterActive && p.filters.IsActive() {
 		var filtered []adapter.Session
 		for _, s := range p.sessions {
-			if p.filters.Matches(s) {
+			if p.filters.Matches(s) && p.filters.MatchesMeta(p.sessionMeta(s)) &&
+				(p.filters.Expr == nil || p.filters.Expr.Match(p.querySubject(s))) {
 				filtered = append(filtered, s)
 			}
 		}
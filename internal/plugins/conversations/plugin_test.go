@@ -3678,3 +3678,36 @@ func TestSessionsRefreshedMsgAddsNewSession(t *testing.T) {
 		t.Errorf("expected new session at top, got %s", p.sessions[0].ID)
 	}
 }
+
+func TestFocusConversationMsg(t *testing.T) {
+	p := New()
+	p.adapters = map[string]adapter.Adapter{"mock": &mockAdapter{}}
+	p.sessions = []adapter.Session{
+		{ID: "test-1", Name: "alpha"},
+		{ID: "test-2", Name: "beta"},
+		{ID: "test-3", Name: "gamma"},
+	}
+	p.displayedCount = 2
+	p.searchMode = true
+	p.searchQuery = "alpha"
+	p.searchResults = []adapter.Session{p.sessions[0]}
+
+	_, _ = p.Update(app.FocusConversationMsg{SessionID: "test-3"})
+
+	if p.selectedSession != "test-3" || p.activePane != PaneMessages {
+		t.Errorf("selected %q in pane %v, want test-3 in messages", p.selectedSession, p.activePane)
+	}
+	if p.searchMode || p.cursor != 2 {
+		t.Errorf("searchMode=%v cursor=%d, want search cleared and cursor 2", p.searchMode, p.cursor)
+	}
+
+	// A session that hasn't loaded yet is opened when it arrives
+	_, _ = p.Update(app.FocusConversationMsg{SessionID: "test-4"})
+	if p.pendingFocus != "test-4" {
+		t.Fatalf("pendingFocus = %q, want test-4", p.pendingFocus)
+	}
+	_, _ = p.Update(SessionsLoadedMsg{Sessions: append(p.sessions, adapter.Session{ID: "test-4"})})
+	if p.selectedSession != "test-4" || p.pendingFocus != "" {
+		t.Errorf("selected %q pending %q after load", p.selectedSession, p.pendingFocus)
+	}
+}
@@ -0,0 +1,160 @@
+package conversations
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/marcus/sidecar/internal/adapter"
+	"github.com/marcus/sidecar/internal/config"
+	appmsg "github.com/marcus/sidecar/internal/msg"
+	"github.com/marcus/sidecar/internal/sessionmeta"
+	"github.com/marcus/sidecar/internal/sessionquery"
+)
+
+// savedFilterName restricts saved filter names to something @name can parse.
+var savedFilterName = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// queryDetailsEntry caches message-derived query details for a session.
+type queryDetailsEntry struct {
+	updatedAt time.Time
+	details   *sessionquery.Details
+}
+
+// QueryDetailsLoadedMsg carries message-derived details for sessions that a
+// query with model/file/tool/has:error terms needs to inspect.
+type QueryDetailsLoadedMsg struct {
+	Epoch   uint64
+	Entries map[string]queryDetailsEntry
+}
+
+// GetEpoch implements plugin.EpochMessage.
+func (m QueryDetailsLoadedMsg) GetEpoch() uint64 { return m.Epoch }
+
+// savedFilters returns the named filters from config.
+func (p *Plugin) savedFilters() map[string]string {
+	if p.ctx == nil || p.ctx.Config == nil {
+		return nil
+	}
+	return p.ctx.Config.Plugins.Conversations.SavedFilters
+}
+
+// querySubject assembles what a query is evaluated against for a session.
+func (p *Plugin) querySubject(s adapter.Session) sessionquery.Subject {
+	sub := sessionquery.Subject{Session: s, Meta: p.sessionMeta(s)}
+	if e, ok := p.queryDetails[sessionmeta.Key(s.AdapterID, s.ID)]; ok && !s.UpdatedAt.After(e.updatedAt) {
+		sub.Details = e.details
+	}
+	return sub
+}
+
+// activeQuery returns the query currently narrowing the session list: the
+// one being typed in search mode, otherwise the persisted filter query.
+func (p *Plugin) activeQuery() *sessionquery.Query {
+	if p.searchMode {
+		return p.searchParsed
+	}
+	if p.filterActive {
+		return p.filters.Expr
+	}
+	return nil
+}
+
+// queryDetailsCmd loads details for sessions the active query cannot
+// evaluate yet. Only one load runs at a time; results trigger a re-filter.
+func (p *Plugin) queryDetailsCmd() tea.Cmd {
+	q := p.activeQuery()
+	if !q.NeedsDetails() || p.queryDetailsLoading {
+		return nil
+	}
+	var pending []adapter.Session
+	for _, s := range p.sessions {
+		if p.querySubject(s).Details == nil {
+			pending = append(pending, s)
+		}
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+	p.queryDetailsLoading = true
+
+	var epoch uint64
+	if p.ctx != nil {
+		epoch = p.ctx.Epoch
+	}
+	adapters := p.adapters
+	return func() tea.Msg {
+		entries := make(map[string]queryDetailsEntry, len(pending))
+		for _, s := range pending {
+			details := &sessionquery.Details{}
+			// Huge sessions are skipped rather than blocking the scan.
+			if a := adapters[s.AdapterID]; a != nil && s.SizeLevel() < 2 {
+				if msgs, err := a.Messages(s.ID); err == nil {
+					details = sessionquery.DetailsFromMessages(msgs)
+				}
+			}
+			entries[sessionmeta.Key(s.AdapterID, s.ID)] = queryDetailsEntry{updatedAt: s.UpdatedAt, details: details}
+		}
+		return QueryDetailsLoadedMsg{Epoch: epoch, Entries: entries}
+	}
+}
+
+// handleQueryDetailsLoaded merges loaded details and re-applies the query.
+func (p *Plugin) handleQueryDetailsLoaded(msg QueryDetailsLoadedMsg) tea.Cmd {
+	p.queryDetailsLoading = false
+	if p.queryDetails == nil {
+		p.queryDetails = make(map[string]queryDetailsEntry)
+	}
+	for k, e := range msg.Entries {
+		p.queryDetails[k] = e
+	}
+	if p.searchMode {
+		p.filterSessions()
+	}
+	p.hitRegionsDirty = true
+	return p.queryDetailsCmd()
+}
+
+// applySearchQuery persists a search containing field terms as the active
+// filter so it keeps narrowing the list after search mode closes.
+func (p *Plugin) applySearchQuery() tea.Cmd {
+	if p.searchQueryErr != nil {
+		return appmsg.ShowToast("Query: "+p.searchQueryErr.Error(), 3*time.Second)
+	}
+	if !p.searchParsed.HasFields() {
+		return nil
+	}
+	p.filters.Expr = p.searchParsed
+	p.filterActive = true
+	return nil
+}
+
+// openSaveFilter opens the modal that names and saves the current search.
+func (p *Plugin) openSaveFilter() tea.Cmd {
+	if p.searchQuery == "" {
+		return appmsg.ShowToast("Type a query to save", 2*time.Second)
+	}
+	if p.searchQueryErr != nil {
+		return appmsg.ShowToast("Query: "+p.searchQueryErr.Error(), 3*time.Second)
+	}
+	p.metaEditQuery = p.searchQuery
+	p.showMetaEdit(metaEditSavedFilter, adapter.Session{}, "", "")
+	return nil
+}
+
+// saveNamedFilter stores query under name in the user config.
+func (p *Plugin) saveNamedFilter(name, query string) error {
+	if !savedFilterName.MatchString(name) {
+		return fmt.Errorf("name must be letters, digits, '-' or '_'")
+	}
+	if p.ctx == nil || p.ctx.Config == nil {
+		return fmt.Errorf("config not available")
+	}
+	cfg := &p.ctx.Config.Plugins.Conversations
+	if cfg.SavedFilters == nil {
+		cfg.SavedFilters = make(map[string]string)
+	}
+	cfg.SavedFilters[name] = query
+	return config.Save(p.ctx.Config)
+}
@@ -0,0 +1,97 @@
+package conversations
+
+import (
+	"testing"
+	"time"
+
+	"github.com/marcus/sidecar/internal/adapter"
+	"github.com/marcus/sidecar/internal/sessionmeta"
+	"github.com/marcus/sidecar/internal/sessionquery"
+)
+
+func TestFilterSessions_QueryLanguage(t *testing.T) {
+	p := New()
+	p.sessions = []adapter.Session{
+		{ID: "a", Name: "alpha", AdapterID: "codex", EstCost: 3},
+		{ID: "b", Name: "beta", AdapterID: "claude-code", EstCost: 5},
+		{ID: "c", Name: "gamma", AdapterID: "codex", EstCost: 0.5},
+	}
+
+	p.searchQuery = "adapter:codex cost>1"
+	p.filterSessions()
+	if p.searchQueryErr != nil {
+		t.Fatalf("unexpected parse error: %v", p.searchQueryErr)
+	}
+	if len(p.searchResults) != 1 || p.searchResults[0].ID != "a" {
+		t.Errorf("expected only session a, got %+v", p.searchResults)
+	}
+
+	// Unknown fields fall back to plain substring search
+	p.searchQuery = "alph:x"
+	p.filterSessions()
+	if p.searchQueryErr == nil {
+		t.Error("expected parse error for unknown field")
+	}
+	if len(p.searchResults) != 0 {
+		t.Errorf("expected no substring matches, got %d", len(p.searchResults))
+	}
+}
+
+func TestFilterSessions_QueryNeedsDetails(t *testing.T) {
+	p := New()
+	now := time.Now()
+	p.sessions = []adapter.Session{
+		{ID: "a", AdapterID: "codex", UpdatedAt: now},
+		{ID: "b", AdapterID: "codex", UpdatedAt: now},
+	}
+	p.searchMode = true
+	p.searchQuery = "tool:Bash"
+	p.filterSessions()
+	if len(p.searchResults) != 0 {
+		t.Fatalf("expected no matches before details load, got %d", len(p.searchResults))
+	}
+
+	p.handleQueryDetailsLoaded(QueryDetailsLoadedMsg{Entries: map[string]queryDetailsEntry{
+		sessionmeta.Key("codex", "a"): {updatedAt: now, details: &sessionquery.Details{Tools: map[string]int{"Bash": 2}}},
+		sessionmeta.Key("codex", "b"): {updatedAt: now, details: &sessionquery.Details{}},
+	}})
+	if len(p.searchResults) != 1 || p.searchResults[0].ID != "a" {
+		t.Errorf("expected session a after details load, got %+v", p.searchResults)
+	}
+
+	// Details older than the session are ignored
+	p.sessions[0].UpdatedAt = now.Add(time.Minute)
+	if p.querySubject(p.sessions[0]).Details != nil {
+		t.Error("stale details should not be used")
+	}
+}
+
+func TestApplySearchQuery(t *testing.T) {
+	p := New()
+	p.sessions = []adapter.Session{
+		{ID: "a", Name: "alpha", AdapterID: "codex"},
+		{ID: "b", Name: "beta", AdapterID: "claude-code"},
+	}
+
+	// Plain text searches are not kept as a filter
+	p.searchQuery = "alpha"
+	p.filterSessions()
+	p.applySearchQuery()
+	if p.filterActive {
+		t.Error("plain text search should not persist as a filter")
+	}
+
+	p.searchQuery = "adapter:claude"
+	p.filterSessions()
+	p.applySearchQuery()
+	if !p.filterActive || p.filters.Expr == nil {
+		t.Fatal("field query should persist as a filter")
+	}
+	visible := p.visibleSessions()
+	if len(visible) != 1 || visible[0].ID != "b" {
+		t.Errorf("expected only session b, got %+v", visible)
+	}
+	if got := p.filters.String(); got != "[adapter:claude]" {
+		t.Errorf("filters.String() = %q", got)
+	}
+}
@@ -7,19 +7,24 @@ import (
 	"time"
 
 	"github.com/marcus/sidecar/internal/adapter"
+	"github.com/marcus/sidecar/internal/sessionmeta"
+	"github.com/marcus/sidecar/internal/sessionquery"
 )
 
 // SearchFilters holds multi-dimensional filter criteria.
 type SearchFilters struct {
-	Query      string    // Text search
-	Adapters   []string  // ["claude-code", "codex"]
-	Models     []string  // ["opus", "sonnet", "haiku"]
-	Categories []string  // ["interactive", "cron", "system"]
-	DateRange  DateRange // today, week, custom
-	MinTokens  int       // Sessions with > N tokens
-	MaxTokens  int       // Sessions with < N tokens
-	ActiveOnly bool      // Only currently active
-	HasFiles   []string  // Sessions that touched these files
+	Query      string              // Text search
+	Adapters   []string            // ["claude-code", "codex"]
+	Models     []string            // ["opus", "sonnet", "haiku"]
+	Categories []string            // ["interactive", "cron", "system"]
+	DateRange  DateRange           // today, week, custom
+	MinTokens  int                 // Sessions with > N tokens
+	MaxTokens  int                 // Sessions with < N tokens
+	ActiveOnly bool                // Only currently active
+	HasFiles   []string            // Sessions that touched these files
+	Starred    bool                // Only starred sessions
+	Tags       []string            // Sessions carrying all of these tags
+	Expr       *sessionquery.Query // Query language filter (kept from search)
 }
 
 // DateRange represents a date range filter.
@@ -39,7 +44,10 @@ func (f *SearchFilters) IsActive() bool {
 		f.MinTokens > 0 ||
 		f.MaxTokens > 0 ||
 		f.ActiveOnly ||
-		len(f.HasFiles) > 0
+		len(f.HasFiles) > 0 ||
+		f.Starred ||
+		len(f.Tags) > 0 ||
+		!f.Expr.IsEmpty()
 }
 
 // ToggleAdapter toggles an adapter in the filter list.
@@ -100,6 +108,33 @@ func (f *SearchFilters) HasCategory(cat string) bool {
 	return slices.Contains(f.Categories, cat)
 }
 
+// ToggleTag toggles a tag in the filter list.
+func (f *SearchFilters) ToggleTag(tag string) {
+	if i := slices.Index(f.Tags, tag); i >= 0 {
+		f.Tags = slices.Delete(f.Tags, i, i+1)
+		return
+	}
+	f.Tags = append(f.Tags, tag)
+}
+
+// HasTag returns true if the tag is in the filter list.
+func (f *SearchFilters) HasTag(tag string) bool {
+	return slices.Contains(f.Tags, tag)
+}
+
+// MatchesMeta checks the filters that depend on sidecar-owned session metadata.
+func (f *SearchFilters) MatchesMeta(meta sessionmeta.Meta) bool {
+	if f.Starred && !meta.Starred {
+		return false
+	}
+	for _, tag := range f.Tags {
+		if !meta.HasTag(tag) {
+			return false
+		}
+	}
+	return true
+}
+
 // SetDateRange sets the date range preset.
 func (f *SearchFilters) SetDateRange(preset string) {
 	if f.DateRange.Preset == preset {
@@ -205,6 +240,15 @@ func (f *SearchFilters) String() string {
 	if f.ActiveOnly {
 		parts = append(parts, "[active]")
 	}
+	if f.Starred {
+		parts = append(parts, "[starred]")
+	}
+	if len(f.Tags) > 0 {
+		parts = append(parts, "[tag:"+strings.Join(f.Tags, ",")+"]")
+	}
+	if !f.Expr.IsEmpty() {
+		parts = append(parts, "["+f.Expr.String()+"]")
+	}
 
 	return strings.Join(parts, " ")
 }
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/marcus/sidecar/internal/adapter"
+	"github.com/marcus/sidecar/internal/sessionmeta"
 )
 
 func TestSearchFilters_IsActive(t *testing.T) {
@@ -217,3 +218,52 @@ func TestFormatTokenCount(t *testing.T) {
 		}
 	}
 }
+
+func TestSearchFilters_MatchesMeta(t *testing.T) {
+	meta := sessionmeta.Meta{Starred: true, Tags: []string{"bug", "ui"}}
+
+	tests := []struct {
+		name    string
+		filters SearchFilters
+		want    bool
+	}{
+		{"no filters", SearchFilters{}, true},
+		{"starred", SearchFilters{Starred: true}, true},
+		{"one tag", SearchFilters{Tags: []string{"bug"}}, true},
+		{"all tags", SearchFilters{Tags: []string{"bug", "ui"}}, true},
+		{"missing tag", SearchFilters{Tags: []string{"bug", "perf"}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filters.MatchesMeta(meta); got != tt.want {
+				t.Errorf("MatchesMeta() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if (&SearchFilters{Starred: true}).MatchesMeta(sessionmeta.Meta{}) {
+		t.Error("starred filter should reject unstarred sessions")
+	}
+	f := SearchFilters{}
+	f.ToggleTag("bug")
+	if !f.IsActive() || !f.HasTag("bug") {
+		t.Error("ToggleTag should activate the tag filter")
+	}
+	f.ToggleTag("bug")
+	if f.IsActive() {
+		t.Error("ToggleTag twice should clear the tag filter")
+	}
+}
+
+func TestTagFilterOptions(t *testing.T) {
+	opts := tagFilterOptions([]string{"bug", "build", "x1"})
+	want := map[string]string{"bug": "B", "build": "U", "x1": "X"}
+	if len(opts) != len(want) {
+		t.Fatalf("got %d options, want %d", len(opts), len(want))
+	}
+	for _, o := range opts {
+		if want[o.tag] != o.key {
+			t.Errorf("tag %q key = %q, want %q", o.tag, o.key, want[o.tag])
+		}
+	}
+}
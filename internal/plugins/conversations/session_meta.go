@@ -0,0 +1,294 @@
+package conversations
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/marcus/sidecar/internal/adapter"
+	"github.com/marcus/sidecar/internal/modal"
+	appmsg "github.com/marcus/sidecar/internal/msg"
+	"github.com/marcus/sidecar/internal/sessionmeta"
+	"github.com/marcus/sidecar/internal/styles"
+	"github.com/marcus/sidecar/internal/ui"
+)
+
+// metaEditKind selects what the metadata edit modal changes.
+type metaEditKind int
+
+const (
+	metaEditTags metaEditKind = iota
+	metaEditNote
+	metaEditAnnotation
+	metaEditSavedFilter
+)
+
+// Modal field IDs
+const (
+	metaEditInputID  = "meta-edit-input"
+	metaEditSubmitID = "meta-edit-submit"
+	metaEditCancelID = "meta-edit-cancel"
+)
+
+// sessionMeta returns sidecar-owned metadata for a session.
+func (p *Plugin) sessionMeta(s adapter.Session) sessionmeta.Meta {
+	return p.meta.Get(s.AdapterID, s.ID)
+}
+
+// metaTargetSession returns the session that star/tag/note actions apply to:
+// the open session in the message pane, otherwise the sidebar cursor.
+func (p *Plugin) metaTargetSession() *adapter.Session {
+	if p.activePane == PaneMessages {
+		if s := p.findSelectedSession(); s != nil {
+			return s
+		}
+	}
+	sessions := p.visibleSessions()
+	if p.cursor >= 0 && p.cursor < len(sessions) {
+		return &sessions[p.cursor]
+	}
+	return nil
+}
+
+// toggleStar stars or unstars the target session.
+func (p *Plugin) toggleStar() tea.Cmd {
+	s := p.metaTargetSession()
+	if s == nil {
+		return nil
+	}
+	starred, err := p.meta.ToggleStar(s.AdapterID, s.ID)
+	if err != nil {
+		return appmsg.ShowToast("Star failed: "+err.Error(), 3*time.Second)
+	}
+	if starred {
+		return appmsg.ShowToast("Starred", 2*time.Second)
+	}
+	return appmsg.ShowToast("Unstarred", 2*time.Second)
+}
+
+// openMetaEdit opens the edit modal for the target session's tags or note.
+func (p *Plugin) openMetaEdit(kind metaEditKind) tea.Cmd {
+	s := p.metaTargetSession()
+	if s == nil {
+		return nil
+	}
+	meta := p.sessionMeta(*s)
+	initial := meta.Note
+	if kind == metaEditTags {
+		initial = strings.Join(meta.Tags, ", ")
+	}
+	p.showMetaEdit(kind, *s, "", initial)
+	return nil
+}
+
+// openAnnotationEdit opens the edit modal for the selected message.
+func (p *Plugin) openAnnotationEdit() tea.Cmd {
+	session := p.findSelectedSession()
+	if session == nil {
+		return nil
+	}
+	var msgID string
+	if p.turnViewMode {
+		if p.turnCursor < len(p.turns) && len(p.turns[p.turnCursor].Messages) > 0 {
+			msgID = p.turns[p.turnCursor].Messages[0].ID
+		}
+	} else if msg := p.getSelectedMessage(); msg != nil {
+		msgID = msg.ID
+	}
+	if msgID == "" {
+		return appmsg.ShowToast("Message has no ID to annotate", 2*time.Second)
+	}
+	text, _ := p.sessionMeta(*session).Annotation(msgID)
+	p.showMetaEdit(metaEditAnnotation, *session, msgID, text)
+	return nil
+}
+
+// showMetaEdit initializes modal state.
+func (p *Plugin) showMetaEdit(kind metaEditKind, s adapter.Session, msgID, initial string) {
+	p.metaEditKind = kind
+	p.metaEditSession = s
+	p.metaEditMessageID = msgID
+	p.metaEditInput = textinput.New()
+	p.metaEditInput.CharLimit = 500
+	switch kind {
+	case metaEditTags:
+		p.metaEditInput.Placeholder = "bug, refactor, good-example"
+	case metaEditNote:
+		p.metaEditInput.Placeholder = "Note about this session"
+	case metaEditAnnotation:
+		p.metaEditInput.Placeholder = "Annotation for this message"
+	case metaEditSavedFilter:
+		p.metaEditInput.Placeholder = "pricey-codex"
+	}
+	p.metaEditInput.SetValue(initial)
+	p.metaEditInput.CursorEnd()
+	p.metaEditInput.Focus()
+	p.metaEditModal = nil
+	p.metaEditModalWidth = 0
+	p.showMetaEditModal = true
+}
+
+// resetMetaEdit closes the edit modal.
+func (p *Plugin) resetMetaEdit() {
+	p.showMetaEditModal = false
+	p.metaEditModal = nil
+	p.metaEditModalWidth = 0
+	p.metaEditMessageID = ""
+	p.metaEditQuery = ""
+}
+
+// ensureMetaEditModal builds or caches the edit modal.
+func (p *Plugin) ensureMetaEditModal() {
+	modalW := min(60, max(p.width-4, 20))
+	if p.metaEditModal != nil && p.metaEditModalWidth == modalW {
+		return
+	}
+	p.metaEditModalWidth = modalW
+
+	title, hint := "Edit Tags", "Comma or space separated. Leave empty to clear."
+	switch p.metaEditKind {
+	case metaEditNote:
+		title, hint = "Edit Note", "Leave empty to remove the note."
+	case metaEditAnnotation:
+		title, hint = "Annotate Message", "Leave empty to remove the annotation."
+	case metaEditSavedFilter:
+		title, hint = "Save Filter", "Use it later as @name in a search."
+	}
+
+	subject := "Session: " + p.metaEditSession.Name
+	switch {
+	case p.metaEditKind == metaEditSavedFilter:
+		subject = "Query: " + p.metaEditQuery
+	case p.metaEditSession.Name == "":
+		subject = "Session: " + shortID(p.metaEditSession.ID)
+	}
+
+	p.metaEditModal = modal.New(title,
+		modal.WithWidth(modalW),
+		modal.WithPrimaryAction(metaEditSubmitID),
+		modal.WithHints(false),
+	).
+		AddSection(modal.Text(subject)).
+		AddSection(modal.Spacer()).
+		AddSection(modal.Input(metaEditInputID, &p.metaEditInput)).
+		AddSection(modal.Text(styles.Muted.Render(hint))).
+		AddSection(modal.Spacer()).
+		AddSection(modal.Buttons(
+			modal.Btn(" Save ", metaEditSubmitID),
+			modal.Btn(" Cancel ", metaEditCancelID),
+		))
+}
+
+// handleMetaEditKeys handles keyboard input for the edit modal.
+func (p *Plugin) handleMetaEditKeys(msg tea.KeyMsg) tea.Cmd {
+	p.ensureMetaEditModal()
+	action, cmd := p.metaEditModal.HandleKey(msg)
+	switch action {
+	case metaEditSubmitID:
+		return p.saveMetaEdit()
+	case metaEditCancelID, "cancel":
+		p.resetMetaEdit()
+		return nil
+	}
+	return cmd
+}
+
+// handleMetaEditMouse handles mouse input for the edit modal.
+func (p *Plugin) handleMetaEditMouse(msg tea.MouseMsg) tea.Cmd {
+	p.ensureMetaEditModal()
+	switch p.metaEditModal.HandleMouse(msg, p.mouseHandler) {
+	case metaEditSubmitID:
+		return p.saveMetaEdit()
+	case metaEditCancelID, "cancel":
+		p.resetMetaEdit()
+	}
+	return nil
+}
+
+// saveMetaEdit writes the edited value to the store.
+func (p *Plugin) saveMetaEdit() tea.Cmd {
+	s := p.metaEditSession
+	value := p.metaEditInput.Value()
+	var err error
+	var done string
+	switch p.metaEditKind {
+	case metaEditTags:
+		err = p.meta.SetTags(s.AdapterID, s.ID, sessionmeta.ParseTags(value))
+		done = "Tags saved"
+	case metaEditNote:
+		err = p.meta.SetNote(s.AdapterID, s.ID, value)
+		done = "Note saved"
+	case metaEditAnnotation:
+		err = p.meta.SetAnnotation(s.AdapterID, s.ID, p.metaEditMessageID, value)
+		done = "Annotation saved"
+	case metaEditSavedFilter:
+		name := strings.TrimPrefix(strings.TrimSpace(value), "@")
+		err = p.saveNamedFilter(name, p.metaEditQuery)
+		done = "Saved filter @" + name
+	}
+	p.resetMetaEdit()
+	p.hitRegionsDirty = true
+	if err != nil {
+		return appmsg.ShowToast("Save failed: "+err.Error(), 3*time.Second)
+	}
+	return appmsg.ShowToast(done, 2*time.Second)
+}
+
+// renderMetaEditModal renders the edit modal over the two-pane view.
+func (p *Plugin) renderMetaEditModal(width, height int) string {
+	p.ensureMetaEditModal()
+	background := p.renderTwoPane()
+	rendered := p.metaEditModal.Render(width, height, p.mouseHandler)
+	return ui.OverlayModal(background, rendered, width, height)
+}
+
+// sessionMetaBadgeText returns the plain-text star/note/tag badges for a
+// session row, or "" if the session has no metadata.
+func sessionMetaBadgeText(meta sessionmeta.Meta) string {
+	var parts []string
+	if meta.Starred {
+		parts = append(parts, "★")
+	}
+	if meta.Note != "" {
+		parts = append(parts, "✎")
+	}
+	const maxTags = 2
+	for i, t := range meta.Tags {
+		if i == maxTags {
+			parts = append(parts, fmt.Sprintf("+%d", len(meta.Tags)-maxTags))
+			break
+		}
+		parts = append(parts, "#"+t)
+	}
+	return strings.Join(parts, " ")
+}
+
+// renderSessionMetaBadge renders the colored star/note/tag badges.
+func renderSessionMetaBadge(meta sessionmeta.Meta) string {
+	var parts []string
+	if meta.Starred {
+		parts = append(parts, lipgloss.NewStyle().Foreground(styles.Warning).Render("★"))
+	}
+	if meta.Note != "" {
+		parts = append(parts, styles.Muted.Render("✎"))
+	}
+	tagText := sessionMetaBadgeText(sessionmeta.Meta{Tags: meta.Tags})
+	if tagText != "" {
+		parts = append(parts, lipgloss.NewStyle().Foreground(styles.Info).Render(tagText))
+	}
+	return strings.Join(parts, " ")
+}
+
+// turnAnnotations returns the annotations on a turn's messages joined for display.
+func turnAnnotations(meta sessionmeta.Meta, turn Turn) string {
+	var notes []string
+	for _, msg := range turn.Messages {
+		if text, ok := meta.Annotation(msg.ID); ok {
+			notes = append(notes, text)
+		}
+	}
+	return strings.Join(notes, " · ")
+}
@@ -6,22 +6,24 @@ import (
 	"time"
 
 	"github.com/marcus/sidecar/internal/adapter"
+	"github.com/marcus/sidecar/internal/adapter/contextwindow"
 	"github.com/marcus/sidecar/internal/adapter/pricing"
 )
 
 // SessionSummary holds aggregated statistics for a session.
 type SessionSummary struct {
-	FilesTouched    []string       // Unique files from tool uses
-	FileCount       int            // Number of unique files
-	TotalTokensIn   int            // Sum of input tokens
-	TotalTokensOut  int            // Sum of output tokens
-	TotalCacheRead  int            // Sum of cache read tokens
-	TotalCacheWrite int            // Sum of cache write tokens
-	TotalCost       float64        // Estimated cost in dollars
-	Duration        time.Duration  // Session duration
-	PrimaryModel    string         // Most used model
-	MessageCount    int            // Total messages
-	ToolCounts      map[string]int // Tool name -> count
+	FilesTouched    []string            // Unique files from tool uses
+	FileCount       int                 // Number of unique files
+	TotalTokensIn   int                 // Sum of input tokens
+	TotalTokensOut  int                 // Sum of output tokens
+	TotalCacheRead  int                 // Sum of cache read tokens
+	TotalCacheWrite int                 // Sum of cache write tokens
+	TotalCost       float64             // Estimated cost in dollars
+	Duration        time.Duration       // Session duration
+	PrimaryModel    string              // Most used model
+	MessageCount    int                 // Total messages
+	ToolCounts      map[string]int      // Tool name -> count
+	Context         contextwindow.Gauge // Context window fill over time
 }
 
 // ComputeSessionSummary aggregates statistics from messages.
@@ -53,6 +55,8 @@ func ComputeSessionSummary(messages []adapter.Message, duration time.Duration) S
 		}
 	}
 
+	summary.Context = contextwindow.Measure(messages)
+
 	// Collect unique files
 	for fp := range fileSet {
 		summary.FilesTouched = append(summary.FilesTouched, fp)
@@ -122,6 +126,8 @@ func UpdateSessionSummary(summary *SessionSummary, newMessages []adapter.Message
 		}
 	}
 
+	summary.Context.Add(newMessages)
+
 	// Update primary model if needed
 	var maxCount int
 	for model, count := range modelCounts {
@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	"github.com/marcus/sidecar/internal/adapter"
+	"github.com/marcus/sidecar/internal/adapter/contextwindow"
 )
 
 // xmlTagRegex is pre-compiled for performance in hot path (called per turn on render)
@@ -60,6 +61,17 @@ func (t *Turn) Preview(maxLen int) string {
 	return ""
 }
 
+// ContextEvent returns the first compaction or summary event among the
+// turn's messages, "" if the turn does not start a fresh context window.
+func (t *Turn) ContextEvent() string {
+	for _, msg := range t.Messages {
+		if contextwindow.IsCompaction(msg.Event) {
+			return msg.Event
+		}
+	}
+	return ""
+}
+
 // stripXMLTags removes XML tags from content and extracts user query if present.
 func stripXMLTags(s string) string {
 	// First try to extract user query
@@ -0,0 +1,239 @@
+package conversations
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/marcus/sidecar/internal/styles"
+)
+
+// renderCompare renders the side-by-side session comparison with scrolling.
+func (p *Plugin) renderCompare() string {
+	lines := p.buildCompareLines()
+	p.compareLines = lines
+
+	contentHeight := p.height - 2
+	if contentHeight < 1 {
+		contentHeight = 1
+	}
+	start := p.compareScrollOff
+	if start >= len(lines) {
+		start = max(len(lines)-1, 0)
+	}
+	end := min(start+contentHeight, len(lines))
+	return strings.Join(lines[start:end], "\n")
+}
+
+// buildCompareLines renders every line of the compare view.
+func (p *Plugin) buildCompareLines() []string {
+	width := max(p.width-2, 40)
+	rule := styles.Muted.Render(strings.Repeat("━", width))
+
+	var lines []string
+	lines = append(lines, styles.Title.Render(" Compare Sessions"), rule)
+
+	if p.compareErr != nil {
+		return append(lines, styles.StatusDeleted.Render(" Unable to load sessions: "+p.compareErr.Error()))
+	}
+	if p.compare == nil {
+		return append(lines, styles.Muted.Render(" Loading..."))
+	}
+	c := p.compare
+
+	// Column layout: label | left | right
+	labelW := 12
+	colW := max((width-labelW-4)/2, 10)
+	row := func(label, left, right string) string {
+		return " " + padCell(styles.Subtitle.Render(label), labelW) + " " +
+			padCell(left, colW) + "  " + padCell(right, colW)
+	}
+
+	lines = append(lines, row("", styles.Body.Bold(true).Render(sessionTitle(c.Left)), styles.Body.Bold(true).Render(sessionTitle(c.Right))))
+	lines = append(lines, row("Adapter", renderAdapterIcon(c.Left.Session)+" "+c.Left.Session.AdapterName, renderAdapterIcon(c.Right.Session)+" "+c.Right.Session.AdapterName))
+	lines = append(lines, row("Worktree", worktreeLabel(c.Left), worktreeLabel(c.Right)))
+	lines = append(lines, row("Model", modelShortName(c.Left.Summary.PrimaryModel), modelShortName(c.Right.Summary.PrimaryModel)))
+	lines = append(lines, row("Duration", formatSessionDuration(c.Left.Summary.Duration), formatSessionDuration(c.Right.Summary.Duration)))
+	lines = append(lines, row("Cost", compareCost(c.Left, c.Right), compareCost(c.Right, c.Left)))
+	lines = append(lines, row("Tokens",
+		fmt.Sprintf("in:%s out:%s", formatK(c.Left.Summary.TotalTokensIn), formatK(c.Left.Summary.TotalTokensOut)),
+		fmt.Sprintf("in:%s out:%s", formatK(c.Right.Summary.TotalTokensIn), formatK(c.Right.Summary.TotalTokensOut))))
+	lines = append(lines, row("Messages", fmt.Sprintf("%d", c.Left.Summary.MessageCount), fmt.Sprintf("%d", c.Right.Summary.MessageCount)))
+	lines = append(lines, row("Tool calls", fmt.Sprintf("%d", c.Left.ToolTotal()), fmt.Sprintf("%d", c.Right.ToolTotal())))
+	lines = append(lines, row("Files", fmt.Sprintf("%d", c.Left.Summary.FileCount), fmt.Sprintf("%d", c.Right.Summary.FileCount)))
+	lines = append(lines, "")
+
+	// Tool counts by name, busiest first
+	if names := mergedToolNames(c.Left.Summary.ToolCounts, c.Right.Summary.ToolCounts); len(names) > 0 {
+		lines = append(lines, styles.Title.Render(" Tools"), styles.Muted.Render(strings.Repeat("─", width)))
+		for _, name := range names {
+			lines = append(lines, row(name, countCell(c.Left.Summary.ToolCounts[name]), countCell(c.Right.Summary.ToolCounts[name])))
+		}
+		lines = append(lines, "")
+	}
+
+	// Files touched
+	if len(c.Left.Summary.FilesTouched)+len(c.Right.Summary.FilesTouched) > 0 {
+		lines = append(lines, styles.Title.Render(" Files Touched"), styles.Muted.Render(strings.Repeat("─", width)))
+		n := max(len(c.Left.Summary.FilesTouched), len(c.Right.Summary.FilesTouched))
+		for i := range n {
+			left, right := "", ""
+			if i < len(c.Left.Summary.FilesTouched) {
+				left = relativeOrSelf(c.Left.Summary.FilesTouched[i], c.Left.Root)
+			}
+			if i < len(c.Right.Summary.FilesTouched) {
+				right = relativeOrSelf(c.Right.Summary.FilesTouched[i], c.Right.Root)
+			}
+			lines = append(lines, row("", styles.Body.Render(left), styles.Body.Render(right)))
+		}
+		lines = append(lines, "")
+	}
+
+	// Aligned turns
+	lines = append(lines, styles.Title.Render(" Turns"), styles.Muted.Render(strings.Repeat("─", width)))
+	if len(c.Pairs) == 0 {
+		lines = append(lines, styles.Muted.Render(" No turns"))
+	}
+	for i, pair := range c.Pairs {
+		lines = append(lines, row(fmt.Sprintf("#%d", i+1), exchangePrompt(pair.Left, colW), exchangePrompt(pair.Right, colW)))
+		lines = append(lines, row("", exchangeStats(pair.Left), exchangeStats(pair.Right)))
+	}
+	lines = append(lines, "")
+
+	// Final file changes
+	lines = append(lines, styles.Title.Render(" File Changes (left → right)"), styles.Muted.Render(strings.Repeat("─", width)))
+	switch {
+	case c.SameRoot:
+		lines = append(lines, styles.Muted.Render(" Both sessions ran in the same directory; no file diff available"))
+	case len(c.FileDiffs) == 0:
+		lines = append(lines, styles.Muted.Render(" Touched files are identical in both worktrees"))
+	}
+	for _, fd := range c.FileDiffs {
+		header := fmt.Sprintf(" %s ", fd.Path)
+		stats := styles.DiffAdd.Render(fmt.Sprintf("+%d", fd.Added)) + " " + styles.DiffRemove.Render(fmt.Sprintf("-%d", fd.Removed))
+		status := ""
+		switch fd.Status {
+		case "left-only":
+			status = styles.Muted.Render(" (only in left)")
+		case "right-only":
+			status = styles.Muted.Render(" (only in right)")
+		}
+		lines = append(lines, styles.DiffHeader.Render(header)+stats+status)
+		for _, l := range fd.Lines {
+			l = ansi.Truncate(strings.ReplaceAll(l, "\t", "    "), width, "…")
+			switch {
+			case strings.HasPrefix(l, "@@"):
+				lines = append(lines, styles.Muted.Render(l))
+			case strings.HasPrefix(l, "+"):
+				lines = append(lines, styles.DiffAdd.Render(l))
+			case strings.HasPrefix(l, "-"):
+				lines = append(lines, styles.DiffRemove.Render(l))
+			default:
+				lines = append(lines, styles.DiffContext.Render(l))
+			}
+		}
+		lines = append(lines, "")
+	}
+	return lines
+}
+
+// padCell truncates or pads a styled string to exactly width cells.
+func padCell(s string, width int) string {
+	if lipgloss.Width(s) > width {
+		s = ansi.Truncate(s, width, "…")
+	}
+	if w := lipgloss.Width(s); w < width {
+		s += strings.Repeat(" ", width-w)
+	}
+	return s
+}
+
+func sessionTitle(side CompareSide) string {
+	if side.Session.Name != "" {
+		return side.Session.Name
+	}
+	return shortID(side.Session.ID)
+}
+
+func worktreeLabel(side CompareSide) string {
+	if side.Session.WorktreeName != "" {
+		return side.Session.WorktreeName
+	}
+	return styles.Muted.Render("(current)")
+}
+
+// compareCost renders a side's cost, highlighting the cheaper side.
+func compareCost(side, other CompareSide) string {
+	cost := side.Cost()
+	if cost == 0 {
+		return styles.Muted.Render("—")
+	}
+	label := formatCost(cost)
+	if other.Cost() > 0 && cost < other.Cost() {
+		return lipgloss.NewStyle().Foreground(styles.Success).Render(label)
+	}
+	return styles.Body.Render(label)
+}
+
+func countCell(n int) string {
+	if n == 0 {
+		return styles.Muted.Render("·")
+	}
+	return styles.Body.Render(fmt.Sprintf("%d", n))
+}
+
+// mergedToolNames returns tool names from both sides ordered by combined use.
+func mergedToolNames(a, b map[string]int) []string {
+	totals := make(map[string]int)
+	for name, n := range a {
+		totals[name] += n
+	}
+	for name, n := range b {
+		totals[name] += n
+	}
+	names := make([]string, 0, len(totals))
+	for name := range totals {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if totals[names[i]] != totals[names[j]] {
+			return totals[names[i]] > totals[names[j]]
+		}
+		return names[i] < names[j]
+	})
+	return names
+}
+
+func relativeOrSelf(path, root string) string {
+	if rel := relativeToRoots(path, root); rel != "" {
+		return rel
+	}
+	return path
+}
+
+func exchangePrompt(ex *Exchange, width int) string {
+	if ex == nil {
+		return styles.Muted.Render("—")
+	}
+	prompt := strings.Join(strings.Fields(ex.Prompt), " ")
+	if prompt == "" {
+		prompt = "(no prompt)"
+	}
+	return styles.Body.Render(ansi.Truncate(prompt, width, "…"))
+}
+
+func exchangeStats(ex *Exchange) string {
+	if ex == nil {
+		return ""
+	}
+	parts := []string{fmt.Sprintf("%d tools", ex.ToolCount)}
+	if ex.TokensOut > 0 {
+		parts = append(parts, "out:"+formatK(ex.TokensOut))
+	}
+	if ex.Duration > 0 {
+		parts = append(parts, formatSessionDuration(ex.Duration))
+	}
+	return styles.Muted.Render(strings.Join(parts, " · "))
+}
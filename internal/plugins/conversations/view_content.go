@@ -10,6 +10,7 @@ import (
 
 	"github.com/charmbracelet/lipgloss"
 	"github.com/marcus/sidecar/internal/adapter"
+	"github.com/marcus/sidecar/internal/adapter/contextwindow"
 	"github.com/marcus/sidecar/internal/styles"
 	"github.com/marcus/sidecar/internal/ui"
 )
@@ -326,6 +327,41 @@ func adapterFilterOptions(adapters map[string]adapter.Adapter) []adapterFilterOp
 	return options
 }
 
+type tagFilterOption struct {
+	key string
+	tag string
+}
+
+// tagFilterOptions assigns an uppercase filter key to each tag, preferring
+// the tag's own letters. Tags without a free letter are omitted.
+func tagFilterOptions(tags []string) []tagFilterOption {
+	used := make(map[rune]bool)
+	var options []tagFilterOption
+	for _, tag := range tags {
+		var key rune
+		for _, r := range strings.ToUpper(tag) {
+			if r >= 'A' && r <= 'Z' && !used[r] {
+				key = r
+				break
+			}
+		}
+		if key == 0 {
+			for r := 'A'; r <= 'Z'; r++ {
+				if !used[r] {
+					key = r
+					break
+				}
+			}
+		}
+		if key == 0 {
+			continue
+		}
+		used[key] = true
+		options = append(options, tagFilterOption{key: string(key), tag: tag})
+	}
+	return options
+}
+
 func resumeCommand(session *adapter.Session) string {
 	if session == nil || session.ID == "" {
 		return ""
@@ -471,6 +507,44 @@ func renderTokenFlow(in, out int) string {
 	return styles.Muted.Render(fmt.Sprintf("in:%s out:%s", formatK(in), formatK(out)))
 }
 
+// compactionMarker returns the divider drawn above a message or turn that
+// starts a fresh context window, "" for any other event.
+func compactionMarker(event string, width int) string {
+	var label string
+	switch event {
+	case adapter.EventCompaction:
+		label = " context compacted "
+	case adapter.EventSummary:
+		label = " context summarized "
+	default:
+		return ""
+	}
+	side := (width - len(label)) / 2
+	if side > 12 {
+		side = 12
+	}
+	if side < 2 {
+		side = 2
+	}
+	return strings.Repeat("─", side) + label + strings.Repeat("─", side)
+}
+
+// formatContextGauge renders context window fill as "ctx 45% ▂▃▅▇ ⟳1":
+// current fill, a sparkline of fill over time, and the compaction count.
+func formatContextGauge(g contextwindow.Gauge, sparkWidth int) string {
+	if g.IsEmpty() {
+		return ""
+	}
+	s := fmt.Sprintf("ctx %d%%", int(g.Current.Percent()))
+	if spark := g.Sparkline(sparkWidth); len(g.Points) > 1 && spark != "" {
+		s += " " + spark
+	}
+	if g.Compactions > 0 {
+		s += fmt.Sprintf(" ⟳%d", g.Compactions)
+	}
+	return s
+}
+
 // formatSessionDuration formats session duration for display.
 func formatSessionDuration(d time.Duration) string {
 	if d < time.Minute {
@@ -660,6 +734,11 @@ func (p *Plugin) renderMessageBubble(msg adapter.Message, msgIndex int, maxWidth
 		cursorPrefix = "> "
 	}
 
+	// Divider marking a compaction or summary that reset the context window
+	if marker := compactionMarker(msg.Event, maxWidth); marker != "" {
+		lines = append(lines, "  "+styles.StatusModified.Render(marker))
+	}
+
 	var headerLine string
 	if selected {
 		// For selected messages, use plain text (no colored backgrounds) for consistent highlighting
@@ -727,6 +806,16 @@ func (p *Plugin) renderMessageBubble(msg adapter.Message, msgIndex int, maxWidth
 		}
 	}
 
+	// User annotation for this message
+	if session != nil {
+		if note, ok := p.sessionMeta(*session).Annotation(msg.ID); ok {
+			noteStyle := lipgloss.NewStyle().Foreground(styles.Warning)
+			for _, line := range wrapText("✎ "+note, maxWidth-4) {
+				lines = append(lines, "    "+noteStyle.Render(line))
+			}
+		}
+	}
+
 	// Apply selection highlighting if needed
 	if selected {
 		var styledLines []string
@@ -1078,8 +1167,27 @@ func (p *Plugin) renderFilterMenu(height int) string {
 		activeCheck = "[✓]"
 	}
 	sb.WriteString(fmt.Sprintf("  %s %s Active only\n", styles.Code.Render("a"), activeCheck))
+	starredCheck := "[ ]"
+	if p.filters.Starred {
+		starredCheck = "[✓]"
+	}
+	sb.WriteString(fmt.Sprintf("  %s %s Starred only\n", styles.Code.Render("*"), starredCheck))
 	sb.WriteString("\n")
 
+	// Tag filters (uppercase keys; lowercase are taken by the options above)
+	if tagOptions := tagFilterOptions(p.meta.AllTags()); len(tagOptions) > 0 {
+		sb.WriteString(styles.Subtitle.Render("Tags:"))
+		sb.WriteString("\n")
+		for _, opt := range tagOptions {
+			checkbox := "[ ]"
+			if p.filters.HasTag(opt.tag) {
+				checkbox = "[✓]"
+			}
+			sb.WriteString(fmt.Sprintf("  %s %s #%s\n", styles.Code.Render(opt.key), checkbox, opt.tag))
+		}
+		sb.WriteString("\n")
+	}
+
 	// Clear filters
 	sb.WriteString(fmt.Sprintf("  %s Clear all filters\n", styles.Code.Render("x")))
 
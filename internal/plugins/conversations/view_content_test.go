@@ -1,9 +1,11 @@
 package conversations
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/marcus/sidecar/internal/adapter"
+	"github.com/marcus/sidecar/internal/adapter/contextwindow"
 )
 
 func TestAdapterAbbrev(t *testing.T) {
@@ -156,3 +158,30 @@ func TestAdapterBadgeText(t *testing.T) {
 		})
 	}
 }
+
+func TestCompactionMarker(t *testing.T) {
+	if got := compactionMarker("", 40); got != "" {
+		t.Errorf("expected no marker for plain message, got %q", got)
+	}
+	got := compactionMarker(adapter.EventCompaction, 40)
+	if !strings.Contains(got, "context compacted") || !strings.HasPrefix(got, "──") {
+		t.Errorf("unexpected compaction marker %q", got)
+	}
+	if got := compactionMarker(adapter.EventSummary, 10); !strings.Contains(got, "context summarized") {
+		t.Errorf("unexpected summary marker %q", got)
+	}
+}
+
+func TestFormatContextGauge(t *testing.T) {
+	msgs := []adapter.Message{
+		{Model: "claude-sonnet-4-5", TokenUsage: adapter.TokenUsage{CacheRead: 100_000}},
+		{Event: adapter.EventCompaction},
+		{Model: "claude-sonnet-4-5", TokenUsage: adapter.TokenUsage{InputTokens: 40_000}},
+	}
+	if got := formatContextGauge(contextwindow.Measure(msgs), 12); got != "ctx 20% ▄▁▂ ⟳1" {
+		t.Errorf("formatContextGauge() = %q", got)
+	}
+	if got := formatContextGauge(contextwindow.Measure(nil), 12); got != "" {
+		t.Errorf("expected empty gauge, got %q", got)
+	}
+}
@@ -243,6 +243,9 @@ func (p *Plugin) registerMessageHitRegions(mainX, contentWidth, contentHeight, h
 // calculateTurnHeight returns the number of lines a turn will occupy when rendered.
 func (p *Plugin) calculateTurnHeight(turn Turn, maxWidth int) int {
 	height := 1 // header line always present
+	if turn.ContextEvent() != "" {
+		height++
+	}
 	if turn.ThinkingTokens > 0 {
 		height++
 	}
@@ -255,6 +258,9 @@ func (p *Plugin) calculateTurnHeight(turn Turn, maxWidth int) int {
 	if turn.ToolCount > 0 {
 		height++
 	}
+	if session := p.findSelectedSession(); session != nil && turnAnnotations(p.sessionMeta(*session), turn) != "" {
+		height++
+	}
 	return height
 }
 
@@ -304,10 +310,18 @@ func (p *Plugin) renderSidebarPane(height int) string {
 	// Search bar (if in search mode)
 	if p.searchMode {
 		searchLine := fmt.Sprintf("/%s█", p.searchQuery)
+		if p.queryDetailsLoading {
+			searchLine += " scanning..."
+		}
 		if len(searchLine) > contentWidth {
 			searchLine = searchLine[:contentWidth]
 		}
-		sb.WriteString(styles.StatusInProgress.Render(searchLine))
+		// Unparseable queries fall back to plain text search; flag them
+		searchStyle := styles.StatusInProgress
+		if p.searchQueryErr != nil {
+			searchStyle = styles.StatusDeleted
+		}
+		sb.WriteString(searchStyle.Render(searchLine))
 		sb.WriteString("\n")
 		linesUsed++
 	} else if p.filterActive {
@@ -505,6 +519,10 @@ func (p *Plugin) renderCompactSessionRow(session adapter.Session, selected bool,
 	// Category badge (cron/sys) for non-interactive sessions
 	catBadge := categoryBadgeText(session)
 
+	// Star/note/tag badges from sidecar-owned metadata
+	meta := p.sessionMeta(session)
+	metaBadge := sessionMetaBadgeText(meta)
+
 	// Calculate prefix length for width calculations
 	// active(1) + badge + space + worktree + space (if worktree)
 	prefixLen := 1 + len(badgeText) + 1
@@ -514,6 +532,9 @@ func (p *Plugin) renderCompactSessionRow(session adapter.Session, selected bool,
 	if catBadge != "" {
 		prefixLen += len(catBadge) + 1 // category badge + space
 	}
+	if metaBadge != "" {
+		prefixLen += lipgloss.Width(metaBadge) + 1 // meta badge + space
+	}
 	if session.IsSubAgent {
 		prefixLen += 2 // extra indent for sub-agents
 	}
@@ -552,6 +573,9 @@ func (p *Plugin) renderCompactSessionRow(session adapter.Session, selected bool,
 	if catBadge != "" {
 		visibleLen += len(catBadge) + 1 // category badge + space
 	}
+	if metaBadge != "" {
+		visibleLen += lipgloss.Width(metaBadge) + 1 // meta badge + space
+	}
 	padding := maxWidth - visibleLen - rightColWidth - 1
 	if padding < 0 {
 		padding = 0
@@ -565,8 +589,11 @@ func (p *Plugin) renderCompactSessionRow(session adapter.Session, selected bool,
 		sb.WriteString("  ")
 	}
 
-	// Activity indicator with colors
-	if session.IsActive {
+	// Activity indicator with colors (compare mark takes precedence)
+	compareMarked := p.compareMark != nil && compareKey(*p.compareMark) == compareKey(session)
+	if compareMarked {
+		sb.WriteString(lipgloss.NewStyle().Foreground(styles.Accent).Render("◆"))
+	} else if session.IsActive {
 		sb.WriteString(styles.StatusInProgress.Render("●"))
 	} else if session.IsSubAgent {
 		sb.WriteString(styles.Muted.Render("↳"))
@@ -601,6 +628,10 @@ func (p *Plugin) renderCompactSessionRow(session adapter.Session, selected bool,
 		sb.WriteString(" ")
 		sb.WriteString(renderCategoryBadge(session))
 	}
+	if metaBadge != "" {
+		sb.WriteString(" ")
+		sb.WriteString(renderSessionMetaBadge(meta))
+	}
 
 	// Padding and right-aligned stats (only if we have data)
 	if rightColWidth > 0 && padding > 0 {
@@ -629,7 +660,9 @@ func (p *Plugin) renderCompactSessionRow(session adapter.Session, selected bool,
 		if session.IsSubAgent {
 			plain.WriteString("  ")
 		}
-		if session.IsActive {
+		if compareMarked {
+			plain.WriteString("◆")
+		} else if session.IsActive {
 			plain.WriteString("●")
 		} else if session.IsSubAgent {
 			plain.WriteString("↳")
@@ -647,6 +680,10 @@ func (p *Plugin) renderCompactSessionRow(session adapter.Session, selected bool,
 			plain.WriteString(" ")
 			plain.WriteString(catBadge)
 		}
+		if metaBadge != "" {
+			plain.WriteString(" ")
+			plain.WriteString(metaBadge)
+		}
 		if rightColWidth > 0 && padding > 0 {
 			plain.WriteString(strings.Repeat(" ", padding))
 			plain.WriteString(" ")
@@ -705,8 +742,17 @@ func (p *Plugin) renderMainPane(paneWidth, height int) string {
 		sessionName = session.Name
 	}
 
-	// Calculate max length for session name (leave room for icon)
+	// Star/note/tag badges shown after the name
+	metaBadge := ""
+	if session != nil {
+		metaBadge = renderSessionMetaBadge(p.sessionMeta(*session))
+	}
+
+	// Calculate max length for session name (leave room for icon and badges)
 	maxSessionLen := contentWidth - 4
+	if metaBadge != "" {
+		maxSessionLen -= lipgloss.Width(metaBadge) + 1
+	}
 	if maxSessionLen < 10 {
 		maxSessionLen = 10
 	}
@@ -720,6 +766,10 @@ func (p *Plugin) renderMainPane(paneWidth, height int) string {
 		sb.WriteString(" ")
 	}
 	sb.WriteString(styles.Title.Render(sessionName))
+	if metaBadge != "" {
+		sb.WriteString(" ")
+		sb.WriteString(metaBadge)
+	}
 	sb.WriteString("\n")
 
 	// Header Line 2: Model badge │ msgs │ tokens │ cost │ date
@@ -749,6 +799,11 @@ func (p *Plugin) renderMainPane(paneWidth, height int) string {
 		// Token flow
 		statsParts = append(statsParts, fmt.Sprintf("in:%s out:%s", formatK(s.TotalTokensIn), formatK(s.TotalTokensOut)))
 
+		// Context window fill
+		if gauge := formatContextGauge(s.Context, 12); gauge != "" {
+			statsParts = append(statsParts, gauge)
+		}
+
 		// Cost estimate
 		if session != nil && session.EstCost > 0 {
 			statsParts = append(statsParts, formatCost(session.EstCost))
@@ -1044,6 +1099,18 @@ func (p *Plugin) renderCompactTurn(turn Turn, turnIndex int, maxWidth int) []str
 	var lines []string
 	selected := turnIndex == p.turnCursor
 
+	// Divider marking a compaction or summary that reset the context window
+	if marker := compactionMarker(turn.ContextEvent(), maxWidth); marker != "" {
+		if selected {
+			if w := lipgloss.Width(marker); w < maxWidth {
+				marker += strings.Repeat(" ", maxWidth-w)
+			}
+			lines = append(lines, styles.ListItemSelected.Render(marker))
+		} else {
+			lines = append(lines, styles.StatusModified.Render(marker))
+		}
+	}
+
 	// Header line: [timestamp] role (N msgs) tokens
 	ts := turn.FirstTimestamp()
 
@@ -1114,6 +1181,18 @@ func (p *Plugin) renderCompactTurn(turn Turn, turnIndex int, maxWidth int) []str
 		lines = append(lines, p.styleTurnLine(toolLine, selected, maxWidth))
 	}
 
+	// User annotations on this turn's messages
+	if session != nil {
+		if note := turnAnnotations(p.sessionMeta(*session), turn); note != "" {
+			noteLine := ui.TruncateString("   ✎ "+strings.ReplaceAll(note, "\n", " "), maxWidth)
+			if selected {
+				lines = append(lines, p.styleTurnLine(noteLine, true, maxWidth))
+			} else {
+				lines = append(lines, lipgloss.NewStyle().Foreground(styles.Warning).Render(noteLine))
+			}
+		}
+	}
+
 	return lines
 }
 
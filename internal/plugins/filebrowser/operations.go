@@ -1090,9 +1090,10 @@ func (p *Plugin) expandParents(node *FileNode) {
 	}
 }
 
-// navigateToFile navigates the file browser to a specific file path.
+// navigateToFile navigates the file browser to a specific file path, scrolled
+// to lineNo when it is set.
 // Used when other plugins request navigation (e.g., git plugin opening file in browser).
-func (p *Plugin) navigateToFile(path string) (plugin.Plugin, tea.Cmd) {
+func (p *Plugin) navigateToFile(path string, lineNo int) (plugin.Plugin, tea.Cmd) {
 	// Find the file node in tree
 	var targetNode *FileNode
 	p.walkTree(p.tree.Root, func(node *FileNode) {
@@ -1118,7 +1119,7 @@ func (p *Plugin) navigateToFile(path string) (plugin.Plugin, tea.Cmd) {
 
 	// Load preview
 	p.activePane = PanePreview
-	return p, p.openTab(path, TabOpenNew)
+	return p, p.openTabAtLine(path, lineNo, TabOpenNew)
 }
 
 // copySelectedTextToClipboard copies the selected text to the system clipboard
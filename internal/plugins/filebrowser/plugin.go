@@ -60,6 +60,7 @@ type (
 	// NavigateToFileMsg requests navigation to a specific file (from other plugins).
 	NavigateToFileMsg struct {
 		Path string // Relative path from workdir
+		Line int    // 1-based line to scroll the preview to (0 for the top)
 	}
 	// RevealErrorMsg is sent when reveal in file manager fails.
 	RevealErrorMsg struct {
@@ -545,7 +546,7 @@ func (p *Plugin) Update(msg tea.Msg) (plugin.Plugin, tea.Cmd) {
 		if p.pendingOpenFile != "" {
 			path := p.pendingOpenFile
 			p.pendingOpenFile = "" // Clear immediately to avoid re-processing
-			_, navCmd := p.navigateToFile(path)
+			_, navCmd := p.navigateToFile(path, 0)
 			// Restore state after first tree build
 			if !p.stateRestored {
 				p.stateRestored = true
@@ -659,7 +660,7 @@ func (p *Plugin) Update(msg tea.Msg) (plugin.Plugin, tea.Cmd) {
 		return p, tea.Batch(cmds...)
 
 	case NavigateToFileMsg:
-		return p.navigateToFile(msg.Path)
+		return p.navigateToFile(msg.Path, msg.Line)
 
 	case RevealErrorMsg:
 		p.ctx.Logger.Error("file browser: reveal failed", "error", msg.Err)
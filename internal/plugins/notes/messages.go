@@ -1,8 +1,10 @@
 package notes
 
+import "github.com/marcus/sidecar/internal/notestore"
+
 // NotesLoadedMsg is sent when notes are loaded from the database.
 type NotesLoadedMsg struct {
-	Notes []Note
+	Notes []notestore.Note
 	Err   error
 	Epoch uint64
 }
@@ -14,7 +16,7 @@ func (m NotesLoadedMsg) GetEpoch() uint64 {
 
 // NoteSavedMsg is sent when a note is created or updated.
 type NoteSavedMsg struct {
-	Note  *Note
+	Note  *notestore.Note
 	Err   error
 	Epoch uint64
 }
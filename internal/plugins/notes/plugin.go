@@ -15,6 +15,7 @@ import (
 	"github.com/marcus/sidecar/internal/modal"
 	"github.com/marcus/sidecar/internal/mouse"
 	"github.com/marcus/sidecar/internal/msg"
+	"github.com/marcus/sidecar/internal/notestore"
 	"github.com/marcus/sidecar/internal/plugin"
 	"github.com/marcus/sidecar/internal/state"
 	"github.com/marcus/sidecar/internal/styles"
@@ -64,7 +65,7 @@ func (f NoteFilter) String() string {
 type Plugin struct {
 	ctx     *plugin.Context
 	focused bool
-	store   *Store
+	store   *notestore.Store
 
 	// View dimensions
 	width  int
@@ -78,7 +79,7 @@ type Plugin struct {
 	viewFilter NoteFilter // Active, Archived, or Deleted view
 
 	// Note state
-	notes     []Note
+	notes     []notestore.Note
 	cursor    int
 	scrollOff int
 	loading   bool
@@ -93,10 +94,10 @@ type Plugin struct {
 	filteredNotes []NoteMatch // filtered results
 
 	// Editor state
-	editorNote     *Note          // The note being edited (nil = no note open)
-	editorTextarea textarea.Model // Bubbles textarea for edit mode
-	editorDirty    bool           // Unsaved changes
-	previewMode    bool           // true = read-only preview, false = editing
+	editorNote     *notestore.Note // The note being edited (nil = no note open)
+	editorTextarea textarea.Model  // Bubbles textarea for edit mode
+	editorDirty    bool            // Unsaved changes
+	previewMode    bool            // true = read-only preview, false = editing
 
 	// Preview mode state (read-only navigation)
 	previewLines       []string // Lines for preview mode rendering
@@ -112,7 +113,7 @@ type Plugin struct {
 	showTaskModal         bool
 	taskModal             *modal.Modal
 	taskModalWidth        int
-	taskModalNote         *Note
+	taskModalNote         *notestore.Note
 	taskModalTitleInput   textinput.Model
 	taskModalTypeIdx      int
 	taskModalPriorityIdx  int
@@ -123,14 +124,14 @@ type Plugin struct {
 	showDeleteModal         bool
 	deleteModal             *modal.Modal
 	deleteModalWidth        int
-	deleteModalNote         *Note
+	deleteModalNote         *notestore.Note
 	deleteModalMouseHandler *mouse.Handler
 
 	// Info modal state
 	showInfoModal         bool
 	infoModal             *modal.Modal
 	infoModalWidth        int
-	infoModalNote         *Note
+	infoModalNote         *notestore.Note
 	infoModalMouseHandler *mouse.Handler
 
 	// Pending edit state (for auto-edit on new note)
@@ -269,8 +270,8 @@ func (p *Plugin) Init(ctx *plugin.Context) error {
 
 	// Initialize store - session ID resolved by store from TD_SESSION_ID env var
 	// or falls back to "sidecar" if not set
-	dbPath := DefaultDBPath(ctx.ProjectRoot)
-	store, err := NewStore(dbPath, "")
+	dbPath := notestore.DefaultDBPath(ctx.ProjectRoot)
+	store, err := notestore.NewStore(dbPath, "")
 	if err != nil {
 		// Store initialization may fail if .todos directory doesn't exist
 		// This is OK - plugin will show appropriate message
@@ -972,7 +973,7 @@ func (p *Plugin) syncPreviewFromTextarea() {
 }
 
 // syncEditorFromNote refreshes editor/preview buffers from the note content.
-func (p *Plugin) syncEditorFromNote(note *Note) {
+func (p *Plugin) syncEditorFromNote(note *notestore.Note) {
 	if note == nil {
 		return
 	}
@@ -1301,9 +1302,9 @@ func (p *Plugin) updateFilteredNotes() {
 }
 
 // getDisplayNotes returns the notes to display (filtered or all).
-func (p *Plugin) getDisplayNotes() []Note {
+func (p *Plugin) getDisplayNotes() []notestore.Note {
 	if p.searchQuery != "" && len(p.filteredNotes) > 0 {
-		notes := make([]Note, len(p.filteredNotes))
+		notes := make([]notestore.Note, len(p.filteredNotes))
 		for i, m := range p.filteredNotes {
 			notes[i] = m.Note
 		}
@@ -1313,7 +1314,7 @@ func (p *Plugin) getDisplayNotes() []Note {
 }
 
 // getSelectedNote returns the currently selected note from display list.
-func (p *Plugin) getSelectedNote() *Note {
+func (p *Plugin) getSelectedNote() *notestore.Note {
 	notesList := p.getDisplayNotes()
 	if len(notesList) == 0 || p.cursor < 0 || p.cursor >= len(notesList) {
 		return nil
@@ -1354,7 +1355,7 @@ func (p *Plugin) View(width, height int) string {
 }
 
 // selectedNote returns the currently selected note, or nil if none.
-func (p *Plugin) selectedNote() *Note {
+func (p *Plugin) selectedNote() *notestore.Note {
 	return p.getSelectedNote()
 }
 
@@ -1633,7 +1634,7 @@ func (p *Plugin) loadNotes() tea.Cmd {
 	filter := p.viewFilter
 
 	return func() tea.Msg {
-		var notes []Note
+		var notes []notestore.Note
 		var err error
 
 		switch filter {
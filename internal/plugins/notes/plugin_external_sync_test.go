@@ -4,19 +4,20 @@ import (
 	"testing"
 
 	"github.com/charmbracelet/bubbles/textarea"
+	"github.com/marcus/sidecar/internal/notestore"
 )
 
 func TestNotesLoadedSyncsEditorAfterOutOfBandSave(t *testing.T) {
 	p := New()
 	p.height = 24
 	p.editorTextarea = textarea.New()
-	p.editorNote = &Note{ID: "nt-1", Content: "before"}
+	p.editorNote = &notestore.Note{ID: "nt-1", Content: "before"}
 	p.editorTextarea.SetValue("before")
 	p.previewLines = []string{"before"}
 	p.pendingEditorSyncID = "nt-1"
 
 	_, _ = p.Update(NotesLoadedMsg{
-		Notes: []Note{
+		Notes: []notestore.Note{
 			{ID: "nt-1", Content: "after"},
 		},
 	})
@@ -36,12 +37,12 @@ func TestNotesLoadedDoesNotSyncEditorWithoutPendingMarker(t *testing.T) {
 	p := New()
 	p.height = 24
 	p.editorTextarea = textarea.New()
-	p.editorNote = &Note{ID: "nt-1", Content: "before"}
+	p.editorNote = &notestore.Note{ID: "nt-1", Content: "before"}
 	p.editorTextarea.SetValue("local edit buffer")
 	p.previewLines = []string{"local edit buffer"}
 
 	_, _ = p.Update(NotesLoadedMsg{
-		Notes: []Note{
+		Notes: []notestore.Note{
 			{ID: "nt-1", Content: "after"},
 		},
 	})
@@ -4,11 +4,13 @@ import (
 	"sort"
 	"strings"
 	"unicode"
+
+	"github.com/marcus/sidecar/internal/notestore"
 )
 
 // NoteMatch represents a note matching the search query.
 type NoteMatch struct {
-	Note  Note
+	Note  notestore.Note
 	Score int // Higher = better match
 }
 
@@ -18,7 +20,7 @@ type NoteMatch struct {
 //   - Word start matches: large bonus
 //   - Title match bonus: prefer title matches over content
 //   - Shorter text: small bonus
-func FuzzyMatchNote(query string, note Note) int {
+func FuzzyMatchNote(query string, note notestore.Note) int {
 	if query == "" {
 		return 0
 	}
@@ -96,7 +98,7 @@ func isWordSeparator(r rune) bool {
 }
 
 // ExactTitleMatch returns true if query matches a note's title exactly (case-insensitive).
-func ExactTitleMatch(query string, note Note) bool {
+func ExactTitleMatch(query string, note notestore.Note) bool {
 	queryLower := strings.ToLower(strings.TrimSpace(query))
 	if queryLower == "" {
 		return false
@@ -116,7 +118,7 @@ func ExactTitleMatch(query string, note Note) bool {
 
 // FilterNotes filters and scores notes against a query.
 // Returns matches sorted by score descending.
-func FilterNotes(notes []Note, query string) []NoteMatch {
+func FilterNotes(notes []notestore.Note, query string) []NoteMatch {
 	if query == "" {
 		// Return all notes as matches with default score
 		var matches []NoteMatch
@@ -149,7 +151,7 @@ func FilterNotes(notes []Note, query string) []NoteMatch {
 }
 
 // FindExactTitleMatch returns the note with exact title match, or nil if none.
-func FindExactTitleMatch(notes []Note, query string) *Note {
+func FindExactTitleMatch(notes []notestore.Note, query string) *notestore.Note {
 	for i := range notes {
 		if ExactTitleMatch(query, notes[i]) {
 			return &notes[i]
@@ -7,6 +7,7 @@ import (
 	"github.com/charmbracelet/lipgloss"
 	"github.com/charmbracelet/x/ansi"
 	"github.com/charmbracelet/x/cellbuf"
+	"github.com/marcus/sidecar/internal/notestore"
 	"github.com/marcus/sidecar/internal/styles"
 	"github.com/marcus/sidecar/internal/ui"
 )
@@ -541,6 +542,9 @@ func (p *Plugin) renderSearchInput() string {
 	return sb.String()
 }
 
+// maxTitleLength is the maximum length for note titles (truncated when displaying)
+const maxTitleLength = 80
+
 // Note status icon constants
 const (
 	iconArchived = "\u25cb" // White circle for archived
@@ -549,7 +553,7 @@ const (
 
 // renderNoteRow renders a single note row.
 // Active notes show just the title; archived/deleted notes show icon + title.
-func (p *Plugin) renderNoteRow(note Note, selected bool, maxWidth int) string {
+func (p *Plugin) renderNoteRow(note notestore.Note, selected bool, maxWidth int) string {
 	var prefix strings.Builder
 
 	// Status icon only for archived/deleted notes (no placeholder for active)
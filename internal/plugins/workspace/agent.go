@@ -10,7 +10,6 @@ import (
 	"os/exec"
 	"path/filepath"
 	"regexp"
-	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -213,6 +212,7 @@ const (
 	// Timeout for tmux capture commands to avoid blocking on hung sessions
 	tmuxCaptureTimeout      = 2 * time.Second
 	tmuxBatchCaptureTimeout = 3 * time.Second
+	tmuxHistoryTimeout      = 5 * time.Second // Full-scrollback capture for output search
 
 	// Polling intervals - adaptive based on agent status and visibility
 	// Fast (visible+focused): 200ms active, 2s idle
@@ -346,8 +346,7 @@ func (p *Plugin) StartAgent(wt *Worktree, agentType AgentType) tea.Cmd {
 		sessionName := tmuxSessionPrefix + sanitizeName(wt.Name)
 
 		// Check if session already exists
-		checkCmd := exec.Command("tmux", "has-session", "-t", sessionName)
-		if checkCmd.Run() == nil {
+		if sessionExists(sessionName) {
 			// Session exists - reconnect to it instead of failing
 			paneID := getPaneID(sessionName)
 			return AgentStartedMsg{
@@ -361,39 +360,24 @@ func (p *Plugin) StartAgent(wt *Worktree, agentType AgentType) tea.Cmd {
 		}
 
 		// Create new detached session with working directory
-		args := []string{
-			"new-session",
-			"-d",              // Detached
-			"-s", sessionName, // Session name
-			"-c", wt.Path, // Working directory
-		}
-
-		cmd := exec.Command("tmux", args...)
-		if err := cmd.Run(); err != nil {
+		if err := sessionBackend.Create(sessionName, wt.Path, 0, 0); err != nil {
 			return AgentStartedMsg{Epoch: epoch, Err: fmt.Errorf("create session: %w", err)}
 		}
 
-		// Ensure server persists when all sessions are killed
-		ensureTmuxServerConfig()
-
-		// Set history limit for scrollback capture
-		_ = exec.Command("tmux", "set-option", "-t", sessionName, "history-limit",
-			strconv.Itoa(tmuxHistoryLimit)).Run()
-
 		// Set TD_SESSION_ID environment variable for td session tracking
 		envCmd := fmt.Sprintf("export TD_SESSION_ID=%s", shellQuote(sessionName))
-		_ = exec.Command("tmux", "send-keys", "-t", sessionName, envCmd, "Enter").Run()
+		_ = sendSessionLine(sessionName, envCmd)
 
 		// Apply environment isolation to prevent conflicts (GOWORK, etc.)
-		envOverrides := BuildEnvOverrides(p.ctx.WorkDir)
+		envOverrides := BuildWorktreeEnvOverrides(p.ctx.WorkDir, p.ctx.ProjectRoot, wt.Path, wt.Branch)
 		if envCmd := GenerateSingleEnvCommand(envOverrides); envCmd != "" {
-			_ = exec.Command("tmux", "send-keys", "-t", sessionName, envCmd, "Enter").Run()
+			_ = sendSessionLine(sessionName, envCmd)
 		}
 
 		// If worktree has a linked task, start it in td
 		if wt.TaskID != "" {
 			tdStartCmd := fmt.Sprintf("td start %s", wt.TaskID)
-			_ = exec.Command("tmux", "send-keys", "-t", sessionName, tdStartCmd, "Enter").Run()
+			_ = sendSessionLine(sessionName, tdStartCmd)
 		}
 
 		// Small delay to ensure env is set
@@ -403,10 +387,9 @@ func (p *Plugin) StartAgent(wt *Worktree, agentType AgentType) tea.Cmd {
 		agentCmd := p.getAgentCommandWithContext(agentType, wt)
 
 		// Send the agent command to start it
-		sendCmd := exec.Command("tmux", "send-keys", "-t", sessionName, agentCmd, "Enter")
-		if err := sendCmd.Run(); err != nil {
+		if err := sendSessionLine(sessionName, agentCmd); err != nil {
 			// Try to kill the session if we failed to start the agent
-			_ = exec.Command("tmux", "kill-session", "-t", sessionName).Run()
+			_ = sessionBackend.Kill(sessionName)
 			return AgentStartedMsg{Epoch: epoch, Err: fmt.Errorf("start agent: %w", err)}
 		}
 
@@ -537,6 +520,7 @@ func (p *Plugin) buildAgentCommand(agentType AgentType, wt *Worktree, skipPerms
 		worktreePath = wt.Path
 	}
 	baseCmd := p.resolveAgentBaseCommand(worktreePath, agentType)
+	baseCmd = p.withAgentHooks(agentType, worktreePath, baseCmd)
 
 	// Apply skip permissions flag if requested
 	if skipPerms {
@@ -548,8 +532,8 @@ func (p *Plugin) buildAgentCommand(agentType AgentType, wt *Worktree, skipPerms
 	// Determine context to pass to agent
 	var ctx string
 	if prompt != nil {
-		// Use prompt template with ticket expansion
-		ctx = ExpandPromptTemplate(prompt.Body, wt.TaskID)
+		// Use prompt template with variable expansion
+		ctx = p.expandPrompt(prompt, wt)
 	} else if wt.TaskID != "" {
 		// No prompt selected but task selected: try to fetch full context
 		ctx = p.getTaskContext(wt.TaskID)
@@ -662,8 +646,7 @@ func (p *Plugin) StartAgentWithOptions(wt *Worktree, agentType AgentType, skipPe
 		sessionName := tmuxSessionPrefix + sanitizeName(wt.Name)
 
 		// Check if session already exists
-		checkCmd := exec.Command("tmux", "has-session", "-t", sessionName)
-		if checkCmd.Run() == nil {
+		if sessionExists(sessionName) {
 			// Session exists - reconnect to it instead of failing
 			paneID := getPaneID(sessionName)
 			return AgentStartedMsg{
@@ -677,39 +660,24 @@ func (p *Plugin) StartAgentWithOptions(wt *Worktree, agentType AgentType, skipPe
 		}
 
 		// Create new detached session with working directory
-		args := []string{
-			"new-session",
-			"-d",              // Detached
-			"-s", sessionName, // Session name
-			"-c", wt.Path, // Working directory
-		}
-
-		cmd := exec.Command("tmux", args...)
-		if err := cmd.Run(); err != nil {
+		if err := sessionBackend.Create(sessionName, wt.Path, 0, 0); err != nil {
 			return AgentStartedMsg{Epoch: epoch, Err: fmt.Errorf("create session: %w", err)}
 		}
 
-		// Ensure server persists when all sessions are killed
-		ensureTmuxServerConfig()
-
-		// Set history limit for scrollback capture
-		_ = exec.Command("tmux", "set-option", "-t", sessionName, "history-limit",
-			strconv.Itoa(tmuxHistoryLimit)).Run()
-
 		// Set TD_SESSION_ID environment variable for td session tracking
 		tdEnvCmd := fmt.Sprintf("export TD_SESSION_ID=%s", shellQuote(sessionName))
-		_ = exec.Command("tmux", "send-keys", "-t", sessionName, tdEnvCmd, "Enter").Run()
+		_ = sendSessionLine(sessionName, tdEnvCmd)
 
 		// Apply environment isolation to prevent conflicts (GOWORK, etc.)
-		envOverrides := BuildEnvOverrides(p.ctx.WorkDir)
+		envOverrides := BuildWorktreeEnvOverrides(p.ctx.WorkDir, p.ctx.ProjectRoot, wt.Path, wt.Branch)
 		if envCmd := GenerateSingleEnvCommand(envOverrides); envCmd != "" {
-			_ = exec.Command("tmux", "send-keys", "-t", sessionName, envCmd, "Enter").Run()
+			_ = sendSessionLine(sessionName, envCmd)
 		}
 
 		// If worktree has a linked task, start it in td
 		if wt.TaskID != "" {
 			tdStartCmd := fmt.Sprintf("td start %s", wt.TaskID)
-			_ = exec.Command("tmux", "send-keys", "-t", sessionName, tdStartCmd, "Enter").Run()
+			_ = sendSessionLine(sessionName, tdStartCmd)
 		}
 
 		// Small delay to ensure env is set
@@ -719,10 +687,9 @@ func (p *Plugin) StartAgentWithOptions(wt *Worktree, agentType AgentType, skipPe
 		agentCmd := p.buildAgentCommand(agentType, wt, skipPerms, prompt)
 
 		// Send the agent command to start it
-		sendCmd := exec.Command("tmux", "send-keys", "-t", sessionName, agentCmd, "Enter")
-		if err := sendCmd.Run(); err != nil {
+		if err := sendSessionLine(sessionName, agentCmd); err != nil {
 			// Try to kill the session if we failed to start the agent
-			_ = exec.Command("tmux", "kill-session", "-t", sessionName).Run()
+			_ = sessionBackend.Kill(sessionName)
 			return AgentStartedMsg{Epoch: epoch, Err: fmt.Errorf("start agent: %w", err)}
 		}
 
@@ -744,25 +711,14 @@ func (p *Plugin) AttachToWorktreeDir(wt *Worktree) tea.Cmd {
 	sessionName := tmuxSessionPrefix + sanitizeName(wt.Name)
 
 	// Check if session already exists
-	checkCmd := exec.Command("tmux", "has-session", "-t", sessionName)
-	if checkCmd.Run() != nil {
+	if !sessionExists(sessionName) {
 		// Session doesn't exist, create it
-		args := []string{
-			"new-session",
-			"-d",              // Detached
-			"-s", sessionName, // Session name
-			"-c", wt.Path, // Working directory
-		}
-		cmd := exec.Command("tmux", args...)
-		if err := cmd.Run(); err != nil {
+		if err := sessionBackend.Create(sessionName, wt.Path, 0, 0); err != nil {
 			return func() tea.Msg {
 				return TmuxAttachFinishedMsg{WorkspaceName: wt.Name, Err: fmt.Errorf("create session: %w", err)}
 			}
 		}
 
-		// Ensure server persists when all sessions are killed
-		ensureTmuxServerConfig()
-
 		// Track as managed session
 		p.managedSessions[sessionName] = true
 	}
@@ -811,31 +767,10 @@ func sanitizeName(name string) string {
 	return name
 }
 
-// getPaneID retrieves the tmux pane ID for a session.
-// Returns pane IDs like "%12" which are globally unique and stable.
-// Uses caching to avoid subprocess calls (pane IDs rarely change) (td-c2961e).
+// getPaneID retrieves a stable pane target for a session.
+// With tmux this is a pane ID like "%12", which is globally unique.
 func getPaneID(sessionName string) string {
-	// Check cache first
-	if paneID, ok := globalPaneIDCache.get(sessionName); ok {
-		return paneID
-	}
-
-	cmd := exec.Command("tmux", "list-panes", "-t", sessionName, "-F", "#{pane_id}")
-	output, err := cmd.Output()
-	if err != nil {
-		return ""
-	}
-	// Return first pane ID (sessions typically have one pane)
-	paneID := strings.TrimSpace(string(output))
-	if idx := strings.Index(paneID, "\n"); idx > 0 {
-		paneID = paneID[:idx]
-	}
-
-	// Cache for future lookups
-	if paneID != "" {
-		globalPaneIDCache.set(sessionName, paneID)
-	}
-	return paneID
+	return sessionBackend.PaneID(sessionName)
 }
 
 // staggerOffset returns a consistent stagger offset for a worktree name.
@@ -876,6 +811,7 @@ type AgentPollUnchangedMsg struct {
 	WorkspaceName string
 	CurrentStatus WorktreeStatus // Status including session file re-check
 	WaitingFor    string         // Prompt text if waiting
+	WaitingTool   toolCall       // Tool call the prompt is about, from hooks
 	// Cursor position captured atomically (even when content unchanged)
 	CursorRow     int
 	CursorCol     int
@@ -902,6 +838,11 @@ func (p *Plugin) handlePollAgent(worktreeName string) tea.Cmd {
 	maxBytes := p.tmuxCaptureMaxBytes
 	outputBuf := wt.Agent.OutputBuf
 	currentStatus := wt.Status
+	var projectRoot string
+	hooksEnabled := p.agentHooksEnabled()
+	if hooksEnabled {
+		projectRoot = p.ctx.ProjectRoot
+	}
 
 	// Use non-joined capture when interactive mode is active for this worktree
 	// to preserve tmux line wrapping for cursor positioning (td-c7dd1e).
@@ -983,39 +924,39 @@ func (p *Plugin) handlePollAgent(worktreeName string) tea.Cmd {
 		// Use hash-based change detection to skip processing if content unchanged
 		outputChanged := outputBuf == nil || outputBuf.Update(output)
 
-		// Detect status. Both detectors run; each is authoritative for what it's good at (td-2fca7d):
-		//   - tmux patterns: thinking, done, error (high-signal, session files can't detect these)
-		//   - session files: active vs waiting (reliable, tmux patterns are noisy for this)
-		// Session file detection ALWAYS runs (even when output unchanged) because the agent
-		// may finish while tmux output stays the same (td-2fca7d v8).
+		// Detect status from the most structured source available:
+		//   1. agent hooks (status events written by `sidecar agent-hook`)
+		//   2. agent session files (active/thinking/waiting/done from JSONL)
+		//   3. tmux output patterns, only when neither source is available
+		// Hook and session checks run every poll because the agent may finish
+		// while tmux output stays the same (td-2fca7d v8).
 		status := currentStatus
 		waitingFor := ""
+		var waitingTool toolCall
 		if !interactiveCapture {
-			if outputChanged {
+			var hookFile string
+			if hooksEnabled {
+				hookFile, _ = agentStatusFile(projectRoot, wtPath)
+			}
+			if hookStatus, hookWaiting, hookTool, ok := detectHookStatus(agentType, hookFile, wtPath); ok {
+				status, waitingFor, waitingTool = hookStatus, hookWaiting, hookTool
+				slog.Debug("status: agent hook", "worktree", worktreeName, "status", status)
+			} else if sessionStatus, ok := detectAgentSessionStatus(agentType, wtPath); ok {
+				status = sessionStatus
+				if status == StatusWaiting {
+					waitingFor = extractPrompt(output)
+					if waitingFor == "" {
+						waitingFor = "Waiting for input"
+					}
+				}
+				slog.Debug("status: session file", "worktree", worktreeName, "status", status)
+			} else if outputChanged {
 				// Tmux pattern detection only when output changes (same output = same patterns).
 				status = detectStatus(output)
 				if status == StatusWaiting {
 					waitingFor = extractPrompt(output)
 				}
-			}
-			// Session file check runs every poll — mtime changes independently of tmux output.
-			// Only override active/waiting/done; preserve tmux-detected thinking/error.
-			if status == StatusActive || status == StatusWaiting || status == StatusDone {
-				if sessionStatus, ok := detectAgentSessionStatus(agentType, wtPath); ok {
-					prevStatus := status
-					status = sessionStatus
-					if status == StatusWaiting {
-						waitingFor = extractPrompt(output)
-						if waitingFor == "" {
-							waitingFor = "Waiting for input"
-						}
-					} else {
-						waitingFor = ""
-					}
-					slog.Debug("status: session file override", "worktree", worktreeName, "prev", prevStatus, "session", sessionStatus)
-				} else {
-					slog.Debug("status: no session file, using tmux", "worktree", worktreeName, "status", status, "agent", agentType)
-				}
+				slog.Debug("status: tmux fallback", "worktree", worktreeName, "status", status, "agent", agentType)
 			}
 		}
 
@@ -1024,6 +965,7 @@ func (p *Plugin) handlePollAgent(worktreeName string) tea.Cmd {
 				WorkspaceName: worktreeName,
 				CurrentStatus: status,
 				WaitingFor:    waitingFor,
+				WaitingTool:   waitingTool,
 				CursorRow:     cursorRow,
 				CursorCol:     cursorCol,
 				CursorVisible: cursorVisible,
@@ -1038,6 +980,7 @@ func (p *Plugin) handlePollAgent(worktreeName string) tea.Cmd {
 			Output:        output,
 			Status:        status,
 			WaitingFor:    waitingFor,
+			WaitingTool:   waitingTool,
 			CursorRow:     cursorRow,
 			CursorCol:     cursorCol,
 			CursorVisible: cursorVisible,
@@ -1053,6 +996,13 @@ func (p *Plugin) handlePollAgent(worktreeName string) tea.Cmd {
 // On cache miss, captures active sessions at once to populate cache for concurrent polls.
 // Only captures sessions that have been recently polled (td-018f25).
 func capturePane(sessionName string) (string, error) {
+	// PTY sessions and tmux control-mode streams render from memory;
+	// batching and caching buy nothing
+	joinWrapped := !features.IsEnabled(features.TmuxInteractiveInput.Name)
+	if tmux, ok := sessionBackend.(tmuxBackend); !ok || tmux.streams(joinWrapped) {
+		return capturePaneDirect(sessionName)
+	}
+
 	// Mark this session as active (td-018f25)
 	globalActiveRegistry.markActive(sessionName)
 
@@ -1098,26 +1048,7 @@ func capturePaneDirect(sessionName string) (string, error) {
 // capturePaneDirectWithJoin captures a single pane without caching.
 // When joinWrapped is false, tmux preserves wrapped lines for correct cursor alignment.
 func capturePaneDirectWithJoin(sessionName string, joinWrapped bool) (string, error) {
-	startLine := fmt.Sprintf("-%d", captureLineCount)
-	ctx, cancel := context.WithTimeout(context.Background(), tmuxCaptureTimeout)
-	defer cancel()
-	args := []string{"capture-pane", "-p", "-e"}
-	if joinWrapped {
-		args = append(args, "-J")
-	}
-	args = append(args, "-S", startLine, "-t", sessionName)
-	cmd := exec.CommandContext(ctx, "tmux", args...)
-	output, err := cmd.Output()
-	if ctx.Err() == context.DeadlineExceeded {
-		return "", fmt.Errorf("capture-pane: timeout after %s", tmuxCaptureTimeout)
-	}
-	if err != nil {
-		if exitErr, ok := err.(*exec.ExitError); ok && len(exitErr.Stderr) > 0 {
-			return "", fmt.Errorf("capture-pane: %s", strings.TrimSpace(string(exitErr.Stderr)))
-		}
-		return "", fmt.Errorf("capture-pane: %w", err)
-	}
-	return string(output), nil
+	return sessionBackend.Capture(sessionName, joinWrapped)
 }
 
 // batchCaptureActiveSessions captures only recently-polled sidecar sessions (td-018f25).
@@ -1221,9 +1152,9 @@ func tailUTF8Safe(s string, n int) string {
 }
 
 // detectStatus determines agent status from captured output.
-// This is the tmux-based fallback for agents without session file support (td-2fca7d).
-// For supported agents (Claude, Codex, Gemini, OpenCode), session file analysis runs
-// first in handlePollAgent and is more reliable than tmux pattern matching.
+// This is the last-resort fallback for agents without hooks or session file
+// support (td-2fca7d). Hook events and session file analysis run first in
+// handlePollAgent and are far more reliable than tmux pattern matching.
 func detectStatus(output string) WorktreeStatus {
 	// Check tail of output for status patterns (avoids splitting entire string)
 	checkText := tailUTF8Safe(output, statusCheckBytes)
@@ -1373,14 +1304,19 @@ func extractPrompt(output string) string {
 
 // Approve sends "y" to approve a pending prompt.
 func (p *Plugin) Approve(wt *Worktree) tea.Cmd {
+	projectRoot := p.ctx.ProjectRoot
+	var req approvalRequest
+	if wt.Agent != nil {
+		req = waitingApprovalRequest(wt)
+	}
 	return func() tea.Msg {
 		if wt.Agent == nil {
 			return ApproveResultMsg{WorkspaceName: wt.Name, Err: fmt.Errorf("no agent running")}
 		}
 
 		// Send "y" followed by Enter
-		cmd := exec.Command("tmux", "send-keys", "-t", wt.Agent.TmuxSession, "y", "Enter")
-		err := cmd.Run()
+		err := sendSessionLine(wt.Agent.TmuxSession, "y")
+		auditUserAnswer(projectRoot, wt.Name, req, approvalAllow, err)
 
 		return ApproveResultMsg{
 			WorkspaceName: wt.Name,
@@ -1391,13 +1327,18 @@ func (p *Plugin) Approve(wt *Worktree) tea.Cmd {
 
 // Reject sends "n" to reject a pending prompt.
 func (p *Plugin) Reject(wt *Worktree) tea.Cmd {
+	projectRoot := p.ctx.ProjectRoot
+	var req approvalRequest
+	if wt.Agent != nil {
+		req = waitingApprovalRequest(wt)
+	}
 	return func() tea.Msg {
 		if wt.Agent == nil {
 			return RejectResultMsg{WorkspaceName: wt.Name, Err: fmt.Errorf("no agent running")}
 		}
 
-		cmd := exec.Command("tmux", "send-keys", "-t", wt.Agent.TmuxSession, "n", "Enter")
-		err := cmd.Run()
+		err := sendSessionLine(wt.Agent.TmuxSession, "n")
+		auditUserAnswer(projectRoot, wt.Name, req, approvalDeny, err)
 
 		return RejectResultMsg{
 			WorkspaceName: wt.Name,
@@ -1406,11 +1347,13 @@ func (p *Plugin) Reject(wt *Worktree) tea.Cmd {
 	}
 }
 
-// ApproveAll approves all worktrees with pending prompts.
+// ApproveAll approves all worktrees with pending prompts, except prompts
+// the approval policy denies.
 func (p *Plugin) ApproveAll() tea.Cmd {
 	var cmds []tea.Cmd
+	rules := loadApprovalRules(p.approvalConfigFile(), p.ctx.ProjectRoot)
 	for _, wt := range p.worktrees {
-		if wt.Status == StatusWaiting && wt.Agent != nil {
+		if wt.Status == StatusWaiting && wt.Agent != nil && !deniedByPolicy(wt, rules) {
 			cmds = append(cmds, p.Approve(wt))
 		}
 	}
@@ -1429,15 +1372,7 @@ func (p *Plugin) SendText(wt *Worktree, text string) tea.Cmd {
 			return SendTextResultMsg{Err: fmt.Errorf("no agent running")}
 		}
 
-		// Use -l to send literal text (no key name lookup)
-		cmd := exec.Command("tmux", "send-keys", "-l", "-t", wt.Agent.TmuxSession, text)
-		if err := cmd.Run(); err != nil {
-			return SendTextResultMsg{Err: err}
-		}
-
-		// Send Enter separately
-		cmd = exec.Command("tmux", "send-keys", "-t", wt.Agent.TmuxSession, "Enter")
-		err := cmd.Run()
+		err := sendTmuxText(wt.Agent.TmuxSession, text)
 
 		return SendTextResultMsg{
 			WorkspaceName: wt.Name,
@@ -1475,7 +1410,7 @@ func (p *Plugin) StopAgent(wt *Worktree) tea.Cmd {
 		sessionName := wt.Agent.TmuxSession
 
 		// Try graceful interrupt first (Ctrl+C)
-		_ = exec.Command("tmux", "send-keys", "-t", sessionName, "C-c").Run()
+		_ = sessionBackend.SendKey(sessionName, "C-c")
 
 		// Wait briefly for graceful shutdown
 		time.Sleep(2 * time.Second)
@@ -1483,17 +1418,16 @@ func (p *Plugin) StopAgent(wt *Worktree) tea.Cmd {
 		// Check if still running
 		if sessionExists(sessionName) {
 			// Force kill
-			_ = exec.Command("tmux", "kill-session", "-t", sessionName).Run()
+			_ = sessionBackend.Kill(sessionName)
 		}
 
 		return AgentStoppedMsg{WorkspaceName: wt.Name}
 	}
 }
 
-// sessionExists checks if a tmux session exists.
+// sessionExists checks if a session exists.
 func sessionExists(name string) bool {
-	cmd := exec.Command("tmux", "has-session", "-t", name)
-	return cmd.Run() == nil
+	return sessionBackend.Exists(name)
 }
 
 // detectOrphanedWorktrees marks worktrees as orphaned if they have a saved
@@ -1529,23 +1463,15 @@ func (p *Plugin) detectOrphanedWorktrees() {
 // reconnectAgents finds and reconnects to existing tmux sessions on startup.
 func (p *Plugin) reconnectAgents() tea.Cmd {
 	return func() tea.Msg {
-		// Find existing sidecar-ws-* tmux sessions
-		cmd := exec.Command("tmux", "list-sessions", "-F", "#{session_name}")
-		output, err := cmd.Output()
+		// Find existing sidecar-ws-* sessions
+		sessions, err := sessionBackend.List()
 		if err != nil {
 			// No tmux server running, that's fine
 			return reconnectedAgentsMsg{Cmds: nil}
 		}
 
 		var pollingCmds []tea.Cmd
-		sessions := strings.Split(string(output), "\n")
-
 		for _, session := range sessions {
-			session = strings.TrimSpace(session)
-			if session == "" {
-				continue
-			}
-
 			// Only reconnect to sessions with our prefix
 			if !strings.HasPrefix(session, tmuxSessionPrefix) {
 				continue
@@ -1580,6 +1506,7 @@ func (p *Plugin) reconnectAgents() tea.Cmd {
 
 			// Schedule polling via tea.Cmd
 			pollingCmds = append(pollingCmds, p.scheduleAgentPoll(wt.Name, 0))
+			pollingCmds = append(pollingCmds, p.recordSession(session, wt.Name, "agent", wt.Path))
 		}
 
 		return reconnectedAgentsMsg{Cmds: pollingCmds}
@@ -1592,7 +1519,7 @@ func (p *Plugin) Cleanup(removeSessions bool) error {
 		if removeSessions {
 			// Only kill sessions we created
 			if p.managedSessions[agent.TmuxSession] {
-				_ = exec.Command("tmux", "kill-session", "-t", agent.TmuxSession).Run()
+				_ = sessionBackend.Kill(agent.TmuxSession)
 				delete(p.managedSessions, agent.TmuxSession)
 				globalPaneCache.remove(agent.TmuxSession)
 				globalActiveRegistry.remove(agent.TmuxSession) // td-018f25
@@ -1605,18 +1532,12 @@ func (p *Plugin) Cleanup(removeSessions bool) error {
 
 // CleanupOrphanedSessions removes sessions that no longer have worktrees.
 func (p *Plugin) CleanupOrphanedSessions() error {
-	cmd := exec.Command("tmux", "list-sessions", "-F", "#{session_name}")
-	output, err := cmd.Output()
+	sessions, err := sessionBackend.List()
 	if err != nil {
 		return nil // No tmux server
 	}
 
-	for _, session := range strings.Split(string(output), "\n") {
-		session = strings.TrimSpace(session)
-		if session == "" {
-			continue
-		}
-
+	for _, session := range sessions {
 		// Only cleanup sessions we explicitly created and tracked
 		if !p.managedSessions[session] {
 			continue
@@ -1626,7 +1547,7 @@ func (p *Plugin) CleanupOrphanedSessions() error {
 		// Use sanitized name lookup since session names are created with sanitizeName()
 		sanitizedName := strings.TrimPrefix(session, tmuxSessionPrefix)
 		if p.findWorktreeBySanitizedName(sanitizedName) == nil {
-			_ = exec.Command("tmux", "kill-session", "-t", session).Run()
+			_ = sessionBackend.Kill(session)
 			delete(p.managedSessions, session)
 			globalPaneCache.remove(session)
 			globalActiveRegistry.remove(session) // td-018f25
@@ -1641,20 +1562,16 @@ func (p *Plugin) validateManagedSessions() tea.Cmd {
 	return func() tea.Msg {
 		existing := make(map[string]bool)
 
-		// List all tmux sessions
-		cmd := exec.Command("tmux", "list-sessions", "-F", "#{session_name}")
-		output, err := cmd.Output()
+		// List all sessions
+		sessions, err := sessionBackend.List()
 		if err != nil {
 			// No tmux server, all sessions are gone
 			return validateManagedSessionsResultMsg{ExistingSessions: existing}
 		}
 
 		// Build set of existing sessions
-		for _, session := range strings.Split(string(output), "\n") {
-			session = strings.TrimSpace(session)
-			if session != "" {
-				existing[session] = true
-			}
+		for _, session := range sessions {
+			existing[session] = true
 		}
 
 		return validateManagedSessionsResultMsg{ExistingSessions: existing}
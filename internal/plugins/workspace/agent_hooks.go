@@ -0,0 +1,172 @@
+package workspace
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/marcus/sidecar/internal/agentstatus"
+	"github.com/marcus/sidecar/internal/projectdir"
+)
+
+// claudeHookSettingsFile holds the hook settings passed via `claude --settings`.
+const claudeHookSettingsFile = "claude-hooks.json"
+
+// hookGrace tolerates session writes that land just after the hook event
+// they belong to.
+const hookGrace = 2 * time.Second
+
+// agentStatusPaths caches status file paths; resolving the worktree state
+// directory scans the projects directory.
+var agentStatusPaths sync.Map // projectRoot + "\x00" + worktreePath -> string
+
+// agentStatusFile returns the hook status file for a worktree.
+func agentStatusFile(projectRoot, worktreePath string) (string, error) {
+	key := projectRoot + "\x00" + worktreePath
+	if v, ok := agentStatusPaths.Load(key); ok {
+		return v.(string), nil
+	}
+	dir, err := projectdir.WorktreeDir(projectRoot, worktreePath)
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, agentstatus.FileName)
+	agentStatusPaths.Store(key, path)
+	return path, nil
+}
+
+// agentHooksEnabled reports whether status hooks should be registered.
+func (p *Plugin) agentHooksEnabled() bool {
+	return p != nil && p.ctx != nil && p.ctx.Config != nil && p.ctx.Config.Plugins.Workspace.AgentHooks
+}
+
+// withAgentHooks adds the flags that register sidecar's status hooks to an
+// agent's base command. Only plain `claude`/`codex` invocations are changed;
+// wrapper scripts may not accept the flags. The previous status file is
+// removed so a stale event cannot outlive the agent it came from.
+func (p *Plugin) withAgentHooks(agentType AgentType, worktreePath, baseCmd string) string {
+	if !p.agentHooksEnabled() || worktreePath == "" {
+		return baseCmd
+	}
+	fields := strings.Fields(baseCmd)
+	if len(fields) == 0 || filepath.Base(fields[0]) != string(agentType) {
+		return baseCmd
+	}
+	if agentType != AgentClaude && agentType != AgentCodex {
+		return baseCmd
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		return baseCmd
+	}
+	statusFile, err := agentStatusFile(p.ctx.ProjectRoot, worktreePath)
+	if err != nil {
+		slog.Debug("agent hooks: resolve status file", "worktree", worktreePath, "err", err)
+		return baseCmd
+	}
+	_ = os.Remove(statusFile)
+
+	switch agentType {
+	case AgentClaude:
+		hookCmd := strings.Join([]string{shellQuote(exe), "agent-hook", "-agent", "claude", "-file", shellQuote(statusFile)}, " ")
+		settings, err := agentstatus.ClaudeSettings(hookCmd)
+		if err != nil {
+			return baseCmd
+		}
+		settingsPath := filepath.Join(filepath.Dir(statusFile), claudeHookSettingsFile)
+		if err := os.WriteFile(settingsPath, settings, 0644); err != nil {
+			slog.Debug("agent hooks: write claude settings", "path", settingsPath, "err", err)
+			return baseCmd
+		}
+		return baseCmd + " --settings " + shellQuote(settingsPath)
+	default: // AgentCodex
+		notify := agentstatus.CodexNotifyOverride([]string{exe, "agent-hook", "-agent", "codex", "-file", statusFile})
+		return baseCmd + " -c " + shellQuote(notify)
+	}
+}
+
+// detectHookStatus returns the status last reported by the agent's hooks,
+// and for a permission prompt its message and the tool call it is about.
+// Codex only reports turn completion, so its event is ignored once the
+// session file has been written again (the user sent another prompt).
+// Claude fires no hook when the user interrupts a turn, so an active event
+// is ignored once the session file shows the turn ended after it.
+func detectHookStatus(agentType AgentType, statusFile, worktreePath string) (WorktreeStatus, string, toolCall, bool) {
+	if statusFile == "" {
+		return 0, "", toolCall{}, false
+	}
+	ev, err := agentstatus.Read(statusFile)
+	if err != nil {
+		return 0, "", toolCall{}, false
+	}
+	if agentType == AgentCodex {
+		if mod, ok := codexSessionModTime(worktreePath); ok && mod.After(ev.Time.Add(hookGrace)) {
+			return 0, "", toolCall{}, false
+		}
+	}
+	if agentType == AgentClaude && ev.Status == agentstatus.StatusActive && claudeTurnEndedAfter(worktreePath, ev.Time.Add(hookGrace)) {
+		return 0, "", toolCall{}, false
+	}
+	switch ev.Status {
+	case agentstatus.StatusActive:
+		return StatusActive, "", toolCall{}, true
+	case agentstatus.StatusWaiting:
+		msg := ev.Message
+		if msg == "" {
+			msg = "Waiting for input"
+		}
+		return StatusWaiting, msg, toolCall{Tool: ev.Tool, Target: ev.Target}, true
+	case agentstatus.StatusDone:
+		return StatusDone, "", toolCall{}, true
+	}
+	return 0, "", toolCall{}, false
+}
+
+// codexSessionModTime returns the mtime of the Codex session for a worktree.
+func codexSessionModTime(worktreePath string) (time.Time, bool) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return time.Time{}, false
+	}
+	absPath, err := filepath.Abs(worktreePath)
+	if err != nil {
+		return time.Time{}, false
+	}
+	sessionFile, err := findCodexSessionForPath(filepath.Join(home, ".codex", "sessions"), absPath)
+	if err != nil || sessionFile == "" {
+		return time.Time{}, false
+	}
+	info, err := os.Stat(sessionFile)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return info.ModTime(), true
+}
+
+// claudeTurnEndedAfter reports whether the newest Claude session for a
+// worktree was last written after t and ends with a finished turn.
+func claudeTurnEndedAfter(worktreePath string, t time.Time) bool {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return false
+	}
+	absPath, err := filepath.Abs(worktreePath)
+	if err != nil {
+		return false
+	}
+	projectDir := filepath.Join(home, ".claude", "projects", claudeProjectDirName(absPath))
+	sessionFiles, err := findRecentJSONLFiles(projectDir, "agent-")
+	if err != nil || len(sessionFiles) == 0 {
+		return false
+	}
+	info, err := os.Stat(sessionFiles[0])
+	if err != nil || !info.ModTime().After(t) {
+		return false
+	}
+	status, ok := getClaudeSessionStatus(sessionFiles[0])
+	return ok && status == StatusDone
+}
@@ -0,0 +1,119 @@
+package workspace
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/marcus/sidecar/internal/agentstatus"
+	"github.com/marcus/sidecar/internal/config"
+	"github.com/marcus/sidecar/internal/plugin"
+)
+
+func TestWithAgentHooks(t *testing.T) {
+	config.SetTestStateDir(t.TempDir())
+	defer config.ResetTestStateDir()
+
+	projectRoot, wtPath := t.TempDir(), t.TempDir()
+	cfg := config.Default()
+	p := &Plugin{ctx: &plugin.Context{ProjectRoot: projectRoot, Config: cfg}}
+	if got := p.withAgentHooks(AgentCodex, wtPath, "codex"); got != "codex" {
+		t.Errorf("hooks are opt-in, got %q", got)
+	}
+	cfg.Plugins.Workspace.AgentHooks = true
+
+	statusFile, err := agentStatusFile(projectRoot, wtPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := agentstatus.Write(statusFile, agentstatus.Event{Status: agentstatus.StatusDone}); err != nil {
+		t.Fatal(err)
+	}
+
+	got := p.withAgentHooks(AgentClaude, wtPath, "claude")
+	settingsPath := filepath.Join(filepath.Dir(statusFile), claudeHookSettingsFile)
+	if got != "claude --settings "+shellQuote(settingsPath) {
+		t.Errorf("claude command = %q", got)
+	}
+	settings, err := os.ReadFile(settingsPath)
+	if err != nil || !strings.Contains(string(settings), "agent-hook -agent claude -file") {
+		t.Errorf("claude hook settings not written: %s (%v)", settings, err)
+	}
+	if _, err := os.Stat(statusFile); !os.IsNotExist(err) {
+		t.Error("stale status file should be removed on agent start")
+	}
+
+	if got := p.withAgentHooks(AgentCodex, wtPath, "codex"); !strings.HasPrefix(got, "codex -c 'notify=[") {
+		t.Errorf("codex command = %q", got)
+	}
+
+	// Wrapper commands and other agents are left alone
+	for _, tc := range []struct {
+		agent AgentType
+		cmd   string
+	}{
+		{AgentClaude, "my-claude-wrapper"},
+		{AgentGemini, "gemini"},
+	} {
+		if got := p.withAgentHooks(tc.agent, wtPath, tc.cmd); got != tc.cmd {
+			t.Errorf("withAgentHooks(%s, %q) = %q, want unchanged", tc.agent, tc.cmd, got)
+		}
+	}
+
+	cfg.Plugins.Workspace.AgentHooks = false
+	if got := p.withAgentHooks(AgentClaude, wtPath, "claude"); got != "claude" {
+		t.Errorf("hooks disabled: got %q", got)
+	}
+}
+
+func TestDetectHookStatus(t *testing.T) {
+	path := filepath.Join(t.TempDir(), agentstatus.FileName)
+	if _, _, _, ok := detectHookStatus(AgentClaude, path, t.TempDir()); ok {
+		t.Error("expected no status without a status file")
+	}
+
+	ev := agentstatus.Event{Status: agentstatus.StatusWaiting, Message: "Allow Bash?", Tool: "Bash", Target: "make", Time: time.Now()}
+	if err := agentstatus.Write(path, ev); err != nil {
+		t.Fatal(err)
+	}
+	status, waitingFor, call, ok := detectHookStatus(AgentClaude, path, t.TempDir())
+	if !ok || status != StatusWaiting || waitingFor != "Allow Bash?" || call != (toolCall{Tool: "Bash", Target: "make"}) {
+		t.Errorf("detectHookStatus() = %v, %q, %+v, %v", status, waitingFor, call, ok)
+	}
+
+	ev = agentstatus.Event{Status: agentstatus.StatusDone, Time: time.Now()}
+	if err := agentstatus.Write(path, ev); err != nil {
+		t.Fatal(err)
+	}
+	if status, _, _, ok := detectHookStatus(AgentClaude, path, t.TempDir()); !ok || status != StatusDone {
+		t.Errorf("detectHookStatus() = %v, %v, want done", status, ok)
+	}
+}
+
+func TestDetectHookStatusInterruptedClaude(t *testing.T) {
+	worktreePath := "/test/project/path"
+	_, projectDir := setupClaudeTestDir(t, worktreePath)
+	path := filepath.Join(t.TempDir(), agentstatus.FileName)
+
+	ev := agentstatus.Event{Status: agentstatus.StatusActive, Hook: "PreToolUse", Time: time.Now().Add(-time.Minute)}
+	if err := agentstatus.Write(path, ev); err != nil {
+		t.Fatal(err)
+	}
+	writeSessionFile(t, projectDir, "s.jsonl",
+		`{"type":"user","message":{"role":"user","content":"run the tests"}}`, 30*time.Second)
+	if status, _, _, ok := detectHookStatus(AgentClaude, path, worktreePath); !ok || status != StatusActive {
+		t.Errorf("detectHookStatus() = %v, %v, want active before the session moves on", status, ok)
+	}
+
+	// Esc writes an interruption marker but fires no Stop hook
+	writeSessionFile(t, projectDir, "s.jsonl",
+		`{"type":"user","message":{"role":"user","content":[{"type":"text","text":"[Request interrupted by user]"}]}}`, 10*time.Second)
+	if _, _, _, ok := detectHookStatus(AgentClaude, path, worktreePath); ok {
+		t.Error("active hook event should be stale once the session shows the turn ended")
+	}
+	if status, ok := detectClaudeSessionStatus(worktreePath); !ok || status != StatusDone {
+		t.Errorf("detectClaudeSessionStatus() = %v, %v, want done after interrupt", status, ok)
+	}
+}
@@ -881,6 +881,9 @@ func getClaudeSessionStatus(path string) (WorktreeStatus, bool) {
 		}
 		switch msgType {
 		case "user":
+			if isInterruptedEntry(msg) {
+				return StatusDone, true
+			}
 			return StatusActive, true
 		case "assistant":
 			if isPlaceholderAssistant(msg) {
@@ -895,6 +898,34 @@ func getClaudeSessionStatus(path string) (WorktreeStatus, bool) {
 	return 0, false
 }
 
+// claudeInterruptedPrefix starts the user entry Claude Code writes when the
+// user interrupts a turn with Esc; no assistant entry follows it.
+const claudeInterruptedPrefix = "[Request interrupted by user"
+
+// isInterruptedEntry checks if a user JSONL entry is Claude Code's
+// interruption marker rather than a prompt.
+func isInterruptedEntry(entry map[string]any) bool {
+	message, ok := entry["message"].(map[string]any)
+	if !ok {
+		return false
+	}
+	switch content := message["content"].(type) {
+	case string:
+		return strings.HasPrefix(content, claudeInterruptedPrefix)
+	case []any:
+		for _, block := range content {
+			b, ok := block.(map[string]any)
+			if !ok {
+				continue
+			}
+			if text, _ := b["text"].(string); strings.HasPrefix(text, claudeInterruptedPrefix) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // isPlaceholderAssistant checks if an assistant JSONL entry is a placeholder
 // written when the API stream opens. Claude Code writes these with content
 // containing only whitespace text blocks before thinking/generation completes.
@@ -0,0 +1,464 @@
+package workspace
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/marcus/sidecar/internal/config"
+	appmsg "github.com/marcus/sidecar/internal/msg"
+	"github.com/marcus/sidecar/internal/plugin"
+	"github.com/marcus/sidecar/internal/projectdir"
+)
+
+// approvalAuditFile is the audit log of permission decisions, one JSON
+// object per line, in the project's data directory.
+const approvalAuditFile = "approvals.log"
+
+// approvalGrace is how long an auto-answered prompt is remembered after the
+// agent stops showing it, so a slow redraw isn't answered twice.
+const approvalGrace = 3 * time.Second
+
+// Approval actions and who made the decision.
+const (
+	approvalAllow = "allow"
+	approvalDeny  = "deny"
+
+	approvalByPolicy = "policy"
+	approvalByUser   = "user"
+)
+
+// Normalized tool names for rules and parsed prompts.
+const (
+	approvalToolBash = "bash"
+	approvalToolEdit = "edit"
+)
+
+// ApprovalPolicy is the "approvals" section of a config file. Rules are
+// either Tool(pattern), e.g. Bash(go test *) or Edit(internal/*), or a bare
+// pattern matched against the whole prompt. * matches any text.
+type ApprovalPolicy struct {
+	Allow []string `json:"allow"`
+	Deny  []string `json:"deny"`
+}
+
+// configWithApprovals is the config structure for loading approval rules.
+type configWithApprovals struct {
+	Approvals ApprovalPolicy `json:"approvals"`
+}
+
+// approvalRule is one parsed policy rule.
+type approvalRule struct {
+	Action  string
+	Tool    string // Normalized tool, or "" to match the whole prompt
+	Pattern *regexp.Regexp
+	Raw     string
+	Source  string // "global" or "project"
+}
+
+// approvalRules is the merged global and project policy.
+type approvalRules []approvalRule
+
+// toolCall is the tool and command or path a permission prompt is about,
+// as reported by the agent's hooks rather than parsed from prompt text.
+type toolCall struct {
+	Tool   string
+	Target string
+}
+
+// approvalRequest is a permission prompt parsed from Agent.WaitingFor.
+type approvalRequest struct {
+	Prompt string
+	Tool   string // Normalized tool, or "" if unrecognized
+	Target string // Command or path the prompt asks about
+}
+
+// autoAnswer remembers the last prompt sidecar evaluated for a worktree.
+type autoAnswer struct {
+	Prompt string
+	Target string // Hook prompts repeat their text, so the call tells them apart
+	At     time.Time
+}
+
+// approvalAuditEntry is one line of the audit log.
+type approvalAuditEntry struct {
+	Time      time.Time `json:"time"`
+	Workspace string    `json:"workspace"`
+	Prompt    string    `json:"prompt"`
+	Tool      string    `json:"tool,omitempty"`
+	Target    string    `json:"target,omitempty"`
+	Decision  string    `json:"decision"`
+	By        string    `json:"by"`
+	Rule      string    `json:"rule,omitempty"`
+	Source    string    `json:"source,omitempty"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// ApprovalAnsweredMsg reports a prompt answered by the approval policy.
+type ApprovalAnsweredMsg struct {
+	Epoch         uint64
+	WorkspaceName string
+	Decision      string
+	Target        string
+	Rule          string
+	Err           error
+}
+
+// GetEpoch implements plugin.EpochMessage.
+func (m ApprovalAnsweredMsg) GetEpoch() uint64 { return m.Epoch }
+
+// loadApprovalRules loads and merges approval rules from the global config
+// file and the project's config directory. Both apply; deny rules win over
+// allow rules.
+func loadApprovalRules(globalConfigFile, projectDir string) approvalRules {
+	rules := loadApprovalRulesFromFile(globalConfigFile, "global")
+	if projectConfigDir, err := projectdir.Resolve(projectDir); err == nil {
+		rules = append(rules, loadApprovalRulesFromFile(filepath.Join(projectConfigDir, "config.json"), "project")...)
+	}
+	return rules
+}
+
+// loadApprovalRulesFromFile loads rules from a config file.
+func loadApprovalRulesFromFile(path, source string) approvalRules {
+	if path == "" {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var cfg configWithApprovals
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil
+	}
+	var rules approvalRules
+	for _, raw := range cfg.Approvals.Deny {
+		rules = append(rules, parseApprovalRule(approvalDeny, raw, source))
+	}
+	for _, raw := range cfg.Approvals.Allow {
+		rules = append(rules, parseApprovalRule(approvalAllow, raw, source))
+	}
+	return rules
+}
+
+// approvalRulePattern matches Tool(pattern) rules.
+var approvalRulePattern = regexp.MustCompile(`^(\w+)\((.*)\)$`)
+
+// parseApprovalRule parses a Tool(pattern) or bare pattern rule.
+func parseApprovalRule(action, raw, source string) approvalRule {
+	rule := approvalRule{Action: action, Raw: raw, Source: source}
+	pattern := strings.TrimSpace(raw)
+	if m := approvalRulePattern.FindStringSubmatch(pattern); m != nil {
+		rule.Tool = normalizeApprovalTool(m[1])
+		pattern = strings.TrimSpace(m[2])
+		if rule.Tool == approvalToolEdit {
+			pattern = strings.TrimPrefix(pattern, "./")
+		}
+	}
+	rule.Pattern = globPattern(pattern)
+	return rule
+}
+
+// globPattern compiles a pattern where * matches any text, including spaces
+// and slashes. Everything else is literal.
+func globPattern(pattern string) *regexp.Regexp {
+	parts := strings.Split(pattern, "*")
+	for i, part := range parts {
+		parts[i] = regexp.QuoteMeta(part)
+	}
+	return regexp.MustCompile(`(?s)^` + strings.Join(parts, ".*") + `$`)
+}
+
+// normalizeApprovalTool maps the tool names agents use onto bash and edit.
+func normalizeApprovalTool(tool string) string {
+	switch t := strings.ToLower(tool); t {
+	case "bash", "shell", "command", "exec", "run":
+		return approvalToolBash
+	case "edit", "edits", "write", "multiedit", "notebookedit", "patch", "file":
+		return approvalToolEdit
+	default:
+		return t
+	}
+}
+
+// Prompt shapes agents use to ask for permission.
+var (
+	// Bash(go test ./...), Allow Edit(internal/x.go)?
+	approvalCallPattern = regexp.MustCompile(`(?i)\b(\w+)\((.+)\)`)
+	// Allow bash command: go test ./..., Allow edit to internal/x.go?
+	approvalAskPattern = regexp.MustCompile(`(?i)\b(?:allow|run|approve)\s+(bash|shell|command|edits?|write|writes)\b(?:\s+(?:command|to|file|in))?\s*:?\s*(.+)$`)
+	// Claude needs your permission to use Bash
+	approvalUsePattern = regexp.MustCompile(`(?i)permission to use (\w+)`)
+)
+
+// waitingApprovalRequest parses the prompt a worktree's agent is waiting on.
+// A tool call reported by hooks replaces whatever the prompt text names:
+// Claude's permission notification only says which tool it wants to use.
+func waitingApprovalRequest(wt *Worktree) approvalRequest {
+	req := parseApprovalRequest(wt.Agent.WaitingFor, wt.Path)
+	if call := wt.Agent.WaitingTool; call.Tool != "" {
+		req.Tool = normalizeApprovalTool(call.Tool)
+		req.Target = strings.TrimSpace(call.Target)
+		if req.Tool == approvalToolEdit && req.Target != "" {
+			req.Target = relativeApprovalPath(req.Target, wt.Path)
+		}
+	}
+	return req
+}
+
+// parseApprovalRequest extracts the tool and its command or path from a
+// waiting prompt. Paths inside worktreePath are made relative to it.
+func parseApprovalRequest(prompt, worktreePath string) approvalRequest {
+	req := approvalRequest{Prompt: strings.TrimSpace(prompt)}
+	text := stripPromptSuffix(req.Prompt)
+	if m := approvalCallPattern.FindStringSubmatch(text); m != nil {
+		req.Tool, req.Target = normalizeApprovalTool(m[1]), m[2]
+	} else if m := approvalAskPattern.FindStringSubmatch(text); m != nil {
+		req.Tool, req.Target = normalizeApprovalTool(m[1]), m[2]
+	} else if m := approvalUsePattern.FindStringSubmatch(text); m != nil {
+		req.Tool = normalizeApprovalTool(m[1])
+	}
+	req.Target = strings.Trim(strings.TrimSpace(req.Target), "`'\"")
+	if req.Tool == approvalToolEdit && req.Target != "" {
+		req.Target = relativeApprovalPath(req.Target, worktreePath)
+	}
+	return req
+}
+
+// stripPromptSuffix removes the answer hints and punctuation that end a prompt.
+func stripPromptSuffix(s string) string {
+	for {
+		trimmed := strings.TrimSpace(s)
+		lower := strings.ToLower(trimmed)
+		for _, suffix := range []string{"[y/n]", "(y/n)", "?"} {
+			if strings.HasSuffix(lower, suffix) {
+				trimmed = trimmed[:len(trimmed)-len(suffix)]
+				break
+			}
+		}
+		if trimmed == strings.TrimSpace(s) {
+			return trimmed
+		}
+		s = trimmed
+	}
+}
+
+// relativeApprovalPath cleans a path and makes it relative to the worktree,
+// so Edit(internal/*) can't be escaped with ../ or an absolute path.
+func relativeApprovalPath(path, worktreePath string) string {
+	if filepath.IsAbs(path) && worktreePath != "" {
+		if rel, err := filepath.Rel(worktreePath, path); err == nil {
+			path = rel
+		}
+	}
+	return filepath.ToSlash(filepath.Clean(path))
+}
+
+// shellOperators splits a command line into the commands it runs.
+var shellOperators = regexp.MustCompile("&&|\\|\\||[;|&\n`<>]|\\$\\(")
+
+// decide returns the rule that answers req. Deny rules win. A bash allow
+// rule only matches a chained or redirected command if its own pattern
+// chains or redirects too; a deny rule matches any command in the chain.
+func (rules approvalRules) decide(req approvalRequest) (approvalRule, bool) {
+	for _, action := range []string{approvalDeny, approvalAllow} {
+		for _, rule := range rules {
+			if rule.Action == action && rule.matches(req) {
+				return rule, true
+			}
+		}
+	}
+	return approvalRule{}, false
+}
+
+// matches reports whether the rule applies to req. A tool rule never allows
+// a prompt whose command or path is unknown, since a deny rule for that
+// tool could not have been checked against it. A bare pattern allowing a
+// bash prompt is held to the same limits as a Bash rule.
+func (r approvalRule) matches(req approvalRequest) bool {
+	if r.Tool == "" {
+		if r.Action == approvalAllow && req.Tool == approvalToolBash &&
+			(req.Target == "" || (shellOperators.MatchString(req.Target) && !shellOperators.MatchString(r.Raw))) {
+			return false
+		}
+		return r.Pattern.MatchString(req.Prompt)
+	}
+	if r.Tool != req.Tool || (req.Target == "" && r.Action == approvalAllow) {
+		return false
+	}
+	if r.Tool != approvalToolBash || !shellOperators.MatchString(req.Target) {
+		return r.Pattern.MatchString(req.Target)
+	}
+	if r.Action == approvalAllow {
+		return shellOperators.MatchString(r.Raw) && r.Pattern.MatchString(req.Target)
+	}
+	if r.Pattern.MatchString(req.Target) {
+		return true
+	}
+	for _, part := range shellOperators.Split(req.Target, -1) {
+		if r.Pattern.MatchString(strings.TrimSpace(part)) {
+			return true
+		}
+	}
+	return false
+}
+
+// approvalConfigFile is the global config file holding approval rules: the
+// file sidecar was started with, which -config may have moved.
+func (p *Plugin) approvalConfigFile() string {
+	if p.ctx.Config != nil {
+		return p.ctx.Config.Path()
+	}
+	return config.ConfigPath()
+}
+
+// maybeAutoAnswer answers a worktree's waiting prompt when a policy rule
+// matches it. Each prompt is evaluated once; prompts no rule matches are
+// left for the user.
+func (p *Plugin) maybeAutoAnswer(wt *Worktree) tea.Cmd {
+	if wt == nil || wt.Agent == nil || wt.Agent.TmuxSession == "" {
+		return nil
+	}
+	last, seen := p.autoAnswered[wt.Name]
+	prompt := wt.Agent.WaitingFor
+	if wt.Status != StatusWaiting || prompt == "" {
+		if seen && time.Since(last.At) >= approvalGrace {
+			delete(p.autoAnswered, wt.Name)
+		}
+		return nil
+	}
+	req := waitingApprovalRequest(wt)
+	if seen && last.Prompt == prompt && last.Target == req.Target {
+		return nil
+	}
+	if p.autoAnswered == nil {
+		p.autoAnswered = make(map[string]autoAnswer)
+	}
+	p.autoAnswered[wt.Name] = autoAnswer{Prompt: prompt, Target: req.Target, At: time.Now()}
+
+	rule, ok := loadApprovalRules(p.approvalConfigFile(), p.ctx.ProjectRoot).decide(req)
+	if !ok {
+		return nil
+	}
+
+	key := "y"
+	if rule.Action == approvalDeny {
+		key = "n"
+	}
+	epoch := p.ctx.Epoch
+	projectRoot := p.ctx.ProjectRoot
+	name, session := wt.Name, wt.Agent.TmuxSession
+	return func() tea.Msg {
+		err := sendSessionLine(session, key)
+		writeApprovalAudit(projectRoot, approvalAuditEntry{
+			Workspace: name,
+			Prompt:    req.Prompt,
+			Tool:      req.Tool,
+			Target:    req.Target,
+			Decision:  rule.Action,
+			By:        approvalByPolicy,
+			Rule:      rule.Raw,
+			Source:    rule.Source,
+			Error:     errString(err),
+		})
+		return ApprovalAnsweredMsg{
+			Epoch:         epoch,
+			WorkspaceName: name,
+			Decision:      rule.Action,
+			Target:        req.Target,
+			Rule:          rule.Raw,
+			Err:           err,
+		}
+	}
+}
+
+// handleApprovalAnswered clears the answered prompt and reports the decision.
+func (p *Plugin) handleApprovalAnswered(msg ApprovalAnsweredMsg) tea.Cmd {
+	if plugin.IsStale(p.ctx, msg) {
+		return nil
+	}
+	if msg.Err != nil {
+		// Let the next poll try again
+		delete(p.autoAnswered, msg.WorkspaceName)
+		return appmsg.ShowToast(fmt.Sprintf("Auto-%s failed for %s: %v", msg.Decision, msg.WorkspaceName, msg.Err), 3*time.Second)
+	}
+	if wt := p.findWorktree(msg.WorkspaceName); wt != nil && wt.Agent != nil {
+		wt.Agent.WaitingFor = ""
+		wt.Agent.WaitingTool = toolCall{}
+		wt.Status = StatusActive
+	}
+	verb := "Auto-approved"
+	if msg.Decision == approvalDeny {
+		verb = "Auto-rejected"
+	}
+	what := msg.Target
+	if what == "" {
+		what = "prompt"
+	}
+	return tea.Batch(
+		p.scheduleAgentPoll(msg.WorkspaceName, 0),
+		appmsg.ShowToast(fmt.Sprintf("%s %s in %s (%s)", verb, truncateString(what, 40), msg.WorkspaceName, msg.Rule), 3*time.Second),
+	)
+}
+
+// deniedByPolicy reports whether a worktree's waiting prompt matches a deny rule.
+func deniedByPolicy(wt *Worktree, rules approvalRules) bool {
+	rule, ok := rules.decide(waitingApprovalRequest(wt))
+	return ok && rule.Action == approvalDeny
+}
+
+// auditUserAnswer records a prompt answered with the approve or reject keys.
+func auditUserAnswer(projectRoot, name string, req approvalRequest, decision string, err error) {
+	if req.Prompt == "" {
+		return
+	}
+	writeApprovalAudit(projectRoot, approvalAuditEntry{
+		Workspace: name,
+		Prompt:    req.Prompt,
+		Tool:      req.Tool,
+		Target:    req.Target,
+		Decision:  decision,
+		By:        approvalByUser,
+		Error:     errString(err),
+	})
+}
+
+// writeApprovalAudit appends an entry to the project's audit log. Failures
+// are logged, never shown.
+func writeApprovalAudit(projectRoot string, entry approvalAuditEntry) {
+	if entry.Time.IsZero() {
+		entry.Time = time.Now()
+	}
+	dir, err := projectdir.Resolve(projectRoot)
+	if err != nil {
+		slog.Debug("approval audit: resolve project dir", "err", err)
+		return
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	f, err := os.OpenFile(filepath.Join(dir, approvalAuditFile), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		slog.Debug("approval audit: open", "err", err)
+		return
+	}
+	defer func() { _ = f.Close() }()
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		slog.Debug("approval audit: write", "err", err)
+	}
+}
+
+// errString returns err's message, or "" for nil.
+func errString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
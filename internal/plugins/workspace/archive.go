@@ -0,0 +1,410 @@
+package workspace
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	appmsg "github.com/marcus/sidecar/internal/msg"
+	"github.com/marcus/sidecar/internal/plugin"
+	"github.com/marcus/sidecar/internal/projectdir"
+)
+
+const (
+	// archiveFile stores archived worktrees in the project state directory.
+	archiveFile = "archives.json"
+	// archiveRefPrefix namespaces the hidden refs that keep archived work alive.
+	archiveRefPrefix = "refs/sidecar/archive/"
+)
+
+// ArchivedWorktree records a worktree whose directory was removed by
+// archiving, with everything needed to recreate it.
+type ArchivedWorktree struct {
+	Name       string    `json:"name"`
+	Path       string    `json:"path"`
+	Branch     string    `json:"branch"`
+	BaseBranch string    `json:"baseBranch,omitempty"`
+	AgentType  AgentType `json:"agentType,omitempty"`
+	TaskID     string    `json:"taskId,omitempty"`
+	TaskTitle  string    `json:"taskTitle,omitempty"`
+	Prompt     *Prompt   `json:"prompt,omitempty"`
+	Head       string    `json:"head"`            // Branch commit when archived
+	Ref        string    `json:"ref"`             // Hidden ref holding the archived work
+	Dirty      bool      `json:"dirty,omitempty"` // Ref holds uncommitted changes on top of Head
+	ArchivedAt time.Time `json:"archivedAt"`
+}
+
+// WorktreeArchivedMsg reports the result of archiving a worktree.
+type WorktreeArchivedMsg struct {
+	Epoch   uint64 // Epoch when request was issued (for stale detection)
+	Name    string
+	Archive *ArchivedWorktree
+	Err     error
+}
+
+// GetEpoch implements plugin.EpochMessage.
+func (m WorktreeArchivedMsg) GetEpoch() uint64 { return m.Epoch }
+
+// ArchiveRestoredMsg reports the result of recreating an archived worktree.
+type ArchiveRestoredMsg struct {
+	Epoch    uint64 // Epoch when request was issued (for stale detection)
+	Archive  *ArchivedWorktree
+	Worktree *Worktree
+	Err      error
+}
+
+// GetEpoch implements plugin.EpochMessage.
+func (m ArchiveRestoredMsg) GetEpoch() uint64 { return m.Epoch }
+
+// archiveRef returns the hidden ref used to archive branch.
+func archiveRef(branch string) string {
+	return archiveRefPrefix + branch
+}
+
+// loadArchives reads the project's archived worktrees.
+func loadArchives(projectRoot string) []*ArchivedWorktree {
+	dir, err := projectdir.Resolve(projectRoot)
+	if err != nil {
+		return nil
+	}
+	data, err := os.ReadFile(filepath.Join(dir, archiveFile))
+	if err != nil {
+		return nil
+	}
+	var archives []*ArchivedWorktree
+	if err := json.Unmarshal(data, &archives); err != nil {
+		return nil
+	}
+	return archives
+}
+
+// saveArchives writes the project's archived worktrees.
+func saveArchives(projectRoot string, archives []*ArchivedWorktree) error {
+	dir, err := projectdir.Resolve(projectRoot)
+	if err != nil {
+		return err
+	}
+	if archives == nil {
+		archives = []*ArchivedWorktree{}
+	}
+	data, err := json.MarshalIndent(archives, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, archiveFile), data, 0644)
+}
+
+// persistArchives saves archived worktrees, logging failures.
+func (p *Plugin) persistArchives() {
+	if err := saveArchives(p.ctx.ProjectRoot, p.archives); err != nil {
+		p.ctx.Logger.Warn("failed to save archives", "error", err)
+	}
+}
+
+// selectedArchive returns the archived entry selected in the sidebar.
+// Archived entries follow the worktrees in the sidebar's index space.
+func (p *Plugin) selectedArchive() *ArchivedWorktree {
+	if p.shellSelected {
+		return nil
+	}
+	i := p.selectedIdx - len(p.worktrees)
+	if i < 0 || i >= len(p.archives) {
+		return nil
+	}
+	return p.archives[i]
+}
+
+// sidebarItemCount returns the number of worktree and archived entries.
+func (p *Plugin) sidebarItemCount() int {
+	return len(p.worktrees) + len(p.archives)
+}
+
+// archiveSelected archives the selected worktree: its work is committed to
+// a hidden ref, its settings recorded, and its directory removed.
+func (p *Plugin) archiveSelected() tea.Cmd {
+	wt := p.selectedWorktree()
+	if wt == nil || wt.IsMain || wt.IsMissing || p.archiveBusy[wt.Name] {
+		return nil
+	}
+	if p.archiveBusy == nil {
+		p.archiveBusy = make(map[string]bool)
+	}
+	p.archiveBusy[wt.Name] = true
+
+	archive := &ArchivedWorktree{
+		Name:       wt.Name,
+		Path:       wt.Path,
+		Branch:     wt.Branch,
+		BaseBranch: wt.BaseBranch,
+		AgentType:  wt.ChosenAgentType,
+		TaskID:     wt.TaskID,
+		TaskTitle:  wt.TaskTitle,
+		Prompt:     loadPrompt(p.ctx.ProjectRoot, wt.Path),
+		Ref:        archiveRef(wt.Branch),
+	}
+	if wt.Agent != nil {
+		archive.AgentType = wt.Agent.Type
+	}
+	if archive.Prompt == nil {
+		if g := p.fanOutFor(wt); g != nil {
+			archive.Prompt = fanOutPrompt(g.Prompt)
+		}
+	}
+
+	epoch := p.ctx.Epoch
+	workDir := p.ctx.WorkDir
+	sessionName := tmuxSessionPrefix + sanitizeName(wt.Name)
+	return tea.Batch(
+		appmsg.ShowToast("Archiving "+wt.Name+"...", 2*time.Second),
+		func() tea.Msg {
+			head, commit, dirty, err := snapshotWorktree(wt.Path, wt.Branch)
+			if err == nil {
+				err = gitRun(workDir, "update-ref", archive.Ref, commit)
+			}
+			if err != nil {
+				return WorktreeArchivedMsg{Epoch: epoch, Name: wt.Name, Err: err}
+			}
+			archive.Head = head
+			archive.Dirty = dirty
+			archive.ArchivedAt = time.Now()
+
+			if sessionExists(sessionName) {
+				_ = sessionBackend.Kill(sessionName)
+			}
+			if err := doDeleteWorktree(workDir, wt.Path, false); err != nil {
+				_ = gitRun(workDir, "update-ref", "-d", archive.Ref)
+				return WorktreeArchivedMsg{Epoch: epoch, Name: wt.Name, Err: err}
+			}
+			return WorktreeArchivedMsg{Epoch: epoch, Name: wt.Name, Archive: archive}
+		},
+	)
+}
+
+// handleWorktreeArchived swaps an archived worktree for its archive entry.
+func (p *Plugin) handleWorktreeArchived(msg WorktreeArchivedMsg) tea.Cmd {
+	if plugin.IsStale(p.ctx, msg) {
+		return nil
+	}
+	delete(p.archiveBusy, msg.Name)
+	if msg.Err != nil {
+		return appmsg.ShowToast(fmt.Sprintf("Archive failed: %v", msg.Err), 3*time.Second)
+	}
+
+	sessionName := tmuxSessionPrefix + sanitizeName(msg.Name)
+	delete(p.managedSessions, sessionName)
+	globalPaneCache.remove(sessionName)
+	delete(p.agents, msg.Name)
+
+	p.releaseWorktreeEnv(msg.Archive.Path)
+	p.removeWorktreeByName(msg.Name)
+	p.removeFanOutMember(msg.Name)
+	p.removeWorktreeQueue(msg.Name)
+	delete(p.reviews, msg.Name)
+	delete(p.autoAnswered, msg.Name)
+	delete(p.usage, msg.Name)
+	p.archives = append(p.archives, msg.Archive)
+	p.persistArchives()
+
+	// Keep the archived entry selected
+	p.shellSelected = false
+	p.selectedIdx = len(p.worktrees) + len(p.archives) - 1
+	p.saveSelectionState()
+	p.ensureVisible()
+	p.diffContent = ""
+	p.diffRaw = ""
+	p.cachedTaskID = ""
+	p.cachedTask = nil
+	return appmsg.ShowToast("Archived "+msg.Name, 2*time.Second)
+}
+
+// restoreSelectedArchive recreates the selected archived worktree.
+func (p *Plugin) restoreSelectedArchive() tea.Cmd {
+	a := p.selectedArchive()
+	if a == nil || p.archiveBusy[a.Name] {
+		return nil
+	}
+	if p.findWorktree(a.Name) != nil {
+		return appmsg.ShowToast("Workspace "+a.Name+" already exists", 2*time.Second)
+	}
+	if p.archiveBusy == nil {
+		p.archiveBusy = make(map[string]bool)
+	}
+	p.archiveBusy[a.Name] = true
+
+	epoch := p.ctx.Epoch
+	return tea.Batch(
+		appmsg.ShowToast("Restoring "+a.Name+"...", 2*time.Second),
+		func() tea.Msg {
+			wt, err := p.doRestoreArchive(a)
+			return ArchiveRestoredMsg{Epoch: epoch, Archive: a, Worktree: wt, Err: err}
+		},
+	)
+}
+
+// doRestoreArchive recreates the worktree, brings back its uncommitted work
+// and settings, reruns setup, and drops the hidden ref.
+func (p *Plugin) doRestoreArchive(a *ArchivedWorktree) (*Worktree, error) {
+	workDir := p.ctx.WorkDir
+	if err := os.MkdirAll(filepath.Dir(a.Path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create parent directory: %w", err)
+	}
+
+	// Reuse the branch if it survived, otherwise recreate it at the archived head
+	args := []string{"worktree", "add", a.Path, a.Branch}
+	if !branchExists(workDir, a.Branch) {
+		args = []string{"worktree", "add", "-b", a.Branch, a.Path, a.Head}
+	}
+	if err := gitRun(workDir, args...); err != nil {
+		return nil, err
+	}
+	if err := p.setupTDRoot(a.Path); err != nil {
+		p.ctx.Logger.Warn("failed to setup .td-root", "path", a.Path, "error", err)
+	}
+	if a.TaskID != "" {
+		if wtDir, err := projectdir.WorktreeDir(p.ctx.ProjectRoot, a.Path); err == nil {
+			_ = os.WriteFile(filepath.Join(wtDir, sidecarTaskFile), []byte(a.TaskID+"\n"), 0644)
+		}
+	}
+	if err := saveAgentType(p.ctx.ProjectRoot, a.Path, a.AgentType); err != nil {
+		p.ctx.Logger.Warn("failed to save agent type", "path", a.Path, "error", err)
+	}
+	if err := saveBaseBranch(p.ctx.ProjectRoot, a.Path, a.BaseBranch); err != nil {
+		p.ctx.Logger.Warn("failed to save base branch", "path", a.Path, "error", err)
+	}
+	if err := savePrompt(p.ctx.ProjectRoot, a.Path, a.Prompt); err != nil {
+		p.ctx.Logger.Warn("failed to save prompt", "path", a.Path, "error", err)
+	}
+	if err := p.setupWorktree(a.Path, a.Branch, nil); err != nil {
+		p.ctx.Logger.Warn("workspace setup had errors", "path", a.Path, "error", err)
+	}
+	if a.Dirty {
+		// After setup so archived work wins; no-overlay restore recreates
+		// modified, deleted and untracked files
+		if err := gitRun(a.Path, "restore", "--source="+a.Ref, "--worktree", "--", "."); err != nil {
+			return nil, err
+		}
+	}
+	if err := gitRun(workDir, "update-ref", "-d", a.Ref); err != nil {
+		p.ctx.Logger.Warn("failed to delete archive ref", "ref", a.Ref, "error", err)
+	}
+
+	return &Worktree{
+		Name:            a.Name,
+		Path:            a.Path,
+		Branch:          a.Branch,
+		BaseBranch:      a.BaseBranch,
+		TaskID:          a.TaskID,
+		TaskTitle:       a.TaskTitle,
+		ChosenAgentType: a.AgentType,
+		Status:          StatusPaused,
+		CreatedAt:       time.Now(),
+		UpdatedAt:       time.Now(),
+	}, nil
+}
+
+// handleArchiveRestored swaps an archive entry for its recreated worktree
+// and restarts its agent.
+func (p *Plugin) handleArchiveRestored(msg ArchiveRestoredMsg) tea.Cmd {
+	if plugin.IsStale(p.ctx, msg) {
+		return nil
+	}
+	delete(p.archiveBusy, msg.Archive.Name)
+	if msg.Err != nil {
+		return appmsg.ShowToast(fmt.Sprintf("Restore failed: %v", msg.Err), 3*time.Second)
+	}
+
+	for i, a := range p.archives {
+		if a == msg.Archive {
+			p.archives = append(p.archives[:i], p.archives[i+1:]...)
+			break
+		}
+	}
+	p.persistArchives()
+
+	p.worktrees = append(p.worktrees, msg.Worktree)
+	p.shellSelected = false
+	p.selectedIdx = len(p.worktrees) - 1
+	p.previewOffset = 0
+	p.autoScrollOutput = true
+	p.resetScrollBaseLineCount()
+	p.saveSelectionState()
+	p.ensureVisible()
+
+	cmds := []tea.Cmd{p.loadSelectedContent()}
+	if msg.Archive.AgentType != AgentNone && msg.Archive.AgentType != "" {
+		cmds = append(cmds, p.StartAgentWithOptions(msg.Worktree, msg.Archive.AgentType, false, msg.Archive.Prompt))
+	}
+	return tea.Batch(cmds...)
+}
+
+// snapshotWorktree returns the worktree's HEAD and a commit holding its
+// current state. Uncommitted and untracked changes are committed on top of
+// HEAD through a scratch index, leaving the branch and real index untouched.
+func snapshotWorktree(path, branch string) (head, commit string, dirty bool, err error) {
+	head, err = gitOutput(path, "rev-parse", "HEAD")
+	if err != nil {
+		return "", "", false, err
+	}
+	status, err := gitOutput(path, "status", "--porcelain")
+	if err != nil {
+		return "", "", false, err
+	}
+	if status == "" {
+		return head, head, false, nil
+	}
+
+	tmpDir, err := os.MkdirTemp("", "sidecar-archive-")
+	if err != nil {
+		return "", "", false, err
+	}
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+	env := append(os.Environ(), "GIT_INDEX_FILE="+filepath.Join(tmpDir, "index"))
+
+	if _, err := gitOutputEnv(path, env, "read-tree", "HEAD"); err != nil {
+		return "", "", false, err
+	}
+	if _, err := gitOutputEnv(path, env, "add", "-A"); err != nil {
+		return "", "", false, err
+	}
+	tree, err := gitOutputEnv(path, env, "write-tree")
+	if err != nil {
+		return "", "", false, err
+	}
+	commit, err = gitOutput(path, "commit-tree", tree, "-p", head, "-m", "sidecar archive of "+branch)
+	if err != nil {
+		return "", "", false, err
+	}
+	return head, commit, true, nil
+}
+
+// gitRun runs a git command in dir, folding its stderr into any error.
+func gitRun(dir string, args ...string) error {
+	_, err := gitOutputEnv(dir, nil, args...)
+	return err
+}
+
+// gitOutput runs a git command in dir and returns its trimmed output.
+func gitOutput(dir string, args ...string) (string, error) {
+	return gitOutputEnv(dir, nil, args...)
+}
+
+// gitOutputEnv is gitOutput with an explicit environment (nil inherits).
+func gitOutputEnv(dir string, env []string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = env
+	output, err := cmd.Output()
+	if err != nil {
+		var stderr string
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			stderr = strings.TrimSpace(string(exitErr.Stderr))
+		}
+		return "", fmt.Errorf("git %s: %s: %w", args[0], stderr, err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
@@ -0,0 +1,167 @@
+package workspace
+
+import (
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/marcus/sidecar/internal/config"
+	"github.com/marcus/sidecar/internal/plugin"
+)
+
+// initArchiveRepo creates a repo with one commit and a worktree on branch
+// "feature", returning the repo and worktree paths.
+func initArchiveRepo(t *testing.T) (string, string) {
+	t.Helper()
+	root := t.TempDir()
+	repo := filepath.Join(root, "repo")
+	wtPath := filepath.Join(root, "feature")
+	if err := os.Mkdir(repo, 0755); err != nil {
+		t.Fatal(err)
+	}
+	run := func(dir string, args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v: %s", args, err, out)
+		}
+	}
+	run(repo, "init", "-q")
+	run(repo, "config", "user.email", "test@test.com")
+	run(repo, "config", "user.name", "Test")
+	for name, content := range map[string]string{"kept.txt": "kept\n", "gone.txt": "gone\n"} {
+		if err := os.WriteFile(filepath.Join(repo, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	run(repo, "add", ".")
+	run(repo, "commit", "-q", "-m", "initial")
+	run(repo, "worktree", "add", "-q", "-b", "feature", wtPath)
+	return repo, wtPath
+}
+
+func TestSnapshotWorktree_Clean(t *testing.T) {
+	_, wtPath := initArchiveRepo(t)
+
+	head, commit, dirty, err := snapshotWorktree(wtPath, "feature")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dirty || commit != head {
+		t.Errorf("clean worktree: dirty=%v commit=%s head=%s", dirty, commit, head)
+	}
+}
+
+func TestArchiveRestoreRoundTrip(t *testing.T) {
+	config.SetTestStateDir(t.TempDir())
+	repo, wtPath := initArchiveRepo(t)
+
+	// Uncommitted work: a modification, a deletion and an untracked file
+	if err := os.WriteFile(filepath.Join(wtPath, "kept.txt"), []byte("edited\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(filepath.Join(wtPath, "gone.txt")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(wtPath, "new.txt"), []byte("new\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	head, commit, dirty, err := snapshotWorktree(wtPath, "feature")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !dirty || commit == head {
+		t.Fatalf("dirty worktree: dirty=%v commit=%s head=%s", dirty, commit, head)
+	}
+	ref := archiveRef("feature")
+	if err := gitRun(repo, "update-ref", ref, commit); err != nil {
+		t.Fatal(err)
+	}
+	if err := doDeleteWorktree(repo, wtPath, false); err != nil {
+		t.Fatal(err)
+	}
+	if err := deleteBranch(repo, "feature"); err != nil {
+		t.Fatal(err)
+	}
+
+	p := &Plugin{ctx: &plugin.Context{WorkDir: repo, ProjectRoot: repo, Logger: slog.Default()}}
+	a := &ArchivedWorktree{
+		Name:      "feature",
+		Path:      wtPath,
+		Branch:    "feature",
+		AgentType: AgentClaude,
+		Prompt:    &Prompt{Name: "Feature", Body: "Build it"},
+		Head:      head,
+		Ref:       ref,
+		Dirty:     true,
+	}
+	wt, err := p.doRestoreArchive(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if wt.Branch != "feature" || wt.ChosenAgentType != AgentClaude {
+		t.Errorf("restored worktree = %+v", wt)
+	}
+
+	for name, want := range map[string]string{"kept.txt": "edited\n", "new.txt": "new\n"} {
+		got, err := os.ReadFile(filepath.Join(wtPath, name))
+		if err != nil || string(got) != want {
+			t.Errorf("%s = %q, %v; want %q", name, got, err, want)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(wtPath, "gone.txt")); !os.IsNotExist(err) {
+		t.Errorf("gone.txt should stay deleted, stat err = %v", err)
+	}
+	if branchHead, _ := gitOutput(wtPath, "rev-parse", "HEAD"); branchHead != head {
+		t.Errorf("branch head = %s, want %s", branchHead, head)
+	}
+	if _, err := gitOutput(repo, "rev-parse", "--verify", ref); err == nil {
+		t.Error("archive ref should be deleted after restore")
+	}
+	if got := loadAgentType(repo, wtPath); got != AgentClaude {
+		t.Errorf("agent type = %q, want %q", got, AgentClaude)
+	}
+	if got := loadPrompt(repo, wtPath); got == nil || got.Body != "Build it" {
+		t.Errorf("prompt = %+v", got)
+	}
+}
+
+func TestArchivePersistence(t *testing.T) {
+	config.SetTestStateDir(t.TempDir())
+	projectRoot := t.TempDir()
+
+	archives := []*ArchivedWorktree{{Name: "a", Branch: "a", Ref: archiveRef("a"), Dirty: true}}
+	if err := saveArchives(projectRoot, archives); err != nil {
+		t.Fatal(err)
+	}
+	loaded := loadArchives(projectRoot)
+	if len(loaded) != 1 || loaded[0].Ref != "refs/sidecar/archive/a" || !loaded[0].Dirty {
+		t.Errorf("loaded = %+v", loaded)
+	}
+}
+
+func TestSelectedArchive(t *testing.T) {
+	p := &Plugin{
+		worktrees: []*Worktree{{Name: "wt"}},
+		archives:  []*ArchivedWorktree{{Name: "old"}},
+	}
+
+	if p.selectedArchive() != nil {
+		t.Error("worktree selected, want no archive")
+	}
+	p.moveCursor(1)
+	if a := p.selectedArchive(); a == nil || a.Name != "old" {
+		t.Errorf("selectedArchive = %+v, want old", a)
+	}
+	if p.selectedWorktree() != nil {
+		t.Error("archive selected, want no worktree")
+	}
+	p.moveCursor(1)
+	if p.selectedIdx != 1 {
+		t.Errorf("selectedIdx = %d, want clamp at 1", p.selectedIdx)
+	}
+}
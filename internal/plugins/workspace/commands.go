@@ -15,10 +15,14 @@ func (p *Plugin) Commands() []plugin.Command {
 			{ID: "paste", Name: "Paste", Description: "Paste clipboard (" + p.getInteractivePasteKey() + ")", Context: "workspace-interactive", Priority: 3},
 		}
 	case ViewModeCreate:
-		return []plugin.Command{
+		cmds := []plugin.Command{
 			{ID: "cancel", Name: "Cancel", Description: "Cancel workspace creation", Context: "workspace-create", Priority: 1},
 			{ID: "confirm", Name: "Create", Description: "Create the workspace", Context: "workspace-create", Priority: 2},
 		}
+		if len(p.workspacePresets()) > 0 {
+			cmds = append(cmds, plugin.Command{ID: "next-preset", Name: "Preset", Description: "Apply the next workspace preset", Context: "workspace-create", Priority: 3})
+		}
+		return cmds
 	case ViewModeTaskLink:
 		return []plugin.Command{
 			{ID: "cancel", Name: "Cancel", Description: "Cancel task linking", Context: "workspace-task-link", Priority: 1},
@@ -31,6 +35,13 @@ func (p *Plugin) Commands() []plugin.Command {
 				{ID: "yank-merge-error", Name: "Yank", Description: "Copy error to clipboard", Context: "workspace-merge-error", Priority: 2},
 			}
 		}
+		if p.mergeState != nil && p.mergeState.Step == MergeStepVerify && p.mergeState.TestFailed {
+			return []plugin.Command{
+				{ID: "cancel", Name: "Cancel", Description: "Cancel merge workflow", Context: "workspace-merge-tests", Priority: 1},
+				{ID: "retry-merge-tests", Name: "Retry", Description: "Run the tests again", Context: "workspace-merge-tests", Priority: 2},
+				{ID: "send-test-failure", Name: "Fix", Description: "Send failing output to the agent", Context: "workspace-merge-tests", Priority: 3},
+			}
+		}
 		cmds := []plugin.Command{
 			{ID: "cancel", Name: "Cancel", Description: "Cancel merge workflow", Context: "workspace-merge", Priority: 1},
 		}
@@ -80,6 +91,69 @@ func (p *Plugin) Commands() []plugin.Command {
 			{ID: "cancel", Name: "Cancel", Description: "Cancel PR fetch", Context: "workspace-fetch-pr", Priority: 1},
 			{ID: "fetch", Name: "Fetch", Description: "Fetch selected PR", Context: "workspace-fetch-pr", Priority: 2},
 		}
+	case ViewModeFanOut:
+		return []plugin.Command{
+			{ID: "cancel", Name: "Cancel", Description: "Cancel fan-out", Context: "workspace-fan-out", Priority: 1},
+			{ID: "next-field", Name: "Next", Description: "Next field", Context: "workspace-fan-out", Priority: 2},
+		}
+	case ViewModeFanOutBoard:
+		if p.fanOutConfirmWinner {
+			return []plugin.Command{
+				{ID: "confirm-winner", Name: "Merge", Description: "Merge winner and delete the rest", Context: "workspace-fan-out-board", Priority: 1},
+				{ID: "cancel-winner", Name: "Cancel", Description: "Keep all workspaces", Context: "workspace-fan-out-board", Priority: 2},
+			}
+		}
+		return []plugin.Command{
+			{ID: "close", Name: "Close", Description: "Close comparison board", Context: "workspace-fan-out-board", Priority: 1},
+			{ID: "run-tests", Name: "Test", Description: "Run test command in every workspace", Context: "workspace-fan-out-board", Priority: 2},
+			{ID: "pick-winner", Name: "Winner", Description: "Merge selected workspace, delete the rest once merged", Context: "workspace-fan-out-board", Priority: 3},
+			{ID: "go-to", Name: "Go to", Description: "Select workspace in list", Context: "workspace-fan-out-board", Priority: 4},
+			{ID: "refresh", Name: "Refresh", Description: "Reload diff stats and cost", Context: "workspace-fan-out-board", Priority: 5},
+		}
+	case ViewModePromptQueue:
+		if p.queueModal != nil && p.queueModal.FocusedID() == promptQueueListID {
+			return []plugin.Command{
+				{ID: "close", Name: "Close", Description: "Close prompt queue", Context: "workspace-prompt-queue", Priority: 1},
+				{ID: "toggle-pause", Name: "Pause", Description: "Pause or resume the queue", Context: "workspace-prompt-queue", Priority: 2},
+				{ID: "skip-prompt", Name: "Skip", Description: "Remove selected prompt", Context: "workspace-prompt-queue", Priority: 3},
+				{ID: "move-up", Name: "Up", Description: "Move prompt earlier", Context: "workspace-prompt-queue", Priority: 4},
+				{ID: "move-down", Name: "Down", Description: "Move prompt later", Context: "workspace-prompt-queue", Priority: 5},
+			}
+		}
+		return []plugin.Command{
+			{ID: "close", Name: "Close", Description: "Close prompt queue", Context: "workspace-prompt-queue", Priority: 1},
+			{ID: "add-prompt", Name: "Add", Description: "Add prompt to queue", Context: "workspace-prompt-queue", Priority: 2},
+			{ID: "next-field", Name: "Queue", Description: "Edit queued prompts", Context: "workspace-prompt-queue", Priority: 3},
+		}
+	case ViewModeReviewComment:
+		return []plugin.Command{
+			{ID: "save-comment", Name: "Save", Description: "Save review comment", Context: "workspace-review-comment", Priority: 1},
+			{ID: "cancel", Name: "Cancel", Description: "Discard comment", Context: "workspace-review-comment", Priority: 2},
+		}
+	case ViewModeReview:
+		return []plugin.Command{
+			{ID: "close", Name: "Close", Description: "Close review", Context: "workspace-review", Priority: 1},
+			{ID: "send-review", Name: "Send", Description: "Send open comments to the agent", Context: "workspace-review", Priority: 2},
+			{ID: "go-to-comment", Name: "Go to", Description: "Show the comment's line in the diff", Context: "workspace-review", Priority: 3},
+			{ID: "delete-comment", Name: "Delete", Description: "Delete selected comment", Context: "workspace-review", Priority: 4},
+			{ID: "clear-resolved", Name: "Clear", Description: "Delete resolved comments", Context: "workspace-review", Priority: 5},
+		}
+	case ViewModeSyncConflict:
+		return p.syncConflictCommands()
+	case ViewModeOutputSearch:
+		return p.outputSearchCommands()
+	case ViewModeTranscripts:
+		if p.transcripts != nil && p.transcripts.ConfirmDelete {
+			return []plugin.Command{
+				{ID: "confirm-delete", Name: "Delete", Description: "Delete selected transcript", Context: "workspace-transcripts", Priority: 1},
+			}
+		}
+		return []plugin.Command{
+			{ID: "close", Name: "Close", Description: "Close transcripts", Context: "workspace-transcripts", Priority: 1},
+			{ID: "open-transcript", Name: "Open", Description: "Search the selected transcript", Context: "workspace-transcripts", Priority: 2},
+			{ID: "delete-transcript", Name: "Delete", Description: "Delete the selected transcript", Context: "workspace-transcripts", Priority: 3},
+			{ID: "refresh", Name: "Refresh", Description: "Reload transcripts", Context: "workspace-transcripts", Priority: 4},
+		}
 	case ViewModeFilePicker:
 		return []plugin.Command{
 			{ID: "cancel", Name: "Cancel", Description: "Close file picker", Context: "workspace-file-picker", Priority: 1},
@@ -116,6 +190,10 @@ func (p *Plugin) Commands() []plugin.Command {
 						diffViewName = "Unified"
 					}
 					cmds = append(cmds, plugin.Command{ID: "toggle-diff-view", Name: diffViewName, Description: "Cycle diff view mode", Context: "workspace-preview", Priority: 5})
+					if p.diffTabFocus == DiffTabFocusDiff && p.diffViewMode == DiffViewUnified {
+						cmds = append(cmds, plugin.Command{ID: "comment-line", Name: "Comment", Description: "Comment on the marked diff line", Context: "workspace-preview", Priority: 8})
+					}
+					cmds = append(cmds, plugin.Command{ID: "review", Name: "Review", Description: "Review diff comments and send them to the agent", Context: "workspace-preview", Priority: 9})
 					// Add file navigation commands when viewing diff with multiple files
 					if p.multiFileDiff != nil && len(p.multiFileDiff.Files) > 1 {
 						cmds = append(cmds,
@@ -164,6 +242,13 @@ func (p *Plugin) Commands() []plugin.Command {
 					)
 				}
 			}
+			// Scrollback search (Output tab with an agent, or a running shell)
+			if (p.shellSelected && p.getSelectedShell() != nil && p.getSelectedShell().Agent != nil) ||
+				(wt != nil && wt.Agent != nil && p.previewTab == PreviewTabOutput) {
+				cmds = append(cmds,
+					plugin.Command{ID: "search-output", Name: "Search", Description: "Search the session's scrollback", Context: "workspace-preview", Priority: 14},
+				)
+			}
 			// Terminal panel toggle (show on Output tab when an agent or shell is active)
 			if p.previewTab == PreviewTabOutput || p.shellSelected {
 				termName := "Term"
@@ -193,11 +278,34 @@ func (p *Plugin) Commands() []plugin.Command {
 		cmds := []plugin.Command{
 			{ID: "new-workspace", Name: "New", Description: "Create new workspace", Context: "workspace-list", Priority: 1},
 			{ID: "fetch-pr", Name: "Fetch", Description: "Fetch remote PR as workspace", Context: "workspace-list", Priority: 2},
+			{ID: "fan-out", Name: "Fan out", Description: "Run one prompt across several agents", Context: "workspace-list", Priority: 19},
 			{ID: "toggle-view", Name: viewToggleName, Description: "Toggle list/kanban view", Context: "workspace-list", Priority: 3},
 			{ID: "toggle-sidebar", Name: "Sidebar", Description: "Toggle sidebar visibility", Context: "workspace-list", Priority: 4},
 			{ID: "refresh", Name: "Refresh", Description: "Refresh workspace list", Context: "workspace-list", Priority: 5},
 		}
 
+		// Bound presets open the create modal with the preset applied
+		for _, preset := range p.workspacePresets() {
+			if preset.Key != "" {
+				cmds = append(cmds,
+					plugin.Command{ID: presetCommandID(preset.Name), Name: preset.Name, Description: "Create workspace from the " + preset.Name + " preset", Context: "workspace-list", Priority: 25},
+				)
+			}
+		}
+
+		// Kanban: board switch, and card moves on the td task board
+		if p.viewMode == ViewModeKanban {
+			cmds = append(cmds,
+				plugin.Command{ID: "switch-board", Name: "Board", Description: "Switch between agent status and task boards", Context: "workspace-list", Priority: 6},
+			)
+			if p.kanbanByTask {
+				cmds = append(cmds,
+					plugin.Command{ID: "move-card-left", Name: "Move ←", Description: "Move task to the previous status", Context: "workspace-list", Priority: 7},
+					plugin.Command{ID: "move-card-right", Name: "Move →", Description: "Move task to the next status", Context: "workspace-list", Priority: 8},
+				)
+			}
+		}
+
 		// Shell-specific commands when shell is selected
 		if p.shellSelected {
 			shell := p.getSelectedShell()
@@ -212,10 +320,21 @@ func (p *Plugin) Commands() []plugin.Command {
 					plugin.Command{ID: "kill-shell", Name: "Kill", Description: "Kill shell session", Context: "workspace-list", Priority: 11},
 				plugin.Command{ID: "rename-shell", Name: "Rename", Description: "Rename shell", Context: "workspace-list", Priority: 12},
 				)
+				if shell.ChosenAgent != AgentNone && shell.ChosenAgent != "" {
+					cmds = append(cmds,
+						plugin.Command{ID: "prompt-queue", Name: "Queue", Description: "Queue prompts for when the agent is idle", Context: "workspace-list", Priority: 13},
+					)
+				}
 			}
 			return cmds
 		}
 
+		if p.selectedArchive() != nil {
+			cmds = append(cmds,
+				plugin.Command{ID: "archive-workspace", Name: "Restore", Description: "Recreate archived workspace and its agent", Context: "workspace-list", Priority: 6},
+			)
+		}
+
 		wt := p.selectedWorktree()
 		if wt != nil {
 			// Agent commands first (most context-dependent, highest visibility)
@@ -243,7 +362,39 @@ func (p *Plugin) Commands() []plugin.Command {
 				plugin.Command{ID: "push", Name: "Push", Description: "Push branch to remote", Context: "workspace-list", Priority: 6},
 				plugin.Command{ID: "merge-workflow", Name: "Merge", Description: "Start merge workflow", Context: "workspace-list", Priority: 7},
 				plugin.Command{ID: "open-in-git", Name: "Git", Description: "Open in Git tab", Context: "workspace-list", Priority: 16},
+				plugin.Command{ID: "prompt-queue", Name: "Queue", Description: "Queue prompts for when the agent is idle", Context: "workspace-list", Priority: 20},
 			)
+			if len(p.reviewComments(wt)) > 0 {
+				cmds = append(cmds,
+					plugin.Command{ID: "review", Name: "Review", Description: "Review diff comments and send them to the agent", Context: "workspace-list", Priority: 20},
+				)
+			}
+			if u := p.usageFor(wt.Name); u != nil && u.LatestID != "" {
+				cmds = append(cmds,
+					plugin.Command{ID: "jump-to-conversation", Name: "Chat", Description: "Open the latest agent session in Conversations", Context: "workspace-list", Priority: 21},
+				)
+			}
+			if !wt.IsMain && !wt.IsMissing {
+				cmds = append(cmds,
+					plugin.Command{ID: "archive-workspace", Name: "Archive", Description: "Archive workspace and free its directory", Context: "workspace-list", Priority: 21},
+				)
+			}
+			if syncable(wt) {
+				cmds = append(cmds,
+					plugin.Command{ID: "sync-workspace", Name: "Sync", Description: "Sync workspace with its base branch", Context: "workspace-list", Priority: 22},
+					plugin.Command{ID: "sync-all", Name: "Sync all", Description: "Sync every workspace with its base branch", Context: "workspace-list", Priority: 23},
+				)
+			}
+			if p.isStacked(wt) {
+				cmds = append(cmds,
+					plugin.Command{ID: "stack-prs", Name: "Stack PRs", Description: "Push the stack and open a PR for each worktree", Context: "workspace-list", Priority: 24},
+				)
+			}
+			if p.fanOutFor(wt) != nil {
+				cmds = append(cmds,
+					plugin.Command{ID: "fan-out-board", Name: "Compare", Description: "Open fan-out comparison board", Context: "workspace-list", Priority: 15},
+				)
+			}
 			// Task linking
 			if wt.TaskID != "" {
 				cmds = append(cmds,
@@ -272,6 +423,9 @@ func (p *Plugin) Commands() []plugin.Command {
 				plugin.Command{ID: "switch-terminal-layout", Name: layoutName, Description: "Switch terminal layout", Context: "workspace-list", Priority: 18},
 			)
 		}
+		cmds = append(cmds,
+			plugin.Command{ID: "transcripts", Name: "Transcripts", Description: "Browse recorded session transcripts", Context: "workspace-list", Priority: 25},
+		)
 		return cmds
 	}
 }
@@ -289,6 +443,9 @@ func (p *Plugin) FocusContext() string {
 		if p.mergeState != nil && p.mergeState.Step == MergeStepError {
 			return "workspace-merge-error"
 		}
+		if p.mergeState != nil && p.mergeState.Step == MergeStepVerify && p.mergeState.TestFailed {
+			return "workspace-merge-tests"
+		}
 		return "workspace-merge"
 	case ViewModeAgentConfig:
 		return "workspace-agent-config"
@@ -308,6 +465,25 @@ func (p *Plugin) FocusContext() string {
 		return "workspace-type-selector"
 	case ViewModeFetchPR:
 		return "workspace-fetch-pr"
+	case ViewModeFanOut:
+		return "workspace-fan-out"
+	case ViewModeFanOutBoard:
+		return "workspace-fan-out-board"
+	case ViewModePromptQueue:
+		return "workspace-prompt-queue"
+	case ViewModeReviewComment:
+		return "workspace-review-comment"
+	case ViewModeReview:
+		return "workspace-review"
+	case ViewModeSyncConflict:
+		return "workspace-sync-conflict"
+	case ViewModeOutputSearch:
+		if p.outputSearch != nil && p.outputSearch.Editing {
+			return "workspace-output-search-input"
+		}
+		return "workspace-output-search"
+	case ViewModeTranscripts:
+		return "workspace-transcripts"
 	case ViewModeFilePicker:
 		return "workspace-file-picker"
 	default:
@@ -329,9 +505,71 @@ func (p *Plugin) ConsumesTextInput() bool {
 		ViewModePromptPicker,
 		ViewModeRenameShell,
 		ViewModeTypeSelector,
-		ViewModeFetchPR:
+		ViewModeFetchPR,
+		ViewModeFanOut,
+		ViewModePromptQueue,
+		ViewModeReviewComment:
 		return true
+	case ViewModeOutputSearch:
+		return p.outputSearch != nil && p.outputSearch.Editing
 	default:
 		return false
 	}
 }
+
+// syncConflictCommands returns the commands for the sync conflict modal.
+func (p *Plugin) syncConflictCommands() []plugin.Command {
+	s := p.syncState
+	if s == nil || s.Running {
+		return nil
+	}
+	cmds := []plugin.Command{
+		{ID: "close", Name: "Close", Description: "Close sync conflict", Context: "workspace-sync-conflict", Priority: 1},
+	}
+	if s.Resolving != "" {
+		cmds = append(cmds,
+			plugin.Command{ID: "abort", Name: "Abort", Description: "Abort the rebase or merge", Context: "workspace-sync-conflict", Priority: 2},
+		)
+	} else {
+		cmds = append(cmds,
+			plugin.Command{ID: "rebase", Name: "Rebase", Description: "Rebase onto the base branch", Context: "workspace-sync-conflict", Priority: 2},
+			plugin.Command{ID: "merge", Name: "Merge", Description: "Merge the base branch in", Context: "workspace-sync-conflict", Priority: 3},
+		)
+	}
+	if s.Worktree.Agent != nil {
+		cmds = append(cmds,
+			plugin.Command{ID: "hand-to-agent", Name: "Agent", Description: "Have the agent resolve the conflicts", Context: "workspace-sync-conflict", Priority: 4},
+		)
+	}
+	return cmds
+}
+
+// outputSearchCommands returns the commands for the scrollback search.
+func (p *Plugin) outputSearchCommands() []plugin.Command {
+	s := p.outputSearch
+	if s == nil {
+		return nil
+	}
+	if s.Editing {
+		return []plugin.Command{
+			{ID: "done", Name: "Done", Description: "Stop editing the query", Context: "workspace-output-search-input", Priority: 1},
+			{ID: "toggle-regex", Name: "Regex", Description: "Toggle regex or literal search", Context: "workspace-output-search-input", Priority: 2},
+			{ID: "close", Name: "Close", Description: "Close search", Context: "workspace-output-search-input", Priority: 3},
+		}
+	}
+	cmds := []plugin.Command{
+		{ID: "next-match", Name: "Next", Description: "Next match", Context: "workspace-output-search", Priority: 1},
+		{ID: "prev-match", Name: "Prev", Description: "Previous match", Context: "workspace-output-search", Priority: 2},
+		{ID: "copy-block", Name: "Copy", Description: "Copy the block around the match", Context: "workspace-output-search", Priority: 3},
+	}
+	if _, ok := s.outputFileRef(); ok {
+		cmds = append(cmds,
+			plugin.Command{ID: "open-file", Name: "Open", Description: "Open file:line in the file browser", Context: "workspace-output-search", Priority: 4},
+		)
+	}
+	cmds = append(cmds,
+		plugin.Command{ID: "edit-query", Name: "Search", Description: "Edit the search query", Context: "workspace-output-search", Priority: 5},
+		plugin.Command{ID: "close", Name: "Close", Description: "Close search", Context: "workspace-output-search", Priority: 6},
+	)
+	return cmds
+}
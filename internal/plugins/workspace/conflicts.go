@@ -1,115 +1,272 @@
 package workspace
 
 import (
+	"errors"
+	"fmt"
+	"log/slog"
 	"os/exec"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/marcus/sidecar/internal/plugin"
 )
 
-// Conflict represents a file conflict between worktrees.
+// conflictCheckInterval is how often branch tips are checked for changes.
+// Merges are only re-simulated for pairs whose tips moved.
+const conflictCheckInterval = 5 * time.Second
+
+// Conflict represents a predicted merge conflict, found by simulating the
+// merge with git merge-tree. It is either between two worktree branches or,
+// when Base is set, between a worktree branch and its base branch. With git
+// older than 2.38 it is only a pair of worktrees changing the same files.
 type Conflict struct {
-	Worktrees []string // Names of worktrees with conflicting changes
-	Files     []string // List of conflicting files
+	Worktrees []string       // Names of worktrees with conflicting changes
+	Base      string         // Base branch the single worktree conflicts with
+	Files     []string       // List of conflicting files
+	Hunks     []ConflictHunk // Textual conflicts; files like modify/delete have none
+}
+
+// ConflictHunk is one conflicted region from a simulated merge, including
+// the <<<<<<< / ======= / >>>>>>> marker lines.
+type ConflictHunk struct {
+	File  string
+	Lines []string
 }
 
 // ConflictsDetectedMsg signals that conflicts have been detected.
 type ConflictsDetectedMsg struct {
+	Epoch     uint64 // Epoch when request was issued (for stale detection)
 	Conflicts []Conflict
+	Tips      map[string]string // Branch tips the check ran against
 	Err       error
 }
 
-// detectConflicts scans all worktrees for files modified in multiple worktrees.
-// Returns a list of conflicts where each conflict indicates which worktrees
-// are modifying the same files.
-func (p *Plugin) detectConflicts() []Conflict {
-	if len(p.worktrees) < 2 {
-		return nil // Need at least 2 worktrees for conflicts
-	}
+// GetEpoch implements plugin.EpochMessage.
+func (m ConflictsDetectedMsg) GetEpoch() uint64 { return m.Epoch }
+
+// conflictCheckTickMsg triggers a periodic conflict check.
+type conflictCheckTickMsg struct {
+	Epoch uint64
+}
+
+// GetEpoch implements plugin.EpochMessage.
+func (m conflictCheckTickMsg) GetEpoch() uint64 { return m.Epoch }
+
+// conflictTarget is a worktree branch taking part in conflict detection.
+type conflictTarget struct {
+	name, branch, base, path string
+}
 
-	// Build a map of modified files per worktree
-	filesByWorktree := make(map[string][]string)
+// mergeResult is the outcome of one simulated merge.
+type mergeResult struct {
+	files []string
+	hunks []ConflictHunk
+}
+
+// mergeCache holds merge-tree results keyed by the two commits merged, so
+// unchanged pairs aren't re-simulated.
+type mergeCache struct {
+	mu      sync.Mutex
+	entries map[string]mergeResult
+}
+
+// globalMergeCache is shared across checks; entries for tips that moved
+// are dropped on the next check.
+var globalMergeCache = &mergeCache{entries: make(map[string]mergeResult)}
+
+// loadConflicts returns a command to detect conflicts across worktrees.
+func (p *Plugin) loadConflicts() tea.Cmd {
+	epoch := p.ctx.Epoch
+	repoDir := p.ctx.WorkDir
+	var targets []conflictTarget
 	for _, wt := range p.worktrees {
-		files, err := getModifiedFiles(wt.Path)
-		if err != nil {
-			p.ctx.Logger.Debug("failed to get modified files",
-				"worktree", wt.Name, "error", err)
+		if wt.IsMain || wt.IsMissing || wt.Branch == "" {
 			continue
 		}
-		if len(files) > 0 {
-			filesByWorktree[wt.Name] = files
+		targets = append(targets, conflictTarget{name: wt.Name, branch: wt.Branch, base: wt.BaseBranch, path: wt.Path})
+	}
+	return func() tea.Msg {
+		if len(targets) == 0 {
+			return ConflictsDetectedMsg{Epoch: epoch}
+		}
+		tips, err := branchTips(repoDir)
+		if err != nil {
+			return ConflictsDetectedMsg{Epoch: epoch, Err: err}
+		}
+		var conflicts []Conflict
+		if gitHasMergeTree(repoDir) {
+			conflicts = detectConflicts(repoDir, targets, tips, globalMergeCache)
+		} else {
+			conflicts = detectFileOverlaps(targets)
 		}
+		return ConflictsDetectedMsg{Epoch: epoch, Conflicts: conflicts, Tips: tips}
 	}
+}
 
-	// Find overlaps between worktrees
-	var conflicts []Conflict
-	worktreeNames := make([]string, 0, len(filesByWorktree))
-	for name := range filesByWorktree {
-		worktreeNames = append(worktreeNames, name)
+// scheduleConflictCheck schedules the next periodic conflict check.
+func (p *Plugin) scheduleConflictCheck() tea.Cmd {
+	epoch := p.ctx.Epoch
+	return tea.Tick(conflictCheckInterval, func(time.Time) tea.Msg {
+		return conflictCheckTickMsg{Epoch: epoch}
+	})
+}
+
+// handleConflictCheckTick re-runs detection and schedules the next check.
+func (p *Plugin) handleConflictCheckTick(msg conflictCheckTickMsg) tea.Cmd {
+	if plugin.IsStale(p.ctx, msg) {
+		return nil // A newer chain was started for the current project
 	}
+	return tea.Batch(p.loadConflicts(), p.scheduleConflictCheck())
+}
 
-	for i := 0; i < len(worktreeNames); i++ {
-		for j := i + 1; j < len(worktreeNames); j++ {
-			wt1 := worktreeNames[i]
-			wt2 := worktreeNames[j]
+// detectConflicts simulates merging each pair of worktree branches, and each
+// branch into its base branch, with git merge-tree, at the given tips. Only committed work is
+// considered, since that is what a merge would combine. A pair that can't
+// be simulated is skipped and tried again on the next check.
+func detectConflicts(repoDir string, targets []conflictTarget, tips map[string]string, cache *mergeCache) []Conflict {
+	var conflicts []Conflict
+	seen := make(map[string]bool)
+	simulate := func(ours, theirs string) (mergeResult, error) {
+		key := ours + ":" + theirs
+		seen[key] = true
+		if res, ok := cache.get(key); ok {
+			return res, nil
+		}
+		res, err := simulateMerge(repoDir, ours, theirs)
+		if err != nil {
+			return mergeResult{}, err
+		}
+		cache.set(key, res)
+		return res, nil
+	}
 
-			overlap := intersection(filesByWorktree[wt1], filesByWorktree[wt2])
-			if len(overlap) > 0 {
+	for i, a := range targets {
+		tipA := tips[a.branch]
+		if tipA == "" {
+			continue
+		}
+		if tipBase := tips[a.base]; tipBase != "" && tipBase != tipA {
+			res, err := simulate(tipA, tipBase)
+			if err != nil {
+				slog.Debug("conflicts: simulate merge", "worktree", a.name, "base", a.base, "err", err)
+			} else if len(res.files) > 0 {
 				conflicts = append(conflicts, Conflict{
-					Worktrees: []string{wt1, wt2},
-					Files:     overlap,
+					Worktrees: []string{a.name},
+					Base:      a.base,
+					Files:     res.files,
+					Hunks:     res.hunks,
+				})
+			}
+		}
+		for _, b := range targets[i+1:] {
+			tipB := tips[b.branch]
+			if tipB == "" || tipB == tipA {
+				continue
+			}
+			res, err := simulate(tipA, tipB)
+			if err != nil {
+				slog.Debug("conflicts: simulate merge", "worktree", a.name, "other", b.name, "err", err)
+			} else if len(res.files) > 0 {
+				conflicts = append(conflicts, Conflict{
+					Worktrees: []string{a.name, b.name},
+					Files:     res.files,
+					Hunks:     res.hunks,
 				})
 			}
 		}
 	}
-
+	cache.retain(seen)
 	return conflicts
 }
 
-// loadConflicts returns a command to detect conflicts across worktrees.
-func (p *Plugin) loadConflicts() tea.Cmd {
-	return func() tea.Msg {
-		conflicts := p.detectConflicts()
-		return ConflictsDetectedMsg{Conflicts: conflicts}
-	}
+// mergeTreeSupport caches whether git supports merge-tree --write-tree.
+var mergeTreeSupport struct {
+	once sync.Once
+	ok   bool
 }
 
-// getModifiedFiles returns a list of files modified in the worktree.
-// This includes both staged and unstaged changes.
-func getModifiedFiles(workdir string) ([]string, error) {
-	// Get list of modified files (staged + unstaged) relative to HEAD
-	cmd := exec.Command("git", "diff", "--name-only", "HEAD")
-	cmd.Dir = workdir
-	output, err := cmd.Output()
-	if err != nil {
-		// No HEAD yet or other error, try just diff
-		cmd = exec.Command("git", "diff", "--name-only")
-		cmd.Dir = workdir
-		output, _ = cmd.Output()
+// gitHasMergeTree reports whether the installed git supports
+// merge-tree --write-tree (2.38+). git is checked once per process.
+func gitHasMergeTree(repoDir string) bool {
+	mergeTreeSupport.once.Do(func() {
+		cmd := exec.Command("git", "version")
+		cmd.Dir = repoDir
+		output, err := cmd.Output()
+		if err != nil {
+			return
+		}
+		mergeTreeSupport.ok = gitVersionAtLeast(string(output), 2, 38)
+		if !mergeTreeSupport.ok {
+			slog.Info("git merge-tree --write-tree unavailable; conflict detection falls back to changed files", "git", strings.TrimSpace(string(output)))
+		}
+	})
+	return mergeTreeSupport.ok
+}
+
+// gitVersionAtLeast parses `git version` output ("git version 2.39.3
+// (Apple Git-145)") and compares it with major.minor.
+func gitVersionAtLeast(output string, major, minor int) bool {
+	fields := strings.Fields(output)
+	if len(fields) < 3 {
+		return false
+	}
+	parts := strings.SplitN(fields[2], ".", 3)
+	if len(parts) < 2 {
+		return false
+	}
+	gotMajor, err1 := strconv.Atoi(parts[0])
+	gotMinor, err2 := strconv.Atoi(parts[1])
+	if err1 != nil || err2 != nil {
+		return false
 	}
+	return gotMajor > major || (gotMajor == major && gotMinor >= minor)
+}
 
-	var files []string
-	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
-		line = strings.TrimSpace(line)
-		if line != "" {
-			files = append(files, line)
+// detectFileOverlaps reports pairs of worktrees with uncommitted changes to
+// the same files. It is the fallback for git without merge-tree --write-tree.
+func detectFileOverlaps(targets []conflictTarget) []Conflict {
+	filesByTarget := make([][]string, len(targets))
+	for i, t := range targets {
+		filesByTarget[i] = getModifiedFiles(t.path)
+	}
+	var conflicts []Conflict
+	for i := range targets {
+		for j := i + 1; j < len(targets); j++ {
+			if overlap := intersection(filesByTarget[i], filesByTarget[j]); len(overlap) > 0 {
+				conflicts = append(conflicts, Conflict{
+					Worktrees: []string{targets[i].name, targets[j].name},
+					Files:     overlap,
+				})
+			}
 		}
 	}
+	return conflicts
+}
 
-	// Also get untracked files that are new
-	cmd = exec.Command("git", "ls-files", "--others", "--exclude-standard")
-	cmd.Dir = workdir
-	untrackedOutput, err := cmd.Output()
-	if err == nil {
-		for _, line := range strings.Split(strings.TrimSpace(string(untrackedOutput)), "\n") {
-			line = strings.TrimSpace(line)
-			if line != "" {
+// getModifiedFiles returns the files modified in a worktree, staged,
+// unstaged or untracked.
+func getModifiedFiles(workdir string) []string {
+	var files []string
+	for _, args := range [][]string{
+		{"diff", "--name-only", "HEAD"},
+		{"ls-files", "--others", "--exclude-standard"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = workdir
+		output, err := cmd.Output()
+		if err != nil {
+			continue
+		}
+		for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+			if line = strings.TrimSpace(line); line != "" {
 				files = append(files, line)
 			}
 		}
 	}
-
-	return files, nil
+	return files
 }
 
 // intersection returns the common elements between two slices.
@@ -118,7 +275,6 @@ func intersection(a, b []string) []string {
 	for _, item := range a {
 		set[item] = true
 	}
-
 	var result []string
 	for _, item := range b {
 		if set[item] {
@@ -128,6 +284,114 @@ func intersection(a, b []string) []string {
 	return result
 }
 
+// branchTips returns the commit of every local and remote-tracking branch,
+// keyed by short name ("main", "origin/main").
+func branchTips(repoDir string) (map[string]string, error) {
+	cmd := exec.Command("git", "for-each-ref", "--format=%(objectname) %(refname:short)", "refs/heads", "refs/remotes")
+	cmd.Dir = repoDir
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("list branches: %w", err)
+	}
+	tips := make(map[string]string)
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		if sha, ref, ok := strings.Cut(line, " "); ok {
+			tips[ref] = sha
+		}
+	}
+	return tips, nil
+}
+
+// simulateMerge merges two commits with git merge-tree --write-tree (git
+// 2.38+), without touching any worktree, and extracts the conflict hunks
+// from the conflicted files in the resulting tree.
+func simulateMerge(repoDir, ours, theirs string) (mergeResult, error) {
+	cmd := exec.Command("git", "merge-tree", "--write-tree", "--name-only", "--no-messages", "-z", ours, theirs)
+	cmd.Dir = repoDir
+	output, err := cmd.Output()
+	if err != nil {
+		// Exit status 1 means the merge has conflicts; anything else failed
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) || exitErr.ExitCode() != 1 {
+			return mergeResult{}, fmt.Errorf("merge-tree: %w", err)
+		}
+	}
+
+	fields := strings.Split(string(output), "\x00")
+	tree := fields[0]
+	if tree == "" {
+		// Some failures, like an unknown commit, also exit with status 1
+		msg := "no tree written"
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+			msg = strings.TrimSpace(string(exitErr.Stderr))
+		}
+		return mergeResult{}, fmt.Errorf("merge-tree: %s", msg)
+	}
+	var res mergeResult
+	for _, file := range fields[1:] {
+		if file == "" || (len(res.files) > 0 && res.files[len(res.files)-1] == file) {
+			continue
+		}
+		res.files = append(res.files, file)
+
+		show := exec.Command("git", "cat-file", "blob", tree+":"+file)
+		show.Dir = repoDir
+		content, err := show.Output()
+		if err != nil {
+			continue // Deleted on one side: no text to show
+		}
+		res.hunks = append(res.hunks, parseConflictHunks(file, content)...)
+	}
+	return res, nil
+}
+
+// parseConflictHunks returns the marker-delimited regions of a merged file.
+func parseConflictHunks(file string, content []byte) []ConflictHunk {
+	var hunks []ConflictHunk
+	var current []string
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSuffix(line, "\r")
+		switch {
+		case current == nil && strings.HasPrefix(line, "<<<<<<< "):
+			current = []string{line}
+		case current != nil:
+			current = append(current, line)
+			if strings.HasPrefix(line, ">>>>>>> ") {
+				hunks = append(hunks, ConflictHunk{File: file, Lines: current})
+				current = nil
+			}
+		}
+	}
+	return hunks
+}
+
+// get returns a cached merge result.
+func (c *mergeCache) get(key string) (mergeResult, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	res, ok := c.entries[key]
+	return res, ok
+}
+
+// set stores a merge result.
+func (c *mergeCache) set(key string, res mergeResult) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[key] = res
+}
+
+// retain drops results for pairs that are no longer checked.
+func (c *mergeCache) retain(keys map[string]bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key := range c.entries {
+		if !keys[key] {
+			delete(c.entries, key)
+		}
+	}
+}
+
 // hasConflict checks if a worktree has any conflicts.
 func (p *Plugin) hasConflict(worktreeName string, conflicts []Conflict) bool {
 	for _, c := range conflicts {
@@ -185,3 +449,66 @@ func (p *Plugin) getConflictingWorktrees(worktreeName string, conflicts []Confli
 	}
 	return others
 }
+
+// conflictHunksForFile returns the hunks a worktree's file conflicts with,
+// each labelled with what it conflicts against (a worktree or base branch).
+func (p *Plugin) conflictHunksForFile(worktreeName, file string) (labels []string, hunks []ConflictHunk) {
+	for _, c := range p.conflicts {
+		label := c.Base
+		involved := false
+		for _, wt := range c.Worktrees {
+			if wt == worktreeName {
+				involved = true
+			} else {
+				label = wt
+			}
+		}
+		if !involved {
+			continue
+		}
+		for _, h := range c.Hunks {
+			if h.File == file {
+				labels = append(labels, label)
+				hunks = append(hunks, h)
+			}
+		}
+	}
+	return labels, hunks
+}
+
+// conflictSummary describes a worktree's predicted conflicts for the
+// sidebar, e.g. "⚠ 3 hunks vs main +1". Returns "" when there are none.
+func (p *Plugin) conflictSummary(worktreeName string) string {
+	files := p.getConflictingFiles(worktreeName, p.conflicts)
+	if len(files) == 0 {
+		return ""
+	}
+	hunks := 0
+	var others []string
+	for _, c := range p.conflicts {
+		if !p.hasConflict(worktreeName, []Conflict{c}) {
+			continue
+		}
+		hunks += len(c.Hunks)
+		if c.Base != "" {
+			others = append(others, c.Base)
+		}
+	}
+	others = append(others, p.getConflictingWorktrees(worktreeName, p.conflicts)...)
+
+	count, noun := len(files), "conflicting file"
+	if hunks > 0 {
+		count, noun = hunks, "conflict hunk"
+	}
+	if count != 1 {
+		noun += "s"
+	}
+	summary := fmt.Sprintf("⚠ %d %s", count, noun)
+	if len(others) > 0 {
+		summary += " vs " + others[0]
+		if len(others) > 1 {
+			summary += fmt.Sprintf(" +%d", len(others)-1)
+		}
+	}
+	return summary
+}
@@ -1,74 +1,184 @@
 package workspace
 
 import (
+	"os"
+	"os/exec"
+	"path/filepath"
 	"reflect"
 	"sort"
+	"strings"
 	"testing"
 )
 
-func TestIntersection(t *testing.T) {
+func TestParseConflictHunks(t *testing.T) {
+	content := "a\n<<<<<<< ours\nX\n=======\nY\n>>>>>>> theirs\nb\n<<<<<<< ours\n=======\nZ\n>>>>>>> theirs\n"
+	hunks := parseConflictHunks("f.txt", []byte(content))
+	if len(hunks) != 2 {
+		t.Fatalf("got %d hunks, want 2", len(hunks))
+	}
+	want := []string{"<<<<<<< ours", "X", "=======", "Y", ">>>>>>> theirs"}
+	if !reflect.DeepEqual(hunks[0].Lines, want) || hunks[0].File != "f.txt" {
+		t.Errorf("hunk 0 = %+v", hunks[0])
+	}
+	if len(hunks[1].Lines) != 4 {
+		t.Errorf("hunk 1 = %v", hunks[1].Lines)
+	}
+	if got := parseConflictHunks("f.txt", []byte("clean\n")); got != nil {
+		t.Errorf("clean file: got %v", got)
+	}
+}
+
+func TestDetectConflicts(t *testing.T) {
+	repo := t.TempDir()
+	run := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repo
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v: %s", args, err, out)
+		}
+	}
+	commit := func(branch, file, content string) {
+		t.Helper()
+		run("checkout", "-q", branch)
+		if err := os.WriteFile(filepath.Join(repo, file), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		run("add", file)
+		run("commit", "-q", "-m", branch+" "+file)
+	}
+	run("init", "-q", "-b", "main")
+	run("config", "user.email", "test@test.com")
+	run("config", "user.name", "Test")
+	if err := os.WriteFile(filepath.Join(repo, "shared.txt"), []byte("one\ntwo\nthree\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	run("add", ".")
+	run("commit", "-q", "-m", "initial")
+	run("branch", "feat-a")
+	run("branch", "feat-b")
+	run("branch", "feat-c")
+	commit("feat-a", "shared.txt", "one\nA\nthree\n")
+	commit("feat-b", "shared.txt", "one\nB\nthree\n")
+	// Same file, different region: a file-list overlap but not a conflict
+	commit("feat-c", "shared.txt", "one\ntwo\nthree\nfour\n")
+	commit("main", "shared.txt", "one\nMAIN\nthree\n")
+
+	cmd := exec.Command("git", "merge-tree", "--write-tree", "HEAD", "HEAD")
+	cmd.Dir = repo
+	if cmd.Run() != nil {
+		t.Skip("git merge-tree --write-tree not supported")
+	}
+
+	targets := []conflictTarget{
+		// A pair that can't be simulated must not hide the others
+		{name: "gone", branch: "feat-gone", base: "main"},
+		{name: "a", branch: "feat-a", base: "main"},
+		{name: "b", branch: "feat-b", base: "main"},
+		{name: "c", branch: "feat-c"},
+	}
+	tips, err := branchTips(repo)
+	if err != nil {
+		t.Fatalf("branchTips: %v", err)
+	}
+	tips["feat-gone"] = strings.Repeat("0", 39) + "1"
+	cache := &mergeCache{entries: make(map[string]mergeResult)}
+	conflicts := detectConflicts(repo, targets, tips, cache)
+
+	var got []string
+	for _, c := range conflicts {
+		label := strings.Join(c.Worktrees, "+")
+		if c.Base != "" {
+			label += "~" + c.Base
+		}
+		got = append(got, label)
+		if !reflect.DeepEqual(c.Files, []string{"shared.txt"}) || len(c.Hunks) != 1 {
+			t.Errorf("%s: files %v, %d hunks", label, c.Files, len(c.Hunks))
+		}
+	}
+	sort.Strings(got)
+	want := []string{"a+b", "a~main", "b~main"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("conflicts = %v, want %v", got, want)
+	}
+	if len(cache.entries) != 5 {
+		t.Errorf("cache has %d entries, want 5 (clean pairs are cached too)", len(cache.entries))
+	}
+
+	// Moving a tip drops its stale cache entries
+	commit("feat-c", "other.txt", "x\n")
+	if tips, err = branchTips(repo); err != nil {
+		t.Fatal(err)
+	}
+	detectConflicts(repo, targets[3:], tips, cache)
+	if len(cache.entries) != 0 {
+		t.Errorf("cache has %d entries after tips moved, want 0", len(cache.entries))
+	}
+}
+
+func TestGitVersionAtLeast(t *testing.T) {
 	tests := []struct {
-		name     string
-		a        []string
-		b        []string
-		expected []string
+		output string
+		want   bool
 	}{
-		{
-			name:     "no overlap",
-			a:        []string{"a", "b", "c"},
-			b:        []string{"d", "e", "f"},
-			expected: nil,
-		},
-		{
-			name:     "partial overlap",
-			a:        []string{"a", "b", "c"},
-			b:        []string{"b", "c", "d"},
-			expected: []string{"b", "c"},
-		},
-		{
-			name:     "full overlap",
-			a:        []string{"a", "b", "c"},
-			b:        []string{"a", "b", "c"},
-			expected: []string{"a", "b", "c"},
-		},
-		{
-			name:     "empty a",
-			a:        []string{},
-			b:        []string{"a", "b"},
-			expected: nil,
-		},
-		{
-			name:     "empty b",
-			a:        []string{"a", "b"},
-			b:        []string{},
-			expected: nil,
-		},
-		{
-			name:     "both empty",
-			a:        []string{},
-			b:        []string{},
-			expected: nil,
-		},
-		{
-			name:     "single element overlap",
-			a:        []string{"a", "b", "c"},
-			b:        []string{"b"},
-			expected: []string{"b"},
-		},
+		{"git version 2.38.0\n", true},
+		{"git version 2.39.3 (Apple Git-145)\n", true},
+		{"git version 3.0.0", true},
+		{"git version 2.37.1", false},
+		{"git version 2.34.1.windows.1", false},
+		{"garbage", false},
 	}
-
 	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			result := intersection(tt.a, tt.b)
+		if got := gitVersionAtLeast(tt.output, 2, 38); got != tt.want {
+			t.Errorf("gitVersionAtLeast(%q) = %v, want %v", tt.output, got, tt.want)
+		}
+	}
+}
 
-			// Sort both for comparison
-			sort.Strings(result)
-			sort.Strings(tt.expected)
+func TestDetectFileOverlaps(t *testing.T) {
+	var targets []conflictTarget
+	for _, name := range []string{"a", "b", "c"} {
+		dir := t.TempDir()
+		cmd := exec.Command("git", "init", "-q")
+		cmd.Dir = dir
+		if err := cmd.Run(); err != nil {
+			t.Fatalf("git init: %v", err)
+		}
+		file := "shared.txt"
+		if name == "c" {
+			file = "other.txt"
+		}
+		if err := os.WriteFile(filepath.Join(dir, file), []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+		targets = append(targets, conflictTarget{name: name, path: dir})
+	}
+	conflicts := detectFileOverlaps(targets)
+	if len(conflicts) != 1 || !reflect.DeepEqual(conflicts[0].Worktrees, []string{"a", "b"}) ||
+		!reflect.DeepEqual(conflicts[0].Files, []string{"shared.txt"}) {
+		t.Errorf("conflicts = %+v, want a+b on shared.txt", conflicts)
+	}
+}
 
-			if !reflect.DeepEqual(result, tt.expected) {
-				t.Errorf("intersection(%v, %v) = %v, want %v", tt.a, tt.b, result, tt.expected)
-			}
-		})
+func TestConflictSummary(t *testing.T) {
+	p := &Plugin{conflicts: []Conflict{
+		{Worktrees: []string{"wt1"}, Base: "main", Files: []string{"a"}, Hunks: []ConflictHunk{{File: "a"}, {File: "a"}}},
+		{Worktrees: []string{"wt1", "wt2"}, Files: []string{"a", "b"}, Hunks: []ConflictHunk{{File: "b"}}},
+		{Worktrees: []string{"wt3", "wt2"}, Files: []string{"gone"}},
+		{Worktrees: []string{"wt5"}, Base: "main", Files: []string{"c"}, Hunks: []ConflictHunk{{File: "c"}}},
+		{Worktrees: []string{"wt6", "wt7"}, Files: []string{"d", "e"}},
+	}}
+	tests := map[string]string{
+		"wt1": "⚠ 3 conflict hunks vs main +1",
+		"wt3": "⚠ 1 conflicting file vs wt2",
+		"wt5": "⚠ 1 conflict hunk vs main",
+		"wt6": "⚠ 2 conflicting files vs wt7",
+		"wt4": "",
+	}
+	for wt, want := range tests {
+		if got := p.conflictSummary(wt); got != want {
+			t.Errorf("conflictSummary(%s) = %q, want %q", wt, got, want)
+		}
 	}
 }
 
@@ -0,0 +1,305 @@
+package workspace
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/marcus/sidecar/internal/adapter"
+	"github.com/marcus/sidecar/internal/adapter/contextwindow"
+	appmsg "github.com/marcus/sidecar/internal/msg"
+	"github.com/marcus/sidecar/internal/plugin"
+)
+
+// contextCheckInterval throttles context usage checks per worktree. Usage
+// only changes once per model response, so polling-rate checks are wasted.
+const contextCheckInterval = 10 * time.Second
+
+// ContextUsage is how full a running agent's session context window is.
+type ContextUsage struct {
+	Used int // Tokens in context after the last response
+	Size int // Context window size of the session's model
+}
+
+// Percent returns the fill of the context window, 0-100.
+func (u ContextUsage) Percent() int {
+	return int(contextwindow.Point{Used: u.Used, Size: u.Size}.Percent())
+}
+
+// contextUsageState tracks the latest usage and warning state for a worktree.
+type contextUsageState struct {
+	usage     *ContextUsage
+	checkedAt time.Time
+	warned    bool // threshold toast already shown for this crossing
+}
+
+// ContextUsageMsg delivers context window usage for a worktree's agent session.
+type ContextUsageMsg struct {
+	Epoch         uint64
+	WorkspaceName string
+	Usage         *ContextUsage // nil if the session could not be read
+}
+
+// GetEpoch implements plugin.EpochMessage.
+func (m ContextUsageMsg) GetEpoch() uint64 { return m.Epoch }
+
+// contextWarnPercent returns the configured warning threshold, 0 if disabled.
+func (p *Plugin) contextWarnPercent() int {
+	if p.ctx == nil || p.ctx.Config == nil {
+		return 0
+	}
+	return p.ctx.Config.Plugins.Workspace.ContextWarnPercent
+}
+
+// contextUsageFor returns the last known context usage for a worktree.
+func (p *Plugin) contextUsageFor(name string) *ContextUsage {
+	if st := p.contextUsage[name]; st != nil {
+		return st.usage
+	}
+	return nil
+}
+
+// contextWarning reports whether a worktree's agent session has passed the
+// configured context threshold.
+func (p *Plugin) contextWarning(wt *Worktree) (ContextUsage, bool) {
+	threshold := p.contextWarnPercent()
+	if threshold <= 0 || wt.Agent == nil {
+		return ContextUsage{}, false
+	}
+	u := p.contextUsageFor(wt.Name)
+	if u == nil || u.Percent() < threshold {
+		return ContextUsage{}, false
+	}
+	return *u, true
+}
+
+// maybeCheckContextUsage returns a command reading the agent session's
+// context usage, at most once per contextCheckInterval per worktree.
+func (p *Plugin) maybeCheckContextUsage(workspaceName string) tea.Cmd {
+	if p.contextWarnPercent() <= 0 {
+		return nil
+	}
+	wt := p.findWorktree(workspaceName)
+	if wt == nil || wt.Agent == nil {
+		return nil
+	}
+	if p.contextUsage == nil {
+		p.contextUsage = make(map[string]*contextUsageState)
+	}
+	st := p.contextUsage[workspaceName]
+	if st == nil {
+		st = &contextUsageState{}
+		p.contextUsage[workspaceName] = st
+	}
+	if time.Since(st.checkedAt) < contextCheckInterval {
+		return nil
+	}
+	st.checkedAt = time.Now()
+
+	epoch := p.ctx.Epoch
+	agentType, path := wt.Agent.Type, wt.Path
+	return func() tea.Msg {
+		var usage *ContextUsage
+		if u, ok := detectAgentContextUsage(agentType, path); ok {
+			usage = &u
+		}
+		return ContextUsageMsg{Epoch: epoch, WorkspaceName: workspaceName, Usage: usage}
+	}
+}
+
+// handleContextUsage stores usage and toasts once when the threshold is crossed.
+func (p *Plugin) handleContextUsage(msg ContextUsageMsg) tea.Cmd {
+	if plugin.IsStale(p.ctx, msg) {
+		return nil
+	}
+	st := p.contextUsage[msg.WorkspaceName]
+	if st == nil {
+		return nil
+	}
+	st.usage = msg.Usage
+
+	threshold := p.contextWarnPercent()
+	if msg.Usage == nil || threshold <= 0 || msg.Usage.Percent() < threshold {
+		// Re-arm after compaction or a new session drops usage below threshold
+		st.warned = false
+		return nil
+	}
+	if st.warned {
+		return nil
+	}
+	st.warned = true
+	return appmsg.ShowToast(fmt.Sprintf("%s: context %d%% full", msg.WorkspaceName, msg.Usage.Percent()), 4*time.Second)
+}
+
+// contextUsageCacheEntry caches parsed usage for an unchanged session file.
+type contextUsageCacheEntry struct {
+	modTime time.Time
+	size    int64
+	usage   ContextUsage
+	ok      bool
+}
+
+var contextUsageCache = struct {
+	mu      sync.Mutex
+	entries map[string]contextUsageCacheEntry
+}{
+	entries: make(map[string]contextUsageCacheEntry),
+}
+
+// detectAgentContextUsage finds the agent's current session file for a
+// worktree and reads its latest context usage. Claude Code and Codex are
+// supported; other agents report ok=false.
+func detectAgentContextUsage(agentType AgentType, worktreePath string) (ContextUsage, bool) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ContextUsage{}, false
+	}
+	absPath, err := filepath.Abs(worktreePath)
+	if err != nil {
+		return ContextUsage{}, false
+	}
+
+	var sessionFile string
+	var parse func(lines []string) (ContextUsage, bool)
+	switch agentType {
+	case AgentClaude:
+		projectDir := filepath.Join(home, ".claude", "projects", claudeProjectDirName(absPath))
+		files, err := findRecentJSONLFiles(projectDir, "agent-")
+		if err != nil || len(files) == 0 {
+			return ContextUsage{}, false
+		}
+		sessionFile, parse = files[0], parseClaudeContextUsage
+	case AgentCodex:
+		sessionFile, err = findCodexSessionForPath(filepath.Join(home, ".codex", "sessions"), absPath)
+		if err != nil || sessionFile == "" {
+			return ContextUsage{}, false
+		}
+		parse = parseCodexContextUsage
+	default:
+		return ContextUsage{}, false
+	}
+	return cachedContextUsage(sessionFile, parse)
+}
+
+// cachedContextUsage parses the tail of path unless its size and mtime are
+// unchanged since the last parse.
+func cachedContextUsage(path string, parse func(lines []string) (ContextUsage, bool)) (ContextUsage, bool) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return ContextUsage{}, false
+	}
+
+	contextUsageCache.mu.Lock()
+	entry, hit := contextUsageCache.entries[path]
+	contextUsageCache.mu.Unlock()
+	if hit && entry.size == info.Size() && entry.modTime.Equal(info.ModTime()) {
+		return entry.usage, entry.ok
+	}
+
+	lines, err := readTailLines(path, sessionStatusTailBytes)
+	if err != nil {
+		return ContextUsage{}, false
+	}
+	usage, ok := parse(lines)
+
+	contextUsageCache.mu.Lock()
+	contextUsageCache.entries[path] = contextUsageCacheEntry{modTime: info.ModTime(), size: info.Size(), usage: usage, ok: ok}
+	contextUsageCache.mu.Unlock()
+	return usage, ok
+}
+
+// parseClaudeContextUsage returns usage from the last assistant entry with
+// token counts. A compaction boundary after it means the context was just
+// reset, which reports zero usage.
+func parseClaudeContextUsage(lines []string) (ContextUsage, bool) {
+	for i := len(lines) - 1; i >= 0; i-- {
+		line := strings.TrimSpace(lines[i])
+		if line == "" {
+			continue
+		}
+		var entry struct {
+			Type             string `json:"type"`
+			Subtype          string `json:"subtype"`
+			IsCompactSummary bool   `json:"isCompactSummary"`
+			Message          struct {
+				Model string `json:"model"`
+				Usage struct {
+					InputTokens              int `json:"input_tokens"`
+					OutputTokens             int `json:"output_tokens"`
+					CacheReadInputTokens     int `json:"cache_read_input_tokens"`
+					CacheCreationInputTokens int `json:"cache_creation_input_tokens"`
+				} `json:"usage"`
+			} `json:"message"`
+		}
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			continue
+		}
+		if entry.IsCompactSummary || entry.Subtype == "compact_boundary" {
+			return ContextUsage{Size: contextwindow.DefaultSize}, true
+		}
+		if entry.Type != "assistant" {
+			continue
+		}
+		u := entry.Message.Usage
+		used := contextwindow.Used(entry.Message.Model, adapter.TokenUsage{
+			InputTokens:  u.InputTokens,
+			OutputTokens: u.OutputTokens,
+			CacheRead:    u.CacheReadInputTokens,
+			CacheWrite:   u.CacheCreationInputTokens,
+		})
+		if used == 0 {
+			continue // placeholder or synthetic entry
+		}
+		return ContextUsage{Used: used, Size: contextwindow.Size(entry.Message.Model)}, true
+	}
+	return ContextUsage{}, false
+}
+
+// parseCodexContextUsage returns usage from the last token_count event,
+// using the context window Codex reports when present. A later "compacted"
+// record means the context was just reset.
+func parseCodexContextUsage(lines []string) (ContextUsage, bool) {
+	for i := len(lines) - 1; i >= 0; i-- {
+		line := strings.TrimSpace(lines[i])
+		if line == "" {
+			continue
+		}
+		var record struct {
+			Type    string `json:"type"`
+			Payload struct {
+				Type string `json:"type"`
+				Info *struct {
+					LastTokenUsage *struct {
+						InputTokens  int `json:"input_tokens"`
+						OutputTokens int `json:"output_tokens"`
+					} `json:"last_token_usage"`
+					ModelContextWindow int `json:"model_context_window"`
+				} `json:"info"`
+			} `json:"payload"`
+		}
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			continue
+		}
+		if record.Type == "compacted" {
+			return ContextUsage{Size: contextwindow.Size("codex")}, true
+		}
+		if record.Type != "event_msg" || record.Payload.Type != "token_count" {
+			continue
+		}
+		info := record.Payload.Info
+		if info == nil || info.LastTokenUsage == nil {
+			continue
+		}
+		size := info.ModelContextWindow
+		if size <= 0 {
+			size = contextwindow.Size("codex")
+		}
+		return ContextUsage{Used: info.LastTokenUsage.InputTokens + info.LastTokenUsage.OutputTokens, Size: size}, true
+	}
+	return ContextUsage{}, false
+}
@@ -0,0 +1,85 @@
+package workspace
+
+import (
+	"testing"
+
+	"github.com/marcus/sidecar/internal/config"
+	"github.com/marcus/sidecar/internal/plugin"
+)
+
+func TestParseClaudeContextUsage(t *testing.T) {
+	lines := []string{
+		`{"type":"user","message":{"role":"user","content":"go"}}`,
+		`{"type":"assistant","message":{"model":"claude-sonnet-4-5","usage":{"input_tokens":10,"cache_read_input_tokens":150000,"cache_creation_input_tokens":9990,"output_tokens":10000}}}`,
+		`{"type":"assistant","message":{"model":"<synthetic>","usage":{"input_tokens":0,"output_tokens":0}}}`,
+		``,
+	}
+	u, ok := parseClaudeContextUsage(lines)
+	if !ok || u.Used != 170000 || u.Size != 200000 || u.Percent() != 85 {
+		t.Fatalf("parseClaudeContextUsage() = %+v, %v", u, ok)
+	}
+
+	// A compaction after the last response resets usage
+	lines = append(lines, `{"type":"system","subtype":"compact_boundary"}`)
+	if u, ok := parseClaudeContextUsage(lines); !ok || u.Used != 0 {
+		t.Errorf("expected reset after compaction, got %+v, %v", u, ok)
+	}
+
+	if _, ok := parseClaudeContextUsage([]string{`{"type":"user"}`}); ok {
+		t.Error("expected no usage without assistant entries")
+	}
+}
+
+func TestParseCodexContextUsage(t *testing.T) {
+	lines := []string{
+		`{"type":"event_msg","payload":{"type":"token_count","info":{"last_token_usage":{"input_tokens":120000,"cached_input_tokens":100000,"output_tokens":2000},"model_context_window":244000}}}`,
+		`{"type":"event_msg","payload":{"type":"token_count","info":null}}`,
+	}
+	u, ok := parseCodexContextUsage(lines)
+	if !ok || u.Used != 122000 || u.Size != 244000 || u.Percent() != 50 {
+		t.Fatalf("parseCodexContextUsage() = %+v, %v", u, ok)
+	}
+
+	lines = append(lines, `{"type":"compacted","payload":{"message":"summary"}}`)
+	if u, ok := parseCodexContextUsage(lines); !ok || u.Used != 0 {
+		t.Errorf("expected reset after compaction, got %+v, %v", u, ok)
+	}
+}
+
+func TestHandleContextUsage_WarnsOnce(t *testing.T) {
+	cfg := config.Default()
+	p := &Plugin{
+		ctx:          &plugin.Context{Config: cfg},
+		contextUsage: map[string]*contextUsageState{"wt": {}},
+	}
+	wt := &Worktree{Name: "wt", Agent: &Agent{Type: AgentClaude}}
+
+	if cmd := p.handleContextUsage(ContextUsageMsg{WorkspaceName: "wt", Usage: &ContextUsage{Used: 50, Size: 100}}); cmd != nil {
+		t.Error("expected no toast below threshold")
+	}
+	if _, warn := p.contextWarning(wt); warn {
+		t.Error("expected no sidebar warning below threshold")
+	}
+
+	high := ContextUsageMsg{WorkspaceName: "wt", Usage: &ContextUsage{Used: 90, Size: 100}}
+	if cmd := p.handleContextUsage(high); cmd == nil {
+		t.Error("expected toast when crossing threshold")
+	}
+	if cmd := p.handleContextUsage(high); cmd != nil {
+		t.Error("expected toast only once per crossing")
+	}
+	if u, warn := p.contextWarning(wt); !warn || u.Percent() != 90 {
+		t.Errorf("expected sidebar warning at 90%%, got %+v %v", u, warn)
+	}
+
+	// Dropping below (e.g. after compaction) re-arms the toast
+	p.handleContextUsage(ContextUsageMsg{WorkspaceName: "wt", Usage: &ContextUsage{Used: 5, Size: 100}})
+	if cmd := p.handleContextUsage(high); cmd == nil {
+		t.Error("expected toast after re-crossing threshold")
+	}
+
+	cfg.Plugins.Workspace.ContextWarnPercent = 0
+	if _, warn := p.contextWarning(wt); warn {
+		t.Error("threshold 0 should disable the warning")
+	}
+}
@@ -23,6 +23,7 @@ const (
 	createBranchItemPrefix      = "create-branch-"
 	createTaskItemPrefix        = "create-task-item-"
 	createAgentItemPrefix       = "create-agent-"
+	createPresetItemPrefix      = "create-preset-"
 )
 
 func createIndexedID(prefix string, idx int) string {
@@ -68,6 +69,8 @@ func (p *Plugin) ensureCreateModal() {
 		modal.WithPrimaryAction(createSubmitID),
 		modal.WithHints(false),
 	).
+		AddSection(p.createPresetSection()).
+		AddSection(modal.When(func() bool { return len(p.workspacePresets()) > 0 }, modal.Spacer())).
 		AddSection(p.createNameLabelSection()).
 		AddSection(modal.Input(createNameFieldID, &p.createNameInput, modal.WithSubmitOnEnter(false))).
 		AddSection(p.createNameErrorsSection()).
@@ -88,6 +91,8 @@ func (p *Plugin) ensureCreateModal() {
 		AddSection(modal.Spacer()).
 		AddSection(p.createErrorSection()).
 		AddSection(modal.When(func() bool { return p.createError != "" }, modal.Spacer())).
+		AddSection(p.createProvisionSection()).
+		AddSection(modal.When(func() bool { return len(p.createProvision) > 0 }, modal.Spacer())).
 		AddSection(modal.Buttons(
 			modal.Btn(" Create ", createSubmitID),
 			modal.Btn(" Cancel ", createCancelID),
@@ -151,6 +156,47 @@ func (p *Plugin) createFocusID() string {
 	}
 }
 
+// createPresetSection renders the configured presets as a clickable row,
+// with the applied one highlighted.
+func (p *Plugin) createPresetSection() modal.Section {
+	return modal.Custom(func(contentWidth int, focusID, hoverID string) modal.RenderedSection {
+		presets := p.workspacePresets()
+		if len(presets) == 0 {
+			return modal.RenderedSection{}
+		}
+
+		line := "Preset: "
+		x := ansi.StringWidth(line)
+		var focusables []modal.FocusableInfo
+		for i := -1; i < len(presets); i++ {
+			name := "none"
+			if i >= 0 {
+				name = presets[i].Name
+				if presets[i].Key != "" {
+					name += " (" + presets[i].Key + ")"
+				}
+			}
+			chip := " " + name + " "
+			id := createIndexedID(createPresetItemPrefix, i+1)
+			switch {
+			case i == p.createPresetIdx:
+				chip = lipgloss.NewStyle().Foreground(styles.Primary).Bold(true).Render("[" + chip + "]")
+			case id == hoverID:
+				chip = " " + chip + " "
+			default:
+				chip = dimText(" " + chip + " ")
+			}
+			w := ansi.StringWidth(chip)
+			focusables = append(focusables, modal.FocusableInfo{ID: id, OffsetX: x, OffsetY: 0, Width: w, Height: 1})
+			line += chip
+			x += w
+		}
+		line += dimText("  ctrl+p")
+
+		return modal.RenderedSection{Content: line, Focusables: focusables}
+	}, nil)
+}
+
 func (p *Plugin) createNameLabelSection() modal.Section {
 	return modal.Custom(func(contentWidth int, focusID, hoverID string) modal.RenderedSection {
 		label := "Name:"
@@ -484,6 +530,60 @@ func (p *Plugin) createSkipPermissionsHintSection() modal.Section {
 	}, nil)
 }
 
+// createProvisionSection shows setup step progress while the worktree is
+// provisioned, and any failures afterwards.
+func (p *Plugin) createProvisionSection() modal.Section {
+	return modal.Custom(func(contentWidth int, focusID, hoverID string) modal.RenderedSection {
+		if len(p.createProvision) == 0 {
+			return modal.RenderedSection{}
+		}
+		lines := []string{lipgloss.NewStyle().Bold(true).Render("Setup")}
+		for _, s := range p.createProvision {
+			lines = append(lines, renderProvisionStatus(s, contentWidth))
+		}
+		if p.createProvisionDone != nil {
+			errStyle := lipgloss.NewStyle().Foreground(styles.Error)
+			lines = append(lines, "", errStyle.Render(fmt.Sprintf("%d setup step(s) failed.", provisionFailures(p.createProvision)))+
+				dimText(" Enter to continue"))
+		}
+		return modal.RenderedSection{Content: strings.Join(lines, "\n")}
+	}, nil)
+}
+
+// renderProvisionStatus renders one step line: icon, name, detail and time.
+func renderProvisionStatus(s ProvisionStepStatus, width int) string {
+	var icon string
+	switch s.State {
+	case ProvisionRunning:
+		icon = lipgloss.NewStyle().Foreground(styles.Warning).Render("●")
+	case ProvisionDone:
+		icon = lipgloss.NewStyle().Foreground(styles.Success).Render("✓")
+	case ProvisionSkipped:
+		icon = dimText("–")
+	case ProvisionFailed:
+		icon = lipgloss.NewStyle().Foreground(styles.Error).Render("✗")
+	default:
+		icon = dimText("○")
+	}
+
+	var suffix string
+	if s.Detail != "" {
+		suffix = " " + s.Detail
+	}
+	if s.Elapsed > 0 {
+		suffix += fmt.Sprintf(" (%.1fs)", s.Elapsed.Seconds())
+	}
+	line := "  " + icon + " " + s.Name
+	if suffix == "" {
+		return ansi.Truncate(line, width, "…")
+	}
+	styled := dimText(suffix)
+	if s.State == ProvisionFailed {
+		styled = lipgloss.NewStyle().Foreground(styles.Error).Render(suffix)
+	}
+	return ansi.Truncate(line+styled, width, "…")
+}
+
 func (p *Plugin) createErrorSection() modal.Section {
 	return modal.Custom(func(contentWidth int, focusID, hoverID string) modal.RenderedSection {
 		if p.createError == "" {
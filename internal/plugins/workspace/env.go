@@ -80,7 +80,28 @@ func parseWorktreeEnvFile(mainRepoPath string) (map[string]string, error) {
 
 // BuildEnvOverrides returns the combined environment overrides for a worktree.
 // User overrides from .worktree-env take precedence over defaults.
+// Allocator specs (alloc:, slug:) are omitted; use BuildWorktreeEnvOverrides
+// to resolve them for a specific worktree.
 func BuildEnvOverrides(mainRepoPath string) map[string]string {
+	result := buildEnvOverrides(mainRepoPath)
+	for k, v := range result {
+		if isEnvAllocator(v) {
+			delete(result, k)
+		}
+	}
+	return result
+}
+
+// BuildWorktreeEnvOverrides returns the environment overrides for a specific
+// worktree, with allocator specs resolved to values reserved for it.
+func BuildWorktreeEnvOverrides(mainRepoPath, projectRoot, worktreePath, branch string) map[string]string {
+	result := buildEnvOverrides(mainRepoPath)
+	resolveEnvAllocators(result, projectRoot, worktreePath, branch)
+	return result
+}
+
+// buildEnvOverrides merges defaults with .worktree-env, leaving allocator specs unresolved.
+func buildEnvOverrides(mainRepoPath string) map[string]string {
 	// Start with defaults
 	result := make(map[string]string, len(DefaultEnvOverrides))
 	for k, v := range DefaultEnvOverrides {
@@ -0,0 +1,218 @@
+package workspace
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+
+	"github.com/marcus/sidecar/internal/projectdir"
+)
+
+const (
+	// envAllocFile stores allocated env values in the project state directory.
+	envAllocFile = "env-allocations.json"
+
+	// allocPrefix declares a numeric allocator: KEY=alloc:3000-3999 picks the
+	// lowest value in the range not held by another worktree.
+	allocPrefix = "alloc:"
+	// slugPrefix declares a templated value: KEY=slug:app_{branch} expands
+	// {branch}, {name} and {repo} into an identifier-safe slug.
+	slugPrefix = "slug:"
+)
+
+// envAllocMu serializes registry updates; agents for several worktrees can
+// start concurrently (fan-out).
+var envAllocMu sync.Mutex
+
+// envAllocations maps worktree path -> env key -> allocated value.
+type envAllocations map[string]map[string]string
+
+var slugInvalidChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+// isEnvAllocator reports whether an env value is an allocator spec.
+func isEnvAllocator(value string) bool {
+	return strings.HasPrefix(value, allocPrefix) || strings.HasPrefix(value, slugPrefix)
+}
+
+// parseAllocRange parses the "3000-3999" part of an alloc spec.
+func parseAllocRange(spec string) (lo, hi int, ok bool) {
+	loStr, hiStr, found := strings.Cut(strings.TrimPrefix(spec, allocPrefix), "-")
+	if !found {
+		return 0, 0, false
+	}
+	lo, err1 := strconv.Atoi(strings.TrimSpace(loStr))
+	hi, err2 := strconv.Atoi(strings.TrimSpace(hiStr))
+	if err1 != nil || err2 != nil || lo < 0 || hi < lo {
+		return 0, 0, false
+	}
+	return lo, hi, true
+}
+
+// expandSlug expands a slug spec for a worktree. The result is lowercase with
+// runs of other characters collapsed to "_", so it is safe for database,
+// container and similar names.
+func expandSlug(spec, repo, worktreePath, branch string) string {
+	value := strings.NewReplacer(
+		"{branch}", branch,
+		"{name}", filepath.Base(worktreePath),
+		"{repo}", repo,
+	).Replace(strings.TrimPrefix(spec, slugPrefix))
+	return strings.Trim(slugInvalidChars.ReplaceAllString(strings.ToLower(value), "_"), "_")
+}
+
+// portInUse reports whether value is a TCP port something is listening on.
+// Permission errors (privileged ports) don't count as in use.
+func portInUse(value int) bool {
+	if value < 1 || value > 65535 {
+		return false
+	}
+	ln, err := net.Listen("tcp", ":"+strconv.Itoa(value))
+	if err != nil {
+		return errors.Is(err, syscall.EADDRINUSE)
+	}
+	_ = ln.Close()
+	return false
+}
+
+// loadEnvAllocations reads the project's allocation registry.
+func loadEnvAllocations(dir string) envAllocations {
+	allocs := envAllocations{}
+	data, err := os.ReadFile(filepath.Join(dir, envAllocFile))
+	if err != nil {
+		return allocs
+	}
+	_ = json.Unmarshal(data, &allocs)
+	return allocs
+}
+
+// saveEnvAllocations writes the project's allocation registry.
+func saveEnvAllocations(dir string, allocs envAllocations) error {
+	data, err := json.MarshalIndent(allocs, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, envAllocFile), data, 0644)
+}
+
+// resolveEnvAllocators replaces allocator specs in overrides with values
+// allocated to the worktree, recording them in the project registry.
+// Values a worktree already holds are kept. Specs that can't be satisfied
+// (malformed, range exhausted) are dropped rather than exported verbatim.
+func resolveEnvAllocators(overrides map[string]string, projectRoot, worktreePath, branch string) {
+	var keys []string
+	for k, v := range overrides {
+		if isEnvAllocator(v) {
+			keys = append(keys, k)
+		}
+	}
+	if len(keys) == 0 {
+		return
+	}
+	sort.Strings(keys) // Deterministic allocation order
+
+	envAllocMu.Lock()
+	defer envAllocMu.Unlock()
+
+	dir, err := projectdir.Resolve(projectRoot)
+	if err != nil {
+		for _, k := range keys {
+			delete(overrides, k)
+		}
+		return
+	}
+	allocs := loadEnvAllocations(dir)
+
+	// Values held by other worktrees that still exist
+	used := make(map[string]bool)
+	for path, vals := range allocs {
+		if path == worktreePath {
+			continue
+		}
+		if _, err := os.Stat(path); err != nil {
+			delete(allocs, path) // Worktree removed outside sidecar
+			continue
+		}
+		for _, v := range vals {
+			used[v] = true
+		}
+	}
+
+	held := allocs[worktreePath]
+	mine := make(map[string]string, len(keys))
+	repo := filepath.Base(projectRoot)
+	for _, k := range keys {
+		spec := overrides[k]
+		delete(overrides, k)
+
+		if strings.HasPrefix(spec, slugPrefix) {
+			if v := expandSlug(spec, repo, worktreePath, branch); v != "" {
+				mine[k] = v
+			}
+			continue
+		}
+
+		lo, hi, ok := parseAllocRange(spec)
+		if !ok {
+			continue
+		}
+		if prev, err := strconv.Atoi(held[k]); err == nil && prev >= lo && prev <= hi && !used[held[k]] {
+			mine[k] = held[k]
+			used[held[k]] = true
+			continue
+		}
+		for n := lo; n <= hi; n++ {
+			v := strconv.Itoa(n)
+			if used[v] || portInUse(n) {
+				continue
+			}
+			mine[k] = v
+			used[v] = true
+			break
+		}
+	}
+
+	for k, v := range mine {
+		overrides[k] = v
+	}
+	if len(mine) == 0 {
+		delete(allocs, worktreePath)
+	} else {
+		allocs[worktreePath] = mine
+	}
+	_ = saveEnvAllocations(dir, allocs)
+}
+
+// releaseEnvAllocations frees every value allocated to a worktree.
+func releaseEnvAllocations(projectRoot, worktreePath string) error {
+	envAllocMu.Lock()
+	defer envAllocMu.Unlock()
+
+	dir, err := projectdir.Resolve(projectRoot)
+	if err != nil {
+		return err
+	}
+	allocs := loadEnvAllocations(dir)
+	if _, ok := allocs[worktreePath]; !ok {
+		return nil
+	}
+	delete(allocs, worktreePath)
+	return saveEnvAllocations(dir, allocs)
+}
+
+// releaseWorktreeEnv frees a removed worktree's allocated env values, logging failures.
+func (p *Plugin) releaseWorktreeEnv(worktreePath string) {
+	if worktreePath == "" {
+		return
+	}
+	if err := releaseEnvAllocations(p.ctx.ProjectRoot, worktreePath); err != nil {
+		p.ctx.Logger.Warn("failed to release env allocations", "path", worktreePath, "error", err)
+	}
+}
@@ -0,0 +1,115 @@
+package workspace
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/marcus/sidecar/internal/config"
+)
+
+// writeAllocEnv writes a .worktree-env with allocator specs and returns the
+// repo path plus two existing worktree paths.
+func writeAllocEnv(t *testing.T, content string) (string, string, string) {
+	t.Helper()
+	config.SetTestStateDir(t.TempDir())
+	root := t.TempDir()
+	repo := filepath.Join(root, "myapp")
+	wt1 := filepath.Join(root, "myapp-feature-a")
+	wt2 := filepath.Join(root, "myapp-feature-b")
+	for _, dir := range []string{repo, wt1, wt2} {
+		if err := os.Mkdir(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(repo, worktreeEnvFile), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return repo, wt1, wt2
+}
+
+func TestBuildWorktreeEnvOverrides_AllocUnique(t *testing.T) {
+	repo, wt1, wt2 := writeAllocEnv(t, "PORT=alloc:41000-41999\nAPI_PORT=alloc:41000-41999\n")
+
+	first := BuildWorktreeEnvOverrides(repo, repo, wt1, "feature/a")
+	second := BuildWorktreeEnvOverrides(repo, repo, wt2, "feature/b")
+
+	seen := map[string]bool{}
+	for _, env := range []map[string]string{first, second} {
+		for _, key := range []string{"PORT", "API_PORT"} {
+			v := env[key]
+			if v == "" {
+				t.Fatalf("%s not allocated: %v", key, env)
+			}
+			if seen[v] {
+				t.Errorf("value %s allocated twice", v)
+			}
+			seen[v] = true
+		}
+	}
+
+	// Allocations are stable across calls
+	again := BuildWorktreeEnvOverrides(repo, repo, wt1, "feature/a")
+	if again["PORT"] != first["PORT"] || again["API_PORT"] != first["API_PORT"] {
+		t.Errorf("reallocated: first=%v again=%v", first, again)
+	}
+}
+
+func TestBuildWorktreeEnvOverrides_Release(t *testing.T) {
+	repo, wt1, wt2 := writeAllocEnv(t, "PORT=alloc:41000-41999\n")
+
+	first := BuildWorktreeEnvOverrides(repo, repo, wt1, "a")
+	if err := releaseEnvAllocations(repo, wt1); err != nil {
+		t.Fatal(err)
+	}
+	second := BuildWorktreeEnvOverrides(repo, repo, wt2, "b")
+	if second["PORT"] != first["PORT"] {
+		t.Errorf("released port %s not reused, got %s", first["PORT"], second["PORT"])
+	}
+}
+
+func TestBuildWorktreeEnvOverrides_PrunesMissingWorktrees(t *testing.T) {
+	repo, wt1, wt2 := writeAllocEnv(t, "PORT=alloc:41000-41999\n")
+
+	first := BuildWorktreeEnvOverrides(repo, repo, wt1, "a")
+	if err := os.Remove(wt1); err != nil {
+		t.Fatal(err)
+	}
+	second := BuildWorktreeEnvOverrides(repo, repo, wt2, "b")
+	if second["PORT"] != first["PORT"] {
+		t.Errorf("port of removed worktree %s not reused, got %s", first["PORT"], second["PORT"])
+	}
+}
+
+func TestBuildWorktreeEnvOverrides_Slug(t *testing.T) {
+	repo, wt1, _ := writeAllocEnv(t, "DB_NAME=slug:{repo}_{branch}\nDIR=slug:{name}\nBAD=alloc:9-1\n")
+
+	env := BuildWorktreeEnvOverrides(repo, repo, wt1, "Feature/Login-Page")
+	if env["DB_NAME"] != "myapp_feature_login_page" {
+		t.Errorf("DB_NAME = %q", env["DB_NAME"])
+	}
+	if env["DIR"] != "myapp_feature_a" {
+		t.Errorf("DIR = %q", env["DIR"])
+	}
+	if _, ok := env["BAD"]; ok {
+		t.Errorf("malformed spec should be dropped, got %q", env["BAD"])
+	}
+	if env["GOWORK"] != "off" {
+		t.Error("defaults should still apply")
+	}
+}
+
+func TestBuildEnvOverrides_OmitsAllocators(t *testing.T) {
+	repo, _, _ := writeAllocEnv(t, "PORT=alloc:3000-3999\nDB_NAME=slug:{branch}\nFOO=bar\n")
+
+	env := BuildEnvOverrides(repo)
+	if _, ok := env["PORT"]; ok {
+		t.Error("PORT spec should be omitted")
+	}
+	if _, ok := env["DB_NAME"]; ok {
+		t.Error("DB_NAME spec should be omitted")
+	}
+	if env["FOO"] != "bar" {
+		t.Errorf("FOO = %q, want bar", env["FOO"])
+	}
+}
@@ -0,0 +1,661 @@
+package workspace
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+	"unicode/utf8"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/marcus/sidecar/internal/adapter"
+	appmsg "github.com/marcus/sidecar/internal/msg"
+	"github.com/marcus/sidecar/internal/plugin"
+	"github.com/marcus/sidecar/internal/projectdir"
+	"github.com/marcus/sidecar/internal/sessionquery"
+)
+
+const (
+	// fanOutFile stores fan-out groups in the project state directory.
+	fanOutFile = "fanouts.json"
+
+	// maxFanOutMembers caps how many worktrees one fan-out may create.
+	maxFanOutMembers = 8
+
+	// sidecarTestFile overrides the configured test command for a worktree.
+	// Like .sidecar-agent-start it lives in the worktree root so it can be
+	// checked in per repo.
+	sidecarTestFile = ".sidecar-test"
+
+	// testTimeout bounds a single test command run.
+	testTimeout = 10 * time.Minute
+
+	// testOutputTailBytes is how much test output is kept for display.
+	testOutputTailBytes = 4096
+
+	// testWaitDelay bounds how long a killed test command may hold its
+	// output pipes open.
+	testWaitDelay = 5 * time.Second
+)
+
+// FanOutMember is one worktree in a fan-out group.
+type FanOutMember struct {
+	Name      string    `json:"name"` // Worktree name
+	Path      string    `json:"path"`
+	Branch    string    `json:"branch"`
+	AgentType AgentType `json:"agentType"`
+}
+
+// FanOut is a set of worktrees created from one base branch that run the
+// same prompt with different agents, so their results can be compared.
+type FanOut struct {
+	Name       string         `json:"name"` // Base name; members are <name>-<agent>[-<run>]
+	BaseBranch string         `json:"baseBranch"`
+	Prompt     string         `json:"prompt"`
+	CreatedAt  time.Time      `json:"createdAt"`
+	Members    []FanOutMember `json:"members"`
+	Winner     string         `json:"winner,omitempty"` // Member picked for merging
+}
+
+// member returns the member with the given worktree name.
+func (f *FanOut) member(name string) *FanOutMember {
+	for i := range f.Members {
+		if f.Members[i].Name == name {
+			return &f.Members[i]
+		}
+	}
+	return nil
+}
+
+// fanOutSlot is a worktree to create for a fan-out.
+type fanOutSlot struct {
+	Branch    string
+	AgentType AgentType
+}
+
+// fanOutSlots names the worktrees for a fan-out: <name>-<agent>, with a
+// -<run> suffix when each agent runs more than once.
+func fanOutSlots(name string, agents []AgentType, runs int) []fanOutSlot {
+	var slots []fanOutSlot
+	for _, at := range agents {
+		for i := 1; i <= runs; i++ {
+			branch := name + "-" + string(at)
+			if runs > 1 {
+				branch = fmt.Sprintf("%s-%d", branch, i)
+			}
+			slots = append(slots, fanOutSlot{Branch: branch, AgentType: at})
+		}
+	}
+	return slots
+}
+
+// parseFanOutRuns parses the runs-per-agent field.
+func parseFanOutRuns(s string) (int, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 1, nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 1 || n > maxFanOutMembers {
+		return 0, fmt.Errorf("runs per agent must be 1-%d", maxFanOutMembers)
+	}
+	return n, nil
+}
+
+// validateFanOut checks the fan-out modal input and returns the slots to
+// create, or an error message for the modal.
+func validateFanOut(name, prompt string, agents []AgentType, runsInput string) ([]fanOutSlot, string) {
+	if name == "" {
+		return nil, "Name is required"
+	}
+	if valid, errs, _ := ValidateBranchName(name); !valid {
+		return nil, "Invalid branch name: " + strings.Join(errs, ", ")
+	}
+	if strings.TrimSpace(prompt) == "" {
+		return nil, "Prompt is required"
+	}
+	if len(agents) == 0 {
+		return nil, "Select at least one agent"
+	}
+	runs, err := parseFanOutRuns(runsInput)
+	if err != nil {
+		return nil, err.Error()
+	}
+	slots := fanOutSlots(name, agents, runs)
+	if len(slots) < 2 {
+		return nil, "Fan-out needs at least two workspaces (more agents or runs)"
+	}
+	if len(slots) > maxFanOutMembers {
+		return nil, fmt.Sprintf("Fan-out is limited to %d workspaces (got %d)", maxFanOutMembers, len(slots))
+	}
+	return slots, ""
+}
+
+// executeFanOut validates the fan-out modal and creates the worktrees.
+func (p *Plugin) executeFanOut() tea.Cmd {
+	name := strings.TrimSpace(p.fanOutNameInput.Value())
+	prompt := strings.TrimSpace(p.fanOutPromptInput.Value())
+	agents := p.selectedFanOutAgents()
+	slots, errMsg := validateFanOut(name, prompt, agents, p.fanOutRunsInput.Value())
+	if errMsg != "" {
+		p.fanOutError = errMsg
+		return nil
+	}
+	for _, s := range slots {
+		if branchExists(p.ctx.WorkDir, s.Branch) {
+			p.fanOutError = "Branch already exists: " + s.Branch
+			return nil
+		}
+	}
+	p.fanOutError = ""
+	p.fanOutCreating = true
+	return p.createFanOut(name, strings.TrimSpace(p.fanOutBaseInput.Value()), prompt, slots, p.fanOutSkipPerms)
+}
+
+// fanOutPrompt wraps the fan-out prompt text for StartAgentWithOptions.
+func fanOutPrompt(body string) *Prompt {
+	if strings.TrimSpace(body) == "" {
+		return nil
+	}
+	return &Prompt{Name: "fan-out", TicketMode: TicketNone, Body: body}
+}
+
+// loadFanOuts reads the project's fan-out groups. Members whose worktree no
+// longer exists are dropped, as are groups left without members.
+func loadFanOuts(projectRoot string) []*FanOut {
+	dir, err := projectdir.Resolve(projectRoot)
+	if err != nil {
+		return nil
+	}
+	data, err := os.ReadFile(filepath.Join(dir, fanOutFile))
+	if err != nil {
+		return nil
+	}
+	var groups []*FanOut
+	if err := json.Unmarshal(data, &groups); err != nil {
+		return nil
+	}
+	live := groups[:0]
+	for _, g := range groups {
+		members := g.Members[:0]
+		for _, m := range g.Members {
+			if _, err := os.Stat(m.Path); err == nil {
+				members = append(members, m)
+			}
+		}
+		g.Members = members
+		if len(g.Members) > 0 {
+			live = append(live, g)
+		}
+	}
+	return live
+}
+
+// saveFanOuts writes the project's fan-out groups.
+func saveFanOuts(projectRoot string, groups []*FanOut) error {
+	dir, err := projectdir.Resolve(projectRoot)
+	if err != nil {
+		return err
+	}
+	if groups == nil {
+		groups = []*FanOut{}
+	}
+	data, err := json.MarshalIndent(groups, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, fanOutFile), data, 0644)
+}
+
+// fanOutFor returns the fan-out group a worktree belongs to.
+func (p *Plugin) fanOutFor(wt *Worktree) *FanOut {
+	if wt == nil {
+		return nil
+	}
+	for _, g := range p.fanOuts {
+		if g.member(wt.Name) != nil {
+			return g
+		}
+	}
+	return nil
+}
+
+// removeFanOutMember drops a deleted worktree from its fan-out group,
+// dropping the group once it is empty.
+func (p *Plugin) removeFanOutMember(name string) {
+	for i, g := range p.fanOuts {
+		for j, m := range g.Members {
+			if m.Name != name {
+				continue
+			}
+			g.Members = append(g.Members[:j], g.Members[j+1:]...)
+			if len(g.Members) == 0 {
+				p.fanOuts = append(p.fanOuts[:i], p.fanOuts[i+1:]...)
+			}
+			p.persistFanOuts()
+			return
+		}
+	}
+}
+
+// persistFanOuts saves fan-out groups, logging failures.
+func (p *Plugin) persistFanOuts() {
+	if err := saveFanOuts(p.ctx.ProjectRoot, p.fanOuts); err != nil {
+		p.ctx.Logger.Warn("failed to save fan-outs", "error", err)
+	}
+}
+
+// FanOutCreatedMsg signals that a fan-out's worktrees were created.
+type FanOutCreatedMsg struct {
+	Group     *FanOut
+	Worktrees []*Worktree
+	SkipPerms bool
+	Errs      []string // Per-worktree creation failures
+}
+
+// createFanOut creates one worktree per slot from the same base branch.
+// Creation continues past failures so one bad slot doesn't sink the rest.
+func (p *Plugin) createFanOut(name, baseBranch, promptBody string, slots []fanOutSlot, skipPerms bool) tea.Cmd {
+	return func() tea.Msg {
+		group := &FanOut{Name: name, BaseBranch: baseBranch, Prompt: promptBody, CreatedAt: time.Now()}
+		var created []*Worktree
+		var errs []string
+		for _, s := range slots {
+			wt, err := p.doCreateWorktree(s.Branch, baseBranch, "", "", s.AgentType, nil)
+			if err != nil {
+				errs = append(errs, fmt.Sprintf("%s: %v", s.Branch, err))
+				continue
+			}
+			created = append(created, wt)
+			group.Members = append(group.Members, FanOutMember{Name: wt.Name, Path: wt.Path, Branch: wt.Branch, AgentType: s.AgentType})
+		}
+		if len(created) > 0 {
+			group.BaseBranch = created[0].BaseBranch // Resolved when base was left empty
+		}
+		return FanOutCreatedMsg{Group: group, Worktrees: created, SkipPerms: skipPerms, Errs: errs}
+	}
+}
+
+// handleFanOutCreated adds the new worktrees, starts an agent in each and
+// opens the comparison board.
+func (p *Plugin) handleFanOutCreated(msg FanOutCreatedMsg) tea.Cmd {
+	p.fanOutCreating = false
+	if len(msg.Worktrees) == 0 {
+		p.fanOutError = "Fan-out failed: " + strings.Join(msg.Errs, "; ")
+		p.viewMode = ViewModeFanOut
+		return nil
+	}
+
+	p.fanOuts = append(p.fanOuts, msg.Group)
+	p.persistFanOuts()
+
+	prompt := fanOutPrompt(msg.Group.Prompt)
+	var cmds []tea.Cmd
+	for _, wt := range msg.Worktrees {
+		p.worktrees = append(p.worktrees, wt)
+		cmds = append(cmds, p.StartAgentWithOptions(wt, wt.ChosenAgentType, msg.SkipPerms, prompt))
+	}
+	p.clearFanOutModal()
+	cmds = append(cmds, p.openFanOutBoard(msg.Group))
+
+	if len(msg.Errs) > 0 {
+		cmds = append(cmds, appmsg.ShowToast(fmt.Sprintf("Fan-out: %d of %d workspaces failed", len(msg.Errs), len(msg.Errs)+len(msg.Worktrees)), 4*time.Second))
+	}
+	return tea.Batch(cmds...)
+}
+
+// fanOutTestState is the progress of a member's test run.
+type fanOutTestState int
+
+const (
+	fanOutTestNone fanOutTestState = iota
+	fanOutTestRunning
+	fanOutTestPassed
+	fanOutTestFailed
+)
+
+// fanOutResult holds the comparison board metrics for one member.
+type fanOutResult struct {
+	Stats      *GitStats
+	Cost       float64
+	HasCost    bool // A session was found for the worktree
+	Test       fanOutTestState
+	TestOutput string // Tail of the last test run
+}
+
+// FanOutMetricsMsg delivers diff stats and cost for a fan-out member.
+type FanOutMetricsMsg struct {
+	Epoch   uint64
+	Name    string
+	Stats   *GitStats
+	Cost    float64
+	HasCost bool
+}
+
+// GetEpoch implements plugin.EpochMessage.
+func (m FanOutMetricsMsg) GetEpoch() uint64 { return m.Epoch }
+
+// FanOutTestDoneMsg delivers the result of a member's test run.
+type FanOutTestDoneMsg struct {
+	Epoch  uint64
+	Name   string
+	Passed bool
+	Output string
+}
+
+// GetEpoch implements plugin.EpochMessage.
+func (m FanOutTestDoneMsg) GetEpoch() uint64 { return m.Epoch }
+
+// openFanOutBoard shows the comparison board for a group and loads metrics.
+func (p *Plugin) openFanOutBoard(group *FanOut) tea.Cmd {
+	p.fanOutBoard = group
+	p.fanOutBoardIdx = 0
+	p.fanOutConfirmWinner = false
+	p.fanOutResults = make(map[string]*fanOutResult, len(group.Members))
+	p.viewMode = ViewModeFanOutBoard
+	return p.loadFanOutMetrics()
+}
+
+// closeFanOutBoard returns to the list view.
+func (p *Plugin) closeFanOutBoard() {
+	p.fanOutBoard = nil
+	p.fanOutBoardModal = nil
+	p.fanOutBoardModalWidth = 0
+	p.fanOutBoardIdx = 0
+	p.fanOutConfirmWinner = false
+	p.fanOutResults = nil
+	p.viewMode = ViewModeList
+}
+
+// fanOutResultFor returns the board metrics for a member, creating them.
+func (p *Plugin) fanOutResultFor(name string) *fanOutResult {
+	if p.fanOutResults == nil {
+		p.fanOutResults = make(map[string]*fanOutResult)
+	}
+	r := p.fanOutResults[name]
+	if r == nil {
+		r = &fanOutResult{}
+		p.fanOutResults[name] = r
+	}
+	return r
+}
+
+// loadFanOutMetrics computes diff stats and cost for every board member.
+func (p *Plugin) loadFanOutMetrics() tea.Cmd {
+	if p.fanOutBoard == nil {
+		return nil
+	}
+	epoch := p.ctx.Epoch
+	base := p.fanOutBoard.BaseBranch
+	since := p.fanOutBoard.CreatedAt
+	cmds := make([]tea.Cmd, 0, len(p.fanOutBoard.Members))
+	for _, m := range p.fanOutBoard.Members {
+		name, path := m.Name, m.Path
+		cmds = append(cmds, func() tea.Msg {
+			msg := FanOutMetricsMsg{Epoch: epoch, Name: name}
+			if stats, err := computeBranchStats(path, base); err == nil {
+				msg.Stats = stats
+			}
+			msg.Cost, msg.HasCost = sessionCostSince(path, since)
+			return msg
+		})
+	}
+	return tea.Batch(cmds...)
+}
+
+// handleFanOutMetrics stores metrics for a board member.
+func (p *Plugin) handleFanOutMetrics(msg FanOutMetricsMsg) {
+	if plugin.IsStale(p.ctx, msg) || p.fanOutBoard == nil {
+		return
+	}
+	r := p.fanOutResultFor(msg.Name)
+	r.Stats = msg.Stats
+	r.Cost = msg.Cost
+	r.HasCost = msg.HasCost
+}
+
+// sessionCostSince sums the estimated cost of agent sessions in a worktree
+// that were active after since. ok is false when no session was found.
+func sessionCostSince(worktreePath string, since time.Time) (cost float64, ok bool) {
+	for _, a := range adapter.AllAdapters() {
+		if found, err := a.Detect(worktreePath); err != nil || !found {
+			continue
+		}
+		sessions, err := a.Sessions(worktreePath)
+		if err != nil {
+			continue
+		}
+		for _, s := range sessions {
+			if s.UpdatedAt.Before(since) {
+				continue
+			}
+			ok = true
+			c := s.EstCost
+			if c == 0 {
+				if msgs, err := a.Messages(s.ID); err == nil {
+					c = sessionquery.DetailsFromMessages(msgs).Cost
+				}
+			}
+			cost += c
+		}
+	}
+	return cost, ok
+}
+
+// resolveTestCommand returns the test command for a worktree.
+// Precedence: .sidecar-test on the base branch > projects.list[].testCommand >
+// plugins.workspace.testCommand. The file is read from baseBranch rather
+// than the worktree so an agent cannot replace the command that gates it.
+func (p *Plugin) resolveTestCommand(worktreePath, baseBranch string) string {
+	if baseBranch == "" {
+		baseBranch = detectDefaultBranch(worktreePath)
+	}
+	cmd := exec.Command("git", "show", baseBranch+":"+sidecarTestFile)
+	cmd.Dir = worktreePath
+	if raw, err := cmd.Output(); err == nil {
+		raw = bytes.TrimPrefix(bytes.TrimSpace(raw), []byte{0xEF, 0xBB, 0xBF}) // UTF-8 BOM
+		if command := strings.TrimSpace(string(raw)); command != "" && utf8.ValidString(command) {
+			return command
+		}
+	}
+	if p.ctx == nil || p.ctx.Config == nil {
+		return ""
+	}
+	for _, proj := range p.ctx.Config.Projects.List {
+		if proj.Path == p.ctx.ProjectRoot && proj.TestCommand != "" {
+			return proj.TestCommand
+		}
+	}
+	return p.ctx.Config.Plugins.Workspace.TestCommand
+}
+
+// runTestCommand runs command via sh in dir and returns whether it passed
+// along with the tail of its combined output.
+func runTestCommand(dir, command string) (bool, string) {
+	return runTestCommandTo(context.Background(), dir, command, nil)
+}
+
+// runTestCommandTo is runTestCommand writing output to tail as it is
+// produced, so callers can stream it. A nil tail is allocated internally.
+func runTestCommandTo(parent context.Context, dir, command string, tail *outputTail) (bool, string) {
+	ctx, cancel := context.WithTimeout(parent, testTimeout)
+	defer cancel()
+
+	if tail == nil {
+		tail = &outputTail{}
+	}
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Dir = dir
+	cmd.Stdout = tail
+	cmd.Stderr = tail
+	// Run in its own process group so cancelling kills the whole tree, not
+	// just sh; test runners leave children behind otherwise.
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+	cmd.WaitDelay = testWaitDelay
+	err := cmd.Run()
+	out := strings.TrimSpace(tail.String())
+	switch {
+	case ctx.Err() == context.DeadlineExceeded:
+		return false, strings.TrimSpace(out + "\n" + fmt.Sprintf("timed out after %s", testTimeout))
+	case parent.Err() != nil:
+		return false, strings.TrimSpace(out + "\ncanceled")
+	}
+	if err != nil && out == "" {
+		out = err.Error()
+	}
+	return err == nil, out
+}
+
+// outputTail is an io.Writer keeping the last testOutputTailBytes written.
+// Safe for concurrent use, so the UI can read it while a command runs.
+type outputTail struct {
+	mu  sync.Mutex
+	buf []byte
+}
+
+// Write implements io.Writer.
+func (t *outputTail) Write(b []byte) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.buf = append(t.buf, b...)
+	if over := len(t.buf) - testOutputTailBytes; over > 0 {
+		t.buf = append(t.buf[:0], t.buf[over:]...)
+	}
+	return len(b), nil
+}
+
+// String returns the retained output.
+func (t *outputTail) String() string {
+	if t == nil {
+		return ""
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return string(t.buf)
+}
+
+// runFanOutTests runs the test command in every board member.
+func (p *Plugin) runFanOutTests() tea.Cmd {
+	if p.fanOutBoard == nil {
+		return nil
+	}
+	epoch := p.ctx.Epoch
+	var cmds []tea.Cmd
+	for _, m := range p.fanOutBoard.Members {
+		command := p.resolveTestCommand(m.Path, p.fanOutBoard.BaseBranch)
+		if command == "" {
+			continue
+		}
+		r := p.fanOutResultFor(m.Name)
+		if r.Test == fanOutTestRunning {
+			continue
+		}
+		r.Test = fanOutTestRunning
+		name, path := m.Name, m.Path
+		cmds = append(cmds, func() tea.Msg {
+			passed, output := runTestCommand(path, command)
+			return FanOutTestDoneMsg{Epoch: epoch, Name: name, Passed: passed, Output: output}
+		})
+	}
+	if len(cmds) == 0 {
+		return appmsg.ShowToast("No test command: set plugins.workspace.testCommand or add "+sidecarTestFile, 4*time.Second)
+	}
+	return tea.Batch(cmds...)
+}
+
+// handleFanOutTestDone stores a member's test result.
+func (p *Plugin) handleFanOutTestDone(msg FanOutTestDoneMsg) {
+	if plugin.IsStale(p.ctx, msg) || p.fanOutBoard == nil {
+		return
+	}
+	r := p.fanOutResultFor(msg.Name)
+	r.Test = fanOutTestFailed
+	if msg.Passed {
+		r.Test = fanOutTestPassed
+	}
+	r.TestOutput = msg.Output
+}
+
+// pickFanOutWinner records the selected member as the winner and starts the
+// merge workflow for it. The other members are kept until the winner has
+// merged, so a failed or cancelled merge loses nothing.
+func (p *Plugin) pickFanOutWinner() tea.Cmd {
+	group := p.fanOutBoard
+	if group == nil || p.fanOutBoardIdx < 0 || p.fanOutBoardIdx >= len(group.Members) {
+		return nil
+	}
+	winner := group.Members[p.fanOutBoardIdx]
+	wt := p.findWorktree(winner.Name)
+	if wt == nil {
+		p.fanOutConfirmWinner = false
+		return appmsg.ShowToast("Workspace "+winner.Name+" not found", 3*time.Second)
+	}
+
+	group.Winner = winner.Name
+	p.persistFanOuts()
+	p.closeFanOutBoard()
+
+	// Select the winner so the list reflects the merge target
+	for i, w := range p.worktrees {
+		if w == wt {
+			p.shellSelected = false
+			p.selectedIdx = i
+			p.ensureVisible()
+			break
+		}
+	}
+
+	return p.startMergeWorkflow(wt)
+}
+
+// deleteFanOutLosers deletes the other members of the fan-out group whose
+// winner is wt, once wt has merged.
+func (p *Plugin) deleteFanOutLosers(wt *Worktree) tea.Cmd {
+	group := p.fanOutFor(wt)
+	if group == nil || group.Winner != wt.Name {
+		return nil
+	}
+	var cmds []tea.Cmd
+	for _, m := range group.Members {
+		if m.Name != wt.Name {
+			cmds = append(cmds, p.deleteFanOutMember(m))
+		}
+	}
+	group.Members = []FanOutMember{*group.member(wt.Name)}
+	p.persistFanOuts()
+	return tea.Batch(cmds...)
+}
+
+// deleteFanOutMember stops a losing member's agent and removes its worktree
+// and local branch.
+func (p *Plugin) deleteFanOutMember(m FanOutMember) tea.Cmd {
+	sessionName := tmuxSessionPrefix + sanitizeName(m.Name)
+	if sessionExists(sessionName) {
+		_ = sessionBackend.Kill(sessionName)
+	}
+	delete(p.managedSessions, sessionName)
+	globalPaneCache.remove(sessionName)
+
+	workDir := p.ctx.WorkDir
+	return func() tea.Msg {
+		if err := doDeleteWorktree(workDir, m.Path, false); err != nil {
+			return DeleteDoneMsg{Name: m.Name, Err: err}
+		}
+		var warnings []string
+		if err := deleteBranch(workDir, m.Branch); err != nil {
+			warnings = append(warnings, fmt.Sprintf("Local branch: %v", err))
+		}
+		return DeleteDoneMsg{Name: m.Name, Warnings: warnings}
+	}
+}
@@ -0,0 +1,275 @@
+package workspace
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/marcus/sidecar/internal/config"
+	"github.com/marcus/sidecar/internal/plugin"
+)
+
+func TestFanOutSlots(t *testing.T) {
+	got := fanOutSlots("auth", []AgentType{AgentClaude, AgentCodex}, 1)
+	want := []string{"auth-claude", "auth-codex"}
+	if len(got) != len(want) {
+		t.Fatalf("got %d slots, want %d", len(got), len(want))
+	}
+	for i, s := range got {
+		if s.Branch != want[i] {
+			t.Errorf("slot %d = %q, want %q", i, s.Branch, want[i])
+		}
+	}
+
+	got = fanOutSlots("auth", []AgentType{AgentClaude}, 3)
+	if len(got) != 3 || got[0].Branch != "auth-claude-1" || got[2].Branch != "auth-claude-3" || got[2].AgentType != AgentClaude {
+		t.Errorf("repeated runs = %+v", got)
+	}
+}
+
+func TestValidateFanOut(t *testing.T) {
+	both := []AgentType{AgentClaude, AgentCodex}
+	tests := []struct {
+		name, prompt, runs string
+		agents             []AgentType
+		wantErr            string
+	}{
+		{"", "do it", "1", both, "Name is required"},
+		{"bad name", "do it", "1", both, "Invalid branch name"},
+		{"auth", " ", "1", both, "Prompt is required"},
+		{"auth", "do it", "1", nil, "Select at least one agent"},
+		{"auth", "do it", "x", both, "runs per agent"},
+		{"auth", "do it", "1", []AgentType{AgentClaude}, "at least two"},
+		{"auth", "do it", "5", both, "limited to 8"},
+		{"auth", "do it", "", both, ""},
+		{"auth", "do it", "3", []AgentType{AgentClaude}, ""},
+	}
+	for _, tc := range tests {
+		slots, errMsg := validateFanOut(tc.name, tc.prompt, tc.agents, tc.runs)
+		if tc.wantErr == "" {
+			if errMsg != "" || len(slots) < 2 {
+				t.Errorf("validateFanOut(%q, runs %q) = %d slots, %q; want valid", tc.name, tc.runs, len(slots), errMsg)
+			}
+			continue
+		}
+		if !strings.Contains(errMsg, tc.wantErr) {
+			t.Errorf("validateFanOut(%q, runs %q) error = %q, want %q", tc.name, tc.runs, errMsg, tc.wantErr)
+		}
+	}
+}
+
+func TestFanOutPersistence(t *testing.T) {
+	config.SetTestStateDir(t.TempDir())
+	defer config.ResetTestStateDir()
+
+	projectRoot := t.TempDir()
+	live := t.TempDir()
+	gone := filepath.Join(t.TempDir(), "deleted")
+
+	groups := []*FanOut{
+		{
+			Name:       "auth",
+			BaseBranch: "main",
+			Prompt:     "add login",
+			CreatedAt:  time.Now(),
+			Members: []FanOutMember{
+				{Name: "auth-claude", Path: live, Branch: "auth-claude", AgentType: AgentClaude},
+				{Name: "auth-codex", Path: gone, Branch: "auth-codex", AgentType: AgentCodex},
+			},
+		},
+		{Name: "stale", Members: []FanOutMember{{Name: "stale-claude", Path: gone}}},
+	}
+	if err := saveFanOuts(projectRoot, groups); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded := loadFanOuts(projectRoot)
+	if len(loaded) != 1 {
+		t.Fatalf("loaded %d groups, want 1 (group without live members dropped)", len(loaded))
+	}
+	g := loaded[0]
+	if g.Name != "auth" || g.Prompt != "add login" || g.BaseBranch != "main" {
+		t.Errorf("loaded group = %+v", g)
+	}
+	if len(g.Members) != 1 || g.Members[0].Name != "auth-claude" || g.Members[0].AgentType != AgentClaude {
+		t.Errorf("members = %+v, want only the live worktree", g.Members)
+	}
+}
+
+func TestRemoveFanOutMember(t *testing.T) {
+	config.SetTestStateDir(t.TempDir())
+	defer config.ResetTestStateDir()
+
+	p := &Plugin{ctx: &plugin.Context{ProjectRoot: t.TempDir()}}
+	p.fanOuts = []*FanOut{{Name: "auth", Members: []FanOutMember{{Name: "a"}, {Name: "b"}}}}
+
+	p.removeFanOutMember("a")
+	if len(p.fanOuts) != 1 || len(p.fanOuts[0].Members) != 1 {
+		t.Fatalf("after removing a: %+v", p.fanOuts)
+	}
+	if p.fanOutFor(&Worktree{Name: "a"}) != nil {
+		t.Error("removed member should not belong to the group")
+	}
+	p.removeFanOutMember("b")
+	if len(p.fanOuts) != 0 {
+		t.Errorf("empty group should be dropped, got %d groups", len(p.fanOuts))
+	}
+}
+
+func TestResolveTestCommand(t *testing.T) {
+	cfg := config.Default()
+	cfg.Plugins.Workspace.TestCommand = "make test"
+	p := &Plugin{ctx: &plugin.Context{Config: cfg}}
+	wtPath := t.TempDir()
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = wtPath
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v: %s", args, err, out)
+		}
+	}
+	git("init", "-b", "main")
+	git("config", "user.email", "test@test.com")
+	git("config", "user.name", "Test")
+	git("commit", "--allow-empty", "-m", "initial")
+
+	if got := p.resolveTestCommand(wtPath, "main"); got != "make test" {
+		t.Errorf("config command = %q, want %q", got, "make test")
+	}
+	testFile := filepath.Join(wtPath, sidecarTestFile)
+	if err := os.WriteFile(testFile, []byte("true\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got := p.resolveTestCommand(wtPath, "main"); got != "make test" {
+		t.Errorf("untracked file = %q, want config command", got)
+	}
+
+	if err := os.WriteFile(testFile, []byte("go test ./...\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	git("add", sidecarTestFile)
+	git("commit", "-m", "add test command")
+	git("checkout", "-b", "feature")
+	if err := os.WriteFile(testFile, []byte("true\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	git("commit", "-am", "skip tests")
+	if got := p.resolveTestCommand(wtPath, "main"); got != "go test ./..." {
+		t.Errorf("file override = %q, want base branch's %q", got, "go test ./...")
+	}
+}
+
+func TestRunTestCommand(t *testing.T) {
+	dir := t.TempDir()
+	if passed, out := runTestCommand(dir, "echo ok"); !passed || out != "ok" {
+		t.Errorf("passing command = %v, %q", passed, out)
+	}
+	if passed, out := runTestCommand(dir, "echo broken; exit 3"); passed || out != "broken" {
+		t.Errorf("failing command = %v, %q", passed, out)
+	}
+}
+
+func TestRunTestCommandCancelKillsGroup(t *testing.T) {
+	dir := t.TempDir()
+	pidFile := filepath.Join(dir, "child.pid")
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		for {
+			if _, err := os.Stat(pidFile); err == nil {
+				cancel()
+				return
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}()
+
+	start := time.Now()
+	passed, out := runTestCommandTo(ctx, dir, "sleep 30 & echo $! > pid.tmp; mv pid.tmp child.pid; wait", nil)
+	if passed || !strings.Contains(out, "canceled") {
+		t.Errorf("canceled command = %v, %q", passed, out)
+	}
+	if elapsed := time.Since(start); elapsed > testWaitDelay {
+		t.Errorf("cancel took %s", elapsed)
+	}
+
+	raw, err := os.ReadFile(pidFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(raw)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The child may linger briefly as a zombie until reaped by init
+	deadline := time.Now().Add(2 * time.Second)
+	for syscall.Kill(pid, 0) == nil {
+		if time.Now().After(deadline) {
+			_ = syscall.Kill(pid, syscall.SIGKILL)
+			t.Fatal("child process survived cancel")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func TestPickFanOutWinnerKeepsLosersUntilMerged(t *testing.T) {
+	config.SetTestStateDir(t.TempDir())
+	defer config.ResetTestStateDir()
+
+	winner := &Worktree{Name: "auth-codex", Path: t.TempDir()}
+	group := &FanOut{Name: "auth", Members: []FanOutMember{{Name: "auth-claude"}, {Name: "auth-codex"}}}
+	p := &Plugin{
+		ctx:       &plugin.Context{ProjectRoot: t.TempDir(), Logger: slog.Default()},
+		worktrees: []*Worktree{{Name: "auth-claude"}, winner},
+		fanOuts:   []*FanOut{group},
+	}
+	p.fanOutBoard = group
+	p.fanOutBoardIdx = 1
+
+	p.pickFanOutWinner()
+	if group.Winner != "auth-codex" || len(group.Members) != 2 {
+		t.Fatalf("winner = %q, members = %d; losers must survive until the merge", group.Winner, len(group.Members))
+	}
+	if cmd := p.deleteFanOutLosers(&Worktree{Name: "auth-claude"}); cmd != nil || len(group.Members) != 2 {
+		t.Error("a losing member's merge must not delete the others")
+	}
+
+	if cmd := p.deleteFanOutLosers(winner); cmd == nil {
+		t.Fatal("expected the losers to be deleted once the winner merged")
+	}
+	if len(group.Members) != 1 || group.Members[0].Name != "auth-codex" {
+		t.Errorf("members = %+v, want only the winner", group.Members)
+	}
+}
+
+func TestFanOutBoardWinnerNeedsConfirmation(t *testing.T) {
+	p := &Plugin{ctx: &plugin.Context{}}
+	group := &FanOut{Name: "auth", Members: []FanOutMember{{Name: "auth-claude"}, {Name: "auth-codex"}}}
+	p.fanOutBoard = group
+	p.viewMode = ViewModeFanOutBoard
+
+	p.handleFanOutBoardKeys(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("j")})
+	if p.fanOutBoardIdx != 1 {
+		t.Fatalf("fanOutBoardIdx = %d, want 1", p.fanOutBoardIdx)
+	}
+	p.handleFanOutBoardKeys(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("w")})
+	if !p.fanOutConfirmWinner {
+		t.Fatal("w should ask for confirmation")
+	}
+	p.handleFanOutBoardKeys(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("n")})
+	if p.fanOutConfirmWinner || len(group.Members) != 2 || group.Winner != "" {
+		t.Errorf("n should cancel without changes: confirm=%v members=%d winner=%q", p.fanOutConfirmWinner, len(group.Members), group.Winner)
+	}
+	p.handleFanOutBoardKeys(tea.KeyMsg{Type: tea.KeyEsc})
+	if p.viewMode != ViewModeList || p.fanOutBoard != nil {
+		t.Error("esc should close the board")
+	}
+}
@@ -0,0 +1,344 @@
+package workspace
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textarea"
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/marcus/sidecar/internal/modal"
+	"github.com/marcus/sidecar/internal/styles"
+	"github.com/marcus/sidecar/internal/ui"
+)
+
+const (
+	fanOutNameFieldID       = "fan-out-name"
+	fanOutBaseFieldID       = "fan-out-base"
+	fanOutPromptFieldID     = "fan-out-prompt"
+	fanOutRunsFieldID       = "fan-out-runs"
+	fanOutSkipPermissionsID = "fan-out-skip-permissions"
+	fanOutSubmitID          = "fan-out-submit"
+	fanOutCancelID          = "fan-out-cancel"
+	fanOutAgentItemPrefix   = "fan-out-agent-"
+)
+
+// fanOutAgentOrder lists the agents offered for fan-out (every agent but None).
+func fanOutAgentOrder() []AgentType {
+	agents := make([]AgentType, 0, len(AgentTypeOrder))
+	for _, at := range AgentTypeOrder {
+		if at != AgentNone {
+			agents = append(agents, at)
+		}
+	}
+	return agents
+}
+
+// openFanOutModal initializes and opens the fan-out modal.
+func (p *Plugin) openFanOutModal() {
+	p.fanOutNameInput = textinput.New()
+	p.fanOutNameInput.Placeholder = "feature-name"
+	p.fanOutNameInput.CharLimit = 80
+	p.fanOutNameInput.Prompt = ""
+	p.fanOutNameInput.Focus()
+
+	p.fanOutBaseInput = textinput.New()
+	p.fanOutBaseInput.Placeholder = "current branch"
+	p.fanOutBaseInput.CharLimit = 100
+	p.fanOutBaseInput.Prompt = ""
+
+	p.fanOutPromptInput = textarea.New()
+	p.fanOutPromptInput.Placeholder = "Prompt sent to every agent..."
+	p.fanOutPromptInput.FocusedStyle.Placeholder = lipgloss.NewStyle().Foreground(styles.TextSecondary)
+	p.fanOutPromptInput.CharLimit = 0
+	p.fanOutPromptInput.ShowLineNumbers = false
+
+	p.fanOutRunsInput = textinput.New()
+	p.fanOutRunsInput.SetValue("1")
+	p.fanOutRunsInput.CharLimit = 1
+	p.fanOutRunsInput.Width = 3
+	p.fanOutRunsInput.Prompt = ""
+
+	agents := fanOutAgentOrder()
+	p.fanOutAgents = make([]bool, len(agents))
+	defaultAgent := p.getConfigDefaultAgentType()
+	for i, at := range agents {
+		p.fanOutAgents[i] = at == defaultAgent
+	}
+	p.fanOutSkipPerms = false
+	p.fanOutError = ""
+	p.fanOutCreating = false
+	p.fanOutModal = nil
+	p.fanOutModalWidth = 0
+	p.viewMode = ViewModeFanOut
+}
+
+// clearFanOutModal resets fan-out modal state.
+func (p *Plugin) clearFanOutModal() {
+	p.fanOutNameInput = textinput.Model{}
+	p.fanOutBaseInput = textinput.Model{}
+	p.fanOutPromptInput = textarea.Model{}
+	p.fanOutRunsInput = textinput.Model{}
+	p.fanOutAgents = nil
+	p.fanOutSkipPerms = false
+	p.fanOutError = ""
+	p.fanOutCreating = false
+	p.fanOutModal = nil
+	p.fanOutModalWidth = 0
+}
+
+// selectedFanOutAgents returns the checked agents in display order.
+func (p *Plugin) selectedFanOutAgents() []AgentType {
+	var agents []AgentType
+	for i, at := range fanOutAgentOrder() {
+		if i < len(p.fanOutAgents) && p.fanOutAgents[i] {
+			agents = append(agents, at)
+		}
+	}
+	return agents
+}
+
+// ensureFanOutModal builds or rebuilds the fan-out modal.
+func (p *Plugin) ensureFanOutModal() {
+	modalW := 70
+	maxW := p.width - 4
+	if maxW < 1 {
+		maxW = 1
+	}
+	if modalW > maxW {
+		modalW = maxW
+	}
+
+	if p.fanOutModal != nil && p.fanOutModalWidth == modalW {
+		return
+	}
+	p.fanOutModalWidth = modalW
+
+	m := modal.New("Fan Out: Best of N",
+		modal.WithWidth(modalW),
+		modal.WithHints(false),
+	).
+		AddSection(modal.Text("Name (branches are <name>-<agent>):")).
+		AddSection(modal.Input(fanOutNameFieldID, &p.fanOutNameInput, modal.WithSubmitOnEnter(false))).
+		AddSection(modal.Spacer()).
+		AddSection(modal.Text("Base Branch (default: current):")).
+		AddSection(modal.Input(fanOutBaseFieldID, &p.fanOutBaseInput, modal.WithSubmitOnEnter(false))).
+		AddSection(modal.Spacer()).
+		AddSection(modal.TextareaWithLabel(fanOutPromptFieldID, "Prompt:", &p.fanOutPromptInput, 4)).
+		AddSection(modal.Spacer()).
+		AddSection(modal.Text("Agents:"))
+	for i, at := range fanOutAgentOrder() {
+		m.AddSection(modal.Checkbox(fanOutAgentItemPrefix+fmt.Sprint(i), AgentDisplayNames[at], &p.fanOutAgents[i]))
+	}
+	m.AddSection(modal.Spacer()).
+		AddSection(modal.Text(fmt.Sprintf("Runs per agent (1-%d):", maxFanOutMembers))).
+		AddSection(modal.Input(fanOutRunsFieldID, &p.fanOutRunsInput, modal.WithSubmitOnEnter(false))).
+		AddSection(modal.Spacer()).
+		AddSection(modal.Checkbox(fanOutSkipPermissionsID, "Auto-approve all actions", &p.fanOutSkipPerms)).
+		AddSection(p.fanOutSummarySection()).
+		AddSection(modal.Spacer()).
+		AddSection(p.fanOutErrorSection()).
+		AddSection(modal.When(func() bool { return p.fanOutError != "" }, modal.Spacer())).
+		AddSection(modal.Buttons(
+			modal.Btn(" Fan Out ", fanOutSubmitID),
+			modal.Btn(" Cancel ", fanOutCancelID),
+		))
+	p.fanOutModal = m
+	p.fanOutModal.SetFocus(fanOutNameFieldID)
+}
+
+// fanOutSummarySection previews the branches the fan-out will create.
+func (p *Plugin) fanOutSummarySection() modal.Section {
+	return modal.Custom(func(contentWidth int, focusID, hoverID string) modal.RenderedSection {
+		name := strings.TrimSpace(p.fanOutNameInput.Value())
+		agents := p.selectedFanOutAgents()
+		runs, _ := parseFanOutRuns(p.fanOutRunsInput.Value())
+		if name == "" || len(agents) == 0 || runs == 0 {
+			return modal.RenderedSection{}
+		}
+		if p.fanOutCreating {
+			return modal.RenderedSection{Content: dimText(fmt.Sprintf("  Creating %d workspaces...", len(agents)*runs))}
+		}
+		slots := fanOutSlots(name, agents, runs)
+		branches := make([]string, len(slots))
+		for i, s := range slots {
+			branches[i] = s.Branch
+		}
+		line := fmt.Sprintf("  Creates %d: %s", len(slots), strings.Join(branches, ", "))
+		return modal.RenderedSection{Content: dimText(ansi.Truncate(line, contentWidth, "…"))}
+	}, nil)
+}
+
+// fanOutErrorSection renders the fan-out validation error.
+func (p *Plugin) fanOutErrorSection() modal.Section {
+	return modal.Custom(func(contentWidth int, focusID, hoverID string) modal.RenderedSection {
+		if p.fanOutError == "" {
+			return modal.RenderedSection{}
+		}
+		errStyle := lipgloss.NewStyle().Foreground(styles.Error)
+		return modal.RenderedSection{Content: errStyle.Render("Error: " + p.fanOutError)}
+	}, nil)
+}
+
+// renderFanOutModal renders the fan-out modal over a dimmed background.
+func (p *Plugin) renderFanOutModal(width, height int) string {
+	background := p.renderListView(width, height)
+
+	p.ensureFanOutModal()
+	if p.fanOutModal == nil {
+		return background
+	}
+
+	modalContent := p.fanOutModal.Render(width, height, p.mouseHandler)
+	return ui.OverlayModal(background, modalContent, width, height)
+}
+
+// ensureFanOutBoardModal builds or rebuilds the comparison board modal.
+func (p *Plugin) ensureFanOutBoardModal() {
+	modalW := 100
+	maxW := p.width - 4
+	if maxW < 1 {
+		maxW = 1
+	}
+	if modalW > maxW {
+		modalW = maxW
+	}
+
+	if p.fanOutBoardModal != nil && p.fanOutBoardModalWidth == modalW {
+		return
+	}
+	p.fanOutBoardModalWidth = modalW
+
+	p.fanOutBoardModal = modal.New("Fan-out Comparison",
+		modal.WithWidth(modalW),
+		modal.WithHints(false),
+	).
+		AddSection(p.fanOutBoardSection())
+}
+
+// fanOutBoardSection renders the member comparison table.
+func (p *Plugin) fanOutBoardSection() modal.Section {
+	return modal.Custom(func(contentWidth int, focusID, hoverID string) modal.RenderedSection {
+		group := p.fanOutBoard
+		if group == nil {
+			return modal.RenderedSection{}
+		}
+
+		var lines []string
+		lines = append(lines, fmt.Sprintf("%s  %s", lipgloss.NewStyle().Bold(true).Render(group.Name), dimText("from "+group.BaseBranch)))
+		if prompt := strings.Join(strings.Fields(group.Prompt), " "); prompt != "" {
+			lines = append(lines, dimText(ansi.Truncate("Prompt: "+prompt, contentWidth, "…")))
+		}
+		lines = append(lines, "")
+
+		nameW := 0
+		for _, m := range group.Members {
+			nameW = max(nameW, ansi.StringWidth(m.Name))
+		}
+		nameW = min(max(nameW, 9), max(contentWidth-60, 12))
+
+		header := fmt.Sprintf("  %-*s  %-9s %-11s %-20s %-8s %s", nameW, "Workspace", "Agent", "Status", "Diff", "Tests", "Cost")
+		lines = append(lines, dimText(ansi.Truncate(header, contentWidth, "")))
+
+		for i, m := range group.Members {
+			prefix := "  "
+			if i == p.fanOutBoardIdx {
+				prefix = "> "
+			}
+			status := "missing"
+			if wt := p.findWorktree(m.Name); wt != nil {
+				status = wt.Status.Icon() + " " + wt.Status.String()
+			}
+			r := p.fanOutResults[m.Name]
+			row := fmt.Sprintf("%s%-*s  %-9s %-11s %-20s %-8s %s",
+				prefix,
+				nameW, ansi.Truncate(m.Name, nameW, "…"),
+				ansi.Truncate(AgentDisplayNames[m.AgentType], 9, "…"),
+				status,
+				formatFanOutDiff(r),
+				formatFanOutTest(r),
+				formatFanOutCost(r))
+			row = ansi.Truncate(row, contentWidth, "")
+			if i == p.fanOutBoardIdx {
+				row = lipgloss.NewStyle().Foreground(styles.Primary).Render(row)
+			}
+			lines = append(lines, row)
+		}
+
+		// Failing test output for the selected member
+		if p.fanOutBoardIdx >= 0 && p.fanOutBoardIdx < len(group.Members) {
+			if r := p.fanOutResults[group.Members[p.fanOutBoardIdx].Name]; r != nil && r.Test == fanOutTestFailed && r.TestOutput != "" {
+				lines = append(lines, "")
+				out := strings.Split(r.TestOutput, "\n")
+				if len(out) > 8 {
+					out = out[len(out)-8:]
+				}
+				errStyle := lipgloss.NewStyle().Foreground(styles.Error)
+				for _, l := range out {
+					lines = append(lines, errStyle.Render(ansi.Truncate("  "+l, contentWidth, "…")))
+				}
+			}
+		}
+
+		lines = append(lines, "")
+		if p.fanOutConfirmWinner && p.fanOutBoardIdx < len(group.Members) {
+			warn := lipgloss.NewStyle().Foreground(styles.Warning)
+			lines = append(lines, warn.Render(fmt.Sprintf("Merge %s and, once merged, delete the other %d workspaces and branches? (y/n)",
+				group.Members[p.fanOutBoardIdx].Name, len(group.Members)-1)))
+		} else {
+			lines = append(lines, dimText("t run tests · r refresh · enter go to · w pick winner · esc close"))
+		}
+
+		return modal.RenderedSection{Content: strings.Join(lines, "\n")}
+	}, nil)
+}
+
+// formatFanOutDiff renders diff stats against the fan-out base branch.
+func formatFanOutDiff(r *fanOutResult) string {
+	if r == nil || r.Stats == nil {
+		return "…"
+	}
+	s := fmt.Sprintf("+%d -%d %df", r.Stats.Additions, r.Stats.Deletions, r.Stats.FilesChanged)
+	if r.Stats.Ahead > 0 {
+		s += fmt.Sprintf(" ↑%d", r.Stats.Ahead)
+	}
+	return s
+}
+
+// formatFanOutTest renders a member's test state.
+func formatFanOutTest(r *fanOutResult) string {
+	if r == nil {
+		return "-"
+	}
+	switch r.Test {
+	case fanOutTestRunning:
+		return "running"
+	case fanOutTestPassed:
+		return "✓ pass"
+	case fanOutTestFailed:
+		return "✗ fail"
+	}
+	return "-"
+}
+
+// formatFanOutCost renders a member's estimated agent cost.
+func formatFanOutCost(r *fanOutResult) string {
+	if r == nil || !r.HasCost {
+		return "-"
+	}
+	return fmt.Sprintf("$%.2f", r.Cost)
+}
+
+// renderFanOutBoard renders the comparison board over a dimmed background.
+func (p *Plugin) renderFanOutBoard(width, height int) string {
+	background := p.renderListView(width, height)
+
+	p.ensureFanOutBoardModal()
+	if p.fanOutBoardModal == nil {
+		return background
+	}
+
+	modalContent := p.fanOutBoardModal.Render(width, height, p.mouseHandler)
+	return ui.OverlayModal(background, modalContent, width, height)
+}
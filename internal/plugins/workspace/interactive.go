@@ -1,10 +1,9 @@
 package workspace
 
 import (
+	"errors"
 	"fmt"
 	"os"
-	"os/exec"
-	"strconv"
 	"strings"
 	"time"
 
@@ -14,6 +13,7 @@ import (
 	"github.com/charmbracelet/x/ansi"
 	app "github.com/marcus/sidecar/internal/app"
 	"github.com/marcus/sidecar/internal/features"
+	appmsg "github.com/marcus/sidecar/internal/msg"
 	"github.com/marcus/sidecar/internal/styles"
 	"github.com/marcus/sidecar/internal/tty"
 	"golang.org/x/term"
@@ -148,6 +148,9 @@ func isSessionDeadError(err error) bool {
 	if err == nil {
 		return false
 	}
+	if errors.Is(err, errPTYSessionNotFound) {
+		return true
+	}
 	errStr := err.Error()
 	return strings.Contains(errStr, "can't find pane") ||
 		strings.Contains(errStr, "no such session") ||
@@ -161,28 +164,16 @@ func MapKeyToTmux(msg tea.KeyMsg) (key string, useLiteral bool) {
 	return tty.MapKeyToTmux(msg)
 }
 
-// sendKeyToTmux sends a key to a tmux pane using send-keys.
+// sendKeyToTmux sends a key to a session pane.
 // Uses the tmux key name syntax (e.g., "Enter", "C-c", "Up").
 func sendKeyToTmux(sessionName, key string) error {
-	cmd := exec.Command("tmux", "send-keys", "-t", sessionName, key)
-	return cmd.Run()
+	return sessionBackend.SendKey(sessionName, key)
 }
 
-// sendLiteralToTmux sends literal text to a tmux pane using send-keys -l.
-// This prevents tmux from interpreting special key names.
+// sendLiteralToTmux sends literal text to a session pane.
+// This prevents special key names from being interpreted.
 func sendLiteralToTmux(sessionName, text string) error {
-	// tmux treats bare ; in argv as a command separator, so a literal
-	// semicolon never reaches send-keys. Fall back to hex encoding (-H)
-	// which bypasses tmux's command parser entirely.
-	if strings.Contains(text, ";") {
-		args := []string{"send-keys", "-t", sessionName, "-H"}
-		for _, b := range []byte(text) {
-			args = append(args, fmt.Sprintf("%02x", b))
-		}
-		return exec.Command("tmux", args...).Run()
-	}
-	cmd := exec.Command("tmux", "send-keys", "-l", "-t", sessionName, text)
-	return cmd.Run()
+	return sessionBackend.SendLiteral(sessionName, text)
 }
 
 // keySpec describes a key to send to tmux with ordering preserved.
@@ -228,19 +219,11 @@ func sendInteractivePasteInputCmd(sessionName, text string, bracketed bool) tea.
 	}
 }
 
-// sendPasteToTmux pastes multi-line text via tmux buffer.
-// Uses load-buffer + paste-buffer which works regardless of app paste mode state.
+// sendPasteToTmux pastes multi-line text into a session pane.
+// With tmux this uses load-buffer + paste-buffer, which works regardless of
+// app paste mode state.
 func sendPasteToTmux(sessionName, text string) error {
-	// Load text into tmux default buffer via stdin
-	loadCmd := exec.Command("tmux", "load-buffer", "-")
-	loadCmd.Stdin = strings.NewReader(text)
-	if err := loadCmd.Run(); err != nil {
-		return err
-	}
-
-	// Paste buffer into target pane
-	pasteCmd := exec.Command("tmux", "paste-buffer", "-t", sessionName)
-	return pasteCmd.Run()
+	return sessionBackend.Paste(sessionName, text, pastePlain)
 }
 
 // Bracketed paste escape sequences
@@ -274,18 +257,7 @@ func detectBracketedPasteMode(output string) bool {
 // sendBracketedPasteToTmux sends text wrapped in bracketed paste sequences.
 // Used when the target app has enabled bracketed paste mode.
 func sendBracketedPasteToTmux(sessionName, text string) error {
-	// Send bracketed paste start sequence
-	if err := sendLiteralToTmux(sessionName, bracketedPasteStart); err != nil {
-		return err
-	}
-
-	// Send the actual text
-	if err := sendLiteralToTmux(sessionName, text); err != nil {
-		return err
-	}
-
-	// Send bracketed paste end sequence
-	return sendLiteralToTmux(sessionName, bracketedPasteEnd)
+	return sessionBackend.Paste(sessionName, text, pasteBracketed)
 }
 
 func (p *Plugin) pasteClipboardToTmuxCmd() tea.Cmd {
@@ -684,34 +656,13 @@ func (p *Plugin) maybeResizeInteractivePane(paneWidth, paneHeight int) tea.Cmd {
 	return p.resizeInteractivePaneCmd()
 }
 
-// resizeTmuxPane resizes a tmux window/pane to the specified dimensions.
-// resize-window works for detached sessions; resize-pane is a fallback.
+// resizeTmuxPane resizes a session pane to the specified dimensions.
 func (p *Plugin) resizeTmuxPane(paneID string, width, height int) {
 	if width <= 0 && height <= 0 {
 		return
 	}
 
-	args := []string{"resize-window", "-t", paneID}
-	if width > 0 {
-		args = append(args, "-x", strconv.Itoa(width))
-	}
-	if height > 0 {
-		args = append(args, "-y", strconv.Itoa(height))
-	}
-	cmd := exec.Command("tmux", args...)
-	if err := cmd.Run(); err == nil {
-		return
-	}
-
-	// Fallback for older tmux or attached clients that reject resize-window.
-	args = []string{"resize-pane", "-t", paneID}
-	if width > 0 {
-		args = append(args, "-x", strconv.Itoa(width))
-	}
-	if height > 0 {
-		args = append(args, "-y", strconv.Itoa(height))
-	}
-	_ = exec.Command("tmux", args...).Run()
+	_ = sessionBackend.Resize(paneID, width, height)
 }
 
 func queryPaneSize(target string) (width, height int, ok bool) {
@@ -719,20 +670,7 @@ func queryPaneSize(target string) (width, height int, ok bool) {
 		return 0, 0, false
 	}
 
-	cmd := exec.Command("tmux", "display-message", "-t", target, "-p", "#{pane_width},#{pane_height}")
-	output, err := cmd.Output()
-	if err != nil {
-		return 0, 0, false
-	}
-
-	parts := strings.Split(strings.TrimSpace(string(output)), ",")
-	if len(parts) < 2 {
-		return 0, 0, false
-	}
-
-	width, _ = strconv.Atoi(parts[0])
-	height, _ = strconv.Atoi(parts[1])
-	return width, height, true
+	return sessionBackend.Size(target)
 }
 
 // resizeSelectedPaneCmd resizes the currently selected tmux pane to match the
@@ -768,7 +706,12 @@ func (p *Plugin) resizeForAttachCmd(target string) tea.Cmd {
 // attachWithResize resizes the tmux pane to full terminal, waits briefly for
 // tmux to process, then attaches. Centralizes resize-before-attach logic.
 func (p *Plugin) attachWithResize(target, sessionName, displayName string, onComplete func(error) tea.Msg) tea.Cmd {
-	c := exec.Command("tmux", "attach-session", "-t", sessionName)
+	c := sessionBackend.AttachCmd(sessionName)
+	if c == nil {
+		return func() tea.Msg {
+			return appmsg.ShowToast("Attach needs the tmux backend; use interactive mode instead", 3*time.Second)
+		}
+	}
 	termState, _ := term.GetState(int(os.Stdout.Fd()))
 	wrappedOnComplete := func(err error) tea.Msg {
 		if termState != nil {
@@ -1460,28 +1403,7 @@ func queryCursorPositionSync(target string) (row, col, paneHeight, paneWidth int
 		return 0, 0, 0, 0, false, false
 	}
 
-	cmd := exec.Command("tmux", "display-message", "-t", target,
-		"-p", "#{cursor_x},#{cursor_y},#{cursor_flag},#{pane_height},#{pane_width}")
-	output, err := cmd.Output()
-	if err != nil {
-		return 0, 0, 0, 0, false, false
-	}
-
-	parts := strings.Split(strings.TrimSpace(string(output)), ",")
-	if len(parts) < 2 {
-		return 0, 0, 0, 0, false, false
-	}
-
-	col, _ = strconv.Atoi(parts[0])
-	row, _ = strconv.Atoi(parts[1])
-	visible = len(parts) < 3 || parts[2] != "0"
-	if len(parts) >= 4 {
-		paneHeight, _ = strconv.Atoi(parts[3])
-	}
-	if len(parts) >= 5 {
-		paneWidth, _ = strconv.Atoi(parts[4])
-	}
-	return row, col, paneHeight, paneWidth, visible, true
+	return sessionBackend.Cursor(target)
 }
 
 // renderWithCursor overlays the cursor on content at the specified position.
@@ -23,10 +23,22 @@ func kanbanStatusForColumn(col int) (WorktreeStatus, bool) {
 // kanbanData holds pre-computed kanban column data for a render/update cycle.
 // Status columns contain worktrees first, then agent-shells.
 // The Shells column (index 0) contains only plain shells (no agent or orphaned).
+// On the task board, columns hold worktrees only, one per taskBoardColumns entry.
 type kanbanData struct {
 	worktrees   map[WorktreeStatus][]*Worktree
 	shells      map[WorktreeStatus][]*ShellSession
 	plainShells []*ShellSession
+
+	byTask   bool
+	taskCols [][]*Worktree
+}
+
+// columnCount returns the number of columns on the board.
+func (kd *kanbanData) columnCount() int {
+	if kd.byTask {
+		return len(kd.taskCols)
+	}
+	return kanbanColumnCount()
 }
 
 // kanbanShellStatus maps a shell's agent status to a WorktreeStatus for kanban routing.
@@ -53,6 +65,15 @@ func kanbanShellStatus(shell *ShellSession) (WorktreeStatus, bool) {
 
 // buildKanbanData computes column assignments for all worktrees and shells.
 func (p *Plugin) buildKanbanData() *kanbanData {
+	if p.kanbanByTask {
+		kd := &kanbanData{byTask: true, taskCols: make([][]*Worktree, len(taskBoardColumns))}
+		for _, wt := range p.worktrees {
+			col := p.taskBoardColumnOf(wt)
+			kd.taskCols[col] = append(kd.taskCols[col], wt)
+		}
+		return kd
+	}
+
 	kd := &kanbanData{
 		worktrees: map[WorktreeStatus][]*Worktree{
 			StatusActive:   {},
@@ -91,6 +112,12 @@ func (p *Plugin) buildKanbanData() *kanbanData {
 
 // columnItemCount returns total items (worktrees + shells) in a column.
 func (kd *kanbanData) columnItemCount(col int) int {
+	if kd.byTask {
+		if col < 0 || col >= len(kd.taskCols) {
+			return 0
+		}
+		return len(kd.taskCols[col])
+	}
 	if col == kanbanShellColumnIndex {
 		return len(kd.plainShells)
 	}
@@ -103,6 +130,12 @@ func (kd *kanbanData) columnItemCount(col int) int {
 
 // itemAt returns the worktree or shell at (col, row). Worktrees come first in each column.
 func (kd *kanbanData) itemAt(col, row int) (*Worktree, *ShellSession) {
+	if kd.byTask {
+		if col < 0 || col >= len(kd.taskCols) || row < 0 || row >= len(kd.taskCols[col]) {
+			return nil, nil
+		}
+		return kd.taskCols[col][row], nil
+	}
 	if col == kanbanShellColumnIndex {
 		if row >= 0 && row < len(kd.plainShells) {
 			return nil, kd.plainShells[row]
@@ -202,8 +235,8 @@ func (p *Plugin) moveKanbanColumn(delta int) {
 	if newCol < 0 {
 		newCol = 0
 	}
-	if newCol >= kanbanColumnCount() {
-		newCol = kanbanColumnCount() - 1
+	if newCol >= kd.columnCount() {
+		newCol = kd.columnCount() - 1
 	}
 
 	if newCol != p.kanbanCol {
@@ -249,6 +282,22 @@ func (p *Plugin) getKanbanWorktree(col, row int) *Worktree {
 func (p *Plugin) syncListToKanban() {
 	kd := p.buildKanbanData()
 
+	if kd.byTask {
+		// The task board shows worktrees only
+		p.kanbanCol, p.kanbanRow = 0, 0
+		if wt := p.selectedWorktree(); wt != nil && !p.shellSelected {
+			for colIdx, items := range kd.taskCols {
+				for rowIdx, item := range items {
+					if item.Name == wt.Name {
+						p.kanbanCol, p.kanbanRow = colIdx, rowIdx
+						return
+					}
+				}
+			}
+		}
+		return
+	}
+
 	if p.shellSelected {
 		// Find which column/row the selected shell is in
 		shell := p.getSelectedShell()
@@ -0,0 +1,337 @@
+package workspace
+
+import (
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	appmsg "github.com/marcus/sidecar/internal/msg"
+)
+
+// td task statuses shown on the task board.
+const (
+	tdStatusOpen       = "open"
+	tdStatusInProgress = "in_progress"
+	tdStatusInReview   = "in_review"
+	tdStatusBlocked    = "blocked"
+	tdStatusClosed     = "closed"
+)
+
+// taskBoardColumn is a column of the task board.
+type taskBoardColumn struct {
+	Status string // td status cards are moved to; "" for the No task column
+	Title  string
+}
+
+// taskBoardColumns are the task board's columns. Worktrees without a linked
+// task, or whose task status isn't known, go in the first.
+var taskBoardColumns = []taskBoardColumn{
+	{"", "No task"},
+	{tdStatusOpen, "○ Open"},
+	{tdStatusInProgress, "● In Progress"},
+	{tdStatusInReview, "◎ In Review"},
+	{tdStatusClosed, "✓ Done"},
+}
+
+// taskBoardTask is what the task board knows about a linked td task.
+type taskBoardTask struct {
+	Status string
+	Title  string
+}
+
+// taskBoardLoadedMsg delivers the statuses of linked tasks.
+type taskBoardLoadedMsg struct {
+	Epoch uint64
+	Tasks map[string]taskBoardTask // Task ID -> task
+	Err   error
+}
+
+// GetEpoch implements plugin.EpochMessage.
+func (m taskBoardLoadedMsg) GetEpoch() uint64 { return m.Epoch }
+
+// taskTransitionedMsg reports a td status transition.
+type taskTransitionedMsg struct {
+	Epoch    uint64
+	Worktree string // Worktree whose card was moved
+	TaskID   string
+	Status   string
+	Err      error
+}
+
+// GetEpoch implements plugin.EpochMessage.
+func (m taskTransitionedMsg) GetEpoch() uint64 { return m.Epoch }
+
+// taskBoardColumnFor returns the column for a td status. Blocked tasks sit
+// with open ones.
+func taskBoardColumnFor(status string) int {
+	if status == tdStatusBlocked {
+		status = tdStatusOpen
+	}
+	for i, col := range taskBoardColumns {
+		if col.Status != "" && col.Status == status {
+			return i
+		}
+	}
+	return 0
+}
+
+// taskBoardColumnOf returns the column of a worktree's card.
+func (p *Plugin) taskBoardColumnOf(wt *Worktree) int {
+	if wt.TaskID == "" {
+		return 0
+	}
+	return taskBoardColumnFor(p.taskBoard[wt.TaskID].Status)
+}
+
+// toggleKanbanBoard switches the kanban view between the agent status board
+// and the task board.
+func (p *Plugin) toggleKanbanBoard() tea.Cmd {
+	wt := p.selectedKanbanWorktree()
+	p.kanbanByTask = !p.kanbanByTask
+	if !p.followKanbanCard(wt) {
+		p.syncListToKanban()
+	}
+	if p.kanbanByTask {
+		return p.loadTaskBoard()
+	}
+	return nil
+}
+
+// loadTaskBoard fetches the status of every task linked to a worktree.
+func (p *Plugin) loadTaskBoard() tea.Cmd {
+	seen := make(map[string]bool)
+	var ids []string
+	for _, wt := range p.worktrees {
+		if wt.TaskID != "" && !seen[wt.TaskID] {
+			seen[wt.TaskID] = true
+			ids = append(ids, wt.TaskID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	p.taskBoardLoading = true
+	epoch := p.ctx.Epoch
+	workDir := p.ctx.WorkDir
+	return func() tea.Msg {
+		tasks := make(map[string]taskBoardTask, len(ids))
+		var firstErr error
+		for _, id := range ids {
+			task, err := tdShowTask(workDir, id)
+			if err != nil {
+				if firstErr == nil {
+					firstErr = err
+				}
+				continue
+			}
+			tasks[id] = task
+		}
+		// A deleted task shouldn't hide the rest; only fail if nothing loaded
+		if len(tasks) > 0 {
+			firstErr = nil
+		}
+		return taskBoardLoadedMsg{Epoch: epoch, Tasks: tasks, Err: firstErr}
+	}
+}
+
+// tdShowTask reads a task's status and title with td show.
+func tdShowTask(workDir, taskID string) (taskBoardTask, error) {
+	cmd := exec.Command("td", "show", taskID, "--json")
+	cmd.Dir = workDir
+	output, err := cmd.Output()
+	if err != nil {
+		return taskBoardTask{}, fmt.Errorf("td show %s: %w", taskID, err)
+	}
+	var task taskBoardTask
+	var raw struct {
+		Status string `json:"status"`
+		Title  string `json:"title"`
+	}
+	if err := json.Unmarshal(output, &raw); err != nil {
+		return task, fmt.Errorf("parse task json: %w", err)
+	}
+	task.Status, task.Title = raw.Status, raw.Title
+	return task, nil
+}
+
+// handleTaskBoardLoaded installs loaded task statuses and keeps the cursor
+// on the selected card as it changes column.
+func (p *Plugin) handleTaskBoardLoaded(msg taskBoardLoadedMsg) tea.Cmd {
+	p.taskBoardLoading = false
+	if msg.Err != nil {
+		return appmsg.ShowToast("Task board: "+msg.Err.Error(), 3*time.Second)
+	}
+	wt := p.selectedKanbanWorktree()
+	if p.taskBoard == nil {
+		p.taskBoard = make(map[string]taskBoardTask)
+	}
+	for id, task := range msg.Tasks {
+		p.taskBoard[id] = task
+	}
+	p.followKanbanCard(wt)
+	return nil
+}
+
+// followKanbanCard moves the kanban cursor to a worktree's card, wherever it
+// now is. It returns false if the worktree has no card.
+func (p *Plugin) followKanbanCard(wt *Worktree) bool {
+	if wt == nil {
+		return false
+	}
+	kd := p.buildKanbanData()
+	for col := 0; col < kd.columnCount(); col++ {
+		for row := 0; row < kd.columnItemCount(col); row++ {
+			if item, _ := kd.itemAt(col, row); item != nil && item.Name == wt.Name {
+				p.kanbanCol, p.kanbanRow = col, row
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// tdTransition returns the td commands that move a task from one status to
+// another. td only allows some transitions directly (a closed task must be
+// reopened before it can be started, say), so a move may take several.
+func tdTransition(taskID, from, to string) ([][]string, error) {
+	if from == to {
+		return nil, nil
+	}
+	cmd := func(name string) []string { return []string{name, taskID} }
+
+	// Steps back to an open task from each status
+	var reopen [][]string
+	switch from {
+	case tdStatusInProgress:
+		reopen = [][]string{cmd("unstart")}
+	case tdStatusBlocked:
+		reopen = [][]string{cmd("unblock")}
+	case tdStatusInReview, tdStatusClosed:
+		reopen = [][]string{cmd("reopen")}
+	}
+
+	switch to {
+	case tdStatusOpen:
+		return reopen, nil
+	case tdStatusInProgress:
+		if from == tdStatusInReview {
+			return [][]string{cmd("reject")}, nil
+		}
+		return append(reopen, cmd("start")), nil
+	case tdStatusInReview:
+		if from == tdStatusInProgress {
+			return [][]string{cmd("review")}, nil
+		}
+		return append(reopen, cmd("start"), cmd("review")), nil
+	case tdStatusClosed:
+		if from == tdStatusInReview {
+			return [][]string{cmd("approve")}, nil
+		}
+		return [][]string{cmd("close")}, nil
+	}
+	return nil, fmt.Errorf("unknown status %q", to)
+}
+
+// transitionTask runs the td commands that move a worktree's task to a status.
+func (p *Plugin) transitionTask(wt *Worktree, from, to string) tea.Cmd {
+	taskID, name := wt.TaskID, wt.Name
+	steps, err := tdTransition(taskID, from, to)
+	if err != nil {
+		return appmsg.ShowToast(err.Error(), 3*time.Second)
+	}
+	if len(steps) == 0 {
+		return nil
+	}
+	epoch := p.ctx.Epoch
+	workDir := p.ctx.WorkDir
+	return func() tea.Msg {
+		for _, args := range steps {
+			cmd := exec.Command("td", args...)
+			cmd.Dir = workDir
+			if out, err := cmd.CombinedOutput(); err != nil {
+				detail := strings.TrimSpace(string(out))
+				if detail == "" {
+					detail = err.Error()
+				}
+				return taskTransitionedMsg{Epoch: epoch, Worktree: name, TaskID: taskID, Err: fmt.Errorf("td %s: %s", args[0], detail)}
+			}
+		}
+		return taskTransitionedMsg{Epoch: epoch, Worktree: name, TaskID: taskID, Status: to}
+	}
+}
+
+// handleTaskTransitioned records a transition, then reloads the board to
+// pick up what td actually did.
+func (p *Plugin) handleTaskTransitioned(msg taskTransitionedMsg) tea.Cmd {
+	if msg.Err != nil {
+		return tea.Batch(
+			appmsg.ShowToast(msg.Err.Error(), 5*time.Second),
+			p.loadTaskBoard(),
+		)
+	}
+	if p.taskBoard == nil {
+		p.taskBoard = make(map[string]taskBoardTask)
+	}
+	task := p.taskBoard[msg.TaskID]
+	task.Status = msg.Status
+	p.taskBoard[msg.TaskID] = task
+	p.followKanbanCard(p.findWorktree(msg.Worktree))
+	return p.loadTaskBoard()
+}
+
+// moveTaskCard moves a worktree's card to a task board column, running the
+// matching td transition.
+func (p *Plugin) moveTaskCard(wt *Worktree, col int) tea.Cmd {
+	if wt == nil || col < 0 || col >= len(taskBoardColumns) {
+		return nil
+	}
+	if wt.TaskID == "" {
+		return appmsg.ShowToast("Link a task first (T)", 2*time.Second)
+	}
+	task, ok := p.taskBoard[wt.TaskID]
+	if !ok {
+		return appmsg.ShowToast("Task status not loaded yet", 2*time.Second)
+	}
+	to := taskBoardColumns[col].Status
+	if to == "" || col == p.taskBoardColumnOf(wt) {
+		return nil
+	}
+	return p.transitionTask(wt, task.Status, to)
+}
+
+// moveSelectedTaskCard moves the selected card one column left or right.
+func (p *Plugin) moveSelectedTaskCard(delta int) tea.Cmd {
+	if !p.kanbanByTask {
+		return appmsg.ShowToast("Switch to the task board (V) to move cards", 2*time.Second)
+	}
+	wt := p.selectedKanbanWorktree()
+	if wt == nil {
+		return nil
+	}
+	return p.moveTaskCard(wt, p.taskBoardColumnOf(wt)+delta)
+}
+
+// kanbanColumnAtX returns the board column under screen column x.
+func (p *Plugin) kanbanColumnAtX(x int) int {
+	if p.kanbanColWidth <= 0 {
+		return -1
+	}
+	col := (x - 2) / (p.kanbanColWidth + 1) // Columns start after the panel border
+	if x < 2 || col >= p.buildKanbanData().columnCount() {
+		return -1
+	}
+	return col
+}
+
+// dropKanbanCard finishes dragging a card on the task board, moving it to
+// the column it was dropped on.
+func (p *Plugin) dropKanbanCard() tea.Cmd {
+	col := p.kanbanColumnAtX(p.kanbanDropX)
+	if col < 0 {
+		return nil
+	}
+	return p.moveTaskCard(p.selectedKanbanWorktree(), col)
+}
@@ -0,0 +1,152 @@
+package workspace
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTdTransition(t *testing.T) {
+	tests := []struct {
+		from, to string
+		want     [][]string
+	}{
+		{tdStatusOpen, tdStatusOpen, nil},
+		{tdStatusOpen, tdStatusInProgress, [][]string{{"start", "td-1"}}},
+		{tdStatusOpen, tdStatusInReview, [][]string{{"start", "td-1"}, {"review", "td-1"}}},
+		{tdStatusOpen, tdStatusClosed, [][]string{{"close", "td-1"}}},
+		{tdStatusInProgress, tdStatusOpen, [][]string{{"unstart", "td-1"}}},
+		{tdStatusInProgress, tdStatusInReview, [][]string{{"review", "td-1"}}},
+		{tdStatusInReview, tdStatusInProgress, [][]string{{"reject", "td-1"}}},
+		{tdStatusInReview, tdStatusClosed, [][]string{{"approve", "td-1"}}},
+		{tdStatusBlocked, tdStatusInProgress, [][]string{{"unblock", "td-1"}, {"start", "td-1"}}},
+		{tdStatusClosed, tdStatusOpen, [][]string{{"reopen", "td-1"}}},
+		{tdStatusClosed, tdStatusInProgress, [][]string{{"reopen", "td-1"}, {"start", "td-1"}}},
+	}
+	for _, tt := range tests {
+		got, err := tdTransition("td-1", tt.from, tt.to)
+		if err != nil {
+			t.Errorf("%s -> %s: unexpected error %v", tt.from, tt.to, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s -> %s = %v, want %v", tt.from, tt.to, got, tt.want)
+		}
+	}
+
+	if _, err := tdTransition("td-1", tdStatusOpen, "bogus"); err == nil {
+		t.Error("expected error for unknown status")
+	}
+}
+
+func TestTaskBoardColumns(t *testing.T) {
+	p := &Plugin{
+		worktrees: []*Worktree{
+			{Name: "no-task"},
+			{Name: "open", TaskID: "td-1"},
+			{Name: "blocked", TaskID: "td-2"},
+			{Name: "review", TaskID: "td-3"},
+			{Name: "unloaded", TaskID: "td-4"},
+		},
+		shells:       []*ShellSession{{Name: "Shell 1"}},
+		kanbanByTask: true,
+		taskBoard: map[string]taskBoardTask{
+			"td-1": {Status: tdStatusOpen},
+			"td-2": {Status: tdStatusBlocked},
+			"td-3": {Status: tdStatusInReview},
+		},
+	}
+
+	kd := p.buildKanbanData()
+	if kd.columnCount() != len(taskBoardColumns) {
+		t.Fatalf("expected %d columns, got %d", len(taskBoardColumns), kd.columnCount())
+	}
+	want := map[int][]string{
+		0: {"no-task", "unloaded"}, // Shells aren't on the task board
+		1: {"open", "blocked"},
+		3: {"review"},
+	}
+	for col := 0; col < kd.columnCount(); col++ {
+		var names []string
+		for row := 0; row < kd.columnItemCount(col); row++ {
+			wt, shell := kd.itemAt(col, row)
+			if shell != nil {
+				t.Errorf("column %d: unexpected shell card", col)
+			} else if wt != nil {
+				names = append(names, wt.Name)
+			}
+		}
+		if !reflect.DeepEqual(names, want[col]) {
+			t.Errorf("column %d = %v, want %v", col, names, want[col])
+		}
+	}
+}
+
+func TestMoveTaskCardNoop(t *testing.T) {
+	p := &Plugin{
+		kanbanByTask: true,
+		taskBoard:    map[string]taskBoardTask{"td-1": {Status: tdStatusInProgress}},
+	}
+
+	if cmd := p.moveTaskCard(&Worktree{Name: "wt"}, 1); cmd == nil {
+		t.Error("expected toast for worktree without a task")
+	}
+	if cmd := p.moveTaskCard(&Worktree{Name: "wt", TaskID: "td-9"}, 1); cmd == nil {
+		t.Error("expected toast for task without a loaded status")
+	}
+	wt := &Worktree{Name: "wt", TaskID: "td-1"}
+	if cmd := p.moveTaskCard(wt, 2); cmd != nil {
+		t.Error("expected no-op when dropping on the card's own column")
+	}
+	if cmd := p.moveTaskCard(wt, 0); cmd != nil {
+		t.Error("expected no-op when dropping on the No task column")
+	}
+	if cmd := p.moveTaskCard(wt, len(taskBoardColumns)); cmd != nil {
+		t.Error("expected no-op for out of range column")
+	}
+}
+
+func TestFollowKanbanCard(t *testing.T) {
+	wt := &Worktree{Name: "wt2", TaskID: "td-2"}
+	p := &Plugin{
+		worktrees:    []*Worktree{{Name: "wt1", TaskID: "td-1"}, wt},
+		kanbanByTask: true,
+		taskBoard: map[string]taskBoardTask{
+			"td-1": {Status: tdStatusInProgress},
+			"td-2": {Status: tdStatusInProgress},
+		},
+	}
+
+	if !p.followKanbanCard(wt) || p.kanbanCol != 2 || p.kanbanRow != 1 {
+		t.Fatalf("expected cursor on (2,1), got (%d,%d)", p.kanbanCol, p.kanbanRow)
+	}
+
+	p.taskBoard["td-2"] = taskBoardTask{Status: tdStatusClosed}
+	if !p.followKanbanCard(wt) || p.kanbanCol != 4 || p.kanbanRow != 0 {
+		t.Errorf("expected cursor to follow card to (4,0), got (%d,%d)", p.kanbanCol, p.kanbanRow)
+	}
+	if p.followKanbanCard(&Worktree{Name: "gone"}) {
+		t.Error("expected false for a worktree without a card")
+	}
+}
+
+func TestKanbanColumnAtX(t *testing.T) {
+	p := &Plugin{kanbanByTask: true}
+	if col := p.kanbanColumnAtX(10); col != -1 {
+		t.Errorf("expected -1 before the board is rendered, got %d", col)
+	}
+
+	p.kanbanColWidth = 20
+	tests := []struct{ x, want int }{
+		{0, -1},
+		{2, 0},
+		{21, 0},
+		{23, 1},
+		{2 + 4*21, 4},
+		{2 + 5*21, -1},
+	}
+	for _, tt := range tests {
+		if got := p.kanbanColumnAtX(tt.x); got != tt.want {
+			t.Errorf("kanbanColumnAtX(%d) = %d, want %d", tt.x, got, tt.want)
+		}
+	}
+}
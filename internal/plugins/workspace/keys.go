@@ -3,7 +3,6 @@ package workspace
 import (
 	"fmt"
 	"math"
-	"os/exec"
 	"strings"
 	"time"
 
@@ -43,6 +42,22 @@ func (p *Plugin) handleKeyPress(msg tea.KeyMsg) tea.Cmd {
 		return p.handleRenameShellKeys(msg)
 	case ViewModeFetchPR:
 		return p.handleFetchPRKeys(msg)
+	case ViewModeFanOut:
+		return p.handleFanOutKeys(msg)
+	case ViewModeFanOutBoard:
+		return p.handleFanOutBoardKeys(msg)
+	case ViewModePromptQueue:
+		return p.handlePromptQueueKeys(msg)
+	case ViewModeReviewComment:
+		return p.handleReviewCommentKeys(msg)
+	case ViewModeReview:
+		return p.handleReviewKeys(msg)
+	case ViewModeSyncConflict:
+		return p.handleSyncConflictKeys(msg)
+	case ViewModeOutputSearch:
+		return p.handleOutputSearchKeys(msg)
+	case ViewModeTranscripts:
+		return p.handleTranscriptsKeys(msg)
 	case ViewModeFilePicker:
 		return p.handleFilePickerKeys(msg)
 	case ViewModeInteractive:
@@ -51,6 +66,109 @@ func (p *Plugin) handleKeyPress(msg tea.KeyMsg) tea.Cmd {
 	return nil
 }
 
+// handleSyncConflictKeys handles keys in the sync conflict modal.
+func (p *Plugin) handleSyncConflictKeys(msg tea.KeyMsg) tea.Cmd {
+	s := p.syncState
+	if s == nil {
+		p.viewMode = ViewModeList
+		return nil
+	}
+	if s.Running {
+		return nil
+	}
+
+	switch msg.String() {
+	case "esc", "q":
+		p.closeSyncConflict()
+	case "r":
+		return p.executeRebaseResolution()
+	case "m":
+		return p.executeMergeResolution()
+	case "a":
+		if s.Worktree.Agent == nil {
+			return nil
+		}
+		if s.Resolving != "" {
+			return p.handSyncToAgent()
+		}
+		s.Handoff = true
+		return p.executeSyncResolution(s.Strategy)
+	case "x":
+		return p.abortSync()
+	}
+	return nil
+}
+
+// handleOutputSearchKeys handles keys in the scrollback search.
+func (p *Plugin) handleOutputSearchKeys(msg tea.KeyMsg) tea.Cmd {
+	s := p.outputSearch
+	if s == nil {
+		p.viewMode = ViewModeList
+		return nil
+	}
+
+	if s.Editing {
+		switch msg.String() {
+		case "esc":
+			p.closeOutputSearch()
+		case "enter":
+			s.Editing = false
+			s.Input.Blur()
+		case "ctrl+r":
+			s.Regex = !s.Regex
+			p.updateOutputMatches()
+		case "up", "ctrl+p":
+			p.moveOutputMatch(-1)
+		case "down", "ctrl+n":
+			p.moveOutputMatch(1)
+		default:
+			query := s.Input.Value()
+			var cmd tea.Cmd
+			s.Input, cmd = s.Input.Update(msg)
+			if s.Input.Value() != query {
+				p.updateOutputMatches()
+			}
+			return cmd
+		}
+		return nil
+	}
+
+	switch msg.String() {
+	case "esc", "q":
+		p.closeOutputSearch()
+	case "/":
+		s.Editing = true
+		return s.Input.Focus()
+	case "n":
+		p.moveOutputMatch(1)
+	case "N":
+		p.moveOutputMatch(-1)
+	case "j", "down":
+		p.scrollOutputSearch(1)
+	case "k", "up":
+		p.scrollOutputSearch(-1)
+	case "ctrl+d":
+		p.scrollOutputSearch(p.outputSearchHeight() / 2)
+	case "ctrl+u":
+		p.scrollOutputSearch(-p.outputSearchHeight() / 2)
+	case "g":
+		p.scrollOutputSearch(-len(s.Lines))
+	case "G":
+		p.scrollOutputSearch(len(s.Lines))
+	case "ctrl+r":
+		s.Regex = !s.Regex
+		p.updateOutputMatches()
+	case "r":
+		s.Loading = true
+		return p.loadOutputHistory(s)
+	case "y":
+		return p.copyOutputMatchBlock()
+	case "o", "enter":
+		return p.openOutputFileRef()
+	}
+	return nil
+}
+
 // handleTypeSelectorKeys handles keys in the type selector modal.
 func (p *Plugin) handleTypeSelectorKeys(msg tea.KeyMsg) tea.Cmd {
 	p.ensureTypeSelectorModal()
@@ -161,6 +279,168 @@ func (p *Plugin) handleFetchPRKeys(msg tea.KeyMsg) tea.Cmd {
 	}
 }
 
+// handleFanOutKeys handles keys in the fan-out modal.
+func (p *Plugin) handleFanOutKeys(msg tea.KeyMsg) tea.Cmd {
+	p.ensureFanOutModal()
+	if p.fanOutModal == nil {
+		return nil
+	}
+
+	// Handle Enter manually: the prompt textarea takes newlines, checkboxes
+	// toggle, and only the buttons act.
+	if msg.String() == "enter" {
+		switch focusID := p.fanOutModal.FocusedID(); {
+		case focusID == fanOutSubmitID:
+			if p.fanOutCreating {
+				return nil
+			}
+			return p.executeFanOut()
+		case focusID == fanOutCancelID:
+			p.viewMode = ViewModeList
+			p.clearFanOutModal()
+			return nil
+		case focusID == fanOutPromptFieldID,
+			focusID == fanOutSkipPermissionsID,
+			strings.HasPrefix(focusID, fanOutAgentItemPrefix):
+			_, cmd := p.fanOutModal.HandleKey(msg)
+			return cmd
+		}
+		return nil
+	}
+
+	p.fanOutError = ""
+	action, cmd := p.fanOutModal.HandleKey(msg)
+	switch action {
+	case "cancel", fanOutCancelID:
+		if p.fanOutCreating {
+			return nil
+		}
+		p.viewMode = ViewModeList
+		p.clearFanOutModal()
+		return nil
+	}
+	return cmd
+}
+
+// handleFanOutBoardKeys handles keys in the fan-out comparison board.
+func (p *Plugin) handleFanOutBoardKeys(msg tea.KeyMsg) tea.Cmd {
+	group := p.fanOutBoard
+	if group == nil {
+		p.viewMode = ViewModeList
+		return nil
+	}
+
+	if p.fanOutConfirmWinner {
+		switch msg.String() {
+		case "y", "Y":
+			return p.pickFanOutWinner()
+		case "n", "N", "esc":
+			p.fanOutConfirmWinner = false
+		}
+		return nil
+	}
+
+	switch msg.String() {
+	case "esc", "q":
+		p.closeFanOutBoard()
+	case "j", "down":
+		if p.fanOutBoardIdx < len(group.Members)-1 {
+			p.fanOutBoardIdx++
+		}
+	case "k", "up":
+		if p.fanOutBoardIdx > 0 {
+			p.fanOutBoardIdx--
+		}
+	case "r":
+		return p.loadFanOutMetrics()
+	case "t":
+		return p.runFanOutTests()
+	case "w":
+		if len(group.Members) > 0 {
+			p.fanOutConfirmWinner = true
+		}
+	case "enter":
+		// Go to the selected member in the list
+		if p.fanOutBoardIdx < len(group.Members) {
+			name := group.Members[p.fanOutBoardIdx].Name
+			p.closeFanOutBoard()
+			for i, wt := range p.worktrees {
+				if wt.Name == name {
+					p.shellSelected = false
+					p.selectedIdx = i
+					p.ensureVisible()
+					p.saveSelectionState()
+					return p.loadSelectedContent()
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// handlePromptQueueKeys handles keys in the prompt queue editor. The input
+// adds prompts; the list takes the pause/skip/reorder keys.
+func (p *Plugin) handlePromptQueueKeys(msg tea.KeyMsg) tea.Cmd {
+	slot, _, ok := p.queueSlot(p.queueKey)
+	p.ensurePromptQueueModal()
+	if !ok || p.queueModal == nil {
+		p.clearPromptQueueModal()
+		return nil
+	}
+
+	if p.queueModal.FocusedID() == promptQueueListID {
+		q := p.promptQueue()
+		switch msg.String() {
+		case "esc", "q":
+			p.clearPromptQueueModal()
+		case "j", "down":
+			if p.queueIdx < q.Len()-1 {
+				p.queueIdx++
+			}
+		case "k", "up":
+			if p.queueIdx > 0 {
+				p.queueIdx--
+			}
+		case "J", "shift+down":
+			p.queueIdx = q.Move(p.queueIdx, 1)
+			p.persistQueue(p.queueKey)
+		case "K", "shift+up":
+			p.queueIdx = q.Move(p.queueIdx, -1)
+			p.persistQueue(p.queueKey)
+		case "x", "d", "delete":
+			if q.Remove(p.queueIdx) {
+				if p.queueIdx >= q.Len() && p.queueIdx > 0 {
+					p.queueIdx--
+				}
+				p.persistQueue(p.queueKey)
+			}
+		case "p", " ":
+			if *slot == nil {
+				*slot = &PromptQueue{}
+			}
+			(*slot).Paused = !(*slot).Paused
+			p.persistQueue(p.queueKey)
+			return p.dispatchQueue(p.queueKey)
+		case "tab", "shift+tab":
+			p.queueModal.HandleKey(msg)
+		}
+		return nil
+	}
+
+	if msg.String() == "enter" {
+		text := p.queueInput.Value()
+		p.queueInput.SetValue("")
+		return p.enqueuePrompt(p.queueKey, text)
+	}
+
+	action, cmd := p.queueModal.HandleKey(msg)
+	if action == "cancel" {
+		p.clearPromptQueueModal()
+		return nil
+	}
+	return cmd
+}
+
 // handlePromptPickerKeys handles keys in the prompt picker modal.
 func (p *Plugin) handlePromptPickerKeys(msg tea.KeyMsg) tea.Cmd {
 	if p.promptPicker == nil {
@@ -175,6 +455,10 @@ func (p *Plugin) handlePromptPickerKeys(msg tea.KeyMsg) tea.Cmd {
 	pp := p.promptPicker
 	key := msg.String()
 
+	if pp.answering != nil {
+		return p.handlePromptAnswerKeys(msg)
+	}
+
 	if len(pp.prompts) == 0 && key == "d" {
 		return func() tea.Msg { return PromptInstallDefaultsMsg{} }
 	}
@@ -441,7 +725,7 @@ func (p *Plugin) executeDelete() tea.Cmd {
 	// Kill tmux session if it exists (before deleting worktree)
 	sessionName := tmuxSessionPrefix + sanitizeName(name)
 	if sessionExists(sessionName) {
-		_ = exec.Command("tmux", "kill-session", "-t", sessionName).Run()
+		_ = sessionBackend.Kill(sessionName)
 	}
 	delete(p.managedSessions, sessionName)
 	globalPaneCache.remove(sessionName)
@@ -678,9 +962,9 @@ func (p *Plugin) handleListKeys(msg tea.KeyMsg) tea.Cmd {
 		}
 		if p.activePane == PaneSidebar {
 			// Jump to bottom = select last worktree (not shell)
-			if len(p.worktrees) > 0 {
+			if p.sidebarItemCount() > 0 {
 				p.shellSelected = false
-				p.selectedIdx = len(p.worktrees) - 1
+				p.selectedIdx = p.sidebarItemCount() - 1
 				// Exit interactive mode when switching selection (td-fc758e88)
 				p.exitInteractiveMode()
 				p.saveSelectionState()
@@ -963,6 +1247,14 @@ func (p *Plugin) handleListKeys(msg tea.KeyMsg) tea.Cmd {
 		if p.activePane != PanePreview || p.previewTab == PreviewTabOutput {
 			return p.enterInteractiveMode()
 		}
+	case "c":
+		// In diff tab: comment on a diff line
+		if p.activePane == PanePreview && p.previewTab == PreviewTabDiff {
+			return p.handleDiffTabKey(msg)
+		}
+	case "C":
+		// Review comments for the selected worktree
+		return p.openReviewModal()
 	case "v":
 		// In preview pane on diff tab: cycle view mode
 		if p.activePane == PanePreview && p.previewTab == PreviewTabDiff {
@@ -972,6 +1264,9 @@ func (p *Plugin) handleListKeys(msg tea.KeyMsg) tea.Cmd {
 			case ViewModeList:
 				p.viewMode = ViewModeKanban
 				p.syncListToKanban()
+				if p.kanbanByTask {
+					return tea.Batch(p.pollAllAgentStatusesNow(), p.loadTaskBoard())
+				}
 				return p.pollAllAgentStatusesNow()
 			case ViewModeKanban:
 				p.viewMode = ViewModeList
@@ -983,6 +1278,18 @@ func (p *Plugin) handleListKeys(msg tea.KeyMsg) tea.Cmd {
 		if p.activePane == PanePreview && p.previewTab == PreviewTabDiff {
 			return p.handleDiffTabKey(msg)
 		}
+		// Kanban: switch between the agent status and td task boards
+		if p.viewMode == ViewModeKanban {
+			return p.toggleKanbanBoard()
+		}
+	case "<", ">":
+		// Task board: move the selected card, running the td transition
+		if p.viewMode == ViewModeKanban {
+			if msg.String() == "<" {
+				return p.moveSelectedTaskCard(-1)
+			}
+			return p.moveSelectedTaskCard(1)
+		}
 	case "ctrl+d":
 		// Page down in preview pane
 		if p.activePane == PanePreview {
@@ -1125,6 +1432,46 @@ func (p *Plugin) handleListKeys(msg tea.KeyMsg) tea.Cmd {
 			p.taskSearchLoading = true
 			return p.loadOpenTasks()
 		}
+	case "A":
+		// Archive the selected worktree, or restore the selected archived entry
+		if p.selectedArchive() != nil {
+			return p.restoreSelectedArchive()
+		}
+		return p.archiveSelected()
+	case "u":
+		// Sync the selected worktree with its base branch
+		return p.syncSelected()
+	case "U":
+		// Sync every worktree with its base branch
+		return p.syncAll()
+	case "P":
+		// Push the selected worktree's stack and open chained PRs
+		if wt := p.selectedWorktree(); wt != nil && p.isStacked(wt) {
+			return p.createStackPRs()
+		}
+		return nil
+	case "/":
+		// Search the scrollback of the agent or shell shown in the preview
+		if p.activePane == PanePreview && (p.previewTab == PreviewTabOutput || p.shellSelected) {
+			return p.openOutputSearch()
+		}
+		return nil
+	case "L":
+		// Browse recorded session transcripts
+		return p.openTranscripts()
+	case "Q":
+		// Edit the prompt queue for the selected worktree or agent shell
+		p.openPromptQueueModal()
+		return nil
+	case "B":
+		// Fan out one prompt to several agents (best of N)
+		p.openFanOutModal()
+		return nil
+	case "b":
+		// Open the fan-out comparison board for the selected worktree
+		if group := p.fanOutFor(p.selectedWorktree()); group != nil {
+			return p.openFanOutBoard(group)
+		}
 	case "F":
 		// Fetch remote PR as workspace
 		p.viewMode = ViewModeFetchPR
@@ -1153,6 +1500,11 @@ func (p *Plugin) handleListKeys(msg tea.KeyMsg) tea.Cmd {
 		if wt != nil {
 			return p.openInGitTab(wt)
 		}
+	case "H":
+		// Open the worktree's latest agent session in the conversations plugin
+		if !p.shellSelected {
+			return p.jumpToConversation(p.selectedWorktree())
+		}
 	case "ctrl+t":
 		// Toggle terminal panel visibility (on/off with last layout)
 		p.ctx.Logger.Debug("termPanel: ctrl+t pressed", "currentlyVisible", p.termPanelVisible)
@@ -1162,6 +1514,11 @@ func (p *Plugin) handleListKeys(msg tea.KeyMsg) tea.Cmd {
 		p.ctx.Logger.Debug("termPanel: alt+t pressed, switching layout")
 		return p.switchTermPanelLayout()
 	default:
+		// Preset keys from config open the create modal with the preset
+		// applied. Checked after the built-in keys so they can't replace them.
+		if idx := p.presetForKey(msg.String()); idx >= 0 {
+			return p.openCreateModalWithPreset(idx)
+		}
 		// Unhandled key in preview pane - flash to indicate attach is needed
 		// Only flash on the Output tab where there's a terminal to attach to.
 		// Diff and Task tabs have no interactive terminal.
@@ -1183,6 +1540,19 @@ func (p *Plugin) handleCreateKeys(msg tea.KeyMsg) tea.Cmd {
 		return nil
 	}
 
+	// The worktree exists once provisioning starts; wait for it, then let
+	// failed steps be read before moving on
+	if p.createProvisioning {
+		return nil
+	}
+	if done := p.createProvisionDone; done != nil {
+		switch msg.String() {
+		case "enter", "esc":
+			return p.finishCreate(*done)
+		}
+		return nil
+	}
+
 	focusID := p.createModal.FocusedID()
 
 	switch msg.String() {
@@ -1204,6 +1574,8 @@ func (p *Plugin) handleCreateKeys(msg tea.KeyMsg) tea.Cmd {
 		p.focusCreateInput()
 		p.syncCreateModalFocus()
 		return nil
+	case "ctrl+p":
+		return p.cycleCreatePreset()
 	case "backspace":
 		if p.createFocus == 3 && p.createTaskID != "" {
 			p.createTaskID = ""
@@ -1255,8 +1627,7 @@ func (p *Plugin) handleCreateKeys(msg tea.KeyMsg) tea.Cmd {
 		}
 		if idx, ok := parseIndexedID(createTaskItemPrefix, focusID); ok && idx < len(p.taskSearchFiltered) {
 			task := p.taskSearchFiltered[idx]
-			p.createTaskID = task.ID
-			p.createTaskTitle = task.Title
+			p.linkCreateTask(task.ID, task.Title)
 			p.taskSearchInput.Blur()
 			p.createFocus = 4
 			p.syncCreateModalFocus()
@@ -1288,8 +1659,7 @@ func (p *Plugin) handleCreateKeys(msg tea.KeyMsg) tea.Cmd {
 		}
 		if p.createFocus == 3 && len(p.taskSearchFiltered) > 0 {
 			selectedTask := p.taskSearchFiltered[p.taskSearchIdx]
-			p.createTaskID = selectedTask.ID
-			p.createTaskTitle = selectedTask.Title
+			p.linkCreateTask(selectedTask.ID, selectedTask.Title)
 			p.taskSearchInput.Blur()
 			p.createFocus = 4
 			p.syncCreateModalFocus()
@@ -1485,6 +1855,35 @@ func (p *Plugin) handleMergeKeys(msg tea.KeyMsg) tea.Cmd {
 		return nil
 	}
 
+	// Test gate failed — retry, hand the output to the agent, or cancel
+	if p.mergeState.Step == MergeStepVerify && p.mergeState.TestFailed {
+		switch msg.String() {
+		case "r":
+			return p.runMergeTests()
+		case "f":
+			return p.sendMergeTestFailure()
+		case "esc", "q":
+			p.cancelMergeWorkflow()
+			p.clearMergeModal()
+			return nil
+		}
+		if p.mergeModal != nil {
+			action, cmd := p.mergeModal.HandleKey(msg)
+			switch action {
+			case mergeTestRetryID:
+				return p.runMergeTests()
+			case mergeTestFixID:
+				return p.sendMergeTestFailure()
+			case "cancel":
+				p.cancelMergeWorkflow()
+				p.clearMergeModal()
+				return nil
+			}
+			return cmd
+		}
+		return nil
+	}
+
 	// For PostMergeConfirmation step, delegate to modal library for Tab/Enter/Space
 	if p.mergeState.Step == MergeStepPostMergeConfirmation && p.mergeModal != nil {
 		action, cmd := p.mergeModal.HandleKey(msg)
@@ -1616,6 +2015,13 @@ func (p *Plugin) handleMergeKeys(msg tea.KeyMsg) tea.Cmd {
 		}
 		return nil
 
+	case "n":
+		// Merge the next worktree up the stack (Done step after a stacked merge)
+		if p.mergeState.Step == MergeStepDone {
+			return p.mergeNextInStack()
+		}
+		return nil
+
 	case "m":
 		// Merge action (only when branch diverged in Done step)
 		// Note: 'm' in list view starts merge workflow, but here we're in MergeStepDone
@@ -1950,6 +2356,9 @@ func (p *Plugin) handleDiffTabDiffPaneKey(msg tea.KeyMsg) tea.Cmd {
 		}
 	case "v", "V":
 		return p.cycleDiffTabViewMode()
+	case "c":
+		// Comment on the first line shown
+		return p.openReviewCommentModal()
 	case "{":
 		// Previous file
 		return p.jumpToPrevFile()
@@ -23,6 +23,7 @@ const (
 	MergeStepReviewDiff  MergeWorkflowStep = iota
 	MergeStepTargetBranch                  // Choose target branch for merge/PR
 	MergeStepMergeMethod                   // Choose: PR workflow or direct merge
+	MergeStepVerify                        // Run the project's test command before push/merge
 	MergeStepPush
 	MergeStepGeneratePR                    // Agent generates PR title/body
 	MergeStepCreatePR
@@ -43,6 +44,8 @@ func (s MergeWorkflowStep) String() string {
 		return "Target Branch"
 	case MergeStepMergeMethod:
 		return "Merge Method"
+	case MergeStepVerify:
+		return "Run Tests"
 	case MergeStepPush:
 		return "Push Branch"
 	case MergeStepGeneratePR:
@@ -100,6 +103,12 @@ type MergeWorkflowState struct {
 	ConfirmationFocus   int  // 0-3=checkboxes, 4=confirm btn, 5=skip btn
 	ConfirmationHover   int  // Mouse hover state
 
+	// Test gate state
+	TestCommand string             // Command run in the Verify step
+	TestOutput  *outputTail        // Streaming output of the test command
+	TestCancel  context.CancelFunc // Cancels the running test command
+	TestFailed  bool               // Tests failed; merging is blocked
+
 	// PR generation state
 	PRGenerationDots   int            // Animation counter for progress dots (0-3)
 	PRGenerationCancel context.CancelFunc // Cancel func for in-flight agent process
@@ -107,6 +116,9 @@ type MergeWorkflowState struct {
 	// Cleanup results for summary display
 	CleanupResults     *CleanupResults
 	PendingCleanupOps  int // Counter for parallel cleanup operations in flight
+
+	// Stack state
+	StackNext string // Retargeted worktree to merge next, if this one had a stack above it
 }
 
 // CleanupResults holds the results of cleanup operations for display in summary.
@@ -280,6 +292,14 @@ func (p *Plugin) startMergeWorkflow(wt *Worktree) tea.Cmd {
 		return nil
 	}
 
+	// Stacks merge bottom-up: the base of a stacked worktree is not merged yet
+	if root := p.stackRoot(wt); root != wt {
+		return tea.Batch(
+			appmsg.ShowToast(fmt.Sprintf("%s is stacked on %s; merging %s first", wt.Name, p.stackParent(wt).Name, root.Name), 3*time.Second),
+			p.checkUncommittedChanges(root),
+		)
+	}
+
 	// Check for uncommitted changes before proceeding
 	return p.checkUncommittedChanges(wt)
 }
@@ -973,6 +993,7 @@ type RebaseResolutionMsg struct {
 	Branch       string
 	Success      bool
 	Err          error
+	Conflicts    []string // Files left conflicted in the worktree (sync only)
 }
 
 // MergeResolutionMsg signals result of merge resolution attempt.
@@ -981,10 +1002,15 @@ type MergeResolutionMsg struct {
 	Branch       string
 	Success      bool
 	Err          error
+	Conflicts    []string // Files left conflicted in the worktree (sync only)
 }
 
 // executeRebaseResolution performs git pull --rebase to resolve diverged branches.
+// For a conflicted sync it rebases the worktree onto its base branch instead.
 func (p *Plugin) executeRebaseResolution() tea.Cmd {
+	if p.viewMode == ViewModeSyncConflict {
+		return p.executeSyncResolution(syncRebase)
+	}
 	if p.mergeState == nil || p.mergeState.CleanupResults == nil {
 		return nil
 	}
@@ -1016,7 +1042,11 @@ func (p *Plugin) executeRebaseResolution() tea.Cmd {
 }
 
 // executeMergeResolution performs git pull (with merge) to resolve diverged branches.
+// For a conflicted sync it merges the base branch into the worktree instead.
 func (p *Plugin) executeMergeResolution() tea.Cmd {
+	if p.viewMode == ViewModeSyncConflict {
+		return p.executeSyncResolution(syncMerge)
+	}
 	if p.mergeState == nil || p.mergeState.CleanupResults == nil {
 		return nil
 	}
@@ -1096,7 +1126,7 @@ func (p *Plugin) performSelectedCleanup(wt *Worktree, state *MergeWorkflowState)
 		branch := wt.Branch
 
 		// Stop agent if running and clean up tracking (always do this)
-		_ = exec.Command("tmux", "kill-session", "-t", sessionName).Run()
+		_ = sessionBackend.Kill(sessionName)
 		delete(p.managedSessions, sessionName)
 		globalPaneCache.remove(sessionName)
 
@@ -1203,21 +1233,19 @@ func (p *Plugin) advanceMergeStep() tea.Cmd {
 		p.mergeState.StepStatus[MergeStepMergeMethod] = "done"
 		p.mergeState.UseDirectMerge = p.mergeState.MergeMethodOption == 1
 
-		if p.mergeState.UseDirectMerge {
-			// Direct merge path - skip PR workflow
-			p.mergeState.StepStatus[MergeStepPush] = "skipped"
-			p.mergeState.StepStatus[MergeStepGeneratePR] = "skipped"
-			p.mergeState.StepStatus[MergeStepCreatePR] = "skipped"
-			p.mergeState.StepStatus[MergeStepWaitingMerge] = "skipped"
-			p.mergeState.Step = MergeStepDirectMerge
-			p.mergeState.StepStatus[MergeStepDirectMerge] = "running"
-			return p.performDirectMerge(p.mergeState.Worktree, p.mergeState.TargetBranch)
+		// Test gate: verify the worktree before anything leaves it
+		if command := p.resolveTestCommand(p.mergeState.Worktree.Path, resolveBaseBranch(p.mergeState.Worktree)); command != "" {
+			p.mergeState.Step = MergeStepVerify
+			p.mergeState.TestCommand = command
+			return p.runMergeTests()
 		}
+		p.mergeState.StepStatus[MergeStepVerify] = "skipped"
+		return p.startMergeDelivery()
 
-		// PR workflow path - push first
-		p.mergeState.Step = MergeStepPush
-		p.mergeState.StepStatus[MergeStepPush] = "running"
-		return p.pushForMerge(p.mergeState.Worktree)
+	case MergeStepVerify:
+		// Tests passed
+		p.mergeState.StepStatus[MergeStepVerify] = "done"
+		return p.startMergeDelivery()
 
 	case MergeStepDirectMerge:
 		// Direct merge completed, go to confirmation
@@ -1231,7 +1259,10 @@ func (p *Plugin) advanceMergeStep() tea.Cmd {
 		// Pull option: default checked if current branch matches base branch
 		p.mergeState.PullAfterMerge = p.mergeState.CurrentBranch == p.mergeState.TargetBranch
 		p.mergeState.ConfirmationFocus = 0
-		return nil
+		return tea.Batch(
+			p.retargetStackChildren(p.mergeState.Worktree, p.mergeState.TargetBranch),
+			p.deleteFanOutLosers(p.mergeState.Worktree),
+		)
 
 	case MergeStepPush:
 		// Mark Push as done, move to PR generation step
@@ -1281,7 +1312,12 @@ func (p *Plugin) advanceMergeStep() tea.Cmd {
 		// Pull option: default checked if current branch matches base branch
 		p.mergeState.PullAfterMerge = p.mergeState.CurrentBranch == p.mergeState.TargetBranch
 		p.mergeState.ConfirmationFocus = 0
-		return nil // Wait for user interaction
+		// Wait for user interaction; meanwhile move any stack above onto the
+		// target and drop the fan-out members that lost to this one
+		return tea.Batch(
+			p.retargetStackChildren(p.mergeState.Worktree, p.mergeState.TargetBranch),
+			p.deleteFanOutLosers(p.mergeState.Worktree),
+		)
 
 	case MergeStepPostMergeConfirmation:
 		// Mark confirmation as done
@@ -1343,11 +1379,34 @@ func (p *Plugin) advanceMergeStep() tea.Cmd {
 	return nil
 }
 
+// startMergeDelivery begins the chosen merge method: a direct merge into the
+// target branch, or pushing the branch for a PR.
+func (p *Plugin) startMergeDelivery() tea.Cmd {
+	if p.mergeState.UseDirectMerge {
+		// Direct merge path - skip PR workflow
+		p.mergeState.StepStatus[MergeStepPush] = "skipped"
+		p.mergeState.StepStatus[MergeStepGeneratePR] = "skipped"
+		p.mergeState.StepStatus[MergeStepCreatePR] = "skipped"
+		p.mergeState.StepStatus[MergeStepWaitingMerge] = "skipped"
+		p.mergeState.Step = MergeStepDirectMerge
+		p.mergeState.StepStatus[MergeStepDirectMerge] = "running"
+		return p.performDirectMerge(p.mergeState.Worktree, p.mergeState.TargetBranch)
+	}
+
+	// PR workflow path - push first
+	p.mergeState.Step = MergeStepPush
+	p.mergeState.StepStatus[MergeStepPush] = "running"
+	return p.pushForMerge(p.mergeState.Worktree)
+}
+
 // cancelMergeWorkflow cancels the merge workflow and returns to list view.
 func (p *Plugin) cancelMergeWorkflow() {
 	if p.mergeState != nil && p.mergeState.PRGenerationCancel != nil {
 		p.mergeState.PRGenerationCancel()
 	}
+	if p.mergeState != nil && p.mergeState.TestCancel != nil {
+		p.mergeState.TestCancel()
+	}
 	p.mergeState = nil
 	p.viewMode = ViewModeList
 }
@@ -13,6 +13,7 @@ func TestMergeWorkflowStepString(t *testing.T) {
 		expected string
 	}{
 		{MergeStepReviewDiff, "Review Diff"},
+		{MergeStepVerify, "Run Tests"},
 		{MergeStepPush, "Push Branch"},
 		{MergeStepGeneratePR, "Generate PR"},
 		{MergeStepCreatePR, "Create PR"},
@@ -0,0 +1,127 @@
+package workspace
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	appmsg "github.com/marcus/sidecar/internal/msg"
+)
+
+const (
+	// mergeTestTickInterval is how often the merge modal repaints streaming test output.
+	mergeTestTickInterval = 250 * time.Millisecond
+	// mergeTestOutputLines is how many trailing output lines the merge modal shows.
+	mergeTestOutputLines = 12
+)
+
+// MergeTestDoneMsg signals the merge test gate command finished.
+type MergeTestDoneMsg struct {
+	WorkspaceName string
+	Passed        bool
+	Output        string // Tail of combined output
+}
+
+// mergeTestTickMsg repaints the merge modal while tests run.
+type mergeTestTickMsg struct {
+	WorkspaceName string
+}
+
+// runMergeTests starts the test gate command in the merge worktree, streaming
+// its output into the merge state.
+func (p *Plugin) runMergeTests() tea.Cmd {
+	state := p.mergeState
+	if state == nil {
+		return nil
+	}
+	if state.TestCancel != nil {
+		state.TestCancel()
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	output := &outputTail{}
+	state.TestOutput = output
+	state.TestCancel = cancel
+	state.TestFailed = false
+	state.StepStatus[MergeStepVerify] = "running"
+	p.clearMergeModal()
+
+	wt := state.Worktree
+	command := state.TestCommand
+	return tea.Batch(
+		func() tea.Msg {
+			passed, out := runTestCommandTo(ctx, wt.Path, command, output)
+			return MergeTestDoneMsg{WorkspaceName: wt.Name, Passed: passed, Output: out}
+		},
+		p.scheduleMergeTestTick(wt.Name),
+	)
+}
+
+// scheduleMergeTestTick schedules the next repaint of streaming test output.
+func (p *Plugin) scheduleMergeTestTick(wtName string) tea.Cmd {
+	return tea.Tick(mergeTestTickInterval, func(time.Time) tea.Msg {
+		return mergeTestTickMsg{WorkspaceName: wtName}
+	})
+}
+
+// mergeTestsRunning reports whether the merge test gate is in progress for wtName.
+func (p *Plugin) mergeTestsRunning(wtName string) bool {
+	return p.mergeState != nil &&
+		p.mergeState.Worktree.Name == wtName &&
+		p.mergeState.Step == MergeStepVerify &&
+		p.mergeState.StepStatus[MergeStepVerify] == "running"
+}
+
+// handleMergeTestDone continues the merge when tests pass and blocks it when
+// they fail.
+func (p *Plugin) handleMergeTestDone(msg MergeTestDoneMsg) tea.Cmd {
+	if !p.mergeTestsRunning(msg.WorkspaceName) {
+		return nil
+	}
+	p.mergeState.TestCancel = nil
+	if msg.Passed {
+		return p.advanceMergeStep()
+	}
+	// Keep the final tail (includes timeout notes) for display and fix-it prompts
+	p.mergeState.TestOutput = &outputTail{}
+	_, _ = p.mergeState.TestOutput.Write([]byte(msg.Output))
+	p.mergeState.TestFailed = true
+	p.mergeState.StepStatus[MergeStepVerify] = "error"
+	p.clearMergeModal()
+	return nil
+}
+
+// sendMergeTestFailure sends the failing test output to the worktree's agent
+// as a fix-it prompt and closes the merge workflow.
+func (p *Plugin) sendMergeTestFailure() tea.Cmd {
+	if p.mergeState == nil || !p.mergeState.TestFailed {
+		return nil
+	}
+	wt := p.mergeState.Worktree
+	if wt.Agent == nil || wt.Agent.TmuxSession == "" {
+		return appmsg.ShowToast("No agent running in "+wt.Name, 2*time.Second)
+	}
+	prompt := buildTestFixPrompt(p.mergeState.TestCommand, p.mergeState.TestOutput.String())
+	session := wt.Agent.TmuxSession
+	name := wt.Name
+	p.cancelMergeWorkflow()
+	p.clearMergeModal()
+	return func() tea.Msg {
+		if err := sendTmuxText(session, prompt); err != nil {
+			return appmsg.ToastMsg{Message: "Send failed: " + err.Error(), Duration: 3 * time.Second, IsError: true}
+		}
+		return appmsg.ToastMsg{Message: "Sent test failures to " + name, Duration: 2 * time.Second}
+	}
+}
+
+// buildTestFixPrompt builds the prompt asking an agent to fix failing tests.
+func buildTestFixPrompt(command, output string) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "The verification command `%s` failed before merging this branch. ", command)
+	sb.WriteString("Fix the cause of the failure, then run the command again to confirm it passes.\n\n")
+	sb.WriteString("Output:\n```\n")
+	sb.WriteString(strings.TrimSpace(output))
+	sb.WriteString("\n```")
+	return sb.String()
+}
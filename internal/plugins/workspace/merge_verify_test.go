@@ -0,0 +1,133 @@
+package workspace
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/marcus/sidecar/internal/config"
+	"github.com/marcus/sidecar/internal/plugin"
+)
+
+func newVerifyTestPlugin(t *testing.T, wtPath string) *Plugin {
+	t.Helper()
+	return &Plugin{
+		ctx:      &plugin.Context{Config: config.Default(), ProjectRoot: t.TempDir()},
+		viewMode: ViewModeMerge,
+		mergeState: &MergeWorkflowState{
+			Worktree:          &Worktree{Name: "feature", Path: wtPath, Branch: "feature"},
+			Step:              MergeStepMergeMethod,
+			MergeMethodOption: 1, // direct merge
+			StepStatus:        make(map[MergeWorkflowStep]string),
+		},
+	}
+}
+
+func TestMergeTestGate_SkippedWithoutCommand(t *testing.T) {
+	p := newVerifyTestPlugin(t, t.TempDir())
+
+	if cmd := p.advanceMergeStep(); cmd == nil {
+		t.Fatal("expected direct merge command")
+	}
+	if p.mergeState.Step != MergeStepDirectMerge {
+		t.Errorf("Step = %v, want MergeStepDirectMerge", p.mergeState.Step)
+	}
+	if got := p.mergeState.StepStatus[MergeStepVerify]; got != "skipped" {
+		t.Errorf("verify status = %q, want skipped", got)
+	}
+}
+
+func TestMergeTestGate_RunsBeforeDelivery(t *testing.T) {
+	p := newVerifyTestPlugin(t, t.TempDir())
+	p.ctx.Config.Plugins.Workspace.TestCommand = "make check"
+
+	if cmd := p.advanceMergeStep(); cmd == nil {
+		t.Fatal("expected test command")
+	}
+	defer p.cancelMergeWorkflow()
+	if p.mergeState.Step != MergeStepVerify {
+		t.Fatalf("Step = %v, want MergeStepVerify", p.mergeState.Step)
+	}
+	if p.mergeState.TestCommand != "make check" {
+		t.Errorf("TestCommand = %q, want %q", p.mergeState.TestCommand, "make check")
+	}
+	if !p.mergeTestsRunning("feature") {
+		t.Error("tests should be running")
+	}
+}
+
+func TestHandleMergeTestDone(t *testing.T) {
+	t.Run("failure blocks merge", func(t *testing.T) {
+		p := newVerifyTestPlugin(t, t.TempDir())
+		p.mergeState.Step = MergeStepVerify
+		p.mergeState.StepStatus[MergeStepVerify] = "running"
+
+		if cmd := p.handleMergeTestDone(MergeTestDoneMsg{WorkspaceName: "feature", Output: "FAIL: TestX"}); cmd != nil {
+			t.Error("failure should not produce a command")
+		}
+		if p.mergeState.Step != MergeStepVerify || !p.mergeState.TestFailed {
+			t.Errorf("Step = %v, TestFailed = %v; want blocked at verify", p.mergeState.Step, p.mergeState.TestFailed)
+		}
+		if got := p.mergeState.StepStatus[MergeStepVerify]; got != "error" {
+			t.Errorf("verify status = %q, want error", got)
+		}
+		if got := p.mergeState.TestOutput.String(); got != "FAIL: TestX" {
+			t.Errorf("output = %q", got)
+		}
+	})
+
+	t.Run("pass continues merge", func(t *testing.T) {
+		p := newVerifyTestPlugin(t, t.TempDir())
+		p.mergeState.Step = MergeStepVerify
+		p.mergeState.UseDirectMerge = true
+		p.mergeState.StepStatus[MergeStepVerify] = "running"
+
+		if cmd := p.handleMergeTestDone(MergeTestDoneMsg{WorkspaceName: "feature", Passed: true}); cmd == nil {
+			t.Error("expected direct merge command")
+		}
+		if p.mergeState.Step != MergeStepDirectMerge {
+			t.Errorf("Step = %v, want MergeStepDirectMerge", p.mergeState.Step)
+		}
+		if got := p.mergeState.StepStatus[MergeStepVerify]; got != "done" {
+			t.Errorf("verify status = %q, want done", got)
+		}
+	})
+
+	t.Run("stale result ignored", func(t *testing.T) {
+		p := newVerifyTestPlugin(t, t.TempDir())
+		p.mergeState.Step = MergeStepVerify
+		p.mergeState.StepStatus[MergeStepVerify] = "running"
+
+		p.handleMergeTestDone(MergeTestDoneMsg{WorkspaceName: "other", Passed: true})
+		if p.mergeState.Step != MergeStepVerify {
+			t.Errorf("Step = %v, want MergeStepVerify", p.mergeState.Step)
+		}
+	})
+}
+
+func TestRunTestCommandTo_Streams(t *testing.T) {
+	tail := &outputTail{}
+	passed, out := runTestCommandTo(context.Background(), t.TempDir(), "echo one; echo two", tail)
+	if !passed || out != "one\ntwo" {
+		t.Errorf("result = %v, %q", passed, out)
+	}
+	if got := tail.String(); got != "one\ntwo\n" {
+		t.Errorf("streamed = %q", got)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if passed, _ := runTestCommandTo(ctx, t.TempDir(), "echo never", nil); passed {
+		t.Error("canceled run should not pass")
+	}
+}
+
+func TestBuildTestFixPrompt(t *testing.T) {
+	prompt := buildTestFixPrompt("go test ./...", "\n--- FAIL: TestX\n")
+	if !strings.Contains(prompt, "`go test ./...` failed") {
+		t.Errorf("prompt missing command: %q", prompt)
+	}
+	if !strings.HasSuffix(prompt, "```\n--- FAIL: TestX\n```") {
+		t.Errorf("prompt missing output block: %q", prompt)
+	}
+}
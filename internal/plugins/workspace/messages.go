@@ -32,6 +32,7 @@ type AgentOutputMsg struct {
 	Output       string
 	Status       WorktreeStatus
 	WaitingFor   string
+	WaitingTool  toolCall // Tool call the prompt is about, from hooks
 	// Cursor position captured atomically with output (only set in interactive mode)
 	CursorRow     int
 	CursorCol     int
@@ -96,9 +97,10 @@ type CreateWorktreeMsg struct {
 // CreateDoneMsg signals worktree creation completed.
 type CreateDoneMsg struct {
 	Worktree  *Worktree
-	AgentType AgentType // Agent selected at creation
-	SkipPerms bool      // Whether to skip permissions
-	Prompt    *Prompt   // Selected prompt template (nil if none)
+	AgentType AgentType             // Agent selected at creation
+	SkipPerms bool                  // Whether to skip permissions
+	Prompt    *Prompt               // Selected prompt template (nil if none)
+	Provision []ProvisionStepStatus // Final state of provisioning steps
 	Err       error
 }
 
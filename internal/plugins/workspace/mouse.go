@@ -81,6 +81,38 @@ func (p *Plugin) handleMouse(msg tea.MouseMsg) tea.Cmd {
 		return p.handleFetchPRModalMouse(msg)
 	}
 
+	if p.viewMode == ViewModeFanOut {
+		return p.handleFanOutModalMouse(msg)
+	}
+
+	if p.viewMode == ViewModeFanOutBoard {
+		return p.handleFanOutBoardMouse(msg)
+	}
+
+	if p.viewMode == ViewModePromptQueue {
+		return p.handlePromptQueueMouse(msg)
+	}
+
+	if p.viewMode == ViewModeReviewComment {
+		return p.handleReviewCommentMouse(msg)
+	}
+
+	if p.viewMode == ViewModeReview {
+		return p.handleReviewMouse(msg)
+	}
+
+	if p.viewMode == ViewModeSyncConflict {
+		return p.handleSyncConflictMouse(msg)
+	}
+
+	if p.viewMode == ViewModeOutputSearch {
+		return p.handleOutputSearchMouse(msg)
+	}
+
+	if p.viewMode == ViewModeTranscripts {
+		return p.handleTranscriptsMouse(msg)
+	}
+
 	if p.viewMode == ViewModeMerge {
 		return p.handleMergeModalMouse(msg)
 	}
@@ -117,6 +149,15 @@ func (p *Plugin) handleCreateModalMouse(msg tea.MouseMsg) tea.Cmd {
 	}
 
 	action := p.createModal.HandleMouse(msg, p.mouseHandler)
+	if p.createProvisioning {
+		return nil
+	}
+	if done := p.createProvisionDone; done != nil {
+		if action == createSubmitID || action == createCancelID || action == "cancel" {
+			return p.finishCreate(*done)
+		}
+		return nil
+	}
 	switch action {
 	case "":
 		return nil
@@ -162,8 +203,7 @@ func (p *Plugin) handleCreateModalMouse(msg tea.MouseMsg) tea.Cmd {
 	}
 	if idx, ok := parseIndexedID(createTaskItemPrefix, action); ok && idx < len(p.taskSearchFiltered) {
 		task := p.taskSearchFiltered[idx]
-		p.createTaskID = task.ID
-		p.createTaskTitle = task.Title
+		p.linkCreateTask(task.ID, task.Title)
 		p.createFocus = 3
 		p.syncCreateModalFocus()
 		return nil
@@ -175,6 +215,10 @@ func (p *Plugin) handleCreateModalMouse(msg tea.MouseMsg) tea.Cmd {
 		p.syncCreateModalFocus()
 		return nil
 	}
+	if idx, ok := parseIndexedID(createPresetItemPrefix, action); ok && idx <= len(p.workspacePresets()) {
+		// Chip 0 is "none"; presets follow
+		return p.applyCreatePreset(idx - 1)
+	}
 
 	return nil
 }
@@ -283,10 +327,20 @@ func (p *Plugin) handlePromptPickerModalMouse(msg tea.MouseMsg) tea.Cmd {
 	}
 
 	action := p.promptPickerModal.HandleMouse(msg, p.mouseHandler)
+	if idx, ok := parsePromptPickerInputID(action); ok && p.promptPicker.answering != nil {
+		p.promptPicker.answering.setFocus(idx)
+		p.syncPromptPickerFocus()
+		return nil
+	}
 	switch action {
 	case "":
 		return nil
 	case "cancel":
+		if p.promptPicker.answering != nil {
+			p.promptPicker.answering = nil
+			p.clearPromptPickerModal()
+			return nil
+		}
 		return func() tea.Msg { return PromptCancelledMsg{} }
 	case promptPickerFilterID:
 		p.promptPicker.filterFocused = true
@@ -372,6 +426,97 @@ func (p *Plugin) handleFetchPRModalMouse(msg tea.MouseMsg) tea.Cmd {
 	return nil
 }
 
+func (p *Plugin) handleFanOutModalMouse(msg tea.MouseMsg) tea.Cmd {
+	p.ensureFanOutModal()
+	if p.fanOutModal == nil {
+		return nil
+	}
+
+	action := p.fanOutModal.HandleMouse(msg, p.mouseHandler)
+	switch action {
+	case "cancel", fanOutCancelID:
+		if p.fanOutCreating {
+			return nil
+		}
+		p.viewMode = ViewModeList
+		p.clearFanOutModal()
+	case fanOutSubmitID:
+		if !p.fanOutCreating {
+			return p.executeFanOut()
+		}
+	case fanOutSkipPermissionsID:
+		p.fanOutSkipPerms = !p.fanOutSkipPerms
+	default:
+		if idx, ok := parseIndexedID(fanOutAgentItemPrefix, action); ok && idx < len(p.fanOutAgents) {
+			p.fanOutAgents[idx] = !p.fanOutAgents[idx]
+		}
+	}
+	return nil
+}
+
+func (p *Plugin) handleFanOutBoardMouse(msg tea.MouseMsg) tea.Cmd {
+	p.ensureFanOutBoardModal()
+	if p.fanOutBoardModal == nil {
+		return nil
+	}
+
+	if p.fanOutBoardModal.HandleMouse(msg, p.mouseHandler) == "cancel" {
+		p.closeFanOutBoard()
+	}
+	return nil
+}
+
+func (p *Plugin) handlePromptQueueMouse(msg tea.MouseMsg) tea.Cmd {
+	p.ensurePromptQueueModal()
+	if p.queueModal == nil {
+		p.clearPromptQueueModal()
+		return nil
+	}
+
+	if p.queueModal.HandleMouse(msg, p.mouseHandler) == "cancel" {
+		p.clearPromptQueueModal()
+	}
+	return nil
+}
+
+// handleTranscriptsMouse closes the transcripts modal on a backdrop click.
+func (p *Plugin) handleTranscriptsMouse(msg tea.MouseMsg) tea.Cmd {
+	p.ensureTranscriptsModal()
+	if p.transcriptsModal == nil {
+		p.closeTranscripts()
+		return nil
+	}
+
+	if p.transcriptsModal.HandleMouse(msg, p.mouseHandler) == "cancel" {
+		p.closeTranscripts()
+	}
+	return nil
+}
+
+func (p *Plugin) handleSyncConflictMouse(msg tea.MouseMsg) tea.Cmd {
+	p.ensureSyncConflictModal()
+	if p.syncModal == nil {
+		p.closeSyncConflict()
+		return nil
+	}
+
+	if p.syncModal.HandleMouse(msg, p.mouseHandler) == "cancel" && !p.syncState.Running {
+		p.closeSyncConflict()
+	}
+	return nil
+}
+
+// handleOutputSearchMouse scrolls the searched scrollback with the wheel.
+func (p *Plugin) handleOutputSearchMouse(msg tea.MouseMsg) tea.Cmd {
+	switch p.mouseHandler.HandleMouse(msg).Type {
+	case mouse.ActionScrollUp:
+		p.scrollOutputSearch(-3)
+	case mouse.ActionScrollDown:
+		p.scrollOutputSearch(3)
+	}
+	return nil
+}
+
 func (p *Plugin) handleMergeModalMouse(msg tea.MouseMsg) tea.Cmd {
 	p.ensureMergeModal()
 	if p.mergeModal == nil {
@@ -394,6 +539,10 @@ func (p *Plugin) handleMergeModalMouse(msg tea.MouseMsg) tea.Cmd {
 	case mergeMethodActionID, mergeTargetActionID, mergeCleanUpButtonID:
 		// Advance to next step
 		return p.advanceMergeStep()
+	case mergeTestRetryID:
+		return p.runMergeTests()
+	case mergeTestFixID:
+		return p.sendMergeTestFailure()
 	case mergeSkipButtonID:
 		// Skip all cleanup
 		if p.mergeState != nil {
@@ -621,8 +770,8 @@ func (p *Plugin) handleMouseClick(action mouse.MouseAction) tea.Cmd {
 					p.activePane = PaneSidebar
 					return p.loadSelectedContent()
 				}
-			} else if idx >= 0 && idx < len(p.worktrees) {
-				// Worktree clicked
+			} else if idx >= 0 && idx < p.sidebarItemCount() {
+				// Worktree (or archived entry) clicked
 				if p.shellSelected || p.selectedIdx != idx {
 					p.shellSelected = false
 					p.selectedIdx = idx
@@ -670,6 +819,11 @@ func (p *Plugin) handleMouseClick(action mouse.MouseAction) tea.Cmd {
 			p.kanbanRow = data.row
 			p.syncKanbanToList()
 			p.applyKanbanSelectionChange(oldShellSelected, oldShellIdx, oldWorktreeIdx)
+			if p.kanbanByTask {
+				// Task board cards can be dragged to another column
+				p.kanbanDropX = action.X
+				p.mouseHandler.StartDrag(action.X, action.Y, regionKanbanCard, data.col)
+			}
 			return p.loadSelectedContent()
 		}
 	case regionKanbanColumn:
@@ -690,9 +844,15 @@ func (p *Plugin) handleMouseClick(action mouse.MouseAction) tea.Cmd {
 		if idx, ok := action.Region.Data.(int); ok {
 			if idx == 0 {
 				p.viewMode = ViewModeList
+			} else if p.viewMode == ViewModeKanban {
+				// Clicking the board tab again switches between agent and task boards
+				return p.toggleKanbanBoard()
 			} else {
 				p.viewMode = ViewModeKanban
 				p.syncListToKanban()
+				if p.kanbanByTask {
+					return p.loadTaskBoard()
+				}
 			}
 		}
 	case regionDiffTabFile:
@@ -824,8 +984,7 @@ func (p *Plugin) handleMouseClick(action mouse.MouseAction) tea.Cmd {
 				// Task selection
 				if data.idx >= 0 && data.idx < len(p.taskSearchFiltered) {
 					task := p.taskSearchFiltered[data.idx]
-					p.createTaskID = task.ID
-					p.createTaskTitle = task.Title
+					p.linkCreateTask(task.ID, task.Title)
 					p.taskSearchFiltered = nil
 				}
 			}
@@ -912,6 +1071,12 @@ func (p *Plugin) handleMouseDoubleClick(action mouse.MouseAction) tea.Cmd {
 					p.saveSelectionState()
 					return p.ensureShellAndAttachByIndex(shellIdx)
 				}
+			} else if idx >= len(p.worktrees) && idx < p.sidebarItemCount() {
+				// Double-click on archived entry restores it
+				p.shellSelected = false
+				p.selectedIdx = idx
+				p.saveSelectionState()
+				return p.restoreSelectedArchive()
 			} else if idx >= 0 && idx < len(p.worktrees) {
 				p.shellSelected = false
 				p.selectedIdx = idx
@@ -997,7 +1162,7 @@ func (p *Plugin) handleMouseDoubleClick(action mouse.MouseAction) tea.Cmd {
 			p.kanbanRow = data.row
 			p.syncKanbanToList()
 			p.applyKanbanSelectionChange(oldShellSelected, oldShellIdx, oldWorktreeIdx)
-			if data.col == kanbanShellColumnIndex {
+			if data.col == kanbanShellColumnIndex && !p.kanbanByTask {
 				if shell := p.kanbanShellAt(data.row); shell != nil {
 					return p.ensureShellAndAttachByIndex(data.row)
 				}
@@ -1096,7 +1261,7 @@ func (p *Plugin) handleMouseScroll(action mouse.MouseAction) tea.Cmd {
 // scrollSidebar scrolls the sidebar list (shells + worktrees).
 func (p *Plugin) scrollSidebar(delta int) tea.Cmd {
 	// Check if there's anything to scroll through
-	if len(p.shells) == 0 && len(p.worktrees) == 0 {
+	if len(p.shells) == 0 && p.sidebarItemCount() == 0 {
 		return nil
 	}
 
@@ -1248,10 +1413,10 @@ func (p *Plugin) scrollPreview(delta int) tea.Cmd {
 
 // scrollKanban scrolls within the current Kanban column.
 func (p *Plugin) scrollKanban(delta int) tea.Cmd {
-	if p.kanbanCol < 0 || p.kanbanCol >= kanbanColumnCount() {
+	kd := p.buildKanbanData()
+	if p.kanbanCol < 0 || p.kanbanCol >= kd.columnCount() {
 		return nil
 	}
-	kd := p.buildKanbanData()
 	count := kd.columnItemCount(p.kanbanCol)
 
 	if count == 0 {
@@ -1344,6 +1509,8 @@ func (p *Plugin) handleMouseDrag(action mouse.MouseAction) tea.Cmd {
 			}
 			p.termPanelSize = newSize
 		}
+	case regionKanbanCard:
+		p.kanbanDropX = action.X
 	case regionPreviewPane:
 		if p.viewMode == ViewModeInteractive && p.interactiveState != nil && p.interactiveState.Active &&
 			!p.interactiveState.MouseReportingEnabled {
@@ -1366,6 +1533,9 @@ func (p *Plugin) handleMouseDragEnd() tea.Cmd {
 
 	// Persist widths based on what was being dragged
 	switch p.lastDragRegion {
+	case regionKanbanCard:
+		p.lastDragRegion = ""
+		return p.dropKanbanCard()
 	case regionDiffTabDivider:
 		_ = state.SetDiffTabFileListWidth(p.diffTabListWidth)
 	case regionTermPanelDivider:
@@ -0,0 +1,391 @@
+package workspace
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/atotto/clipboard"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/marcus/sidecar/internal/app"
+	appmsg "github.com/marcus/sidecar/internal/msg"
+	"github.com/marcus/sidecar/internal/plugins/filebrowser"
+	"github.com/marcus/sidecar/internal/ui"
+)
+
+// outputBlockMaxLines caps how far a matched block extends around its match.
+const outputBlockMaxLines = 200
+
+// OutputSearchState tracks a search over a session's full scrollback, or over
+// a recorded transcript. The scrollback is captured once when the search
+// opens; r reloads it.
+type OutputSearchState struct {
+	Title      string // Worktree or shell name
+	Target     string // Session target, or transcript directory when Transcript is set
+	Transcript bool   // Searching a recorded transcript rather than a live session
+	Root       string // Directory relative file paths in the output refer to
+	Lines      []string
+	Loading    bool
+	Err        error
+
+	Input   textinput.Model
+	Editing bool // Typing the query; otherwise n/N navigate
+	Regex   bool // Query is a regular expression rather than literal text
+	Invalid string
+
+	Matches []outputMatch
+	Current int // Index into Matches
+	Scroll  int // First visible line
+}
+
+// outputMatch is one match, as byte offsets into a scrollback line.
+type outputMatch struct {
+	Line, Start, End int
+}
+
+// fileRef is a file:line reference found in output.
+type fileRef struct {
+	Path       string
+	Line       int
+	Start, End int // Byte offsets of the reference in its line
+}
+
+// outputHistoryMsg delivers a captured scrollback.
+type outputHistoryMsg struct {
+	Epoch  uint64
+	Target string
+	Lines  []string
+	Err    error
+}
+
+// GetEpoch implements plugin.EpochMessage.
+func (m outputHistoryMsg) GetEpoch() uint64 { return m.Epoch }
+
+// fileRefPattern matches paths with an extension followed by a line number,
+// as in "internal/app/model.go:42", "./main.py:7:3" or "src/App.tsx(12,5)".
+var fileRefPattern = regexp.MustCompile(`(?:^|[\s"'(\[<=])((?:[.~]{0,2}/)?[\w.\-@+/]*\w\.[A-Za-z]\w*)(?::(\d+)|\((\d+)(?:,\d+)?\))`)
+
+// openOutputSearch starts a scrollback search for the selected worktree's
+// agent or the selected shell.
+func (p *Plugin) openOutputSearch() tea.Cmd {
+	var agent *Agent
+	var title, root string
+	if p.shellSelected {
+		shell := p.getSelectedShell()
+		if shell == nil {
+			return nil
+		}
+		agent, title, root = shell.Agent, shell.Name, p.ctx.WorkDir
+	} else {
+		wt := p.selectedWorktree()
+		if wt == nil || p.previewTab != PreviewTabOutput {
+			return nil
+		}
+		agent, title, root = wt.Agent, wt.Name, wt.Path
+	}
+	if agent == nil {
+		return appmsg.ShowToast("No session to search", 2*time.Second)
+	}
+	target := agent.TmuxPane
+	if target == "" {
+		target = agent.TmuxSession
+	}
+
+	input := textinput.New()
+	input.Placeholder = "search output"
+	input.Prompt = "/"
+	input.CharLimit = 200
+	input.Focus()
+	p.outputSearch = &OutputSearchState{
+		Title:   title,
+		Target:  target,
+		Root:    root,
+		Loading: true,
+		Input:   input,
+		Editing: true,
+	}
+	p.viewMode = ViewModeOutputSearch
+	return p.loadOutputHistory(p.outputSearch)
+}
+
+// closeOutputSearch leaves the search, returning to the transcript list if
+// it was opened from there and to the list view otherwise.
+func (p *Plugin) closeOutputSearch() {
+	fromTranscripts := p.outputSearch != nil && p.outputSearch.Transcript && p.transcripts != nil
+	p.outputSearch = nil
+	p.viewMode = ViewModeList
+	if fromTranscripts {
+		p.viewMode = ViewModeTranscripts
+	}
+}
+
+// loadOutputHistory captures a session's scrollback, or reads its transcript.
+func (p *Plugin) loadOutputHistory(s *OutputSearchState) tea.Cmd {
+	epoch := p.ctx.Epoch
+	target, fromTranscript := s.Target, s.Transcript
+	return func() tea.Msg {
+		var lines []string
+		if fromTranscript {
+			var err error
+			if lines, err = readTranscriptLines(target); err != nil {
+				return outputHistoryMsg{Epoch: epoch, Target: target, Err: err}
+			}
+		} else {
+			history, err := sessionBackend.History(target)
+			if err != nil {
+				return outputHistoryMsg{Epoch: epoch, Target: target, Err: err}
+			}
+			lines = strings.Split(strings.TrimRight(history, "\n"), "\n")
+		}
+		for i, line := range lines {
+			// Expanded once here so match offsets line up with display columns
+			lines[i] = ui.ExpandTabs(strings.TrimRight(line, " "), tabStopWidth)
+		}
+		return outputHistoryMsg{Epoch: epoch, Target: target, Lines: lines}
+	}
+}
+
+// handleOutputHistory installs a captured scrollback and re-runs the query.
+func (p *Plugin) handleOutputHistory(msg outputHistoryMsg) {
+	s := p.outputSearch
+	if s == nil || s.Target != msg.Target {
+		return
+	}
+	s.Loading = false
+	s.Err = msg.Err
+	s.Lines = msg.Lines
+	p.updateOutputMatches()
+	if len(s.Matches) == 0 {
+		s.Scroll = max(len(s.Lines)-p.outputSearchHeight(), 0)
+	}
+}
+
+// updateOutputMatches re-runs the query, selecting the most recent match.
+func (p *Plugin) updateOutputMatches() {
+	s := p.outputSearch
+	if s == nil {
+		return
+	}
+	matches, err := findOutputMatches(s.Lines, s.Input.Value(), s.Regex)
+	s.Invalid = ""
+	if err != nil {
+		s.Invalid = err.Error()
+	}
+	s.Matches = matches
+	s.Current = len(matches) - 1
+	p.revealOutputMatch()
+}
+
+// findOutputMatches returns every match of query in lines. Queries are
+// case-insensitive unless they contain an upper-case letter.
+func findOutputMatches(lines []string, query string, regex bool) ([]outputMatch, error) {
+	if query == "" {
+		return nil, nil
+	}
+	pattern := query
+	if !regex {
+		pattern = regexp.QuoteMeta(query)
+	}
+	if !strings.ContainsFunc(query, unicode.IsUpper) {
+		pattern = "(?i)" + pattern
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid regex")
+	}
+
+	var matches []outputMatch
+	for i, line := range lines {
+		for _, loc := range re.FindAllStringIndex(line, -1) {
+			if loc[0] == loc[1] {
+				continue // Empty matches (e.g. "a*") can't be shown or navigated
+			}
+			matches = append(matches, outputMatch{Line: i, Start: loc[0], End: loc[1]})
+		}
+	}
+	return matches, nil
+}
+
+// moveOutputMatch selects the next (delta 1) or previous (delta -1) match,
+// wrapping around at either end.
+func (p *Plugin) moveOutputMatch(delta int) {
+	s := p.outputSearch
+	if s == nil || len(s.Matches) == 0 {
+		return
+	}
+	s.Current = (s.Current + delta + len(s.Matches)) % len(s.Matches)
+	p.revealOutputMatch()
+}
+
+// revealOutputMatch scrolls so the current match is visible, centering it
+// when it was off screen.
+func (p *Plugin) revealOutputMatch() {
+	s := p.outputSearch
+	if s == nil || s.Current < 0 || s.Current >= len(s.Matches) {
+		return
+	}
+	height := p.outputSearchHeight()
+	line := s.Matches[s.Current].Line
+	if line < s.Scroll || line >= s.Scroll+height {
+		s.Scroll = line - height/2
+	}
+	p.clampOutputSearchScroll()
+}
+
+// scrollOutputSearch scrolls the scrollback by delta lines.
+func (p *Plugin) scrollOutputSearch(delta int) {
+	if p.outputSearch == nil {
+		return
+	}
+	p.outputSearch.Scroll += delta
+	p.clampOutputSearchScroll()
+}
+
+// clampOutputSearchScroll keeps the scroll offset within the scrollback.
+func (p *Plugin) clampOutputSearchScroll() {
+	s := p.outputSearch
+	s.Scroll = min(s.Scroll, len(s.Lines)-p.outputSearchHeight())
+	s.Scroll = max(s.Scroll, 0)
+}
+
+// outputSearchHeight returns how many scrollback lines the view shows.
+func (p *Plugin) outputSearchHeight() int {
+	// Pane borders, then the search bar and hint lines
+	return max(p.height-2-2, 1)
+}
+
+// currentOutputMatch returns the selected match, if any.
+func (s *OutputSearchState) currentOutputMatch() (outputMatch, bool) {
+	if s.Current < 0 || s.Current >= len(s.Matches) {
+		return outputMatch{}, false
+	}
+	return s.Matches[s.Current], true
+}
+
+// outputMatchBlock returns the range [start, end) of non-blank lines around
+// line, e.g. the whole stack trace a match falls in.
+func outputMatchBlock(lines []string, line int) (start, end int) {
+	start, end = line, line+1
+	for start > 0 && line-start < outputBlockMaxLines && strings.TrimSpace(lines[start-1]) != "" {
+		start--
+	}
+	for end < len(lines) && end-line < outputBlockMaxLines && strings.TrimSpace(lines[end]) != "" {
+		end++
+	}
+	return start, end
+}
+
+// copyOutputMatchBlock copies the block around the current match.
+func (p *Plugin) copyOutputMatchBlock() tea.Cmd {
+	s := p.outputSearch
+	if s == nil {
+		return nil
+	}
+	m, ok := s.currentOutputMatch()
+	if !ok {
+		return nil
+	}
+	start, end := outputMatchBlock(s.Lines, m.Line)
+	if err := clipboard.WriteAll(strings.Join(s.Lines[start:end], "\n")); err != nil {
+		return appmsg.ShowToast("Copy failed: "+err.Error(), 2*time.Second)
+	}
+	return appmsg.ShowToast(fmt.Sprintf("Copied %d lines", end-start), 2*time.Second)
+}
+
+// parseFileRefs returns the file:line references in a line of output.
+func parseFileRefs(line string) []fileRef {
+	var refs []fileRef
+	for _, loc := range fileRefPattern.FindAllStringSubmatchIndex(line, -1) {
+		numStart, numEnd := loc[4], loc[5]
+		if numStart < 0 {
+			numStart, numEnd = loc[6], loc[7]
+		}
+		n, err := strconv.Atoi(line[numStart:numEnd])
+		if err != nil || n == 0 {
+			continue
+		}
+		refs = append(refs, fileRef{Path: line[loc[2]:loc[3]], Line: n, Start: loc[2], End: loc[1]})
+	}
+	return refs
+}
+
+// outputFileRef returns the file reference for the current match: the one
+// on the match's line nearest the match, or else the first in its block.
+func (s *OutputSearchState) outputFileRef() (fileRef, bool) {
+	m, ok := s.currentOutputMatch()
+	if !ok {
+		return fileRef{}, false
+	}
+	if refs := parseFileRefs(s.Lines[m.Line]); len(refs) > 0 {
+		best := refs[0]
+		for _, ref := range refs {
+			if ref.Start <= m.Start {
+				best = ref
+			}
+		}
+		return best, true
+	}
+	start, end := outputMatchBlock(s.Lines, m.Line)
+	for i := m.Line + 1; i < end; i++ {
+		if refs := parseFileRefs(s.Lines[i]); len(refs) > 0 {
+			return refs[0], true
+		}
+	}
+	for i := m.Line - 1; i >= start; i-- {
+		if refs := parseFileRefs(s.Lines[i]); len(refs) > 0 {
+			return refs[0], true
+		}
+	}
+	return fileRef{}, false
+}
+
+// openOutputFileRef shows the current match's file:line in the file browser.
+func (p *Plugin) openOutputFileRef() tea.Cmd {
+	s := p.outputSearch
+	if s == nil {
+		return nil
+	}
+	ref, ok := s.outputFileRef()
+	if !ok {
+		return appmsg.ShowToast("No file:line near this match", 2*time.Second)
+	}
+	rel, ok := projectRelativePath(ref.Path, s.Root, p.ctx.WorkDir)
+	if !ok {
+		return appmsg.ShowToast(ref.Path+" is outside the project", 2*time.Second)
+	}
+	p.closeOutputSearch()
+	return tea.Batch(
+		app.FocusPlugin("file-browser"),
+		func() tea.Msg {
+			return filebrowser.NavigateToFileMsg{Path: rel, Line: ref.Line}
+		},
+	)
+}
+
+// projectRelativePath maps a path printed in a session's output to a path
+// relative to the project, as the file browser expects. Paths are resolved
+// against root (the worktree the session runs in); worktree paths map to the
+// same file in the project checkout.
+func projectRelativePath(path, root, projectDir string) (string, bool) {
+	if strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			path = filepath.Join(home, path[2:])
+		}
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(root, path)
+	}
+	for _, dir := range []string{root, projectDir} {
+		rel, err := filepath.Rel(dir, path)
+		if err == nil && rel != ".." && !strings.HasPrefix(rel, "../") {
+			return filepath.ToSlash(rel), true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,138 @@
+package workspace
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/marcus/sidecar/internal/plugin"
+)
+
+func TestFindOutputMatches(t *testing.T) {
+	lines := []string{"Error: boom", "no error here", "ERROR again", "panic: x.go:12"}
+
+	got, err := findOutputMatches(lines, "error", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []outputMatch{{0, 0, 5}, {1, 3, 8}, {2, 0, 5}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("lower-case query = %v, want case-insensitive %v", got, want)
+	}
+
+	if got, _ := findOutputMatches(lines, "ERROR", false); len(got) != 1 || got[0].Line != 2 {
+		t.Errorf("upper-case query = %v, want case-sensitive match on line 2", got)
+	}
+	if got, _ := findOutputMatches(lines, "x.go", false); len(got) != 1 {
+		t.Errorf("literal query = %v, want '.' matched literally", got)
+	}
+	if got, _ := findOutputMatches(lines, `\w+\.go:\d+`, true); !reflect.DeepEqual(got, []outputMatch{{3, 7, 14}}) {
+		t.Errorf("regex query = %v", got)
+	}
+	if got, _ := findOutputMatches(lines, "z*", true); len(got) != 0 {
+		t.Errorf("empty matches should be dropped, got %v", got)
+	}
+	if _, err := findOutputMatches(lines, "(", true); err == nil {
+		t.Error("invalid regex should fail")
+	}
+}
+
+func TestParseFileRefs(t *testing.T) {
+	tests := []struct {
+		line string
+		want []fileRef
+	}{
+		{"internal/app/model.go:42: undefined: x", []fileRef{{Path: "internal/app/model.go", Line: 42, Start: 0, End: 24}}},
+		{"  at ./src/main.py:7:3", []fileRef{{Path: "./src/main.py", Line: 7, Start: 5, End: 20}}},
+		{"src/App.tsx(12,5): error", []fileRef{{Path: "src/App.tsx", Line: 12, Start: 0, End: 17}}},
+		{"see http://example.com:8080/x and v1.2:3", nil},
+		{"listening on localhost:8080", nil},
+	}
+	for _, tt := range tests {
+		if got := parseFileRefs(tt.line); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseFileRefs(%q) = %+v, want %+v", tt.line, got, tt.want)
+		}
+	}
+}
+
+func TestOutputMatchBlock(t *testing.T) {
+	lines := []string{"ok", "", "panic: boom", "  main.go:3", "  lib.go:9", "", "done"}
+	if start, end := outputMatchBlock(lines, 3); start != 2 || end != 5 {
+		t.Errorf("block = [%d, %d), want [2, 5)", start, end)
+	}
+	if start, end := outputMatchBlock(lines, 6); start != 6 || end != 7 {
+		t.Errorf("last line block = [%d, %d), want [6, 7)", start, end)
+	}
+}
+
+func TestOutputFileRef(t *testing.T) {
+	s := &OutputSearchState{Lines: []string{"panic: boom", "  main.go:3", "  lib.go:9"}}
+	s.Matches, _ = findOutputMatches(s.Lines, "boom", false)
+	s.Current = 0
+	if ref, ok := s.outputFileRef(); !ok || ref.Path != "main.go" || ref.Line != 3 {
+		t.Errorf("ref = %+v, %v, want the first reference in the block", ref, ok)
+	}
+
+	s.Matches, _ = findOutputMatches(s.Lines, "lib", false)
+	if ref, ok := s.outputFileRef(); !ok || ref.Path != "lib.go" {
+		t.Errorf("ref = %+v, %v, want the reference on the match's line", ref, ok)
+	}
+}
+
+func TestProjectRelativePath(t *testing.T) {
+	tests := []struct {
+		path   string
+		want   string
+		wantOK bool
+	}{
+		{"src/a.go", "src/a.go", true},
+		{"/wt/feature/src/a.go", "src/a.go", true},
+		{"/repo/docs/b.md", "docs/b.md", true},
+		{"/etc/passwd", "", false},
+		{"../other/c.go", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := projectRelativePath(tt.path, "/wt/feature", "/repo")
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("projectRelativePath(%q) = %q, %v, want %q, %v", tt.path, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestOutputSearchNavigation(t *testing.T) {
+	p := &Plugin{ctx: &plugin.Context{}, height: 10, viewMode: ViewModeOutputSearch}
+	p.outputSearch = &OutputSearchState{Target: "%1", Loading: true, Editing: true, Input: textinput.New()}
+	p.outputSearch.Input.Focus()
+
+	lines := make([]string, 40)
+	lines[5], lines[20], lines[35] = "fail one", "fail two", "fail three"
+	p.handleOutputHistory(outputHistoryMsg{Target: "%1", Lines: lines})
+
+	for _, r := range "fail" {
+		p.handleOutputSearchKeys(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	s := p.outputSearch
+	if len(s.Matches) != 3 || s.Current != 2 {
+		t.Fatalf("matches = %v, current %d; want 3 with the newest selected", s.Matches, s.Current)
+	}
+
+	p.handleOutputSearchKeys(tea.KeyMsg{Type: tea.KeyEnter})
+	if s.Editing || p.ConsumesTextInput() {
+		t.Fatal("enter should stop editing")
+	}
+	p.handleOutputSearchKeys(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'N'}})
+	if s.Current != 1 || s.Scroll > 20 || s.Scroll+p.outputSearchHeight() <= 20 {
+		t.Errorf("N: current %d, scroll %d; want match on line 20 in view", s.Current, s.Scroll)
+	}
+	p.handleOutputSearchKeys(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'n'}})
+	p.handleOutputSearchKeys(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'n'}})
+	if s.Current != 0 {
+		t.Errorf("n past the newest match should wrap, current = %d", s.Current)
+	}
+
+	p.handleOutputSearchKeys(tea.KeyMsg{Type: tea.KeyEsc})
+	if p.outputSearch != nil || p.viewMode != ViewModeList {
+		t.Error("esc should close the search")
+	}
+}
@@ -0,0 +1,97 @@
+package workspace
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/marcus/sidecar/internal/styles"
+)
+
+// renderOutputSearch renders the scrollback search in place of the preview
+// content: a search bar, a hint line, then the scrollback around the
+// current match.
+func (p *Plugin) renderOutputSearch(width, height int) string {
+	s := p.outputSearch
+
+	status := ""
+	switch {
+	case s.Invalid != "":
+		status = lipgloss.NewStyle().Foreground(styles.Error).Render(s.Invalid)
+	case s.Input.Value() == "" || s.Loading:
+	case len(s.Matches) == 0:
+		status = lipgloss.NewStyle().Foreground(styles.Warning).Render("no matches")
+	default:
+		status = fmt.Sprintf("%d/%d", s.Current+1, len(s.Matches))
+	}
+	mode := "literal"
+	if s.Regex {
+		mode = "regex"
+	}
+	status = strings.TrimSpace(status + "  " + dimText("["+mode+"]"))
+	s.Input.Width = max(width-lipgloss.Width(status)-3, 10)
+	bar := s.Input.View()
+	if gap := width - lipgloss.Width(bar) - lipgloss.Width(status); gap > 0 {
+		bar += strings.Repeat(" ", gap) + status
+	}
+
+	var hint string
+	if s.Editing {
+		hint = "enter: done  ↑/↓: prev/next  ctrl+r: regex  esc: close"
+	} else {
+		hint = "n/N: next/prev  y: copy block  /: edit  esc: close"
+		if ref, ok := s.outputFileRef(); ok {
+			hint = fmt.Sprintf("o: open %s:%d  %s", ref.Path, ref.Line, hint)
+		}
+	}
+	lines := []string{bar, dimText(s.Title + " · " + hint)}
+
+	switch {
+	case s.Loading:
+		lines = append(lines, dimText("Loading scrollback..."))
+	case s.Err != nil && s.Transcript:
+		lines = append(lines, lipgloss.NewStyle().Foreground(styles.Error).Render("Read failed: "+s.Err.Error()))
+	case s.Err != nil:
+		lines = append(lines, lipgloss.NewStyle().Foreground(styles.Error).Render("Capture failed: "+s.Err.Error()))
+	default:
+		visible := max(height-len(lines), 1)
+		end := min(s.Scroll+visible, len(s.Lines))
+		for i := s.Scroll; i < end; i++ {
+			lines = append(lines, p.renderOutputSearchLine(i, width))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// renderOutputSearchLine renders one scrollback line with its matches
+// highlighted. A line whose current match lies past the pane edge is
+// shifted left to bring the match into view.
+func (p *Plugin) renderOutputSearchLine(i, width int) string {
+	s := p.outputSearch
+	line := s.Lines[i]
+	first := sort.Search(len(s.Matches), func(j int) bool { return s.Matches[j].Line >= i })
+
+	var sb strings.Builder
+	pos, currentEnd := 0, -1
+	for j := first; j < len(s.Matches) && s.Matches[j].Line == i; j++ {
+		m := s.Matches[j]
+		style := styles.SearchMatch
+		if j == s.Current {
+			style = styles.SearchMatchCurrent
+			currentEnd = ansi.StringWidth(line[:m.End])
+		}
+		sb.WriteString(line[pos:m.Start])
+		sb.WriteString(style.Render(line[m.Start:m.End]))
+		pos = m.End
+	}
+	sb.WriteString(line[pos:])
+
+	rendered := sb.String()
+	if currentEnd > width {
+		left := currentEnd - width + 1
+		return "…" + ansi.Cut(rendered, left+1, left+width)
+	}
+	return ansi.Truncate(rendered, width, "")
+}
@@ -7,11 +7,13 @@ import (
 	"strings"
 	"time"
 
+	"github.com/charmbracelet/bubbles/textarea"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/marcus/sidecar/internal/markdown"
 	"github.com/marcus/sidecar/internal/modal"
 	"github.com/marcus/sidecar/internal/mouse"
+	appmsg "github.com/marcus/sidecar/internal/msg"
 	"github.com/marcus/sidecar/internal/plugin"
 	"github.com/marcus/sidecar/internal/projectdir"
 	"github.com/marcus/sidecar/internal/plugins/gitstatus"
@@ -77,6 +79,8 @@ const (
 	mergeCleanUpButtonID   = "merge-cleanup-btn"
 	mergeSkipButtonID      = "merge-skip-btn"
 	mergePRURLID           = "merge-pr-url"
+	mergeTestRetryID       = "merge-test-retry"
+	mergeTestFixID         = "merge-test-fix"
 
 	// Prompt Picker modal regions
 	regionPromptItem   = "prompt-item"
@@ -156,6 +160,13 @@ type Plugin struct {
 	kanbanCol int // Current column index (0=Shells, 1=Active, 2=Thinking, 3=Waiting, 4=Done, 5=Paused)
 	kanbanRow int // Current row within the column
 
+	// Task board (kanban grouped by linked td task status)
+	kanbanByTask     bool
+	taskBoard        map[string]taskBoardTask // Task ID -> status and title
+	taskBoardLoading bool
+	kanbanColWidth   int // Rendered column width, for mapping drops to columns
+	kanbanDropX      int // Last X of a card being dragged
+
 	// Agent state
 	attachedSession     string // Name of worktree we're attached to (pauses polling)
 	tmuxCaptureMaxBytes int    // Cap for tmux capture output (bytes)
@@ -166,6 +177,12 @@ type Plugin struct {
 	pollGeneration      map[string]int // Per-worktree/shell poll generation counter
 	shellPollGeneration map[string]int // Per-shell poll generation counter
 
+	// Context window usage of running agents' sessions, keyed by worktree name
+	contextUsage map[string]*contextUsageState
+
+	// Agent session usage (tokens, cost) per worktree, keyed by worktree name
+	usage map[string]*usageState
+
 	// Truncation cache to eliminate ANSI parser allocation churn
 	truncateCache *ui.TruncateCache
 
@@ -232,8 +249,13 @@ type Plugin struct {
 	createFocus           int       // 0=name, 1=base, 2=prompt, 3=task, 4=agent, 5=skipPerms, 6=create, 7=cancel
 	createButtonHover     int       // 0=none, 1=create, 2=cancel
 	createError           string    // Error message to display in create modal
+	createProvision       []ProvisionStepStatus
+	createProvisioning    bool           // Worktree is being created and provisioned
+	createProvisionDone   *CreateDoneMsg // Created with failed steps; shown until dismissed
 	createModal           *modal.Modal
 	createModalWidth      int
+	createPresetIdx       int    // Applied preset index in config presets (-1 = none)
+	createNameDerived     string // Name derived from the linked task, replaced if the task changes
 
 	// Branch name validation state
 	branchNameValid     bool     // Is current name valid?
@@ -247,6 +269,7 @@ type Plugin struct {
 	promptPickerModal      *modal.Modal
 	promptPickerModalWidth int
 	promptPickerModalEmpty bool
+	promptPickerModalAnswering bool // Built for the input questions step
 
 	// Task search state for create modal
 	taskSearchInput    textinput.Model
@@ -378,6 +401,67 @@ type Plugin struct {
 	fetchPRModal        *modal.Modal // Modal instance
 	fetchPRModalWidth   int          // Cached width for rebuild detection
 
+	// Fan-out modal state
+	fanOutNameInput   textinput.Model
+	fanOutBaseInput   textinput.Model
+	fanOutPromptInput textarea.Model
+	fanOutRunsInput   textinput.Model
+	fanOutAgents      []bool // Checked agents, parallel to fanOutAgentOrder()
+	fanOutSkipPerms   bool
+	fanOutError       string
+	fanOutCreating    bool // True while worktrees are being created
+	fanOutModal       *modal.Modal
+	fanOutModalWidth  int
+
+	// Archived worktrees, listed after the worktrees in the sidebar
+	archives    []*ArchivedWorktree
+	archiveBusy map[string]bool // Worktrees being archived or restored
+
+	// Fan-out groups and comparison board state
+	fanOuts               []*FanOut
+	fanOutBoard           *FanOut                  // Group shown on the board
+	fanOutBoardIdx        int                      // Selected member
+	fanOutConfirmWinner   bool                     // Awaiting y/n to merge the selected member
+	fanOutResults         map[string]*fanOutResult // Board metrics by worktree name
+	fanOutBoardModal      *modal.Modal
+	fanOutBoardModalWidth int
+
+	// Prompt queue state
+	queueSentAt     map[string]time.Time // Queue key -> when its last prompt was sent (cleared once the agent is busy)
+	queueKey        string               // Worktree name or shell tmux name shown in the queue modal
+	queueInput      textinput.Model
+	queueIdx        int // Selected queued prompt
+	queueModal      *modal.Modal
+	queueModalWidth int
+
+	// Diff review state
+	reviews                 map[string][]*ReviewComment // Worktree name -> review comments (loaded on first use)
+	reviewDraft             *ReviewComment              // Comment being written
+	reviewInput             textarea.Model
+	reviewCommentModal      *modal.Modal
+	reviewCommentModalWidth int
+	reviewIdx               int // Selected comment in the review modal
+	reviewModal             *modal.Modal
+	reviewModalWidth        int
+
+	// Approval policy state
+	autoAnswered map[string]autoAnswer // Worktree name -> last prompt the policy evaluated
+
+	// Base branch sync state
+	syncState      *SyncState        // Conflicted sync shown in the modal
+	syncInFlight   map[string]bool   // Worktrees being synced
+	syncBaseTips   map[string]string // Worktree name -> base branch tip last seen by auto-sync
+	syncModal      *modal.Modal
+	syncModalWidth int
+
+	// Scrollback search state
+	outputSearch *OutputSearchState
+
+	// Transcript list state
+	transcripts           *TranscriptsState
+	transcriptsModal      *modal.Modal
+	transcriptsModalWidth int
+
 	// Shell manifest for persistence and cross-instance sync (td-f88fdd)
 	shellManifest *ShellManifest
 	shellWatcher  *ShellWatcher
@@ -395,6 +479,8 @@ func New() *Plugin {
 		shells:              make([]*ShellSession, 0),
 		pollGeneration:      make(map[string]int),
 		shellPollGeneration: make(map[string]int),
+		contextUsage:        make(map[string]*contextUsageState),
+		usage:               make(map[string]*usageState),
 		viewMode:            ViewModeList,
 		activePane:          PaneSidebar,
 		previewTab:          PreviewTabOutput,
@@ -408,6 +494,7 @@ func New() *Plugin {
 		taskMarkdownMode:    true,  // Default to rendered mode
 		shellSelected:       false, // Start with first worktree selected, not shell
 		typeSelectorIdx:     1,     // Default to Worktree option
+		createPresetIdx:     -1,
 		taskLoading:         false, // Explicitly initialized (td-3668584f)
 	}
 }
@@ -439,6 +526,16 @@ func (p *Plugin) Init(ctx *plugin.Context) error {
 	if ctx.Config != nil && ctx.Config.Plugins.Workspace.TmuxCaptureMaxBytes > 0 {
 		p.tmuxCaptureMaxBytes = ctx.Config.Plugins.Workspace.TmuxCaptureMaxBytes
 	}
+	backend := ""
+	controlMode := true
+	if ctx.Config != nil {
+		backend = ctx.Config.Plugins.Workspace.SessionBackend
+		controlMode = ctx.Config.Plugins.Workspace.TmuxControlMode
+	}
+	sessionBackend = selectSessionBackend(backend, controlMode)
+	if !controlMode {
+		globalTmuxControl.closeAll()
+	}
 
 	// Reset terminal panel state for reinit (sessions are preserved in tmux)
 	p.cleanupTermPanelSession()
@@ -455,6 +552,8 @@ func (p *Plugin) Init(ctx *plugin.Context) error {
 	// Reset poll generation counters (td-83dc22): invalidates any stale timers from previous project
 	p.pollGeneration = make(map[string]int)
 	p.shellPollGeneration = make(map[string]int)
+	p.contextUsage = make(map[string]*contextUsageState)
+	p.usage = make(map[string]*usageState)
 
 	// Reset shell state before initializing for new project (critical for project switching)
 	p.shells = make([]*ShellSession, 0)
@@ -472,6 +571,8 @@ func (p *Plugin) Init(ctx *plugin.Context) error {
 		manifestPath := filepath.Join(projDir, "shells.json")
 		p.shellManifest, _ = LoadShellManifest(manifestPath)
 	}
+	p.fanOuts = loadFanOuts(ctx.ProjectRoot)
+	p.archives = loadArchives(ctx.ProjectRoot)
 
 	// Stop any previous watcher (important for project switching)
 	if p.shellWatcher != nil {
@@ -496,6 +597,11 @@ func (p *Plugin) Init(ctx *plugin.Context) error {
 		ctx.Keymap.RegisterPluginBinding("enter", "confirm", "workspace-create")
 		ctx.Keymap.RegisterPluginBinding("tab", "next-field", "workspace-create")
 		ctx.Keymap.RegisterPluginBinding("shift+tab", "prev-field", "workspace-create")
+		ctx.Keymap.RegisterPluginBinding("ctrl+p", "next-preset", "workspace-create")
+
+		// Fan-out modal context
+		ctx.Keymap.RegisterPluginBinding("esc", "cancel", "workspace-fan-out")
+		ctx.Keymap.RegisterPluginBinding("tab", "next-field", "workspace-fan-out")
 
 		// Task link modal context
 		ctx.Keymap.RegisterPluginBinding("esc", "cancel", "workspace-task-link")
@@ -519,6 +625,9 @@ func (p *Plugin) Init(ctx *plugin.Context) error {
 		ctx.Keymap.RegisterPluginBinding(p.getInteractiveExitKey(), "exit-interactive", "workspace-interactive")
 		ctx.Keymap.RegisterPluginBinding(p.getInteractiveCopyKey(), "copy", "workspace-interactive")
 		ctx.Keymap.RegisterPluginBinding(p.getInteractivePasteKey(), "paste", "workspace-interactive")
+
+		// Workspace preset keys from config
+		p.registerPresetBindings()
 	}
 
 	// Load saved sidebar width
@@ -568,9 +677,19 @@ func (p *Plugin) Start() tea.Cmd {
 		}
 	}
 
+	// Resume recording transcripts of shells from a previous run
+	cmds = append(cmds, p.recordShells())
+
 	// Start shell manifest watcher for cross-instance sync (td-f88fdd)
 	cmds = append(cmds, p.startShellWatcher())
 
+	// Re-check predicted merge conflicts as branch tips move
+	cmds = append(cmds, p.scheduleConflictCheck())
+
+	if warning := p.presetKeyWarning(); warning != "" {
+		cmds = append(cmds, appmsg.ShowToast(warning, 5*time.Second))
+	}
+
 	return tea.Batch(cmds...)
 }
 
@@ -865,8 +984,13 @@ func (p *Plugin) clearCreateModal() {
 	p.createAgentType = p.getDefaultCreateAgentType()
 	p.createAgentIdx = p.agentTypeIndex(p.createAgentType)
 	p.createSkipPermissions = false
+	p.createPresetIdx = -1
+	p.createNameDerived = ""
 	p.createFocus = 0
 	p.createError = ""
+	p.createProvision = nil
+	p.createProvisioning = false
+	p.createProvisionDone = nil
 	p.createModal = nil
 	p.createModalWidth = 0
 	p.taskSearchInput = textinput.Model{}
@@ -888,6 +1012,7 @@ func (p *Plugin) clearPromptPickerModal() {
 	p.promptPickerModal = nil
 	p.promptPickerModalWidth = 0
 	p.promptPickerModalEmpty = false
+	p.promptPickerModalAnswering = false
 }
 
 // initCreateModalBase initializes common create modal state.
@@ -917,8 +1042,13 @@ func (p *Plugin) initCreateModalBase() {
 	p.createAgentType = p.getDefaultCreateAgentType()
 	p.createAgentIdx = p.agentTypeIndex(p.createAgentType)
 	p.createSkipPermissions = false
+	p.createPresetIdx = -1
+	p.createNameDerived = ""
 	p.createFocus = 0
 	p.createError = ""
+	p.createProvision = nil
+	p.createProvisioning = false
+	p.createProvisionDone = nil
 	p.createModal = nil
 	p.createModalWidth = 0
 	p.taskSearchAll = nil
@@ -949,14 +1079,8 @@ func (p *Plugin) openCreateModal() tea.Cmd {
 func (p *Plugin) openCreateModalWithTask(taskID, taskTitle string) tea.Cmd {
 	p.initCreateModalBase()
 
-	// Pre-fill name from task
-	suggestedName := p.deriveBranchName(taskID, taskTitle)
-	p.createNameInput.SetValue(suggestedName)
-	p.branchNameValid, p.branchNameErrors, p.branchNameSanitized = ValidateBranchName(suggestedName)
-
-	// Pre-fill task link
-	p.createTaskID = taskID
-	p.createTaskTitle = taskTitle
+	// Pre-fill task link and the name derived from it
+	p.linkCreateTask(taskID, taskTitle)
 
 	return tea.Batch(p.loadOpenTasks(), p.loadBranches())
 }
@@ -995,14 +1119,14 @@ func (p *Plugin) toggleSidebar() {
 }
 
 // moveCursor moves the selection cursor.
-// Navigation order: shells[0], shells[1], ..., worktrees[0], worktrees[1], ...
+// Navigation order: shells[0], shells[1], ..., worktrees[0], worktrees[1], ..., archives[0], ...
 func (p *Plugin) moveCursor(delta int) {
 	oldShellSelected := p.shellSelected
 	oldShellIdx := p.selectedShellIdx
 	oldWorktreeIdx := p.selectedIdx
 
 	shellCount := len(p.shells)
-	worktreeCount := len(p.worktrees)
+	worktreeCount := p.sidebarItemCount() // Worktrees followed by archived entries
 
 	if p.shellSelected {
 		// Currently on a shell entry
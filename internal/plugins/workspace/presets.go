@@ -0,0 +1,214 @@
+package workspace
+
+import (
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/marcus/sidecar/internal/config"
+	appmsg "github.com/marcus/sidecar/internal/msg"
+)
+
+// presetNoAgent selects no agent in a preset, as AgentNone has no name.
+const presetNoAgent = "none"
+
+// workspacePresets returns the configured create-modal presets.
+func (p *Plugin) workspacePresets() []config.WorkspacePreset {
+	if p.ctx == nil || p.ctx.Config == nil {
+		return nil
+	}
+	return p.ctx.Config.Plugins.Workspace.Presets
+}
+
+// selectedPreset returns the preset applied in the create modal, or nil.
+func (p *Plugin) selectedPreset() *config.WorkspacePreset {
+	presets := p.workspacePresets()
+	if p.createPresetIdx < 0 || p.createPresetIdx >= len(presets) {
+		return nil
+	}
+	return &presets[p.createPresetIdx]
+}
+
+// presetForKey returns the index of the preset bound to key, or -1. Keys
+// that shadow an existing binding are ignored.
+func (p *Plugin) presetForKey(key string) int {
+	if key == "" || p.presetKeyConflict(key) != "" {
+		return -1
+	}
+	for i, preset := range p.workspacePresets() {
+		if preset.Key == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// presetKeyConflict returns the command key is already bound to globally or
+// in the workspace list, or "" if a preset may use it.
+func (p *Plugin) presetKeyConflict(key string) string {
+	if p.ctx == nil || p.ctx.Keymap == nil {
+		return ""
+	}
+	for _, context := range []string{"global", "workspace-list"} {
+		for _, b := range p.ctx.Keymap.BindingsForContext(context) {
+			if b.Key == key && !strings.HasPrefix(b.Command, presetCommandPrefix) {
+				return b.Command
+			}
+		}
+	}
+	return ""
+}
+
+// registerPresetBindings adds preset keys to the workspace list help,
+// skipping keys that would shadow an existing binding.
+func (p *Plugin) registerPresetBindings() {
+	if p.ctx == nil || p.ctx.Keymap == nil {
+		return
+	}
+	for _, preset := range p.workspacePresets() {
+		if preset.Key == "" {
+			continue
+		}
+		if cmd := p.presetKeyConflict(preset.Key); cmd != "" {
+			p.ctx.Logger.Warn("workspace: preset key already bound, ignoring", "preset", preset.Name, "key", preset.Key, "command", cmd)
+			continue
+		}
+		p.ctx.Keymap.RegisterPluginBinding(preset.Key, presetCommandID(preset.Name), "workspace-list")
+	}
+}
+
+// presetKeyWarning describes preset keys ignored because they are already
+// bound, or "" if there are none.
+func (p *Plugin) presetKeyWarning() string {
+	var ignored []string
+	for _, preset := range p.workspacePresets() {
+		if preset.Key != "" && p.presetKeyConflict(preset.Key) != "" {
+			ignored = append(ignored, preset.Key+" ("+preset.Name+")")
+		}
+	}
+	if len(ignored) == 0 {
+		return ""
+	}
+	return "Preset keys already bound, ignored: " + strings.Join(ignored, ", ")
+}
+
+// presetCommandPrefix starts the command IDs of preset keys.
+const presetCommandPrefix = "preset-"
+
+// presetCommandID is the command a preset key is bound to.
+func presetCommandID(name string) string {
+	return presetCommandPrefix + SanitizeBranchName(name)
+}
+
+// openCreateModalWithPreset opens the create modal with a preset applied.
+func (p *Plugin) openCreateModalWithPreset(idx int) tea.Cmd {
+	open := p.openCreateModal()
+	return tea.Batch(open, p.applyCreatePreset(idx))
+}
+
+// cycleCreatePreset applies the next preset, wrapping through none.
+func (p *Plugin) cycleCreatePreset() tea.Cmd {
+	n := len(p.workspacePresets())
+	if n == 0 {
+		return nil
+	}
+	next := p.createPresetIdx + 1
+	if next >= n {
+		next = -1
+	}
+	return p.applyCreatePreset(next)
+}
+
+// applyCreatePreset fills the create modal from preset idx. -1 clears the
+// preset and restores the defaults it replaced. Name and task are left alone.
+func (p *Plugin) applyCreatePreset(idx int) tea.Cmd {
+	presets := p.workspacePresets()
+	if idx < 0 || idx >= len(presets) {
+		p.createPresetIdx = -1
+		p.createBaseBranchInput.SetValue("")
+		p.createAgentType = p.getDefaultCreateAgentType()
+		p.createAgentIdx = p.agentTypeIndex(p.createAgentType)
+		p.createSkipPermissions = false
+		p.createPromptIdx = -1
+		p.syncCreateModalFocus()
+		return nil
+	}
+	preset := presets[idx]
+	p.createPresetIdx = idx
+	p.createBaseBranchInput.SetValue(preset.BaseBranch)
+	p.branchFiltered = nil
+
+	var problems []string
+	switch agent := AgentType(preset.Agent); {
+	case preset.Agent == "":
+		p.createAgentType = p.getDefaultCreateAgentType()
+	case preset.Agent == presetNoAgent:
+		p.createAgentType = AgentNone
+	case isKnownAgentType(agent):
+		p.createAgentType = agent
+	default:
+		p.createAgentType = p.getDefaultCreateAgentType()
+		problems = append(problems, "unknown agent "+preset.Agent)
+	}
+	p.createAgentIdx = p.agentTypeIndex(p.createAgentType)
+	p.createSkipPermissions = preset.SkipPermissions && p.shouldShowSkipPermissions()
+
+	if preset.SetupProfile != "" && p.setupProfile(preset.SetupProfile) == nil {
+		problems = append(problems, "unknown setup profile "+preset.SetupProfile)
+	}
+
+	var cmd tea.Cmd
+	p.createPromptIdx = -1
+	if preset.Prompt != "" {
+		i := promptIndex(p.createPrompts, preset.Prompt)
+		switch {
+		case i < 0:
+			problems = append(problems, "unknown prompt "+preset.Prompt)
+		case len(PromptInputs(p.createPrompts[i].Body)) > 0:
+			// Ask the prompt's input questions before selecting it
+			p.openPromptPicker(p.createPrompts, ViewModeCreate)
+			cmd = p.promptPicker.choose(p.createPrompts[i])
+		default:
+			p.createPromptIdx = i
+		}
+	}
+	p.syncCreateModalFocus()
+
+	if len(problems) > 0 {
+		return tea.Batch(cmd, appmsg.ShowToast("Preset "+preset.Name+": "+strings.Join(problems, ", "), 3*time.Second))
+	}
+	return cmd
+}
+
+// promptIndex returns the index of the prompt named name, or -1.
+func promptIndex(prompts []Prompt, name string) int {
+	for i, pr := range prompts {
+		if strings.EqualFold(pr.Name, name) {
+			return i
+		}
+	}
+	return -1
+}
+
+// setupProfile returns the steps of a named setup profile, or nil.
+func (p *Plugin) setupProfile(name string) []config.ProvisionStep {
+	if p.ctx == nil || p.ctx.Config == nil {
+		return nil
+	}
+	return p.ctx.Config.Plugins.Workspace.Setup.Profiles[name]
+}
+
+// linkCreateTask links a task in the create modal. The name is derived from
+// the task unless the user typed one.
+func (p *Plugin) linkCreateTask(taskID, taskTitle string) {
+	p.createTaskID = taskID
+	p.createTaskTitle = taskTitle
+	name := p.createNameInput.Value()
+	if name != "" && name != p.createNameDerived {
+		return
+	}
+	name = p.deriveBranchName(taskID, taskTitle)
+	p.createNameInput.SetValue(name)
+	p.createNameDerived = name
+	p.branchNameValid, p.branchNameErrors, p.branchNameSanitized = ValidateBranchName(name)
+}
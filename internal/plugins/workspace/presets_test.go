@@ -0,0 +1,152 @@
+package workspace
+
+import (
+	"log/slog"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/marcus/sidecar/internal/config"
+	"github.com/marcus/sidecar/internal/keymap"
+	"github.com/marcus/sidecar/internal/plugin"
+)
+
+func newPresetTestPlugin(t *testing.T) *Plugin {
+	t.Helper()
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	cfg := config.Default()
+	cfg.Plugins.Workspace.Setup = config.WorkspaceSetupConfig{
+		Steps:    []config.ProvisionStep{{Name: "default", Run: "true"}},
+		Profiles: map[string][]config.ProvisionStep{"full": {{Name: "deps", Run: "make deps"}}},
+	}
+	cfg.Plugins.Workspace.Presets = []config.WorkspacePreset{
+		{Name: "bugfix", BaseBranch: "main", Agent: "claude", Prompt: "Fix ticket", SkipPermissions: true, SetupProfile: "full", Key: "alt+b"},
+		{Name: "spike", Agent: "none", Prompt: "Missing", SetupProfile: "nope"},
+	}
+	return &Plugin{
+		ctx:                   &plugin.Context{WorkDir: t.TempDir(), ProjectRoot: t.TempDir(), Config: cfg},
+		createNameInput:       textinput.New(),
+		createBaseBranchInput: textinput.New(),
+		createPrompts:         []Prompt{{Name: "Explore", Body: "Look around"}, {Name: "Fix ticket", Body: "Fix {{ticket}}"}},
+		createPromptIdx:       -1,
+		createPresetIdx:       -1,
+	}
+}
+
+func TestApplyCreatePreset(t *testing.T) {
+	p := newPresetTestPlugin(t)
+
+	if cmd := p.applyCreatePreset(0); cmd != nil {
+		t.Error("expected no toast for a valid preset")
+	}
+	if p.createBaseBranchInput.Value() != "main" || p.createAgentType != AgentClaude || !p.createSkipPermissions {
+		t.Errorf("base %q, agent %q, skipPerms %v", p.createBaseBranchInput.Value(), p.createAgentType, p.createSkipPermissions)
+	}
+	if prompt := p.getSelectedPrompt(); prompt == nil || prompt.Name != "Fix ticket" {
+		t.Errorf("selected prompt = %+v, want Fix ticket", prompt)
+	}
+	if steps := p.provisionSteps(); len(steps) == 0 || steps[0].Name != "deps" {
+		t.Errorf("provision steps = %+v, want the full profile", steps)
+	}
+
+	// Unknown names fall back and report the problem
+	if cmd := p.applyCreatePreset(1); cmd == nil {
+		t.Error("expected a toast for unknown prompt and profile")
+	}
+	if p.createAgentType != AgentNone || p.createSkipPermissions || p.getSelectedPrompt() != nil {
+		t.Errorf("agent %q, skipPerms %v, prompt %+v", p.createAgentType, p.createSkipPermissions, p.getSelectedPrompt())
+	}
+	if steps := p.provisionSteps(); len(steps) == 0 || steps[0].Name != "default" {
+		t.Errorf("provision steps for an unknown profile = %+v, want setup.steps", steps)
+	}
+
+	// Cycling past the last preset clears it
+	p.cycleCreatePreset()
+	if p.createPresetIdx != -1 || p.createBaseBranchInput.Value() != "" {
+		t.Errorf("preset %d, base %q after cycling past the end", p.createPresetIdx, p.createBaseBranchInput.Value())
+	}
+	if steps := p.provisionSteps(); len(steps) == 0 || steps[0].Name != "default" {
+		t.Errorf("provision steps without a preset = %+v, want setup.steps", steps)
+	}
+}
+
+func TestPresetForKey(t *testing.T) {
+	p := newPresetTestPlugin(t)
+	if got := p.presetForKey("alt+b"); got != 0 {
+		t.Errorf("presetForKey(alt+b) = %d, want 0", got)
+	}
+	if got := p.presetForKey("b"); got != -1 {
+		t.Errorf("presetForKey(b) = %d, want -1", got)
+	}
+	if got := p.presetForKey(""); got != -1 {
+		t.Errorf("presetForKey(\"\") = %d, want -1 for presets without a key", got)
+	}
+}
+
+func TestPresetKeysCannotShadowBindings(t *testing.T) {
+	p := newPresetTestPlugin(t)
+	km := keymap.NewRegistry()
+	keymap.RegisterDefaults(km)
+	p.ctx.Keymap = km
+	p.ctx.Logger = slog.Default()
+	p.ctx.Config.Plugins.Workspace.Presets = append(p.ctx.Config.Plugins.Workspace.Presets,
+		config.WorkspacePreset{Name: "nav", Key: "j"},
+		config.WorkspacePreset{Name: "new", Key: "n"},
+	)
+	p.registerPresetBindings()
+
+	for _, key := range []string{"j", "n"} {
+		if got := p.presetForKey(key); got != -1 {
+			t.Errorf("presetForKey(%s) = %d, want -1 for a key the list already uses", key, got)
+		}
+	}
+	if got := p.presetForKey("alt+b"); got != 0 {
+		t.Errorf("presetForKey(alt+b) = %d, want 0", got)
+	}
+	var bound []string
+	for _, b := range km.BindingsForContext("workspace-list") {
+		if b.Key == "j" || b.Key == "n" || b.Key == "alt+b" {
+			bound = append(bound, b.Key+"="+b.Command)
+		}
+	}
+	if !reflect.DeepEqual(bound, []string{"n=new-workspace", "alt+b=preset-bugfix"}) {
+		t.Errorf("workspace-list bindings = %v", bound)
+	}
+	if w := p.presetKeyWarning(); !strings.Contains(w, "j (nav)") || !strings.Contains(w, "n (new)") {
+		t.Errorf("warning = %q", w)
+	}
+
+	// Built-in keys missing from the keymap still win in the list
+	p.ctx.Config.Plugins.Workspace.Presets[0].Key = "ctrl+d"
+	p.handleListKeys(tea.KeyMsg{Type: tea.KeyCtrlD})
+	if p.viewMode == ViewModeCreate {
+		t.Error("ctrl+d opened the create modal instead of scrolling")
+	}
+}
+
+func TestLinkCreateTaskDerivesName(t *testing.T) {
+	p := newPresetTestPlugin(t)
+
+	p.linkCreateTask("td-1", "Fix login crash")
+	if got := p.createNameInput.Value(); got != "td-1-fix-login-crash" {
+		t.Errorf("name = %q", got)
+	}
+	if !p.branchNameValid {
+		t.Error("derived name should be valid")
+	}
+
+	// A derived name follows the linked task
+	p.linkCreateTask("td-2", "Add dark mode")
+	if got := p.createNameInput.Value(); got != "td-2-add-dark-mode" {
+		t.Errorf("name after relinking = %q", got)
+	}
+
+	// A typed name is kept
+	p.createNameInput.SetValue("my-branch")
+	p.linkCreateTask("td-3", "Something else")
+	if got := p.createNameInput.Value(); got != "my-branch" || p.createTaskID != "td-3" {
+		t.Errorf("name %q, task %q", got, p.createTaskID)
+	}
+}
@@ -3,10 +3,12 @@ package workspace
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	appmsg "github.com/marcus/sidecar/internal/msg"
 	"github.com/marcus/sidecar/internal/styles"
 )
 
@@ -18,10 +20,19 @@ type PromptPicker struct {
 	selectedIdx   int             // highlighted row (0-based into filtered, -1 = none option)
 	hoverIdx      int             // hovered row for mouse feedback (-2 = no hover, -1 = none, 0+ = prompt)
 	filterFocused bool            // true when filter has keyboard focus (vs item list)
+	answering     *promptAnswers  // non-nil while asking the chosen prompt's input questions
 	width         int
 	height        int
 }
 
+// promptAnswers collects answers to a prompt's {{input:"..."}} questions.
+type promptAnswers struct {
+	prompt    Prompt
+	questions []string
+	inputs    []textinput.Model // One per question
+	focus     int
+}
+
 // PromptSelectedMsg is sent when a prompt is selected.
 type PromptSelectedMsg struct {
 	Prompt *Prompt // nil means "none" was selected
@@ -60,6 +71,10 @@ func (pp *PromptPicker) Update(msg tea.Msg) (*PromptPicker, tea.Cmd) {
 	case tea.KeyMsg:
 		key := msg.String()
 
+		if pp.answering != nil {
+			return pp, pp.updateAnswers(msg)
+		}
+
 		// When no prompts configured, handle 'd' to install defaults
 		if len(pp.prompts) == 0 && key == "d" {
 			return pp, func() tea.Msg { return PromptInstallDefaultsMsg{} }
@@ -86,8 +101,7 @@ func (pp *PromptPicker) Update(msg tea.Msg) (*PromptPicker, tea.Cmd) {
 				return pp, func() tea.Msg { return PromptSelectedMsg{Prompt: nil} }
 			}
 			if pp.selectedIdx < len(pp.filtered) {
-				prompt := pp.filtered[pp.selectedIdx]
-				return pp, func() tea.Msg { return PromptSelectedMsg{Prompt: &prompt} }
+				return pp, pp.choose(pp.filtered[pp.selectedIdx])
 			}
 			return pp, nil
 
@@ -142,6 +156,82 @@ func (pp *PromptPicker) Update(msg tea.Msg) (*PromptPicker, tea.Cmd) {
 	return pp, nil
 }
 
+// choose selects a prompt. Prompts with input questions ask them first;
+// prompts whose template failed to load can't be chosen.
+func (pp *PromptPicker) choose(prompt Prompt) tea.Cmd {
+	if prompt.Error != "" {
+		return appmsg.ShowToast("Prompt template error: "+prompt.Error, 3*time.Second)
+	}
+	questions := PromptInputs(prompt.Body)
+	if len(questions) == 0 {
+		return func() tea.Msg { return PromptSelectedMsg{Prompt: &prompt} }
+	}
+
+	a := &promptAnswers{prompt: prompt, questions: questions}
+	for _, q := range questions {
+		ti := textinput.New()
+		ti.Prompt = ""
+		ti.CharLimit = 500
+		// Offer the answers given last time
+		ti.SetValue(prompt.Inputs[q])
+		a.inputs = append(a.inputs, ti)
+	}
+	a.setFocus(0)
+	pp.answering = a
+	return nil
+}
+
+// updateAnswers handles keys while answering input questions. Enter moves to
+// the next question, and on the last one selects the prompt.
+func (pp *PromptPicker) updateAnswers(msg tea.KeyMsg) tea.Cmd {
+	a := pp.answering
+	switch msg.String() {
+	case "esc":
+		// Back to the prompt list
+		pp.answering = nil
+	case "tab", "down":
+		a.setFocus(a.focus + 1)
+	case "shift+tab", "up":
+		a.setFocus(a.focus - 1)
+	case "enter":
+		if a.focus < len(a.inputs)-1 {
+			a.setFocus(a.focus + 1)
+			return nil
+		}
+		return pp.submitAnswers()
+	default:
+		var cmd tea.Cmd
+		a.inputs[a.focus], cmd = a.inputs[a.focus].Update(msg)
+		return cmd
+	}
+	return nil
+}
+
+// submitAnswers selects the prompt with the given answers.
+func (pp *PromptPicker) submitAnswers() tea.Cmd {
+	a := pp.answering
+	prompt := a.prompt
+	prompt.Inputs = make(map[string]string, len(a.questions))
+	for i, q := range a.questions {
+		prompt.Inputs[q] = strings.TrimSpace(a.inputs[i].Value())
+	}
+	pp.answering = nil
+	return func() tea.Msg { return PromptSelectedMsg{Prompt: &prompt} }
+}
+
+// setFocus focuses question i, wrapping around.
+func (a *promptAnswers) setFocus(i int) {
+	n := len(a.inputs)
+	a.focus = (i%n + n) % n
+	for j := range a.inputs {
+		if j == a.focus {
+			a.inputs[j].Focus()
+		} else {
+			a.inputs[j].Blur()
+		}
+	}
+}
+
 // applyFilter filters prompts based on the current filter input.
 func (pp *PromptPicker) applyFilter() {
 	query := strings.ToLower(pp.filterInput.Value())
@@ -257,6 +347,9 @@ func (pp *PromptPicker) View() string {
 
 		// Preview (truncate, rune-safe for Unicode)
 		preview := strings.ReplaceAll(p.Body, "\n", " ")
+		if p.Error != "" {
+			preview = "invalid: " + p.Error
+		}
 		maxPreview := pp.width - 50
 		if maxPreview < 10 {
 			maxPreview = 10
@@ -13,9 +13,10 @@ import (
 )
 
 const (
-	promptPickerFilterID   = "prompt-picker-filter"
-	promptPickerItemPrefix = "prompt-picker-item-"
-	promptPickerNoneID     = "prompt-picker-item-none"
+	promptPickerFilterID    = "prompt-picker-filter"
+	promptPickerItemPrefix  = "prompt-picker-item-"
+	promptPickerNoneID      = "prompt-picker-item-none"
+	promptPickerInputPrefix = "prompt-picker-input-"
 )
 
 var (
@@ -30,6 +31,21 @@ func promptPickerItemID(idx int) string {
 	return fmt.Sprintf("%s%d", promptPickerItemPrefix, idx)
 }
 
+func promptPickerInputID(idx int) string {
+	return fmt.Sprintf("%s%d", promptPickerInputPrefix, idx)
+}
+
+func parsePromptPickerInputID(id string) (int, bool) {
+	if !strings.HasPrefix(id, promptPickerInputPrefix) {
+		return 0, false
+	}
+	idx, err := strconv.Atoi(strings.TrimPrefix(id, promptPickerInputPrefix))
+	if err != nil {
+		return 0, false
+	}
+	return idx, true
+}
+
 func parsePromptPickerItemID(id string) (int, bool) {
 	if id == promptPickerNoneID {
 		return -1, true
@@ -58,12 +74,29 @@ func (p *Plugin) ensurePromptPickerModal() {
 	}
 
 	isEmpty := len(p.promptPicker.prompts) == 0
-	if p.promptPickerModal != nil && p.promptPickerModalWidth == modalW && p.promptPickerModalEmpty == isEmpty {
+	answers := p.promptPicker.answering
+	if p.promptPickerModal != nil && p.promptPickerModalWidth == modalW && p.promptPickerModalEmpty == isEmpty &&
+		p.promptPickerModalAnswering == (answers != nil) {
 		return
 	}
 
 	p.promptPickerModalWidth = modalW
 	p.promptPickerModalEmpty = isEmpty
+	p.promptPickerModalAnswering = answers != nil
+
+	if answers != nil {
+		m := modal.New("Prompt: "+answers.prompt.Name,
+			modal.WithWidth(modalW),
+			modal.WithHints(false),
+		)
+		for i, q := range answers.questions {
+			m.AddSection(modal.InputWithLabel(promptPickerInputID(i), q, &answers.inputs[i], modal.WithSubmitOnEnter(false)))
+		}
+		m.AddSection(modal.Spacer()).
+			AddSection(modal.Text(styles.Muted.Render("Enter: next/start   Tab: next field   Esc: back to prompts")))
+		p.promptPickerModal = m
+		return
+	}
 
 	if isEmpty {
 		p.promptPickerModal = modal.New("Select Prompt",
@@ -83,7 +116,8 @@ func (p *Plugin) ensurePromptPickerModal() {
 		AddSection(p.promptPickerHeaderSection()).
 		AddSection(p.promptPickerSeparatorSection()).
 		AddSection(p.promptPickerListSection()).
-		AddSection(modal.When(p.promptPickerHasMore, p.promptPickerMoreSection()))
+		AddSection(modal.When(p.promptPickerHasMore, p.promptPickerMoreSection())).
+		AddSection(modal.When(p.promptPickerSelectedInvalid, p.promptPickerErrorSection()))
 }
 
 func (p *Plugin) syncPromptPickerFocus() {
@@ -91,6 +125,11 @@ func (p *Plugin) syncPromptPickerFocus() {
 		return
 	}
 
+	if a := p.promptPicker.answering; a != nil {
+		p.promptPickerModal.SetFocus(promptPickerInputID(a.focus))
+		return
+	}
+
 	if p.promptPicker.filterFocused {
 		p.promptPickerModal.SetFocus(promptPickerFilterID)
 		return
@@ -109,12 +148,51 @@ func (p *Plugin) promptPickerSelectCmd() tea.Cmd {
 		return func() tea.Msg { return PromptSelectedMsg{Prompt: nil} }
 	}
 	if pp.selectedIdx < len(pp.filtered) {
-		prompt := pp.filtered[pp.selectedIdx]
-		return func() tea.Msg { return PromptSelectedMsg{Prompt: &prompt} }
+		cmd := pp.choose(pp.filtered[pp.selectedIdx])
+		if pp.answering != nil {
+			// Rebuild as the input questions step
+			p.clearPromptPickerModal()
+		}
+		return cmd
 	}
 	return nil
 }
 
+// handlePromptAnswerKeys handles keys while the picker asks a prompt's input
+// questions.
+func (p *Plugin) handlePromptAnswerKeys(msg tea.KeyMsg) tea.Cmd {
+	pp := p.promptPicker
+	cmd := pp.updateAnswers(msg)
+	if pp.answering == nil {
+		// Answered or backed out; either way the list modal comes back
+		p.clearPromptPickerModal()
+		return cmd
+	}
+	p.syncPromptPickerFocus()
+	return cmd
+}
+
+// promptPickerSelectedInvalid reports whether the highlighted prompt's
+// template failed to load.
+func (p *Plugin) promptPickerSelectedInvalid() bool {
+	pp := p.promptPicker
+	return pp != nil && pp.selectedIdx >= 0 && pp.selectedIdx < len(pp.filtered) &&
+		pp.filtered[pp.selectedIdx].Error != ""
+}
+
+// promptPickerErrorSection shows the highlighted prompt's template error.
+func (p *Plugin) promptPickerErrorSection() modal.Section {
+	return modal.Custom(func(contentWidth int, focusID, hoverID string) modal.RenderedSection {
+		if !p.promptPickerSelectedInvalid() {
+			return modal.RenderedSection{}
+		}
+		prompt := p.promptPicker.filtered[p.promptPicker.selectedIdx]
+		text := "Template error: " + prompt.Error
+		wrapped := lipgloss.NewStyle().Width(contentWidth).Foreground(styles.Error).Render(text)
+		return modal.RenderedSection{Content: "\n" + wrapped}
+	}, nil)
+}
+
 func (p *Plugin) promptPickerEmptySection() modal.Section {
 	return modal.Custom(func(contentWidth int, focusID, hoverID string) modal.RenderedSection {
 		var sb strings.Builder
@@ -261,6 +339,9 @@ func (p *Plugin) promptPickerPromptLine(prompt Prompt, idx int, selected, hovere
 	ticket := string(prompt.TicketMode)
 
 	preview := strings.ReplaceAll(prompt.Body, "\n", " ")
+	if prompt.Error != "" {
+		preview = "invalid: " + prompt.Error
+	}
 	baseWidth := 46
 	maxPreview := width - baseWidth
 	if maxPreview < 5 {
@@ -273,6 +354,9 @@ func (p *Plugin) promptPickerPromptLine(prompt Prompt, idx int, selected, hovere
 	line := fmt.Sprintf("%s%-24s %-7s %-10s %s", prefix, truncateString(prompt.Name, 24), scope, ticket, preview)
 	line = ansi.Truncate(line, width, "")
 
+	if prompt.Error != "" && !selected {
+		return lipgloss.NewStyle().Foreground(styles.Error).Render(line)
+	}
 	if selected {
 		return promptPickerSelectedStyle.Render(line)
 	}
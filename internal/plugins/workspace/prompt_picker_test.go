@@ -3,6 +3,7 @@ package workspace
 import (
 	"os"
 	"path/filepath"
+	"reflect"
 	"testing"
 
 	tea "github.com/charmbracelet/bubbletea"
@@ -85,6 +86,54 @@ func TestPromptPickerEnterEmptyPrompts(t *testing.T) {
 	}
 }
 
+func TestPromptPickerAsksInputs(t *testing.T) {
+	prompts := []Prompt{{
+		Name: "scoped",
+		Body: `Refactor {{input:"Which package?"}} for {{input:"Goal?"}}`,
+		// Answers from a previous pick are offered again
+		Inputs: map[string]string{"Goal?": "speed"},
+	}}
+	pp := NewPromptPicker(prompts, 80, 24)
+	pp.selectedIdx = 0
+
+	if _, cmd := pp.Update(tea.KeyMsg{Type: tea.KeyEnter}); cmd != nil {
+		t.Fatal("Expected questions before selecting, got a command")
+	}
+	if pp.answering == nil || len(pp.answering.inputs) != 2 {
+		t.Fatal("Expected two questions to answer")
+	}
+
+	for _, r := range "auth" {
+		pp.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	pp.Update(tea.KeyMsg{Type: tea.KeyEnter}) // Next question
+	_, cmd := pp.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil {
+		t.Fatal("Expected PromptSelectedMsg after the last question")
+	}
+	selected, ok := cmd().(PromptSelectedMsg)
+	if !ok || selected.Prompt == nil {
+		t.Fatalf("Expected PromptSelectedMsg with prompt, got %#v", cmd())
+	}
+	want := map[string]string{"Which package?": "auth", "Goal?": "speed"}
+	if !reflect.DeepEqual(selected.Prompt.Inputs, want) {
+		t.Errorf("Inputs = %v, want %v", selected.Prompt.Inputs, want)
+	}
+}
+
+func TestPromptPickerRejectsInvalidPrompt(t *testing.T) {
+	pp := NewPromptPicker([]Prompt{{Name: "bad", Body: "{{nope}}", Error: `line 1: unknown variable "nope"`}}, 80, 24)
+	pp.selectedIdx = 0
+
+	_, cmd := pp.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil {
+		t.Fatal("Expected a toast command")
+	}
+	if _, ok := cmd().(PromptSelectedMsg); ok {
+		t.Error("Invalid prompt should not be selectable")
+	}
+}
+
 func TestInstallDefaultsRoundTrip(t *testing.T) {
 	// Exercises the same code path as the PromptInstallDefaultsMsg handler:
 	// WriteDefaultPromptsToConfig → LoadPrompts → NewPromptPicker
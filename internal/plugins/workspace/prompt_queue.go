@@ -0,0 +1,269 @@
+package workspace
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	appmsg "github.com/marcus/sidecar/internal/msg"
+	"github.com/marcus/sidecar/internal/plugin"
+)
+
+// queueDispatchGrace bounds how long a sent prompt holds back the next one
+// when the agent is never seen busy (it finished between two polls).
+const queueDispatchGrace = 30 * time.Second
+
+// PromptQueue is an ordered list of prompts fed to an agent one at a time,
+// each time it goes idle. Persisted in the shell manifest.
+type PromptQueue struct {
+	Items  []string `json:"items,omitempty"`
+	Paused bool     `json:"paused,omitempty"`
+}
+
+// Len returns the number of queued prompts (0 for a nil queue).
+func (q *PromptQueue) Len() int {
+	if q == nil {
+		return 0
+	}
+	return len(q.Items)
+}
+
+// IsZero reports whether the queue carries no state worth persisting.
+func (q *PromptQueue) IsZero() bool {
+	return q == nil || (len(q.Items) == 0 && !q.Paused)
+}
+
+// Clone returns a deep copy of the queue.
+func (q *PromptQueue) Clone() *PromptQueue {
+	if q == nil {
+		return nil
+	}
+	return &PromptQueue{Items: append([]string(nil), q.Items...), Paused: q.Paused}
+}
+
+// Pop removes and returns the first prompt.
+func (q *PromptQueue) Pop() (string, bool) {
+	if q.Len() == 0 {
+		return "", false
+	}
+	text := q.Items[0]
+	q.Items = q.Items[1:]
+	return text, true
+}
+
+// Remove drops the prompt at index i.
+func (q *PromptQueue) Remove(i int) bool {
+	if i < 0 || i >= q.Len() {
+		return false
+	}
+	q.Items = append(q.Items[:i], q.Items[i+1:]...)
+	return true
+}
+
+// Move swaps the prompt at index i with its neighbour delta positions away
+// and returns the prompt's new index.
+func (q *PromptQueue) Move(i, delta int) int {
+	j := i + delta
+	if i < 0 || i >= q.Len() || j < 0 || j >= q.Len() {
+		return i
+	}
+	q.Items[i], q.Items[j] = q.Items[j], q.Items[i]
+	return j
+}
+
+// QueuedPromptSentMsg reports delivery of a queued prompt to an agent session.
+type QueuedPromptSentMsg struct {
+	Epoch uint64 // Epoch when request was issued (for stale detection)
+	Key   string // Worktree name or shell tmux name
+	Text  string
+	Err   error
+}
+
+// GetEpoch implements plugin.EpochMessage.
+func (m QueuedPromptSentMsg) GetEpoch() uint64 { return m.Epoch }
+
+// queueSlot resolves a queue key (shell tmux name or worktree name) to the
+// field holding its queue and a display name.
+func (p *Plugin) queueSlot(key string) (**PromptQueue, string, bool) {
+	if shell := p.findShellByName(key); shell != nil {
+		return &shell.Queue, shell.Name, true
+	}
+	if wt := p.findWorktree(key); wt != nil {
+		return &wt.Queue, wt.Name, true
+	}
+	return nil, "", false
+}
+
+// persistQueue writes the queue for key to the shell manifest.
+func (p *Plugin) persistQueue(key string) {
+	if p.shellManifest == nil {
+		return
+	}
+	if shell := p.findShellByName(key); shell != nil {
+		if p.shellManifest.FindShell(shell.TmuxName) != nil {
+			_ = p.shellManifest.UpdateShell(shellToDefinition(shell))
+		}
+		return
+	}
+	if wt := p.findWorktree(key); wt != nil {
+		_ = p.shellManifest.SetWorktreeQueue(wt.Name, wt.Queue)
+	}
+}
+
+// attachWorktreeQueues copies persisted queues onto the current worktrees.
+// Called after refresh (which replaces the worktree structs) and after the
+// manifest is reloaded by another instance.
+func (p *Plugin) attachWorktreeQueues() {
+	if p.shellManifest == nil {
+		return
+	}
+	for _, wt := range p.worktrees {
+		wt.Queue = p.shellManifest.WorktreeQueue(wt.Name)
+	}
+}
+
+// enqueuePrompt appends a prompt to key's queue, persists it, and sends it
+// straight away if the agent is already idle.
+func (p *Plugin) enqueuePrompt(key, text string) tea.Cmd {
+	text = strings.TrimSpace(text)
+	slot, _, ok := p.queueSlot(key)
+	if !ok || text == "" {
+		return nil
+	}
+	if *slot == nil {
+		*slot = &PromptQueue{}
+	}
+	(*slot).Items = append((*slot).Items, text)
+	p.persistQueue(key)
+	return p.dispatchQueue(key)
+}
+
+// dispatchQueue sends the next queued prompt for key if its agent is idle.
+func (p *Plugin) dispatchQueue(key string) tea.Cmd {
+	if shell := p.findShellByName(key); shell != nil {
+		return p.maybeDispatchShellQueue(shell)
+	}
+	return p.maybeDispatchWorktreeQueue(p.findWorktree(key))
+}
+
+// waitingMeansIdle reports whether StatusWaiting from agentType's session
+// detector means the turn ended and the agent sits at its input prompt.
+// Claude (session files and Notification hook) and the tmux fallback use it
+// for an open permission dialog, where typed text would answer the dialog.
+func waitingMeansIdle(agentType AgentType) bool {
+	switch agentType {
+	case AgentCodex, AgentGemini, AgentOpenCode, AgentPi, AgentAmp:
+		return true
+	}
+	return false
+}
+
+// atInputPrompt reports whether an agent with the given status is idle at
+// its input prompt, so text sent to it starts a new turn.
+func atInputPrompt(agentType AgentType, status WorktreeStatus) bool {
+	return status == StatusDone || (status == StatusWaiting && waitingMeansIdle(agentType))
+}
+
+// maybeDispatchWorktreeQueue feeds the next queued prompt to a worktree's
+// agent once it is idle at its input prompt.
+func (p *Plugin) maybeDispatchWorktreeQueue(wt *Worktree) tea.Cmd {
+	if wt == nil || wt.Agent == nil || wt.Agent.TmuxSession == "" {
+		return nil
+	}
+	switch wt.Status {
+	case StatusActive, StatusThinking:
+		delete(p.queueSentAt, wt.Name)
+		return nil
+	}
+	if atInputPrompt(wt.Agent.Type, wt.Status) {
+		return p.sendNextQueued(wt.Name, wt.Agent.TmuxSession, wt.Queue)
+	}
+	return nil
+}
+
+// maybeDispatchShellQueue is maybeDispatchWorktreeQueue for agent shells.
+func (p *Plugin) maybeDispatchShellQueue(shell *ShellSession) tea.Cmd {
+	if shell == nil || shell.Agent == nil || shell.ChosenAgent == AgentNone || shell.ChosenAgent == "" {
+		return nil
+	}
+	switch shell.Agent.Status {
+	case AgentStatusRunning:
+		delete(p.queueSentAt, shell.TmuxName)
+		return nil
+	case AgentStatusDone:
+		return p.sendNextQueued(shell.TmuxName, shell.TmuxName, shell.Queue)
+	case AgentStatusWaiting:
+		if waitingMeansIdle(shell.ChosenAgent) {
+			return p.sendNextQueued(shell.TmuxName, shell.TmuxName, shell.Queue)
+		}
+	}
+	return nil
+}
+
+// sendNextQueued pops and sends the head of q unless the queue is paused or
+// a previously sent prompt has not been picked up yet.
+func (p *Plugin) sendNextQueued(key, session string, q *PromptQueue) tea.Cmd {
+	if q.Len() == 0 || q.Paused {
+		return nil
+	}
+	if sent, ok := p.queueSentAt[key]; ok && time.Since(sent) < queueDispatchGrace {
+		return nil
+	}
+	text, _ := q.Pop()
+	if p.queueSentAt == nil {
+		p.queueSentAt = make(map[string]time.Time)
+	}
+	p.queueSentAt[key] = time.Now()
+	p.persistQueue(key)
+
+	epoch := p.ctx.Epoch
+	return func() tea.Msg {
+		return QueuedPromptSentMsg{Epoch: epoch, Key: key, Text: text, Err: sendTmuxText(session, text)}
+	}
+}
+
+// handleQueuedPromptSent puts an undelivered prompt back at the head of its
+// queue and reports the outcome.
+func (p *Plugin) handleQueuedPromptSent(msg QueuedPromptSentMsg) tea.Cmd {
+	if plugin.IsStale(p.ctx, msg) {
+		return nil
+	}
+	slot, name, ok := p.queueSlot(msg.Key)
+	if !ok {
+		return nil
+	}
+	if msg.Err != nil {
+		delete(p.queueSentAt, msg.Key)
+		if *slot == nil {
+			*slot = &PromptQueue{}
+		}
+		(*slot).Items = append([]string{msg.Text}, (*slot).Items...)
+		p.persistQueue(msg.Key)
+		return appmsg.ShowToast(fmt.Sprintf("Queued prompt not sent to %s: %v", name, msg.Err), 3*time.Second)
+	}
+	return appmsg.ShowToast(fmt.Sprintf("Sent queued prompt to %s (%d left)", name, (*slot).Len()), 2*time.Second)
+}
+
+// removeWorktreeQueue forgets a deleted worktree's queue.
+func (p *Plugin) removeWorktreeQueue(name string) {
+	delete(p.queueSentAt, name)
+	if p.shellManifest != nil {
+		_ = p.shellManifest.SetWorktreeQueue(name, nil)
+	}
+}
+
+// sendTmuxText types text into a session and presses Enter. Multi-line
+// text is pasted instead (bracketed if the app asked for it) so embedded
+// newlines don't submit it early.
+func sendTmuxText(session, text string) error {
+	if strings.Contains(text, "\n") {
+		if err := sessionBackend.Paste(session, text, pasteAuto); err != nil {
+			return err
+		}
+	} else if err := sessionBackend.SendLiteral(session, text); err != nil {
+		return err
+	}
+	// Send Enter separately
+	return sessionBackend.SendKey(session, "Enter")
+}
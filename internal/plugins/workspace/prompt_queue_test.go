@@ -0,0 +1,112 @@
+package workspace
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/marcus/sidecar/internal/plugin"
+)
+
+func TestPromptQueueEditing(t *testing.T) {
+	q := &PromptQueue{Items: []string{"a", "b", "c"}}
+
+	if idx := q.Move(0, 1); idx != 1 || q.Items[0] != "b" || q.Items[1] != "a" {
+		t.Errorf("Move down = %d, %v", idx, q.Items)
+	}
+	if idx := q.Move(0, -1); idx != 0 {
+		t.Errorf("Move past top = %d, want 0", idx)
+	}
+	if !q.Remove(2) || q.Len() != 2 {
+		t.Errorf("Remove = %v", q.Items)
+	}
+	if text, ok := q.Pop(); !ok || text != "b" || q.Len() != 1 {
+		t.Errorf("Pop = %q, %v, left %v", text, ok, q.Items)
+	}
+
+	var nilQueue *PromptQueue
+	if nilQueue.Len() != 0 || !nilQueue.IsZero() || nilQueue.Clone() != nil {
+		t.Error("nil queue should be empty")
+	}
+	if (&PromptQueue{Paused: true}).IsZero() {
+		t.Error("paused queue should be persisted")
+	}
+}
+
+func TestWorktreeQueueDispatch(t *testing.T) {
+	p := &Plugin{ctx: &plugin.Context{}}
+	wt := &Worktree{
+		Name:   "auth",
+		Agent:  &Agent{TmuxSession: "sidecar-ws-auth"},
+		Status: StatusActive,
+		Queue:  &PromptQueue{Items: []string{"first", "second"}},
+	}
+	p.worktrees = []*Worktree{wt}
+
+	if cmd := p.maybeDispatchWorktreeQueue(wt); cmd != nil {
+		t.Fatal("busy agent should not receive a prompt")
+	}
+
+	wt.Status = StatusDone
+	if cmd := p.maybeDispatchWorktreeQueue(wt); cmd == nil || wt.Queue.Len() != 1 {
+		t.Fatalf("idle agent should receive the next prompt, queue %v", wt.Queue.Items)
+	}
+	if cmd := p.maybeDispatchWorktreeQueue(wt); cmd != nil {
+		t.Fatal("next prompt must wait until the agent has picked up the last one")
+	}
+
+	// Agent goes busy, then idle again: the next prompt is sent
+	wt.Status = StatusActive
+	p.maybeDispatchWorktreeQueue(wt)
+	wt.Status = StatusDone
+	wt.Queue.Paused = true
+	if cmd := p.maybeDispatchWorktreeQueue(wt); cmd != nil {
+		t.Fatal("paused queue should not dispatch")
+	}
+	wt.Queue.Paused = false
+	if cmd := p.maybeDispatchWorktreeQueue(wt); cmd == nil || wt.Queue.Len() != 0 {
+		t.Errorf("resumed queue should dispatch, left %v", wt.Queue.Items)
+	}
+}
+
+func TestWaitingClaudeKeepsQueue(t *testing.T) {
+	p := &Plugin{ctx: &plugin.Context{}}
+	wt := &Worktree{
+		Name:   "auth",
+		Agent:  &Agent{Type: AgentClaude, TmuxSession: "sidecar-ws-auth"},
+		Status: StatusWaiting,
+		Queue:  &PromptQueue{Items: []string{"next"}},
+	}
+	p.worktrees = []*Worktree{wt}
+
+	// Claude is waiting on a permission dialog; typing would answer it
+	if cmd := p.maybeDispatchWorktreeQueue(wt); cmd != nil || wt.Queue.Len() != 1 {
+		t.Fatalf("waiting Claude should keep its queue, left %v", wt.Queue.Items)
+	}
+
+	// Pi reports waiting once its turn has ended
+	wt.Agent.Type = AgentPi
+	if cmd := p.maybeDispatchWorktreeQueue(wt); cmd == nil || wt.Queue.Len() != 0 {
+		t.Errorf("waiting Pi should receive the next prompt, left %v", wt.Queue.Items)
+	}
+
+	shell := &ShellSession{
+		TmuxName:    "sidecar-sh-1",
+		ChosenAgent: AgentClaude,
+		Agent:       &Agent{Status: AgentStatusWaiting},
+		Queue:       &PromptQueue{Items: []string{"next"}},
+	}
+	if cmd := p.maybeDispatchShellQueue(shell); cmd != nil || shell.Queue.Len() != 1 {
+		t.Errorf("waiting Claude shell should keep its queue, left %v", shell.Queue.Items)
+	}
+}
+
+func TestQueuedPromptSendFailureRequeues(t *testing.T) {
+	p := &Plugin{ctx: &plugin.Context{}}
+	wt := &Worktree{Name: "auth", Queue: &PromptQueue{Items: []string{"second"}}}
+	p.worktrees = []*Worktree{wt}
+
+	p.handleQueuedPromptSent(QueuedPromptSentMsg{Key: "auth", Text: "first", Err: errors.New("no session")})
+	if wt.Queue.Len() != 2 || wt.Queue.Items[0] != "first" {
+		t.Errorf("failed prompt should return to the head of the queue, got %v", wt.Queue.Items)
+	}
+}
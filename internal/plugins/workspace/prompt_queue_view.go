@@ -0,0 +1,173 @@
+package workspace
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/marcus/sidecar/internal/modal"
+	"github.com/marcus/sidecar/internal/styles"
+	"github.com/marcus/sidecar/internal/ui"
+)
+
+const (
+	promptQueueInputID = "prompt-queue-input"
+	promptQueueListID  = "prompt-queue-list"
+	promptQueueMaxRows = 8
+)
+
+// openPromptQueueModal opens the queue editor for the selected shell or worktree.
+func (p *Plugin) openPromptQueueModal() {
+	key := ""
+	if p.shellSelected {
+		shell := p.getSelectedShell()
+		if shell == nil || shell.ChosenAgent == AgentNone || shell.ChosenAgent == "" {
+			return
+		}
+		key = shell.TmuxName
+	} else if wt := p.selectedWorktree(); wt != nil {
+		key = wt.Name
+	}
+	if key == "" {
+		return
+	}
+
+	p.queueKey = key
+	p.queueInput = textinput.New()
+	p.queueInput.Placeholder = "Prompt to send when the agent is idle..."
+	p.queueInput.CharLimit = 0
+	p.queueInput.Prompt = ""
+	p.queueInput.Focus()
+	p.queueIdx = 0
+	p.queueModal = nil
+	p.queueModalWidth = 0
+	p.viewMode = ViewModePromptQueue
+}
+
+// clearPromptQueueModal closes the queue editor.
+func (p *Plugin) clearPromptQueueModal() {
+	p.queueKey = ""
+	p.queueInput = textinput.Model{}
+	p.queueIdx = 0
+	p.queueModal = nil
+	p.queueModalWidth = 0
+	p.viewMode = ViewModeList
+}
+
+// promptQueue returns the queue being edited (may be nil).
+func (p *Plugin) promptQueue() *PromptQueue {
+	slot, _, ok := p.queueSlot(p.queueKey)
+	if !ok {
+		return nil
+	}
+	return *slot
+}
+
+// ensurePromptQueueModal builds or rebuilds the queue editor modal.
+func (p *Plugin) ensurePromptQueueModal() {
+	_, name, ok := p.queueSlot(p.queueKey)
+	if !ok {
+		p.queueModal = nil
+		return
+	}
+
+	modalW := 80
+	maxW := p.width - 4
+	if maxW < 1 {
+		maxW = 1
+	}
+	if modalW > maxW {
+		modalW = maxW
+	}
+	p.queueInput.Width = modalW - 10
+
+	if p.queueModal != nil && p.queueModalWidth == modalW {
+		return
+	}
+	p.queueModalWidth = modalW
+
+	p.queueModal = modal.New("Prompt Queue: "+name,
+		modal.WithWidth(modalW),
+		modal.WithHints(false),
+	).
+		AddSection(modal.InputWithLabel(promptQueueInputID, "Add prompt:", &p.queueInput)).
+		AddSection(modal.Spacer()).
+		AddSection(p.promptQueueListSection())
+}
+
+// promptQueueListSection renders the queued prompts and the control hints.
+func (p *Plugin) promptQueueListSection() modal.Section {
+	return modal.Custom(func(contentWidth int, focusID, hoverID string) modal.RenderedSection {
+		q := p.promptQueue()
+		focused := focusID == promptQueueListID
+
+		var lines []string
+		header := fmt.Sprintf("Queued (%d)", q.Len())
+		if q != nil && q.Paused {
+			header += "  " + lipgloss.NewStyle().Foreground(styles.Warning).Render("⏸ paused")
+		}
+		lines = append(lines, header)
+
+		if q.Len() == 0 {
+			lines = append(lines, dimText("  Nothing queued"))
+		}
+		start := 0
+		if p.queueIdx >= promptQueueMaxRows {
+			start = p.queueIdx - promptQueueMaxRows + 1
+		}
+		for i := start; i < q.Len() && i < start+promptQueueMaxRows; i++ {
+			prefix := "  "
+			if focused && i == p.queueIdx {
+				prefix = "> "
+			}
+			text := strings.Join(strings.Fields(q.Items[i]), " ")
+			row := ansi.Truncate(fmt.Sprintf("%s%d. %s", prefix, i+1, text), contentWidth, "…")
+			if focused && i == p.queueIdx {
+				row = lipgloss.NewStyle().Foreground(styles.Primary).Render(row)
+			}
+			lines = append(lines, row)
+		}
+
+		lines = append(lines, "")
+		if focused {
+			lines = append(lines, dimText("K/J move · x skip · p pause/resume · tab add · esc close"))
+		} else {
+			lines = append(lines, dimText("enter add · tab edit queue · esc close"))
+		}
+
+		return modal.RenderedSection{
+			Content: strings.Join(lines, "\n"),
+			Focusables: []modal.FocusableInfo{{
+				ID:     promptQueueListID,
+				Width:  contentWidth,
+				Height: len(lines),
+			}},
+		}
+	}, nil)
+}
+
+// renderPromptQueueModal renders the queue editor over the list view.
+func (p *Plugin) renderPromptQueueModal(width, height int) string {
+	background := p.renderListView(width, height)
+
+	p.ensurePromptQueueModal()
+	if p.queueModal == nil {
+		return background
+	}
+
+	modalContent := p.queueModal.Render(width, height, p.mouseHandler)
+	return ui.OverlayModal(background, modalContent, width, height)
+}
+
+// queueIndicator returns the sidebar label for a queue ("" when empty).
+func queueIndicator(q *PromptQueue) string {
+	if q.Len() == 0 {
+		return ""
+	}
+	if q.Paused {
+		return fmt.Sprintf("⏸ %d queued", q.Len())
+	}
+	return fmt.Sprintf("≡ %d queued", q.Len())
+}
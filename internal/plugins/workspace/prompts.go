@@ -26,6 +26,11 @@ type Prompt struct {
 	TicketMode TicketMode `json:"ticketMode"`
 	Body       string     `json:"body"`
 	Source     string     `json:"-"` // "global" or "project" (set at load time)
+	Error      string     `json:"-"` // Template error found at load time
+
+	// Inputs holds the answers to the body's {{input:"..."}} questions,
+	// keyed by question. Saved with the worktree so restarts reuse them.
+	Inputs map[string]string `json:"inputs,omitempty"`
 }
 
 // configWithPrompts is the config structure for loading prompts.
@@ -105,6 +110,10 @@ func loadPromptsFromFile(path, source string) ([]Prompt, error) {
 		if cfg.Prompts[i].TicketMode == "" {
 			cfg.Prompts[i].TicketMode = TicketOptional
 		}
+		// Keep invalid templates listed so the picker can show what's wrong
+		if _, err := ParsePromptTemplate(cfg.Prompts[i].Body); err != nil {
+			cfg.Prompts[i].Error = err.Error()
+		}
 	}
 
 	return cfg.Prompts, nil
@@ -282,6 +282,32 @@ func TestLoadPromptsJSON(t *testing.T) {
 	}
 }
 
+func TestLoadPromptsValidatesTemplates(t *testing.T) {
+	globalDir := t.TempDir()
+
+	config := `{
+  "prompts": [
+    {"name": "good", "body": "Work on {{ticket}} in {{branch}}"},
+    {"name": "bad", "body": "{{#if ticket}}never closed"}
+  ]
+}`
+	if err := os.WriteFile(filepath.Join(globalDir, "config.json"), []byte(config), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	prompts := LoadPrompts(globalDir, t.TempDir())
+	if len(prompts) != 2 {
+		t.Fatalf("Expected 2 prompts (invalid ones stay listed), got %d", len(prompts))
+	}
+	// Sorted by name: bad, good
+	if prompts[0].Error == "" {
+		t.Error("Expected template error on 'bad'")
+	}
+	if prompts[1].Error != "" {
+		t.Errorf("Unexpected error on 'good': %s", prompts[1].Error)
+	}
+}
+
 func TestLoadPromptsDefaultTicketMode(t *testing.T) {
 	// Test that ticketMode defaults to optional when not specified
 	globalDir := t.TempDir()
@@ -0,0 +1,433 @@
+package workspace
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/marcus/sidecar/internal/config"
+)
+
+// Copy modes for provisioning copy steps.
+const (
+	copyModeAuto     = "auto"
+	copyModeReflink  = "reflink"
+	copyModeHardlink = "hardlink"
+	copyModeCopy     = "copy"
+)
+
+// ProvisionState is the progress of a provisioning step.
+type ProvisionState string
+
+const (
+	ProvisionPending ProvisionState = "pending"
+	ProvisionRunning ProvisionState = "running"
+	ProvisionDone    ProvisionState = "done"
+	ProvisionSkipped ProvisionState = "skipped"
+	ProvisionFailed  ProvisionState = "failed"
+)
+
+// ProvisionStepStatus is the progress of one provisioning step.
+type ProvisionStepStatus struct {
+	Name    string
+	State   ProvisionState
+	Detail  string // Skip reason, copy mode used, or error
+	Elapsed time.Duration
+}
+
+// ProvisionProgressMsg reports a provisioning step changing state.
+type ProvisionProgressMsg struct {
+	Epoch    uint64 // Epoch when request was issued (for stale detection)
+	Index    int
+	Status   ProvisionStepStatus
+	progress <-chan ProvisionProgressMsg
+}
+
+// GetEpoch implements plugin.EpochMessage.
+func (m ProvisionProgressMsg) GetEpoch() uint64 { return m.Epoch }
+
+// provisionReporter receives step progress. It is called from step goroutines.
+type provisionReporter func(index int, status ProvisionStepStatus)
+
+// errReflinkUnsupported means the platform has no copy-on-write cp.
+var errReflinkUnsupported = errors.New("reflink copies are not supported on " + runtime.GOOS)
+
+// listenForProvisionProgress waits for the next step update. The channel is
+// closed when creation finishes.
+func listenForProvisionProgress(progress <-chan ProvisionProgressMsg) tea.Cmd {
+	return func() tea.Msg {
+		msg, ok := <-progress
+		if !ok {
+			return nil
+		}
+		return msg
+	}
+}
+
+// provisionSteps returns the configured setup steps, or those of the
+// selected preset's setup profile when it exists, followed by the .worktree-setup.sh script
+// when the main worktree has one.
+func (p *Plugin) provisionSteps() []config.ProvisionStep {
+	var steps []config.ProvisionStep
+	if p.ctx.Config != nil {
+		var profile []config.ProvisionStep
+		if preset := p.selectedPreset(); preset != nil && preset.SetupProfile != "" {
+			profile = p.setupProfile(preset.SetupProfile)
+		}
+		if profile != nil {
+			steps = append(steps, profile...)
+		} else {
+			steps = append(steps, p.ctx.Config.Plugins.Workspace.Setup.Steps...)
+		}
+	}
+	if DefaultSetupConfig().RunSetupScript {
+		scriptPath := filepath.Join(p.ctx.WorkDir, setupScriptName)
+		if _, err := os.Stat(scriptPath); err == nil {
+			steps = append(steps, config.ProvisionStep{Name: setupScriptName, Run: "bash " + shellQuote(scriptPath)})
+		}
+	}
+	return steps
+}
+
+// pendingProvisionStatuses lists steps as not yet started.
+func pendingProvisionStatuses(steps []config.ProvisionStep) []ProvisionStepStatus {
+	statuses := make([]ProvisionStepStatus, len(steps))
+	for i, s := range steps {
+		statuses[i] = ProvisionStepStatus{Name: provisionStepName(s), State: ProvisionPending}
+	}
+	return statuses
+}
+
+// provisionFailures counts failed steps.
+func provisionFailures(statuses []ProvisionStepStatus) int {
+	n := 0
+	for _, s := range statuses {
+		if s.State == ProvisionFailed {
+			n++
+		}
+	}
+	return n
+}
+
+// provisionStepName labels a step in the create modal.
+func provisionStepName(s config.ProvisionStep) string {
+	switch {
+	case s.Name != "":
+		return s.Name
+	case len(s.Copy) > 0:
+		return "copy " + strings.Join(s.Copy, ", ")
+	default:
+		return s.Run
+	}
+}
+
+// runProvisionSteps runs steps in order. Consecutive parallel steps run at the
+// same time. A failed step doesn't stop later ones.
+func (p *Plugin) runProvisionSteps(worktreePath, branchName string, steps []config.ProvisionStep, report provisionReporter) error {
+	if report == nil {
+		report = func(int, ProvisionStepStatus) {}
+	}
+
+	var (
+		mu   sync.Mutex
+		errs []error
+	)
+	for start := 0; start < len(steps); {
+		end := start + 1
+		if steps[start].Parallel {
+			for end < len(steps) && steps[end].Parallel {
+				end++
+			}
+		}
+
+		var wg sync.WaitGroup
+		for i := start; i < end; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				if err := p.runProvisionStep(i, steps[i], worktreePath, branchName, report); err != nil {
+					mu.Lock()
+					errs = append(errs, fmt.Errorf("%s: %w", provisionStepName(steps[i]), err))
+					mu.Unlock()
+				}
+			}(i)
+		}
+		wg.Wait()
+		start = end
+	}
+	return errors.Join(errs...)
+}
+
+// runProvisionStep copies and then runs what a step declares, skipping each
+// part when its lockfile conditions say the work isn't needed.
+func (p *Plugin) runProvisionStep(index int, step config.ProvisionStep, worktreePath, branchName string, report provisionReporter) error {
+	name := provisionStepName(step)
+	started := time.Now()
+	report(index, ProvisionStepStatus{Name: name, State: ProvisionRunning})
+
+	finish := func(state ProvisionState, detail string) {
+		report(index, ProvisionStepStatus{Name: name, State: state, Detail: detail, Elapsed: time.Since(started)})
+	}
+
+	mainDir := p.mainWorktreeDir()
+	same, err := lockfilesMatch(mainDir, worktreePath, step.Lockfiles)
+	if err != nil {
+		finish(ProvisionFailed, err.Error())
+		return err
+	}
+
+	ran := false
+	var details []string
+	if len(step.Copy) > 0 {
+		if !same {
+			details = append(details, "lockfiles differ, not copying")
+		} else {
+			mode, copied, err := copyProvisionPaths(mainDir, worktreePath, step.Copy, step.CopyMode)
+			if err != nil {
+				finish(ProvisionFailed, err.Error())
+				return err
+			}
+			if copied > 0 {
+				ran = true
+				details = append(details, mode)
+			} else {
+				details = append(details, "nothing to copy")
+			}
+		}
+	}
+
+	if step.Run != "" {
+		if len(step.Creates) > 0 && same && pathsExist(worktreePath, step.Creates) {
+			details = append(details, "up to date")
+		} else {
+			if err := p.runProvisionCommand(step.Run, worktreePath, branchName); err != nil {
+				finish(ProvisionFailed, err.Error())
+				return err
+			}
+			ran = true
+		}
+	}
+
+	if ran {
+		finish(ProvisionDone, strings.Join(details, ", "))
+	} else {
+		finish(ProvisionSkipped, strings.Join(details, ", "))
+	}
+	return nil
+}
+
+// mainWorktreeDir returns the main worktree that copies and lockfiles come from.
+func (p *Plugin) mainWorktreeDir() string {
+	if p.ctx.ProjectRoot != "" {
+		return p.ctx.ProjectRoot
+	}
+	return p.ctx.WorkDir
+}
+
+// lockfilesMatch reports whether each lockfile has the same content in both
+// worktrees. A lockfile missing from both counts as matching.
+func lockfilesMatch(mainDir, worktreePath string, lockfiles []string) (bool, error) {
+	for _, lf := range lockfiles {
+		a, err := hashFile(filepath.Join(mainDir, lf))
+		if err != nil {
+			return false, err
+		}
+		b, err := hashFile(filepath.Join(worktreePath, lf))
+		if err != nil {
+			return false, err
+		}
+		if !bytes.Equal(a, b) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// hashFile returns the SHA-256 of a file, or nil if it doesn't exist.
+func hashFile(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
+
+// pathsExist reports whether every path exists under dir.
+func pathsExist(dir string, paths []string) bool {
+	for _, path := range paths {
+		if _, err := os.Lstat(filepath.Join(dir, path)); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// copyProvisionPaths copies paths from the main worktree into the new one.
+// Paths missing from the main worktree, or already present in the new one,
+// are left alone. Returns the copy mode used and how many paths were copied.
+func copyProvisionPaths(mainDir, worktreePath string, paths []string, mode string) (string, int, error) {
+	if mode == "" {
+		mode = copyModeAuto
+	}
+	used := mode
+	copied := 0
+	for _, path := range paths {
+		if filepath.IsAbs(path) || strings.HasPrefix(filepath.Clean(path), "..") {
+			return used, copied, fmt.Errorf("copy path %q must be inside the worktree", path)
+		}
+		src := filepath.Join(mainDir, path)
+		dst := filepath.Join(worktreePath, path)
+		if _, err := os.Lstat(src); os.IsNotExist(err) {
+			continue
+		}
+		if _, err := os.Lstat(dst); err == nil {
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+			return used, copied, err
+		}
+
+		var err error
+		switch mode {
+		case copyModeAuto:
+			if err = reflinkCopy(src, dst); err != nil {
+				// Filesystem can't share blocks; fall back to a full copy
+				_ = os.RemoveAll(dst)
+				used = copyModeCopy
+				err = copyTree(src, dst, false)
+			} else {
+				used = copyModeReflink
+			}
+		case copyModeReflink:
+			err = reflinkCopy(src, dst)
+		case copyModeHardlink:
+			err = copyTree(src, dst, true)
+		case copyModeCopy:
+			err = copyTree(src, dst, false)
+		default:
+			err = fmt.Errorf("unknown copy mode %q", mode)
+		}
+		if err != nil {
+			return used, copied, err
+		}
+		copied++
+	}
+	return used, copied, nil
+}
+
+// reflinkCopy copies src to dst sharing data blocks (copy-on-write), which
+// is near-instant on btrfs, XFS and APFS.
+func reflinkCopy(src, dst string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "linux":
+		cmd = exec.Command("cp", "-a", "--reflink=always", src, dst)
+	case "darwin":
+		cmd = exec.Command("cp", "-c", "-R", "-p", src, dst)
+	default:
+		return errReflinkUnsupported
+	}
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("cp: %s: %w", strings.TrimSpace(string(output)), err)
+	}
+	return nil
+}
+
+// copyTree recreates src at dst. Regular files are hard-linked when link is
+// true and copied otherwise; symlinks are recreated as-is.
+func copyTree(src, dst string, link bool) error {
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+
+		switch {
+		case d.IsDir():
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			return os.MkdirAll(target, info.Mode().Perm())
+		case d.Type()&fs.ModeSymlink != 0:
+			dest, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(dest, target)
+		case d.Type().IsRegular():
+			if link {
+				return os.Link(path, target)
+			}
+			return copyFile(path, target)
+		default:
+			// Sockets, devices and pipes have no place in a dependency cache
+			return nil
+		}
+	})
+}
+
+// runProvisionCommand runs a step command via sh in the new worktree with the
+// same environment as .worktree-setup.sh.
+func (p *Plugin) runProvisionCommand(command, worktreePath, branchName string) error {
+	cmd := exec.Command("sh", "-c", command)
+	cmd.Dir = worktreePath
+
+	// Build isolated environment with overrides applied
+	isolatedEnv := ApplyEnvOverrides(os.Environ(), BuildWorktreeEnvOverrides(p.ctx.WorkDir, p.ctx.ProjectRoot, worktreePath, branchName))
+
+	// Add worktree-specific variables
+	cmd.Env = append(isolatedEnv,
+		"MAIN_WORKTREE="+p.ctx.WorkDir,
+		"WORKTREE_BRANCH="+branchName,
+		"WORKTREE_PATH="+worktreePath,
+	)
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		p.ctx.Logger.Warn("setup step failed",
+			"command", command,
+			"output", string(output),
+			"error", err)
+		if last := lastOutputLine(output); last != "" {
+			return fmt.Errorf("%w: %s", err, last)
+		}
+		return err
+	}
+
+	p.ctx.Logger.Debug("setup step completed",
+		"command", command,
+		"output", string(output))
+	return nil
+}
+
+// lastOutputLine returns the last non-blank line of command output, which is
+// usually the most useful part of an error.
+func lastOutputLine(output []byte) string {
+	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
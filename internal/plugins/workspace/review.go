@@ -0,0 +1,446 @@
+package workspace
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	appmsg "github.com/marcus/sidecar/internal/msg"
+	"github.com/marcus/sidecar/internal/plugin"
+	"github.com/marcus/sidecar/internal/plugins/gitstatus"
+	"github.com/marcus/sidecar/internal/projectdir"
+)
+
+const sidecarReviewFile = "review.json"
+
+// Sides of the diff a review comment can anchor to.
+const (
+	reviewSideNew = "new" // Added or context line, numbered in the current file
+	reviewSideOld = "old" // Removed line, numbered in the base file
+)
+
+// ReviewComment is a comment on a diff line, sent to the agent as part of a
+// review. Persisted per worktree in the centralized worktree data directory.
+type ReviewComment struct {
+	ID        int       `json:"id"`
+	File      string    `json:"file"`
+	Line      int       `json:"line"`
+	Side      string    `json:"side"`
+	Excerpt   []string  `json:"excerpt"` // Commented line with up to one line of context either side
+	Anchor    int       `json:"anchor"`  // Index of the commented line in Excerpt
+	Body      string    `json:"body"`
+	CreatedAt time.Time `json:"createdAt"`
+	Sent      bool      `json:"sent,omitempty"`
+	Resolved  bool      `json:"resolved,omitempty"` // The commented lines have changed
+}
+
+// code returns the commented line.
+func (c *ReviewComment) code() string {
+	if c.Anchor < 0 || c.Anchor >= len(c.Excerpt) {
+		return ""
+	}
+	return c.Excerpt[c.Anchor]
+}
+
+// location returns "file:line" for display and prompts.
+func (c *ReviewComment) location() string {
+	return fmt.Sprintf("%s:%d", c.File, c.Line)
+}
+
+// ReviewCommentsCheckedMsg delivers comment positions and resolution after the
+// worktree's files were compared with each comment's excerpt.
+type ReviewCommentsCheckedMsg struct {
+	Epoch         uint64 // Epoch when request was issued (for stale detection)
+	WorkspaceName string
+	Updates       map[int]ReviewComment // Changed comments by ID
+}
+
+// GetEpoch implements plugin.EpochMessage.
+func (m ReviewCommentsCheckedMsg) GetEpoch() uint64 { return m.Epoch }
+
+// ReviewSentMsg reports delivery of a review to a worktree's agent.
+type ReviewSentMsg struct {
+	Epoch         uint64 // Epoch when request was issued (for stale detection)
+	WorkspaceName string
+	IDs           []int // Comments included in the review
+	Queued        bool  // Added to the prompt queue because the agent was busy
+	Err           error
+}
+
+// GetEpoch implements plugin.EpochMessage.
+func (m ReviewSentMsg) GetEpoch() uint64 { return m.Epoch }
+
+// saveReviewComments writes a worktree's comments, removing the file when
+// there are none.
+func saveReviewComments(projectRoot, worktreePath string, comments []*ReviewComment) error {
+	wtDir, err := projectdir.WorktreeDir(projectRoot, worktreePath)
+	if err != nil {
+		return fmt.Errorf("resolve worktree dir: %w", err)
+	}
+	reviewPath := filepath.Join(wtDir, sidecarReviewFile)
+	if len(comments) == 0 {
+		_ = os.Remove(reviewPath)
+		return nil
+	}
+	data, err := json.MarshalIndent(comments, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(reviewPath, data, 0644)
+}
+
+// loadReviewComments reads a worktree's comments.
+func loadReviewComments(projectRoot, worktreePath string) []*ReviewComment {
+	wtDir, err := projectdir.WorktreeDir(projectRoot, worktreePath)
+	if err != nil {
+		return nil
+	}
+	data, err := os.ReadFile(filepath.Join(wtDir, sidecarReviewFile))
+	if err != nil {
+		return nil
+	}
+	var comments []*ReviewComment
+	if err := json.Unmarshal(data, &comments); err != nil {
+		return nil
+	}
+	return comments
+}
+
+// reviewComments returns a worktree's comments, loading them on first use.
+func (p *Plugin) reviewComments(wt *Worktree) []*ReviewComment {
+	if wt == nil {
+		return nil
+	}
+	if comments, ok := p.reviews[wt.Name]; ok {
+		return comments
+	}
+	if p.reviews == nil {
+		p.reviews = make(map[string][]*ReviewComment)
+	}
+	comments := loadReviewComments(p.ctx.ProjectRoot, wt.Path)
+	p.reviews[wt.Name] = comments
+	return comments
+}
+
+// setReviewComments replaces and persists a worktree's comments.
+func (p *Plugin) setReviewComments(wt *Worktree, comments []*ReviewComment) {
+	if p.reviews == nil {
+		p.reviews = make(map[string][]*ReviewComment)
+	}
+	p.reviews[wt.Name] = comments
+	if err := saveReviewComments(p.ctx.ProjectRoot, wt.Path, comments); err != nil {
+		p.ctx.Logger.Warn("failed to save review comments", "path", wt.Path, "error", err)
+	}
+}
+
+// addReviewComment appends a comment to a worktree's review.
+func (p *Plugin) addReviewComment(wt *Worktree, c ReviewComment) {
+	comments := p.reviewComments(wt)
+	for _, existing := range comments {
+		if existing.ID >= c.ID {
+			c.ID = existing.ID + 1
+		}
+	}
+	if c.ID == 0 {
+		c.ID = 1
+	}
+	c.CreatedAt = time.Now()
+	p.setReviewComments(wt, append(comments, &c))
+}
+
+// removeReviewComment deletes a comment by ID.
+func (p *Plugin) removeReviewComment(wt *Worktree, id int) {
+	comments := p.reviewComments(wt)
+	kept := make([]*ReviewComment, 0, len(comments))
+	for _, c := range comments {
+		if c.ID != id {
+			kept = append(kept, c)
+		}
+	}
+	p.setReviewComments(wt, kept)
+}
+
+// openReviewComments counts comments that are neither resolved nor sent.
+func openReviewComments(comments []*ReviewComment) int {
+	n := 0
+	for _, c := range comments {
+		if !c.Resolved && !c.Sent {
+			n++
+		}
+	}
+	return n
+}
+
+// reviewCommentAt builds a comment anchored to a line of a file's diff, with
+// its excerpt taken from the same side of the hunk.
+func reviewCommentAt(file string, hunk gitstatus.Hunk, lineIdx int) (ReviewComment, bool) {
+	if lineIdx < 0 || lineIdx >= len(hunk.Lines) {
+		return ReviewComment{}, false
+	}
+	line := hunk.Lines[lineIdx]
+	c := ReviewComment{File: file, Side: reviewSideNew, Line: line.NewLineNo}
+	if line.Type == gitstatus.LineRemove {
+		c.Side, c.Line = reviewSideOld, line.OldLineNo
+	}
+	onSide := func(l gitstatus.DiffLine) bool {
+		if c.Side == reviewSideOld {
+			return l.Type != gitstatus.LineAdd
+		}
+		return l.Type != gitstatus.LineRemove
+	}
+
+	// One line of context either side, skipping lines from the other side
+	if prev := lineIdx - 1; prev >= 0 {
+		for prev >= 0 && !onSide(hunk.Lines[prev]) {
+			prev--
+		}
+		if prev >= 0 {
+			c.Excerpt = append(c.Excerpt, hunk.Lines[prev].Content)
+			c.Anchor = 1
+		}
+	}
+	c.Excerpt = append(c.Excerpt, line.Content)
+	for next := lineIdx + 1; next < len(hunk.Lines); next++ {
+		if onSide(hunk.Lines[next]) {
+			c.Excerpt = append(c.Excerpt, hunk.Lines[next].Content)
+			break
+		}
+	}
+	return c, true
+}
+
+// reviewCommentOnLine reports whether c is anchored to a diff line of its file.
+func reviewCommentOnLine(c *ReviewComment, line gitstatus.DiffLine) bool {
+	if c.Side == reviewSideOld {
+		return line.Type == gitstatus.LineRemove && line.OldLineNo == c.Line
+	}
+	return line.Type != gitstatus.LineRemove && line.NewLineNo == c.Line
+}
+
+// relocateReviewComment finds a new-side comment's excerpt in the current file
+// contents. It follows the excerpt when lines above it were added or removed
+// and marks the comment resolved when the excerpt is gone. Returns whether
+// the comment changed.
+func relocateReviewComment(c *ReviewComment, fileLines []string) bool {
+	if c.Resolved || len(c.Excerpt) == 0 {
+		return false
+	}
+	wantStart := c.Line - 1 - c.Anchor
+	best := -1
+	for start := 0; start+len(c.Excerpt) <= len(fileLines); start++ {
+		match := true
+		for i, want := range c.Excerpt {
+			if fileLines[start+i] != want {
+				match = false
+				break
+			}
+		}
+		if match && (best < 0 || absInt(start-wantStart) < absInt(best-wantStart)) {
+			best = start
+		}
+	}
+	if best < 0 {
+		c.Resolved = true
+		return true
+	}
+	if line := best + c.Anchor + 1; line != c.Line {
+		c.Line = line
+		return true
+	}
+	return false
+}
+
+// relocateRemovedLineComment keeps an old-side comment while its removed line
+// is still part of the file's diff, and marks it resolved otherwise.
+func relocateRemovedLineComment(c *ReviewComment, diff *gitstatus.ParsedDiff) bool {
+	if c.Resolved {
+		return false
+	}
+	best := -1
+	if diff != nil {
+		for _, hunk := range diff.Hunks {
+			for _, line := range hunk.Lines {
+				if line.Type == gitstatus.LineRemove && line.Content == c.code() &&
+					(best < 0 || absInt(line.OldLineNo-c.Line) < absInt(best-c.Line)) {
+					best = line.OldLineNo
+				}
+			}
+		}
+	}
+	if best < 0 {
+		c.Resolved = true
+		return true
+	}
+	if best != c.Line {
+		c.Line = best
+		return true
+	}
+	return false
+}
+
+func absInt(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// checkReviewComments compares a worktree's open comments with its files and
+// current diff, following moved lines and resolving changed ones.
+func (p *Plugin) checkReviewComments(wt *Worktree, raw string) tea.Cmd {
+	var pending []ReviewComment
+	for _, c := range p.reviewComments(wt) {
+		if !c.Resolved {
+			pending = append(pending, *c)
+		}
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+
+	epoch := p.ctx.Epoch
+	name, path := wt.Name, wt.Path
+	return func() tea.Msg {
+		diffs := make(map[string]*gitstatus.ParsedDiff)
+		for _, f := range gitstatus.ParseMultiFileDiff(raw).Files {
+			diffs[f.FileName()] = f.Diff
+		}
+
+		files := make(map[string][]string)
+		updates := make(map[int]ReviewComment)
+		for _, c := range pending {
+			var changed bool
+			if c.Side == reviewSideOld {
+				changed = relocateRemovedLineComment(&c, diffs[c.File])
+			} else {
+				lines, ok := files[c.File]
+				if !ok {
+					if data, err := os.ReadFile(filepath.Join(path, c.File)); err == nil {
+						lines = splitLines(string(data))
+					}
+					files[c.File] = lines
+				}
+				changed = relocateReviewComment(&c, lines)
+			}
+			if changed {
+				updates[c.ID] = c
+			}
+		}
+		return ReviewCommentsCheckedMsg{Epoch: epoch, WorkspaceName: name, Updates: updates}
+	}
+}
+
+// handleReviewCommentsChecked applies relocated and resolved comments.
+func (p *Plugin) handleReviewCommentsChecked(msg ReviewCommentsCheckedMsg) {
+	if plugin.IsStale(p.ctx, msg) || len(msg.Updates) == 0 {
+		return
+	}
+	wt := p.findWorktree(msg.WorkspaceName)
+	if wt == nil {
+		return
+	}
+	comments := p.reviewComments(wt)
+	for _, c := range comments {
+		if u, ok := msg.Updates[c.ID]; ok {
+			c.Line, c.Resolved = u.Line, u.Resolved
+		}
+	}
+	p.setReviewComments(wt, comments)
+}
+
+// composeReview formats comments as a prompt for the agent.
+func composeReview(comments []*ReviewComment) string {
+	var sb strings.Builder
+	sb.WriteString("Please address these review comments on your changes:\n")
+	for i, c := range comments {
+		fmt.Fprintf(&sb, "\n%d. %s", i+1, c.location())
+		if c.Side == reviewSideOld {
+			sb.WriteString(" (removed line)")
+		}
+		sb.WriteString("\n```\n")
+		for j, line := range c.Excerpt {
+			marker := "  "
+			if j == c.Anchor {
+				marker = "> "
+			}
+			sb.WriteString(marker + line + "\n")
+		}
+		sb.WriteString("```\n")
+		sb.WriteString(strings.TrimSpace(c.Body) + "\n")
+	}
+	return sb.String()
+}
+
+// sendReview sends the worktree's open comments to its agent, or queues them
+// unless the agent is idle at its input prompt. A waiting agent may have a
+// permission dialog open, which typed text would answer.
+func (p *Plugin) sendReview(wt *Worktree) tea.Cmd {
+	if wt == nil {
+		return nil
+	}
+	var open []*ReviewComment
+	var ids []int
+	for _, c := range p.reviewComments(wt) {
+		if !c.Resolved && !c.Sent {
+			open = append(open, c)
+			ids = append(ids, c.ID)
+		}
+	}
+	if len(open) == 0 {
+		return appmsg.ShowToast("No unsent review comments", 2*time.Second)
+	}
+	if wt.Agent == nil {
+		return appmsg.ShowToast("No agent running in "+wt.Name, 2*time.Second)
+	}
+
+	review := composeReview(open)
+	epoch := p.ctx.Epoch
+	if !atInputPrompt(wt.Agent.Type, wt.Status) {
+		queued := p.enqueuePrompt(wt.Name, review)
+		sent := func() tea.Msg {
+			return ReviewSentMsg{Epoch: epoch, WorkspaceName: wt.Name, IDs: ids, Queued: true}
+		}
+		return tea.Batch(queued, sent)
+	}
+
+	send := p.SendText(wt, review)
+	return func() tea.Msg {
+		result, _ := send().(SendTextResultMsg)
+		return ReviewSentMsg{Epoch: epoch, WorkspaceName: wt.Name, IDs: ids, Err: result.Err}
+	}
+}
+
+// handleReviewSent marks delivered comments as sent.
+func (p *Plugin) handleReviewSent(msg ReviewSentMsg) tea.Cmd {
+	if plugin.IsStale(p.ctx, msg) {
+		return nil
+	}
+	wt := p.findWorktree(msg.WorkspaceName)
+	if wt == nil {
+		return nil
+	}
+	if msg.Err != nil {
+		return appmsg.ShowToast(fmt.Sprintf("Review not sent to %s: %v", wt.Name, msg.Err), 3*time.Second)
+	}
+
+	sent := make(map[int]bool, len(msg.IDs))
+	for _, id := range msg.IDs {
+		sent[id] = true
+	}
+	comments := p.reviewComments(wt)
+	for _, c := range comments {
+		if sent[c.ID] {
+			c.Sent = true
+		}
+	}
+	p.setReviewComments(wt, comments)
+
+	if msg.Queued {
+		return appmsg.ShowToast(fmt.Sprintf("Review queued for %s (%d comments)", wt.Name, len(msg.IDs)), 2*time.Second)
+	}
+	return appmsg.ShowToast(fmt.Sprintf("Sent review to %s (%d comments)", wt.Name, len(msg.IDs)), 2*time.Second)
+}
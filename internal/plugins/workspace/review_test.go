@@ -0,0 +1,193 @@
+package workspace
+
+import (
+	"log/slog"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/marcus/sidecar/internal/config"
+	"github.com/marcus/sidecar/internal/plugin"
+	"github.com/marcus/sidecar/internal/plugins/gitstatus"
+)
+
+const reviewTestDiff = `diff --git a/main.go b/main.go
+--- a/main.go
++++ b/main.go
+@@ -1,4 +1,4 @@
+ package main
+-func old() {}
++func added() {}
+ func kept() {}
+@@ -10,2 +10,3 @@
+ a
++b
+ c
+`
+
+func reviewTestParsed(t *testing.T) *gitstatus.ParsedDiff {
+	t.Helper()
+	parsed, err := gitstatus.ParseUnifiedDiff(reviewTestDiff)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return parsed
+}
+
+func TestReviewCommentAt(t *testing.T) {
+	parsed := reviewTestParsed(t)
+	hunk := parsed.Hunks[0]
+
+	added, ok := reviewCommentAt("main.go", hunk, 2)
+	if !ok {
+		t.Fatal("expected a comment on the added line")
+	}
+	// The removed line belongs to the other side and is skipped
+	if added.Side != reviewSideNew || added.Line != 2 || added.code() != "func added() {}" ||
+		!reflect.DeepEqual(added.Excerpt, []string{"package main", "func added() {}", "func kept() {}"}) {
+		t.Errorf("added-line comment = %+v", added)
+	}
+
+	removed, _ := reviewCommentAt("main.go", hunk, 1)
+	if removed.Side != reviewSideOld || removed.Line != 2 ||
+		!reflect.DeepEqual(removed.Excerpt, []string{"package main", "func old() {}", "func kept() {}"}) {
+		t.Errorf("removed-line comment = %+v", removed)
+	}
+
+	if _, ok := reviewCommentAt("main.go", hunk, 10); ok {
+		t.Error("expected no comment past the hunk")
+	}
+}
+
+func TestRelocateReviewComment(t *testing.T) {
+	newComment := func() *ReviewComment {
+		return &ReviewComment{Line: 2, Excerpt: []string{"package main", "func added() {}", "func kept() {}"}, Anchor: 1}
+	}
+
+	c := newComment()
+	if relocateReviewComment(c, []string{"package main", "func added() {}", "func kept() {}"}) || c.Line != 2 || c.Resolved {
+		t.Errorf("unchanged file: %+v", c)
+	}
+
+	c = newComment()
+	moved := []string{"// header", "", "package main", "func added() {}", "func kept() {}"}
+	if !relocateReviewComment(c, moved) || c.Line != 4 || c.Resolved {
+		t.Errorf("lines inserted above: %+v, want line 4", c)
+	}
+
+	c = newComment()
+	if !relocateReviewComment(c, []string{"package main", "func added(x int) {}", "func kept() {}"}) || !c.Resolved {
+		t.Errorf("changed line: %+v, want resolved", c)
+	}
+}
+
+func TestRelocateRemovedLineComment(t *testing.T) {
+	parsed := reviewTestParsed(t)
+	c := &ReviewComment{Side: reviewSideOld, Line: 2, Excerpt: []string{"func old() {}"}}
+	if relocateRemovedLineComment(c, parsed) || c.Resolved {
+		t.Errorf("removed line still in diff: %+v", c)
+	}
+	if !relocateRemovedLineComment(c, nil) || !c.Resolved {
+		t.Errorf("file no longer in diff: %+v, want resolved", c)
+	}
+}
+
+func TestUnifiedDiffRowsMatchRenderer(t *testing.T) {
+	parsed := reviewTestParsed(t)
+	for scroll := 0; scroll < 8; scroll++ {
+		rendered := gitstatus.RenderLineDiff(parsed, 80, scroll, 20, 0, nil, false)
+		rows := unifiedDiffRows(parsed, scroll, 20)
+		lines := strings.Split(strings.TrimSuffix(rendered, "\n"), "\n")
+		if len(lines) != len(rows) {
+			t.Fatalf("scroll %d: %d rendered rows, %d mapped", scroll, len(lines), len(rows))
+		}
+		for i, r := range rows {
+			if r.line < 0 {
+				continue
+			}
+			if content := parsed.Hunks[r.hunk].Lines[r.line].Content; !strings.Contains(lines[i], content) {
+				t.Errorf("scroll %d row %d: %q does not show %q", scroll, i, lines[i], content)
+			}
+		}
+	}
+}
+
+func TestDiffScrollForLine(t *testing.T) {
+	parsed := reviewTestParsed(t)
+	for h, hunk := range parsed.Hunks {
+		for l := range hunk.Lines {
+			rows := unifiedDiffRows(parsed, diffScrollForLine(parsed, h, l), 3)
+			if anchor, ok := reviewAnchorRow(rows); !ok || anchor != (diffRow{hunk: h, line: l}) {
+				t.Errorf("hunk %d line %d: anchor = %+v", h, l, anchor)
+			}
+		}
+	}
+}
+
+func TestComposeReview(t *testing.T) {
+	got := composeReview([]*ReviewComment{
+		{File: "main.go", Line: 2, Excerpt: []string{"package main", "func added() {}"}, Anchor: 1, Body: "Name this better\n"},
+		{File: "main.go", Line: 2, Side: reviewSideOld, Excerpt: []string{"func old() {}"}, Body: "Keep this"},
+	})
+	for _, want := range []string{
+		"1. main.go:2\n```\n  package main\n> func added() {}\n```\nName this better\n",
+		"2. main.go:2 (removed line)\n```\n> func old() {}\n```\nKeep this\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("review missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestReviewCommentsPersist(t *testing.T) {
+	config.SetTestStateDir(t.TempDir())
+	projectRoot := t.TempDir()
+	wt := &Worktree{Name: "feature", Path: t.TempDir()}
+	p := &Plugin{ctx: &plugin.Context{ProjectRoot: projectRoot, Logger: slog.Default()}}
+
+	p.addReviewComment(wt, ReviewComment{File: "a.go", Line: 1, Body: "one"})
+	p.addReviewComment(wt, ReviewComment{File: "a.go", Line: 5, Body: "two"})
+
+	loaded := loadReviewComments(projectRoot, wt.Path)
+	if len(loaded) != 2 || loaded[0].ID != 1 || loaded[1].ID != 2 || loaded[1].Body != "two" {
+		t.Fatalf("loaded = %+v", loaded)
+	}
+
+	p.handleReviewSent(ReviewSentMsg{WorkspaceName: "feature", IDs: []int{1}})
+	if p.findWorktree("feature") != nil {
+		t.Fatal("worktree should not be listed")
+	}
+
+	p.worktrees = []*Worktree{wt}
+	p.handleReviewSent(ReviewSentMsg{WorkspaceName: "feature", IDs: []int{1}})
+	if got := openReviewComments(loadReviewComments(projectRoot, wt.Path)); got != 1 {
+		t.Errorf("open comments after sending one = %d, want 1", got)
+	}
+
+	p.removeReviewComment(wt, 1)
+	p.removeReviewComment(wt, 2)
+	if got := loadReviewComments(projectRoot, wt.Path); got != nil {
+		t.Errorf("comments after removing all = %+v", got)
+	}
+}
+
+func TestSendReviewQueuesWhileWaiting(t *testing.T) {
+	config.SetTestStateDir(t.TempDir())
+	wt := &Worktree{
+		Name:   "feature",
+		Path:   t.TempDir(),
+		Agent:  &Agent{Type: AgentClaude, TmuxSession: "sidecar-ws-feature"},
+		Status: StatusWaiting,
+	}
+	p := &Plugin{ctx: &plugin.Context{ProjectRoot: t.TempDir(), Logger: slog.Default()}}
+	p.worktrees = []*Worktree{wt}
+	p.addReviewComment(wt, ReviewComment{File: "a.go", Line: 1, Body: "rename this"})
+
+	// Claude is waiting on a permission dialog: the review must not be typed into it
+	if cmd := p.sendReview(wt); cmd == nil {
+		t.Fatal("sendReview returned nil")
+	}
+	if wt.Queue.Len() != 1 || !strings.Contains(wt.Queue.Items[0], "rename this") {
+		t.Errorf("review should be queued, queue = %+v", wt.Queue)
+	}
+}
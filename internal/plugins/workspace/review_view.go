@@ -0,0 +1,497 @@
+package workspace
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textarea"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/marcus/sidecar/internal/modal"
+	appmsg "github.com/marcus/sidecar/internal/msg"
+	"github.com/marcus/sidecar/internal/plugins/gitstatus"
+	"github.com/marcus/sidecar/internal/styles"
+	"github.com/marcus/sidecar/internal/ui"
+)
+
+const (
+	reviewCommentInputID = "review-comment-input"
+	reviewListID         = "review-list"
+	reviewMaxRows        = 10
+	reviewGutterWidth    = 2
+)
+
+// diffRow is a rendered row of a unified diff: a line of a hunk, or a hunk
+// header or separator (line -1).
+type diffRow struct {
+	hunk, line int
+}
+
+// unifiedDiffRows lays out the rows gitstatus.RenderLineDiff draws for the
+// same scroll position, so markers can be placed beside them.
+func unifiedDiffRows(diff *gitstatus.ParsedDiff, startLine, maxLines int) []diffRow {
+	if diff == nil || diff.Binary {
+		return nil
+	}
+	var rows []diffRow
+	lineNum := 0
+	isFirstHunk := true
+	for h, hunk := range diff.Hunks {
+		if lineNum < startLine {
+			// Headers only count toward the scroll offset while skipped
+			lineNum++
+		} else {
+			if !isFirstHunk && len(rows) < maxLines {
+				rows = append(rows, diffRow{hunk: h, line: -1})
+			}
+			rows = append(rows, diffRow{hunk: h, line: -1})
+			isFirstHunk = false
+		}
+		if len(rows) >= maxLines {
+			break
+		}
+		for i := range hunk.Lines {
+			lineNum++
+			if lineNum <= startLine {
+				continue
+			}
+			if len(rows) >= maxLines {
+				break
+			}
+			rows = append(rows, diffRow{hunk: h, line: i})
+		}
+		if len(rows) >= maxLines {
+			break
+		}
+	}
+	return rows
+}
+
+// diffScrollForLine returns the scroll offset that puts a hunk line at the
+// top of the unified diff.
+func diffScrollForLine(diff *gitstatus.ParsedDiff, hunk, line int) int {
+	scroll := 0
+	for h := 0; h < hunk && h < len(diff.Hunks); h++ {
+		scroll += 1 + len(diff.Hunks[h].Lines)
+	}
+	return scroll + 1 + line
+}
+
+// reviewAnchorRow returns the first code row on screen, which new comments
+// attach to.
+func reviewAnchorRow(rows []diffRow) (diffRow, bool) {
+	for _, r := range rows {
+		if r.line >= 0 {
+			return r, true
+		}
+	}
+	return diffRow{}, false
+}
+
+// renderReviewGutter prefixes each row of a rendered unified diff with a
+// marker column: ● for open comments, ✓ for resolved ones, and ▸ for the line
+// a new comment would attach to when the pane is focused.
+func renderReviewGutter(rendered string, diff *gitstatus.ParsedDiff, rows []diffRow, file string, comments []*ReviewComment, focused bool) string {
+	anchor, hasAnchor := reviewAnchorRow(rows)
+	open := lipgloss.NewStyle().Foreground(styles.Warning).Render("●")
+	cursor := lipgloss.NewStyle().Foreground(styles.Primary).Render("▸")
+
+	lines := strings.Split(strings.TrimSuffix(rendered, "\n"), "\n")
+	for i := range lines {
+		marker := " "
+		if i < len(rows) && rows[i].line >= 0 {
+			dl := diff.Hunks[rows[i].hunk].Lines[rows[i].line]
+			for _, c := range comments {
+				if c.File != file || !reviewCommentOnLine(c, dl) {
+					continue
+				}
+				if c.Resolved {
+					if marker == " " {
+						marker = dimText("✓")
+					}
+				} else {
+					marker = open
+				}
+			}
+			if focused && hasAnchor && rows[i] == anchor && marker == " " {
+				marker = cursor
+			}
+		}
+		lines[i] = marker + " " + lines[i]
+	}
+	return strings.Join(lines, "\n")
+}
+
+// openReviewCommentModal starts a comment on the first code line shown in
+// the diff pane.
+func (p *Plugin) openReviewCommentModal() tea.Cmd {
+	wt := p.selectedWorktree()
+	parsed := p.parsedDiffForCurrentFile()
+	if wt == nil || parsed == nil {
+		return nil
+	}
+	if p.diffViewMode != DiffViewUnified {
+		return appmsg.ShowToast("Switch to the unified diff view (v) to comment on lines", 2*time.Second)
+	}
+	rows := unifiedDiffRows(parsed, p.diffTabDiffScroll, 2) // A hunk header may come first
+	anchor, ok := reviewAnchorRow(rows)
+	if !ok {
+		return nil
+	}
+	c, ok := reviewCommentAt(p.selectedDiffTabFile(), parsed.Hunks[anchor.hunk], anchor.line)
+	if !ok {
+		return nil
+	}
+
+	p.reviewDraft = &c
+	p.reviewInput = textarea.New()
+	p.reviewInput.Placeholder = "What should change here?"
+	p.reviewInput.FocusedStyle.Placeholder = lipgloss.NewStyle().Foreground(styles.TextSecondary)
+	p.reviewInput.CharLimit = 0
+	p.reviewInput.ShowLineNumbers = false
+	p.reviewInput.Focus()
+	p.reviewCommentModal = nil
+	p.reviewCommentModalWidth = 0
+	p.viewMode = ViewModeReviewComment
+	return nil
+}
+
+// clearReviewCommentModal closes the comment editor.
+func (p *Plugin) clearReviewCommentModal() {
+	p.reviewDraft = nil
+	p.reviewInput = textarea.Model{}
+	p.reviewCommentModal = nil
+	p.reviewCommentModalWidth = 0
+	p.viewMode = ViewModeList
+}
+
+// ensureReviewCommentModal builds or rebuilds the comment editor modal.
+func (p *Plugin) ensureReviewCommentModal() {
+	if p.reviewDraft == nil {
+		p.reviewCommentModal = nil
+		return
+	}
+	modalW := 80
+	maxW := p.width - 4
+	if maxW < 1 {
+		maxW = 1
+	}
+	if modalW > maxW {
+		modalW = maxW
+	}
+	p.reviewInput.SetWidth(modalW - 8)
+
+	if p.reviewCommentModal != nil && p.reviewCommentModalWidth == modalW {
+		return
+	}
+	p.reviewCommentModalWidth = modalW
+
+	p.reviewCommentModal = modal.New("Comment: "+p.reviewDraft.location(),
+		modal.WithWidth(modalW),
+		modal.WithHints(false),
+	).
+		AddSection(p.reviewExcerptSection(p.reviewDraft)).
+		AddSection(modal.Spacer()).
+		AddSection(modal.Textarea(reviewCommentInputID, &p.reviewInput, 4)).
+		AddSection(modal.Spacer()).
+		AddSection(modal.Text(dimText("enter save · ctrl+j newline · esc cancel")))
+}
+
+// reviewExcerptSection shows a comment's code excerpt with the commented
+// line highlighted.
+func (p *Plugin) reviewExcerptSection(c *ReviewComment) modal.Section {
+	return modal.Custom(func(contentWidth int, focusID, hoverID string) modal.RenderedSection {
+		lines := make([]string, 0, len(c.Excerpt))
+		for i, line := range c.Excerpt {
+			line = ui.ExpandTabs(line, tabStopWidth)
+			if i == c.Anchor {
+				lines = append(lines, lipgloss.NewStyle().Foreground(styles.Primary).Render(ansi.Truncate("> "+line, contentWidth, "…")))
+			} else {
+				lines = append(lines, dimText(ansi.Truncate("  "+line, contentWidth, "…")))
+			}
+		}
+		return modal.RenderedSection{Content: strings.Join(lines, "\n")}
+	}, nil)
+}
+
+// renderReviewCommentModal renders the comment editor over the list view.
+func (p *Plugin) renderReviewCommentModal(width, height int) string {
+	background := p.renderListView(width, height)
+
+	p.ensureReviewCommentModal()
+	if p.reviewCommentModal == nil {
+		return background
+	}
+
+	modalContent := p.reviewCommentModal.Render(width, height, p.mouseHandler)
+	return ui.OverlayModal(background, modalContent, width, height)
+}
+
+func (p *Plugin) handleReviewCommentKeys(msg tea.KeyMsg) tea.Cmd {
+	p.ensureReviewCommentModal()
+	wt := p.selectedWorktree()
+	if p.reviewCommentModal == nil || wt == nil {
+		p.clearReviewCommentModal()
+		return nil
+	}
+
+	switch msg.String() {
+	case "esc":
+		p.clearReviewCommentModal()
+		return nil
+	case "enter":
+		body := strings.TrimSpace(p.reviewInput.Value())
+		if body == "" {
+			return nil
+		}
+		c := *p.reviewDraft
+		c.Body = body
+		p.addReviewComment(wt, c)
+		p.clearReviewCommentModal()
+		p.activePane = PanePreview
+		return nil
+	case "ctrl+j":
+		p.reviewInput.InsertString("\n")
+		return nil
+	}
+
+	_, cmd := p.reviewCommentModal.HandleKey(msg)
+	return cmd
+}
+
+func (p *Plugin) handleReviewCommentMouse(msg tea.MouseMsg) tea.Cmd {
+	p.ensureReviewCommentModal()
+	if p.reviewCommentModal == nil {
+		p.clearReviewCommentModal()
+		return nil
+	}
+	if p.reviewCommentModal.HandleMouse(msg, p.mouseHandler) == "cancel" {
+		p.clearReviewCommentModal()
+	}
+	return nil
+}
+
+// openReviewModal lists the selected worktree's review comments.
+func (p *Plugin) openReviewModal() tea.Cmd {
+	wt := p.selectedWorktree()
+	if wt == nil || p.shellSelected {
+		return nil
+	}
+	if len(p.reviewComments(wt)) == 0 {
+		return appmsg.ShowToast("No review comments (c in the diff pane adds one)", 2*time.Second)
+	}
+	p.reviewIdx = 0
+	p.reviewModal = nil
+	p.reviewModalWidth = 0
+	p.viewMode = ViewModeReview
+	return nil
+}
+
+// closeReviewModal closes the review list and returns to the diff tab.
+func (p *Plugin) closeReviewModal() {
+	p.reviewIdx = 0
+	p.reviewModal = nil
+	p.reviewModalWidth = 0
+	p.viewMode = ViewModeList
+}
+
+// ensureReviewModal builds or rebuilds the review list modal.
+func (p *Plugin) ensureReviewModal() {
+	wt := p.selectedWorktree()
+	if wt == nil {
+		p.reviewModal = nil
+		return
+	}
+	modalW := 90
+	maxW := p.width - 4
+	if maxW < 1 {
+		maxW = 1
+	}
+	if modalW > maxW {
+		modalW = maxW
+	}
+	if p.reviewModal != nil && p.reviewModalWidth == modalW {
+		return
+	}
+	p.reviewModalWidth = modalW
+
+	p.reviewModal = modal.New("Review: "+wt.Name,
+		modal.WithWidth(modalW),
+		modal.WithHints(false),
+	).
+		AddSection(p.reviewListSection())
+}
+
+// reviewListSection renders the comments, the selected one's excerpt, and
+// the control hints.
+func (p *Plugin) reviewListSection() modal.Section {
+	return modal.Custom(func(contentWidth int, focusID, hoverID string) modal.RenderedSection {
+		comments := p.reviewComments(p.selectedWorktree())
+		lines := []string{fmt.Sprintf("Comments (%d open)", openReviewComments(comments))}
+
+		start := 0
+		if p.reviewIdx >= reviewMaxRows {
+			start = p.reviewIdx - reviewMaxRows + 1
+		}
+		for i := start; i < len(comments) && i < start+reviewMaxRows; i++ {
+			c := comments[i]
+			status := lipgloss.NewStyle().Foreground(styles.Warning).Render("●")
+			switch {
+			case c.Resolved:
+				status = dimText("✓")
+			case c.Sent:
+				status = lipgloss.NewStyle().Foreground(styles.Info).Render("→")
+			}
+			prefix := "  "
+			if i == p.reviewIdx {
+				prefix = "> "
+			}
+			body := strings.Join(strings.Fields(c.Body), " ")
+			row := ansi.Truncate(fmt.Sprintf("%s%s %s  %s", prefix, status, c.location(), body), contentWidth, "…")
+			if i == p.reviewIdx {
+				row = lipgloss.NewStyle().Foreground(styles.Primary).Render(row)
+			}
+			lines = append(lines, row)
+		}
+
+		if p.reviewIdx >= 0 && p.reviewIdx < len(comments) {
+			c := comments[p.reviewIdx]
+			lines = append(lines, "")
+			for i, line := range c.Excerpt {
+				line = ui.ExpandTabs(line, tabStopWidth)
+				if i == c.Anchor {
+					lines = append(lines, ansi.Truncate("> "+line, contentWidth, "…"))
+				} else {
+					lines = append(lines, dimText(ansi.Truncate("  "+line, contentWidth, "…")))
+				}
+			}
+			for _, bodyLine := range strings.Split(strings.TrimSpace(c.Body), "\n") {
+				lines = append(lines, ansi.Truncate(bodyLine, contentWidth, "…"))
+			}
+		}
+
+		lines = append(lines, "", dimText("● open · → sent · ✓ resolved (lines changed)"))
+		lines = append(lines, dimText("s send review · enter go to line · x delete · X clear resolved · esc close"))
+
+		return modal.RenderedSection{
+			Content: strings.Join(lines, "\n"),
+			Focusables: []modal.FocusableInfo{{
+				ID:     reviewListID,
+				Width:  contentWidth,
+				Height: len(lines),
+			}},
+		}
+	}, nil)
+}
+
+// renderReviewModal renders the review list over the list view.
+func (p *Plugin) renderReviewModal(width, height int) string {
+	background := p.renderListView(width, height)
+
+	p.ensureReviewModal()
+	if p.reviewModal == nil {
+		return background
+	}
+
+	modalContent := p.reviewModal.Render(width, height, p.mouseHandler)
+	return ui.OverlayModal(background, modalContent, width, height)
+}
+
+func (p *Plugin) handleReviewKeys(msg tea.KeyMsg) tea.Cmd {
+	wt := p.selectedWorktree()
+	p.ensureReviewModal()
+	if p.reviewModal == nil || wt == nil {
+		p.closeReviewModal()
+		return nil
+	}
+	comments := p.reviewComments(wt)
+
+	switch msg.String() {
+	case "esc", "q":
+		p.closeReviewModal()
+	case "j", "down":
+		if p.reviewIdx < len(comments)-1 {
+			p.reviewIdx++
+		}
+	case "k", "up":
+		if p.reviewIdx > 0 {
+			p.reviewIdx--
+		}
+	case "x", "d", "delete":
+		if p.reviewIdx < len(comments) {
+			p.removeReviewComment(wt, comments[p.reviewIdx].ID)
+			if p.reviewIdx >= len(p.reviewComments(wt)) && p.reviewIdx > 0 {
+				p.reviewIdx--
+			}
+		}
+		if len(p.reviewComments(wt)) == 0 {
+			p.closeReviewModal()
+		}
+	case "X":
+		var kept []*ReviewComment
+		for _, c := range comments {
+			if !c.Resolved {
+				kept = append(kept, c)
+			}
+		}
+		p.setReviewComments(wt, kept)
+		p.reviewIdx = 0
+		if len(kept) == 0 {
+			p.closeReviewModal()
+		}
+	case "s":
+		p.closeReviewModal()
+		return p.sendReview(wt)
+	case "enter":
+		if p.reviewIdx < len(comments) {
+			c := comments[p.reviewIdx]
+			p.closeReviewModal()
+			return p.jumpToReviewComment(c)
+		}
+	}
+	return nil
+}
+
+func (p *Plugin) handleReviewMouse(msg tea.MouseMsg) tea.Cmd {
+	p.ensureReviewModal()
+	if p.reviewModal == nil {
+		p.closeReviewModal()
+		return nil
+	}
+	if p.reviewModal.HandleMouse(msg, p.mouseHandler) == "cancel" {
+		p.closeReviewModal()
+	}
+	return nil
+}
+
+// jumpToReviewComment shows a comment's line at the top of the diff pane.
+func (p *Plugin) jumpToReviewComment(c *ReviewComment) tea.Cmd {
+	if p.multiFileDiff == nil {
+		return nil
+	}
+	for i, f := range p.multiFileDiff.Files {
+		if f.FileName() != c.File {
+			continue
+		}
+		oldCursor := p.diffTabCursor
+		p.diffTabCursor = i
+		cmd := p.onDiffTabCursorChanged(oldCursor)
+		p.activePane = PanePreview
+		p.previewTab = PreviewTabDiff
+		p.diffTabFocus = DiffTabFocusDiff
+		if f.Diff != nil {
+			for h, hunk := range f.Diff.Hunks {
+				for l, dl := range hunk.Lines {
+					if reviewCommentOnLine(c, dl) {
+						p.diffTabDiffScroll = diffScrollForLine(f.Diff, h, l)
+						return cmd
+					}
+				}
+			}
+		}
+		return cmd
+	}
+	return appmsg.ShowToast(c.location()+" is not in the current diff", 2*time.Second)
+}
@@ -0,0 +1,396 @@
+package workspace
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/marcus/sidecar/internal/tty"
+)
+
+// Session backend names, as used by the sessionBackend config option.
+const (
+	backendAuto = "auto"
+	backendTmux = "tmux"
+	backendPTY  = "pty"
+)
+
+// SessionBackend runs the terminal sessions behind agents, shells and the
+// terminal panel. Targets are session names or pane IDs from PaneID.
+type SessionBackend interface {
+	// Name returns the backend name (backendTmux or backendPTY).
+	Name() string
+	// Available reports whether the backend can create sessions on this machine.
+	Available() bool
+	// Create starts a detached shell session in workDir. A width or height
+	// <= 0 uses the backend default.
+	Create(name, workDir string, width, height int) error
+	// Exists reports whether a session is running.
+	Exists(name string) bool
+	// List returns the names of all running sessions.
+	List() ([]string, error)
+	// Kill terminates a session.
+	Kill(name string) error
+	// PaneID returns a stable target for the session's pane, or "".
+	PaneID(name string) string
+	// SendKey sends a tmux key name ("Enter", "C-c", "Up"). Anything that
+	// isn't a key name is typed literally, as with tmux send-keys.
+	SendKey(target, key string) error
+	// SendLiteral types text without key name lookup.
+	SendLiteral(target, text string) error
+	// Paste pastes text. With pasteAuto it is bracketed only if the
+	// application asked for bracketed paste.
+	Paste(target, text string, mode pasteMode) error
+	// Capture returns the session's history and screen with SGR styling.
+	// joinWrapped joins soft-wrapped lines where the backend supports it.
+	Capture(target string, joinWrapped bool) (string, error)
+	// History returns the session's entire scrollback and screen as plain
+	// text, with soft-wrapped lines joined where the backend supports it.
+	History(target string) (string, error)
+	// Record logs the session's output from now on to a transcript in dir
+	// (see the transcript package). Recording an already recorded session
+	// is a no-op.
+	Record(target, dir string, maxBytes int64, keep int) error
+	// Cursor returns the 0-indexed cursor position and pane size.
+	Cursor(target string) (row, col, paneHeight, paneWidth int, visible, ok bool)
+	// Size returns the pane size.
+	Size(target string) (width, height int, ok bool)
+	// Resize resizes the pane. A width or height <= 0 is left unchanged.
+	Resize(target string, width, height int) error
+	// AttachCmd returns a command attaching the user's terminal to the
+	// session, or nil if the backend doesn't support attaching.
+	AttachCmd(name string) *exec.Cmd
+}
+
+// pasteMode selects how Paste wraps text.
+type pasteMode int
+
+const (
+	pastePlain     pasteMode = iota // Unbracketed
+	pasteBracketed                  // Always bracketed
+	pasteAuto                       // Bracketed if the application enabled it
+)
+
+// sessionBackend is the active backend, chosen from config in Init.
+var sessionBackend SessionBackend = tmuxBackend{}
+
+// selectSessionBackend returns the backend for a sessionBackend config value.
+// "auto" (the default) uses tmux when installed and the PTY backend otherwise.
+// controlMode makes the tmux backend stream panes in control mode.
+func selectSessionBackend(name string, controlMode bool) SessionBackend {
+	tmux := tmuxBackend{}
+	if controlMode {
+		tmux.control = globalTmuxControl
+	}
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case backendTmux:
+		return tmux
+	case backendPTY:
+		return globalPTYBackend
+	default:
+		if isTmuxInstalled() {
+			return tmux
+		}
+		return globalPTYBackend
+	}
+}
+
+// tmuxBackend runs sessions in tmux. Sessions outlive sidecar and can be
+// attached from any terminal.
+type tmuxBackend struct {
+	// control streams pane output in control mode; nil polls capture-pane.
+	control *tmuxControl
+}
+
+// stream returns the control-mode stream for a target, or nil when
+// streaming is off or the stream isn't ready yet.
+func (b tmuxBackend) stream(target string) *tmuxStream {
+	if b.control == nil {
+		return nil
+	}
+	return b.control.stream(target)
+}
+
+// streams reports whether Capture with joinWrapped is served from control
+// mode. Streams render at the pane width, so joined captures still poll.
+func (b tmuxBackend) streams(joinWrapped bool) bool {
+	return b.control != nil && !joinWrapped
+}
+
+func (tmuxBackend) Name() string { return backendTmux }
+
+func (tmuxBackend) Available() bool { return isTmuxInstalled() }
+
+func (tmuxBackend) Create(name, workDir string, width, height int) error {
+	args := []string{
+		"new-session",
+		"-d",       // Detached
+		"-s", name, // Session name
+		"-c", workDir, // Working directory
+	}
+	if width > 0 && height > 0 {
+		args = append(args, "-x", strconv.Itoa(width), "-y", strconv.Itoa(height))
+	}
+	if err := exec.Command("tmux", args...).Run(); err != nil {
+		return err
+	}
+
+	// Ensure server persists when all sessions are killed
+	ensureTmuxServerConfig()
+
+	// Set history limit for scrollback capture
+	_ = exec.Command("tmux", "set-option", "-t", name, "history-limit",
+		strconv.Itoa(tmuxHistoryLimit)).Run()
+
+	if width > 0 && height > 0 {
+		tty.SetWindowSizeManual(name)
+	}
+	return nil
+}
+
+func (tmuxBackend) Exists(name string) bool {
+	return exec.Command("tmux", "has-session", "-t", name).Run() == nil
+}
+
+func (tmuxBackend) List() ([]string, error) {
+	output, err := exec.Command("tmux", "list-sessions", "-F", "#{session_name}").Output()
+	if err != nil {
+		return nil, err // No tmux server running
+	}
+	var names []string
+	for _, line := range strings.Split(string(output), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			names = append(names, line)
+		}
+	}
+	return names, nil
+}
+
+func (tmuxBackend) Kill(name string) error {
+	return exec.Command("tmux", "kill-session", "-t", name).Run()
+}
+
+// PaneID returns pane IDs like "%12", which are globally unique and stable.
+// Uses caching to avoid subprocess calls (pane IDs rarely change) (td-c2961e).
+func (tmuxBackend) PaneID(name string) string {
+	if paneID, ok := globalPaneIDCache.get(name); ok {
+		return paneID
+	}
+
+	output, err := exec.Command("tmux", "list-panes", "-t", name, "-F", "#{pane_id}").Output()
+	if err != nil {
+		return ""
+	}
+	// Return first pane ID (sessions typically have one pane)
+	paneID := strings.TrimSpace(string(output))
+	if idx := strings.Index(paneID, "\n"); idx > 0 {
+		paneID = paneID[:idx]
+	}
+
+	if paneID != "" {
+		globalPaneIDCache.set(name, paneID)
+	}
+	return paneID
+}
+
+func (tmuxBackend) SendKey(target, key string) error {
+	return exec.Command("tmux", "send-keys", "-t", target, key).Run()
+}
+
+func (tmuxBackend) SendLiteral(target, text string) error {
+	// tmux treats bare ; in argv as a command separator, so a literal
+	// semicolon never reaches send-keys. Fall back to hex encoding (-H)
+	// which bypasses tmux's command parser entirely.
+	if strings.Contains(text, ";") {
+		args := []string{"send-keys", "-t", target, "-H"}
+		for _, b := range []byte(text) {
+			args = append(args, fmt.Sprintf("%02x", b))
+		}
+		return exec.Command("tmux", args...).Run()
+	}
+	return exec.Command("tmux", "send-keys", "-l", "-t", target, text).Run()
+}
+
+func (b tmuxBackend) Paste(target, text string, mode pasteMode) error {
+	if mode == pasteBracketed {
+		return b.SendLiteral(target, bracketedPasteStart+text+bracketedPasteEnd)
+	}
+
+	// Load text into a tmux buffer via stdin, then paste it into the pane.
+	// paste-buffer -p brackets the paste if the application asked for it.
+	load := exec.Command("tmux", "load-buffer", "-")
+	load.Stdin = strings.NewReader(text)
+	if err := load.Run(); err != nil {
+		return err
+	}
+	args := []string{"paste-buffer", "-d", "-t", target}
+	if mode == pasteAuto {
+		args = append(args, "-p")
+	}
+	return exec.Command("tmux", args...).Run()
+}
+
+// Capture renders the control-mode screen model when streaming, and runs
+// capture-pane otherwise. When joinWrapped is false, lines stay wrapped at
+// the pane width for correct cursor alignment.
+func (b tmuxBackend) Capture(target string, joinWrapped bool) (string, error) {
+	if b.streams(joinWrapped) {
+		if s := b.stream(target); s != nil {
+			return s.render(), nil
+		}
+	}
+
+	startLine := fmt.Sprintf("-%d", captureLineCount)
+	ctx, cancel := context.WithTimeout(context.Background(), tmuxCaptureTimeout)
+	defer cancel()
+	args := []string{"capture-pane", "-p", "-e"}
+	if joinWrapped {
+		args = append(args, "-J")
+	}
+	args = append(args, "-S", startLine, "-t", target)
+	output, err := exec.CommandContext(ctx, "tmux", args...).Output()
+	if ctx.Err() == context.DeadlineExceeded {
+		return "", fmt.Errorf("capture-pane: timeout after %s", tmuxCaptureTimeout)
+	}
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok && len(exitErr.Stderr) > 0 {
+			return "", fmt.Errorf("capture-pane: %s", strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return "", fmt.Errorf("capture-pane: %w", err)
+	}
+	return string(output), nil
+}
+
+// History captures the whole pane history with capture-pane -S -. It always
+// runs tmux, since control-mode streams only model the last captureLineCount
+// lines.
+func (tmuxBackend) History(target string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), tmuxHistoryTimeout)
+	defer cancel()
+	output, err := exec.CommandContext(ctx, "tmux", "capture-pane", "-p", "-J", "-S", "-", "-t", target).Output()
+	if ctx.Err() == context.DeadlineExceeded {
+		return "", fmt.Errorf("capture-pane: timeout after %s", tmuxHistoryTimeout)
+	}
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok && len(exitErr.Stderr) > 0 {
+			return "", fmt.Errorf("capture-pane: %s", strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return "", fmt.Errorf("capture-pane: %w", err)
+	}
+	return string(output), nil
+}
+
+// Record pipes the pane's output to `sidecar transcript` with pipe-pane.
+// The pipe lives in tmux, so recording continues while sidecar isn't running.
+func (tmuxBackend) Record(target, dir string, maxBytes int64, keep int) error {
+	// pipe-pane -o would toggle an existing pipe off, so check for one first
+	out, err := exec.Command("tmux", "display-message", "-p", "-t", target, "#{pane_pipe}").Output()
+	if err != nil {
+		return fmt.Errorf("display-message: %w", err)
+	}
+	if strings.TrimSpace(string(out)) == "1" {
+		return nil
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	pipeCmd := fmt.Sprintf("exec %s transcript -dir %s -max-bytes %d -keep %d",
+		shellQuote(exe), shellQuote(dir), maxBytes, keep)
+	if out, err := exec.Command("tmux", "pipe-pane", "-t", target, pipeCmd).CombinedOutput(); err != nil {
+		return fmt.Errorf("pipe-pane: %s", strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+func (b tmuxBackend) Cursor(target string) (row, col, paneHeight, paneWidth int, visible, ok bool) {
+	if s := b.stream(target); s != nil {
+		term := s.terminal()
+		row, col, visible = term.Cursor()
+		paneWidth, paneHeight = term.Size()
+		return row, col, paneHeight, paneWidth, visible, true
+	}
+
+	output, err := exec.Command("tmux", "display-message", "-t", target,
+		"-p", "#{cursor_x},#{cursor_y},#{cursor_flag},#{pane_height},#{pane_width}").Output()
+	if err != nil {
+		return 0, 0, 0, 0, false, false
+	}
+
+	parts := strings.Split(strings.TrimSpace(string(output)), ",")
+	if len(parts) < 2 {
+		return 0, 0, 0, 0, false, false
+	}
+
+	col, _ = strconv.Atoi(parts[0])
+	row, _ = strconv.Atoi(parts[1])
+	visible = len(parts) < 3 || parts[2] != "0"
+	if len(parts) >= 4 {
+		paneHeight, _ = strconv.Atoi(parts[3])
+	}
+	if len(parts) >= 5 {
+		paneWidth, _ = strconv.Atoi(parts[4])
+	}
+	return row, col, paneHeight, paneWidth, visible, true
+}
+
+func (b tmuxBackend) Size(target string) (width, height int, ok bool) {
+	if s := b.stream(target); s != nil {
+		width, height = s.terminal().Size()
+		return width, height, true
+	}
+
+	output, err := exec.Command("tmux", "display-message", "-t", target, "-p", "#{pane_width},#{pane_height}").Output()
+	if err != nil {
+		return 0, 0, false
+	}
+
+	parts := strings.Split(strings.TrimSpace(string(output)), ",")
+	if len(parts) < 2 {
+		return 0, 0, false
+	}
+
+	width, _ = strconv.Atoi(parts[0])
+	height, _ = strconv.Atoi(parts[1])
+	return width, height, true
+}
+
+// Resize uses resize-window, which works for detached sessions; resize-pane
+// is a fallback.
+func (tmuxBackend) Resize(target string, width, height int) error {
+	args := []string{"resize-window", "-t", target}
+	if width > 0 {
+		args = append(args, "-x", strconv.Itoa(width))
+	}
+	if height > 0 {
+		args = append(args, "-y", strconv.Itoa(height))
+	}
+	if err := exec.Command("tmux", args...).Run(); err == nil {
+		return nil
+	}
+
+	// Fallback for older tmux or attached clients that reject resize-window.
+	args = []string{"resize-pane", "-t", target}
+	if width > 0 {
+		args = append(args, "-x", strconv.Itoa(width))
+	}
+	if height > 0 {
+		args = append(args, "-y", strconv.Itoa(height))
+	}
+	return exec.Command("tmux", args...).Run()
+}
+
+func (tmuxBackend) AttachCmd(name string) *exec.Cmd {
+	return exec.Command("tmux", "attach-session", "-t", name)
+}
+
+// sendSessionLine types a command line into a session and presses Enter.
+func sendSessionLine(target, line string) error {
+	if err := sessionBackend.SendLiteral(target, line); err != nil {
+		return err
+	}
+	return sessionBackend.SendKey(target, "Enter")
+}
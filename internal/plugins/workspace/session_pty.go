@@ -0,0 +1,366 @@
+package workspace
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"syscall"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/creack/pty"
+	"github.com/marcus/sidecar/internal/transcript"
+	"github.com/marcus/sidecar/internal/vt"
+)
+
+const (
+	// ptyDefaultCols and ptyDefaultRows size new PTY sessions, like tmux's default-size.
+	ptyDefaultCols = 80
+	ptyDefaultRows = 24
+)
+
+// errPTYSessionNotFound mirrors tmux's "can't find" wording so the poll loops
+// treat a gone PTY session like a gone tmux session.
+var errPTYSessionNotFound = errors.New("can't find session")
+
+// globalPTYBackend owns every in-process session. It is shared across
+// plugin re-inits (project switches) so running sessions aren't orphaned.
+var globalPTYBackend = &ptyBackend{sessions: make(map[string]*ptySession)}
+
+// ptySession is a shell running on a PTY, with its output fed to a screen emulator.
+type ptySession struct {
+	cmd  *exec.Cmd
+	pty  *os.File
+	term *vt.Terminal
+	done chan struct{}
+
+	logMu sync.Mutex
+	log   *transcript.Writer // Transcript being recorded, or nil
+}
+
+// ptyBackend runs sessions in process: each is a shell on a PTY whose output
+// drives a VT emulator, so capture reads exact screen state from memory
+// instead of running tmux. Sessions end when sidecar exits.
+type ptyBackend struct {
+	mu       sync.Mutex
+	sessions map[string]*ptySession
+}
+
+func (b *ptyBackend) Name() string { return backendPTY }
+
+func (b *ptyBackend) Available() bool { return true }
+
+func (b *ptyBackend) Create(name, workDir string, width, height int) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if s, ok := b.sessions[name]; ok && !s.exited() {
+		return fmt.Errorf("duplicate session: %s", name)
+	}
+
+	if width <= 0 || height <= 0 {
+		width, height = ptyDefaultCols, ptyDefaultRows
+	}
+	shell := os.Getenv("SHELL")
+	if shell == "" {
+		shell = "/bin/sh"
+	}
+	cmd := exec.Command(shell, "-l")
+	cmd.Dir = workDir
+	cmd.Env = append(os.Environ(), "TERM=xterm-256color")
+	f, err := pty.StartWithSize(cmd, &pty.Winsize{Cols: uint16(width), Rows: uint16(height)})
+	if err != nil {
+		return fmt.Errorf("start pty: %w", err)
+	}
+
+	s := &ptySession{
+		cmd:  cmd,
+		pty:  f,
+		term: vt.New(width, height, tmuxHistoryLimit, f),
+		done: make(chan struct{}),
+	}
+	b.sessions[name] = s
+
+	go func() {
+		_, _ = s.term.ReadFrom(io.TeeReader(f, s))
+		_ = cmd.Wait()
+		s.stopRecording()
+		close(s.done)
+		_ = f.Close()
+		b.mu.Lock()
+		if b.sessions[name] == s {
+			delete(b.sessions, name)
+		}
+		b.mu.Unlock()
+	}()
+	return nil
+}
+
+// Write copies session output to the transcript, if one is being recorded.
+// Logging errors stop the recording rather than the session.
+func (s *ptySession) Write(p []byte) (int, error) {
+	s.logMu.Lock()
+	defer s.logMu.Unlock()
+	if s.log != nil {
+		if _, err := s.log.Write(p); err != nil {
+			_ = s.log.Close()
+			s.log = nil
+		}
+	}
+	return len(p), nil
+}
+
+// stopRecording closes the transcript, if any.
+func (s *ptySession) stopRecording() {
+	s.logMu.Lock()
+	defer s.logMu.Unlock()
+	if s.log != nil {
+		_ = s.log.Close()
+		s.log = nil
+	}
+}
+
+// exited reports whether the session's shell has exited.
+func (s *ptySession) exited() bool {
+	select {
+	case <-s.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// get returns a running session.
+func (b *ptyBackend) get(name string) (*ptySession, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	s, ok := b.sessions[name]
+	if !ok || s.exited() {
+		return nil, fmt.Errorf("%w: %s", errPTYSessionNotFound, name)
+	}
+	return s, nil
+}
+
+func (b *ptyBackend) Exists(name string) bool {
+	_, err := b.get(name)
+	return err == nil
+}
+
+func (b *ptyBackend) List() ([]string, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var names []string
+	for name, s := range b.sessions {
+		if !s.exited() {
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
+// Kill hangs up the session's process group, like closing a terminal.
+func (b *ptyBackend) Kill(name string) error {
+	s, err := b.get(name)
+	if err != nil {
+		return err
+	}
+	if s.cmd.Process != nil {
+		_ = syscall.Kill(-s.cmd.Process.Pid, syscall.SIGHUP)
+	}
+	return s.pty.Close()
+}
+
+// PaneID returns the session name; PTY sessions have a single pane.
+func (b *ptyBackend) PaneID(name string) string {
+	if !b.Exists(name) {
+		return ""
+	}
+	return name
+}
+
+func (b *ptyBackend) SendKey(target, key string) error {
+	s, err := b.get(target)
+	if err != nil {
+		return err
+	}
+	_, err = s.pty.WriteString(ptyKeySequence(key, s.term.AppCursorKeys()))
+	return err
+}
+
+func (b *ptyBackend) SendLiteral(target, text string) error {
+	s, err := b.get(target)
+	if err != nil {
+		return err
+	}
+	_, err = s.pty.WriteString(text)
+	return err
+}
+
+// Paste writes text with newlines as carriage returns (like tmux
+// paste-buffer), bracketed when requested.
+func (b *ptyBackend) Paste(target, text string, mode pasteMode) error {
+	s, err := b.get(target)
+	if err != nil {
+		return err
+	}
+	if mode == pasteBracketed || (mode == pasteAuto && s.term.BracketedPaste()) {
+		text = bracketedPasteStart + text + bracketedPasteEnd
+	} else {
+		text = strings.ReplaceAll(text, "\n", "\r")
+	}
+	_, err = s.pty.WriteString(text)
+	return err
+}
+
+// Capture renders the emulator screen. Lines are always wrapped at the pane
+// width, so joinWrapped is ignored.
+func (b *ptyBackend) Capture(target string, joinWrapped bool) (string, error) {
+	s, err := b.get(target)
+	if err != nil {
+		return "", err
+	}
+	return s.term.Render(captureLineCount), nil
+}
+
+// History renders all of the emulator's scrollback without styling.
+func (b *ptyBackend) History(target string) (string, error) {
+	s, err := b.get(target)
+	if err != nil {
+		return "", err
+	}
+	return ansi.Strip(s.term.Render(s.term.ScrollbackLen())), nil
+}
+
+// Record tees the session's output into a transcript until the shell exits.
+func (b *ptyBackend) Record(target, dir string, maxBytes int64, keep int) error {
+	s, err := b.get(target)
+	if err != nil {
+		return err
+	}
+	s.logMu.Lock()
+	defer s.logMu.Unlock()
+	if s.log != nil {
+		return nil
+	}
+	w, err := transcript.NewWriter(dir, maxBytes, keep)
+	if err != nil {
+		return err
+	}
+	s.log = w
+	return nil
+}
+
+func (b *ptyBackend) Cursor(target string) (row, col, paneHeight, paneWidth int, visible, ok bool) {
+	s, err := b.get(target)
+	if err != nil {
+		return 0, 0, 0, 0, false, false
+	}
+	row, col, visible = s.term.Cursor()
+	paneWidth, paneHeight = s.term.Size()
+	return row, col, paneHeight, paneWidth, visible, true
+}
+
+func (b *ptyBackend) Size(target string) (width, height int, ok bool) {
+	s, err := b.get(target)
+	if err != nil {
+		return 0, 0, false
+	}
+	width, height = s.term.Size()
+	return width, height, true
+}
+
+func (b *ptyBackend) Resize(target string, width, height int) error {
+	s, err := b.get(target)
+	if err != nil {
+		return err
+	}
+	cols, rows := s.term.Size()
+	if width > 0 {
+		cols = width
+	}
+	if height > 0 {
+		rows = height
+	}
+	s.term.Resize(cols, rows)
+	return pty.Setsize(s.pty, &pty.Winsize{Cols: uint16(cols), Rows: uint16(rows)})
+}
+
+// AttachCmd returns nil: PTY sessions live inside sidecar, so they are
+// driven through interactive mode instead.
+func (b *ptyBackend) AttachCmd(name string) *exec.Cmd {
+	return nil
+}
+
+// ptyKeySequence translates a tmux key name to the bytes a terminal sends.
+// Unknown names are returned as-is, matching tmux send-keys.
+func ptyKeySequence(key string, appCursor bool) string {
+	cursorKey := func(final string) string {
+		if appCursor {
+			return "\x1bO" + final
+		}
+		return "\x1b[" + final
+	}
+	switch key {
+	case "Enter":
+		return "\r"
+	case "BSpace":
+		return "\x7f"
+	case "Tab":
+		return "\t"
+	case "BTab":
+		return "\x1b[Z"
+	case "Space":
+		return " "
+	case "Escape":
+		return "\x1b"
+	case "Up":
+		return cursorKey("A")
+	case "Down":
+		return cursorKey("B")
+	case "Right":
+		return cursorKey("C")
+	case "Left":
+		return cursorKey("D")
+	case "Home":
+		return cursorKey("H")
+	case "End":
+		return cursorKey("F")
+	case "IC":
+		return "\x1b[2~"
+	case "DC":
+		return "\x1b[3~"
+	case "PPage":
+		return "\x1b[5~"
+	case "NPage":
+		return "\x1b[6~"
+	}
+	if seq, ok := ptyFunctionKeys[key]; ok {
+		return seq
+	}
+	if len(key) == 3 && key[1] == '-' {
+		switch key[0] {
+		case 'C':
+			if c := key[2] | 0x20; c >= 'a' && c <= 'z' {
+				return string(rune(c & 0x1f))
+			}
+			switch key[2] {
+			case '@', ' ':
+				return "\x00"
+			case '[', '\\', ']', '^', '_':
+				return string(rune(key[2] & 0x1f))
+			}
+		case 'M':
+			return "\x1b" + key[2:]
+		}
+	}
+	return key
+}
+
+// ptyFunctionKeys maps F1-F12 to xterm sequences.
+var ptyFunctionKeys = map[string]string{
+	"F1": "\x1bOP", "F2": "\x1bOQ", "F3": "\x1bOR", "F4": "\x1bOS",
+	"F5": "\x1b[15~", "F6": "\x1b[17~", "F7": "\x1b[18~", "F8": "\x1b[19~",
+	"F9": "\x1b[20~", "F10": "\x1b[21~", "F11": "\x1b[23~", "F12": "\x1b[24~",
+}
@@ -0,0 +1,159 @@
+package workspace
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/marcus/sidecar/internal/transcript"
+)
+
+func TestPTYKeySequence(t *testing.T) {
+	tests := []struct {
+		key       string
+		appCursor bool
+		want      string
+	}{
+		{"Enter", false, "\r"},
+		{"BSpace", false, "\x7f"},
+		{"Up", false, "\x1b[A"},
+		{"Up", true, "\x1bOA"},
+		{"Home", true, "\x1bOH"},
+		{"PPage", false, "\x1b[5~"},
+		{"F5", false, "\x1b[15~"},
+		{"C-c", false, "\x03"},
+		{"C-[", false, "\x1b"},
+		{"M-x", false, "\x1bx"},
+		{"q", false, "q"},
+	}
+	for _, tt := range tests {
+		if got := ptyKeySequence(tt.key, tt.appCursor); got != tt.want {
+			t.Errorf("ptyKeySequence(%q, %v) = %q, want %q", tt.key, tt.appCursor, got, tt.want)
+		}
+	}
+}
+
+func TestSelectSessionBackend(t *testing.T) {
+	if b := selectSessionBackend("pty", true); b.Name() != backendPTY {
+		t.Errorf("pty: got %s", b.Name())
+	}
+	if b := selectSessionBackend(" TMUX ", true); b.Name() != backendTmux {
+		t.Errorf("tmux: got %s", b.Name())
+	}
+	want := backendPTY
+	if isTmuxInstalled() {
+		want = backendTmux
+	}
+	if b := selectSessionBackend("", true); b.Name() != want {
+		t.Errorf("auto: got %s, want %s", b.Name(), want)
+	}
+}
+
+func TestPTYBackend_RoundTrip(t *testing.T) {
+	t.Setenv("SHELL", "/bin/sh")
+	b := &ptyBackend{sessions: make(map[string]*ptySession)}
+	name := "sidecar-test-pty"
+
+	if err := b.Create(name, t.TempDir(), 40, 10); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	defer func() { _ = b.Kill(name) }()
+
+	if !b.Exists(name) || b.PaneID(name) != name {
+		t.Fatal("session should exist with its name as pane ID")
+	}
+	if names, _ := b.List(); len(names) != 1 || names[0] != name {
+		t.Errorf("List = %v", names)
+	}
+	if err := b.Create(name, t.TempDir(), 0, 0); err == nil {
+		t.Error("duplicate Create should fail")
+	}
+
+	if err := b.SendLiteral(name, "echo sidecar-$((40+2))"); err != nil {
+		t.Fatalf("SendLiteral: %v", err)
+	}
+	if err := b.SendKey(name, "Enter"); err != nil {
+		t.Fatalf("SendKey: %v", err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		out, err := b.Capture(name, false)
+		if err != nil {
+			t.Fatalf("Capture: %v", err)
+		}
+		if strings.Contains(out, "sidecar-42") {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("output never appeared:\n%s", out)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	if err := b.Resize(name, 60, 0); err != nil {
+		t.Fatalf("Resize: %v", err)
+	}
+	if w, h, ok := b.Size(name); !ok || w != 60 || h != 10 {
+		t.Errorf("Size = %dx%d (ok %v), want 60x10", w, h, ok)
+	}
+
+	if err := b.Kill(name); err != nil {
+		t.Fatalf("Kill: %v", err)
+	}
+	deadline = time.Now().Add(5 * time.Second)
+	for b.Exists(name) {
+		if time.Now().After(deadline) {
+			t.Fatal("session still exists after Kill")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	if _, err := b.Capture(name, false); err == nil || !isSessionDeadError(err) {
+		t.Errorf("Capture after Kill: err = %v, want session-dead error", err)
+	}
+}
+
+func TestPTYBackend_Record(t *testing.T) {
+	t.Setenv("SHELL", "/bin/sh")
+	b := &ptyBackend{sessions: make(map[string]*ptySession)}
+	name := "sidecar-test-pty-record"
+	dir := t.TempDir()
+
+	if err := b.Create(name, t.TempDir(), 40, 10); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	defer func() { _ = b.Kill(name) }()
+	if err := b.Record(name, dir, 0, 0); err != nil {
+		t.Fatalf("Record: %v", err)
+	}
+	if err := b.Record(name, dir, 0, 0); err != nil {
+		t.Fatalf("second Record should be a no-op: %v", err)
+	}
+
+	_ = b.SendLiteral(name, "echo recorded-$((40+2))")
+	_ = b.SendKey(name, "Enter")
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		out, _ := b.Capture(name, false)
+		if strings.Contains(out, "recorded-42") {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("output never appeared:\n%s", out)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	_ = b.Kill(name)
+	deadline = time.Now().Add(5 * time.Second)
+	for {
+		text, _ := transcript.ReadText(dir)
+		if strings.Contains(text, "recorded-42") {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("transcript missing output:\n%s", text)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
@@ -0,0 +1,371 @@
+package workspace
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"log/slog"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/marcus/sidecar/internal/vt"
+)
+
+const (
+	// tmuxControlRetryDelay is how long to use capture-pane for a pane after
+	// a control-mode attach failed (e.g. tmux older than 3.2).
+	tmuxControlRetryDelay = 30 * time.Second
+
+	// tmuxControlIdleTimeout detaches streams nobody has read for this long.
+	tmuxControlIdleTimeout = 2 * time.Minute
+)
+
+// tmuxControl streams tmux panes through control-mode clients (tmux -C).
+// Each pane's %output notifications feed an in-process screen model, so
+// captures, cursor and size queries are answered from memory instead of
+// spawning capture-pane on every poll.
+type tmuxControl struct {
+	mu        sync.Mutex
+	streams   map[string]*tmuxStream // Keyed by pane ID
+	failed    map[string]time.Time   // Pane ID -> last failed attach
+	cleanOnce sync.Once
+}
+
+var globalTmuxControl = &tmuxControl{
+	streams: make(map[string]*tmuxStream),
+	failed:  make(map[string]time.Time),
+}
+
+// tmuxStream is one control-mode client following a single pane.
+type tmuxStream struct {
+	paneID string
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	done   chan struct{}
+
+	mu       sync.Mutex
+	term     *vt.Terminal // nil until seeded from capture-pane
+	lastRead time.Time
+
+	// Last render, reused while the screen is unchanged
+	renderedTerm    *vt.Terminal
+	renderedVersion uint64
+	rendered        string
+}
+
+// tmuxPaneState is the pane state a stream is seeded with.
+type tmuxPaneState struct {
+	width, height int
+	cursorX       int
+	cursorY       int
+	cursorVisible bool
+	altScreen     bool
+}
+
+// stream returns the seeded stream for a session name or pane ID. On first
+// use it starts attaching in the background and returns nil, so the caller
+// falls back to capture-pane until the stream is ready.
+func (c *tmuxControl) stream(target string) *tmuxStream {
+	paneID := target
+	if !strings.HasPrefix(target, "%") {
+		paneID = tmuxBackend{}.PaneID(target)
+	}
+	if paneID == "" {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if s, ok := c.streams[paneID]; ok {
+		if s.exited() {
+			delete(c.streams, paneID)
+		} else {
+			return s.touch()
+		}
+	}
+	if failedAt, ok := c.failed[paneID]; ok && time.Since(failedAt) < tmuxControlRetryDelay {
+		return nil
+	}
+
+	s, err := c.attach(paneID)
+	if err != nil {
+		slog.Debug("tmux control: attach failed", "pane", paneID, "err", err)
+		c.failed[paneID] = time.Now()
+		return nil
+	}
+	c.streams[paneID] = s
+	c.cleanOnce.Do(c.startCleanupLoop)
+	return nil
+}
+
+// attach starts a control-mode client for a pane. ignore-size keeps the
+// client from shrinking the window to the control client's default size.
+// Caller must hold c.mu.
+func (c *tmuxControl) attach(paneID string) (*tmuxStream, error) {
+	cmd := exec.Command("tmux", "-C", "attach-session", "-f", "ignore-size", "-t", paneID)
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	s := &tmuxStream{
+		paneID:   paneID,
+		cmd:      cmd,
+		stdin:    stdin,
+		done:     make(chan struct{}),
+		lastRead: time.Now(),
+	}
+	go func() {
+		seeded := s.readLoop(stdout)
+		s.close()
+		_ = cmd.Wait()
+		close(s.done)
+		c.mu.Lock()
+		if c.streams[paneID] == s {
+			delete(c.streams, paneID)
+		}
+		if !seeded {
+			c.failed[paneID] = time.Now()
+		}
+		c.mu.Unlock()
+	}()
+	return s, nil
+}
+
+// startCleanupLoop periodically detaches streams that are no longer read.
+func (c *tmuxControl) startCleanupLoop() {
+	go func() {
+		ticker := time.NewTicker(30 * time.Second)
+		defer ticker.Stop()
+		for range ticker.C {
+			c.cleanup()
+		}
+	}()
+}
+
+// cleanup detaches idle streams and forgets expired attach failures.
+func (c *tmuxControl) cleanup() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for paneID, s := range c.streams {
+		s.mu.Lock()
+		idle := time.Since(s.lastRead) >= tmuxControlIdleTimeout
+		s.mu.Unlock()
+		if idle {
+			s.close()
+			delete(c.streams, paneID)
+		}
+	}
+	for paneID, failedAt := range c.failed {
+		if time.Since(failedAt) >= tmuxControlRetryDelay {
+			delete(c.failed, paneID)
+		}
+	}
+}
+
+// closeAll detaches every stream (used when switching backends).
+func (c *tmuxControl) closeAll() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for paneID, s := range c.streams {
+		s.close()
+		delete(c.streams, paneID)
+	}
+}
+
+// readLoop consumes control-mode output until the client exits. It seeds
+// the screen once tmux acknowledges the attach, then applies %output for
+// the pane incrementally. Returns whether the stream was ever seeded.
+func (s *tmuxStream) readLoop(r io.Reader) (seeded bool) {
+	br := bufio.NewReaderSize(r, 64*1024)
+	attached := false
+	for {
+		line, err := br.ReadString('\n')
+		line = strings.TrimSuffix(line, "\n")
+		switch {
+		case !attached && strings.HasPrefix(line, "%end "):
+			// Reply to the attach command: the client is now receiving output
+			attached = true
+			if seeded = s.reseed(); !seeded {
+				return false
+			}
+		case !attached && strings.HasPrefix(line, "%error "):
+			return false
+		case strings.HasPrefix(line, "%layout-change "):
+			// Resizes reflow the pane in tmux; take a fresh snapshot
+			// rather than emulating tmux's reflow.
+			seeded = s.reseed() || seeded
+		case line == "%exit" || strings.HasPrefix(line, "%exit "):
+			return seeded
+		default:
+			s.handleLine(line)
+		}
+		if err != nil {
+			return seeded
+		}
+	}
+}
+
+// handleLine applies an %output notification for the stream's pane.
+// Other notifications are ignored.
+func (s *tmuxStream) handleLine(line string) {
+	rest, ok := strings.CutPrefix(line, "%output ")
+	if !ok {
+		return
+	}
+	paneID, data, ok := strings.Cut(rest, " ")
+	if !ok || paneID != s.paneID {
+		return
+	}
+	s.mu.Lock()
+	term := s.term
+	s.mu.Unlock()
+	if term != nil {
+		_, _ = term.Write(unescapeTmuxOutput(data))
+	}
+}
+
+// reseed replaces the screen model with a capture-pane snapshot. Output
+// that arrives while the snapshot is taken is applied on top of it; a
+// full-screen redraw or the next layout change corrects any overlap.
+func (s *tmuxStream) reseed() bool {
+	state, ok := queryTmuxPaneState(s.paneID)
+	if !ok {
+		return false
+	}
+	capture, err := tmuxBackend{}.Capture(s.paneID, false)
+	if err != nil {
+		return false
+	}
+	term := seedTerminal(capture, state)
+	s.mu.Lock()
+	s.term = term
+	s.mu.Unlock()
+	return true
+}
+
+// touch records a read and returns the stream if it is seeded.
+func (s *tmuxStream) touch() *tmuxStream {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lastRead = time.Now()
+	if s.term == nil {
+		return nil
+	}
+	return s
+}
+
+// terminal returns the current screen model.
+func (s *tmuxStream) terminal() *vt.Terminal {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.term
+}
+
+// render returns the screen in capture-pane format, re-rendering only when
+// the model changed since the last call.
+func (s *tmuxStream) render() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	version := s.term.Version()
+	if s.renderedTerm != s.term || s.renderedVersion != version {
+		s.rendered = s.term.Render(captureLineCount)
+		s.renderedTerm, s.renderedVersion = s.term, version
+	}
+	return s.rendered
+}
+
+// exited reports whether the control client has exited.
+func (s *tmuxStream) exited() bool {
+	select {
+	case <-s.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// close detaches the control client. tmux exits a control client when its
+// stdin closes; the kill covers a client that is stuck writing.
+func (s *tmuxStream) close() {
+	_ = s.stdin.Close()
+	if s.cmd.Process != nil {
+		_ = s.cmd.Process.Kill()
+	}
+}
+
+// queryTmuxPaneState reads the pane size, cursor and screen mode.
+func queryTmuxPaneState(target string) (tmuxPaneState, bool) {
+	output, err := exec.Command("tmux", "display-message", "-t", target, "-p",
+		"#{pane_width},#{pane_height},#{cursor_x},#{cursor_y},#{cursor_flag},#{alternate_on}").Output()
+	if err != nil {
+		return tmuxPaneState{}, false
+	}
+	parts := strings.Split(strings.TrimSpace(string(output)), ",")
+	if len(parts) < 6 {
+		return tmuxPaneState{}, false
+	}
+	var state tmuxPaneState
+	state.width, _ = strconv.Atoi(parts[0])
+	state.height, _ = strconv.Atoi(parts[1])
+	state.cursorX, _ = strconv.Atoi(parts[2])
+	state.cursorY, _ = strconv.Atoi(parts[3])
+	state.cursorVisible = parts[4] != "0"
+	state.altScreen = parts[5] == "1"
+	return state, state.width > 0 && state.height > 0
+}
+
+// seedTerminal builds a screen model from capture-pane output (history then
+// screen rows, one per line) and the pane state. History rows scroll into
+// the model's scrollback, leaving the last height rows on screen.
+func seedTerminal(capture string, state tmuxPaneState) *vt.Terminal {
+	term := vt.New(state.width, state.height, tmuxHistoryLimit, nil)
+	lines := strings.Split(strings.TrimSuffix(capture, "\n"), "\n")
+
+	var b strings.Builder
+	if state.altScreen {
+		// The main screen is hidden in tmux; seed only the visible rows
+		b.WriteString("\x1b[?1049h")
+		lines = lines[max(len(lines)-state.height, 0):]
+	}
+	for i, line := range lines {
+		if i > 0 {
+			b.WriteString("\r\n")
+		}
+		b.WriteString("\x1b[0m")
+		b.WriteString(line)
+	}
+	fmt.Fprintf(&b, "\x1b[0m\x1b[%d;%dH", state.cursorY+1, state.cursorX+1)
+	if !state.cursorVisible {
+		b.WriteString("\x1b[?25l")
+	}
+	_, _ = term.Write([]byte(b.String()))
+	return term
+}
+
+// unescapeTmuxOutput decodes %output data, in which tmux writes bytes below
+// 0x20 and backslash as three-digit octal escapes ("\015", "\134").
+func unescapeTmuxOutput(data string) []byte {
+	out := make([]byte, 0, len(data))
+	for i := 0; i < len(data); i++ {
+		if data[i] == '\\' && i+3 < len(data) && isOctal(data[i+1]) && isOctal(data[i+2]) && isOctal(data[i+3]) {
+			out = append(out, (data[i+1]-'0')<<6|(data[i+2]-'0')<<3|(data[i+3]-'0'))
+			i += 3
+			continue
+		}
+		out = append(out, data[i])
+	}
+	return out
+}
+
+func isOctal(c byte) bool { return c >= '0' && c <= '7' }
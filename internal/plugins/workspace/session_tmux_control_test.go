@@ -0,0 +1,131 @@
+package workspace
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestUnescapeTmuxOutput(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{`plain text`, "plain text"},
+		{`line\015\012`, "line\r\n"},
+		{`back\134slash`, `back\slash`},
+		{`\033[1mbold\033[0m`, "\x1b[1mbold\x1b[0m"},
+		{`short\01`, `short\01`},
+		{`not\999octal`, `not\999octal`},
+	}
+	for _, tt := range tests {
+		if got := string(unescapeTmuxOutput(tt.in)); got != tt.want {
+			t.Errorf("unescapeTmuxOutput(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestSeedTerminal(t *testing.T) {
+	capture := "old 1\nold 2\n\x1b[31mred\x1b[39m\n$ \n"
+	term := seedTerminal(capture, tmuxPaneState{width: 20, height: 2, cursorX: 2, cursorY: 1, cursorVisible: true})
+
+	if got := ansi.Strip(term.Render(10)); got != "old 1\nold 2\nred\n$\n" {
+		t.Errorf("render = %q", got)
+	}
+	if row, col, visible := term.Cursor(); row != 1 || col != 2 || !visible {
+		t.Errorf("cursor = (%d,%d,%v), want (1,2,true)", row, col, visible)
+	}
+
+	alt := seedTerminal("history\nscreen\n", tmuxPaneState{width: 20, height: 1, altScreen: true})
+	if got := ansi.Strip(alt.Render(10)); got != "screen\n" {
+		t.Errorf("alt render = %q, want screen rows only", got)
+	}
+	if _, _, visible := alt.Cursor(); visible {
+		t.Error("cursor should be hidden")
+	}
+}
+
+func TestTmuxStream_HandleLine(t *testing.T) {
+	s := &tmuxStream{paneID: "%3"}
+	s.handleLine(`%output %3 dropped`) // Not seeded yet
+
+	s.term = seedTerminal("\n", tmuxPaneState{width: 20, height: 1, cursorVisible: true})
+	first := s.render()
+	s.handleLine(`%output %4 other pane`)
+	s.handleLine(`%window-add @2`)
+	if s.render() != first {
+		t.Error("render changed for another pane's output")
+	}
+
+	s.handleLine(`%output %3 hi\134there`)
+	if got := ansi.Strip(s.render()); got != `hi\there`+"\n" {
+		t.Errorf("render = %q", got)
+	}
+}
+
+func TestTmuxControl_Stream(t *testing.T) {
+	if _, err := exec.LookPath("tmux"); err != nil {
+		t.Skip("tmux not installed")
+	}
+	name := "sidecar-test-control"
+	b := tmuxBackend{control: &tmuxControl{
+		streams: make(map[string]*tmuxStream),
+		failed:  make(map[string]time.Time),
+	}}
+	if err := b.Create(name, t.TempDir(), 40, 10); err != nil {
+		t.Skipf("tmux new-session: %v", err)
+	}
+	defer func() { _ = b.Kill(name) }()
+	defer b.control.closeAll()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for b.stream(name) == nil {
+		if time.Now().After(deadline) {
+			t.Skip("control-mode attach not supported by this tmux")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	if err := sendTmuxLine(name, "echo sidecar-$((40+2))"); err != nil {
+		t.Fatalf("send-keys: %v", err)
+	}
+	deadline = time.Now().Add(5 * time.Second)
+	for {
+		out, err := b.Capture(name, false)
+		if err != nil {
+			t.Fatalf("Capture: %v", err)
+		}
+		if strings.Contains(out, "sidecar-42") {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("output never streamed:\n%s", out)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	if err := b.Resize(name, 30, 0); err != nil {
+		t.Fatalf("Resize: %v", err)
+	}
+	deadline = time.Now().Add(5 * time.Second)
+	for {
+		if w, _, ok := b.Size(name); ok && w == 30 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("stream never picked up the resize")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+// sendTmuxLine types a line into a tmux session without touching the
+// package-level session backend.
+func sendTmuxLine(target, line string) error {
+	if err := (tmuxBackend{}).SendLiteral(target, line); err != nil {
+		return err
+	}
+	return tmuxBackend{}.SendKey(target, "Enter")
+}
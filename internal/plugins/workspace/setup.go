@@ -3,7 +3,6 @@ package workspace
 import (
 	"io"
 	"os"
-	"os/exec"
 	"path/filepath"
 )
 
@@ -36,8 +35,9 @@ func DefaultSetupConfig() *SetupConfig {
 }
 
 // setupWorktree performs post-creation setup for a new worktree.
-// This includes copying env files, creating symlinks, and running setup scripts.
-func (p *Plugin) setupWorktree(worktreePath, branchName string) error {
+// This includes copying env files, creating symlinks, and running the
+// provisioning steps and setup script. report, if set, receives step progress.
+func (p *Plugin) setupWorktree(worktreePath, branchName string, report provisionReporter) error {
 	config := DefaultSetupConfig()
 
 	// 1. Copy environment files
@@ -56,15 +56,8 @@ func (p *Plugin) setupWorktree(worktreePath, branchName string) error {
 		}
 	}
 
-	// 3. Run setup script (if exists)
-	if config.RunSetupScript {
-		if err := p.runSetupScript(worktreePath, branchName); err != nil {
-			p.ctx.Logger.Warn("setup script failed", "path", worktreePath, "error", err)
-			// Don't fail creation for setup script errors
-		}
-	}
-
-	return nil
+	// 3. Run provisioning steps, then the setup script (if exists)
+	return p.runProvisionSteps(worktreePath, branchName, p.provisionSteps(), report)
 }
 
 // copyEnvFiles copies environment files from the main worktree to the new worktree.
@@ -164,46 +157,3 @@ func (p *Plugin) symlinkDirs(worktreePath string, dirs []string) error {
 
 	return nil
 }
-
-// runSetupScript executes the .worktree-setup.sh script if it exists in the main repo.
-// The script runs with the new worktree as the working directory and receives
-// environment variables for the main worktree path, branch name, and worktree path.
-func (p *Plugin) runSetupScript(worktreePath, branchName string) error {
-	scriptPath := filepath.Join(p.ctx.WorkDir, setupScriptName)
-
-	// Check if setup script exists
-	if _, err := os.Stat(scriptPath); os.IsNotExist(err) {
-		return nil // No script, that's fine
-	}
-
-	// Run the script with the worktree as working directory
-	cmd := exec.Command("bash", scriptPath)
-	cmd.Dir = worktreePath
-
-	// Build isolated environment with overrides applied
-	isolatedEnv := ApplyEnvOverrides(os.Environ(), BuildEnvOverrides(p.ctx.WorkDir))
-
-	// Add worktree-specific variables
-	cmd.Env = append(isolatedEnv,
-		"MAIN_WORKTREE="+p.ctx.WorkDir,
-		"WORKTREE_BRANCH="+branchName,
-		"WORKTREE_PATH="+worktreePath,
-	)
-
-	// Capture output for logging
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		p.ctx.Logger.Warn("setup script failed",
-			"script", scriptPath,
-			"output", string(output),
-			"error", err)
-		return err
-	}
-
-	p.ctx.Logger.Debug("setup script completed",
-		"script", scriptPath,
-		"output", string(output))
-
-	return nil
-}
-
@@ -1,9 +1,15 @@
 package workspace
 
 import (
+	"log/slog"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
+
+	"github.com/marcus/sidecar/internal/config"
+	"github.com/marcus/sidecar/internal/plugin"
 )
 
 func TestCopyFile(t *testing.T) {
@@ -64,3 +70,140 @@ func TestDefaultSetupConfig(t *testing.T) {
 		t.Error("EnvFiles should have default values")
 	}
 }
+
+// newProvisionTest returns a plugin whose main worktree has a package-lock.json
+// and a node_modules cache, and an empty new worktree.
+func newProvisionTest(t *testing.T) (p *Plugin, mainDir, wtDir string) {
+	t.Helper()
+	mainDir, wtDir = t.TempDir(), t.TempDir()
+	write := func(path, content string) {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(filepath.Join(mainDir, "package-lock.json"), "v1")
+	write(filepath.Join(mainDir, "node_modules", "left-pad", "index.js"), "pad")
+	write(filepath.Join(wtDir, "package-lock.json"), "v1")
+	p = &Plugin{ctx: &plugin.Context{WorkDir: mainDir, ProjectRoot: mainDir, Logger: slog.Default()}}
+	return p, mainDir, wtDir
+}
+
+// recordProvision collects the final status of each step.
+func recordProvision(n int) ([]ProvisionStepStatus, provisionReporter) {
+	var mu sync.Mutex
+	statuses := make([]ProvisionStepStatus, n)
+	return statuses, func(i int, s ProvisionStepStatus) {
+		mu.Lock()
+		defer mu.Unlock()
+		statuses[i] = s
+	}
+}
+
+func TestRunProvisionSteps_CopyModes(t *testing.T) {
+	for _, mode := range []string{copyModeCopy, copyModeHardlink, copyModeAuto} {
+		t.Run(mode, func(t *testing.T) {
+			p, mainDir, wtDir := newProvisionTest(t)
+			steps := []config.ProvisionStep{{Copy: []string{"node_modules"}, CopyMode: mode, Lockfiles: []string{"package-lock.json"}}}
+			statuses, report := recordProvision(len(steps))
+
+			if err := p.runProvisionSteps(wtDir, "feature", steps, report); err != nil {
+				t.Fatalf("runProvisionSteps: %v", err)
+			}
+			if statuses[0].State != ProvisionDone {
+				t.Errorf("state = %s (%s), want done", statuses[0].State, statuses[0].Detail)
+			}
+			got, err := os.ReadFile(filepath.Join(wtDir, "node_modules", "left-pad", "index.js"))
+			if err != nil || string(got) != "pad" {
+				t.Fatalf("copied file = %q, %v", got, err)
+			}
+			if mode == copyModeHardlink {
+				src, _ := os.Stat(filepath.Join(mainDir, "node_modules", "left-pad", "index.js"))
+				dst, _ := os.Stat(filepath.Join(wtDir, "node_modules", "left-pad", "index.js"))
+				if !os.SameFile(src, dst) {
+					t.Error("hardlink mode should share the file with the main worktree")
+				}
+			}
+		})
+	}
+}
+
+func TestRunProvisionSteps_LockfileSkips(t *testing.T) {
+	p, _, wtDir := newProvisionTest(t)
+	if err := os.WriteFile(filepath.Join(wtDir, "package-lock.json"), []byte("v2"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	steps := []config.ProvisionStep{
+		{Name: "deps", Copy: []string{"node_modules"}, Lockfiles: []string{"package-lock.json"}},
+		{Name: "install", Run: "touch installed", Lockfiles: []string{"package-lock.json"}, Creates: []string{"node_modules"}},
+	}
+	statuses, report := recordProvision(len(steps))
+	if err := p.runProvisionSteps(wtDir, "feature", steps, report); err != nil {
+		t.Fatalf("runProvisionSteps: %v", err)
+	}
+
+	// Lockfiles differ: the cache is stale, so the install runs instead
+	if statuses[0].State != ProvisionSkipped || !strings.Contains(statuses[0].Detail, "lockfiles differ") {
+		t.Errorf("deps = %+v, want skipped for differing lockfiles", statuses[0])
+	}
+	if _, err := os.Stat(filepath.Join(wtDir, "node_modules")); !os.IsNotExist(err) {
+		t.Error("stale node_modules should not be copied")
+	}
+	if statuses[1].State != ProvisionDone {
+		t.Errorf("install = %+v, want done", statuses[1])
+	}
+	if _, err := os.Stat(filepath.Join(wtDir, "installed")); err != nil {
+		t.Error("install command should have run in the worktree")
+	}
+}
+
+func TestRunProvisionSteps_SkipsRunWhenCreatesExist(t *testing.T) {
+	p, _, wtDir := newProvisionTest(t)
+	steps := []config.ProvisionStep{
+		{Copy: []string{"node_modules"}, CopyMode: copyModeCopy, Lockfiles: []string{"package-lock.json"}},
+		{Run: "touch installed", Lockfiles: []string{"package-lock.json"}, Creates: []string{"node_modules"}},
+	}
+	statuses, report := recordProvision(len(steps))
+	if err := p.runProvisionSteps(wtDir, "feature", steps, report); err != nil {
+		t.Fatalf("runProvisionSteps: %v", err)
+	}
+	if statuses[1].State != ProvisionSkipped {
+		t.Errorf("install = %+v, want skipped after the cache copy", statuses[1])
+	}
+	if _, err := os.Stat(filepath.Join(wtDir, "installed")); !os.IsNotExist(err) {
+		t.Error("install command should not have run")
+	}
+}
+
+func TestRunProvisionSteps_ParallelAndFailures(t *testing.T) {
+	p, _, wtDir := newProvisionTest(t)
+	// The parallel pair waits on each other's files, so it only finishes
+	// when both steps run at the same time
+	steps := []config.ProvisionStep{
+		{Name: "a", Run: "touch a; for i in $(seq 500); do [ -e b ] && exit 0; sleep 0.01; done; exit 1", Parallel: true},
+		{Name: "b", Run: "touch b; for i in $(seq 500); do [ -e a ] && exit 0; sleep 0.01; done; exit 1", Parallel: true},
+		{Name: "broken", Run: "echo boom >&2; exit 3"},
+		{Name: "after", Run: "touch after"},
+	}
+	statuses, report := recordProvision(len(steps))
+	err := p.runProvisionSteps(wtDir, "feature", steps, report)
+	if err == nil || !strings.Contains(err.Error(), "broken") || !strings.Contains(err.Error(), "boom") {
+		t.Fatalf("error = %v, want the broken step's output", err)
+	}
+	for i, want := range []ProvisionState{ProvisionDone, ProvisionDone, ProvisionFailed, ProvisionDone} {
+		if statuses[i].State != want {
+			t.Errorf("step %s = %s, want %s", steps[i].Name, statuses[i].State, want)
+		}
+	}
+	if got := provisionFailures(statuses); got != 1 {
+		t.Errorf("provisionFailures = %d, want 1", got)
+	}
+}
+
+func TestCopyProvisionPaths_RejectsOutsidePaths(t *testing.T) {
+	if _, _, err := copyProvisionPaths(t.TempDir(), t.TempDir(), []string{"../x"}, copyModeCopy); err == nil {
+		t.Error("expected an error for a path outside the worktree")
+	}
+}
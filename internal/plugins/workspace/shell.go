@@ -15,7 +15,6 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/marcus/sidecar/internal/features"
 	"github.com/marcus/sidecar/internal/state"
-	"github.com/marcus/sidecar/internal/tty"
 )
 
 // Shell session constants
@@ -268,6 +267,7 @@ func (p *Plugin) shellFromDefinition(def ShellDefinition, isRunning bool) *Shell
 		ChosenAgent: definitionToAgentType(def.AgentType),
 		SkipPerms:   def.SkipPerms,
 		IsOrphaned:  !isRunning,
+		Queue:       def.Queue.Clone(),
 	}
 
 	if isRunning {
@@ -329,8 +329,7 @@ func (p *Plugin) discoverTmuxSessionNames() []string {
 	projectName := filepath.Base(p.ctx.WorkDir)
 	basePrefix := shellSessionPrefix + sanitizeName(projectName)
 
-	cmd := exec.Command("tmux", "list-sessions", "-F", "#{session_name}")
-	output, err := cmd.Output()
+	sessions, err := sessionBackend.List()
 	if err != nil {
 		return nil
 	}
@@ -338,11 +337,7 @@ func (p *Plugin) discoverTmuxSessionNames() []string {
 	var result []string
 	indexPattern := regexp.MustCompile(`^` + regexp.QuoteMeta(basePrefix) + `(?:-(\d+))?$`)
 
-	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
-		line = strings.TrimSpace(line)
-		if line == "" {
-			continue
-		}
+	for _, line := range sessions {
 		if indexPattern.MatchString(line) {
 			result = append(result, line)
 		}
@@ -411,6 +406,7 @@ func (p *Plugin) applyManifestSync() {
 			existing.ChosenAgent = definitionToAgentType(def.AgentType)
 			existing.SkipPerms = def.SkipPerms
 			existing.IsOrphaned = !isRunning
+			existing.Queue = def.Queue.Clone()
 			newShells = append(newShells, existing)
 		} else {
 			// New shell from manifest
@@ -433,6 +429,7 @@ func (p *Plugin) applyManifestSync() {
 	}
 
 	p.shells = newShells
+	p.attachWorktreeQueues()
 
 	// Adjust selection if needed
 	if p.shellSelected && p.selectedShellIdx >= len(p.shells) {
@@ -518,7 +515,7 @@ func (p *Plugin) generateShellSessionName() string {
 // createNewShell creates a new shell session. If customName is non-empty, it is
 // used as the display name instead of the auto-generated "Shell N".
 func (p *Plugin) createNewShell(customName string) tea.Cmd {
-	if !isTmuxInstalled() {
+	if !sessionBackend.Available() {
 		return func() tea.Msg {
 			return ShellCreatedMsg{Err: fmt.Errorf("tmux not installed: %s", getTmuxInstallInstructions())}
 		}
@@ -539,14 +536,7 @@ func (p *Plugin) createNewShell(customName string) tea.Cmd {
 		}
 
 		// Create new detached session in project directory
-		args := []string{
-			"new-session",
-			"-d",              // Detached
-			"-s", sessionName, // Session name
-			"-c", workDir, // Working directory
-		}
-		cmd := exec.Command("tmux", args...)
-		if err := cmd.Run(); err != nil {
+		if err := sessionBackend.Create(sessionName, workDir, 0, 0); err != nil {
 			return ShellCreatedMsg{
 				SessionName: sessionName,
 				DisplayName: displayName,
@@ -554,9 +544,6 @@ func (p *Plugin) createNewShell(customName string) tea.Cmd {
 			}
 		}
 
-		// Ensure server persists when all sessions are killed
-		ensureTmuxServerConfig()
-
 		// Capture pane ID for interactive mode support
 		paneID := getPaneID(sessionName)
 
@@ -573,7 +560,7 @@ func (p *Plugin) createShellWithAgent() tea.Cmd {
 	agentType := p.typeSelectorAgentType
 	skipPerms := p.typeSelectorSkipPerms
 
-	if !isTmuxInstalled() {
+	if !sessionBackend.Available() {
 		return func() tea.Msg {
 			return ShellCreatedMsg{Err: fmt.Errorf("tmux not installed: %s", getTmuxInstallInstructions())}
 		}
@@ -600,14 +587,7 @@ func (p *Plugin) createShellWithAgent() tea.Cmd {
 		}
 
 		// Create new detached session in project directory
-		args := []string{
-			"new-session",
-			"-d",              // Detached
-			"-s", sessionName, // Session name
-			"-c", workDir, // Working directory
-		}
-		cmd := exec.Command("tmux", args...)
-		if err := cmd.Run(); err != nil {
+		if err := sessionBackend.Create(sessionName, workDir, 0, 0); err != nil {
 			return ShellCreatedMsg{
 				SessionName: sessionName,
 				DisplayName: displayName,
@@ -615,9 +595,6 @@ func (p *Plugin) createShellWithAgent() tea.Cmd {
 			}
 		}
 
-		// Ensure server persists when all sessions are killed
-		ensureTmuxServerConfig()
-
 		// Capture pane ID for interactive mode support
 		paneID := getPaneID(sessionName)
 
@@ -648,13 +625,8 @@ func (p *Plugin) recreateOrphanedShell(idx int) tea.Cmd {
 	previewWidth, previewHeight := p.calculatePreviewDimensions()
 
 	return func() tea.Msg {
-		// Create new detached session
-		args := []string{"new-session", "-d", "-s", sessionName, "-c", workDir}
-		if previewWidth > 0 && previewHeight > 0 {
-			args = append(args, "-x", strconv.Itoa(previewWidth), "-y", strconv.Itoa(previewHeight))
-		}
-		cmd := exec.Command("tmux", args...)
-		if err := cmd.Run(); err != nil {
+		// Create new detached session sized to the preview pane
+		if err := sessionBackend.Create(sessionName, workDir, previewWidth, previewHeight); err != nil {
 			return ShellCreatedMsg{
 				SessionName: sessionName,
 				DisplayName: shell.Name,
@@ -662,11 +634,6 @@ func (p *Plugin) recreateOrphanedShell(idx int) tea.Cmd {
 			}
 		}
 
-		// Ensure server persists when all sessions are killed
-		ensureTmuxServerConfig()
-
-		tty.SetWindowSizeManual(sessionName)
-
 		// Capture pane ID
 		paneID := getPaneID(sessionName)
 
@@ -710,8 +677,7 @@ func (p *Plugin) startAgentInShell(tmuxName string, agentType AgentType, skipPer
 		}
 
 		// Send the command to the shell's tmux session
-		cmd := exec.Command("tmux", "send-keys", "-t", tmuxName, baseCmd, "Enter")
-		if err := cmd.Run(); err != nil {
+		if err := sendSessionLine(tmuxName, baseCmd); err != nil {
 			return ShellAgentErrorMsg{
 				TmuxName: tmuxName,
 				Err:      fmt.Errorf("failed to start agent: %w", err),
@@ -768,20 +734,13 @@ func (p *Plugin) ensureShellAndAttachByIndex(idx int) tea.Cmd {
 	previewWidth, previewHeight := p.calculatePreviewDimensions()
 	return tea.Sequence(
 		func() tea.Msg {
-			args := []string{"new-session", "-d", "-s", sessionName, "-c", workDir}
-			if previewWidth > 0 && previewHeight > 0 {
-				args = append(args, "-x", strconv.Itoa(previewWidth), "-y", strconv.Itoa(previewHeight))
-			}
-			cmd := exec.Command("tmux", args...)
-			if err := cmd.Run(); err != nil {
+			if err := sessionBackend.Create(sessionName, workDir, previewWidth, previewHeight); err != nil {
 				return ShellCreatedMsg{
 					SessionName: sessionName,
 					DisplayName: shell.Name,
 					Err:         fmt.Errorf("recreate shell session: %w", err),
 				}
 			}
-			ensureTmuxServerConfig()
-			tty.SetWindowSizeManual(sessionName)
 			// Capture pane ID for interactive mode support
 			paneID := getPaneID(sessionName)
 			return ShellCreatedMsg{SessionName: sessionName, DisplayName: shell.Name, PaneID: paneID}
@@ -826,8 +785,7 @@ func (p *Plugin) killShellSessionByName(sessionName string) tea.Cmd {
 
 	return func() tea.Msg {
 		// Kill the session
-		cmd := exec.Command("tmux", "kill-session", "-t", sessionName)
-		_ = cmd.Run() // Ignore errors (session may already be dead)
+		_ = sessionBackend.Kill(sessionName) // Ignore errors (session may already be dead)
 
 		// Clean up pane cache
 		globalPaneCache.remove(sessionName)
@@ -1017,15 +975,14 @@ func (p *Plugin) createShellWithResume(resumeCmd string) tea.Cmd {
 
 // sendResumeCommandToShell injects a command into the shell without executing it.
 func (p *Plugin) sendResumeCommandToShell(tmuxSession string, resumeCmd string) tea.Cmd {
-	if !isTmuxInstalled() {
+	if !sessionBackend.Available() {
 		return nil
 	}
 
 	return func() tea.Msg {
-		// Use tmux send-keys to type the command without pressing Enter
+		// Type the command without pressing Enter
 		// This lets the user review before executing
-		cmd := exec.Command("tmux", "send-keys", "-t", tmuxSession, resumeCmd)
-		if err := cmd.Run(); err != nil {
+		if err := sessionBackend.SendLiteral(tmuxSession, resumeCmd); err != nil {
 			return shellResumeErrorMsg{Err: err}
 		}
 		return shellResumeInjectedMsg{TmuxSession: tmuxSession}
@@ -1067,7 +1024,7 @@ func (p *Plugin) createWorktreeWithResume(msg ResumeConversationMsg) tea.Cmd {
 
 	return func() tea.Msg {
 		// Create the worktree (reuse doCreateWorktree)
-		wt, err := p.doCreateWorktree(name, baseBranch, "", "", agentType)
+		wt, err := p.doCreateWorktree(name, baseBranch, "", "", agentType, nil)
 		if err != nil {
 			return worktreeResumeCreatedMsg{Err: err}
 		}
@@ -1088,8 +1045,7 @@ func (p *Plugin) startAgentWithResumeCmd(wt *Worktree, agentType AgentType, skip
 		sessionName := tmuxSessionPrefix + sanitizeName(wt.Name)
 
 		// Check if session already exists
-		checkCmd := exec.Command("tmux", "has-session", "-t", sessionName)
-		if checkCmd.Run() == nil {
+		if sessionExists(sessionName) {
 			// Session exists - should not happen for new resume worktree
 			paneID := getPaneID(sessionName)
 			return AgentStartedMsg{
@@ -1103,40 +1059,27 @@ func (p *Plugin) startAgentWithResumeCmd(wt *Worktree, agentType AgentType, skip
 		}
 
 		// Create new detached session with working directory
-		args := []string{
-			"new-session",
-			"-d",              // Detached
-			"-s", sessionName, // Session name
-			"-c", wt.Path, // Working directory
-		}
-
-		cmd := exec.Command("tmux", args...)
-		if err := cmd.Run(); err != nil {
+		if err := sessionBackend.Create(sessionName, wt.Path, 0, 0); err != nil {
 			return AgentStartedMsg{Epoch: epoch, Err: fmt.Errorf("create session: %w", err)}
 		}
 
-		// Set history limit for scrollback capture
-		_ = exec.Command("tmux", "set-option", "-t", sessionName, "history-limit",
-			strconv.Itoa(tmuxHistoryLimit)).Run()
-
 		// Set TD_SESSION_ID environment variable for td session tracking
 		tdEnvCmd := fmt.Sprintf("export TD_SESSION_ID=%s", shellQuote(sessionName))
-		_ = exec.Command("tmux", "send-keys", "-t", sessionName, tdEnvCmd, "Enter").Run()
+		_ = sendSessionLine(sessionName, tdEnvCmd)
 
 		// Apply environment isolation
-		envOverrides := BuildEnvOverrides(p.ctx.WorkDir)
+		envOverrides := BuildWorktreeEnvOverrides(p.ctx.WorkDir, p.ctx.ProjectRoot, wt.Path, wt.Branch)
 		if envCmd := GenerateSingleEnvCommand(envOverrides); envCmd != "" {
-			_ = exec.Command("tmux", "send-keys", "-t", sessionName, envCmd, "Enter").Run()
+			_ = sendSessionLine(sessionName, envCmd)
 		}
 
 		// Small delay to ensure env is set
 		time.Sleep(100 * time.Millisecond)
 
 		// Send the resume command instead of the normal agent command
-		sendCmd := exec.Command("tmux", "send-keys", "-t", sessionName, resumeCmd, "Enter")
-		if err := sendCmd.Run(); err != nil {
+		if err := sendSessionLine(sessionName, resumeCmd); err != nil {
 			// Try to kill the session if we failed to start the agent
-			_ = exec.Command("tmux", "kill-session", "-t", sessionName).Run()
+			_ = sessionBackend.Kill(sessionName)
 			return AgentStartedMsg{Epoch: epoch, Err: fmt.Errorf("start agent with resume: %w", err)}
 		}
 
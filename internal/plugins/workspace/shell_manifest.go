@@ -17,6 +17,9 @@ import (
 type ShellManifest struct {
 	Version int               `json:"version"`
 	Shells  []ShellDefinition `json:"shells"`
+	// WorktreeQueues holds pending prompts for worktree agents, keyed by
+	// worktree name. Shell queues live on their ShellDefinition.
+	WorktreeQueues map[string]*PromptQueue `json:"worktreeQueues,omitempty"`
 
 	path string     // not serialized - file path
 	mu   sync.Mutex // protects concurrent access
@@ -24,11 +27,12 @@ type ShellManifest struct {
 
 // ShellDefinition contains all info needed to recreate a shell session.
 type ShellDefinition struct {
-	TmuxName    string    `json:"tmuxName"`
-	DisplayName string    `json:"displayName"`
-	CreatedAt   time.Time `json:"createdAt"`
-	AgentType   string    `json:"agentType,omitempty"`
-	SkipPerms   bool      `json:"skipPerms,omitempty"`
+	TmuxName    string       `json:"tmuxName"`
+	DisplayName string       `json:"displayName"`
+	CreatedAt   time.Time    `json:"createdAt"`
+	AgentType   string       `json:"agentType,omitempty"`
+	SkipPerms   bool         `json:"skipPerms,omitempty"`
+	Queue       *PromptQueue `json:"queue,omitempty"`
 }
 
 // manifestVersion is the current manifest format version.
@@ -164,6 +168,33 @@ func (m *ShellManifest) UpdateShell(def ShellDefinition) error {
 	return m.saveLocked()
 }
 
+// WorktreeQueue returns a copy of the prompt queue stored for a worktree,
+// or nil if it has none.
+func (m *ShellManifest) WorktreeQueue(name string) *PromptQueue {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.WorktreeQueues[name].Clone()
+}
+
+// SetWorktreeQueue stores a worktree's prompt queue and saves.
+// An empty, unpaused queue removes the entry.
+func (m *ShellManifest) SetWorktreeQueue(name string, q *PromptQueue) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if q.IsZero() {
+		if _, ok := m.WorktreeQueues[name]; !ok {
+			return nil
+		}
+		delete(m.WorktreeQueues, name)
+		return m.saveLocked()
+	}
+	if m.WorktreeQueues == nil {
+		m.WorktreeQueues = make(map[string]*PromptQueue)
+	}
+	m.WorktreeQueues[name] = q.Clone()
+	return m.saveLocked()
+}
+
 // Path returns the manifest file path.
 func (m *ShellManifest) Path() string {
 	return m.path
@@ -237,6 +268,7 @@ func shellToDefinition(shell *ShellSession) ShellDefinition {
 		CreatedAt:   shell.CreatedAt,
 		AgentType:   agentType,
 		SkipPerms:   shell.SkipPerms,
+		Queue:       shell.Queue.Clone(),
 	}
 }
 
@@ -450,3 +450,41 @@ func TestShellManifest_LockAcquisitionNonBlocking(t *testing.T) {
 		}
 	}
 }
+
+func TestShellManifest_Queues(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".sidecar", "shells.json")
+	m, _ := LoadShellManifest(path)
+
+	if err := m.AddShell(ShellDefinition{
+		TmuxName: "sidecar-sh-test-1",
+		Queue:    &PromptQueue{Items: []string{"add tests"}, Paused: true},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.SetWorktreeQueue("auth", &PromptQueue{Items: []string{"fix lint", "write docs"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, _ := LoadShellManifest(path)
+	if q := loaded.FindShell("sidecar-sh-test-1").Queue; q.Len() != 1 || !q.Paused {
+		t.Errorf("shell queue = %+v", q)
+	}
+	q := loaded.WorktreeQueue("auth")
+	if q.Len() != 2 || q.Items[1] != "write docs" {
+		t.Fatalf("worktree queue = %+v", q)
+	}
+	// WorktreeQueue hands out copies
+	q.Items[0] = "changed"
+	if loaded.WorktreeQueue("auth").Items[0] != "fix lint" {
+		t.Error("WorktreeQueue should return a copy")
+	}
+
+	// An empty queue removes the entry
+	if err := loaded.SetWorktreeQueue("auth", &PromptQueue{}); err != nil {
+		t.Fatal(err)
+	}
+	reloaded, _ := LoadShellManifest(path)
+	if reloaded.WorktreeQueue("auth") != nil {
+		t.Error("empty queue should be removed from manifest")
+	}
+}
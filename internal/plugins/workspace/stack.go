@@ -0,0 +1,321 @@
+package workspace
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	appmsg "github.com/marcus/sidecar/internal/msg"
+)
+
+// A worktree whose BaseBranch is another worktree's branch is stacked on
+// that worktree. Stacks are derived from the worktree list on demand, so
+// they follow base branch changes without separate bookkeeping.
+
+// stackPRsCreatedMsg reports the PRs opened or updated for a stack.
+type stackPRsCreatedMsg struct {
+	URLs map[string]string // Worktree name -> PR URL
+	Errs []string
+}
+
+// stackRetargetedMsg reports children moved onto the branch their merged
+// parent went into.
+type stackRetargetedMsg struct {
+	Parent   string   // Merged worktree
+	Base     string   // New base branch of the children
+	Children []string // Retargeted worktrees
+	Errs     []string
+}
+
+// stackParent returns the worktree wt is stacked on, or nil.
+func (p *Plugin) stackParent(wt *Worktree) *Worktree {
+	if wt == nil || wt.IsMain || wt.BaseBranch == "" {
+		return nil
+	}
+	for _, other := range p.worktrees {
+		if other != wt && !other.IsMain && other.Branch == wt.BaseBranch {
+			return other
+		}
+	}
+	return nil
+}
+
+// stackChildren returns the worktrees stacked directly on wt.
+func (p *Plugin) stackChildren(wt *Worktree) []*Worktree {
+	if wt == nil || wt.IsMain || wt.Branch == "" {
+		return nil
+	}
+	var children []*Worktree
+	for _, other := range p.worktrees {
+		if other != wt && !other.IsMain && other.BaseBranch == wt.Branch {
+			children = append(children, other)
+		}
+	}
+	return children
+}
+
+// stackRoot returns the bottom worktree of wt's stack (wt if unstacked).
+func (p *Plugin) stackRoot(wt *Worktree) *Worktree {
+	seen := map[*Worktree]bool{wt: true}
+	for {
+		parent := p.stackParent(wt)
+		if parent == nil || seen[parent] {
+			return wt
+		}
+		seen[parent] = true
+		wt = parent
+	}
+}
+
+// stackFrom returns wt and every worktree stacked above it, parents first.
+func (p *Plugin) stackFrom(wt *Worktree) []*Worktree {
+	members := []*Worktree{wt}
+	seen := map[*Worktree]bool{wt: true}
+	for i := 0; i < len(members); i++ {
+		for _, child := range p.stackChildren(members[i]) {
+			if !seen[child] {
+				seen[child] = true
+				members = append(members, child)
+			}
+		}
+	}
+	return members
+}
+
+// stackDepth returns how many worktrees wt is stacked on.
+func (p *Plugin) stackDepth(wt *Worktree) int {
+	depth := 0
+	seen := map[*Worktree]bool{wt: true}
+	for parent := p.stackParent(wt); parent != nil && !seen[parent]; parent = p.stackParent(parent) {
+		seen[parent] = true
+		depth++
+	}
+	return depth
+}
+
+// isStacked reports whether wt is part of a stack.
+func (p *Plugin) isStacked(wt *Worktree) bool {
+	return p.stackParent(wt) != nil || len(p.stackChildren(wt)) > 0
+}
+
+// stackOrder sorts worktrees so stack parents come before their children,
+// keeping the given order otherwise.
+func (p *Plugin) stackOrder(wts []*Worktree) []*Worktree {
+	ordered := make([]*Worktree, 0, len(wts))
+	for depth := 0; len(ordered) < len(wts); depth++ {
+		for _, wt := range wts {
+			if p.stackDepth(wt) == depth {
+				ordered = append(ordered, wt)
+			}
+		}
+	}
+	return ordered
+}
+
+// stackLabel describes where wt sits in its stack for the sidebar, e.g.
+// "↳ auth-api" for a worktree stacked on auth-api. Returns "" if unstacked.
+func (p *Plugin) stackLabel(wt *Worktree) string {
+	if parent := p.stackParent(wt); parent != nil {
+		return "↳ " + parent.Name
+	}
+	if n := len(p.stackChildren(wt)); n > 0 {
+		return fmt.Sprintf("⧉ %d stacked", n)
+	}
+	return ""
+}
+
+// createStackPRs pushes every branch in the selected worktree's stack and
+// opens a PR for each, bottom-up, targeting its parent branch. Existing PRs
+// are retargeted to the parent instead.
+func (p *Plugin) createStackPRs() tea.Cmd {
+	wt := p.selectedWorktree()
+	if wt == nil || wt.IsMain || wt.IsMissing {
+		return nil
+	}
+	type stackPR struct {
+		name, path, branch, base, parent string
+	}
+	var prs []stackPR
+	for _, member := range p.stackFrom(p.stackRoot(wt)) {
+		pr := stackPR{name: member.Name, path: member.Path, branch: member.Branch, base: resolveBaseBranch(member)}
+		if parent := p.stackParent(member); parent != nil {
+			pr.parent = parent.Name
+		}
+		prs = append(prs, pr)
+	}
+
+	return func() tea.Msg {
+		msg := stackPRsCreatedMsg{URLs: make(map[string]string)}
+		for _, pr := range prs {
+			if pr.parent != "" && msg.URLs[pr.parent] == "" {
+				msg.Errs = append(msg.Errs, fmt.Sprintf("%s: parent has no PR", pr.name))
+				continue
+			}
+			// Restacked branches were rewritten, so a plain push can be rejected
+			if err := doPush(pr.path, pr.branch, true, true); err != nil {
+				msg.Errs = append(msg.Errs, fmt.Sprintf("%s: %v", pr.name, err))
+				continue
+			}
+			url, err := openStackPR(pr.path, pr.branch, pr.base, msg.URLs[pr.parent])
+			if err != nil {
+				msg.Errs = append(msg.Errs, fmt.Sprintf("%s: %v", pr.name, err))
+				continue
+			}
+			msg.URLs[pr.name] = url
+		}
+		return msg
+	}
+}
+
+// openStackPR creates a PR for branch targeting base, noting the parent PR
+// in its body. If the PR already exists its base is updated instead.
+func openStackPR(dir, branch, base, parentURL string) (string, error) {
+	title, body := buildFallbackPRDescription(branch, getCommitLogForPR(dir, base), getDiffStatForPR(dir, base))
+	if parentURL != "" {
+		body = fmt.Sprintf("Stacked on %s. Merge that PR first.\n\n%s", parentURL, body)
+	}
+	cmd := exec.Command("gh", "pr", "create", "--title", title, "--body", body, "--base", base, "--head", branch)
+	cmd.Dir = dir
+	output, err := cmd.CombinedOutput()
+	if err == nil {
+		return strings.TrimSpace(string(output)), nil
+	}
+	url, found := parseExistingPRURL(string(output))
+	if !found {
+		return "", fmt.Errorf("gh pr create: %s", strings.TrimSpace(string(output)))
+	}
+	edit := exec.Command("gh", "pr", "edit", url, "--base", base)
+	edit.Dir = dir
+	if output, err := edit.CombinedOutput(); err != nil {
+		return url, fmt.Errorf("gh pr edit: %s", strings.TrimSpace(string(output)))
+	}
+	return url, nil
+}
+
+// handleStackPRsCreated records the PR URLs and reports the outcome.
+func (p *Plugin) handleStackPRsCreated(msg stackPRsCreatedMsg) tea.Cmd {
+	for name, url := range msg.URLs {
+		if wt := p.findWorktree(name); wt != nil {
+			wt.PRURL = url
+			_ = savePRURL(p.ctx.ProjectRoot, wt.Path, url)
+		}
+	}
+	if len(msg.Errs) > 0 {
+		return appmsg.ShowToast(fmt.Sprintf("Stack PRs: %d opened, %s", len(msg.URLs), strings.Join(msg.Errs, "; ")), 5*time.Second)
+	}
+	return appmsg.ShowToast(fmt.Sprintf("Opened %d stacked PRs", len(msg.URLs)), 2*time.Second)
+}
+
+// retargetStackChildren moves the worktrees stacked on a merged worktree
+// onto the branch it was merged into: their base branch and PR base are
+// updated, and their own commits are rebased onto the merge result.
+func (p *Plugin) retargetStackChildren(wt *Worktree, target string) tea.Cmd {
+	children := p.stackChildren(wt)
+	if len(children) == 0 {
+		return nil
+	}
+	type child struct {
+		name, path, branch string
+		hasPR              bool
+	}
+	var targets []child
+	for _, c := range children {
+		targets = append(targets, child{name: c.Name, path: c.Path, branch: c.Branch, hasPR: c.PRURL != ""})
+	}
+	projectRoot := p.ctx.ProjectRoot
+	repoDir := p.ctx.WorkDir
+	parent, parentBranch := wt.Name, wt.Branch
+
+	return func() tea.Msg {
+		msg := stackRetargetedMsg{Parent: parent, Base: target}
+		// The parent's commits end the part of each child that is not its own
+		parentTip, err := gitOutput(repoDir, "rev-parse", "refs/heads/"+parentBranch)
+		if err != nil {
+			msg.Errs = append(msg.Errs, err.Error())
+			return msg
+		}
+		// A merged PR only updated the remote branch
+		onto := target
+		if _, err := gitOutput(repoDir, "fetch", "origin", target); err == nil {
+			onto = "origin/" + target
+		}
+
+		for _, c := range targets {
+			if err := saveBaseBranch(projectRoot, c.path, target); err != nil {
+				msg.Errs = append(msg.Errs, fmt.Sprintf("%s: %v", c.name, err))
+				continue
+			}
+			msg.Children = append(msg.Children, c.name)
+			if c.hasPR {
+				edit := exec.Command("gh", "pr", "edit", c.branch, "--base", target)
+				edit.Dir = c.path
+				if output, err := edit.CombinedOutput(); err != nil {
+					msg.Errs = append(msg.Errs, fmt.Sprintf("%s: gh pr edit: %s", c.name, strings.TrimSpace(string(output))))
+				}
+			}
+			if err := restackOnto(c.path, onto, parentTip); err != nil {
+				msg.Errs = append(msg.Errs, fmt.Sprintf("%s: %v", c.name, err))
+			}
+		}
+		return msg
+	}
+}
+
+// restackOnto replays the commits in dir after upstream onto onto, dropping
+// the parent's commits (which may have been squashed into onto). Conflicts
+// abort the rebase, leaving the branch to be synced by hand.
+func restackOnto(dir, onto, upstream string) error {
+	status, err := gitOutput(dir, "status", "--porcelain", "--untracked-files=no")
+	if err != nil {
+		return err
+	}
+	if status != "" {
+		return fmt.Errorf("uncommitted changes, not rebased")
+	}
+	cmd := exec.Command("git", "rebase", "--onto", onto, upstream)
+	cmd.Dir = dir
+	if _, err := cmd.CombinedOutput(); err != nil {
+		_, _ = gitOutput(dir, "rebase", "--abort")
+		return fmt.Errorf("rebase onto %s hit conflicts; press u to resolve", onto)
+	}
+	return nil
+}
+
+// handleStackRetargeted applies the new base branches and offers the next
+// worktree up the stack for merging.
+func (p *Plugin) handleStackRetargeted(msg stackRetargetedMsg) tea.Cmd {
+	for _, name := range msg.Children {
+		if wt := p.findWorktree(name); wt != nil {
+			wt.BaseBranch = msg.Base
+		}
+	}
+	if p.mergeState != nil && p.mergeState.Worktree.Name == msg.Parent && len(msg.Children) > 0 {
+		p.mergeState.StackNext = msg.Children[0]
+		p.clearMergeModal()
+	}
+
+	cmds := []tea.Cmd{func() tea.Msg { return RefreshMsg{} }}
+	if len(msg.Errs) > 0 {
+		cmds = append(cmds, appmsg.ShowToast("Retarget stack: "+strings.Join(msg.Errs, "; "), 5*time.Second))
+	} else {
+		cmds = append(cmds, appmsg.ShowToast(fmt.Sprintf("Moved %s onto %s", strings.Join(msg.Children, ", "), msg.Base), 3*time.Second))
+	}
+	return tea.Batch(cmds...)
+}
+
+// mergeNextInStack closes the finished merge workflow and starts it for the
+// next worktree up the stack.
+func (p *Plugin) mergeNextInStack() tea.Cmd {
+	if p.mergeState == nil || p.mergeState.StackNext == "" {
+		return nil
+	}
+	next := p.findWorktree(p.mergeState.StackNext)
+	p.cancelMergeWorkflow()
+	p.clearMergeModal()
+	if next == nil {
+		return nil
+	}
+	return p.startMergeWorkflow(next)
+}
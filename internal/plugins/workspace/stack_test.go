@@ -0,0 +1,117 @@
+package workspace
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// stackPlugin returns a plugin with main, a stack api <- ui <- polish, and
+// an unrelated worktree docs.
+func stackPlugin() (*Plugin, map[string]*Worktree) {
+	wts := map[string]*Worktree{
+		"main":   {Name: "main", Branch: "main", IsMain: true},
+		"api":    {Name: "api", Branch: "feat/api", BaseBranch: "main"},
+		"ui":     {Name: "ui", Branch: "feat/ui", BaseBranch: "feat/api"},
+		"polish": {Name: "polish", Branch: "feat/polish", BaseBranch: "feat/ui"},
+		"docs":   {Name: "docs", Branch: "docs", BaseBranch: "main"},
+	}
+	p := &Plugin{worktrees: []*Worktree{wts["main"], wts["polish"], wts["docs"], wts["ui"], wts["api"]}}
+	return p, wts
+}
+
+func names(wts []*Worktree) string {
+	var out []string
+	for _, wt := range wts {
+		out = append(out, wt.Name)
+	}
+	return strings.Join(out, ",")
+}
+
+func TestStackGraph(t *testing.T) {
+	p, wts := stackPlugin()
+
+	if parent := p.stackParent(wts["ui"]); parent != wts["api"] {
+		t.Errorf("stackParent(ui) = %v, want api", parent)
+	}
+	if parent := p.stackParent(wts["api"]); parent != nil {
+		t.Errorf("stackParent(api) = %v, want nil: main is not a stack member", parent)
+	}
+	if root := p.stackRoot(wts["polish"]); root != wts["api"] {
+		t.Errorf("stackRoot(polish) = %v, want api", root)
+	}
+	if got := names(p.stackFrom(wts["api"])); got != "api,ui,polish" {
+		t.Errorf("stackFrom(api) = %s", got)
+	}
+	if got := names(p.stackOrder(p.worktrees[1:])); got != "docs,api,ui,polish" {
+		t.Errorf("stackOrder = %s", got)
+	}
+	if p.isStacked(wts["docs"]) || !p.isStacked(wts["api"]) {
+		t.Error("isStacked: want api stacked, docs not")
+	}
+	if got := p.stackLabel(wts["ui"]); got != "↳ api" {
+		t.Errorf("stackLabel(ui) = %q", got)
+	}
+
+	// A cycle must not hang the graph walks
+	wts["api"].BaseBranch = "feat/polish"
+	if root := p.stackRoot(wts["ui"]); root == nil {
+		t.Error("stackRoot on a cycle returned nil")
+	}
+	if got := len(p.stackOrder(p.worktrees[1:])); got != 4 {
+		t.Errorf("stackOrder on a cycle returned %d worktrees, want 4", got)
+	}
+}
+
+func TestSyncTargets_SkipsAboveFailedParent(t *testing.T) {
+	repo, _ := initSyncRepo(t, "shared.txt", "shared.txt")
+	clean, _ := initSyncRepo(t, "feature.txt", "shared.txt")
+
+	results := syncTargets([]syncTarget{
+		{name: "api", path: repo, base: "main"},
+		{name: "ui", path: clean, base: "main", parent: "api"},
+		{name: "polish", path: clean, base: "main", parent: "ui"},
+		{name: "docs", path: clean, base: "main"},
+	}, syncRebase)
+
+	if results[0].Err == nil {
+		t.Fatalf("api: %+v, want conflict", results[0])
+	}
+	for _, res := range results[1:3] {
+		if res.Skipped != "parent not synced" {
+			t.Errorf("%s: %+v, want skipped", res.Name, res)
+		}
+	}
+	if results[3].Err != nil || results[3].Skipped != "" {
+		t.Errorf("docs: %+v, want synced", results[3])
+	}
+}
+
+func TestRestackOnto(t *testing.T) {
+	// feature is one commit on top of main; stack child on it, then squash
+	// feature into main the way a merged PR would
+	repo, run := initSyncRepo(t, "feature.txt", "shared.txt")
+	run("checkout", "-q", "-b", "child")
+	if err := os.WriteFile(filepath.Join(repo, "child.txt"), []byte("child\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	run("add", ".")
+	run("commit", "-q", "-m", "child")
+	parentTip := run("rev-parse", "feature")
+
+	run("checkout", "-q", "main")
+	run("merge", "-q", "--squash", "feature")
+	run("commit", "-q", "-m", "squashed feature")
+	run("checkout", "-q", "child")
+
+	if err := restackOnto(repo, "main", parentTip); err != nil {
+		t.Fatalf("restackOnto: %v", err)
+	}
+	if got := run("rev-list", "--count", "main..HEAD"); got != "1" {
+		t.Errorf("commits on top of main = %s, want only the child's", got)
+	}
+	if got := run("log", "-1", "--format=%s"); got != "child" {
+		t.Errorf("HEAD = %q, want child commit", got)
+	}
+}
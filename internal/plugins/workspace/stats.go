@@ -37,14 +37,48 @@ func computeStats(workdir string) (*GitStats, error) {
 	return stats, nil
 }
 
+// computeBranchStats calculates git stats for everything a worktree changed
+// since it forked from baseBranch: commits plus uncommitted work.
+func computeBranchStats(workdir, baseBranch string) (*GitStats, error) {
+	stats := &GitStats{}
+
+	since := baseBranch
+	cmd := exec.Command("git", "merge-base", "HEAD", baseBranch)
+	cmd.Dir = workdir
+	if output, err := cmd.Output(); err == nil {
+		since = strings.TrimSpace(string(output))
+	}
+	if err := getDiffStatsSince(workdir, since, stats); err != nil {
+		return nil, err
+	}
+
+	// Ahead/behind relative to the base branch (non-fatal)
+	cmd = exec.Command("git", "rev-list", "--left-right", "--count", baseBranch+"...HEAD")
+	cmd.Dir = workdir
+	if output, err := cmd.Output(); err == nil {
+		parts := strings.Fields(strings.TrimSpace(string(output)))
+		if len(parts) == 2 {
+			stats.Behind, _ = strconv.Atoi(parts[0])
+			stats.Ahead, _ = strconv.Atoi(parts[1])
+		}
+	}
+
+	return stats, nil
+}
+
 // getDiffStats computes additions/deletions from git diff.
 func getDiffStats(workdir string, stats *GitStats) error {
+	return getDiffStatsSince(workdir, "HEAD", stats)
+}
+
+// getDiffStatsSince computes additions/deletions of the working tree against rev.
+func getDiffStatsSince(workdir, rev string, stats *GitStats) error {
 	// Use --numstat for reliable parsing of tracked file changes (staged + unstaged)
-	cmd := exec.Command("git", "diff", "--numstat", "HEAD")
+	cmd := exec.Command("git", "diff", "--numstat", rev)
 	cmd.Dir = workdir
 	output, err := cmd.Output()
 	if err != nil {
-		// No HEAD yet or other error, try without HEAD
+		// No HEAD yet or other error, try without a revision
 		cmd = exec.Command("git", "diff", "--numstat")
 		cmd.Dir = workdir
 		output, _ = cmd.Output()
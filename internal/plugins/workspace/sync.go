@@ -0,0 +1,494 @@
+package workspace
+
+import (
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	appmsg "github.com/marcus/sidecar/internal/msg"
+	"github.com/marcus/sidecar/internal/plugin"
+)
+
+// Sync strategies for bringing a worktree up to date with its base branch.
+const (
+	syncRebase = "rebase"
+	syncMerge  = "merge"
+)
+
+// SyncState tracks a worktree whose sync with its base branch hit conflicts.
+type SyncState struct {
+	Worktree  *Worktree
+	Base      string
+	Strategy  string   // Strategy that hit the conflicts
+	Conflicts []string // Conflicted files
+	Err       error
+	Summary   string // Concise error from summarizeGitError
+	Running   bool   // A resolution is in flight
+	Resolving string // Rebase or merge left in progress in the worktree
+	Handoff   bool   // Hand the conflicts to the agent once the resolution stops on them
+}
+
+// syncTarget is a worktree to sync.
+type syncTarget struct {
+	name, path, base string
+	parent           string // Stack parent synced earlier in the same batch
+}
+
+// syncResult is the outcome of syncing one worktree.
+type syncResult struct {
+	Name      string
+	Base      string
+	Behind    int      // Commits the branch was behind its base
+	Skipped   string   // Why the sync wasn't attempted
+	Conflicts []string // Files that conflicted; the sync was aborted
+	Err       error
+}
+
+// WorktreesSyncedMsg reports the outcome of syncing one or more worktrees.
+type WorktreesSyncedMsg struct {
+	Epoch    uint64
+	Strategy string
+	Auto     bool // Started by auto-sync
+	Results  []syncResult
+}
+
+// GetEpoch implements plugin.EpochMessage.
+func (m WorktreesSyncedMsg) GetEpoch() uint64 { return m.Epoch }
+
+// syncAbortedMsg signals that a rebase or merge left by a sync was aborted.
+type syncAbortedMsg struct {
+	WorkspaceName string
+	Err           error
+}
+
+// syncStrategy returns the configured sync strategy, rebase by default.
+func (p *Plugin) syncStrategy() string {
+	if p.ctx != nil && p.ctx.Config != nil && p.ctx.Config.Plugins.Workspace.SyncStrategy == syncMerge {
+		return syncMerge
+	}
+	return syncRebase
+}
+
+// autoSyncEnabled reports whether worktrees follow their base automatically.
+func (p *Plugin) autoSyncEnabled() bool {
+	return p.ctx != nil && p.ctx.Config != nil && p.ctx.Config.Plugins.Workspace.AutoSync
+}
+
+// syncable reports whether a worktree has a base branch to sync with.
+func syncable(wt *Worktree) bool {
+	return wt != nil && !wt.IsMain && !wt.IsMissing && wt.BaseBranch != "" && wt.Branch != wt.BaseBranch
+}
+
+// agentIdle reports whether a worktree's agent can have its files changed
+// under it: no agent, or one that has finished its turn. A waiting agent may
+// be stopped mid-turn on a permission prompt for an edit the sync would
+// rewrite.
+func agentIdle(wt *Worktree) bool {
+	if wt.Agent == nil {
+		return true
+	}
+	return wt.Status == StatusDone
+}
+
+// syncSelected syncs the selected worktree with its base branch, then
+// restacks the worktrees stacked on it.
+func (p *Plugin) syncSelected() tea.Cmd {
+	wt := p.selectedWorktree()
+	if !syncable(wt) {
+		return nil
+	}
+	if p.syncInFlight[wt.Name] {
+		return appmsg.ShowToast("Already syncing "+wt.Name, 2*time.Second)
+	}
+	var wts []*Worktree
+	for _, member := range p.stackFrom(wt) {
+		if syncable(member) && !p.syncInFlight[member.Name] {
+			wts = append(wts, member)
+		}
+	}
+	return p.syncWorktrees(wts, false)
+}
+
+// syncAll syncs every worktree with its base branch.
+func (p *Plugin) syncAll() tea.Cmd {
+	var wts []*Worktree
+	for _, wt := range p.worktrees {
+		if syncable(wt) && !p.syncInFlight[wt.Name] {
+			wts = append(wts, wt)
+		}
+	}
+	if len(wts) == 0 {
+		return appmsg.ShowToast("No workspaces to sync", 2*time.Second)
+	}
+	return p.syncWorktrees(wts, false)
+}
+
+// syncWorktrees returns a command that syncs worktrees one after another,
+// stack parents before the worktrees stacked on them.
+func (p *Plugin) syncWorktrees(wts []*Worktree, auto bool) tea.Cmd {
+	if p.syncInFlight == nil {
+		p.syncInFlight = make(map[string]bool)
+	}
+	wts = p.stackOrder(wts)
+	targets := make([]syncTarget, 0, len(wts))
+	for _, wt := range wts {
+		p.syncInFlight[wt.Name] = true
+		t := syncTarget{name: wt.Name, path: wt.Path, base: wt.BaseBranch}
+		if parent := p.stackParent(wt); parent != nil {
+			t.parent = parent.Name
+		}
+		targets = append(targets, t)
+	}
+	epoch := p.ctx.Epoch
+	strategy := p.syncStrategy()
+	return func() tea.Msg {
+		return WorktreesSyncedMsg{Epoch: epoch, Strategy: strategy, Auto: auto, Results: syncTargets(targets, strategy)}
+	}
+}
+
+// syncTargets syncs targets in order. A worktree whose stack parent failed
+// to sync is skipped, since it would be synced onto the stale parent.
+func syncTargets(targets []syncTarget, strategy string) []syncResult {
+	results := make([]syncResult, 0, len(targets))
+	failed := make(map[string]bool)
+	for _, t := range targets {
+		var res syncResult
+		if failed[t.parent] {
+			res = syncResult{Base: t.base, Skipped: "parent not synced"}
+			failed[t.name] = true
+		} else {
+			res = syncWorktree(t.path, t.base, strategy)
+			failed[t.name] = res.Err != nil
+		}
+		res.Name = t.name
+		results = append(results, res)
+	}
+	return results
+}
+
+// syncWorktree rebases the worktree at dir onto base, or merges base into
+// it. Worktrees that are up to date or have uncommitted changes are skipped.
+// On conflicts the rebase or merge is aborted, leaving the branch as it was.
+func syncWorktree(dir, base, strategy string) syncResult {
+	res := syncResult{Base: base}
+
+	out, err := gitOutput(dir, "rev-list", "--count", "HEAD.."+base)
+	if err != nil {
+		res.Err = fmt.Errorf("compare with %s: %w", base, err)
+		return res
+	}
+	res.Behind, _ = strconv.Atoi(out)
+	if res.Behind == 0 {
+		res.Skipped = "up to date"
+		return res
+	}
+
+	status, err := gitOutput(dir, "status", "--porcelain", "--untracked-files=no")
+	if err != nil {
+		res.Err = fmt.Errorf("status: %w", err)
+		return res
+	}
+	if status != "" {
+		res.Skipped = "uncommitted changes"
+		return res
+	}
+
+	conflicts, err := startSync(dir, base, strategy)
+	if err != nil {
+		res.Conflicts = conflicts
+		_, _ = gitOutput(dir, strategy, "--abort")
+		res.Err = err
+	}
+	return res
+}
+
+// startSync runs the rebase or merge in dir. On failure it returns the
+// conflicted files, leaving the rebase or merge in progress. Rebases use
+// --fork-point, so a branch stacked on a rewritten parent only replays its
+// own commits.
+func startSync(dir, base, strategy string) ([]string, error) {
+	args := []string{"rebase", "--fork-point", base}
+	if strategy == syncMerge {
+		args = []string{"merge", "--no-edit", base}
+	}
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	output, err := cmd.CombinedOutput()
+	if err == nil {
+		return nil, nil
+	}
+	var conflicts []string
+	if files, diffErr := gitOutput(dir, "diff", "--name-only", "--diff-filter=U"); diffErr == nil && files != "" {
+		conflicts = strings.Split(files, "\n")
+	}
+	return conflicts, fmt.Errorf("%s failed: %s", strategy, strings.TrimSpace(string(output)))
+}
+
+// handleWorktreesSynced reports sync results. A conflict from a manual sync
+// of a single worktree opens the conflict modal; otherwise it is reported
+// and the user can sync that worktree to resolve it.
+func (p *Plugin) handleWorktreesSynced(msg WorktreesSyncedMsg) tea.Cmd {
+	for _, res := range msg.Results {
+		delete(p.syncInFlight, res.Name)
+	}
+	if plugin.IsStale(p.ctx, msg) {
+		return nil
+	}
+
+	var synced, current, skipped, conflicted, failed []string
+	for _, res := range msg.Results {
+		switch {
+		case res.Err != nil && len(res.Conflicts) > 0:
+			conflicted = append(conflicted, res.Name)
+		case res.Err != nil:
+			failed = append(failed, res.Name)
+		case res.Skipped == "up to date":
+			current = append(current, res.Name)
+		case res.Skipped != "":
+			skipped = append(skipped, res.Name)
+		default:
+			synced = append(synced, res.Name)
+		}
+	}
+
+	var cmds []tea.Cmd
+	if len(synced) > 0 {
+		cmds = append(cmds, func() tea.Msg { return RefreshMsg{} }, p.loadConflicts())
+	}
+
+	if len(msg.Results) == 1 && !msg.Auto {
+		res := msg.Results[0]
+		switch {
+		case len(conflicted) > 0 && (p.viewMode == ViewModeList || p.viewMode == ViewModeKanban):
+			p.openSyncConflict(res, msg.Strategy)
+		case res.Err != nil:
+			cmds = append(cmds, appmsg.ShowToast(fmt.Sprintf("Sync %s: %v", res.Name, firstLine(res.Err.Error())), 4*time.Second))
+		case res.Skipped != "":
+			cmds = append(cmds, appmsg.ShowToast(fmt.Sprintf("%s not synced: %s", res.Name, res.Skipped), 2*time.Second))
+		default:
+			cmds = append(cmds, appmsg.ShowToast(fmt.Sprintf("Synced %s with %s (%d new commits)", res.Name, res.Base, res.Behind), 2*time.Second))
+		}
+		return tea.Batch(cmds...)
+	}
+
+	// Auto-sync only reports what needs attention
+	if msg.Auto && len(synced) == 0 && len(conflicted) == 0 && len(failed) == 0 {
+		return tea.Batch(cmds...)
+	}
+	var parts []string
+	if len(synced) > 0 {
+		parts = append(parts, fmt.Sprintf("%d synced", len(synced)))
+	}
+	if len(current) > 0 && !msg.Auto {
+		parts = append(parts, fmt.Sprintf("%d up to date", len(current)))
+	}
+	if len(skipped) > 0 {
+		parts = append(parts, fmt.Sprintf("%d skipped", len(skipped)))
+	}
+	if len(conflicted) > 0 {
+		parts = append(parts, fmt.Sprintf("conflicts in %s (u to resolve)", strings.Join(conflicted, ", ")))
+	}
+	if len(failed) > 0 {
+		parts = append(parts, "failed: "+strings.Join(failed, ", "))
+	}
+	prefix := "Sync: "
+	if msg.Auto {
+		prefix = "Auto-sync: "
+	}
+	return tea.Batch(append(cmds, appmsg.ShowToast(prefix+strings.Join(parts, ", "), 4*time.Second))...)
+}
+
+// autoSyncWorktrees syncs idle worktrees whose base branch advanced since
+// it was last seen. The first tip seen for a worktree is only recorded, and
+// worktrees with a busy agent are retried on a later check.
+func (p *Plugin) autoSyncWorktrees(tips map[string]string) tea.Cmd {
+	if len(tips) == 0 {
+		return nil
+	}
+	if p.syncBaseTips == nil {
+		p.syncBaseTips = make(map[string]string)
+	}
+	var due []*Worktree
+	for _, wt := range p.worktrees {
+		if !syncable(wt) {
+			continue
+		}
+		tip := tips[wt.BaseBranch]
+		last, seen := p.syncBaseTips[wt.Name]
+		if tip == "" || tip == last {
+			continue
+		}
+		if !seen || !p.autoSyncEnabled() {
+			p.syncBaseTips[wt.Name] = tip
+			continue
+		}
+		if !agentIdle(wt) || p.syncInFlight[wt.Name] || (p.syncState != nil && p.syncState.Worktree == wt) {
+			continue
+		}
+		p.syncBaseTips[wt.Name] = tip
+		due = append(due, wt)
+	}
+	if len(due) == 0 {
+		return nil
+	}
+	return p.syncWorktrees(due, true)
+}
+
+// openSyncConflict shows the resolution options for a conflicted sync.
+func (p *Plugin) openSyncConflict(res syncResult, strategy string) {
+	wt := p.findWorktree(res.Name)
+	if wt == nil {
+		return
+	}
+	summary, _, _ := summarizeGitError(res.Err)
+	p.syncState = &SyncState{
+		Worktree:  wt,
+		Base:      res.Base,
+		Strategy:  strategy,
+		Conflicts: res.Conflicts,
+		Err:       res.Err,
+		Summary:   summary,
+	}
+	p.syncModal = nil
+	p.viewMode = ViewModeSyncConflict
+}
+
+// closeSyncConflict dismisses the sync conflict modal.
+func (p *Plugin) closeSyncConflict() {
+	p.syncState = nil
+	p.syncModal = nil
+	p.syncModalWidth = 0
+	p.viewMode = ViewModeList
+}
+
+// executeSyncResolution starts a rebase or merge of the base branch in the
+// worktree, leaving any conflicts in place to be resolved there.
+func (p *Plugin) executeSyncResolution(strategy string) tea.Cmd {
+	s := p.syncState
+	if s == nil || s.Running || s.Resolving != "" {
+		return nil
+	}
+	s.Running = true
+	s.Strategy = strategy
+	wtName, dir, base := s.Worktree.Name, s.Worktree.Path, s.Base
+
+	return func() tea.Msg {
+		conflicts, err := startSync(dir, base, strategy)
+		if strategy == syncMerge {
+			return MergeResolutionMsg{WorkspaceName: wtName, Branch: base, Success: err == nil, Err: err, Conflicts: conflicts}
+		}
+		return RebaseResolutionMsg{WorkspaceName: wtName, Branch: base, Success: err == nil, Err: err, Conflicts: conflicts}
+	}
+}
+
+// handleSyncResolution updates the sync state from a resolution attempt.
+// When the rebase or merge stops on conflicts they stay in the worktree,
+// and are handed to the agent if that was requested.
+func (p *Plugin) handleSyncResolution(strategy string, success bool, err error, conflicts []string) tea.Cmd {
+	s := p.syncState
+	s.Running = false
+	if success {
+		name, base := s.Worktree.Name, s.Base
+		p.closeSyncConflict()
+		return tea.Batch(
+			func() tea.Msg { return RefreshMsg{} },
+			p.loadConflicts(),
+			appmsg.ShowToast(fmt.Sprintf("Synced %s with %s", name, base), 2*time.Second),
+		)
+	}
+
+	s.Err = err
+	s.Summary, _, _ = summarizeGitError(err)
+	if len(conflicts) > 0 {
+		s.Conflicts = conflicts
+		s.Resolving = strategy
+		if s.Handoff {
+			return p.handSyncToAgent()
+		}
+	}
+	return nil
+}
+
+// handSyncToAgent sends the agent a prompt to finish the in-progress rebase
+// or merge, queueing it if the agent is busy.
+func (p *Plugin) handSyncToAgent() tea.Cmd {
+	s := p.syncState
+	if s == nil || s.Resolving == "" {
+		return nil
+	}
+	wt := s.Worktree
+	if wt.Agent == nil {
+		return appmsg.ShowToast("No agent running in "+wt.Name, 2*time.Second)
+	}
+	text := syncConflictPrompt(s.Base, s.Resolving, s.Conflicts)
+	p.closeSyncConflict()
+	return tea.Batch(
+		p.enqueuePrompt(wt.Name, text),
+		appmsg.ShowToast(fmt.Sprintf("Handed %s conflicts to %s's agent", s.Resolving, wt.Name), 2*time.Second),
+	)
+}
+
+// abortSync aborts the rebase or merge left in progress by a resolution.
+func (p *Plugin) abortSync() tea.Cmd {
+	s := p.syncState
+	if s == nil || s.Resolving == "" || s.Running {
+		return nil
+	}
+	s.Running = true
+	wtName, dir, strategy := s.Worktree.Name, s.Worktree.Path, s.Resolving
+	return func() tea.Msg {
+		_, err := gitOutput(dir, strategy, "--abort")
+		return syncAbortedMsg{WorkspaceName: wtName, Err: err}
+	}
+}
+
+// handleSyncAborted closes the conflict modal once the abort finished.
+func (p *Plugin) handleSyncAborted(msg syncAbortedMsg) tea.Cmd {
+	if p.syncState == nil || p.syncState.Worktree.Name != msg.WorkspaceName {
+		return nil
+	}
+	if msg.Err != nil {
+		p.syncState.Running = false
+		return appmsg.ShowToast("Abort failed: "+msg.Err.Error(), 3*time.Second)
+	}
+	p.closeSyncConflict()
+	return tea.Batch(
+		func() tea.Msg { return RefreshMsg{} },
+		appmsg.ShowToast("Sync aborted in "+msg.WorkspaceName, 2*time.Second),
+	)
+}
+
+// syncConflictPrompt asks an agent to finish a rebase or merge that stopped
+// on conflicts.
+func syncConflictPrompt(base, strategy string, conflicts []string) string {
+	var sb strings.Builder
+	if strategy == syncMerge {
+		fmt.Fprintf(&sb, "Merging %s into this branch stopped on conflicts", base)
+	} else {
+		fmt.Fprintf(&sb, "Rebasing this branch onto %s stopped on conflicts", base)
+	}
+	if len(conflicts) > 0 {
+		sb.WriteString(" in:\n")
+		for _, f := range conflicts {
+			sb.WriteString("- " + f + "\n")
+		}
+	} else {
+		sb.WriteString(".\n")
+	}
+	sb.WriteString("\nResolve the conflicts, keeping the intent of both sides, and stage the files with git add. ")
+	if strategy == syncMerge {
+		sb.WriteString("Then run `git commit --no-edit` to conclude the merge.")
+	} else {
+		sb.WriteString("Then run `git rebase --continue`, repeating for any later commits that conflict until the rebase finishes.")
+	}
+	return sb.String()
+}
+
+// firstLine returns the first line of s.
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(s, "\n")
+	return line
+}
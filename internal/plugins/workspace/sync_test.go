@@ -0,0 +1,156 @@
+package workspace
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/marcus/sidecar/internal/config"
+	"github.com/marcus/sidecar/internal/plugin"
+)
+
+// initSyncRepo creates a repo whose feature branch is one commit behind main,
+// with feature touching file and main touching mainFile.
+func initSyncRepo(t *testing.T, file, mainFile string) (string, func(args ...string) string) {
+	t.Helper()
+	repo := t.TempDir()
+	run := func(args ...string) string {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repo
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(repo, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	run("init", "-q", "-b", "main")
+	run("config", "user.email", "test@test.com")
+	run("config", "user.name", "Test")
+	write("shared.txt", "one\ntwo\nthree\n")
+	run("add", ".")
+	run("commit", "-q", "-m", "initial")
+	run("checkout", "-q", "-b", "feature")
+	write(file, "one\nFEATURE\nthree\n")
+	run("add", ".")
+	run("commit", "-q", "-m", "feature")
+	run("checkout", "-q", "main")
+	write(mainFile, "one\nMAIN\nthree\n")
+	run("add", ".")
+	run("commit", "-q", "-m", "main")
+	run("checkout", "-q", "feature")
+	return repo, run
+}
+
+func TestSyncWorktree(t *testing.T) {
+	for _, strategy := range []string{syncRebase, syncMerge} {
+		t.Run(strategy, func(t *testing.T) {
+			repo, run := initSyncRepo(t, "feature.txt", "shared.txt")
+
+			if err := os.WriteFile(filepath.Join(repo, "feature.txt"), []byte("dirty\n"), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if res := syncWorktree(repo, "main", strategy); res.Skipped != "uncommitted changes" || res.Behind != 1 {
+				t.Errorf("dirty: %+v, want skipped for uncommitted changes", res)
+			}
+			run("checkout", "--", "feature.txt")
+
+			res := syncWorktree(repo, "main", strategy)
+			if res.Err != nil || res.Skipped != "" || res.Behind != 1 {
+				t.Fatalf("sync: %+v", res)
+			}
+			if run("merge-base", "--is-ancestor", "main", "HEAD") != "" {
+				t.Error("main should be an ancestor after syncing")
+			}
+			if res := syncWorktree(repo, "main", strategy); res.Skipped != "up to date" {
+				t.Errorf("second sync: %+v, want up to date", res)
+			}
+		})
+	}
+}
+
+func TestSyncWorktree_ConflictAborts(t *testing.T) {
+	repo, run := initSyncRepo(t, "shared.txt", "shared.txt")
+	before := run("rev-parse", "HEAD")
+
+	res := syncWorktree(repo, "main", syncRebase)
+	if res.Err == nil || !reflect.DeepEqual(res.Conflicts, []string{"shared.txt"}) {
+		t.Fatalf("sync: %+v, want conflict in shared.txt", res)
+	}
+	if after := run("rev-parse", "HEAD"); after != before {
+		t.Errorf("HEAD moved to %s, want %s", after, before)
+	}
+	if status := run("status", "--porcelain"); status != "" {
+		t.Errorf("worktree not clean after abort:\n%s", status)
+	}
+
+	// A resolution leaves the conflict in place for the agent or user
+	conflicts, err := startSync(repo, "main", syncMerge)
+	if err == nil || !reflect.DeepEqual(conflicts, []string{"shared.txt"}) {
+		t.Fatalf("startSync: %v, %v", conflicts, err)
+	}
+	if _, err := os.Stat(filepath.Join(repo, ".git", "MERGE_HEAD")); err != nil {
+		t.Errorf("merge should be left in progress: %v", err)
+	}
+}
+
+func TestAutoSyncWorktrees(t *testing.T) {
+	cfg := config.Default()
+	p := &Plugin{ctx: &plugin.Context{Config: cfg}}
+	idle := &Worktree{Name: "idle", Branch: "a", BaseBranch: "main"}
+	busy := &Worktree{Name: "busy", Branch: "b", BaseBranch: "main", Agent: &Agent{}, Status: StatusActive}
+	p.worktrees = []*Worktree{{Name: "main", Branch: "main", IsMain: true}, idle, busy}
+
+	// First sighting of the base tip is only a baseline
+	if cmd := p.autoSyncWorktrees(map[string]string{"main": "c1"}); cmd != nil {
+		t.Error("first check should not sync")
+	}
+	// Base advanced but auto-sync is off: record it without syncing
+	if cmd := p.autoSyncWorktrees(map[string]string{"main": "c2"}); cmd != nil {
+		t.Error("auto-sync disabled should not sync")
+	}
+
+	cfg.Plugins.Workspace.AutoSync = true
+	if cmd := p.autoSyncWorktrees(map[string]string{"main": "c3"}); cmd == nil {
+		t.Fatal("base advanced with an idle agent should sync")
+	}
+	if !p.syncInFlight["idle"] || p.syncInFlight["busy"] {
+		t.Errorf("in flight = %v, want only idle", p.syncInFlight)
+	}
+	if p.syncBaseTips["busy"] != "c2" {
+		t.Errorf("busy tip = %q, want c2 so it syncs once idle", p.syncBaseTips["busy"])
+	}
+
+	// Waiting may be a permission prompt mid-turn
+	busy.Status = StatusWaiting
+	if cmd := p.autoSyncWorktrees(map[string]string{"main": "c3"}); cmd != nil || p.syncInFlight["busy"] {
+		t.Error("agent waiting on a prompt should not sync")
+	}
+
+	busy.Status = StatusDone
+	if cmd := p.autoSyncWorktrees(map[string]string{"main": "c3"}); cmd == nil || !p.syncInFlight["busy"] {
+		t.Error("agent that went idle should sync")
+	}
+}
+
+func TestSyncConflictPrompt(t *testing.T) {
+	rebase := syncConflictPrompt("main", syncRebase, []string{"a.go", "b.go"})
+	for _, want := range []string{"onto main", "- a.go\n- b.go\n", "git rebase --continue"} {
+		if !strings.Contains(rebase, want) {
+			t.Errorf("rebase prompt missing %q:\n%s", want, rebase)
+		}
+	}
+	merge := syncConflictPrompt("develop", syncMerge, nil)
+	if !strings.Contains(merge, "Merging develop") || !strings.Contains(merge, "git commit --no-edit") {
+		t.Errorf("merge prompt:\n%s", merge)
+	}
+}
@@ -0,0 +1,141 @@
+package workspace
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/marcus/sidecar/internal/modal"
+	"github.com/marcus/sidecar/internal/styles"
+	"github.com/marcus/sidecar/internal/ui"
+)
+
+// syncConflictMaxFiles caps the conflicted files listed in the modal.
+const syncConflictMaxFiles = 10
+
+// renderSyncConflictModal renders the sync conflict modal over the list view.
+func (p *Plugin) renderSyncConflictModal(width, height int) string {
+	background := p.renderListView(width, height)
+
+	p.ensureSyncConflictModal()
+	if p.syncModal == nil {
+		return background
+	}
+
+	modalContent := p.syncModal.Render(width, height, p.mouseHandler)
+	return ui.OverlayModal(background, modalContent, width, height)
+}
+
+// ensureSyncConflictModal builds/rebuilds the sync conflict modal.
+func (p *Plugin) ensureSyncConflictModal() {
+	if p.syncState == nil {
+		return
+	}
+
+	modalW := 70
+	if modalW > p.width-4 {
+		modalW = p.width - 4
+	}
+	if modalW < 20 {
+		modalW = 20
+	}
+
+	if p.syncModal != nil && p.syncModalWidth == modalW {
+		return
+	}
+	p.syncModalWidth = modalW
+
+	p.syncModal = modal.New("Sync Conflict",
+		modal.WithWidth(modalW),
+		modal.WithVariant(modal.VariantWarning),
+		modal.WithHints(false),
+	).
+		AddSection(p.syncConflictSection())
+}
+
+// syncConflictSection lists the conflicted files and resolution options.
+func (p *Plugin) syncConflictSection() modal.Section {
+	return modal.Custom(func(contentWidth int, focusID, hoverID string) modal.RenderedSection {
+		s := p.syncState
+		if s == nil {
+			return modal.RenderedSection{}
+		}
+		wt := s.Worktree
+
+		var sb strings.Builder
+		action := "Rebasing onto"
+		if s.Strategy == syncMerge {
+			action = "Merging"
+		}
+		sb.WriteString(fmt.Sprintf("%s %s %s\n", action, lipgloss.NewStyle().Bold(true).Render(s.Base), dimText("in "+wt.Name)))
+		if s.Summary != "" {
+			sb.WriteString(lipgloss.NewStyle().Foreground(styles.Warning).Render(s.Summary))
+			sb.WriteString("\n")
+		}
+
+		if len(s.Conflicts) > 0 {
+			sb.WriteString("\n")
+			sb.WriteString(dimText("Conflicted files:"))
+			sb.WriteString("\n")
+			for i, f := range s.Conflicts {
+				if i == syncConflictMaxFiles {
+					sb.WriteString(dimText(fmt.Sprintf("  ... (%d more)", len(s.Conflicts)-i)))
+					sb.WriteString("\n")
+					break
+				}
+				sb.WriteString(ansi.Truncate("  • "+f, contentWidth, "…"))
+				sb.WriteString("\n")
+			}
+		}
+
+		sb.WriteString("\n")
+		sb.WriteString(strings.Repeat("─", min(contentWidth, 60)))
+		sb.WriteString("\n\n")
+
+		switch {
+		case s.Running:
+			sb.WriteString(dimText("Running..."))
+		case s.Resolving != "":
+			sb.WriteString(lipgloss.NewStyle().Bold(true).Render("Resolution Options"))
+			sb.WriteString("\n")
+			sb.WriteString(dimText(fmt.Sprintf("  The %s is stopped on conflicts in the worktree.", s.Resolving)))
+			sb.WriteString("\n\n")
+			if wt.Agent != nil {
+				sb.WriteString(dimText("    [a] Hand to agent"))
+				sb.WriteString("\n")
+				sb.WriteString(dimText("        Ask the agent to resolve the conflicts and continue"))
+				sb.WriteString("\n\n")
+			}
+			sb.WriteString(dimText(fmt.Sprintf("    [x] Abort the %s", s.Resolving)))
+			sb.WriteString("\n")
+			sb.WriteString(dimText("        Put the branch back as it was"))
+			sb.WriteString("\n\n")
+			sb.WriteString(dimText("Esc: resolve it yourself"))
+		default:
+			sb.WriteString(lipgloss.NewStyle().Bold(true).Render("Resolution Options"))
+			sb.WriteString("\n")
+			sb.WriteString(dimText(fmt.Sprintf("  The sync was aborted; '%s' is unchanged.", wt.Branch)))
+			sb.WriteString("\n\n")
+			sb.WriteString(dimText(fmt.Sprintf("    [r] Rebase onto %s", s.Base)))
+			sb.WriteString("\n")
+			sb.WriteString(dimText("        Replay the branch's commits, stopping at the first conflict"))
+			sb.WriteString("\n\n")
+			sb.WriteString(dimText(fmt.Sprintf("    [m] Merge %s into the branch", s.Base)))
+			sb.WriteString("\n")
+			sb.WriteString(dimText("        Creates a merge commit; conflicts are left to resolve"))
+			sb.WriteString("\n")
+			if wt.Agent != nil {
+				sb.WriteString("\n")
+				sb.WriteString(dimText("    [a] Hand to agent"))
+				sb.WriteString("\n")
+				sb.WriteString(dimText(fmt.Sprintf("        Start the %s and ask the agent to resolve the conflicts", s.Strategy)))
+				sb.WriteString("\n")
+			}
+			sb.WriteString("\n")
+			sb.WriteString(dimText("Esc: cancel"))
+		}
+
+		return modal.RenderedSection{Content: sb.String()}
+	}, nil)
+}
@@ -1,16 +1,308 @@
 package workspace
 
 import (
+	"errors"
+	"fmt"
+	"path/filepath"
 	"regexp"
+	"strconv"
+	"strings"
 )
 
+// Prompt template syntax:
+//
+//	{{ticket}}                  linked task ID
+//	{{branch}}, {{base}}        worktree branch and the branch it was created from
+//	{{task.title}}              linked task title
+//	{{task.description}}        linked task description
+//	{{diffstat}}                git diff --stat against the base branch
+//	{{changed_files}}           files changed since the base branch, one per line
+//	{{note:<id>}}               content of a note
+//	{{file:<path>}}             content of a file in the worktree
+//	{{input:"Question"}}        answer the prompt picker asks for
+//	{{name || 'fallback'}}      fallback when a value is empty
+//	{{#if name}}...{{else}}...{{/if}}
+//	                            conditional on a value being non-empty
+//
+// Any other {{...}}, such as ${{ secrets.TOKEN }} or a Go template action,
+// is kept as literal text. Only the tags above are checked.
+var promptTemplateVars = map[string]bool{
+	"ticket":           true,
+	"branch":           true,
+	"base":             true,
+	"task.title":       true,
+	"task.description": true,
+	"diffstat":         true,
+	"changed_files":    true,
+}
+
+// promptTemplateAliases are older names for template variables.
+var promptTemplateAliases = map[string]string{
+	"taskTitle": "task.title",
+	"taskBody":  "task.description",
+}
+
+// errUnknownTemplateVar marks a tag that isn't template syntax at all.
+var errUnknownTemplateVar = errors.New("unknown variable")
+
+// templateFallbackPattern splits "expr || 'fallback'" tags.
+var templateFallbackPattern = regexp.MustCompile(`^(.*?)\s*\|\|\s*'([^']*)'$`)
+
+// PromptTemplate is a parsed prompt body.
+type PromptTemplate struct {
+	nodes []templateNode
+}
+
+// templateNode is literal text, a substitution, or an if block.
+type templateNode struct {
+	text  string
+	expr  *templateExpr  // Substitution
+	cond  *templateExpr  // If block condition
+	then  []templateNode // If block body
+	other []templateNode // Else body
+}
+
+// templateExpr is a value reference inside {{ }}.
+type templateExpr struct {
+	name     string // Variable name, or "note", "file", "input"
+	arg      string // Note ID, file path or input question
+	fallback string
+}
+
+// TemplateResolver returns the value of a template variable. arg is the note
+// ID, file path or question for note, file and input references.
+type TemplateResolver func(name, arg string) string
+
+// ParsePromptTemplate parses and validates a prompt body.
+func ParsePromptTemplate(body string) (*PromptTemplate, error) {
+	// Open if blocks, innermost last
+	type frame struct {
+		node   *templateNode
+		inElse bool
+		line   int
+	}
+	var stack []frame
+	root := &PromptTemplate{}
+
+	appendNode := func(n templateNode) {
+		if len(stack) == 0 {
+			root.nodes = append(root.nodes, n)
+			return
+		}
+		top := &stack[len(stack)-1]
+		if top.inElse {
+			top.node.other = append(top.node.other, n)
+		} else {
+			top.node.then = append(top.node.then, n)
+		}
+	}
+	lineAt := func(pos int) int { return strings.Count(body[:pos], "\n") + 1 }
+
+	pos := 0
+	for pos < len(body) {
+		start := strings.Index(body[pos:], "{{")
+		if start < 0 {
+			appendNode(templateNode{text: body[pos:]})
+			break
+		}
+		start += pos
+		if start > pos {
+			appendNode(templateNode{text: body[pos:start]})
+		}
+		end := strings.Index(body[start:], "}}")
+		if end < 0 {
+			appendNode(templateNode{text: body[start:]})
+			break
+		}
+		end += start
+		tag := strings.TrimSpace(body[start+2 : end])
+		pos = end + 2
+		line := lineAt(start)
+
+		isBlock := true
+		switch {
+		case strings.HasPrefix(tag, "#if ") || tag == "#if":
+			expr, err := parseTemplateExpr(strings.TrimSpace(strings.TrimPrefix(tag, "#if")))
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+			stack = append(stack, frame{node: &templateNode{cond: expr}, line: line})
+		case tag == "else":
+			if len(stack) == 0 || stack[len(stack)-1].inElse {
+				return nil, fmt.Errorf("line %d: {{else}} without {{#if}}", line)
+			}
+			stack[len(stack)-1].inElse = true
+		case tag == "/if":
+			if len(stack) == 0 {
+				return nil, fmt.Errorf("line %d: {{/if}} without {{#if}}", line)
+			}
+			n := stack[len(stack)-1].node
+			stack = stack[:len(stack)-1]
+			appendNode(*n)
+		default:
+			isBlock = false
+			expr, err := parseTemplateExpr(tag)
+			if errors.Is(err, errUnknownTemplateVar) {
+				appendNode(templateNode{text: body[start:pos]})
+				continue
+			}
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+			appendNode(templateNode{expr: expr})
+		}
+
+		// A block tag alone on its line doesn't leave a blank line behind
+		if isBlock && (start == 0 || body[start-1] == '\n') && strings.HasPrefix(body[pos:], "\n") {
+			pos++
+		}
+	}
+	if len(stack) > 0 {
+		return nil, fmt.Errorf("line %d: {{#if}} is never closed", stack[len(stack)-1].line)
+	}
+	return root, nil
+}
+
+// parseTemplateExpr parses the inside of a substitution tag.
+func parseTemplateExpr(s string) (*templateExpr, error) {
+	expr := &templateExpr{}
+	if m := templateFallbackPattern.FindStringSubmatch(s); m != nil {
+		s, expr.fallback = m[1], m[2]
+	}
+	if s == "" {
+		return nil, errUnknownTemplateVar
+	}
+
+	name, arg, hasArg := strings.Cut(s, ":")
+	if !hasArg {
+		if alias, ok := promptTemplateAliases[s]; ok {
+			s = alias
+		}
+		if !promptTemplateVars[s] {
+			return nil, fmt.Errorf("%w %q", errUnknownTemplateVar, s)
+		}
+		expr.name = s
+		return expr, nil
+	}
+
+	expr.name = strings.TrimSpace(name)
+	arg = strings.TrimSpace(arg)
+	switch expr.name {
+	case "note":
+		if arg == "" || strings.ContainsAny(arg, " \t") {
+			return nil, fmt.Errorf("invalid note ID %q", arg)
+		}
+	case "file":
+		if arg == "" {
+			return nil, fmt.Errorf("file: needs a path")
+		}
+		if filepath.IsAbs(arg) || strings.HasPrefix(filepath.Clean(arg), "..") {
+			return nil, fmt.Errorf("file %q must be inside the worktree", arg)
+		}
+	case "input":
+		q, err := unquoteTemplateArg(arg)
+		if err != nil || q == "" {
+			return nil, fmt.Errorf("input needs a quoted question, like {{input:\"Which module?\"}}")
+		}
+		arg = q
+	default:
+		return nil, fmt.Errorf("%w %q", errUnknownTemplateVar, s)
+	}
+	expr.arg = arg
+	return expr, nil
+}
+
+// unquoteTemplateArg strips double or single quotes from a tag argument.
+func unquoteTemplateArg(s string) (string, error) {
+	if len(s) >= 2 && s[0] == '\'' && s[len(s)-1] == '\'' {
+		return s[1 : len(s)-1], nil
+	}
+	return strconv.Unquote(s)
+}
+
+// Inputs returns the questions of the template's input placeholders, in
+// order of first use.
+func (t *PromptTemplate) Inputs() []string {
+	seen := make(map[string]bool)
+	var questions []string
+	add := func(e *templateExpr) {
+		if e != nil && e.name == "input" && !seen[e.arg] {
+			seen[e.arg] = true
+			questions = append(questions, e.arg)
+		}
+	}
+	var walk func([]templateNode)
+	walk = func(nodes []templateNode) {
+		for _, n := range nodes {
+			add(n.cond)
+			add(n.expr)
+			walk(n.then)
+			walk(n.other)
+		}
+	}
+	walk(t.nodes)
+	return questions
+}
+
+// Execute expands the template, looking values up with resolve.
+func (t *PromptTemplate) Execute(resolve TemplateResolver) string {
+	var sb strings.Builder
+	executeTemplateNodes(&sb, t.nodes, resolve)
+	return sb.String()
+}
+
+func executeTemplateNodes(sb *strings.Builder, nodes []templateNode, resolve TemplateResolver) {
+	for _, n := range nodes {
+		switch {
+		case n.cond != nil:
+			if strings.TrimSpace(n.cond.value(resolve)) != "" {
+				executeTemplateNodes(sb, n.then, resolve)
+			} else {
+				executeTemplateNodes(sb, n.other, resolve)
+			}
+		case n.expr != nil:
+			sb.WriteString(n.expr.value(resolve))
+		default:
+			sb.WriteString(n.text)
+		}
+	}
+}
+
+// value resolves an expression, applying its fallback.
+func (e *templateExpr) value(resolve TemplateResolver) string {
+	if v := resolve(e.name, e.arg); v != "" {
+		return v
+	}
+	return e.fallback
+}
+
+// PromptInputs returns the questions a prompt body asks, or nil if the body
+// doesn't parse.
+func PromptInputs(body string) []string {
+	tmpl, err := ParsePromptTemplate(body)
+	if err != nil {
+		return nil
+	}
+	return tmpl.Inputs()
+}
+
 // ticketPattern matches {{ticket}} or {{ticket || 'fallback text'}}
 var ticketPattern = regexp.MustCompile(`\{\{ticket(?:\s*\|\|\s*'([^']*)')?\}\}`)
 
 // ExpandPromptTemplate expands template variables in a prompt body.
 // - {{ticket}} expands to taskID (returns empty if taskID is empty)
 // - {{ticket || 'default'}} expands to taskID, or 'default' if taskID is empty
+// Other variables expand to their fallback. Bodies that don't parse only
+// have their ticket references expanded.
 func ExpandPromptTemplate(body, taskID string) string {
+	if tmpl, err := ParsePromptTemplate(body); err == nil {
+		return tmpl.Execute(func(name, _ string) string {
+			if name == "ticket" {
+				return taskID
+			}
+			return ""
+		})
+	}
 	return ticketPattern.ReplaceAllStringFunc(body, func(match string) string {
 		submatch := ticketPattern.FindStringSubmatch(match)
 
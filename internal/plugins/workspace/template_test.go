@@ -0,0 +1,123 @@
+package workspace
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParsePromptTemplate_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{"unknown if variable", "{{#if tickt}}x{{/if}}", `line 1: unknown variable "tickt"`},
+		{"unclosed if", "{{#if ticket}}\nwork", "line 1: {{#if}} is never closed"},
+		{"stray endif", "{{/if}}", "line 1: {{/if}} without {{#if}}"},
+		{"stray else", "x {{else}}", "line 1: {{else}} without {{#if}}"},
+		{"double else", "{{#if base}}a{{else}}b{{else}}c{{/if}}", "line 1: {{else}} without {{#if}}"},
+		{"unquoted input", "{{input:Which?}}", "input needs a quoted question"},
+		{"file outside worktree", "{{file:../secrets}}", "must be inside the worktree"},
+		{"absolute file", "{{file:/etc/passwd}}", "must be inside the worktree"},
+		{"empty note", "{{note:}}", "invalid note ID"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParsePromptTemplate(tt.body)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("ParsePromptTemplate(%q) error = %v, want %q", tt.body, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestPromptTemplate_Execute(t *testing.T) {
+	values := map[string]string{
+		"ticket":              "td-1",
+		"branch":              "feature",
+		"task.title":          "Add login",
+		"note:nt-1":           "remember the tests",
+		"input:Which module?": "auth",
+	}
+	resolve := func(name, arg string) string {
+		if arg != "" {
+			return values[name+":"+arg]
+		}
+		return values[name]
+	}
+
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{"variables", "{{ticket}} on {{branch}}: {{task.title}}", "td-1 on feature: Add login"},
+		{"alias", "{{taskTitle}}", "Add login"},
+		{"fallback unused", "{{ticket || 'none'}}", "td-1"},
+		{"fallback used", "{{diffstat || 'no changes'}}", "no changes"},
+		{"note", "Notes: {{note:nt-1}}", "Notes: remember the tests"},
+		{"input", `Work in {{input:"Which module?"}}`, "Work in auth"},
+		{"if true", "{{#if ticket}}has task{{else}}no task{{/if}}", "has task"},
+		{"if false", "{{#if base}}has base{{else}}no base{{/if}}", "no base"},
+		{"nested if", "{{#if ticket}}{{#if diffstat}}diff{{else}}clean{{/if}}{{/if}}", "clean"},
+		{
+			"block lines leave no blanks",
+			"Start.\n{{#if task.title}}\nTitle: {{task.title}}\n{{/if}}\nDone.",
+			"Start.\nTitle: Add login\nDone.",
+		},
+		{"stray braces kept", "map[string]int{} and }} alone", "map[string]int{} and }} alone"},
+		{"unknown tags kept", "token: ${{ secrets.TOKEN }} {{.Name}} {{#each items}}{{/each}} {{ }}",
+			"token: ${{ secrets.TOKEN }} {{.Name}} {{#each items}}{{/each}} {{ }}"},
+		{"unclosed tag kept", "{{ticket}} then {{branch", "td-1 then {{branch"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl, err := ParsePromptTemplate(tt.body)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := tmpl.Execute(resolve); got != tt.want {
+				t.Errorf("Execute = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPromptInputs(t *testing.T) {
+	body := `{{input:"Scope?"}} {{#if input:'Extra?'}}x{{/if}} {{input:"Scope?"}}`
+	if got, want := PromptInputs(body), []string{"Scope?", "Extra?"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("PromptInputs = %v, want %v", got, want)
+	}
+	if got := PromptInputs(`{{input:"Scope?"}} {{/if}}`); got != nil {
+		t.Errorf("PromptInputs of invalid body = %v, want nil", got)
+	}
+}
+
+func TestPromptResolver_FileAndInput(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "SPEC.md"), []byte("the spec\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	wt := &Worktree{Name: "wt", Path: dir, Branch: "feature", TaskID: ""}
+	resolve := promptResolver(wt, map[string]string{"Why?": "because"}, dir)
+
+	if got := resolve("file", "SPEC.md"); got != "the spec" {
+		t.Errorf("file = %q", got)
+	}
+	if got := resolve("file", "missing.md"); got != "" {
+		t.Errorf("missing file = %q, want empty", got)
+	}
+	if got := resolve("input", "Why?"); got != "because" {
+		t.Errorf("input = %q", got)
+	}
+	if got := resolve("branch", ""); got != "feature" {
+		t.Errorf("branch = %q", got)
+	}
+	// No linked task: nothing to look up
+	if got := resolve("task.title", ""); got != "" {
+		t.Errorf("task.title = %q, want empty", got)
+	}
+}
@@ -0,0 +1,134 @@
+package workspace
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/marcus/sidecar/internal/notestore"
+)
+
+// promptFileMaxBytes caps how much of a file {{file:...}} inlines.
+const promptFileMaxBytes = 64 << 10
+
+// expandPrompt expands a prompt's template for a worktree.
+func (p *Plugin) expandPrompt(prompt *Prompt, wt *Worktree) string {
+	tmpl, err := ParsePromptTemplate(prompt.Body)
+	if err != nil {
+		// LoadPrompts keeps invalid prompts out of the picker, but saved
+		// prompts from older versions may still get here
+		return ExpandPromptTemplate(prompt.Body, wt.TaskID)
+	}
+	workDir := ""
+	if p.ctx != nil {
+		workDir = p.ctx.WorkDir
+	}
+	return tmpl.Execute(promptResolver(wt, prompt.Inputs, workDir))
+}
+
+// promptTask is the part of td show output prompts can reference.
+type promptTask struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+}
+
+// promptResolver looks up template variables for a worktree. Values that
+// need git or td are fetched once, on first use.
+func promptResolver(wt *Worktree, inputs map[string]string, workDir string) TemplateResolver {
+	cache := make(map[string]string)
+	var task *promptTask
+	loadTask := func() {
+		if task != nil {
+			return
+		}
+		task = &promptTask{}
+		if wt.TaskID == "" {
+			return
+		}
+		cmd := exec.Command("td", "show", wt.TaskID, "--json")
+		cmd.Dir = workDir
+		if output, err := cmd.Output(); err == nil {
+			_ = json.Unmarshal(output, task)
+		}
+		if task.Title == "" {
+			task.Title = wt.TaskTitle
+		}
+	}
+
+	return func(name, arg string) string {
+		key := name + ":" + arg
+		if v, ok := cache[key]; ok {
+			return v
+		}
+		var v string
+		switch name {
+		case "ticket":
+			v = wt.TaskID
+		case "branch":
+			v = wt.Branch
+		case "base":
+			v = resolveBaseBranch(wt)
+		case "task.title":
+			loadTask()
+			v = task.Title
+		case "task.description":
+			loadTask()
+			v = task.Description
+		case "diffstat":
+			v, _ = getDiffStatFromBase(wt.Path, resolveBaseBranch(wt))
+		case "changed_files":
+			v = changedFilesFromBase(wt.Path, resolveBaseBranch(wt))
+		case "note":
+			v = readNoteContent(workDir, arg)
+		case "file":
+			v = readPromptFile(wt.Path, arg)
+		case "input":
+			v = inputs[arg]
+		}
+		cache[key] = v
+		return v
+	}
+}
+
+// changedFilesFromBase lists files changed since the worktree branched from
+// base, including uncommitted changes.
+func changedFilesFromBase(workdir, base string) string {
+	from := base
+	if mb, err := gitOutput(workdir, "merge-base", base, "HEAD"); err == nil && mb != "" {
+		from = mb
+	}
+	files, err := gitOutput(workdir, "diff", "--name-only", from)
+	if err != nil {
+		return ""
+	}
+	return files
+}
+
+// readNoteContent returns a note's content from the project's notes database.
+func readNoteContent(workDir, id string) string {
+	store, err := notestore.NewStore(notestore.DefaultDBPath(workDir), "")
+	if err != nil {
+		return ""
+	}
+	defer func() { _ = store.Close() }()
+	note, err := store.Get(id)
+	if err != nil || note == nil {
+		return ""
+	}
+	return strings.TrimSpace(note.Content)
+}
+
+// readPromptFile returns the content of a file in the worktree, truncated to
+// promptFileMaxBytes.
+func readPromptFile(worktreePath, rel string) string {
+	f, err := os.Open(filepath.Join(worktreePath, filepath.Clean(rel)))
+	if err != nil {
+		return ""
+	}
+	defer func() { _ = f.Close() }()
+	data, _ := io.ReadAll(io.LimitReader(f, promptFileMaxBytes))
+	return strings.TrimRight(string(data), "\n")
+}
@@ -2,7 +2,6 @@ package workspace
 
 import (
 	"fmt"
-	"os/exec"
 	"strings"
 	"time"
 
@@ -175,6 +174,12 @@ func (p *Plugin) switchTermPanelLayout() tea.Cmd {
 func (p *Plugin) createTermPanelSession(sessionName string) tea.Cmd {
 	workDir := p.termPanelWorkDir()
 
+	var wt *Worktree
+	if !p.shellSelected {
+		wt = p.selectedWorktree()
+	}
+	mainRepo, projectRoot := p.ctx.WorkDir, p.ctx.ProjectRoot
+
 	return func() tea.Msg {
 		// Check if session already exists
 		if sessionExists(sessionName) {
@@ -182,7 +187,7 @@ func (p *Plugin) createTermPanelSession(sessionName string) tea.Cmd {
 			return TermPanelSessionCreatedMsg{SessionName: sessionName, PaneID: paneID}
 		}
 
-		if !isTmuxInstalled() {
+		if !sessionBackend.Available() {
 			return TermPanelSessionCreatedMsg{
 				SessionName: sessionName,
 				Err:         fmt.Errorf("tmux not installed"),
@@ -190,21 +195,21 @@ func (p *Plugin) createTermPanelSession(sessionName string) tea.Cmd {
 		}
 
 		// Create new detached session
-		args := []string{
-			"new-session",
-			"-d",
-			"-s", sessionName,
-			"-c", workDir,
-		}
-		cmd := exec.Command("tmux", args...)
-		if err := cmd.Run(); err != nil {
+		if err := sessionBackend.Create(sessionName, workDir, 0, 0); err != nil {
 			return TermPanelSessionCreatedMsg{
 				SessionName: sessionName,
 				Err:         fmt.Errorf("create terminal panel session: %w", err),
 			}
 		}
 
-		ensureTmuxServerConfig()
+		// Worktree shells get the same isolated env (including allocated ports) as agents
+		if wt != nil {
+			envOverrides := BuildWorktreeEnvOverrides(mainRepo, projectRoot, wt.Path, wt.Branch)
+			if envCmd := GenerateSingleEnvCommand(envOverrides); envCmd != "" {
+				_ = sendSessionLine(sessionName, envCmd)
+			}
+		}
+
 		paneID := getPaneID(sessionName)
 		return TermPanelSessionCreatedMsg{SessionName: sessionName, PaneID: paneID}
 	}
@@ -0,0 +1,260 @@
+package workspace
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	appmsg "github.com/marcus/sidecar/internal/msg"
+	"github.com/marcus/sidecar/internal/projectdir"
+	"github.com/marcus/sidecar/internal/transcript"
+)
+
+// transcriptsDirName is the directory under the project's data directory that
+// holds one transcript directory per session.
+const transcriptsDirName = "transcripts"
+
+// TranscriptsState is the recorded session list shown in the transcripts modal.
+type TranscriptsState struct {
+	Entries       []transcript.Entry
+	Live          map[string]bool // Session name -> still running
+	Cursor        int
+	Loading       bool
+	Err           error
+	ConfirmDelete bool // d pressed; y deletes the selected transcript
+}
+
+// transcriptsLoadedMsg delivers the recorded sessions.
+type transcriptsLoadedMsg struct {
+	Epoch   uint64
+	Entries []transcript.Entry
+	Live    map[string]bool
+	Err     error
+}
+
+// GetEpoch implements plugin.EpochMessage.
+func (m transcriptsLoadedMsg) GetEpoch() uint64 { return m.Epoch }
+
+// transcriptsDir returns where a project's transcripts are stored.
+func transcriptsDir(projectRoot string) (string, error) {
+	dir, err := projectdir.Resolve(projectRoot)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, transcriptsDirName), nil
+}
+
+// transcriptLogging reports whether sessions should be recorded.
+func (p *Plugin) transcriptLogging() bool {
+	return p.ctx != nil && p.ctx.Config != nil && p.ctx.Config.Plugins.Workspace.TranscriptLogging
+}
+
+// transcriptMaxBytes returns the configured rotation size.
+func (p *Plugin) transcriptMaxBytes() int64 {
+	if p.ctx != nil && p.ctx.Config != nil && p.ctx.Config.Plugins.Workspace.TranscriptMaxMB > 0 {
+		return int64(p.ctx.Config.Plugins.Workspace.TranscriptMaxMB) << 20
+	}
+	return transcript.DefaultMaxBytes
+}
+
+// recordSession starts recording a session's transcript when transcript
+// logging is on. Recording is best effort: failures are logged, never shown,
+// and recording a session that is already recorded does nothing.
+func (p *Plugin) recordSession(session, title, kind, path string) tea.Cmd {
+	if !p.transcriptLogging() || session == "" {
+		return nil
+	}
+	projectRoot := p.ctx.ProjectRoot
+	maxBytes := p.transcriptMaxBytes()
+	return func() tea.Msg {
+		root, err := transcriptsDir(projectRoot)
+		if err != nil {
+			slog.Debug("transcript: resolve dir", "session", session, "err", err)
+			return nil
+		}
+		dir := filepath.Join(root, session)
+		meta := transcript.Meta{Session: session, Title: title, Kind: kind, Path: path, Started: time.Now()}
+		if err := transcript.WriteMeta(dir, meta); err != nil {
+			slog.Debug("transcript: write meta", "session", session, "err", err)
+			return nil
+		}
+		if err := sessionBackend.Record(session, dir, maxBytes, transcript.DefaultKeep); err != nil {
+			slog.Debug("transcript: record", "session", session, "err", err)
+		}
+		return nil
+	}
+}
+
+// recordShells starts recording the shells found running at startup.
+func (p *Plugin) recordShells() tea.Cmd {
+	var cmds []tea.Cmd
+	for _, shell := range p.shells {
+		if shell.Agent != nil {
+			cmds = append(cmds, p.recordSession(shell.TmuxName, shell.Name, "shell", p.ctx.WorkDir))
+		}
+	}
+	return tea.Batch(cmds...)
+}
+
+// openTranscripts opens the recorded session list.
+func (p *Plugin) openTranscripts() tea.Cmd {
+	p.transcripts = &TranscriptsState{Loading: true}
+	p.clearTranscriptsModal()
+	p.viewMode = ViewModeTranscripts
+	return p.loadTranscripts()
+}
+
+// closeTranscripts closes the recorded session list.
+func (p *Plugin) closeTranscripts() {
+	p.transcripts = nil
+	p.clearTranscriptsModal()
+	p.viewMode = ViewModeList
+}
+
+// loadTranscripts lists the project's transcripts and which are still live.
+func (p *Plugin) loadTranscripts() tea.Cmd {
+	epoch := p.ctx.Epoch
+	projectRoot := p.ctx.ProjectRoot
+	return func() tea.Msg {
+		root, err := transcriptsDir(projectRoot)
+		if err != nil {
+			return transcriptsLoadedMsg{Epoch: epoch, Err: err}
+		}
+		entries, err := transcript.List(root)
+		if err != nil {
+			return transcriptsLoadedMsg{Epoch: epoch, Err: err}
+		}
+		live := make(map[string]bool)
+		if sessions, err := sessionBackend.List(); err == nil {
+			for _, s := range sessions {
+				live[s] = true
+			}
+		}
+		return transcriptsLoadedMsg{Epoch: epoch, Entries: entries, Live: live}
+	}
+}
+
+// handleTranscriptsLoaded installs the listed transcripts.
+func (p *Plugin) handleTranscriptsLoaded(msg transcriptsLoadedMsg) {
+	s := p.transcripts
+	if s == nil {
+		return
+	}
+	s.Loading = false
+	s.Err = msg.Err
+	s.Entries = msg.Entries
+	s.Live = msg.Live
+	s.Cursor = min(s.Cursor, max(len(s.Entries)-1, 0))
+}
+
+// selectedTranscript returns the transcript under the cursor.
+func (s *TranscriptsState) selectedTranscript() *transcript.Entry {
+	if s == nil || s.Cursor < 0 || s.Cursor >= len(s.Entries) {
+		return nil
+	}
+	return &s.Entries[s.Cursor]
+}
+
+// handleTranscriptsKeys handles keys in the transcripts modal.
+func (p *Plugin) handleTranscriptsKeys(msg tea.KeyMsg) tea.Cmd {
+	s := p.transcripts
+	if s == nil {
+		p.viewMode = ViewModeList
+		return nil
+	}
+
+	if s.ConfirmDelete {
+		s.ConfirmDelete = false
+		if key := msg.String(); key == "y" || key == "enter" {
+			return p.deleteSelectedTranscript()
+		}
+		return nil
+	}
+
+	switch msg.String() {
+	case "esc", "q":
+		p.closeTranscripts()
+	case "j", "down":
+		if s.Cursor < len(s.Entries)-1 {
+			s.Cursor++
+		}
+	case "k", "up":
+		if s.Cursor > 0 {
+			s.Cursor--
+		}
+	case "g":
+		s.Cursor = 0
+	case "G":
+		s.Cursor = max(len(s.Entries)-1, 0)
+	case "enter", "o":
+		return p.openTranscriptSearch()
+	case "d":
+		if s.selectedTranscript() != nil {
+			s.ConfirmDelete = true
+		}
+	case "r":
+		s.Loading = true
+		return p.loadTranscripts()
+	}
+	return nil
+}
+
+// deleteSelectedTranscript removes the selected transcript from disk.
+func (p *Plugin) deleteSelectedTranscript() tea.Cmd {
+	s := p.transcripts
+	entry := s.selectedTranscript()
+	if entry == nil {
+		return nil
+	}
+	if s.Live[entry.Session] && p.transcriptLogging() {
+		return appmsg.ShowToast("Session is still recording", 2*time.Second)
+	}
+	if err := os.RemoveAll(entry.Dir); err != nil {
+		return appmsg.ShowToast("Delete failed: "+err.Error(), 3*time.Second)
+	}
+	s.Entries = append(s.Entries[:s.Cursor], s.Entries[s.Cursor+1:]...)
+	s.Cursor = min(s.Cursor, max(len(s.Entries)-1, 0))
+	return nil
+}
+
+// openTranscriptSearch opens the selected transcript in the scrollback search.
+func (p *Plugin) openTranscriptSearch() tea.Cmd {
+	entry := p.transcripts.selectedTranscript()
+	if entry == nil {
+		return nil
+	}
+	root := entry.Path
+	if root == "" {
+		root = p.ctx.WorkDir
+	}
+
+	input := textinput.New()
+	input.Placeholder = "search transcript"
+	input.Prompt = "/"
+	input.CharLimit = 200
+	input.Focus()
+	p.outputSearch = &OutputSearchState{
+		Title:      entry.Title,
+		Target:     entry.Dir,
+		Transcript: true,
+		Root:       root,
+		Loading:    true,
+		Input:      input,
+		Editing:    true,
+	}
+	p.viewMode = ViewModeOutputSearch
+	return p.loadOutputHistory(p.outputSearch)
+}
+
+// readTranscriptLines reads a transcript as display lines.
+func readTranscriptLines(dir string) ([]string, error) {
+	text, err := transcript.ReadText(dir)
+	if err != nil {
+		return nil, err
+	}
+	return strings.Split(strings.TrimRight(text, "\n"), "\n"), nil
+}
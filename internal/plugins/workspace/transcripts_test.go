@@ -0,0 +1,68 @@
+package workspace
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/marcus/sidecar/internal/plugin"
+	"github.com/marcus/sidecar/internal/transcript"
+)
+
+func TestTranscriptsBrowse(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "sidecar-ws-feature")
+	if err := transcript.WriteMeta(dir, transcript.Meta{Session: "sidecar-ws-feature", Title: "feature", Kind: "agent"}); err != nil {
+		t.Fatal(err)
+	}
+	w, err := transcript.NewWriter(dir, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = w.Write([]byte("\x1b[31mFAIL\x1b[0m\tpkg/x_test.go:12\r\nok\r\n"))
+	_ = w.Close()
+	entries, err := transcript.List(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p := &Plugin{ctx: &plugin.Context{WorkDir: "/repo"}, height: 20}
+	p.openTranscripts()
+	p.handleTranscriptsLoaded(transcriptsLoadedMsg{Entries: entries})
+	if p.viewMode != ViewModeTranscripts || p.FocusContext() != "workspace-transcripts" {
+		t.Fatalf("viewMode = %v, context %q", p.viewMode, p.FocusContext())
+	}
+
+	cmd := p.handleTranscriptsKeys(tea.KeyMsg{Type: tea.KeyEnter})
+	s := p.outputSearch
+	if cmd == nil || s == nil || !s.Transcript || s.Root != "/repo" {
+		t.Fatalf("enter should open the transcript in the search, got %+v", s)
+	}
+	p.handleOutputHistory(cmd().(outputHistoryMsg))
+	if len(s.Lines) != 2 || !strings.HasPrefix(s.Lines[0], "FAIL    pkg/x_test.go:12") {
+		t.Errorf("lines = %q, want plain text with tabs expanded", s.Lines)
+	}
+
+	p.handleOutputSearchKeys(tea.KeyMsg{Type: tea.KeyEsc})
+	if p.viewMode != ViewModeTranscripts {
+		t.Fatal("closing a transcript search should return to the list")
+	}
+
+	p.handleTranscriptsKeys(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'d'}})
+	p.handleTranscriptsKeys(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'n'}})
+	if _, err := os.Stat(dir); err != nil {
+		t.Fatal("any key but y should cancel the delete")
+	}
+	p.handleTranscriptsKeys(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'d'}})
+	p.handleTranscriptsKeys(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'y'}})
+	if _, err := os.Stat(dir); !os.IsNotExist(err) || len(p.transcripts.Entries) != 0 {
+		t.Error("d then y should delete the transcript")
+	}
+
+	p.handleTranscriptsKeys(tea.KeyMsg{Type: tea.KeyEsc})
+	if p.transcripts != nil || p.viewMode != ViewModeList {
+		t.Error("esc should close the transcripts")
+	}
+}
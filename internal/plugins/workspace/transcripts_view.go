@@ -0,0 +1,145 @@
+package workspace
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/marcus/sidecar/internal/modal"
+	"github.com/marcus/sidecar/internal/styles"
+	"github.com/marcus/sidecar/internal/ui"
+)
+
+// transcriptsMaxVisible caps the transcripts listed at once.
+const transcriptsMaxVisible = 12
+
+// renderTranscriptsModal renders the transcripts modal over the list view.
+func (p *Plugin) renderTranscriptsModal(width, height int) string {
+	background := p.renderListView(width, height)
+
+	p.ensureTranscriptsModal()
+	if p.transcriptsModal == nil {
+		return background
+	}
+
+	modalContent := p.transcriptsModal.Render(width, height, p.mouseHandler)
+	return ui.OverlayModal(background, modalContent, width, height)
+}
+
+// ensureTranscriptsModal builds/rebuilds the transcripts modal.
+func (p *Plugin) ensureTranscriptsModal() {
+	if p.transcripts == nil {
+		return
+	}
+
+	modalW := 76
+	if modalW > p.width-4 {
+		modalW = p.width - 4
+	}
+	if modalW < 20 {
+		modalW = 20
+	}
+
+	if p.transcriptsModal != nil && p.transcriptsModalWidth == modalW {
+		return
+	}
+	p.transcriptsModalWidth = modalW
+
+	p.transcriptsModal = modal.New("Transcripts",
+		modal.WithWidth(modalW),
+		modal.WithHints(false),
+	).
+		AddSection(p.transcriptsSection())
+}
+
+// clearTranscriptsModal invalidates the cached modal so it rebuilds next frame.
+func (p *Plugin) clearTranscriptsModal() {
+	p.transcriptsModal = nil
+	p.transcriptsModalWidth = 0
+}
+
+// transcriptsSection lists the recorded sessions, newest first.
+func (p *Plugin) transcriptsSection() modal.Section {
+	return modal.Custom(func(contentWidth int, focusID, hoverID string) modal.RenderedSection {
+		s := p.transcripts
+		if s == nil {
+			return modal.RenderedSection{}
+		}
+
+		var lines []string
+		switch {
+		case s.Loading:
+			lines = append(lines, dimText("Loading transcripts..."))
+		case s.Err != nil:
+			lines = append(lines, lipgloss.NewStyle().Foreground(styles.Error).Render(s.Err.Error()))
+		case len(s.Entries) == 0:
+			lines = append(lines, dimText("No transcripts recorded"))
+			if !p.transcriptLogging() {
+				lines = append(lines, dimText("Set plugins.workspace.transcriptLogging to record sessions."))
+			}
+		default:
+			offset := 0
+			if s.Cursor >= transcriptsMaxVisible {
+				offset = s.Cursor - transcriptsMaxVisible + 1
+			}
+			end := min(offset+transcriptsMaxVisible, len(s.Entries))
+			for i := offset; i < end; i++ {
+				lines = append(lines, p.renderTranscriptLine(i, contentWidth))
+			}
+			if remaining := len(s.Entries) - end; remaining > 0 {
+				lines = append(lines, dimText(fmt.Sprintf("  ... %d more", remaining)))
+			}
+		}
+
+		lines = append(lines, "")
+		if s.ConfirmDelete {
+			entry := s.selectedTranscript()
+			if entry != nil {
+				lines = append(lines, lipgloss.NewStyle().Foreground(styles.Warning).Render(
+					fmt.Sprintf("Delete transcript of %s? y: delete  any other key: cancel", entry.Title)))
+			}
+		} else {
+			lines = append(lines, dimText("enter: open  d: delete  r: refresh  esc: close"))
+		}
+		return modal.RenderedSection{Content: strings.Join(lines, "\n")}
+	}, nil)
+}
+
+// renderTranscriptLine renders one recorded session:
+// "> feature-auth  agent  1.2MB  3h ago  live".
+func (p *Plugin) renderTranscriptLine(i, width int) string {
+	s := p.transcripts
+	entry := s.Entries[i]
+
+	prefix := "  "
+	if i == s.Cursor {
+		prefix = "> "
+	}
+	details := fmt.Sprintf("%-5s  %7s  %s", entry.Kind, formatTranscriptSize(entry.Size), formatRelativeTime(entry.Modified))
+	if s.Live[entry.Session] {
+		details += "  live"
+	}
+	titleW := max(width-len(prefix)-ansi.StringWidth(details)-2, 8)
+	title := ansi.Truncate(entry.Title, titleW, "…")
+	line := fmt.Sprintf("%s%-*s  %s", prefix, titleW, title, details)
+
+	if i == s.Cursor {
+		return lipgloss.NewStyle().Foreground(styles.Primary).Render(line)
+	}
+	return dimText(line)
+}
+
+// formatTranscriptSize formats a transcript size in human-readable form.
+func formatTranscriptSize(bytes int64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%dB", bytes)
+	}
+	div, exp := int64(unit), 0
+	for n := bytes / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f%cB", float64(bytes)/float64(div), "KMGTPE"[exp])
+}
@@ -39,6 +39,14 @@ const (
 	ViewModeInteractive                    // Interactive mode (tmux input passthrough)
 	ViewModeFetchPR                        // Fetch remote PR modal
 	ViewModeAgentConfig                    // Agent config modal (start/restart with options)
+	ViewModeFanOut                         // Fan-out modal (one prompt, several agents)
+	ViewModeFanOutBoard                    // Fan-out comparison board
+	ViewModePromptQueue                    // Prompt queue editor modal
+	ViewModeSyncConflict                   // Base branch sync conflict modal
+	ViewModeOutputSearch                   // Search over a session's scrollback
+	ViewModeTranscripts                    // Recorded session transcripts modal
+	ViewModeReviewComment                  // Diff line comment editor modal
+	ViewModeReview                         // Review comments list modal
 )
 
 // FocusPane represents which pane is active in the split view.
@@ -271,9 +279,10 @@ type Worktree struct {
 	Stats           *GitStats      // +/- line counts
 	CreatedAt       time.Time
 	UpdatedAt       time.Time
-	IsOrphaned      bool // True if agent file exists but tmux session is gone
-	IsMain          bool // True if this is the primary/main worktree (project root)
-	IsMissing       bool // True if worktree directory no longer exists (detected via os.Stat or git prunable)
+	IsOrphaned      bool         // True if agent file exists but tmux session is gone
+	IsMain          bool         // True if this is the primary/main worktree (project root)
+	IsMissing       bool         // True if worktree directory no longer exists (detected via os.Stat or git prunable)
+	Queue           *PromptQueue // Prompts sent to the agent as it goes idle (nil if none)
 }
 
 // ShellSession represents a tmux shell session (not tied to a git worktree).
@@ -282,9 +291,10 @@ type ShellSession struct {
 	TmuxName    string    // tmux session name (e.g., "sidecar-sh-project-1")
 	Agent       *Agent    // Reuses Agent struct for tmux state
 	CreatedAt   time.Time
-	ChosenAgent AgentType // td-317b64: Agent type selected at creation (AgentNone for plain shell)
-	SkipPerms   bool      // td-317b64: Whether skip permissions was enabled
-	IsOrphaned  bool      // td-f88fdd: True if manifest entry exists but tmux session is gone
+	ChosenAgent AgentType    // td-317b64: Agent type selected at creation (AgentNone for plain shell)
+	SkipPerms   bool         // td-317b64: Whether skip permissions was enabled
+	IsOrphaned  bool         // td-f88fdd: True if manifest entry exists but tmux session is gone
+	Queue       *PromptQueue // Prompts sent to the shell's agent as it goes idle (nil if none)
 }
 
 // Agent represents an AI coding agent process.
@@ -297,7 +307,8 @@ type Agent struct {
 	LastOutput  time.Time     // Last time output was detected
 	OutputBuf   *OutputBuffer // Last N lines of output
 	Status      AgentStatus
-	WaitingFor  string // Prompt text if waiting
+	WaitingFor  string   // Prompt text if waiting
+	WaitingTool toolCall // Tool call the prompt is about, when hooks report it
 
 	// Runaway detection fields (td-018f25)
 	// Track recent poll times to detect continuous output that would cause CPU spikes.
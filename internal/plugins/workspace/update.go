@@ -79,8 +79,10 @@ func (p *Plugin) Update(msg tea.Msg) (plugin.Plugin, tea.Cmd) {
 			if p.selectedIdx >= 0 && p.selectedIdx < len(p.worktrees) {
 				selectedName = p.worktrees[p.selectedIdx].Name
 			}
+			selectedArchive := p.selectedArchive()
 
 			p.worktrees = msg.Worktrees
+			p.attachWorktreeQueues()
 
 			// Restore selection by finding the worktree with the same name
 			if selectedName != "" {
@@ -126,9 +128,18 @@ func (p *Plugin) Update(msg tea.Msg) (plugin.Plugin, tea.Cmd) {
 				go func() { _ = migration.MigrateProject(p.ctx.ProjectRoot, wtPaths) }()
 			}
 
+			// Archived entries follow the worktrees, so their index shifts
+			if selectedArchive != nil {
+				for i, a := range p.archives {
+					if a == selectedArchive {
+						p.selectedIdx = len(p.worktrees) + i
+					}
+				}
+			}
+
 			// Bounds check in case the selected worktree was deleted
-			if p.selectedIdx >= len(p.worktrees) && len(p.worktrees) > 0 {
-				p.selectedIdx = len(p.worktrees) - 1
+			if p.selectedIdx >= p.sidebarItemCount() && p.sidebarItemCount() > 0 {
+				p.selectedIdx = p.sidebarItemCount() - 1
 			}
 
 			// Preserve agent pointers from existing agents map
@@ -143,6 +154,9 @@ func (p *Plugin) Update(msg tea.Msg) (plugin.Plugin, tea.Cmd) {
 					continue // Skip metadata for worktrees with missing directories
 				}
 				cmds = append(cmds, p.loadStats(wt.Path))
+				if cmd := p.maybeLoadUsage(wt); cmd != nil {
+					cmds = append(cmds, cmd)
+				}
 				// Load linked task ID from centralized worktree data directory
 				wt.TaskID = loadTaskLink(p.ctx.ProjectRoot, wt.Path)
 				// Load chosen agent type from centralized worktree data directory
@@ -166,9 +180,58 @@ func (p *Plugin) Update(msg tea.Msg) (plugin.Plugin, tea.Cmd) {
 		}
 
 	case ConflictsDetectedMsg:
+		if plugin.IsStale(p.ctx, msg) {
+			return p, nil
+		}
 		if msg.Err == nil {
 			p.conflicts = msg.Conflicts
 		}
+		return p, p.autoSyncWorktrees(msg.Tips)
+
+	case conflictCheckTickMsg:
+		return p, p.handleConflictCheckTick(msg)
+
+	case outputHistoryMsg:
+		if !plugin.IsStale(p.ctx, msg) {
+			p.handleOutputHistory(msg)
+		}
+		return p, nil
+
+	case taskBoardLoadedMsg:
+		if !plugin.IsStale(p.ctx, msg) {
+			return p, p.handleTaskBoardLoaded(msg)
+		}
+		return p, nil
+
+	case taskTransitionedMsg:
+		if !plugin.IsStale(p.ctx, msg) {
+			return p, p.handleTaskTransitioned(msg)
+		}
+		return p, nil
+
+	case transcriptsLoadedMsg:
+		if !plugin.IsStale(p.ctx, msg) {
+			p.handleTranscriptsLoaded(msg)
+		}
+		return p, nil
+
+	case WorktreesSyncedMsg:
+		return p, p.handleWorktreesSynced(msg)
+
+	case syncAbortedMsg:
+		return p, p.handleSyncAborted(msg)
+
+	case stackRetargetedMsg:
+		return p, p.handleStackRetargeted(msg)
+
+	case stackPRsCreatedMsg:
+		return p, p.handleStackPRsCreated(msg)
+
+	case ContextUsageMsg:
+		return p, p.handleContextUsage(msg)
+
+	case WorktreeUsageMsg:
+		p.handleWorktreeUsage(msg)
 
 	case StatsLoadedMsg:
 		// Discard stale messages from previous project
@@ -212,8 +275,16 @@ func (p *Plugin) Update(msg tea.Msg) (plugin.Plugin, tea.Cmd) {
 			if p.commitStatusWorktree != msg.WorkspaceName || len(p.commitStatusList) == 0 {
 				cmds = append(cmds, p.loadCommitStatus(p.selectedWorktree()))
 			}
+			// Follow or resolve review comments whose lines changed
+			cmds = append(cmds, p.checkReviewComments(p.selectedWorktree(), msg.Raw))
 		}
 
+	case ReviewCommentsCheckedMsg:
+		p.handleReviewCommentsChecked(msg)
+
+	case ReviewSentMsg:
+		cmds = append(cmds, p.handleReviewSent(msg))
+
 	case FullFileDiffLoadedMsg:
 		if plugin.IsStale(p.ctx, msg) {
 			return p, nil
@@ -292,36 +363,43 @@ func (p *Plugin) Update(msg tea.Msg) (plugin.Plugin, tea.Cmd) {
 			}
 		}
 
+	case ProvisionProgressMsg:
+		if !plugin.IsStale(p.ctx, msg) && p.createProvisioning && msg.Index < len(p.createProvision) {
+			p.createProvision[msg.Index] = msg.Status
+		}
+		cmds = append(cmds, listenForProvisionProgress(msg.progress))
+
 	case CreateDoneMsg:
+		p.createProvisioning = false
 		if msg.Err != nil {
 			p.createError = msg.Err.Error()
+			p.createProvision = nil
 			// Stay in ViewModeCreate - don't close modal or clear state
+		} else if provisionFailures(msg.Provision) > 0 && p.viewMode == ViewModeCreate {
+			// Keep the modal open so failed setup steps can be read
+			p.createProvision = msg.Provision
+			p.createProvisionDone = &msg
 		} else {
-			p.viewMode = ViewModeList
-			p.worktrees = append(p.worktrees, msg.Worktree)
+			cmds = append(cmds, p.finishCreate(msg))
+		}
 
-			// Auto-focus newly created worktree (same pattern as click selection)
-			p.shellSelected = false
-			p.selectedIdx = len(p.worktrees) - 1
-			p.previewOffset = 0
-			p.autoScrollOutput = true
-			p.resetScrollBaseLineCount() // td-f7c8be: clear snapshot for new selection
-			p.saveSelectionState()
-			p.ensureVisible()
+	case WorktreeArchivedMsg:
+		cmds = append(cmds, p.handleWorktreeArchived(msg))
 
-			p.clearCreateModal()
+	case ArchiveRestoredMsg:
+		cmds = append(cmds, p.handleArchiveRestored(msg))
 
-			// Load content for preview pane
-			cmds = append(cmds, p.loadSelectedContent())
+	case QueuedPromptSentMsg:
+		return p, p.handleQueuedPromptSent(msg)
 
-			// Start agent or attach based on selection
-			if msg.AgentType != AgentNone && msg.AgentType != "" {
-				cmds = append(cmds, p.StartAgentWithOptions(msg.Worktree, msg.AgentType, msg.SkipPerms, msg.Prompt))
-			} else {
-				// "None" selected - attach to worktree directory
-				cmds = append(cmds, p.AttachToWorktreeDir(msg.Worktree))
-			}
-		}
+	case FanOutCreatedMsg:
+		cmds = append(cmds, p.handleFanOutCreated(msg))
+
+	case FanOutMetricsMsg:
+		p.handleFanOutMetrics(msg)
+
+	case FanOutTestDoneMsg:
+		p.handleFanOutTestDone(msg)
 
 	case PromptSelectedMsg:
 		// Prompt selected from picker
@@ -335,6 +413,7 @@ func (p *Plugin) Update(msg tea.Msg) (plugin.Plugin, tea.Cmd) {
 				for i, pr := range p.agentConfigPrompts {
 					if pr.Name == msg.Prompt.Name {
 						p.agentConfigPromptIdx = i
+						p.agentConfigPrompts[i].Inputs = msg.Prompt.Inputs
 						break
 					}
 				}
@@ -348,6 +427,7 @@ func (p *Plugin) Update(msg tea.Msg) (plugin.Plugin, tea.Cmd) {
 				for i, pr := range p.createPrompts {
 					if pr.Name == msg.Prompt.Name {
 						p.createPromptIdx = i
+						p.createPrompts[i].Inputs = msg.Prompt.Inputs
 						break
 					}
 				}
@@ -460,8 +540,16 @@ func (p *Plugin) Update(msg tea.Msg) (plugin.Plugin, tea.Cmd) {
 			p.deleteWarnings = []string{fmt.Sprintf("Delete failed: %v", msg.Err)}
 			break
 		}
+		if wt := p.findWorktree(msg.Name); wt != nil {
+			p.releaseWorktreeEnv(wt.Path)
+		}
 		p.removeWorktreeByName(msg.Name)
-		if p.selectedIdx >= len(p.worktrees) && p.selectedIdx > 0 {
+		p.removeFanOutMember(msg.Name)
+		p.removeWorktreeQueue(msg.Name)
+		delete(p.reviews, msg.Name)
+		delete(p.autoAnswered, msg.Name)
+		delete(p.usage, msg.Name)
+		if p.selectedIdx >= p.sidebarItemCount() && p.selectedIdx > 0 {
 			p.selectedIdx--
 		}
 		// Store any warnings for display
@@ -503,13 +591,16 @@ func (p *Plugin) Update(msg tea.Msg) (plugin.Plugin, tea.Cmd) {
 				OutputBuf:   NewOutputBuffer(outputBufferCap),
 			}
 
+			wtPath := ""
 			if wt := p.findWorktree(msg.WorkspaceName); wt != nil {
 				wt.Agent = agent
 				wt.Status = StatusActive
 				wt.IsOrphaned = false
+				wtPath = wt.Path
 			}
 			p.agents[msg.WorkspaceName] = agent
 			p.managedSessions[msg.SessionName] = true
+			cmds = append(cmds, p.recordSession(msg.SessionName, msg.WorkspaceName, "agent", wtPath))
 
 			// Resize pane to match preview width immediately
 			if cmd := p.resizeSelectedPaneCmd(); cmd != nil {
@@ -545,9 +636,22 @@ func (p *Plugin) Update(msg tea.Msg) (plugin.Plugin, tea.Cmd) {
 		if wt := p.findWorktree(msg.WorkspaceName); wt != nil && wt.Agent != nil {
 			wt.Agent.LastOutput = time.Now()
 			wt.Agent.WaitingFor = msg.WaitingFor
+			wt.Agent.WaitingTool = msg.WaitingTool
 			wt.Status = msg.Status
 			// Track poll time for runaway detection (td-018f25)
 			wt.Agent.RecordPollTime()
+			// A prompt the approval policy answers isn't an idle agent
+			if cmd := p.maybeAutoAnswer(wt); cmd != nil {
+				cmds = append(cmds, cmd)
+			} else if cmd := p.maybeDispatchWorktreeQueue(wt); cmd != nil {
+				cmds = append(cmds, cmd)
+			}
+		}
+		if cmd := p.maybeCheckContextUsage(msg.WorkspaceName); cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+		if cmd := p.maybeLoadUsage(p.findWorktree(msg.WorkspaceName)); cmd != nil {
+			cmds = append(cmds, cmd)
 		}
 		// Update bracketed paste mode and cursor position if in interactive mode (td-79ab6163)
 		if p.viewMode == ViewModeInteractive && !p.shellSelected {
@@ -616,6 +720,19 @@ func (p *Plugin) Update(msg tea.Msg) (plugin.Plugin, tea.Cmd) {
 			// (e.g., agent finishes but terminal output stays the same).
 			wt.Status = msg.CurrentStatus
 			wt.Agent.WaitingFor = msg.WaitingFor
+			wt.Agent.WaitingTool = msg.WaitingTool
+			// A prompt the approval policy answers isn't an idle agent
+			if cmd := p.maybeAutoAnswer(wt); cmd != nil {
+				cmds = append(cmds, cmd)
+			} else if cmd := p.maybeDispatchWorktreeQueue(wt); cmd != nil {
+				cmds = append(cmds, cmd)
+			}
+		}
+		if cmd := p.maybeCheckContextUsage(msg.WorkspaceName); cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+		if cmd := p.maybeLoadUsage(p.findWorktree(msg.WorkspaceName)); cmd != nil {
+			cmds = append(cmds, cmd)
 		}
 		// Content unchanged - use longer interval based on current status
 		interval := pollIntervalIdle
@@ -753,6 +870,9 @@ func (p *Plugin) Update(msg tea.Msg) (plugin.Plugin, tea.Cmd) {
 		}
 		// Start polling for output using stable TmuxName
 		cmds = append(cmds, p.scheduleShellPollByName(msg.SessionName, 500*time.Millisecond))
+		if shell := p.findShellByName(msg.SessionName); shell != nil {
+			cmds = append(cmds, p.recordSession(msg.SessionName, shell.Name, "shell", p.ctx.WorkDir))
+		}
 
 		// If there's a pending resume command, inject it and enter interactive mode (td-aa4136)
 		if p.pendingResumeCmd != "" {
@@ -996,6 +1116,9 @@ func (p *Plugin) Update(msg tea.Msg) (plugin.Plugin, tea.Cmd) {
 			if shell.ChosenAgent != AgentNone && shell.ChosenAgent != "" && msg.Status != 0 {
 				shell.Agent.Status = msg.Status
 				shell.Agent.WaitingFor = msg.WaitingFor
+				if cmd := p.maybeDispatchShellQueue(shell); cmd != nil {
+					cmds = append(cmds, cmd)
+				}
 			}
 		}
 		// Update bracketed paste mode and cursor position if in interactive mode (td-79ab6163)
@@ -1143,16 +1266,23 @@ func (p *Plugin) Update(msg tea.Msg) (plugin.Plugin, tea.Cmd) {
 			// Clear waiting state, force immediate poll
 			if wt := p.findWorktree(msg.WorkspaceName); wt != nil && wt.Agent != nil {
 				wt.Agent.WaitingFor = ""
+				wt.Agent.WaitingTool = toolCall{}
 				wt.Status = StatusActive
 			}
 			cmds = append(cmds, p.scheduleAgentPoll(msg.WorkspaceName, 0))
 		}
 
+	case ApprovalAnsweredMsg:
+		if cmd := p.handleApprovalAnswered(msg); cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+
 	case RejectResultMsg:
 		if msg.Err == nil {
 			// Clear waiting state, force immediate poll
 			if wt := p.findWorktree(msg.WorkspaceName); wt != nil && wt.Agent != nil {
 				wt.Agent.WaitingFor = ""
+				wt.Agent.WaitingTool = toolCall{}
 				wt.Status = StatusActive
 			}
 			cmds = append(cmds, p.scheduleAgentPoll(msg.WorkspaceName, 0))
@@ -1166,6 +1296,9 @@ func (p *Plugin) Update(msg tea.Msg) (plugin.Plugin, tea.Cmd) {
 				if msg.TaskID != "" {
 					cmds = append(cmds, p.loadTaskDetails(msg.TaskID))
 				}
+				if p.kanbanByTask {
+					cmds = append(cmds, p.loadTaskBoard())
+				}
 			}
 		}
 
@@ -1276,6 +1409,7 @@ func (p *Plugin) Update(msg tea.Msg) (plugin.Plugin, tea.Cmd) {
 				case MergeStepCleanup:
 					// Cleanup done, mark done and remove from worktree list
 					p.mergeState.StepStatus[msg.Step] = "done"
+					p.releaseWorktreeEnv(p.mergeState.Worktree.Path)
 					p.removeWorktreeByName(msg.WorkspaceName)
 					if p.selectedIdx >= len(p.worktrees) && p.selectedIdx > 0 {
 						p.selectedIdx--
@@ -1313,6 +1447,15 @@ func (p *Plugin) Update(msg tea.Msg) (plugin.Plugin, tea.Cmd) {
 			cmds = append(cmds, p.schedulePRGenerationTick(msg.WorkspaceName))
 		}
 
+	case MergeTestDoneMsg:
+		cmds = append(cmds, p.handleMergeTestDone(msg))
+
+	case mergeTestTickMsg:
+		if p.mergeTestsRunning(msg.WorkspaceName) {
+			p.clearMergeModal() // Force modal rebuild to show new output
+			cmds = append(cmds, p.scheduleMergeTestTick(msg.WorkspaceName))
+		}
+
 	case CheckPRMergedMsg:
 		if p.mergeState != nil && p.mergeState.Worktree.Name == msg.WorkspaceName {
 			if msg.Err != nil {
@@ -1357,6 +1500,7 @@ func (p *Plugin) Update(msg tea.Msg) (plugin.Plugin, tea.Cmd) {
 
 			// Remove worktree from list if deleted
 			if msg.Results.LocalWorktreeDeleted {
+				p.releaseWorktreeEnv(p.mergeState.Worktree.Path)
 				p.removeWorktreeByName(msg.WorkspaceName)
 				if p.selectedIdx >= len(p.worktrees) && p.selectedIdx > 0 {
 					p.selectedIdx--
@@ -1423,6 +1567,8 @@ func (p *Plugin) Update(msg tea.Msg) (plugin.Plugin, tea.Cmd) {
 				p.mergeState.CleanupResults.PullErrorFull = full
 				p.mergeState.CleanupResults.BranchDiverged = diverged
 			}
+		} else if p.syncState != nil && p.syncState.Worktree.Name == msg.WorkspaceName {
+			return p, p.handleSyncResolution(syncRebase, msg.Success, msg.Err, msg.Conflicts)
 		}
 
 	case MergeResolutionMsg:
@@ -1442,6 +1588,8 @@ func (p *Plugin) Update(msg tea.Msg) (plugin.Plugin, tea.Cmd) {
 				p.mergeState.CleanupResults.PullErrorFull = full
 				p.mergeState.CleanupResults.BranchDiverged = diverged
 			}
+		} else if p.syncState != nil && p.syncState.Worktree.Name == msg.WorkspaceName {
+			return p, p.handleSyncResolution(syncMerge, msg.Success, msg.Err, msg.Conflicts)
 		}
 
 	case reconnectedAgentsMsg:
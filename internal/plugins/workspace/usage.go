@@ -0,0 +1,243 @@
+package workspace
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/marcus/sidecar/internal/adapter"
+	"github.com/marcus/sidecar/internal/app"
+	appmsg "github.com/marcus/sidecar/internal/msg"
+	"github.com/marcus/sidecar/internal/plugin"
+	"github.com/marcus/sidecar/internal/sessionquery"
+)
+
+// usageCheckInterval throttles session scans per worktree. Totals only move
+// once per agent response and a scan reads every session in the worktree.
+const usageCheckInterval = 30 * time.Second
+
+// WorktreeUsage is what the agent sessions that ran in a worktree have used.
+type WorktreeUsage struct {
+	Sessions     int // Top-level sessions; sub-agents count toward tokens and cost only
+	Tokens       int
+	Cost         float64
+	LastActivity time.Time
+	LatestID     string // Most recently active top-level session
+}
+
+// usageState tracks the latest usage and check time for a worktree.
+type usageState struct {
+	usage     *WorktreeUsage
+	checkedAt time.Time
+}
+
+// WorktreeUsageMsg delivers session usage for a worktree.
+type WorktreeUsageMsg struct {
+	Epoch         uint64
+	WorkspaceName string
+	Usage         *WorktreeUsage // nil if no session ran in the worktree
+}
+
+// GetEpoch implements plugin.EpochMessage.
+func (m WorktreeUsageMsg) GetEpoch() uint64 { return m.Epoch }
+
+// usageFor returns the last known session usage for a worktree.
+func (p *Plugin) usageFor(name string) *WorktreeUsage {
+	if st := p.usage[name]; st != nil {
+		return st.usage
+	}
+	return nil
+}
+
+// maybeLoadUsage returns a command totalling a worktree's agent sessions, at
+// most once per usageCheckInterval per worktree.
+func (p *Plugin) maybeLoadUsage(wt *Worktree) tea.Cmd {
+	if wt == nil || wt.IsMain || wt.IsMissing || p.ctx == nil || len(p.ctx.Adapters) == 0 {
+		return nil
+	}
+	if p.usage == nil {
+		p.usage = make(map[string]*usageState)
+	}
+	st := p.usage[wt.Name]
+	if st == nil {
+		st = &usageState{}
+		p.usage[wt.Name] = st
+	}
+	if time.Since(st.checkedAt) < usageCheckInterval {
+		return nil
+	}
+	st.checkedAt = time.Now()
+
+	epoch := p.ctx.Epoch
+	name, path := wt.Name, wt.Path
+	adapters := p.ctx.Adapters
+	return func() tea.Msg {
+		return WorktreeUsageMsg{Epoch: epoch, WorkspaceName: name, Usage: computeWorktreeUsage(adapters, path)}
+	}
+}
+
+// handleWorktreeUsage stores usage for a worktree.
+func (p *Plugin) handleWorktreeUsage(msg WorktreeUsageMsg) {
+	if plugin.IsStale(p.ctx, msg) {
+		return
+	}
+	if st := p.usage[msg.WorkspaceName]; st != nil {
+		st.usage = msg.Usage
+	}
+}
+
+// computeWorktreeUsage totals the sessions each adapter reports for a
+// worktree path. Returns nil when no session ran there.
+func computeWorktreeUsage(adapters map[string]adapter.Adapter, worktreePath string) *WorktreeUsage {
+	var u WorktreeUsage
+	found := false
+	for id, a := range adapters {
+		sessions, err := a.Sessions(worktreePath)
+		if err != nil {
+			continue
+		}
+		for _, s := range sessions {
+			found = true
+			tokens, cost := s.TotalTokens, s.EstCost
+			if tokens == 0 || cost == 0 {
+				mt, mc := sessionUsageFromMessages(id, a, s)
+				if tokens == 0 {
+					tokens = mt
+				}
+				if cost == 0 {
+					cost = mc
+				}
+			}
+			u.Tokens += tokens
+			u.Cost += cost
+			if s.IsSubAgent {
+				continue
+			}
+			u.Sessions++
+			if s.UpdatedAt.After(u.LastActivity) {
+				u.LastActivity = s.UpdatedAt
+				u.LatestID = s.ID
+			}
+		}
+	}
+	if !found {
+		return nil
+	}
+	return &u
+}
+
+// sessionUsageEntry caches usage read from an unchanged session's messages.
+type sessionUsageEntry struct {
+	updatedAt time.Time
+	tokens    int
+	cost      float64
+}
+
+var sessionUsageCache = struct {
+	mu      sync.Mutex
+	entries map[string]sessionUsageEntry
+}{
+	entries: make(map[string]sessionUsageEntry),
+}
+
+// sessionUsageFromMessages sums tokens and cost from a session's messages,
+// for adapters whose session list doesn't carry them.
+func sessionUsageFromMessages(adapterID string, a adapter.Adapter, s adapter.Session) (int, float64) {
+	key := adapterID + "/" + s.ID
+	sessionUsageCache.mu.Lock()
+	entry, hit := sessionUsageCache.entries[key]
+	sessionUsageCache.mu.Unlock()
+	if hit && entry.updatedAt.Equal(s.UpdatedAt) {
+		return entry.tokens, entry.cost
+	}
+
+	msgs, err := a.Messages(s.ID)
+	if err != nil {
+		return 0, 0
+	}
+	entry = sessionUsageEntry{updatedAt: s.UpdatedAt, cost: sessionquery.DetailsFromMessages(msgs).Cost}
+	for _, m := range msgs {
+		entry.tokens += m.InputTokens + m.OutputTokens
+	}
+
+	sessionUsageCache.mu.Lock()
+	sessionUsageCache.entries[key] = entry
+	sessionUsageCache.mu.Unlock()
+	return entry.tokens, entry.cost
+}
+
+// jumpToConversation opens a worktree's most recent session in the
+// conversations plugin.
+func (p *Plugin) jumpToConversation(wt *Worktree) tea.Cmd {
+	if wt == nil {
+		return nil
+	}
+	u := p.usageFor(wt.Name)
+	if u == nil || u.LatestID == "" {
+		return appmsg.ShowToast("No conversations in "+wt.Name+" yet", 2*time.Second)
+	}
+	sessionID := u.LatestID
+	return tea.Batch(
+		app.FocusPlugin("conversations"),
+		func() tea.Msg {
+			return app.FocusConversationMsg{SessionID: sessionID}
+		},
+	)
+}
+
+// formatUsageCost renders a cost estimate in dollars.
+func formatUsageCost(cost float64) string {
+	if cost > 0 && cost < 0.01 {
+		return "<$0.01"
+	}
+	return fmt.Sprintf("$%.2f", cost)
+}
+
+// formatUsageTokens renders a token count compactly (e.g. 12k, 1.4M).
+func formatUsageTokens(n int) string {
+	switch {
+	case n >= 1_000_000:
+		return fmt.Sprintf("%.1fM", float64(n)/1_000_000)
+	case n >= 1000:
+		return fmt.Sprintf("%dk", n/1000)
+	default:
+		return fmt.Sprintf("%d", n)
+	}
+}
+
+// sidebarUsage is the compact usage shown on a sidebar row.
+func sidebarUsage(u *WorktreeUsage) string {
+	switch {
+	case u == nil || u.Sessions == 0:
+		return ""
+	case u.Cost > 0:
+		return formatUsageCost(u.Cost)
+	case u.Tokens > 0:
+		return formatUsageTokens(u.Tokens) + " tok"
+	default:
+		return ""
+	}
+}
+
+// usageSummary is the usage line shown in the preview header.
+func usageSummary(u *WorktreeUsage) string {
+	if u == nil || u.Sessions == 0 {
+		return ""
+	}
+	noun := "sessions"
+	if u.Sessions == 1 {
+		noun = "session"
+	}
+	s := fmt.Sprintf("%d %s · %s tokens", u.Sessions, noun, formatUsageTokens(u.Tokens))
+	if u.Cost > 0 {
+		s += " · " + formatUsageCost(u.Cost)
+	}
+	if ago := formatRelativeTime(u.LastActivity); ago != "" {
+		if ago != "now" {
+			ago += " ago"
+		}
+		s += " · " + ago
+	}
+	return s
+}
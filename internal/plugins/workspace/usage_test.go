@@ -0,0 +1,120 @@
+package workspace
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/marcus/sidecar/internal/adapter"
+	"github.com/marcus/sidecar/internal/app"
+	"github.com/marcus/sidecar/internal/plugin"
+)
+
+// usageTestAdapter reports fixed sessions for one worktree path.
+type usageTestAdapter struct {
+	path     string
+	sessions []adapter.Session
+	messages map[string][]adapter.Message
+}
+
+func (a *usageTestAdapter) ID() string                          { return "test" }
+func (a *usageTestAdapter) Name() string                        { return "Test" }
+func (a *usageTestAdapter) Icon() string                        { return "T" }
+func (a *usageTestAdapter) Detect(string) (bool, error)         { return true, nil }
+func (a *usageTestAdapter) Capabilities() adapter.CapabilitySet { return nil }
+func (a *usageTestAdapter) Usage(string) (*adapter.UsageStats, error) {
+	return nil, errors.New("unsupported")
+}
+func (a *usageTestAdapter) Watch(string) (<-chan adapter.Event, io.Closer, error) {
+	return nil, nil, errors.New("unsupported")
+}
+
+func (a *usageTestAdapter) Sessions(path string) ([]adapter.Session, error) {
+	if path != a.path {
+		return nil, nil
+	}
+	return a.sessions, nil
+}
+
+func (a *usageTestAdapter) Messages(id string) ([]adapter.Message, error) {
+	return a.messages[id], nil
+}
+
+func TestComputeWorktreeUsage(t *testing.T) {
+	now := time.Now()
+	a := &usageTestAdapter{
+		path: "/wt/feature",
+		sessions: []adapter.Session{
+			{ID: "old", UpdatedAt: now.Add(-time.Hour), TotalTokens: 1000, EstCost: 0.5},
+			{ID: "new", UpdatedAt: now, TotalTokens: 2000, EstCost: 1.25},
+			// Sub-agents add to the totals but aren't sessions of their own
+			{ID: "sub", UpdatedAt: now.Add(time.Minute), TotalTokens: 500, EstCost: 0.25, IsSubAgent: true},
+			// No totals in the session list: read them from the messages
+			{ID: "bare", UpdatedAt: now.Add(-2 * time.Hour)},
+		},
+		messages: map[string][]adapter.Message{
+			"bare": {{TokenUsage: adapter.TokenUsage{InputTokens: 300, OutputTokens: 200}}},
+		},
+	}
+	adapters := map[string]adapter.Adapter{"test": a}
+
+	u := computeWorktreeUsage(adapters, "/wt/feature")
+	if u == nil {
+		t.Fatal("expected usage")
+	}
+	if u.Sessions != 3 || u.Tokens != 4000 || u.Cost != 2.0 || u.LatestID != "new" || !u.LastActivity.Equal(now) {
+		t.Errorf("usage = %+v", *u)
+	}
+
+	if u := computeWorktreeUsage(adapters, "/wt/other"); u != nil {
+		t.Errorf("usage for a worktree without sessions = %+v", *u)
+	}
+}
+
+func TestUsageDisplay(t *testing.T) {
+	u := &WorktreeUsage{Sessions: 1, Tokens: 1_450_000, Cost: 3.4, LastActivity: time.Now().Add(-5 * time.Minute)}
+	if got := sidebarUsage(u); got != "$3.40" {
+		t.Errorf("sidebarUsage = %q", got)
+	}
+	if got := usageSummary(u); got != "1 session · 1.4M tokens · $3.40 · 5m ago" {
+		t.Errorf("usageSummary = %q", got)
+	}
+
+	noCost := &WorktreeUsage{Sessions: 2, Tokens: 12_345}
+	if got := sidebarUsage(noCost); got != "12k tok" {
+		t.Errorf("sidebarUsage without cost = %q", got)
+	}
+	if got := usageSummary(noCost); got != "2 sessions · 12k tokens" {
+		t.Errorf("usageSummary without cost = %q", got)
+	}
+	if sidebarUsage(nil) != "" || usageSummary(nil) != "" {
+		t.Error("expected no usage text without sessions")
+	}
+}
+
+func TestJumpToConversation(t *testing.T) {
+	p := &Plugin{ctx: &plugin.Context{Logger: slog.Default()}, usage: map[string]*usageState{}}
+	wt := &Worktree{Name: "feature"}
+
+	if cmd := p.jumpToConversation(wt); cmd == nil {
+		t.Fatal("expected a toast without sessions")
+	}
+
+	p.usage["feature"] = &usageState{usage: &WorktreeUsage{Sessions: 1, LatestID: "sess-1"}}
+	batch, ok := p.jumpToConversation(wt)().(tea.BatchMsg)
+	if !ok {
+		t.Fatal("expected a batch focusing the conversations plugin")
+	}
+	var focused bool
+	for _, cmd := range batch {
+		if msg, ok := cmd().(app.FocusConversationMsg); ok {
+			focused = msg.SessionID == "sess-1"
+		}
+	}
+	if !focused {
+		t.Error("expected FocusConversationMsg for sess-1")
+	}
+}
@@ -6,6 +6,7 @@ import (
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
 	"github.com/marcus/sidecar/internal/plugins/gitstatus"
 	"github.com/marcus/sidecar/internal/styles"
 	"github.com/marcus/sidecar/internal/ui"
@@ -197,6 +198,14 @@ func (p *Plugin) renderDiffTabFileList(width, height, baseX, baseY int) string {
 		p.mouseHandler.HitMap.AddRect(regionDiffTabFileListPane, baseX, baseY, width, height, nil)
 	}
 
+	// Files with predicted merge conflicts get a warning icon
+	conflictFiles := make(map[string]bool)
+	if wt := p.selectedWorktree(); wt != nil {
+		for _, f := range p.getConflictingFiles(wt.Name, p.conflicts) {
+			conflictFiles[f] = true
+		}
+	}
+
 	// Header
 	headerText := fmt.Sprintf("Files (%d)", len(files))
 	if fileListActive {
@@ -260,6 +269,9 @@ func (p *Plugin) renderDiffTabFileList(width, height, baseX, baseY int) string {
 		} else if file.Additions == 0 && file.Deletions > 0 {
 			statusIcon = "D"
 		}
+		if conflictFiles[file.FileName()] {
+			statusIcon = "⚠"
+		}
 
 		// File path - truncate if needed (rune-safe)
 		fileName := file.FileName()
@@ -297,7 +309,7 @@ func (p *Plugin) renderDiffTabFileList(width, height, baseX, baseY int) string {
 			switch statusIcon {
 			case "A":
 				statusStyle = styles.StatusStaged
-			case "D":
+			case "D", "⚠":
 				statusStyle = styles.StatusDeleted
 			default:
 				statusStyle = styles.StatusModified
@@ -440,6 +452,9 @@ func (p *Plugin) renderDiffTabDiffPane(width, height, baseX, baseY int) string {
 
 	fileName := file.FileName()
 	headerStr := fmt.Sprintf("%s [%s]", fileName, viewModeStr)
+	if n := openReviewComments(p.reviewComments(p.selectedWorktree())); n > 0 {
+		headerStr += fmt.Sprintf(" · %d review comments (C)", n)
+	}
 	if p.diffTabFocus == DiffTabFocusDiff {
 		sb.WriteString(styles.Title.Render(headerStr))
 	} else {
@@ -449,6 +464,16 @@ func (p *Plugin) renderDiffTabDiffPane(width, height, baseX, baseY int) string {
 
 	// Content height for diff
 	contentHeight := height - 2
+
+	// Predicted merge conflicts for this file take up to half the pane
+	if wt := p.selectedWorktree(); wt != nil {
+		if labels, hunks := p.conflictHunksForFile(wt.Name, fileName); len(hunks) > 0 {
+			conflictSection := renderConflictHunks(labels, hunks, width, contentHeight/2)
+			sb.WriteString(conflictSection)
+			sb.WriteString("\n")
+			contentHeight -= strings.Count(conflictSection, "\n") + 1
+		}
+	}
 	if contentHeight < 1 {
 		contentHeight = 1
 	}
@@ -474,7 +499,13 @@ func (p *Plugin) renderDiffTabDiffPane(width, height, baseX, baseY int) string {
 	case DiffViewSideBySide:
 		diffContent = gitstatus.RenderSideBySide(parsed, width, p.diffTabDiffScroll, contentHeight, p.diffTabHorizScroll, highlighter, false)
 	default:
-		diffContent = gitstatus.RenderLineDiff(parsed, width, p.diffTabDiffScroll, contentHeight, p.diffTabHorizScroll, highlighter, false)
+		// Leave a column for review comment markers
+		diffW := width - reviewGutterWidth
+		diffContent = gitstatus.RenderLineDiff(parsed, diffW, p.diffTabDiffScroll, contentHeight, p.diffTabHorizScroll, highlighter, false)
+		if !parsed.Binary {
+			rows := unifiedDiffRows(parsed, p.diffTabDiffScroll, contentHeight)
+			diffContent = renderReviewGutter(diffContent, parsed, rows, fileName, p.reviewComments(p.selectedWorktree()), p.diffTabFocus == DiffTabFocusDiff)
+		}
 	}
 
 	sb.WriteString(diffContent)
@@ -497,6 +528,31 @@ func (p *Plugin) renderDiffTabDiffPane(width, height, baseX, baseY int) string {
 	return sb.String()
 }
 
+// renderConflictHunks renders predicted conflict hunks in at most maxLines
+// lines, each hunk headed by what it conflicts with.
+func renderConflictHunks(labels []string, hunks []ConflictHunk, width, maxLines int) string {
+	maxLines = max(maxLines, 2)
+	var lines []string
+	for i, h := range hunks {
+		lines = append(lines, styles.StatusModified.Render(fmt.Sprintf("⚠ Conflict %d/%d with %s", i+1, len(hunks), labels[i])))
+		for _, line := range h.Lines {
+			line = ui.ExpandTabs(line, tabStopWidth)
+			if strings.HasPrefix(line, "<<<<<<<") || strings.HasPrefix(line, "=======") || strings.HasPrefix(line, ">>>>>>>") {
+				line = styles.Muted.Render(line)
+			}
+			if lipgloss.Width(line) > width {
+				line = ansi.Truncate(line, width, "")
+			}
+			lines = append(lines, line)
+		}
+	}
+	if len(lines) > maxLines {
+		more := len(lines) - maxLines + 1
+		lines = append(lines[:maxLines-1], dimText(fmt.Sprintf("… %d more lines", more)))
+	}
+	return strings.Join(lines, "\n")
+}
+
 // renderDiffTabCommitPreview renders commit info + file list when cursor is on a commit.
 func (p *Plugin) renderDiffTabCommitPreview(commit CommitStatusInfo, width, height, baseX, baseY int) string {
 	var sb strings.Builder
@@ -551,7 +607,7 @@ func (p *Plugin) renderDiffTabCommitPreview(commit CommitStatusInfo, width, heig
 			switch statusIcon {
 			case "A":
 				statusStyle = styles.StatusStaged
-			case "D":
+			case "D", "⚠":
 				statusStyle = styles.StatusDeleted
 			default:
 				statusStyle = styles.StatusModified
@@ -702,7 +758,7 @@ func (p *Plugin) renderCommitFileList(width, height, baseX, baseY int) string {
 			switch statusIcon {
 			case "A":
 				statusStyle = styles.StatusStaged
-			case "D":
+			case "D", "⚠":
 				statusStyle = styles.StatusDeleted
 			case "R":
 				statusStyle = lipgloss.NewStyle().Foreground(styles.Info)
@@ -10,7 +10,10 @@ import (
 
 // renderKanbanView renders the kanban board view.
 func (p *Plugin) renderKanbanView(width, height int) string {
-	numCols := kanbanColumnCount()
+	// Build unified kanban data (worktrees + shells in status columns)
+	kd := p.buildKanbanData()
+
+	numCols := kd.columnCount()
 	minColWidth := 16
 	minKanbanWidth := (minColWidth * numCols) + (numCols - 1) + 4
 	// Check minimum width - auto-collapse to list view if too narrow
@@ -30,6 +33,9 @@ func (p *Plugin) renderKanbanView(width, height int) string {
 	header := styles.Title.Render("Workspaces")
 	listTab := "List"
 	kanbanTab := "[Kanban]"
+	if kd.byTask {
+		kanbanTab = "[Tasks]"
+	}
 	viewToggle := styles.Muted.Render(listTab + "|" + kanbanTab)
 	headerLine := header + strings.Repeat(" ", max(1, innerWidth-len("Workspaces")-len(listTab)-len(kanbanTab)-1)) + viewToggle
 	lines = append(lines, headerLine)
@@ -41,9 +47,6 @@ func (p *Plugin) renderKanbanView(width, height int) string {
 	p.mouseHandler.HitMap.AddRect(regionViewToggle, toggleX, 1, len(listTab), 1, 0)
 	p.mouseHandler.HitMap.AddRect(regionViewToggle, toggleX+len(listTab)+1, 1, len(kanbanTab), 1, 1)
 
-	// Build unified kanban data (worktrees + shells in status columns)
-	kd := p.buildKanbanData()
-
 	// Column headers and colors
 	columnTitles := map[WorktreeStatus]string{
 		StatusActive:   "● Active",
@@ -52,19 +55,14 @@ func (p *Plugin) renderKanbanView(width, height int) string {
 		StatusDone:     "✓ Ready",
 		StatusPaused:   "⏸ Paused",
 	}
-	columnColors := map[WorktreeStatus]lipgloss.Color{
-		StatusActive:   styles.StatusCompleted.GetForeground().(lipgloss.Color), // Green
-		StatusThinking: styles.Primary,                                          // Purple
-		StatusWaiting:  styles.StatusModified.GetForeground().(lipgloss.Color),  // Yellow
-		StatusDone:     styles.Secondary,                                        // Cyan/Blue
-		StatusPaused:   styles.TextMuted,                                        // Gray
-	}
+	columnColors := kanbanStatusColors()
 
 	// Calculate column widths (account for panel borders)
 	colWidth := (innerWidth - numCols - 1) / numCols // -1 for separators
 	if colWidth < minColWidth {
 		colWidth = minColWidth
 	}
+	p.kanbanColWidth = colWidth
 
 	// Render column headers with colors and register hit regions
 	var colHeaders []string
@@ -72,7 +70,11 @@ func (p *Plugin) renderKanbanView(width, height int) string {
 	for colIdx := 0; colIdx < numCols; colIdx++ {
 		var title string
 		var headerStyle lipgloss.Style
-		if colIdx == kanbanShellColumnIndex {
+		if kd.byTask {
+			col := taskBoardColumns[colIdx]
+			title = fmt.Sprintf("%s (%d)", col.Title, kd.columnItemCount(colIdx))
+			headerStyle = lipgloss.NewStyle().Bold(true).Foreground(taskBoardColumnColor(col.Status)).Width(colWidth)
+		} else if colIdx == kanbanShellColumnIndex {
 			title = fmt.Sprintf("Shells (%d)", len(kd.plainShells))
 			headerStyle = lipgloss.NewStyle().Bold(true).Foreground(styles.Muted.GetForeground().(lipgloss.Color)).Width(colWidth)
 		} else {
@@ -136,6 +138,8 @@ func (p *Plugin) renderKanbanView(width, height int) string {
 				wt, shell := kd.itemAt(colIdx, cardIdx)
 				if shell != nil {
 					cellContent = p.renderKanbanShellCardLine(shell, lineIdx, colWidth-1, isSelected)
+				} else if wt != nil && kd.byTask {
+					cellContent = p.renderTaskCardLine(wt, lineIdx, colWidth-1, isSelected)
 				} else if wt != nil {
 					cellContent = p.renderKanbanCardLine(wt, lineIdx, colWidth-1, isSelected)
 				} else {
@@ -161,6 +165,33 @@ func (p *Plugin) renderKanbanView(width, height int) string {
 	return styles.RenderPanel(content, width, height, true)
 }
 
+// kanbanStatusColors returns the color of each agent status column.
+func kanbanStatusColors() map[WorktreeStatus]lipgloss.Color {
+	return map[WorktreeStatus]lipgloss.Color{
+		StatusActive:   styles.StatusCompleted.GetForeground().(lipgloss.Color), // Green
+		StatusThinking: styles.Primary,                                          // Purple
+		StatusWaiting:  styles.StatusModified.GetForeground().(lipgloss.Color),  // Yellow
+		StatusDone:     styles.Secondary,                                        // Cyan/Blue
+		StatusPaused:   styles.TextMuted,                                        // Gray
+	}
+}
+
+// taskBoardColumnColor returns the header color of a task board column.
+func taskBoardColumnColor(status string) lipgloss.Color {
+	switch status {
+	case tdStatusOpen:
+		return styles.Secondary
+	case tdStatusInProgress:
+		return styles.StatusCompleted.GetForeground().(lipgloss.Color)
+	case tdStatusInReview:
+		return styles.StatusModified.GetForeground().(lipgloss.Color)
+	case tdStatusClosed:
+		return styles.Primary
+	default:
+		return styles.TextMuted
+	}
+}
+
 // renderKanbanShellCardLine renders a single line of a shell kanban card.
 // lineIdx: 0=name, 1=status, 2-3=empty
 func (p *Plugin) renderKanbanShellCardLine(shell *ShellSession, lineIdx, width int, isSelected bool) string {
@@ -304,3 +335,57 @@ func (p *Plugin) renderKanbanCardLine(wt *Worktree, lineIdx, width int, isSelect
 	}
 	return lipgloss.NewStyle().Width(width).Render(content)
 }
+
+// renderTaskCardLine renders a single line of a task board card.
+// lineIdx: 0=name, 1=agent status badge, 2=task, 3=task title
+func (p *Plugin) renderTaskCardLine(wt *Worktree, lineIdx, width int, isSelected bool) string {
+	var content string
+	task := p.taskBoard[wt.TaskID]
+
+	switch lineIdx {
+	case 0:
+		content = " " + truncateString(wt.Name, width-1)
+	case 1:
+		agent := "no agent"
+		if wt.Agent != nil {
+			agent = string(wt.Agent.Type)
+		}
+		badge := wt.Status.Icon() + " " + wt.Status.String()
+		if !isSelected {
+			color := kanbanStatusColors()[wt.Status]
+			if wt.Status == StatusError {
+				color = styles.Error
+			}
+			badge = lipgloss.NewStyle().Foreground(color).Render(badge)
+		}
+		content = "  " + agent + " " + badge
+	case 2:
+		if wt.TaskID != "" {
+			taskStr := wt.TaskID
+			if task.Status == tdStatusBlocked {
+				taskStr += " · blocked"
+			}
+			content = "  " + taskStr
+		}
+	case 3:
+		if task.Title != "" {
+			content = "  " + task.Title
+		}
+	}
+
+	if lipgloss.Width(content) > width {
+		content = truncateString(content, width)
+	}
+	contentWidth := lipgloss.Width(content)
+	if contentWidth < width {
+		content += strings.Repeat(" ", width-contentWidth)
+	}
+
+	if isSelected {
+		return styles.ListItemSelected.Width(width).Render(content)
+	}
+	if lineIdx > 1 {
+		return styles.Muted.Width(width).Render(content)
+	}
+	return lipgloss.NewStyle().Width(width).Render(content)
+}
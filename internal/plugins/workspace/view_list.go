@@ -83,6 +83,20 @@ func (p *Plugin) View(width, height int) string {
 		return p.renderRenameShellModal(width, height)
 	case ViewModeFetchPR:
 		return p.renderFetchPRModal(width, height)
+	case ViewModeFanOut:
+		return p.renderFanOutModal(width, height)
+	case ViewModeFanOutBoard:
+		return p.renderFanOutBoard(width, height)
+	case ViewModePromptQueue:
+		return p.renderPromptQueueModal(width, height)
+	case ViewModeReviewComment:
+		return p.renderReviewCommentModal(width, height)
+	case ViewModeReview:
+		return p.renderReviewModal(width, height)
+	case ViewModeSyncConflict:
+		return p.renderSyncConflictModal(width, height)
+	case ViewModeTranscripts:
+		return p.renderTranscriptsModal(width, height)
 	case ViewModeFilePicker:
 		background := p.renderListView(width, height)
 		return p.renderFilePickerModal(background)
@@ -321,7 +335,7 @@ func (p *Plugin) renderSidebarContent(width, height int) string {
 	p.visibleCount = (contentHeight - shellSectionHeight) / itemHeight
 
 	// Render worktree items
-	if len(p.worktrees) == 0 {
+	if p.sidebarItemCount() == 0 {
 		// No worktrees exist - show empty state message (unless shell is selected or shells exist)
 		if !p.shellSelected && len(p.shells) == 0 {
 			// Calculate vertical centering for empty state
@@ -384,11 +398,16 @@ func (p *Plugin) renderSidebarContent(width, height int) string {
 		// Render worktree items at reduced width to leave room for scrollbar
 		worktreeItemWidth := width - 1
 		var worktreeLines []string
-		for i := p.scrollOffset; i < len(p.worktrees) && i < p.scrollOffset+p.visibleCount; i++ {
-			wt := p.worktrees[i]
+		for i := p.scrollOffset; i < p.sidebarItemCount() && i < p.scrollOffset+p.visibleCount; i++ {
 			// Only show as selected if not shellSelected AND index matches
 			selected := !p.shellSelected && i == p.selectedIdx
-			line := p.renderWorktreeItem(wt, selected, worktreeItemWidth)
+			var line string
+			if i < len(p.worktrees) {
+				line = p.renderWorktreeItem(p.worktrees[i], selected, worktreeItemWidth)
+			} else {
+				// Archived entries follow the worktrees
+				line = p.renderArchivedItem(p.archives[i-len(p.worktrees)], selected, worktreeItemWidth)
+			}
 
 			// Register hit region with ABSOLUTE index
 			p.mouseHandler.HitMap.AddRect(regionWorktreeItem, 0, currentY, worktreeItemWidth, itemHeight, i)
@@ -401,7 +420,7 @@ func (p *Plugin) renderSidebarContent(width, height int) string {
 		worktreeContent := strings.Join(worktreeLines, "\n")
 		trackHeight := p.visibleCount * itemHeight
 		scrollbar := ui.RenderScrollbar(ui.ScrollbarParams{
-			TotalItems:   len(p.worktrees),
+			TotalItems:   p.sidebarItemCount(),
 			ScrollOffset: p.scrollOffset,
 			VisibleItems: p.visibleCount,
 			TrackHeight:  trackHeight,
@@ -519,13 +538,35 @@ func (p *Plugin) renderWorktreeItem(wt *Worktree, selected bool, width int) stri
 	if !sdCfg.HideTask && wt.TaskID != "" {
 		parts = append(parts, wt.TaskID)
 	}
+	stackStr := p.stackLabel(wt)
+	if stackStr != "" {
+		parts = append(parts, stackStr)
+	}
 	if statsStr != "" {
 		parts = append(parts, statsStr)
 	}
+	usageStr := ""
+	if !sdCfg.HideCost {
+		usageStr = sidebarUsage(p.usageFor(wt.Name))
+		if usageStr != "" {
+			parts = append(parts, usageStr)
+		}
+	}
+	ctxUsage, ctxWarn := p.contextWarning(wt)
+	ctxStr := ""
+	if ctxWarn {
+		ctxStr = fmt.Sprintf("⚠ ctx %d%%", ctxUsage.Percent())
+		parts = append(parts, ctxStr)
+	}
+	queueStr := queueIndicator(wt.Queue)
+	if queueStr != "" {
+		parts = append(parts, queueStr)
+	}
+	conflictStr := ""
 	if hasConflict {
-		conflictFiles := p.getConflictingFiles(wt.Name, p.conflicts)
-		if len(conflictFiles) > 0 {
-			parts = append(parts, fmt.Sprintf("⚠ %d conflicts", len(conflictFiles)))
+		conflictStr = p.conflictSummary(wt.Name)
+		if conflictStr != "" {
+			parts = append(parts, conflictStr)
 		}
 	}
 	if wt.IsOrphaned {
@@ -627,14 +668,23 @@ func (p *Plugin) renderWorktreeItem(wt *Worktree, selected bool, width int) stri
 	if !sdCfg.HideTask && wt.TaskID != "" {
 		styledParts = append(styledParts, wt.TaskID)
 	}
+	if stackStr != "" {
+		styledParts = append(styledParts, lipgloss.NewStyle().Foreground(styles.Secondary).Render(stackStr))
+	}
 	if statsStr != "" {
 		styledParts = append(styledParts, statsStr)
 	}
-	if hasConflict {
-		conflictFiles := p.getConflictingFiles(wt.Name, p.conflicts)
-		if len(conflictFiles) > 0 {
-			styledParts = append(styledParts, styles.StatusModified.Render(fmt.Sprintf("⚠ %d conflicts", len(conflictFiles))))
-		}
+	if usageStr != "" {
+		styledParts = append(styledParts, dimText(usageStr))
+	}
+	if ctxWarn {
+		styledParts = append(styledParts, styles.StatusModified.Render(ctxStr))
+	}
+	if queueStr != "" {
+		styledParts = append(styledParts, lipgloss.NewStyle().Foreground(styles.Secondary).Render(queueStr))
+	}
+	if conflictStr != "" {
+		styledParts = append(styledParts, styles.StatusModified.Render(conflictStr))
 	}
 	if wt.IsOrphaned {
 		styledParts = append(styledParts, styles.StatusModified.Render("⚠ session ended"))
@@ -659,6 +709,61 @@ func (p *Plugin) renderWorktreeItem(wt *Worktree, selected bool, width int) stri
 	return styles.ListItemNormal.Width(width).Render(content)
 }
 
+// renderArchivedItem renders an archived worktree list item.
+func (p *Plugin) renderArchivedItem(a *ArchivedWorktree, selected bool, width int) string {
+	timeStr := formatRelativeTime(a.ArchivedAt)
+	timeWidth := lipgloss.Width(timeStr)
+
+	name := a.Name
+	maxNameWidth := width - 4 - timeWidth - 2
+	if maxNameWidth < 8 {
+		maxNameWidth = 8
+	}
+	if nameRunes := []rune(name); len(nameRunes) > maxNameWidth {
+		name = string(nameRunes[:maxNameWidth-1]) + "…"
+	}
+
+	parts := []string{"archived"}
+	if a.AgentType != "" && a.AgentType != AgentNone {
+		parts = append(parts, string(a.AgentType))
+	}
+	if a.TaskID != "" {
+		parts = append(parts, a.TaskID)
+	}
+	if a.Dirty {
+		parts = append(parts, "+uncommitted")
+	}
+	if p.archiveBusy[a.Name] {
+		parts = append(parts, "restoring…")
+	}
+
+	icon := "▣"
+	if !selected {
+		icon = styles.Muted.Render(icon)
+	}
+	line1 := fmt.Sprintf(" %s %s", icon, name)
+	if line1Width := ansi.StringWidth(line1); line1Width < width-timeWidth-2 {
+		line1 = line1 + strings.Repeat(" ", width-line1Width-timeWidth-1) + timeStr
+	}
+	line2 := "   " + strings.Join(parts, "  ")
+	if ansi.StringWidth(line2) > width {
+		line2 = ansi.Truncate(line2, width-1, "…")
+	}
+	content := line1 + "\n" + line2
+
+	if selected {
+		if p.activePane == PaneSidebar {
+			return styles.ListItemSelected.Width(width).Render(content)
+		}
+		return lipgloss.NewStyle().
+			Background(styles.BgSecondary).
+			Foreground(styles.TextSecondary).
+			Width(width).
+			Render(content)
+	}
+	return styles.ListItemNormal.Width(width).Foreground(styles.TextMuted).Render(content)
+}
+
 // renderShellEntryForSession renders a shell entry for a specific shell session.
 func (p *Plugin) renderShellEntryForSession(shell *ShellSession, selected bool, width int) string {
 	isActiveFocus := selected && p.activePane == PaneSidebar
@@ -746,6 +851,9 @@ func (p *Plugin) renderShellEntryForSession(shell *ShellSession, selected bool,
 	} else {
 		statusText = "shell · no session"
 	}
+	if queueStr := queueIndicator(shell.Queue); queueStr != "" {
+		statusText += " · " + queueStr
+	}
 
 	// Calculate layout
 	maxNameWidth := width - 4 - 2 // icon + padding
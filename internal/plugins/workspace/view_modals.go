@@ -652,6 +652,27 @@ func (p *Plugin) ensureMergeModal() {
 		m.AddSection(modal.Spacer())
 		m.AddSection(modal.Text(dimText("↑/↓: select   Enter: continue   Esc: cancel")))
 
+	case MergeStepVerify:
+		if p.mergeState.TestFailed {
+			m.AddSection(modal.Text(lipgloss.NewStyle().Foreground(styles.Error).Bold(true).Render("Tests failed — merge blocked")))
+		} else {
+			m.AddSection(modal.Text(fmt.Sprintf("Running %s...", p.mergeState.TestCommand)))
+		}
+		m.AddSection(modal.Spacer())
+		m.AddSection(p.mergeTestOutputSection())
+		m.AddSection(modal.Spacer())
+		if p.mergeState.TestFailed {
+			m.AddSection(modal.Buttons(
+				modal.Btn(" Retry ", mergeTestRetryID),
+				modal.Btn(" Send to Agent ", mergeTestFixID),
+				modal.Btn(" Cancel ", "cancel"),
+			))
+			m.AddSection(modal.Spacer())
+			m.AddSection(modal.Text(dimText("r: retry   f: send output to agent   Esc: cancel")))
+		} else {
+			m.AddSection(modal.Text(dimText("Esc: stop tests and cancel")))
+		}
+
 	case MergeStepDirectMerge:
 		m.AddSection(modal.Text("Merging directly to base branch..."))
 		m.AddSection(modal.Spacer())
@@ -757,6 +778,7 @@ func (p *Plugin) mergeProgressSection() modal.Section {
 				MergeStepReviewDiff,
 				MergeStepTargetBranch,
 				MergeStepMergeMethod,
+				MergeStepVerify,
 				MergeStepDirectMerge,
 				MergeStepPostMergeConfirmation,
 				MergeStepCleanup,
@@ -766,6 +788,7 @@ func (p *Plugin) mergeProgressSection() modal.Section {
 				MergeStepReviewDiff,
 				MergeStepTargetBranch,
 				MergeStepMergeMethod,
+				MergeStepVerify,
 				MergeStepPush,
 				MergeStepGeneratePR,
 				MergeStepCreatePR,
@@ -814,6 +837,27 @@ func (p *Plugin) mergeProgressSection() modal.Section {
 	}, nil)
 }
 
+// mergeTestOutputSection renders the tail of the test gate output.
+func (p *Plugin) mergeTestOutputSection() modal.Section {
+	return modal.Custom(func(contentWidth int, focusID, hoverID string) modal.RenderedSection {
+		if p.mergeState == nil {
+			return modal.RenderedSection{}
+		}
+		out := strings.TrimRight(p.mergeState.TestOutput.String(), "\n")
+		if out == "" {
+			return modal.RenderedSection{Content: dimText("Waiting for output...")}
+		}
+		lines := strings.Split(out, "\n")
+		if len(lines) > mergeTestOutputLines {
+			lines = lines[len(lines)-mergeTestOutputLines:]
+		}
+		for i, line := range lines {
+			lines[i] = ansi.Truncate(ansi.Strip(strings.TrimRight(line, "\r")), contentWidth, "…")
+		}
+		return modal.RenderedSection{Content: strings.Join(lines, "\n")}
+	}, nil)
+}
+
 // mergeReviewDiffSection renders the diff summary for review.
 func (p *Plugin) mergeReviewDiffSection() modal.Section {
 	return modal.Custom(func(contentWidth int, focusID, hoverID string) modal.RenderedSection {
@@ -1046,6 +1090,8 @@ func (p *Plugin) mergeDoneSection() modal.Section {
 			sb.WriteString(dimText("r: rebase  m: merge  d: details  Enter: close"))
 		} else if p.mergeState.CleanupResults != nil && p.mergeState.CleanupResults.PullError != nil {
 			sb.WriteString(dimText("d: details  Enter: close"))
+		} else if p.mergeState.StackNext != "" {
+			sb.WriteString(dimText(fmt.Sprintf("n: merge %s next  Enter: close", p.mergeState.StackNext)))
 		} else {
 			sb.WriteString(dimText("Press Enter to close"))
 		}
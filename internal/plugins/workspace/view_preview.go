@@ -20,6 +20,16 @@ func (p *Plugin) renderPreviewContent(width, height int) string {
 		p.interactiveState.ContentRowOffset = 0
 	}
 
+	// Scrollback search replaces the output it searches
+	if p.viewMode == ViewModeOutputSearch && p.outputSearch != nil {
+		return p.truncateAllLines(p.renderOutputSearch(width, height), width)
+	}
+
+	// Archived entry: show what restoring will bring back
+	if a := p.selectedArchive(); a != nil {
+		return p.truncateAllLines(p.renderArchivedView(a, width), width)
+	}
+
 	// Show welcome guide only when no worktree AND no shell is selected
 	wt := p.selectedWorktree()
 	if wt == nil && !p.shellSelected {
@@ -47,8 +57,13 @@ func (p *Plugin) renderPreviewContent(width, height int) string {
 		return p.truncateAllLines(p.renderMainWorktreeView(width, height), width)
 	}
 
-	// Tab header (only for worktrees, not shell)
+	// Tab header (only for worktrees, not shell), with session usage on the right
 	tabs := p.renderTabs(width)
+	if summary := usageSummary(p.usageFor(wt.Name)); summary != "" {
+		if gap := width - lipgloss.Width(tabs) - lipgloss.Width(summary) - 1; gap >= 2 {
+			tabs += strings.Repeat(" ", gap) + dimText(summary)
+		}
+	}
 	lines = append(lines, tabs)
 	lines = append(lines, "") // Empty line after header
 
@@ -110,8 +125,8 @@ func (p *Plugin) renderWelcomeGuide(width, height int) string {
 	sectionStyle := lipgloss.NewStyle().Bold(true).Foreground(styles.Primary)
 	warningStyle := lipgloss.NewStyle().Bold(true).Foreground(styles.Warning)
 
-	// Check if tmux is installed
-	if !isTmuxInstalled() {
+	// Check if tmux is installed (only the tmux backend can be unavailable)
+	if !sessionBackend.Available() {
 		lines = append(lines, warningStyle.Render("⚠ tmux Required"))
 		lines = append(lines, "")
 		lines = append(lines, dimText("Workspaces and shell sessions require tmux to be installed."))
@@ -643,8 +658,8 @@ func (p *Plugin) renderShellPrimer(width, height int) string {
 	sectionStyle := lipgloss.NewStyle().Bold(true).Foreground(styles.Primary)
 	warningStyle := lipgloss.NewStyle().Bold(true).Foreground(styles.Warning)
 
-	// Check if tmux is installed
-	if !isTmuxInstalled() {
+	// Check if tmux is installed (only the tmux backend can be unavailable)
+	if !sessionBackend.Available() {
 		lines = append(lines, warningStyle.Render("⚠ tmux Required"))
 		lines = append(lines, "")
 		lines = append(lines, dimText("The project shell requires tmux to be installed."))
@@ -740,6 +755,54 @@ func (p *Plugin) renderMainWorktreeView(width, height int) string {
 	return strings.Join(lines, "\n")
 }
 
+// renderArchivedView renders the details of an archived worktree.
+func (p *Plugin) renderArchivedView(a *ArchivedWorktree, width int) string {
+	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(styles.Primary)
+	hintStyle := lipgloss.NewStyle().Foreground(styles.Success)
+
+	field := func(label, value string) string {
+		if value == "" {
+			value = "—"
+		}
+		return dimText(fmt.Sprintf("%-10s", label)) + value
+	}
+
+	agent := string(a.AgentType)
+	if a.AgentType == AgentNone {
+		agent = ""
+	}
+	task := a.TaskID
+	if a.TaskTitle != "" {
+		task += " " + a.TaskTitle
+	}
+	prompt := ""
+	if a.Prompt != nil {
+		prompt = a.Prompt.Name
+	}
+	work := "committed on " + a.Branch
+	if a.Dirty {
+		work = "uncommitted changes saved in " + a.Ref
+	}
+
+	lines := []string{
+		titleStyle.Render("Archived: " + a.Name),
+		dimText("Archived " + a.ArchivedAt.Format("2006-01-02 15:04")),
+		"",
+		field("Branch", a.Branch),
+		field("Base", a.BaseBranch),
+		field("Agent", agent),
+		field("Task", task),
+		field("Prompt", prompt),
+		field("Work", work),
+		field("Path", a.Path),
+		"",
+		strings.Repeat("─", min(width-4, 50)),
+		"",
+		hintStyle.Render("Press 'A' to restore the workspace, setup and agent"),
+	}
+	return strings.Join(lines, "\n")
+}
+
 // renderTaskContent renders linked task info.
 func (p *Plugin) renderTaskContent(width, height int) string {
 	wt := p.selectedWorktree()
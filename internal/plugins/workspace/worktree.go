@@ -9,6 +9,7 @@ import (
 	"path/filepath"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
@@ -249,14 +250,73 @@ func (p *Plugin) createWorktree() tea.Cmd {
 		}
 	}
 
-	return func() tea.Msg {
-		wt, err := p.doCreateWorktree(name, baseBranch, taskID, taskTitle, agentType)
-		return CreateDoneMsg{Worktree: wt, AgentType: agentType, SkipPerms: skipPerms, Prompt: prompt, Err: err}
+	// Show provisioning steps in the modal as they run
+	steps := p.provisionSteps()
+	p.createProvision = pendingProvisionStatuses(steps)
+	p.createProvisioning = true
+	epoch := p.ctx.Epoch
+	// Each step reports twice (running, then finished), so sends never block
+	progress := make(chan ProvisionProgressMsg, 2*len(steps))
+	var mu sync.Mutex
+	final := pendingProvisionStatuses(steps)
+	report := func(i int, status ProvisionStepStatus) {
+		if i >= len(steps) {
+			return
+		}
+		mu.Lock()
+		final[i] = status
+		mu.Unlock()
+		progress <- ProvisionProgressMsg{Epoch: epoch, Index: i, Status: status, progress: progress}
+	}
+
+	create := func() tea.Msg {
+		defer close(progress)
+		wt, err := p.doCreateWorktree(name, baseBranch, taskID, taskTitle, agentType, report)
+		if err == nil && prompt != nil {
+			if saveErr := savePrompt(p.ctx.ProjectRoot, wt.Path, prompt); saveErr != nil {
+				p.ctx.Logger.Warn("failed to save prompt", "path", wt.Path, "error", saveErr)
+			}
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		return CreateDoneMsg{Worktree: wt, AgentType: agentType, SkipPerms: skipPerms, Prompt: prompt, Provision: final, Err: err}
 	}
+	return tea.Batch(create, listenForProvisionProgress(progress))
+}
+
+// finishCreate closes the create modal, selects the new worktree and starts
+// its agent.
+func (p *Plugin) finishCreate(msg CreateDoneMsg) tea.Cmd {
+	p.viewMode = ViewModeList
+	p.worktrees = append(p.worktrees, msg.Worktree)
+
+	// Auto-focus newly created worktree (same pattern as click selection)
+	p.shellSelected = false
+	p.selectedIdx = len(p.worktrees) - 1
+	p.previewOffset = 0
+	p.autoScrollOutput = true
+	p.resetScrollBaseLineCount() // td-f7c8be: clear snapshot for new selection
+	p.saveSelectionState()
+	p.ensureVisible()
+
+	p.clearCreateModal()
+
+	// Load content for preview pane
+	cmds := []tea.Cmd{p.loadSelectedContent()}
+
+	// Start agent or attach based on selection
+	if msg.AgentType != AgentNone && msg.AgentType != "" {
+		cmds = append(cmds, p.StartAgentWithOptions(msg.Worktree, msg.AgentType, msg.SkipPerms, msg.Prompt))
+	} else {
+		// "None" selected - attach to worktree directory
+		cmds = append(cmds, p.AttachToWorktreeDir(msg.Worktree))
+	}
+	return tea.Batch(cmds...)
 }
 
-// doCreateWorktree performs the actual worktree creation.
-func (p *Plugin) doCreateWorktree(name, baseBranch, taskID, taskTitle string, agentType AgentType) (*Worktree, error) {
+// doCreateWorktree performs the actual worktree creation. report, if set,
+// receives provisioning step progress.
+func (p *Plugin) doCreateWorktree(name, baseBranch, taskID, taskTitle string, agentType AgentType, report provisionReporter) (*Worktree, error) {
 	// Default base branch to current branch if not specified
 	if baseBranch == "" {
 		baseBranch = "HEAD"
@@ -355,8 +415,8 @@ func (p *Plugin) doCreateWorktree(name, baseBranch, taskID, taskTitle string, ag
 		p.ctx.Logger.Warn("failed to save base branch", "path", wtPath, "error", err)
 	}
 
-	// Run post-creation setup (env files, symlinks, setup script)
-	if err := p.setupWorktree(wtPath, name); err != nil {
+	// Run post-creation setup (env files, symlinks, provisioning, setup script)
+	if err := p.setupWorktree(wtPath, name, report); err != nil {
 		p.ctx.Logger.Warn("workspace setup had errors", "path", wtPath, "error", err)
 		// Don't fail creation for setup errors
 	}
@@ -576,6 +636,7 @@ const sidecarAgentFile = "agent"
 const sidecarAgentStartFile = ".sidecar-agent-start"
 const sidecarPRFile = "pr"
 const sidecarBaseFile = "base"
+const sidecarPromptFile = "prompt"
 
 // saveBaseBranch persists the base branch to the centralized worktree data directory.
 func saveBaseBranch(projectRoot, worktreePath string, branch string) error {
@@ -680,6 +741,42 @@ func loadPRURL(projectRoot, worktreePath string) string {
 	return strings.TrimSpace(string(content))
 }
 
+// savePrompt persists the prompt an agent was started with to the
+// centralized worktree data directory.
+func savePrompt(projectRoot, worktreePath string, prompt *Prompt) error {
+	wtDir, err := projectdir.WorktreeDir(projectRoot, worktreePath)
+	if err != nil {
+		return fmt.Errorf("resolve worktree dir: %w", err)
+	}
+	promptPath := filepath.Join(wtDir, sidecarPromptFile)
+	if prompt == nil {
+		_ = os.Remove(promptPath)
+		return nil
+	}
+	data, err := json.Marshal(prompt)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(promptPath, data, 0644)
+}
+
+// loadPrompt reads the saved prompt from the centralized worktree data directory.
+func loadPrompt(projectRoot, worktreePath string) *Prompt {
+	wtDir, err := projectdir.WorktreeDir(projectRoot, worktreePath)
+	if err != nil {
+		return nil
+	}
+	data, err := os.ReadFile(filepath.Join(wtDir, sidecarPromptFile))
+	if err != nil {
+		return nil
+	}
+	var prompt Prompt
+	if err := json.Unmarshal(data, &prompt); err != nil {
+		return nil
+	}
+	return &prompt
+}
+
 // linkTask returns a command to link a td task to a worktree.
 func (p *Plugin) linkTask(wt *Worktree, taskID string) tea.Cmd {
 	projectRoot := p.ctx.ProjectRoot
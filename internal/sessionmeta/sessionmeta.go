@@ -0,0 +1,304 @@
+// Package sessionmeta stores sidecar-owned metadata for agent sessions:
+// stars, tags, free-text notes, and per-message annotations. Adapters are
+// read-only, so this store is the only place users can mark sessions.
+// Entries are keyed by adapter ID + session ID and persisted as JSON under
+// $XDG_STATE_HOME/sidecar/session-meta.json.
+package sessionmeta
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/marcus/sidecar/internal/config"
+)
+
+// fileVersion is the current on-disk format version.
+const fileVersion = 1
+
+// Annotation is a user note attached to a single message.
+type Annotation struct {
+	MessageID string    `json:"messageId"`
+	Text      string    `json:"text"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// Meta is the metadata recorded for one session.
+type Meta struct {
+	Starred     bool         `json:"starred,omitempty"`
+	Tags        []string     `json:"tags,omitempty"`
+	Note        string       `json:"note,omitempty"`
+	Annotations []Annotation `json:"annotations,omitempty"`
+	UpdatedAt   time.Time    `json:"updatedAt"`
+}
+
+// IsEmpty returns true if the entry carries no user data.
+func (m Meta) IsEmpty() bool {
+	return !m.Starred && len(m.Tags) == 0 && m.Note == "" && len(m.Annotations) == 0
+}
+
+// HasTag returns true if the session carries tag (case-insensitive).
+func (m Meta) HasTag(tag string) bool {
+	tag = normalizeTag(tag)
+	return slices.Contains(m.Tags, tag)
+}
+
+// Annotation returns the annotation text for a message, if any.
+func (m Meta) Annotation(messageID string) (string, bool) {
+	for _, a := range m.Annotations {
+		if a.MessageID == messageID {
+			return a.Text, true
+		}
+	}
+	return "", false
+}
+
+// file is the JSON document stored on disk.
+type file struct {
+	Version  int             `json:"version"`
+	Sessions map[string]Meta `json:"sessions"`
+}
+
+// Store is a file-backed session metadata store. All methods are safe for
+// concurrent use and treat a nil *Store as empty and read-only.
+type Store struct {
+	path     string
+	mu       sync.RWMutex
+	sessions map[string]Meta
+}
+
+// DefaultPath returns the default store location.
+func DefaultPath() string {
+	return filepath.Join(config.StateDir(), "session-meta.json")
+}
+
+// Open loads the store at path. A missing file yields an empty store.
+func Open(path string) (*Store, error) {
+	s := &Store{path: path}
+	sessions, err := readFile(path)
+	if err != nil {
+		return nil, err
+	}
+	s.sessions = sessions
+	return s, nil
+}
+
+// Key builds the store key for a session.
+func Key(adapterID, sessionID string) string {
+	return adapterID + "/" + sessionID
+}
+
+// Reload re-reads the store from disk, picking up changes from other
+// sidecar instances.
+func (s *Store) Reload() error {
+	if s == nil {
+		return nil
+	}
+	sessions, err := readFile(s.path)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	s.sessions = sessions
+	s.mu.Unlock()
+	return nil
+}
+
+// Get returns the metadata for a session (zero value if none).
+func (s *Store) Get(adapterID, sessionID string) Meta {
+	if s == nil {
+		return Meta{}
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	m := s.sessions[Key(adapterID, sessionID)]
+	m.Tags = slices.Clone(m.Tags)
+	m.Annotations = slices.Clone(m.Annotations)
+	return m
+}
+
+// ToggleStar flips the star on a session and returns the new state.
+func (s *Store) ToggleStar(adapterID, sessionID string) (bool, error) {
+	var starred bool
+	err := s.update(adapterID, sessionID, func(m *Meta) {
+		m.Starred = !m.Starred
+		starred = m.Starred
+	})
+	return starred, err
+}
+
+// SetTags replaces a session's tags. Tags are normalized and deduplicated.
+func (s *Store) SetTags(adapterID, sessionID string, tags []string) error {
+	return s.update(adapterID, sessionID, func(m *Meta) {
+		m.Tags = normalizeTags(tags)
+	})
+}
+
+// SetNote replaces a session's free-text note. An empty note removes it.
+func (s *Store) SetNote(adapterID, sessionID, note string) error {
+	return s.update(adapterID, sessionID, func(m *Meta) {
+		m.Note = strings.TrimSpace(note)
+	})
+}
+
+// SetAnnotation sets the annotation for one message. Empty text removes it.
+func (s *Store) SetAnnotation(adapterID, sessionID, messageID, text string) error {
+	text = strings.TrimSpace(text)
+	return s.update(adapterID, sessionID, func(m *Meta) {
+		for i, a := range m.Annotations {
+			if a.MessageID != messageID {
+				continue
+			}
+			if text == "" {
+				m.Annotations = slices.Delete(m.Annotations, i, i+1)
+			} else {
+				m.Annotations[i].Text = text
+			}
+			return
+		}
+		if text != "" {
+			m.Annotations = append(m.Annotations, Annotation{
+				MessageID: messageID,
+				Text:      text,
+				CreatedAt: time.Now(),
+			})
+		}
+	})
+}
+
+// AllTags returns every tag in use, sorted.
+func (s *Store) AllTags() []string {
+	if s == nil {
+		return nil
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	seen := make(map[string]bool)
+	var tags []string
+	for _, m := range s.sessions {
+		for _, t := range m.Tags {
+			if !seen[t] {
+				seen[t] = true
+				tags = append(tags, t)
+			}
+		}
+	}
+	sort.Strings(tags)
+	return tags
+}
+
+// SessionTags returns a session's tags. It satisfies dataset.TagSource.
+func (s *Store) SessionTags(adapterID, sessionID string) []string {
+	return s.Get(adapterID, sessionID).Tags
+}
+
+// SessionNote returns a session's note. It satisfies dataset.AnnotationSource.
+func (s *Store) SessionNote(adapterID, sessionID string) string {
+	return s.Get(adapterID, sessionID).Note
+}
+
+// MessageAnnotations returns annotation text keyed by message ID. It
+// satisfies dataset.AnnotationSource.
+func (s *Store) MessageAnnotations(adapterID, sessionID string) map[string]string {
+	m := s.Get(adapterID, sessionID)
+	if len(m.Annotations) == 0 {
+		return nil
+	}
+	out := make(map[string]string, len(m.Annotations))
+	for _, a := range m.Annotations {
+		out[a.MessageID] = a.Text
+	}
+	return out
+}
+
+// update applies fn to a session's entry and persists the store. The file is
+// re-read first so concurrent edits from other instances are not lost.
+func (s *Store) update(adapterID, sessionID string, fn func(*Meta)) error {
+	if s == nil {
+		return fmt.Errorf("session metadata store not available")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if onDisk, err := readFile(s.path); err == nil {
+		s.sessions = onDisk
+	}
+
+	key := Key(adapterID, sessionID)
+	m := s.sessions[key]
+	fn(&m)
+	if m.IsEmpty() {
+		delete(s.sessions, key)
+	} else {
+		m.UpdatedAt = time.Now()
+		s.sessions[key] = m
+	}
+	return writeFile(s.path, s.sessions)
+}
+
+// ParseTags splits user input on commas and whitespace into normalized tags.
+func ParseTags(input string) []string {
+	fields := strings.FieldsFunc(input, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t'
+	})
+	return normalizeTags(fields)
+}
+
+// normalizeTags lowercases, strips a leading '#', and deduplicates tags,
+// preserving order.
+func normalizeTags(tags []string) []string {
+	var out []string
+	for _, t := range tags {
+		t = normalizeTag(t)
+		if t != "" && !slices.Contains(out, t) {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+func normalizeTag(t string) string {
+	return strings.ToLower(strings.TrimPrefix(strings.TrimSpace(t), "#"))
+}
+
+// readFile loads the sessions map from path.
+func readFile(path string) (map[string]Meta, error) {
+	sessions := make(map[string]Meta)
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return sessions, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var f file
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	if f.Sessions != nil {
+		sessions = f.Sessions
+	}
+	return sessions, nil
+}
+
+// writeFile persists sessions atomically (temp file + rename).
+func writeFile(path string, sessions map[string]Meta) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(file{Version: fileVersion, Sessions: sessions}, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
@@ -0,0 +1,141 @@
+package sessionmeta
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestOpenMissingFile(t *testing.T) {
+	s, err := Open(filepath.Join(t.TempDir(), "session-meta.json"))
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	if m := s.Get("claude-code", "abc"); !m.IsEmpty() {
+		t.Errorf("expected empty meta, got %+v", m)
+	}
+}
+
+func TestOpenCorruptFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session-meta.json")
+	if err := os.WriteFile(path, []byte("{not json"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Open(path); err == nil {
+		t.Error("expected parse error for corrupt file")
+	}
+}
+
+func TestStarTagsNotePersist(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session-meta.json")
+	s, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	starred, err := s.ToggleStar("codex", "s1")
+	if err != nil || !starred {
+		t.Fatalf("ToggleStar() = %v, %v; want true, nil", starred, err)
+	}
+	if err := s.SetTags("codex", "s1", []string{"#Refactor", "good", "refactor"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SetNote("codex", "s1", "  worth revisiting  "); err != nil {
+		t.Fatal(err)
+	}
+
+	reopened, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := reopened.Get("codex", "s1")
+	if !m.Starred {
+		t.Error("star not persisted")
+	}
+	if want := []string{"refactor", "good"}; !reflect.DeepEqual(m.Tags, want) {
+		t.Errorf("tags = %v, want %v", m.Tags, want)
+	}
+	if m.Note != "worth revisiting" {
+		t.Errorf("note = %q", m.Note)
+	}
+	if !m.HasTag("REFACTOR") {
+		t.Error("HasTag should be case-insensitive")
+	}
+	if got := reopened.AllTags(); !reflect.DeepEqual(got, []string{"good", "refactor"}) {
+		t.Errorf("AllTags() = %v", got)
+	}
+}
+
+func TestEmptyEntriesArePruned(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session-meta.json")
+	s, _ := Open(path)
+	_, _ = s.ToggleStar("claude-code", "x")
+	_, _ = s.ToggleStar("claude-code", "x")
+
+	s.mu.RLock()
+	n := len(s.sessions)
+	s.mu.RUnlock()
+	if n != 0 {
+		t.Errorf("expected unstarred entry to be pruned, have %d entries", n)
+	}
+}
+
+func TestAnnotations(t *testing.T) {
+	s, _ := Open(filepath.Join(t.TempDir(), "session-meta.json"))
+
+	if err := s.SetAnnotation("pi", "s", "m1", "great plan"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SetAnnotation("pi", "s", "m2", "wrong file"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SetAnnotation("pi", "s", "m1", "great plan, but slow"); err != nil {
+		t.Fatal(err)
+	}
+
+	got := s.MessageAnnotations("pi", "s")
+	want := map[string]string{"m1": "great plan, but slow", "m2": "wrong file"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("MessageAnnotations() = %v, want %v", got, want)
+	}
+
+	if err := s.SetAnnotation("pi", "s", "m2", ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := s.Get("pi", "s").Annotation("m2"); ok {
+		t.Error("empty text should remove the annotation")
+	}
+}
+
+func TestUpdateMergesConcurrentWriters(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session-meta.json")
+	a, _ := Open(path)
+	b, _ := Open(path)
+
+	_, _ = a.ToggleStar("codex", "one")
+	_, _ = b.ToggleStar("codex", "two")
+
+	c, _ := Open(path)
+	if !c.Get("codex", "one").Starred || !c.Get("codex", "two").Starred {
+		t.Error("second writer clobbered the first writer's change")
+	}
+}
+
+func TestNilStore(t *testing.T) {
+	var s *Store
+	if tags := s.SessionTags("a", "b"); tags != nil {
+		t.Errorf("nil store returned tags %v", tags)
+	}
+	if err := s.SetNote("a", "b", "x"); err == nil {
+		t.Error("expected error writing to nil store")
+	}
+}
+
+func TestParseTags(t *testing.T) {
+	got := ParseTags("#bug, Feature  ui,,bug")
+	want := []string{"bug", "feature", "ui"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseTags() = %v, want %v", got, want)
+	}
+}
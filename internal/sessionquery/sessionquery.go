@@ -0,0 +1,533 @@
+// Package sessionquery implements the session filter query language shared
+// by the conversations plugin and `sidecar sessions list`.
+//
+// A query is a whitespace-separated list of terms, all of which must match:
+//
+//	adapter:codex model:opus cost>2 since:7d worktree:feature-x
+//	file:internal/app/*.go tool:Bash has:error -is:subagent "login bug"
+//
+// Bare words match the session name, slug, ID, or adapter name. A leading
+// '-' negates a term, values may be double-quoted, and @name expands a
+// saved filter. Terms that inspect message content (model, file, tool,
+// has:error) need Details; callers check NeedsDetails before loading them.
+package sessionquery
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/marcus/sidecar/internal/adapter"
+	"github.com/marcus/sidecar/internal/adapter/pricing"
+	"github.com/marcus/sidecar/internal/sessionmeta"
+	"github.com/marcus/sidecar/internal/timebound"
+)
+
+// Fields lists the supported field names, for help text and completion.
+var Fields = []string{
+	"adapter", "model", "worktree", "name", "file", "tool", "tag", "category",
+	"has", "is", "cost", "tokens", "messages", "duration", "since", "until",
+}
+
+// detailFields are the fields that need per-message Details.
+var detailFields = map[string]bool{"model": true, "file": true, "tool": true}
+
+// numericFields accept comparison operators.
+var numericFields = map[string]bool{"cost": true, "tokens": true, "messages": true, "duration": true}
+
+// Term is a single parsed query term.
+type Term struct {
+	Field  string // "" for a bare text term
+	Op     string // ":", "=", ">", ">=", "<", "<="
+	Value  string
+	Negate bool
+
+	num  float64        // parsed numeric value (numeric fields)
+	at   time.Time      // resolved bound (since/until)
+	glob *regexp.Regexp // compiled pattern (file, worktree)
+}
+
+// Query is a parsed filter expression. The zero value matches everything.
+type Query struct {
+	Terms []Term
+	raw   string
+}
+
+// Details holds per-session facts derived from messages.
+type Details struct {
+	Models       []string
+	Files        []string
+	Tools        map[string]int
+	HasError     bool
+	Cost         float64
+	MessageCount int
+}
+
+// Subject is what a query is evaluated against.
+type Subject struct {
+	Session adapter.Session
+	Meta    sessionmeta.Meta
+	Details *Details // nil if not loaded
+}
+
+// Parse parses a query string. saved maps filter names to query strings
+// for @name expansion and may be nil.
+func Parse(input string, saved map[string]string) (*Query, error) {
+	return parse(input, saved, time.Now(), 0)
+}
+
+func parse(input string, saved map[string]string, now time.Time, depth int) (*Query, error) {
+	if depth > 8 {
+		return nil, fmt.Errorf("saved filters nest too deeply")
+	}
+	tokens, err := tokenize(input)
+	if err != nil {
+		return nil, err
+	}
+	q := &Query{raw: strings.TrimSpace(input)}
+	for _, tok := range tokens {
+		if name, ok := strings.CutPrefix(tok, "@"); ok && name != "" {
+			expr, found := saved[name]
+			if !found {
+				return nil, fmt.Errorf("unknown saved filter @%s", name)
+			}
+			sub, err := parse(expr, saved, now, depth+1)
+			if err != nil {
+				return nil, fmt.Errorf("@%s: %w", name, err)
+			}
+			q.Terms = append(q.Terms, sub.Terms...)
+			continue
+		}
+		t, err := parseTerm(tok, now)
+		if err != nil {
+			return nil, err
+		}
+		q.Terms = append(q.Terms, t)
+	}
+	return q, nil
+}
+
+// tokenize splits input on whitespace, keeping double-quoted runs intact
+// and stripping the quotes.
+func tokenize(input string) ([]string, error) {
+	var tokens []string
+	var cur strings.Builder
+	inQuote, started := false, false
+	for _, r := range input {
+		switch {
+		case r == '"':
+			inQuote = !inQuote
+			started = true
+		case !inQuote && (r == ' ' || r == '\t' || r == '\n'):
+			if started {
+				tokens = append(tokens, cur.String())
+				cur.Reset()
+				started = false
+			}
+		default:
+			cur.WriteRune(r)
+			started = true
+		}
+	}
+	if inQuote {
+		return nil, fmt.Errorf("unterminated quote")
+	}
+	if started {
+		tokens = append(tokens, cur.String())
+	}
+	return tokens, nil
+}
+
+// parseTerm parses one token into a Term.
+func parseTerm(tok string, now time.Time) (Term, error) {
+	var t Term
+	if len(tok) > 1 && tok[0] == '-' {
+		t.Negate = true
+		tok = tok[1:]
+	}
+
+	idx := strings.IndexAny(tok, ":<>=")
+	field := ""
+	if idx > 0 {
+		field = strings.ToLower(tok[:idx])
+	}
+	if field == "" || !isField(field) {
+		if idx > 0 && isIdent(tok[:idx]) && tok[idx] == ':' {
+			return t, fmt.Errorf("unknown field %q (want one of %s)", tok[:idx], strings.Join(Fields, ", "))
+		}
+		t.Value = tok
+		return t, nil
+	}
+
+	t.Field = field
+	rest := tok[idx:]
+	for _, op := range []string{">=", "<=", ":", "=", ">", "<"} {
+		if strings.HasPrefix(rest, op) {
+			t.Op = op
+			t.Value = rest[len(op):]
+			break
+		}
+	}
+	if t.Value == "" {
+		return t, fmt.Errorf("%s: missing value", field)
+	}
+	if t.Op != ":" && t.Op != "=" && !numericFields[field] {
+		return t, fmt.Errorf("%s: comparison %q only applies to cost, tokens, messages, duration", field, t.Op)
+	}
+
+	var err error
+	switch field {
+	case "cost", "tokens", "messages", "duration":
+		t.num, err = parseNumber(field, t.Value)
+	case "since":
+		t.at, err = timebound.Parse(t.Value, now)
+	case "until":
+		t.at, err = timebound.ParseUntil(t.Value, now)
+	case "file", "worktree":
+		t.glob, err = compileGlob(t.Value)
+	case "has":
+		if !contains([]string{"error", "tools", "files", "tags", "note", "annotations"}, t.Value) {
+			err = fmt.Errorf("has:%s: want error, tools, files, tags, note, or annotations", t.Value)
+		}
+	case "is":
+		if !contains([]string{"active", "starred", "subagent"}, t.Value) {
+			err = fmt.Errorf("is:%s: want active, starred, or subagent", t.Value)
+		}
+	}
+	return t, err
+}
+
+func isField(name string) bool {
+	return contains(Fields, name)
+}
+
+func isIdent(s string) bool {
+	for _, r := range s {
+		if (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') {
+			return false
+		}
+	}
+	return s != ""
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// parseNumber parses a numeric field value. Token and message counts accept
+// k/M suffixes, cost accepts a leading '$' but no suffix (cost>2m would be
+// two million dollars), and duration uses Go syntax.
+func parseNumber(field, v string) (float64, error) {
+	switch field {
+	case "duration":
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return 0, fmt.Errorf("duration: invalid value %q (want e.g. 30m, 2h)", v)
+		}
+		return d.Seconds(), nil
+	case "cost":
+		n, err := strconv.ParseFloat(strings.TrimPrefix(v, "$"), 64)
+		if err != nil {
+			return 0, fmt.Errorf("cost: invalid amount %q (want dollars, e.g. 2 or $0.50)", v)
+		}
+		return n, nil
+	}
+	mult := 1.0
+	switch {
+	case strings.HasSuffix(v, "k") || strings.HasSuffix(v, "K"):
+		mult, v = 1e3, v[:len(v)-1]
+	case strings.HasSuffix(v, "M") || strings.HasSuffix(v, "m"):
+		mult, v = 1e6, v[:len(v)-1]
+	}
+	n, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%s: invalid number %q", field, v)
+	}
+	return n * mult, nil
+}
+
+// compileGlob turns a path glob into a regexp matched against path
+// suffixes: "*" and "?" stay within a segment, "**" crosses segments.
+// Values without glob characters match as substrings.
+func compileGlob(pattern string) (*regexp.Regexp, error) {
+	if !strings.ContainsAny(pattern, "*?") {
+		return regexp.Compile("(?i)" + regexp.QuoteMeta(pattern))
+	}
+	var sb strings.Builder
+	sb.WriteString("(?i)(^|/)")
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; c {
+		case '*':
+			if i+1 < len(pattern) && pattern[i+1] == '*' {
+				sb.WriteString(".*")
+				i++
+			} else {
+				sb.WriteString("[^/]*")
+			}
+		case '?':
+			sb.WriteString("[^/]")
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	sb.WriteString("$")
+	return regexp.Compile(sb.String())
+}
+
+// IsEmpty returns true if the query has no terms.
+func (q *Query) IsEmpty() bool {
+	return q == nil || len(q.Terms) == 0
+}
+
+// HasFields returns true if any term is a field term (not bare text).
+func (q *Query) HasFields() bool {
+	if q == nil {
+		return false
+	}
+	for _, t := range q.Terms {
+		if t.Field != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// NeedsDetails returns true if evaluating the query requires Details.
+func (q *Query) NeedsDetails() bool {
+	if q == nil {
+		return false
+	}
+	for _, t := range q.Terms {
+		if detailFields[t.Field] || (t.Field == "has" && (t.Value == "error" || t.Value == "tools" || t.Value == "files")) {
+			return true
+		}
+	}
+	return false
+}
+
+// String returns the query as typed.
+func (q *Query) String() string {
+	if q == nil {
+		return ""
+	}
+	return q.raw
+}
+
+// Match reports whether sub satisfies every term. Terms that need Details
+// do not match when sub.Details is nil.
+func (q *Query) Match(sub Subject) bool {
+	if q == nil {
+		return true
+	}
+	for _, t := range q.Terms {
+		if t.match(sub) == t.Negate {
+			return false
+		}
+	}
+	return true
+}
+
+func (t Term) match(sub Subject) bool {
+	s := sub.Session
+	d := sub.Details
+	value := strings.ToLower(t.Value)
+
+	switch t.Field {
+	case "":
+		return strings.Contains(strings.ToLower(s.Name), value) ||
+			strings.Contains(strings.ToLower(s.Slug), value) ||
+			strings.Contains(strings.ToLower(s.ID), value) ||
+			strings.Contains(strings.ToLower(s.AdapterName), value)
+	case "adapter":
+		return strings.EqualFold(s.AdapterID, value) ||
+			strings.Contains(strings.ToLower(s.AdapterID), value) ||
+			strings.Contains(strings.ToLower(s.AdapterName), value)
+	case "name":
+		return strings.Contains(strings.ToLower(s.Name), value)
+	case "worktree":
+		if s.WorktreeName == "" {
+			return value == "main"
+		}
+		return t.glob.MatchString(s.WorktreeName)
+	case "category":
+		return strings.EqualFold(s.SessionCategory, value)
+	case "tag":
+		return sub.Meta.HasTag(value)
+	case "is":
+		switch value {
+		case "active":
+			return s.IsActive
+		case "starred":
+			return sub.Meta.Starred
+		case "subagent":
+			return s.IsSubAgent
+		}
+	case "has":
+		switch value {
+		case "tags":
+			return len(sub.Meta.Tags) > 0
+		case "note":
+			return sub.Meta.Note != ""
+		case "annotations":
+			return len(sub.Meta.Annotations) > 0
+		}
+		if d == nil {
+			return false
+		}
+		switch value {
+		case "error":
+			return d.HasError
+		case "tools":
+			return len(d.Tools) > 0
+		case "files":
+			return len(d.Files) > 0
+		}
+	case "model":
+		if d == nil {
+			return false
+		}
+		for _, m := range d.Models {
+			if strings.Contains(strings.ToLower(m), value) {
+				return true
+			}
+		}
+	case "tool":
+		if d == nil {
+			return false
+		}
+		for name := range d.Tools {
+			if strings.EqualFold(name, value) {
+				return true
+			}
+		}
+	case "file":
+		if d == nil {
+			return false
+		}
+		for _, f := range d.Files {
+			if t.glob.MatchString(f) {
+				return true
+			}
+		}
+	case "since":
+		return !s.UpdatedAt.Before(t.at)
+	case "until":
+		return !s.UpdatedAt.After(t.at)
+	case "cost":
+		cost := s.EstCost
+		if cost == 0 && d != nil {
+			cost = d.Cost
+		}
+		return compare(cost, t.Op, t.num)
+	case "tokens":
+		return compare(float64(s.TotalTokens), t.Op, t.num)
+	case "messages":
+		n := s.MessageCount
+		if n == 0 && d != nil {
+			n = d.MessageCount
+		}
+		return compare(float64(n), t.Op, t.num)
+	case "duration":
+		return compare(s.Duration.Seconds(), t.Op, t.num)
+	}
+	return false
+}
+
+// compare applies a comparison operator. ":" on a numeric field means ">=",
+// so cost:2 reads as "cost of at least $2".
+func compare(v float64, op string, n float64) bool {
+	switch op {
+	case ">":
+		return v > n
+	case ">=", ":":
+		return v >= n
+	case "<":
+		return v < n
+	case "<=":
+		return v <= n
+	case "=":
+		return v == n
+	}
+	return false
+}
+
+// DetailsFromMessages derives Details from a session's messages.
+func DetailsFromMessages(messages []adapter.Message) *Details {
+	d := &Details{Tools: make(map[string]int)}
+	models := make(map[string]bool)
+	files := make(map[string]bool)
+	for _, msg := range messages {
+		if msg.Role == "user" || msg.Role == "assistant" {
+			d.MessageCount++
+		}
+		if msg.Model != "" {
+			models[msg.Model] = true
+			d.Cost += messageCost(msg)
+		}
+		for _, tu := range msg.ToolUses {
+			d.Tools[tu.Name]++
+			if fp := toolFilePath(tu.Input); fp != "" {
+				files[fp] = true
+			}
+		}
+		for _, b := range msg.ContentBlocks {
+			if b.Type == "tool_result" && b.IsError {
+				d.HasError = true
+			}
+		}
+	}
+	for m := range models {
+		d.Models = append(d.Models, m)
+	}
+	for f := range files {
+		d.Files = append(d.Files, f)
+	}
+	sort.Strings(d.Models)
+	sort.Strings(d.Files)
+	return d
+}
+
+// oSeriesModel matches OpenAI reasoning models such as o1, o3-mini and
+// o4-mini, but not other names that merely start with "o".
+var oSeriesModel = regexp.MustCompile(`^o[1-9][0-9]*(-|$)`)
+
+// messageCost estimates a message's cost. OpenAI models have no pricing
+// data and count as zero.
+func messageCost(msg adapter.Message) float64 {
+	m := strings.ToLower(msg.Model)
+	if strings.HasPrefix(m, "gpt-") || oSeriesModel.MatchString(m) || strings.Contains(m, "codex") {
+		return 0
+	}
+	return pricing.ModelCost(m, pricing.Usage{
+		InputTokens:  msg.InputTokens,
+		OutputTokens: msg.OutputTokens,
+		CacheRead:    msg.CacheRead,
+		CacheWrite:   msg.CacheWrite,
+	})
+}
+
+// toolFilePath extracts a file path from a tool input JSON object.
+func toolFilePath(input string) string {
+	if input == "" || input[0] != '{' {
+		return ""
+	}
+	var data map[string]any
+	if err := json.Unmarshal([]byte(input), &data); err != nil {
+		return ""
+	}
+	for _, key := range []string{"file_path", "path", "notebook_path"} {
+		if fp, ok := data[key].(string); ok && fp != "" {
+			return fp
+		}
+	}
+	return ""
+}
@@ -0,0 +1,226 @@
+package sessionquery
+
+import (
+	"testing"
+	"time"
+
+	"github.com/marcus/sidecar/internal/adapter"
+	"github.com/marcus/sidecar/internal/sessionmeta"
+)
+
+func TestTokenize(t *testing.T) {
+	got, err := tokenize(`adapter:codex  name:"login bug" "two words"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"adapter:codex", "name:login bug", "two words"}
+	if len(got) != len(want) {
+		t.Fatalf("tokenize() = %q, want %q", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("token %d = %q, want %q", i, got[i], want[i])
+		}
+	}
+	if _, err := tokenize(`name:"open`); err == nil {
+		t.Error("expected error for unterminated quote")
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, input := range []string{
+		"colour:red",
+		"cost>abc",
+		"cost>2m",
+		"cost>1k",
+		"adapter>codex",
+		"has:nothing",
+		"since:yesterdayish",
+		"@missing",
+		"tool:",
+	} {
+		if _, err := Parse(input, nil); err == nil {
+			t.Errorf("Parse(%q) expected error", input)
+		}
+	}
+}
+
+func TestMatchSessionFields(t *testing.T) {
+	now := time.Now()
+	s := adapter.Session{
+		ID:           "abc123",
+		Name:         "Fix login bug",
+		AdapterID:    "codex",
+		AdapterName:  "Codex",
+		UpdatedAt:    now.Add(-48 * time.Hour),
+		Duration:     45 * time.Minute,
+		TotalTokens:  120000,
+		EstCost:      3.5,
+		WorktreeName: "feature-x",
+	}
+	meta := sessionmeta.Meta{Starred: true, Tags: []string{"auth"}}
+
+	tests := []struct {
+		query string
+		want  bool
+	}{
+		{"", true},
+		{"login", true},
+		{"logout", false},
+		{"-logout", true},
+		{"adapter:codex", true},
+		{"adapter:claude", false},
+		{"cost>2", true},
+		{"cost>=3.5", true},
+		{"cost<2", false},
+		{"tokens>100k", true},
+		{"tokens>1M", false},
+		{"duration>30m", true},
+		{"since:7d", true},
+		{"since:1d", false},
+		{"until:1d", true},
+		{"worktree:feature-*", true},
+		{"worktree:main", false},
+		{"is:starred tag:auth", true},
+		{"-is:starred", false},
+		{"is:active", false},
+		{`name:"login bug"`, true},
+		{"has:tags -has:note", true},
+	}
+	for _, tt := range tests {
+		q, err := Parse(tt.query, nil)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", tt.query, err)
+		}
+		if got := q.Match(Subject{Session: s, Meta: meta}); got != tt.want {
+			t.Errorf("Match(%q) = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestMatchUntilDateIsInclusive(t *testing.T) {
+	day := time.Date(2025, 3, 14, 0, 0, 0, 0, time.Local)
+	q, err := Parse("until:2025-03-14", nil)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	s := adapter.Session{UpdatedAt: day.Add(18 * time.Hour)}
+	if !q.Match(Subject{Session: s}) {
+		t.Error("until:2025-03-14 should include sessions later that day")
+	}
+	s.UpdatedAt = day.Add(24 * time.Hour)
+	if q.Match(Subject{Session: s}) {
+		t.Error("until:2025-03-14 should exclude the next day")
+	}
+}
+
+func TestMatchMainWorktree(t *testing.T) {
+	q, _ := Parse("worktree:main", nil)
+	if !q.Match(Subject{Session: adapter.Session{ID: "x"}}) {
+		t.Error("session without a worktree name should match worktree:main")
+	}
+}
+
+func TestMatchDetails(t *testing.T) {
+	messages := []adapter.Message{
+		{Role: "user", Content: "go"},
+		{Role: "assistant", Model: "claude-opus-4-5-20251101",
+			ToolUses: []adapter.ToolUse{
+				{Name: "Edit", Input: `{"file_path":"/repo/internal/app/model.go"}`},
+				{Name: "Bash", Input: `{"command":"go test"}`},
+			}},
+		{Role: "user", ContentBlocks: []adapter.ContentBlock{{Type: "tool_result", IsError: true}}},
+	}
+	d := DetailsFromMessages(messages)
+	sub := Subject{Session: adapter.Session{ID: "s"}, Details: d}
+
+	tests := []struct {
+		query string
+		want  bool
+	}{
+		{"model:opus", true},
+		{"model:sonnet", false},
+		{"tool:bash", true},
+		{"tool:Write", false},
+		{"file:internal/app/*.go", true},
+		{"file:app/*.go", true},
+		{"file:internal/*.go", false},
+		{"file:internal/**/*.go", true},
+		{"file:model.go", true},
+		{"has:error", true},
+		{"has:files", true},
+		{"messages>=3", true},
+	}
+	for _, tt := range tests {
+		q, err := Parse(tt.query, nil)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", tt.query, err)
+		}
+		if !q.NeedsDetails() && tt.query != "messages>=3" {
+			t.Errorf("%q should need details", tt.query)
+		}
+		if got := q.Match(sub); got != tt.want {
+			t.Errorf("Match(%q) = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+
+	// Without details, detail terms never match.
+	q, _ := Parse("tool:Bash", nil)
+	if q.Match(Subject{Session: sub.Session}) {
+		t.Error("tool term matched without details")
+	}
+}
+
+func TestSavedFilters(t *testing.T) {
+	saved := map[string]string{
+		"pricey": "cost>5",
+		"review": "@pricey adapter:codex",
+		"loop":   "@loop",
+	}
+	q, err := Parse("@review is:active", saved)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(q.Terms) != 3 {
+		t.Fatalf("expected 3 expanded terms, got %+v", q.Terms)
+	}
+	if q.String() != "@review is:active" {
+		t.Errorf("String() = %q", q.String())
+	}
+	if _, err := Parse("@loop", saved); err == nil {
+		t.Error("expected error for recursive saved filter")
+	}
+}
+
+func TestHasFields(t *testing.T) {
+	q, _ := Parse("just text", nil)
+	if q.HasFields() {
+		t.Error("bare words are not field terms")
+	}
+	q, _ = Parse("text adapter:codex", nil)
+	if !q.HasFields() {
+		t.Error("expected field term")
+	}
+}
+
+func TestMessageCost(t *testing.T) {
+	tests := []struct {
+		model string
+		free  bool
+	}{
+		{"o1", true},
+		{"o3-mini", true},
+		{"o4-mini", true},
+		{"gpt-5", true},
+		{"gpt-5-codex", true},
+		{"opus", false},
+		{"openrouter/anthropic/claude-sonnet-4", false},
+		{"claude-opus-4-5-20251101", false},
+	}
+	for _, tt := range tests {
+		msg := adapter.Message{Model: tt.model, TokenUsage: adapter.TokenUsage{InputTokens: 1000, OutputTokens: 1000}}
+		if got := messageCost(msg); (got == 0) != tt.free {
+			t.Errorf("messageCost(%q) = %v, want free=%v", tt.model, got, tt.free)
+		}
+	}
+}
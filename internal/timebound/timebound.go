@@ -0,0 +1,57 @@
+// Package timebound parses the time bounds accepted by session filters:
+// absolute dates, RFC3339 timestamps, and ages measured back from now.
+package timebound
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// dateLayout is the bare-date form, interpreted in now's location.
+const dateLayout = "2006-01-02"
+
+// Parse parses an absolute date (2006-01-02 or RFC3339) or a relative age
+// such as "90m", "24h", "7d" or "2w" measured back from now. A bare date
+// means the start of that day. An empty string is the zero time.
+func Parse(s string, now time.Time) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation(dateLayout, s, now.Location()); err == nil {
+		return t, nil
+	}
+	if len(s) > 1 {
+		unit := s[len(s)-1]
+		if n, err := strconv.Atoi(s[:len(s)-1]); err == nil && n >= 0 {
+			switch unit {
+			case 'd':
+				return now.AddDate(0, 0, -n), nil
+			case 'w':
+				return now.AddDate(0, 0, -7*n), nil
+			}
+		}
+	}
+	if d, err := time.ParseDuration(s); err == nil {
+		return now.Add(-d), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q (want YYYY-MM-DD, RFC3339, or an age like 7d)", s)
+}
+
+// ParseUntil is Parse for an upper bound: a bare date means the end of that
+// day, so "until 2026-10-01" includes October 1st.
+func ParseUntil(s string, now time.Time) (time.Time, error) {
+	t, err := Parse(s, now)
+	if err != nil {
+		return t, err
+	}
+	if _, dateErr := time.ParseInLocation(dateLayout, strings.TrimSpace(s), now.Location()); dateErr == nil {
+		t = t.AddDate(0, 0, 1).Add(-time.Nanosecond)
+	}
+	return t, nil
+}
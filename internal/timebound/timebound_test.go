@@ -0,0 +1,46 @@
+package timebound
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		in      string
+		want    time.Time
+		wantErr bool
+	}{
+		{"", time.Time{}, false},
+		{"2026-03-01", time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), false},
+		{"7d", now.AddDate(0, 0, -7), false},
+		{"2w", now.AddDate(0, 0, -14), false},
+		{"36h", now.Add(-36 * time.Hour), false},
+		{"yesterday", time.Time{}, true},
+	}
+	for _, tt := range tests {
+		got, err := Parse(tt.in, now)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Parse(%q) err = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("Parse(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParseUntil(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	got, err := ParseUntil("2026-03-01", now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Date(2026, 3, 1, 23, 59, 59, 999999999, time.UTC); !got.Equal(want) {
+		t.Errorf("ParseUntil(date) = %v, want end of day %v", got, want)
+	}
+	if got, _ := ParseUntil("7d", now); !got.Equal(now.AddDate(0, 0, -7)) {
+		t.Errorf("ParseUntil(7d) = %v", got)
+	}
+}
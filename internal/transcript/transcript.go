@@ -0,0 +1,373 @@
+// Package transcript records terminal sessions to rotating log files: the raw
+// byte stream, and a plain-text copy with escape sequences removed that can
+// be read and searched after the session is gone.
+//
+// Each session logs to its own directory:
+//
+//	<dir>/raw.log          raw output, including escape sequences
+//	<dir>/transcript.log   plain text
+//	<dir>/meta.json        what the session was (see Meta)
+//
+// When a log would grow past its size limit it is rotated to raw.log.1,
+// raw.log.2, ... keeping a fixed number of old files.
+package transcript
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// RawFile holds the session's raw output.
+	RawFile = "raw.log"
+	// TextFile holds the output with escape sequences removed.
+	TextFile = "transcript.log"
+	// MetaFile describes the session.
+	MetaFile = "meta.json"
+
+	// DefaultMaxBytes is the size at which a log is rotated.
+	DefaultMaxBytes = 10 << 20
+	// DefaultKeep is how many rotated files are kept per log.
+	DefaultKeep = 3
+
+	// maxLineBytes flushes text lines that never end, such as a TUI redrawing
+	// in place, so the text log doesn't buffer without bound.
+	maxLineBytes = 64 << 10
+)
+
+// Meta describes a recorded session.
+type Meta struct {
+	Session string    `json:"session"`        // Session name
+	Title   string    `json:"title"`          // Worktree or shell name
+	Kind    string    `json:"kind"`           // "agent" or "shell"
+	Path    string    `json:"path,omitempty"` // Directory the session ran in
+	Started time.Time `json:"started"`
+}
+
+// Entry is a recorded session found by List.
+type Entry struct {
+	Meta
+	Dir      string
+	Size     int64     // Bytes of plain text, including rotated files
+	Modified time.Time // Last write
+}
+
+// Writer writes a session's output to its raw and text logs. It is safe for
+// concurrent use.
+type Writer struct {
+	mu    sync.Mutex
+	raw   *rotatingFile
+	text  *rotatingFile
+	strip stripper
+}
+
+// NewWriter opens the logs in dir for appending, creating dir if needed.
+// A maxBytes or keep <= 0 uses the default.
+func NewWriter(dir string, maxBytes int64, keep int) (*Writer, error) {
+	if maxBytes <= 0 {
+		maxBytes = DefaultMaxBytes
+	}
+	if keep <= 0 {
+		keep = DefaultKeep
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	raw, err := openRotating(filepath.Join(dir, RawFile), maxBytes, keep)
+	if err != nil {
+		return nil, err
+	}
+	text, err := openRotating(filepath.Join(dir, TextFile), maxBytes, keep)
+	if err != nil {
+		_ = raw.Close()
+		return nil, err
+	}
+	return &Writer{raw: raw, text: text}, nil
+}
+
+// Write logs p. The text log receives complete lines only; a trailing
+// partial line is written by a later Write or by Close.
+func (w *Writer) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if _, err := w.raw.Write(p); err != nil {
+		return 0, err
+	}
+	if text := w.strip.feed(p); len(text) > 0 {
+		if _, err := w.text.Write(text); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// Close flushes any partial line and closes the logs.
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	var errs []error
+	if text := w.strip.flush(); len(text) > 0 {
+		_, err := w.text.Write(text)
+		errs = append(errs, err)
+	}
+	errs = append(errs, w.raw.Close(), w.text.Close())
+	return errors.Join(errs...)
+}
+
+// WriteMeta records what a session is, unless dir already has a record:
+// a session that is logged again after a restart keeps its start time.
+func WriteMeta(dir string, meta Meta) error {
+	path := filepath.Join(dir, MetaFile)
+	if _, err := os.Stat(path); err == nil {
+		return nil
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(meta, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// List returns the sessions recorded under root, most recently written first.
+func List(root string) ([]Entry, error) {
+	dirs, err := os.ReadDir(root)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []Entry
+	for _, d := range dirs {
+		if !d.IsDir() {
+			continue
+		}
+		dir := filepath.Join(root, d.Name())
+		entry := Entry{Dir: dir, Meta: Meta{Session: d.Name(), Title: d.Name()}}
+		if data, err := os.ReadFile(filepath.Join(dir, MetaFile)); err == nil {
+			_ = json.Unmarshal(data, &entry.Meta)
+		}
+		files := textFiles(dir)
+		if len(files) == 0 {
+			continue
+		}
+		for _, f := range files {
+			if info, err := os.Stat(f); err == nil {
+				entry.Size += info.Size()
+				if info.ModTime().After(entry.Modified) {
+					entry.Modified = info.ModTime()
+				}
+			}
+		}
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Modified.After(entries[j].Modified) })
+	return entries, nil
+}
+
+// ReadText returns a session's plain-text transcript, oldest output first.
+func ReadText(dir string) (string, error) {
+	var sb strings.Builder
+	for _, f := range textFiles(dir) {
+		data, err := os.ReadFile(f)
+		if err != nil {
+			return "", err
+		}
+		sb.Write(data)
+	}
+	return sb.String(), nil
+}
+
+// textFiles returns the text log and its rotations that exist, oldest first.
+func textFiles(dir string) []string {
+	var files []string
+	for i := 1; ; i++ {
+		path := fmt.Sprintf("%s.%d", filepath.Join(dir, TextFile), i)
+		if _, err := os.Stat(path); err != nil {
+			break
+		}
+		files = append([]string{path}, files...)
+	}
+	if path := filepath.Join(dir, TextFile); fileExists(path) {
+		files = append(files, path)
+	}
+	return files
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// rotatingFile is an append-only file rotated once it reaches maxBytes.
+type rotatingFile struct {
+	path     string
+	maxBytes int64
+	keep     int
+	f        *os.File
+	size     int64
+}
+
+func openRotating(path string, maxBytes int64, keep int) (*rotatingFile, error) {
+	r := &rotatingFile{path: path, maxBytes: maxBytes, keep: keep}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *rotatingFile) open() error {
+	f, err := os.OpenFile(r.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return err
+	}
+	r.f, r.size = f, info.Size()
+	return nil
+}
+
+func (r *rotatingFile) Write(p []byte) (int, error) {
+	if r.size > 0 && r.size+int64(len(p)) > r.maxBytes {
+		if err := r.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := r.f.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+// rotate shifts path.N to path.N+1, dropping the oldest, and starts a new file.
+func (r *rotatingFile) rotate() error {
+	if err := r.f.Close(); err != nil {
+		return err
+	}
+	_ = os.Remove(fmt.Sprintf("%s.%d", r.path, r.keep))
+	for i := r.keep - 1; i >= 1; i-- {
+		_ = os.Rename(fmt.Sprintf("%s.%d", r.path, i), fmt.Sprintf("%s.%d", r.path, i+1))
+	}
+	if err := os.Rename(r.path, r.path+".1"); err != nil {
+		return err
+	}
+	return r.open()
+}
+
+func (r *rotatingFile) Close() error {
+	return r.f.Close()
+}
+
+// stripState is where the stripper is within an escape sequence.
+type stripState int
+
+const (
+	stateText   stripState = iota
+	stateEsc               // After ESC
+	stateCSI               // Control sequence, up to its final byte
+	stateString            // OSC, DCS and similar, up to BEL or ST
+	stateStrEsc            // ESC inside a string; "\" ends it
+	stateSkip              // One more byte, as in ESC ( B
+)
+
+// stripper turns a terminal byte stream into plain text lines. A carriage
+// return not followed by a newline starts the line over, as the terminal
+// would overwrite it, and backspace removes the previous character.
+type stripper struct {
+	state stripState
+	cr    bool
+	line  []byte
+}
+
+// feed consumes p and returns the lines it completed, newline-terminated.
+func (s *stripper) feed(p []byte) []byte {
+	var out []byte
+	for _, b := range p {
+		switch s.state {
+		case stateEsc:
+			switch b {
+			case '[':
+				s.state = stateCSI
+			case ']', 'P', 'X', '^', '_':
+				s.state = stateString
+			case '(', ')', '*', '+', '#', '%':
+				s.state = stateSkip
+			default:
+				s.state = stateText
+			}
+			continue
+		case stateCSI:
+			if b >= 0x40 && b <= 0x7e {
+				s.state = stateText
+			}
+			continue
+		case stateString:
+			switch b {
+			case 0x07:
+				s.state = stateText
+			case 0x1b:
+				s.state = stateStrEsc
+			}
+			continue
+		case stateStrEsc:
+			s.state = stateString
+			if b == '\\' {
+				s.state = stateText
+			}
+			continue
+		case stateSkip:
+			s.state = stateText
+			continue
+		}
+
+		if s.cr && b != '\n' {
+			s.line = s.line[:0]
+		}
+		s.cr = false
+		switch {
+		case b == 0x1b:
+			s.state = stateEsc
+		case b == '\r':
+			s.cr = true
+		case b == '\n':
+			out = append(append(out, s.line...), '\n')
+			s.line = s.line[:0]
+		case b == '\b':
+			if len(s.line) > 0 {
+				_, size := utf8.DecodeLastRune(s.line)
+				s.line = s.line[:len(s.line)-size]
+			}
+		case b == '\t' || (b >= 0x20 && b != 0x7f):
+			s.line = append(s.line, b)
+			if len(s.line) >= maxLineBytes {
+				out = append(append(out, s.line...), '\n')
+				s.line = s.line[:0]
+			}
+		}
+	}
+	return out
+}
+
+// flush returns the partial line, if any, newline-terminated.
+func (s *stripper) flush() []byte {
+	if len(s.line) == 0 {
+		return nil
+	}
+	out := append(append([]byte(nil), s.line...), '\n')
+	s.line = s.line[:0]
+	return out
+}
@@ -0,0 +1,108 @@
+package transcript
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStripper(t *testing.T) {
+	tests := []struct {
+		name string
+		in   []string // Written in separate chunks
+		want string
+	}{
+		{"plain", []string{"hello\nworld\n"}, "hello\nworld\n"},
+		{"sgr", []string{"\x1b[1;31merror\x1b[0m: boom\r\n"}, "error: boom\n"},
+		{"split escape", []string{"a\x1b[3", "2mb\x1b", "[0mc\n"}, "abc\n"},
+		{"osc title", []string{"\x1b]0;title\x07x\x1b]8;;http://x\x1b\\y\n"}, "xy\n"},
+		{"carriage return overwrites", []string{"10%\r50%\r", "100%\n"}, "100%\n"},
+		{"backspace", []string{"ab\bc\n"}, "ac\n"},
+		{"charset", []string{"\x1b(Bok\n"}, "ok\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var s stripper
+			var got []byte
+			for _, chunk := range tt.in {
+				got = append(got, s.feed([]byte(chunk))...)
+			}
+			if string(got) != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriter_RotatesAndReads(t *testing.T) {
+	dir := t.TempDir()
+	// Every line overflows the limit, so each one lands in its own file
+	w, err := NewWriter(dir, 10, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, chunk := range []string{"line 1\n", "line 2\n", "\x1b[32mline 3\x1b[0m\n", "line 4\n", "tail"} {
+		if _, err := w.Write([]byte(chunk)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, TextFile+".3")); err == nil {
+		t.Error("only 2 rotated files should be kept")
+	}
+	raw, _ := os.ReadFile(filepath.Join(dir, RawFile+".2"))
+	if string(raw) != "\x1b[32mline 3\x1b[0m\n" {
+		t.Errorf("raw log = %q, want escape sequences kept", raw)
+	}
+
+	text, err := ReadText(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Lines 1 and 2 were rotated out; the rest reads back in order
+	if text != "line 3\nline 4\ntail\n" {
+		t.Errorf("ReadText = %q", text)
+	}
+}
+
+func TestList(t *testing.T) {
+	root := t.TempDir()
+	if entries, err := List(filepath.Join(root, "missing")); err != nil || entries != nil {
+		t.Fatalf("missing root: %v, %v", entries, err)
+	}
+
+	for i, name := range []string{"sidecar-ws-old", "sidecar-ws-new"} {
+		dir := filepath.Join(root, name)
+		if err := WriteMeta(dir, Meta{Session: name, Title: strings.TrimPrefix(name, "sidecar-ws-"), Kind: "agent"}); err != nil {
+			t.Fatal(err)
+		}
+		w, err := NewWriter(dir, 0, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, _ = w.Write([]byte("output\n"))
+		_ = w.Close()
+		mod := time.Now().Add(time.Duration(i-2) * time.Hour)
+		_ = os.Chtimes(filepath.Join(dir, TextFile), mod, mod)
+	}
+	// Meta is only written once
+	_ = WriteMeta(filepath.Join(root, "sidecar-ws-new"), Meta{Title: "other"})
+	// Directories without a transcript are skipped
+	_ = os.Mkdir(filepath.Join(root, "empty"), 0755)
+
+	entries, err := List(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[0].Title != "new" || entries[1].Title != "old" {
+		t.Fatalf("entries = %+v, want new then old", entries)
+	}
+	if entries[0].Kind != "agent" || entries[0].Size != int64(len("output\n")) {
+		t.Errorf("entry = %+v", entries[0])
+	}
+}
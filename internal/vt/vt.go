@@ -0,0 +1,814 @@
+// Package vt implements an in-process VT100/xterm screen emulator.
+//
+// A Terminal consumes the byte stream an application writes to its PTY and
+// maintains the visible screen, scrollback and cursor, so sessions can be
+// rendered exactly without an external multiplexer such as tmux.
+package vt
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/charmbracelet/x/cellbuf"
+	"github.com/mattn/go-runewidth"
+)
+
+// DefaultScrollback is the number of lines kept above the screen when none is configured.
+const DefaultScrollback = 10000
+
+// line is one row of cells. Wide characters occupy a cell of Width 2
+// followed by a placeholder cell of Width 0.
+type line []cellbuf.Cell
+
+// cursor is the cursor position and pen saved by DECSC / CSI s.
+type cursor struct {
+	x, y int
+	pen  cellbuf.Style
+}
+
+// Terminal is a VT screen emulator. It is safe for concurrent use.
+type Terminal struct {
+	mu     sync.Mutex
+	parser *ansi.Parser
+
+	cols, rows int
+	screen     []line
+	mainScreen []line // Saved main screen while the alternate screen is active
+	scrollback []line
+	maxScroll  int
+
+	x, y        int
+	wrapPending bool // Cursor is past the last column; next print wraps
+	pen         cellbuf.Style
+	saved       cursor
+	top, bottom int // Scroll region, inclusive
+	tabs        *cellbuf.TabStops
+	lastRune    rune
+
+	altScreen      bool
+	cursorHidden   bool
+	noAutowrap     bool
+	appCursorKeys  bool
+	bracketedPaste bool
+	lineDrawing    bool
+	title          string
+
+	// version counts writes and resizes, so callers can skip rendering an
+	// unchanged screen.
+	version uint64
+
+	// reply receives responses to terminal queries (cursor position,
+	// device attributes), normally the PTY the application reads from.
+	reply io.Writer
+}
+
+// New returns a Terminal of the given size. reply receives answers to
+// queries the application sends (it may be nil). scrollback <= 0 uses
+// DefaultScrollback.
+func New(cols, rows, scrollback int, reply io.Writer) *Terminal {
+	if cols < 1 {
+		cols = 80
+	}
+	if rows < 1 {
+		rows = 24
+	}
+	if scrollback <= 0 {
+		scrollback = DefaultScrollback
+	}
+	t := &Terminal{
+		parser:    ansi.NewParser(),
+		cols:      cols,
+		rows:      rows,
+		maxScroll: scrollback,
+		reply:     reply,
+	}
+	t.screen = t.blankScreen()
+	t.top, t.bottom = 0, rows-1
+	t.tabs = cellbuf.DefaultTabStops(cols)
+	t.parser.SetHandler(ansi.Handler{
+		Print:     t.print,
+		Execute:   t.execute,
+		HandleCsi: t.handleCsi,
+		HandleEsc: t.handleEsc,
+		HandleOsc: t.handleOsc,
+	})
+	return t
+}
+
+// Write feeds application output to the emulator.
+func (t *Terminal) Write(p []byte) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if len(p) > 0 {
+		t.parser.Parse(p)
+		t.version++
+	}
+	return len(p), nil
+}
+
+// ReadFrom feeds output read from r until it fails, e.g. when the PTY closes.
+func (t *Terminal) ReadFrom(r io.Reader) (int64, error) {
+	buf := make([]byte, 32*1024)
+	var total int64
+	for {
+		n, err := r.Read(buf)
+		if n > 0 {
+			_, _ = t.Write(buf[:n])
+			total += int64(n)
+		}
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return total, nil
+			}
+			return total, err
+		}
+	}
+}
+
+// Version returns a counter that changes whenever output is written or the
+// screen is resized. Equal versions mean Render would return the same text.
+func (t *Terminal) Version() uint64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.version
+}
+
+// Size returns the screen size in cells.
+func (t *Terminal) Size() (cols, rows int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.cols, t.rows
+}
+
+// Cursor returns the 0-indexed cursor position on the screen and whether it is visible.
+func (t *Terminal) Cursor() (row, col int, visible bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	col = t.x
+	if col >= t.cols {
+		col = t.cols - 1
+	}
+	return t.y, col, !t.cursorHidden
+}
+
+// AppCursorKeys reports whether the application enabled cursor key mode (DECCKM).
+func (t *Terminal) AppCursorKeys() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.appCursorKeys
+}
+
+// BracketedPaste reports whether the application enabled bracketed paste.
+func (t *Terminal) BracketedPaste() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.bracketedPaste
+}
+
+// Title returns the window title last set by the application.
+func (t *Terminal) Title() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.title
+}
+
+// Resize changes the screen size. Lines are truncated or padded rather than
+// reflowed; rows removed from the top of the main screen move to scrollback.
+func (t *Terminal) Resize(cols, rows int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if cols < 1 || rows < 1 || (cols == t.cols && rows == t.rows) {
+		return
+	}
+
+	resize := func(screen []line, pushTop bool) []line {
+		// Shrinking: drop blank rows below the cursor first, then push the top
+		for len(screen) > rows && len(screen)-1 > t.y && screen[len(screen)-1].blank() {
+			screen = screen[:len(screen)-1]
+		}
+		for len(screen) > rows {
+			if pushTop {
+				t.pushScrollback(screen[0])
+			}
+			screen = screen[1:]
+			if t.y > 0 {
+				t.y--
+			}
+		}
+		for len(screen) < rows {
+			screen = append(screen, t.blankLine(cols))
+		}
+		for i, l := range screen {
+			screen[i] = l.resized(cols)
+		}
+		return screen
+	}
+	if t.altScreen {
+		y := t.y
+		t.mainScreen = resize(t.mainScreen, false)
+		t.y = y
+		t.screen = resize(t.screen, false)
+	} else {
+		t.screen = resize(t.screen, true)
+	}
+
+	t.cols, t.rows = cols, rows
+	t.version++
+	t.top, t.bottom = 0, rows-1
+	t.tabs.Resize(cols)
+	t.x = min(t.x, cols-1)
+	t.y = min(t.y, rows-1)
+	t.wrapPending = false
+}
+
+// Render returns the last historyLines lines of scrollback followed by every
+// screen row, one line per row with SGR sequences for styled cells. This
+// matches `tmux capture-pane -p -e -S -N`. The alternate screen has no
+// scrollback.
+func (t *Terminal) Render(historyLines int) string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var b strings.Builder
+	if !t.altScreen && historyLines > 0 {
+		history := t.history()
+		for _, l := range history[max(len(history)-historyLines, 0):] {
+			l.render(&b)
+			b.WriteByte('\n')
+		}
+	}
+	for _, l := range t.screen {
+		l.render(&b)
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// ScrollbackLen returns the number of lines in scrollback.
+func (t *Terminal) ScrollbackLen() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return len(t.history())
+}
+
+// blankCell is an erased cell, carrying the pen's background (xterm BCE).
+func (t *Terminal) blankCell() cellbuf.Cell {
+	c := cellbuf.BlankCell
+	c.Style.Bg = t.pen.Bg
+	return c
+}
+
+func (t *Terminal) blankLine(cols int) line {
+	l := make(line, cols)
+	blank := t.blankCell()
+	for i := range l {
+		l[i] = blank
+	}
+	return l
+}
+
+func (t *Terminal) blankScreen() []line {
+	screen := make([]line, t.rows)
+	for i := range screen {
+		screen[i] = t.blankLine(t.cols)
+	}
+	return screen
+}
+
+// pushScrollback appends a line to scrollback. The oldest lines are dropped
+// in batches so a busy session doesn't copy the whole history per line.
+func (t *Terminal) pushScrollback(l line) {
+	t.scrollback = append(t.scrollback, l)
+	if len(t.scrollback) > t.maxScroll+t.maxScroll/4 {
+		t.scrollback = append([]line(nil), t.scrollback[len(t.scrollback)-t.maxScroll:]...)
+	}
+}
+
+// history returns scrollback capped to the configured size.
+func (t *Terminal) history() []line {
+	if over := len(t.scrollback) - t.maxScroll; over > 0 {
+		return t.scrollback[over:]
+	}
+	return t.scrollback
+}
+
+// print writes a rune at the cursor, handling wide characters and autowrap.
+func (t *Terminal) print(r rune) {
+	if t.lineDrawing && r >= 0x5f && r <= 0x7e {
+		r = decSpecialGraphics[r-0x5f]
+	}
+	w := runewidth.RuneWidth(r)
+	if w == 0 {
+		// Combining mark: attach to the previous cell
+		if x := t.prevCellX(); x >= 0 {
+			t.screen[t.y][x].Comb = append(t.screen[t.y][x].Comb, r)
+		}
+		return
+	}
+	if w > t.cols {
+		return
+	}
+
+	if t.wrapPending || t.x+w > t.cols {
+		if t.noAutowrap {
+			t.x = t.cols - w
+		} else {
+			t.x = 0
+			t.index()
+		}
+		t.wrapPending = false
+	}
+
+	row := t.screen[t.y]
+	t.clearWideAt(row, t.x)
+	if w == 2 {
+		t.clearWideAt(row, t.x+1)
+	}
+	row[t.x] = cellbuf.Cell{Rune: r, Width: w, Style: t.pen}
+	if w == 2 {
+		row[t.x+1] = cellbuf.Cell{Style: t.pen}
+	}
+	t.lastRune = r
+
+	t.x += w
+	if t.x >= t.cols {
+		t.x = t.cols - 1
+		t.wrapPending = true
+	}
+}
+
+// prevCellX returns the column of the cell before the cursor, skipping wide placeholders.
+func (t *Terminal) prevCellX() int {
+	x := t.x - 1
+	if t.wrapPending {
+		x = t.x
+	}
+	for x >= 0 && t.screen[t.y][x].Width == 0 {
+		x--
+	}
+	return x
+}
+
+// clearWideAt blanks both halves of a wide character overlapping column x.
+func (t *Terminal) clearWideAt(row line, x int) {
+	if x < 0 || x >= len(row) {
+		return
+	}
+	blank := t.blankCell()
+	switch {
+	case row[x].Width == 2 && x+1 < len(row):
+		row[x+1] = blank
+	case row[x].Width == 0 && x > 0 && row[x-1].Width == 2:
+		row[x-1] = blank
+	}
+}
+
+// execute handles C0 control characters.
+func (t *Terminal) execute(b byte) {
+	switch b {
+	case ansi.BS:
+		t.wrapPending = false
+		if t.x > 0 {
+			t.x--
+		}
+	case ansi.HT:
+		t.x = min(t.tabs.Next(t.x), t.cols-1)
+	case ansi.LF, ansi.VT, ansi.FF:
+		t.index()
+	case ansi.CR:
+		t.x = 0
+		t.wrapPending = false
+	case ansi.SO, ansi.SI:
+		// G1 charsets aren't supported
+	}
+}
+
+// index moves the cursor down, scrolling at the bottom of the scroll region.
+func (t *Terminal) index() {
+	t.wrapPending = false
+	if t.y == t.bottom {
+		t.scrollUp(1)
+	} else if t.y < t.rows-1 {
+		t.y++
+	}
+}
+
+// reverseIndex moves the cursor up, scrolling at the top of the scroll region.
+func (t *Terminal) reverseIndex() {
+	t.wrapPending = false
+	if t.y == t.top {
+		t.scrollDown(1)
+	} else if t.y > 0 {
+		t.y--
+	}
+}
+
+// scrollUp scrolls the scroll region up n lines. Lines leaving a full-screen
+// region of the main screen go to scrollback.
+func (t *Terminal) scrollUp(n int) {
+	n = min(n, t.bottom-t.top+1)
+	toScrollback := !t.altScreen && t.top == 0 && t.bottom == t.rows-1
+	for i := 0; i < n; i++ {
+		if toScrollback {
+			t.pushScrollback(t.screen[t.top])
+		}
+		copy(t.screen[t.top:t.bottom], t.screen[t.top+1:t.bottom+1])
+		t.screen[t.bottom] = t.blankLine(t.cols)
+	}
+}
+
+// scrollDown scrolls the scroll region down n lines.
+func (t *Terminal) scrollDown(n int) {
+	n = min(n, t.bottom-t.top+1)
+	for i := 0; i < n; i++ {
+		copy(t.screen[t.top+1:t.bottom+1], t.screen[t.top:t.bottom])
+		t.screen[t.top] = t.blankLine(t.cols)
+	}
+}
+
+// moveTo places the cursor, clamped to the screen.
+func (t *Terminal) moveTo(x, y int) {
+	t.x = max(0, min(x, t.cols-1))
+	t.y = max(0, min(y, t.rows-1))
+	t.wrapPending = false
+}
+
+// eraseCells blanks columns [from, to) of row y.
+func (t *Terminal) eraseCells(y, from, to int) {
+	row := t.screen[y]
+	from, to = max(from, 0), min(to, t.cols)
+	if from >= to {
+		return
+	}
+	t.clearWideAt(row, from)
+	t.clearWideAt(row, to-1)
+	blank := t.blankCell()
+	for x := from; x < to; x++ {
+		row[x] = blank
+	}
+}
+
+func (t *Terminal) handleEsc(cmd ansi.Cmd) {
+	switch cmd.Intermediate() {
+	case '(':
+		t.lineDrawing = cmd.Final() == '0'
+		return
+	case ')', '*', '+', '#':
+		return
+	}
+	switch cmd.Final() {
+	case '7':
+		t.saveCursor()
+	case '8':
+		t.restoreCursor()
+	case 'D':
+		t.index()
+	case 'E':
+		t.x = 0
+		t.index()
+	case 'M':
+		t.reverseIndex()
+	case 'H':
+		t.tabs.Set(t.x)
+	case 'c':
+		t.reset()
+	}
+}
+
+func (t *Terminal) handleOsc(cmd int, data []byte) {
+	if cmd == 0 || cmd == 2 {
+		// data is "N;title"
+		if _, title, ok := strings.Cut(string(data), ";"); ok {
+			t.title = title
+		}
+	}
+}
+
+func (t *Terminal) saveCursor() {
+	t.saved = cursor{x: t.x, y: t.y, pen: t.pen}
+}
+
+func (t *Terminal) restoreCursor() {
+	t.moveTo(t.saved.x, t.saved.y)
+	t.pen = t.saved.pen
+}
+
+// reset performs a full reset (RIS), keeping scrollback.
+func (t *Terminal) reset() {
+	if t.altScreen {
+		t.screen, t.mainScreen = t.mainScreen, nil
+		t.altScreen = false
+	}
+	t.pen = cellbuf.Style{}
+	t.screen = t.blankScreen()
+	t.x, t.y, t.wrapPending = 0, 0, false
+	t.top, t.bottom = 0, t.rows-1
+	t.tabs = cellbuf.DefaultTabStops(t.cols)
+	t.cursorHidden, t.noAutowrap, t.appCursorKeys = false, false, false
+	t.bracketedPaste, t.lineDrawing = false, false
+	t.saved = cursor{}
+}
+
+func (t *Terminal) handleCsi(cmd ansi.Cmd, params ansi.Params) {
+	param := func(i, def int) int {
+		v, _, _ := params.Param(i, def)
+		if v == 0 && def > 0 {
+			return def
+		}
+		return v
+	}
+
+	if cmd.Intermediate() != 0 {
+		return // DECSCUSR, DECRQM and friends
+	}
+	switch cmd.Prefix() {
+	case '?':
+		switch cmd.Final() {
+		case 'h', 'l':
+			for i := range params {
+				t.setPrivateMode(param(i, 0), cmd.Final() == 'h')
+			}
+		case 'J':
+			t.eraseDisplay(param(0, 0))
+		case 'K':
+			t.eraseLine(param(0, 0))
+		}
+		return
+	case '>':
+		if cmd.Final() == 'c' {
+			t.replyf("\x1b[>0;10;1c") // Secondary device attributes
+		}
+		return
+	case 0:
+	default:
+		return
+	}
+
+	switch cmd.Final() {
+	case '@': // ICH
+		n := min(param(0, 1), t.cols-t.x)
+		row := t.screen[t.y]
+		t.clearWideAt(row, t.x)
+		copy(row[t.x+n:], row[t.x:t.cols-n])
+		t.eraseCells(t.y, t.x, t.x+n)
+	case 'A': // CUU
+		t.moveTo(t.x, max(t.y-param(0, 1), t.topLimit()))
+	case 'B', 'e': // CUD, VPR
+		t.moveTo(t.x, min(t.y+param(0, 1), t.bottomLimit()))
+	case 'C', 'a': // CUF, HPR
+		t.moveTo(t.x+param(0, 1), t.y)
+	case 'D': // CUB
+		t.moveTo(t.x-param(0, 1), t.y)
+	case 'E': // CNL
+		t.moveTo(0, min(t.y+param(0, 1), t.bottomLimit()))
+	case 'F': // CPL
+		t.moveTo(0, max(t.y-param(0, 1), t.topLimit()))
+	case 'G', '`': // CHA, HPA
+		t.moveTo(param(0, 1)-1, t.y)
+	case 'H', 'f': // CUP
+		t.moveTo(param(1, 1)-1, param(0, 1)-1)
+	case 'd': // VPA
+		t.moveTo(t.x, param(0, 1)-1)
+	case 'I': // CHT
+		for i := param(0, 1); i > 0; i-- {
+			t.x = min(t.tabs.Next(t.x), t.cols-1)
+		}
+	case 'Z': // CBT
+		for i := param(0, 1); i > 0; i-- {
+			t.x = t.tabs.Prev(t.x)
+		}
+	case 'J':
+		t.eraseDisplay(param(0, 0))
+	case 'K':
+		t.eraseLine(param(0, 0))
+	case 'L': // IL
+		if t.y >= t.top && t.y <= t.bottom {
+			top := t.top
+			t.top = t.y
+			t.scrollDown(param(0, 1))
+			t.top = top
+			t.x = 0
+		}
+	case 'M': // DL
+		if t.y >= t.top && t.y <= t.bottom {
+			top := t.top
+			t.top = t.y
+			alt := t.altScreen
+			t.altScreen = true // Deleted lines never go to scrollback
+			t.scrollUp(param(0, 1))
+			t.altScreen = alt
+			t.top = top
+			t.x = 0
+		}
+	case 'P': // DCH
+		n := min(param(0, 1), t.cols-t.x)
+		row := t.screen[t.y]
+		t.clearWideAt(row, t.x)
+		t.clearWideAt(row, t.x+n-1)
+		copy(row[t.x:], row[t.x+n:])
+		t.eraseCells(t.y, t.cols-n, t.cols)
+	case 'X': // ECH
+		t.eraseCells(t.y, t.x, t.x+param(0, 1))
+	case 'S': // SU
+		t.scrollUp(param(0, 1))
+	case 'T': // SD
+		t.scrollDown(param(0, 1))
+	case 'b': // REP
+		if t.lastRune != 0 {
+			for i := param(0, 1); i > 0; i-- {
+				t.print(t.lastRune)
+			}
+		}
+	case 'g': // TBC
+		switch param(0, 0) {
+		case 0:
+			t.tabs.Reset(t.x)
+		case 3:
+			t.tabs.Clear()
+		}
+	case 'm': // SGR
+		cellbuf.ReadStyle(params, &t.pen)
+	case 'r': // DECSTBM
+		top, bottom := param(0, 1)-1, param(1, t.rows)-1
+		if top < bottom && bottom < t.rows {
+			t.top, t.bottom = top, bottom
+			t.moveTo(0, 0)
+		}
+	case 's':
+		t.saveCursor()
+	case 'u':
+		t.restoreCursor()
+	case 'n': // DSR
+		switch param(0, 0) {
+		case 5:
+			t.replyf("\x1b[0n")
+		case 6:
+			t.replyf("\x1b[%d;%dR", t.y+1, min(t.x, t.cols-1)+1)
+		}
+	case 'c': // DA
+		t.replyf("\x1b[?1;2c")
+	case 't': // Window ops
+		if param(0, 0) == 18 {
+			t.replyf("\x1b[8;%d;%dt", t.rows, t.cols)
+		}
+	}
+}
+
+// topLimit and bottomLimit bound vertical cursor movement: inside the scroll
+// region the cursor stops at its margins.
+func (t *Terminal) topLimit() int {
+	if t.y >= t.top {
+		return t.top
+	}
+	return 0
+}
+
+func (t *Terminal) bottomLimit() int {
+	if t.y <= t.bottom {
+		return t.bottom
+	}
+	return t.rows - 1
+}
+
+func (t *Terminal) eraseDisplay(mode int) {
+	switch mode {
+	case 0:
+		t.eraseCells(t.y, t.x, t.cols)
+		for y := t.y + 1; y < t.rows; y++ {
+			t.screen[y] = t.blankLine(t.cols)
+		}
+	case 1:
+		t.eraseCells(t.y, 0, t.x+1)
+		for y := 0; y < t.y; y++ {
+			t.screen[y] = t.blankLine(t.cols)
+		}
+	case 2:
+		t.screen = t.blankScreen()
+	case 3:
+		t.scrollback = nil
+	}
+}
+
+func (t *Terminal) eraseLine(mode int) {
+	switch mode {
+	case 0:
+		t.eraseCells(t.y, t.x, t.cols)
+	case 1:
+		t.eraseCells(t.y, 0, t.x+1)
+	case 2:
+		t.eraseCells(t.y, 0, t.cols)
+	}
+	t.wrapPending = false
+}
+
+func (t *Terminal) setPrivateMode(mode int, on bool) {
+	switch mode {
+	case 1:
+		t.appCursorKeys = on
+	case 7:
+		t.noAutowrap = !on
+	case 25:
+		t.cursorHidden = !on
+	case 47, 1047, 1049:
+		if mode == 1049 && on {
+			t.saveCursor()
+		}
+		t.setAltScreen(on)
+		if mode == 1049 && !on {
+			t.restoreCursor()
+		}
+	case 2004:
+		t.bracketedPaste = on
+	}
+}
+
+func (t *Terminal) setAltScreen(on bool) {
+	if on == t.altScreen {
+		return
+	}
+	if on {
+		t.mainScreen = t.screen
+		t.screen = t.blankScreen()
+	} else {
+		t.screen, t.mainScreen = t.mainScreen, nil
+	}
+	t.altScreen = on
+	t.top, t.bottom = 0, t.rows-1
+	t.wrapPending = false
+}
+
+func (t *Terminal) replyf(format string, args ...any) {
+	if t.reply != nil {
+		_, _ = fmt.Fprintf(t.reply, format, args...)
+	}
+}
+
+// blank reports whether a line has no visible content or styling.
+func (l line) blank() bool {
+	for _, c := range l {
+		if (c.Rune != ' ' && c.Rune != 0) || !c.Style.Empty() {
+			return false
+		}
+	}
+	return true
+}
+
+// resized returns the line truncated or padded with blank cells to cols.
+func (l line) resized(cols int) line {
+	if len(l) == cols {
+		return l
+	}
+	if len(l) > cols {
+		l = l[:cols]
+		if cols > 0 && l[cols-1].Width == 2 {
+			l[cols-1] = cellbuf.BlankCell
+		}
+		return l
+	}
+	out := make(line, cols)
+	copy(out, l)
+	for i := len(l); i < cols; i++ {
+		out[i] = cellbuf.BlankCell
+	}
+	return out
+}
+
+// render writes the line with SGR sequences, trimming trailing unstyled blanks.
+func (l line) render(b *strings.Builder) {
+	end := len(l)
+	for end > 0 {
+		c := l[end-1]
+		if (c.Rune != ' ' && c.Width != 0) || !c.Style.Empty() {
+			break
+		}
+		end--
+	}
+
+	var cur cellbuf.Style
+	for _, c := range l[:end] {
+		if c.Width == 0 {
+			continue // Wide character placeholder
+		}
+		if !c.Style.Equal(&cur) {
+			b.WriteString(ansi.ResetStyle)
+			if !c.Style.Empty() {
+				b.WriteString(c.Style.Sequence())
+			}
+			cur = c.Style
+		}
+		b.WriteString(c.String())
+	}
+	if !cur.Empty() {
+		b.WriteString(ansi.ResetStyle)
+	}
+}
+
+// decSpecialGraphics maps 0x5f-0x7e to DEC line drawing characters.
+var decSpecialGraphics = [...]rune{
+	' ', '◆', '▒', '␉', '␌', '␍', '␊', '°', '±', '␤', '␋', '┘', '┐', '┌', '└', '┼',
+	'⎺', '⎻', '─', '⎼', '⎽', '├', '┤', '┴', '┬', '│', '≤', '≥', 'π', '≠', '£', '·',
+}
@@ -0,0 +1,192 @@
+package vt
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// screenLines renders the screen without history, trimming the final newline.
+func screenLines(t *Terminal) []string {
+	return strings.Split(strings.TrimSuffix(t.Render(0), "\n"), "\n")
+}
+
+func TestTerminal_PrintAndWrap(t *testing.T) {
+	term := New(5, 3, 0, nil)
+	_, _ = term.Write([]byte("hello world"))
+
+	got := screenLines(term)
+	want := []string{"hello", " worl", "d"}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("line %d = %q, want %q", i, got[i], want[i])
+		}
+	}
+	if row, col, _ := term.Cursor(); row != 2 || col != 1 {
+		t.Errorf("cursor = (%d,%d), want (2,1)", row, col)
+	}
+}
+
+func TestTerminal_CursorMovementAndErase(t *testing.T) {
+	term := New(10, 3, 0, nil)
+	_, _ = term.Write([]byte("abcdefghij\r\nline two\x1b[1;3HX\x1b[2;5H\x1b[K"))
+
+	got := screenLines(term)
+	if got[0] != "abXdefghij" {
+		t.Errorf("line 0 = %q", got[0])
+	}
+	if got[1] != "line" {
+		t.Errorf("line 1 = %q, want erased to end", got[1])
+	}
+
+	_, _ = term.Write([]byte("\x1b[2J"))
+	if strings.TrimSpace(term.Render(0)) != "" {
+		t.Errorf("screen not cleared: %q", term.Render(0))
+	}
+}
+
+func TestTerminal_SGR(t *testing.T) {
+	term := New(20, 2, 0, nil)
+	_, _ = term.Write([]byte("a\x1b[1;31mred\x1b[0mb"))
+
+	got := screenLines(term)[0]
+	if !strings.Contains(got, "red") || !strings.Contains(got, "\x1b[") {
+		t.Fatalf("styled line = %q", got)
+	}
+	if !strings.HasPrefix(got, "a") || !strings.HasSuffix(got, "b") {
+		t.Errorf("styled line = %q, want plain a ... b", got)
+	}
+	if plain := stripSGR(got); plain != "aredb" {
+		t.Errorf("plain text = %q", plain)
+	}
+}
+
+func TestTerminal_WideCharacters(t *testing.T) {
+	term := New(4, 2, 0, nil)
+	_, _ = term.Write([]byte("世界!"))
+
+	got := screenLines(term)
+	if got[0] != "世界" || got[1] != "!" {
+		t.Errorf("lines = %q", got)
+	}
+}
+
+func TestTerminal_ScrollbackAndAltScreen(t *testing.T) {
+	term := New(10, 2, 0, nil)
+	_, _ = term.Write([]byte("one\r\ntwo\r\nthree"))
+
+	if n := term.ScrollbackLen(); n != 1 {
+		t.Fatalf("scrollback = %d, want 1", n)
+	}
+	if got := term.Render(10); got != "one\ntwo\nthree\n" {
+		t.Errorf("render = %q", got)
+	}
+
+	_, _ = term.Write([]byte("\x1b[?1049h\x1b[Hfull screen app"))
+	if got := term.Render(10); got != "full scree\nn app\n" {
+		t.Errorf("alt render = %q", got)
+	}
+	_, _ = term.Write([]byte("\x1b[?1049l"))
+	if got := term.Render(10); got != "one\ntwo\nthree\n" {
+		t.Errorf("restored render = %q", got)
+	}
+	if row, col, _ := term.Cursor(); row != 1 || col != 5 {
+		t.Errorf("restored cursor = (%d,%d), want (1,5)", row, col)
+	}
+}
+
+func TestTerminal_ScrollRegion(t *testing.T) {
+	term := New(10, 4, 0, nil)
+	_, _ = term.Write([]byte("header\r\na\r\nb\r\nfooter"))
+	// Region rows 2-3, scroll it by writing a newline at its bottom
+	_, _ = term.Write([]byte("\x1b[2;3r\x1b[3;1H\nc"))
+
+	got := screenLines(term)
+	want := []string{"header", "b", "c", "footer"}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("line %d = %q, want %q", i, got[i], want[i])
+		}
+	}
+	if n := term.ScrollbackLen(); n != 0 {
+		t.Errorf("region scroll reached scrollback: %d lines", n)
+	}
+}
+
+func TestTerminal_Replies(t *testing.T) {
+	var reply bytes.Buffer
+	term := New(10, 5, 0, &reply)
+	_, _ = term.Write([]byte("\x1b[3;4H\x1b[6n\x1b[c"))
+
+	if got := reply.String(); got != "\x1b[3;4R\x1b[?1;2c" {
+		t.Errorf("reply = %q", got)
+	}
+}
+
+func TestTerminal_Modes(t *testing.T) {
+	term := New(10, 2, 0, nil)
+	_, _ = term.Write([]byte("\x1b[?2004h\x1b[?1h\x1b[?25l\x1b]0;my title\x07"))
+
+	if !term.BracketedPaste() || !term.AppCursorKeys() {
+		t.Error("bracketed paste and cursor key modes should be on")
+	}
+	if _, _, visible := term.Cursor(); visible {
+		t.Error("cursor should be hidden")
+	}
+	if term.Title() != "my title" {
+		t.Errorf("title = %q", term.Title())
+	}
+}
+
+func TestTerminal_Resize(t *testing.T) {
+	term := New(10, 3, 0, nil)
+	_, _ = term.Write([]byte("a\r\nb\r\nc"))
+	term.Resize(4, 2)
+
+	if cols, rows := term.Size(); cols != 4 || rows != 2 {
+		t.Fatalf("size = %dx%d", cols, rows)
+	}
+	if got := term.Render(10); got != "a\nb\nc\n" {
+		t.Errorf("render after shrink = %q", got)
+	}
+	if row, _, _ := term.Cursor(); row != 1 {
+		t.Errorf("cursor row = %d, want 1", row)
+	}
+}
+
+func TestTerminal_Version(t *testing.T) {
+	term := New(10, 3, 0, nil)
+	v0 := term.Version()
+	_, _ = term.Write(nil)
+	if term.Version() != v0 {
+		t.Error("empty write changed version")
+	}
+	_, _ = term.Write([]byte("x"))
+	v1 := term.Version()
+	if v1 == v0 {
+		t.Error("write did not change version")
+	}
+	term.Resize(10, 3)
+	if term.Version() != v1 {
+		t.Error("no-op resize changed version")
+	}
+	term.Resize(8, 3)
+	if term.Version() == v1 {
+		t.Error("resize did not change version")
+	}
+}
+
+// stripSGR removes CSI ... m sequences.
+func stripSGR(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == 0x1b {
+			for i < len(s) && s[i] != 'm' {
+				i++
+			}
+			continue
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}